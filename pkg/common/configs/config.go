@@ -24,6 +24,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+	"time"
 
 	"go.uber.org/zap"
 	"gopkg.in/yaml.v2"
@@ -34,8 +35,8 @@ import (
 // The configuration can contain multiple partitions. Each partition contains the queue definition for a logical
 // set of scheduler resources.
 type SchedulerConfig struct {
-	Partitions []PartitionConfig
-	Checksum   [32]byte `yaml:"-" json:"-"`
+	Partitions []PartitionConfig `schema:"required,minItems=1"`
+	Checksum   [32]byte          `yaml:"-" json:"-"`
 }
 
 // The partition object for each partition:
@@ -44,13 +45,21 @@ type SchedulerConfig struct {
 // - a list of placement rule definition objects
 // - a list of users specifying limits on the partition
 // - the preemption configuration for the partition
+// - a set of properties, exact definition of what can be set is not part of the yaml
 type PartitionConfig struct {
-	Name           string
+	Name           string `schema:"required"`
 	Queues         []QueueConfig
 	PlacementRules []PlacementRule           `yaml:",omitempty" json:",omitempty"`
 	Limits         []Limit                   `yaml:",omitempty" json:",omitempty"`
 	Preemption     PartitionPreemptionConfig `yaml:",omitempty" json:",omitempty"`
 	NodeSortPolicy NodeSortingPolicy         `yaml:",omitempty" json:",omitempty"`
+	Properties     map[string]string         `yaml:",omitempty" json:",omitempty"`
+	// time in milliseconds to wait before the next scheduling cycle when nothing was scheduled, 0 uses the default
+	SchedulingIntervalMs int `yaml:",omitempty" json:",omitempty" schema:"minimum=0"`
+	// maximum time to replay the existing allocations of a new node, 0 means no timeout
+	NodeRegistrationTimeout time.Duration `yaml:",omitempty" json:",omitempty"`
+	// priority offset per priority class name, applied to the asks of applications tagged with the class
+	PriorityClasses map[string]int32 `yaml:",omitempty" json:",omitempty"`
 }
 
 type PartitionPreemptionConfig struct {
@@ -66,7 +75,7 @@ type PartitionPreemptionConfig struct {
 // - a list of sub or child queues
 // - a list of users specifying limits on a queue
 type QueueConfig struct {
-	Name            string
+	Name            string            `schema:"required"`
 	Parent          bool              `yaml:",omitempty" json:",omitempty"`
 	Resources       Resources         `yaml:",omitempty" json:",omitempty"`
 	MaxApplications uint64            `yaml:",omitempty" json:",omitempty"`
@@ -93,12 +102,14 @@ type Resources struct {
 // - rule link to allow setting a rule to generate the parent
 // - value a generic value interpreted depending on the rule type (i.e queue name for the "fixed" rule
 // or the application label name for the "tag" rule)
+// - fallback on error flag: continue with the next rule if this rule fails instead of rejecting the application
 type PlacementRule struct {
-	Name   string
-	Create bool           `yaml:",omitempty" json:",omitempty"`
-	Filter Filter         `yaml:",omitempty" json:",omitempty"`
-	Parent *PlacementRule `yaml:",omitempty" json:",omitempty"`
-	Value  string         `yaml:",omitempty" json:",omitempty"`
+	Name            string         `schema:"required"`
+	Create          bool           `yaml:",omitempty" json:",omitempty"`
+	Filter          Filter         `yaml:",omitempty" json:",omitempty"`
+	Parent          *PlacementRule `yaml:",omitempty" json:",omitempty"`
+	Value           string         `yaml:",omitempty" json:",omitempty"`
+	FallbackOnError bool           `yaml:",omitempty" json:",omitempty"`
 }
 
 // The user and group filter for a rule.
@@ -134,8 +145,10 @@ type Limit struct {
 
 // Global Node Sorting Policy section
 // - type: different type of policies supported (binpacking, fair etc)
+// - parameters: policy specific tuning parameters, keyed as <policy>.<key> (i.e. binpacking.memoryWeight)
 type NodeSortingPolicy struct {
-	Type string
+	Type       string
+	Parameters map[string]string `yaml:",omitempty" json:",omitempty"`
 }
 
 type LoadSchedulerConfigFunc func(policyGroup string) (*SchedulerConfig, error)
@@ -25,6 +25,7 @@ import (
 	"path"
 	"strings"
 	"testing"
+	"time"
 
 	"gotest.tools/assert"
 
@@ -597,6 +598,315 @@ partitions:
 	}
 }
 
+func TestPartitionProperties(t *testing.T) {
+	data := `
+partitions:
+  - name: default
+    queues:
+      - name: root
+    properties:
+      reservation.timeout: 10m
+`
+	// validate the config and check after the update
+	conf, err := CreateConfig(data)
+	assert.NilError(t, err, "should expect no error")
+	assert.Equal(t, conf.Partitions[0].Properties[ReservationTimeout], "10m", "partition property not set")
+
+	data = `
+partitions:
+  - name: default
+    queues:
+      - name: root
+    properties:
+      reservation.timeout: unknown
+`
+	_, err = CreateConfig(data)
+	if err == nil {
+		t.Error("illegal reservation timeout should have failed parsing")
+	}
+
+	data = `
+partitions:
+  - name: default
+    queues:
+      - name: root
+    properties:
+      reservation.timeout: -1m
+`
+	_, err = CreateConfig(data)
+	if err == nil {
+		t.Error("negative reservation timeout should have failed parsing")
+	}
+
+	data = `
+partitions:
+  - name: default
+    queues:
+      - name: root
+    properties:
+      application.completed.limit: 10
+`
+	conf, err = CreateConfig(data)
+	assert.NilError(t, err, "should expect no error")
+	assert.Equal(t, conf.Partitions[0].Properties[CompletedApplicationsLimit], "10", "partition property not set")
+
+	for _, value := range []string{"unknown", "-1"} {
+		data = `
+partitions:
+  - name: default
+    queues:
+      - name: root
+    properties:
+      application.completed.limit: ` + value + `
+`
+		_, err = CreateConfig(data)
+		assert.ErrorContains(t, err, CompletedApplicationsLimit, "illegal completed application limit %s should have failed parsing", value)
+	}
+
+	data = `
+partitions:
+  - name: default
+    queues:
+      - name: root
+    properties:
+      allocation.compaction.interval: 0
+`
+	conf, err = CreateConfig(data)
+	assert.NilError(t, err, "should expect no error")
+	assert.Equal(t, conf.Partitions[0].Properties[AllocationCompactionInterval], "0", "partition property not set")
+
+	for _, value := range []string{"unknown", "-1"} {
+		data = `
+partitions:
+  - name: default
+    queues:
+      - name: root
+    properties:
+      allocation.compaction.interval: ` + value + `
+`
+		_, err = CreateConfig(data)
+		assert.ErrorContains(t, err, AllocationCompactionInterval, "illegal allocation compaction interval %s should have failed parsing", value)
+	}
+}
+
+func TestQueueProperties(t *testing.T) {
+	data := `
+partitions:
+  - name: default
+    queues:
+      - name: root
+        queues:
+          - name: leaf
+            properties:
+              max.concurrent.applications: 2
+`
+	conf, err := CreateConfig(data)
+	assert.NilError(t, err, "should expect no error")
+	assert.Equal(t, conf.Partitions[0].Queues[0].Queues[0].Properties[MaxConcurrentApplications], "2", "queue property not set")
+
+	for _, value := range []string{"unknown", "-1", "1.5"} {
+		data = `
+partitions:
+  - name: default
+    queues:
+      - name: root
+        queues:
+          - name: leaf
+            properties:
+              max.concurrent.applications: ` + value + `
+`
+		_, err = CreateConfig(data)
+		assert.ErrorContains(t, err, MaxConcurrentApplications, "illegal max concurrent applications %s should have failed parsing", value)
+	}
+
+	for _, value := range []string{"unknown", "0", "-1", "NaN", "Inf"} {
+		data = `
+partitions:
+  - name: default
+    queues:
+      - name: root
+        queues:
+          - name: leaf
+            properties:
+              weight: ` + value + `
+`
+		_, err = CreateConfig(data)
+		assert.ErrorContains(t, err, QueueWeight, "illegal queue weight %s should have failed parsing", value)
+	}
+	data = `
+partitions:
+  - name: default
+    queues:
+      - name: root
+        queues:
+          - name: leaf
+            properties:
+              weight: 2.5
+`
+	conf, err = CreateConfig(data)
+	assert.NilError(t, err, "valid queue weight should not have failed")
+	assert.Equal(t, conf.Partitions[0].Queues[0].Queues[0].Properties[QueueWeight], "2.5", "queue weight not set")
+}
+
+func TestSchedulingInterval(t *testing.T) {
+	data := `
+partitions:
+  - name: default
+    queues:
+      - name: root
+    schedulingintervalms: 10
+`
+	conf, err := CreateConfig(data)
+	assert.NilError(t, err, "should expect no error")
+	assert.Equal(t, conf.Partitions[0].SchedulingIntervalMs, 10, "scheduling interval not set")
+
+	data = `
+partitions:
+  - name: default
+    queues:
+      - name: root
+    schedulingintervalms: -1
+`
+	_, err = CreateConfig(data)
+	if err == nil {
+		t.Error("negative scheduling interval should have failed parsing")
+	}
+}
+
+func TestPriorityClasses(t *testing.T) {
+	data := `
+partitions:
+  - name: default
+    queues:
+      - name: root
+    priorityclasses:
+      high: 100
+      low: -10
+`
+	conf, err := CreateConfig(data)
+	assert.NilError(t, err, "should expect no error")
+	assert.DeepEqual(t, conf.Partitions[0].PriorityClasses, map[string]int32{"high": 100, "low": -10})
+
+	data = `
+partitions:
+  - name: default
+    queues:
+      - name: root
+    priorityclasses:
+      "": 100
+`
+	_, err = CreateConfig(data)
+	assert.ErrorContains(t, err, "name cannot be empty", "empty priority class name should have failed parsing")
+}
+
+func TestNodeRegistrationTimeout(t *testing.T) {
+	data := `
+partitions:
+  - name: default
+    queues:
+      - name: root
+    noderegistrationtimeout: 30s
+`
+	conf, err := CreateConfig(data)
+	assert.NilError(t, err, "should expect no error")
+	assert.Equal(t, conf.Partitions[0].NodeRegistrationTimeout, 30*time.Second, "node registration timeout not set")
+
+	data = `
+partitions:
+  - name: default
+    queues:
+      - name: root
+    noderegistrationtimeout: -1s
+`
+	_, err = CreateConfig(data)
+	assert.ErrorContains(t, err, "node registration timeout", "negative node registration timeout should have failed parsing")
+}
+
+func TestNodeSortingPolicyParameters(t *testing.T) {
+	data := `
+partitions:
+  - name: default
+    queues:
+      - name: root
+    nodesortpolicy:
+      type: binpacking
+      parameters:
+        binpacking.memoryWeight: "2.5"
+        fair.memoryWeight: "0.5"
+`
+	conf, err := CreateConfig(data)
+	assert.NilError(t, err, "should expect no error")
+	assert.Equal(t, conf.Partitions[0].NodeSortPolicy.Parameters["binpacking.memoryWeight"], "2.5", "node sorting parameter not set")
+
+	data = `
+partitions:
+  - name: default
+    queues:
+      - name: root
+    nodesortpolicy:
+      type: binpacking
+      parameters:
+        memoryWeight: "2.5"
+`
+	_, err = CreateConfig(data)
+	assert.NilError(t, err, "parameter without a policy namespace should apply to the policy")
+
+	data = `
+partitions:
+  - name: default
+    queues:
+      - name: root
+    nodesortpolicy:
+      type: binpacking
+      parameters:
+        memoryWeight: "-1"
+`
+	_, err = CreateConfig(data)
+	assert.ErrorContains(t, err, "invalid weight", "invalid parameter without a policy namespace should have failed parsing")
+
+	data = `
+partitions:
+  - name: default
+    queues:
+      - name: root
+    nodesortpolicy:
+      type: binpacking
+      parameters:
+        binpacking.memoryWeight: heavy
+`
+	_, err = CreateConfig(data)
+	if err == nil {
+		t.Error("illegal node sorting weight should have failed parsing")
+	}
+
+	data = `
+partitions:
+  - name: default
+    queues:
+      - name: root
+    nodesortpolicy:
+      type: composite
+      parameters:
+        composite.policies: fair@topology.kubernetes.io/zone,binpacking
+        binpacking.memoryWeight: "2"
+`
+	_, err = CreateConfig(data)
+	assert.NilError(t, err, "composite node sorting policy should have been accepted")
+
+	data = `
+partitions:
+  - name: default
+    queues:
+      - name: root
+    nodesortpolicy:
+      type: composite
+      parameters:
+        composite.policies: fair,composite
+`
+	_, err = CreateConfig(data)
+	assert.ErrorContains(t, err, "cannot contain a composite policy", "nested composite node sorting policy should have failed parsing")
+}
+
 func TestParseRule(t *testing.T) {
 	data := `
 partitions:
@@ -20,8 +20,11 @@ package configs
 
 import (
 	"fmt"
+	"math"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -38,6 +41,26 @@ const (
 	DefaultPartition = "default"
 	// How to sort applications in leaf queues, valid options are defined in the scheduler.policies
 	ApplicationSortPolicy = "application.sort.policy"
+	// Maximum number of applications in a leaf queue at the same time, value is a non negative integer (0 means no limit)
+	MaxConcurrentApplications = "max.concurrent.applications"
+	// Weight of the queue compared to its siblings when dividing the parent resources, value is a positive number (default 1)
+	QueueWeight = "weight"
+	// How long a reservation can exist before it is removed, value is a duration string (i.e. "10m")
+	ReservationTimeout = "reservation.timeout"
+	// Default wait between scheduling cycles in milliseconds if nothing was scheduled
+	DefaultSchedulingIntervalMs = 100
+	// Maximum number of completed applications kept per queue, value is a non negative integer (0 disables the history)
+	CompletedApplicationsLimit = "application.completed.limit"
+	// Default number of completed applications kept per queue
+	DefaultCompletedApplicationsLimit = 50
+	// Number of partition manager runs between removing stale allocations, value is a non negative integer (0 disables the compaction)
+	AllocationCompactionInterval = "allocation.compaction.interval"
+	// Default number of partition manager runs between removing stale allocations
+	DefaultAllocationCompactionInterval = 6
+	// Node attribute used to group the nodes in the partition
+	NodeGroupKey = "nodegroup.key"
+	// Default node attribute used to group the nodes
+	DefaultNodeGroupKey = "topology.kubernetes.io/zone"
 )
 
 // A queue can be a username with the dot replaced. Most systems allow a 32 character user name.
@@ -281,9 +304,75 @@ func checkNodeSortingPolicy(partition *PartitionConfig) error {
 	policy := partition.NodeSortPolicy
 
 	// Defined polices.
-	_, err := policies.FromString(policy.Type)
+	policyType, err := policies.FromString(policy.Type)
+	if err != nil {
+		return err
+	}
+	// check the parameters
+	return policies.CheckParameters(policyType, policy.Parameters)
+}
+
+// Check the partition properties: only the known properties are checked, others are ignored
+func checkPartitionProperties(partition *PartitionConfig) error {
+	if value, ok := partition.Properties[ReservationTimeout]; ok {
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid partition property %s: %v", ReservationTimeout, err)
+		}
+		if timeout < 0 {
+			return fmt.Errorf("invalid partition property %s: %s, cannot be negative", ReservationTimeout, value)
+		}
+	}
+	if value, ok := partition.Properties[CompletedApplicationsLimit]; ok {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid partition property %s: %v", CompletedApplicationsLimit, err)
+		}
+		if limit < 0 {
+			return fmt.Errorf("invalid partition property %s: %s, cannot be negative", CompletedApplicationsLimit, value)
+		}
+	}
+	if value, ok := partition.Properties[AllocationCompactionInterval]; ok {
+		interval, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid partition property %s: %v", AllocationCompactionInterval, err)
+		}
+		if interval < 0 {
+			return fmt.Errorf("invalid partition property %s: %s, cannot be negative", AllocationCompactionInterval, value)
+		}
+	}
+	return nil
+}
+
+// Check the queue properties: only the known properties are checked, others are ignored
+func checkQueueProperties(queue *QueueConfig) error {
+	if value, ok := queue.Properties[MaxConcurrentApplications]; ok {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid queue %s property %s: %v", queue.Name, MaxConcurrentApplications, err)
+		}
+		if limit < 0 {
+			return fmt.Errorf("invalid queue %s property %s: %s, cannot be negative", queue.Name, MaxConcurrentApplications, value)
+		}
+	}
+	if value, ok := queue.Properties[QueueWeight]; ok {
+		if _, err := ParseQueueWeight(value); err != nil {
+			return fmt.Errorf("invalid queue %s property: %v", queue.Name, err)
+		}
+	}
+	return nil
+}
 
-	return err
+// Parse the queue weight property, a value that is not a positive number is an error.
+func ParseQueueWeight(value string) (float64, error) {
+	weight, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s value %s: %v", QueueWeight, value, err)
+	}
+	if weight <= 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+		return 0, fmt.Errorf("invalid %s value %s: must be a positive number", QueueWeight, value)
+	}
+	return weight, nil
 }
 
 // Check the queue names configured for compliance and uniqueness
@@ -307,6 +396,11 @@ func checkQueues(queue *QueueConfig, level int) error {
 		return err
 	}
 
+	err = checkQueueProperties(queue)
+	if err != nil {
+		return err
+	}
+
 	// check this level for name compliance and uniqueness
 	queueMap := make(map[string]bool)
 	for _, child := range queue.Queues {
@@ -426,6 +520,21 @@ func Validate(newConfig *SchedulerConfig) error {
 		if err != nil {
 			return err
 		}
+		err = checkPartitionProperties(&partition)
+		if err != nil {
+			return err
+		}
+		if partition.SchedulingIntervalMs < 0 {
+			return fmt.Errorf("invalid scheduling interval %d for partition %s, cannot be negative", partition.SchedulingIntervalMs, partition.Name)
+		}
+		for className := range partition.PriorityClasses {
+			if className == "" {
+				return fmt.Errorf("invalid priority class for partition %s, name cannot be empty", partition.Name)
+			}
+		}
+		if partition.NodeRegistrationTimeout < 0 {
+			return fmt.Errorf("invalid node registration timeout %s for partition %s, cannot be negative", partition.NodeRegistrationTimeout, partition.Name)
+		}
 		// write back the partition to keep changes
 		newConfig.Partitions[i] = partition
 	}
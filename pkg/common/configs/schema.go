@@ -0,0 +1,186 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package configs
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// a duration is set in the yaml as a Go duration string like 1m30s, see time.ParseDuration
+const durationPattern = `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// GenerateJSONSchema returns a JSON Schema (draft-07) document describing the scheduler configuration.
+// The schema is derived from the configuration structs: property names follow the yaml keys, unknown
+// properties are rejected as the config is loaded strictly and constraints come from the schema tag.
+// A time.Duration is a string in the Go duration format.
+// Supported schema tag options: required, minimum=<n>, maximum=<n>, minItems=<n> and maxItems=<n>.
+func GenerateJSONSchema() ([]byte, error) {
+	gen := &schemaGenerator{definitions: make(map[string]interface{})}
+	root, err := gen.structSchema(reflect.TypeOf(SchedulerConfig{}))
+	if err != nil {
+		return nil, err
+	}
+	root["$schema"] = jsonSchemaDraft
+	root["title"] = "YuniKorn scheduler configuration"
+	root["definitions"] = gen.definitions
+	return json.MarshalIndent(root, "", "  ")
+}
+
+type schemaGenerator struct {
+	definitions map[string]interface{}
+}
+
+// Generate the schema for a type. Nested structs are added to the definitions and referenced.
+func (g *schemaGenerator) typeSchema(t reflect.Type) (map[string]interface{}, error) {
+	if t == durationType {
+		return map[string]interface{}{"type": "string", "pattern": durationPattern}, nil
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.typeSchema(t.Elem())
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}, nil
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "minimum": 0}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}, nil
+	case reflect.Slice, reflect.Array:
+		items, err := g.typeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "array", "items": items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %s in config schema", t.Key())
+		}
+		var values map[string]interface{}
+		if t.Elem().Kind() == reflect.String {
+			// yaml converts any scalar into a string: "memory: 1000" is valid
+			values = map[string]interface{}{"type": []string{"string", "number", "boolean"}}
+		} else {
+			var err error
+			if values, err = g.typeSchema(t.Elem()); err != nil {
+				return nil, err
+			}
+		}
+		return map[string]interface{}{"type": "object", "additionalProperties": values}, nil
+	case reflect.Struct:
+		name := t.Name()
+		if _, ok := g.definitions[name]; !ok {
+			// add a placeholder before generating to stop recursive types from looping
+			g.definitions[name] = nil
+			def, err := g.structSchema(t)
+			if err != nil {
+				delete(g.definitions, name)
+				return nil, err
+			}
+			g.definitions[name] = def
+		}
+		return map[string]interface{}{"$ref": "#/definitions/" + name}, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s in config schema", t)
+	}
+}
+
+// Generate the object schema for a struct including the constraints from the schema tags.
+func (g *schemaGenerator) structSchema(t reflect.Type) (map[string]interface{}, error) {
+	properties := make(map[string]interface{})
+	required := make([]string, 0)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := yamlFieldName(field)
+		if name == "-" {
+			continue
+		}
+		prop, err := g.typeSchema(field.Type)
+		if err != nil {
+			return nil, err
+		}
+		var isRequired bool
+		if isRequired, err = applySchemaTag(prop, field.Tag.Get("schema")); err != nil {
+			return nil, fmt.Errorf("field %s.%s: %v", t.Name(), field.Name, err)
+		}
+		if isRequired {
+			required = append(required, name)
+		}
+		properties[name] = prop
+	}
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema, nil
+}
+
+// The yaml key for a field: the tag name if set otherwise the lower cased field name.
+func yamlFieldName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	return name
+}
+
+// Add the constraints from the schema tag to the property schema, returns true if the field is required.
+func applySchemaTag(prop map[string]interface{}, tag string) (bool, error) {
+	var required bool
+	if tag == "" {
+		return required, nil
+	}
+	for _, option := range strings.Split(tag, ",") {
+		key, value := option, ""
+		if idx := strings.Index(option, "="); idx >= 0 {
+			key, value = option[:idx], option[idx+1:]
+		}
+		switch key {
+		case "required":
+			required = true
+		case "minimum", "maximum", "minItems", "maxItems":
+			number, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return false, fmt.Errorf("invalid schema tag value %s for %s", value, key)
+			}
+			prop[key] = number
+		default:
+			return false, fmt.Errorf("unknown schema tag option %s", key)
+		}
+	}
+	return required, nil
+}
@@ -0,0 +1,180 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package configs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/xeipuuv/gojsonschema"
+	"gopkg.in/yaml.v2"
+	"gotest.tools/assert"
+)
+
+// Validate the yaml document against the schema, returns the validation errors joined into one error.
+func validateSchema(t *testing.T, schema []byte, data string) error {
+	result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(schema), gojsonschema.NewGoLoader(yamlToJSONValue(t, data)))
+	assert.NilError(t, err, "schema validation could not be run")
+	if result.Valid() {
+		return nil
+	}
+	messages := make([]string, 0, len(result.Errors()))
+	for _, desc := range result.Errors() {
+		messages = append(messages, desc.String())
+	}
+	return errors.New(strings.Join(messages, "; "))
+}
+
+// Convert a yaml document into the generic JSON form used by the validator.
+func yamlToJSONValue(t *testing.T, data string) interface{} {
+	var raw interface{}
+	err := yaml.Unmarshal([]byte(data), &raw)
+	assert.NilError(t, err, "yaml unmarshal failed")
+	var jsonBytes []byte
+	jsonBytes, err = json.Marshal(convertYAMLValue(raw))
+	assert.NilError(t, err, "json marshal failed")
+	var value interface{}
+	err = json.Unmarshal(jsonBytes, &value)
+	assert.NilError(t, err, "json unmarshal failed")
+	return value
+}
+
+func convertYAMLValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{})
+		for key, val := range v {
+			converted[fmt.Sprintf("%v", key)] = convertYAMLValue(val)
+		}
+		return converted
+	case []interface{}:
+		for i, val := range v {
+			v[i] = convertYAMLValue(val)
+		}
+	}
+	return value
+}
+
+func TestGenerateJSONSchema(t *testing.T) {
+	data, err := GenerateJSONSchema()
+	assert.NilError(t, err, "schema generation failed")
+	var schema map[string]interface{}
+	err = json.Unmarshal(data, &schema)
+	assert.NilError(t, err, "generated schema is not valid json")
+	assert.Equal(t, schema["$schema"], jsonSchemaDraft, "unexpected schema draft")
+	definitions, ok := schema["definitions"].(map[string]interface{})
+	assert.Assert(t, ok, "definitions missing from schema")
+	for _, name := range []string{"PartitionConfig", "QueueConfig", "PlacementRule", "Filter", "Limit", "Resources", "NodeSortingPolicy", "PartitionPreemptionConfig"} {
+		_, ok = definitions[name].(map[string]interface{})
+		assert.Assert(t, ok, "definition %s missing from schema", name)
+	}
+	// checksum is never part of the yaml
+	properties := schema["properties"].(map[string]interface{})
+	_, ok = properties["checksum"]
+	assert.Assert(t, !ok, "checksum should not be part of the schema")
+
+	valid := `
+partitions:
+  - name: default
+    schedulingintervalms: 10
+    noderegistrationtimeout: 1m30s
+    placementrules:
+      - name: tag
+        value: namespace
+        create: true
+        parent:
+          name: fixed
+          value: root.namespaces
+        filter:
+          type: allow
+          users:
+            - test
+    limits:
+      - limit: partition limit
+        users:
+          - user1
+        maxresources:
+          memory: 1000
+          vcore: 10
+        maxapplications: 5
+    nodesortpolicy:
+      type: binpacking
+    queues:
+      - name: root
+        submitacl: '*'
+        properties:
+          application.sort.policy: fifo
+        queues:
+          - name: a
+            resources:
+              guaranteed:
+                memory: 100
+              max:
+                memory: 1000
+            maxapplications: 10
+            queues:
+              - name: a1
+`
+	// the config must load as a sanity check of the test data
+	_, err = LoadSchedulerConfigFromByteArray([]byte(valid))
+	assert.NilError(t, err, "valid config failed to load")
+	err = validateSchema(t, data, valid)
+	assert.NilError(t, err, "valid config failed schema validation")
+
+	var tests = []struct {
+		name   string
+		config string
+		errMsg string
+	}{
+		{"no partitions", "partitions: []", "Array must have at least 1 items"},
+		{"unknown field", "partitions:\n  - name: default\n    unknown: value", "Additional property unknown is not allowed"},
+		{"missing queue name", "partitions:\n  - name: default\n    queues:\n      - parent: true", "name is required"},
+		{"missing nested rule name", "partitions:\n  - name: default\n    placementrules:\n      - name: tag\n        parent:\n          value: x", "name is required"},
+		{"negative interval", "partitions:\n  - name: default\n    schedulingintervalms: -1", "Must be greater than or equal to 0"},
+		{"negative max apps", "partitions:\n  - name: default\n    queues:\n      - name: root\n        maxapplications: -1", "Must be greater than or equal to 0"},
+		{"wrong type", "partitions:\n  - name: default\n    preemption:\n      enabled: maybe", "Invalid type"},
+		{"duration as number", "partitions:\n  - name: default\n    noderegistrationtimeout: 30", "Invalid type"},
+		{"invalid duration", "partitions:\n  - name: default\n    noderegistrationtimeout: 30x", "Does not match pattern"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSchema(t, data, tt.config)
+			if err == nil {
+				t.Fatalf("expected config to fail schema validation")
+			}
+			assert.Assert(t, strings.Contains(err.Error(), tt.errMsg), "unexpected error: %v", err)
+		})
+	}
+}
+
+func TestApplySchemaTag(t *testing.T) {
+	prop := make(map[string]interface{})
+	required, err := applySchemaTag(prop, "required,minimum=1,maximum=10")
+	assert.NilError(t, err, "valid tag failed")
+	assert.Assert(t, required, "required not detected")
+	assert.Equal(t, prop["minimum"], int64(1), "minimum not set")
+	assert.Equal(t, prop["maximum"], int64(10), "maximum not set")
+	_, err = applySchemaTag(prop, "minimum=x")
+	assert.ErrorContains(t, err, "invalid schema tag value")
+	_, err = applySchemaTag(prop, "pattern=abc")
+	assert.ErrorContains(t, err, "unknown schema tag option")
+}
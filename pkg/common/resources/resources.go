@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -34,8 +35,9 @@ import (
 
 // const keys
 const (
-	MEMORY = "memory"
-	VCORE  = "vcore"
+	MEMORY  = "memory"
+	VCORE   = "vcore"
+	STORAGE = "storage"
 )
 
 type Resource struct {
@@ -52,6 +54,28 @@ func (q Quantity) string() string {
 // Never update value of Zero
 var Zero = NewResource()
 
+// Valid resource names are alphanumeric and may contain a dash.
+var resourceNameRegExp = regexp.MustCompile(`^[a-zA-Z0-9-]+$`)
+
+// Error returned when a resource quantity is negative.
+type NegativeQuantityError struct {
+	Name  string
+	Value int64
+}
+
+func (e *NegativeQuantityError) Error() string {
+	return fmt.Sprintf("negative quantity %d for resource %s", e.Value, e.Name)
+}
+
+// Error returned when a resource name contains invalid characters.
+type InvalidResourceNameError struct {
+	Name string
+}
+
+func (e *InvalidResourceNameError) Error() string {
+	return fmt.Sprintf("invalid resource name %q", e.Name)
+}
+
 func NewResource() *Resource {
 	return &Resource{Resources: make(map[string]Quantity)}
 }
@@ -71,6 +95,29 @@ func NewResourceFromMap(m map[string]Quantity) *Resource {
 	return &Resource{Resources: m}
 }
 
+// Create a new resource from the map with strict validation.
+// All names must be valid resource names and quantities must not be negative. The first violation, in name
+// order, is returned as a NegativeQuantityError or InvalidResourceNameError.
+// A nil or empty map returns an empty resource.
+func NewValidResourceFromMap(m map[string]int64) (*Resource, error) {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	res := NewResource()
+	for _, key := range keys {
+		if !resourceNameRegExp.MatchString(key) {
+			return nil, &InvalidResourceNameError{Name: key}
+		}
+		if m[key] < 0 {
+			return nil, &NegativeQuantityError{Name: key, Value: m[key]}
+		}
+		res.Resources[key] = Quantity(m[key])
+	}
+	return res, nil
+}
+
 // Create a new resource from a string.
 // The string must be a json marshalled si.Resource.
 func NewResourceFromString(str string) (*Resource, error) {
@@ -110,13 +157,17 @@ func (r *Resource) DAOString() string {
 	return "[]"
 }
 
-// Convert to a protobuf implementation
+// Convert to a protobuf implementation, this is the canonical conversion to an si.Resource.
+// Zero quantities are not converted: a missing type is treated as zero.
 // a nil resource passes back an empty proto object
 func (r *Resource) ToProto() *si.Resource {
 	proto := &si.Resource{}
 	proto.Resources = make(map[string]*si.Quantity)
 	if r != nil {
 		for k, v := range r.Resources {
+			if v == 0 {
+				continue
+			}
 			proto.Resources[k] = &si.Quantity{Value: int64(v)}
 		}
 	}
@@ -186,6 +237,46 @@ func (r *Resource) MultiplyTo(ratio float64) {
 	}
 }
 
+// Multiply each quantity of the resource by the factor set for its type returning a new resource.
+// Types without a factor set are multiplied by 1, factors for types not in the resource are ignored.
+// The result is rounded down to the nearest integer value after the multiplication.
+// Result is protected from overflow (positive and negative).
+// A nil resource returns a new empty resource (zero)
+func (r *Resource) MultiplyBy(factor map[string]float64) *Resource {
+	ret := NewResource()
+	if r == nil {
+		return ret
+	}
+	for k, v := range r.Resources {
+		if ratio, ok := factor[k]; ok {
+			ret.Resources[k] = mulValRatio(v, ratio)
+		} else {
+			ret.Resources[k] = v
+		}
+	}
+	return ret
+}
+
+// Divide each quantity of the resource by the factor set for its type returning a new resource.
+// This is the inverse of MultiplyBy: types without a factor set are divided by 1, factors for types not in the
+// resource are ignored. The result is rounded down to the nearest integer value after the division.
+// Result is protected from overflow (positive and negative), a zero factor overflows for a non zero quantity.
+// A nil resource returns a new empty resource (zero)
+func (r *Resource) DivideBy(factor map[string]float64) *Resource {
+	ret := NewResource()
+	if r == nil {
+		return ret
+	}
+	for k, v := range r.Resources {
+		if ratio, ok := factor[k]; ok {
+			ret.Resources[k] = divValRatio(v, ratio)
+		} else {
+			ret.Resources[k] = v
+		}
+	}
+	return ret
+}
+
 // Calculate how well the receiver fits in "fit"
 // - A score of 0 is a fit (similar to FitIn)
 // - The score is calculated only using resource type defined in the fit resource.
@@ -227,6 +318,26 @@ func (r *Resource) FitInScore(fit *Resource) float64 {
 	return score
 }
 
+// Calculate the largest fraction of the total used by the resource over all resource types in the total.
+// Resource types that are not set or zero in the total are ignored. A missing type in the receiver is
+// treated as zero. Fractions larger than 1 are returned if the receiver is larger than the total.
+// - A nil receiver or total gives back 0
+func (r *Resource) DominantFraction(total *Resource) float64 {
+	var fraction float64
+	if r == nil || total == nil {
+		return fraction
+	}
+	for key, totalVal := range total.Resources {
+		if totalVal <= 0 {
+			continue
+		}
+		if share := float64(r.Resources[key]) / float64(totalVal); share > fraction {
+			fraction = share
+		}
+	}
+	return fraction
+}
+
 // Wrapping safe calculators for the quantities of resources.
 // They will always return a valid int64. Logging if the calculator wrapped the value.
 // Returning the appropriate MaxInt64 or MinInt64 value.
@@ -306,6 +417,31 @@ func mulValRatio(value Quantity, ratio float64) Quantity {
 	return Quantity(result)
 }
 
+// Divide the value by the ratio, dividing by zero overflows for a non zero value.
+func divValRatio(value Quantity, ratio float64) Quantity {
+	// optimise the zero case (often hit with zero resource)
+	if value == 0 {
+		return 0
+	}
+	result := float64(value) / ratio
+	// protect against positive integer overflow
+	if result > math.MaxInt64 {
+		log.Logger().Warn("Division result positive overflow",
+			zap.Float64("value", float64(value)),
+			zap.Float64("ratio", ratio))
+		return math.MaxInt64
+	}
+	// protect against negative integer overflow
+	if result < math.MinInt64 {
+		log.Logger().Warn("Division result negative overflow",
+			zap.Float64("value", float64(value)),
+			zap.Float64("ratio", ratio))
+		return math.MinInt64
+	}
+	// not wrapped normal case
+	return Quantity(result)
+}
+
 // Operations on resources: the operations leave the passed in resources unchanged.
 // Resources are sparse objects in all cases an undefined quantity is assumed zero (0).
 // All operations must be nil safe.
@@ -707,6 +843,18 @@ func StrictlyGreaterThanOrEquals(larger, smaller *Resource) bool {
 	return true
 }
 
+// Return true if each quantity in the receiver is larger than or equal to the quantity in other.
+// A missing resource type is treated as zero, a nil resource is treated as an empty resource (zero).
+func (r *Resource) GreaterThanOrEqual(other *Resource) bool {
+	return StrictlyGreaterThanOrEquals(r, other)
+}
+
+// Return true if each quantity in the receiver is smaller than or equal to the quantity in other.
+// A missing resource type is treated as zero, a nil resource is treated as an empty resource (zero).
+func (r *Resource) LessThanOrEqual(other *Resource) bool {
+	return StrictlyGreaterThanOrEquals(other, r)
+}
+
 // Have at least one quantity > 0, and no quantities < 0
 // A nil resource is not strictly greater than zero.
 func StrictlyGreaterThanZero(larger *Resource) bool {
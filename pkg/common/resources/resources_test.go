@@ -49,6 +49,37 @@ func TestNewResourceFromConf(t *testing.T) {
 	}
 }
 
+func TestNewValidResourceFromMap(t *testing.T) {
+	// nil and empty maps give an empty resource
+	res, err := NewValidResourceFromMap(nil)
+	assert.NilError(t, err, "nil map should not return an error")
+	assert.Equal(t, len(res.Resources), 0, "nil map should return an empty resource")
+	res, err = NewValidResourceFromMap(map[string]int64{})
+	assert.NilError(t, err, "empty map should not return an error")
+	assert.Equal(t, len(res.Resources), 0, "empty map should return an empty resource")
+
+	res, err = NewValidResourceFromMap(map[string]int64{"zero": 0, "first-1": 10})
+	assert.NilError(t, err, "valid map should not return an error")
+	assert.Assert(t, reflect.DeepEqual(res.Resources, map[string]Quantity{"zero": 0, "first-1": 10}), "unexpected resource: %v", res)
+
+	// negative value
+	res, err = NewValidResourceFromMap(map[string]int64{"first": 1, "second": -1})
+	assert.Assert(t, res == nil, "negative value should not return a resource")
+	negErr, ok := err.(*NegativeQuantityError)
+	assert.Assert(t, ok, "expected a negative quantity error, got %v", err)
+	assert.Equal(t, negErr.Name, "second", "unexpected resource name in error")
+	assert.Equal(t, negErr.Value, int64(-1), "unexpected value in error")
+
+	// invalid names
+	for _, name := range []string{"", "nvidia.com/gpu", "under_score", "space name"} {
+		res, err = NewValidResourceFromMap(map[string]int64{name: 1})
+		assert.Assert(t, res == nil, "invalid name %q should not return a resource", name)
+		nameErr, ok := err.(*InvalidResourceNameError)
+		assert.Assert(t, ok, "expected an invalid name error for %q, got %v", name, err)
+		assert.Equal(t, nameErr.Name, name, "unexpected resource name in error")
+	}
+}
+
 func TestCloneNil(t *testing.T) {
 	// make sure we're nil safe IDE will complain about the non nil check
 	defer func() {
@@ -508,18 +539,39 @@ func TestResourceProto(t *testing.T) {
 		t.Errorf("resource to proto and back to resource does not give same resources: original %v after %v", res1, res2)
 	}
 
-	// resource with zero set values to proto
+	// resource with zero set values to proto: zero values are skipped
 	res1 = NewResourceFromMap(map[string]Quantity{"first": 5, "second": 0, "third": -5})
 	toProto = res1.ToProto()
-	if len(toProto.Resources) != 3 {
+	if len(toProto.Resources) != 2 {
 		t.Fatalf("resource to proto conversion failed: %v", toProto)
 	}
+	if _, ok := toProto.Resources["second"]; ok {
+		t.Errorf("zero value should not have been converted: %v", toProto)
+	}
 	// convert back to resource
 	res2 = NewResourceFromProto(toProto)
-	// res1 and res2 content must be equal
-	if !reflect.DeepEqual(res1.Resources, res2.Resources) {
+	// res1 and res2 must be equal, a missing type is zero
+	if !Equals(res1, res2) {
 		t.Errorf("resource to proto and back to resource does not give same resources: original %v after %v", res1, res2)
 	}
+
+	// only zero values
+	res1 = NewResourceFromMap(map[string]Quantity{"first": 0})
+	toProto = res1.ToProto()
+	if len(toProto.Resources) != 0 {
+		t.Fatalf("zero resource to proto conversion failed: %v", toProto)
+	}
+	res2 = NewResourceFromProto(toProto)
+	if !Equals(res1, res2) || !IsZero(res2) {
+		t.Errorf("zero resource to proto and back to resource does not give same resources: original %v after %v", res1, res2)
+	}
+
+	// nil resource round trip gives an empty resource
+	var nilRes *Resource
+	res2 = NewResourceFromProto(nilRes.ToProto())
+	if res2 == nil || len(res2.Resources) != 0 {
+		t.Errorf("nil resource to proto and back should be empty: %v", res2)
+	}
 }
 
 func TestMultiplyBy(t *testing.T) {
@@ -569,6 +621,59 @@ func TestMultiplyBy(t *testing.T) {
 	}
 }
 
+func TestMultiplyByFactor(t *testing.T) {
+	base := NewResourceFromMap(map[string]Quantity{"memory": 1000, "vcore": 10})
+	tests := []struct {
+		name   string
+		factor map[string]float64
+		want   map[string]Quantity
+	}{
+		{"AllSame", map[string]float64{"memory": 0.3, "vcore": 0.3}, map[string]Quantity{"memory": 300, "vcore": 3}},
+		{"Mixed", map[string]float64{"memory": 0.5, "vcore": 0.25}, map[string]Quantity{"memory": 500, "vcore": 2}},
+		{"MissingKey", map[string]float64{"memory": 0.5}, map[string]Quantity{"memory": 500, "vcore": 10}},
+		{"UnknownKey", map[string]float64{"gpu": 0.5}, map[string]Quantity{"memory": 1000, "vcore": 10}},
+		{"NilFactor", nil, map[string]Quantity{"memory": 1000, "vcore": 10}},
+		{"Zero", map[string]float64{"memory": 0, "vcore": 1}, map[string]Quantity{"memory": 0, "vcore": 10}},
+		{"GreaterThanOne", map[string]float64{"memory": 2.5, "vcore": 1.5}, map[string]Quantity{"memory": 2500, "vcore": 15}},
+	}
+	for _, tt := range tests {
+		result := base.MultiplyBy(tt.factor)
+		assert.DeepEqual(t, result.Resources, tt.want)
+	}
+	assert.Equal(t, base.Resources["memory"], Quantity(1000), "base resource should not have changed")
+	var nilRes *Resource
+	result := nilRes.MultiplyBy(map[string]float64{"memory": 0.5})
+	assert.Assert(t, result != nil && len(result.Resources) == 0, "nil resource did not return zero resource: %v", result)
+}
+
+func TestDivideByFactor(t *testing.T) {
+	base := NewResourceFromMap(map[string]Quantity{"memory": 300, "vcore": 3})
+	tests := []struct {
+		name   string
+		factor map[string]float64
+		want   map[string]Quantity
+	}{
+		{"AllSame", map[string]float64{"memory": 0.3, "vcore": 0.3}, map[string]Quantity{"memory": 1000, "vcore": 10}},
+		{"Mixed", map[string]float64{"memory": 0.5, "vcore": 2}, map[string]Quantity{"memory": 600, "vcore": 1}},
+		{"MissingKey", map[string]float64{"memory": 3}, map[string]Quantity{"memory": 100, "vcore": 3}},
+		{"Zero", map[string]float64{"memory": 0}, map[string]Quantity{"memory": math.MaxInt64, "vcore": 3}},
+		{"GreaterThanOne", map[string]float64{"memory": 1.5, "vcore": 3}, map[string]Quantity{"memory": 200, "vcore": 1}},
+	}
+	for _, tt := range tests {
+		result := base.DivideBy(tt.factor)
+		assert.DeepEqual(t, result.Resources, tt.want)
+	}
+	// inverse of multiply if nothing is lost in rounding
+	factor := map[string]float64{"memory": 0.5, "vcore": 4}
+	assert.Assert(t, Equals(base.MultiplyBy(factor).DivideBy(factor), base), "divide should be the inverse of multiply")
+	// zero quantity does not overflow
+	result := NewResourceFromMap(map[string]Quantity{"memory": 0}).DivideBy(map[string]float64{"memory": 0})
+	assert.Equal(t, result.Resources["memory"], Quantity(0), "zero quantity should stay zero")
+	var nilRes *Resource
+	result = nilRes.DivideBy(factor)
+	assert.Assert(t, result != nil && len(result.Resources) == 0, "nil resource did not return zero resource: %v", result)
+}
+
 func TestMultiply(t *testing.T) {
 	// simple case (nil checks)
 	result := Multiply(nil, 0)
@@ -1552,3 +1657,49 @@ func TestHasNegativeValue(t *testing.T) {
 		})
 	}
 }
+
+func TestGreaterLessThanOrEqual(t *testing.T) {
+	// nil resources are treated as zero
+	var nilRes *Resource
+	assert.Assert(t, nilRes.GreaterThanOrEqual(nil), "nil resources should be greater or equal")
+	assert.Assert(t, nilRes.LessThanOrEqual(nil), "nil resources should be less or equal")
+	res := NewResourceFromMap(map[string]Quantity{"first": 10, "second": 5})
+	assert.Assert(t, res.GreaterThanOrEqual(nil), "resource should be greater or equal than nil")
+	assert.Assert(t, !res.LessThanOrEqual(nil), "resource should not be less or equal than nil")
+	assert.Assert(t, nilRes.LessThanOrEqual(res), "nil should be less or equal than resource")
+
+	// equal values
+	other := NewResourceFromMap(map[string]Quantity{"first": 10, "second": 5})
+	assert.Assert(t, res.GreaterThanOrEqual(other), "equal resources should be greater or equal")
+	assert.Assert(t, res.LessThanOrEqual(other), "equal resources should be less or equal")
+
+	// partially greater: one type larger the rest equal or missing
+	other = NewResourceFromMap(map[string]Quantity{"first": 5})
+	assert.Assert(t, res.GreaterThanOrEqual(other), "partially greater resource should be greater or equal")
+	assert.Assert(t, !res.LessThanOrEqual(other), "partially greater resource should not be less or equal")
+
+	// partially less: one type smaller, one type larger
+	other = NewResourceFromMap(map[string]Quantity{"first": 5, "second": 10})
+	assert.Assert(t, !res.GreaterThanOrEqual(other), "mixed resource should not be greater or equal")
+	assert.Assert(t, !res.LessThanOrEqual(other), "mixed resource should not be less or equal")
+
+	// missing type in the receiver is treated as zero
+	other = NewResourceFromMap(map[string]Quantity{"first": 10, "second": 5, "third": 1})
+	assert.Assert(t, !res.GreaterThanOrEqual(other), "missing type should be treated as zero")
+	assert.Assert(t, res.LessThanOrEqual(other), "missing type should be treated as zero")
+}
+
+func TestDominantFraction(t *testing.T) {
+	var nilRes *Resource
+	total := NewResourceFromMap(map[string]Quantity{"first": 10, "second": 100, "zero": 0})
+	assert.Equal(t, nilRes.DominantFraction(total), 0.0, "nil resource should have a zero fraction")
+	assert.Equal(t, total.DominantFraction(nil), 0.0, "nil total should give a zero fraction")
+	assert.Equal(t, NewResource().DominantFraction(total), 0.0, "empty resource should have a zero fraction")
+
+	res := NewResourceFromMap(map[string]Quantity{"first": 5, "second": 10})
+	assert.Equal(t, res.DominantFraction(total), 0.5, "unexpected dominant fraction")
+	res = NewResourceFromMap(map[string]Quantity{"second": 100, "zero": 5})
+	assert.Equal(t, res.DominantFraction(total), 1.0, "unexpected dominant fraction, missing and zero types")
+	res = NewResourceFromMap(map[string]Quantity{"first": 20, "unknown": 5})
+	assert.Equal(t, res.DominantFraction(total), 2.0, "unexpected dominant fraction above total")
+}
@@ -0,0 +1,81 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package common
+
+// Fixed size ring buffer, the oldest entry is overwritten first when the buffer is full.
+// The storage grows with the entries added up to the size of the buffer.
+// Not locked, the owner must protect access.
+type RingBuffer struct {
+	entries []interface{}
+	size    int
+	start   int // index of the oldest entry once the buffer is full
+}
+
+func NewRingBuffer(size int) *RingBuffer {
+	if size < 0 {
+		size = 0
+	}
+	return &RingBuffer{
+		size: size,
+	}
+}
+
+// Add the entry to the buffer, evicting the oldest entry if the buffer is full.
+// A buffer with a size of 0 does not keep any entries.
+func (b *RingBuffer) Add(entry interface{}) {
+	if b.size == 0 {
+		return
+	}
+	if len(b.entries) < b.size {
+		b.entries = append(b.entries, entry)
+		return
+	}
+	b.entries[b.start] = entry
+	b.start = (b.start + 1) % b.size
+}
+
+// Return the number of entries in the buffer.
+func (b *RingBuffer) Len() int {
+	return len(b.entries)
+}
+
+// Return the maximum number of entries the buffer keeps.
+func (b *RingBuffer) Size() int {
+	return b.size
+}
+
+// Return the entry at the index, 0 is the oldest entry. The index must be smaller than Len().
+func (b *RingBuffer) Get(index int) interface{} {
+	return b.entries[(b.start+index)%len(b.entries)]
+}
+
+// Return the newest entry, nil if the buffer is empty.
+func (b *RingBuffer) Last() interface{} {
+	if len(b.entries) == 0 {
+		return nil
+	}
+	return b.Get(len(b.entries) - 1)
+}
+
+// Return a copy of the entries, oldest entry first.
+func (b *RingBuffer) Entries() []interface{} {
+	entries := make([]interface{}, 0, len(b.entries))
+	entries = append(entries, b.entries[b.start:]...)
+	return append(entries, b.entries[:b.start]...)
+}
@@ -0,0 +1,54 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestRingBuffer(t *testing.T) {
+	buffer := NewRingBuffer(3)
+	assert.Equal(t, buffer.Len(), 0, "new buffer should be empty")
+	assert.Equal(t, buffer.Size(), 3, "unexpected buffer size")
+	assert.Assert(t, buffer.Last() == nil, "empty buffer should not have a last entry")
+	assert.Equal(t, len(buffer.Entries()), 0, "empty buffer should not return entries")
+
+	for i := 0; i < 2; i++ {
+		buffer.Add(i)
+	}
+	assert.Equal(t, buffer.Len(), 2, "unexpected number of entries")
+	assert.DeepEqual(t, buffer.Entries(), []interface{}{0, 1})
+	assert.Equal(t, buffer.Last(), 1, "unexpected last entry")
+
+	// the oldest entries are overwritten
+	for i := 2; i < 5; i++ {
+		buffer.Add(i)
+	}
+	assert.Equal(t, buffer.Len(), 3, "buffer should be capped")
+	assert.DeepEqual(t, buffer.Entries(), []interface{}{2, 3, 4})
+	assert.Equal(t, buffer.Get(0), 2, "unexpected oldest entry")
+	assert.Equal(t, buffer.Last(), 4, "unexpected last entry")
+
+	// a zero size buffer keeps nothing
+	empty := NewRingBuffer(0)
+	empty.Add(1)
+	assert.Equal(t, empty.Len(), 0, "zero size buffer should not keep entries")
+}
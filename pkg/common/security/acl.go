@@ -21,6 +21,7 @@ package security
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 
 	"go.uber.org/zap"
@@ -159,3 +160,25 @@ func (a ACL) CheckAccess(userObj UserGroup) bool {
 	}
 	return false
 }
+
+// Convert the ACL back into the string form used in the configuration.
+// Users and groups are sorted alphabetically, the original order is not retained.
+func (a ACL) String() string {
+	if a.allAllowed {
+		return WildCard
+	}
+	users := make([]string, 0, len(a.users))
+	for user := range a.users {
+		users = append(users, user)
+	}
+	sort.Strings(users)
+	groups := make([]string, 0, len(a.groups))
+	for group := range a.groups {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	if len(groups) == 0 {
+		return strings.Join(users, Separator)
+	}
+	return strings.Join(users, Separator) + Space + strings.Join(groups, Separator)
+}
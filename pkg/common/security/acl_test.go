@@ -135,3 +135,25 @@ func TestACLAccess(t *testing.T) {
 	user = UserGroup{User: "user1", Groups: []string{"group1"}}
 	assert.Assert(t, !acl.CheckAccess(user), "user1/group1, empty ACL always deny")
 }
+
+func TestACLString(t *testing.T) {
+	tests := map[string]string{
+		"":                          "",
+		"*":                         "*",
+		"user1":                     "user1",
+		"user2,user1":               "user1,user2",
+		"user1 group2,group1":       "user1 group1,group2",
+		" group1":                   " group1",
+		"user1,user2 group1,group2": "user1,user2 group1,group2",
+	}
+	for aclStr, expected := range tests {
+		acl, err := NewACL(aclStr)
+		assert.NilError(t, err, "parsing failed for string: '%s'", aclStr)
+		assert.Equal(t, acl.String(), expected, "unexpected string for ACL: '%s'", aclStr)
+		// the string form must parse into the same ACL
+		var parsed ACL
+		parsed, err = NewACL(acl.String())
+		assert.NilError(t, err, "parsing failed for converted string: '%s'", acl.String())
+		assert.Equal(t, parsed.String(), expected, "round trip failed for ACL: '%s'", aclStr)
+	}
+}
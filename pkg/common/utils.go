@@ -19,10 +19,13 @@
 package common
 
 import (
+	"crypto/rand"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	uuid "github.com/satori/go.uuid"
@@ -74,10 +77,66 @@ func WaitFor(interval time.Duration, timeout time.Duration, condition func() boo
 	}
 }
 
-// Generate a new uuid. The chance that we generate a collision is really small.
+// Source of randomness for the UUID generation, only replaced in tests.
+var uuidSource io.Reader = rand.Reader
+
+// Generate a new version 4 uuid using crypto/rand. The chance that we generate a collision is really small.
 // As long as we check the UUID before we communicate it back to the RM we can still replace it without a problem.
 func GetNewUUID() string {
-	return uuid.NewV4().String()
+	var u uuid.UUID
+	if _, err := io.ReadFull(uuidSource, u[:]); err != nil {
+		log.Logger().Warn("failed to read random data for UUID",
+			zap.Error(err))
+	}
+	u.SetVersion(uuid.V4)
+	u.SetVariant(uuid.VariantRFC4122)
+	return u.String()
+}
+
+// Replace the source of randomness for the UUID generation and return the previous source.
+// Visible for tests
+func SetUUIDSource(source io.Reader) io.Reader {
+	previous := uuidSource
+	uuidSource = source
+	return previous
+}
+
+// UUIDCache remembers generated UUIDs for a limited time to allow fast duplicate detection.
+type UUIDCache struct {
+	ttl       time.Duration
+	entries   map[string]time.Time
+	lastPrune time.Time
+
+	sync.Mutex
+}
+
+func NewUUIDCache(ttl time.Duration) *UUIDCache {
+	return &UUIDCache{
+		ttl:       ttl,
+		entries:   make(map[string]time.Time),
+		lastPrune: time.Now(),
+	}
+}
+
+// Add the UUID to the cache. Returns false if the UUID was already added within the TTL.
+// Expired entries are removed from the cache at most once per TTL period while adding.
+func (c *UUIDCache) Add(id string) bool {
+	c.Lock()
+	defer c.Unlock()
+	now := time.Now()
+	if added, ok := c.entries[id]; ok && now.Sub(added) < c.ttl {
+		return false
+	}
+	if now.Sub(c.lastPrune) >= c.ttl {
+		for key, added := range c.entries {
+			if now.Sub(added) >= c.ttl {
+				delete(c.entries, key)
+			}
+		}
+		c.lastPrune = now
+	}
+	c.entries[id] = now
+	return true
 }
 
 func GetBoolEnvVar(key string, defaultVal bool) bool {
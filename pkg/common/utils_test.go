@@ -21,10 +21,22 @@ package common
 import (
 	"os"
 	"testing"
+	"time"
 
+	uuid "github.com/satori/go.uuid"
 	"gotest.tools/assert"
 )
 
+// reader that always returns the same bytes: simulates a source without entropy
+type fixedReader struct{}
+
+func (fixedReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0x42
+	}
+	return len(p), nil
+}
+
 func TestGetNormalizedPartitionName(t *testing.T) {
 	tests := []struct {
 		partitionName string
@@ -95,3 +107,46 @@ func TestGetBoolEnvVar(t *testing.T) {
 		})
 	}
 }
+
+func TestGetNewUUID(t *testing.T) {
+	first := GetNewUUID()
+	parsed, err := uuid.FromString(first)
+	assert.NilError(t, err, "generated UUID is not valid")
+	assert.Equal(t, parsed.Version(), byte(uuid.V4), "unexpected UUID version")
+	assert.Equal(t, parsed.Variant(), byte(uuid.VariantRFC4122), "unexpected UUID variant")
+	assert.Assert(t, first != GetNewUUID(), "UUIDs should not have been the same")
+
+	// no entropy: all UUIDs are the same
+	previous := SetUUIDSource(fixedReader{})
+	defer SetUUIDSource(previous)
+	first = GetNewUUID()
+	assert.Equal(t, first, GetNewUUID(), "fixed source should generate the same UUID")
+	parsed, err = uuid.FromString(first)
+	assert.NilError(t, err, "generated UUID is not valid")
+	assert.Equal(t, parsed.Version(), byte(uuid.V4), "unexpected UUID version")
+}
+
+func TestUUIDCache(t *testing.T) {
+	cache := NewUUIDCache(50 * time.Millisecond)
+	assert.Assert(t, cache.Add("uuid-1"), "new UUID should have been added")
+	assert.Assert(t, !cache.Add("uuid-1"), "duplicate UUID should have been detected")
+	assert.Assert(t, cache.Add("uuid-2"), "new UUID should have been added")
+	time.Sleep(60 * time.Millisecond)
+	// expired entries can be added again and are pruned
+	assert.Assert(t, cache.Add("uuid-1"), "expired UUID should have been added")
+	assert.Equal(t, len(cache.entries), 1, "expired entries should have been pruned")
+	assert.Assert(t, !cache.Add("uuid-1"), "duplicate UUID should have been detected")
+}
+
+func BenchmarkGetNewUUID(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		GetNewUUID()
+	}
+}
+
+// the library generator for comparison
+func BenchmarkGetNewUUIDLibrary(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = uuid.NewV4().String()
+	}
+}
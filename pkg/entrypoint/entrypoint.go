@@ -19,6 +19,10 @@
 package entrypoint
 
 import (
+	"os"
+
+	"go.uber.org/zap"
+
 	"github.com/apache/incubator-yunikorn-core/pkg/events"
 	"github.com/apache/incubator-yunikorn-core/pkg/handler"
 	"github.com/apache/incubator-yunikorn-core/pkg/log"
@@ -61,6 +65,17 @@ func StartAllServicesWithManualScheduler() *ServiceContext {
 }
 
 func startAllServicesWithParameters(opts startupOptions) *ServiceContext {
+	if path, ok := os.LookupEnv(log.AuditLogPathEnv); ok && path != "" {
+		if err := log.InitAuditLog(path); err != nil {
+			log.Logger().Warn("failed to initialise audit log",
+				zap.String("path", path),
+				zap.Error(err))
+		} else {
+			log.Logger().Info("audit log initialised",
+				zap.String("path", path))
+		}
+	}
+
 	var eventCache *events.EventCache
 	var eventPublisher events.EventPublisher
 	if opts.eventCacheEnabled {
@@ -0,0 +1,122 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package log
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// environment variable that sets the file the audit log is written to
+	AuditLogPathEnv = "AUDIT_LOG_PATH"
+
+	// audit event types
+	AuditACLDenied    = "ACLDenied"
+	AuditNodeRejected = "NodeRejected"
+	AuditAllocation   = "Allocation"
+	AuditForceRemoved = "ForceRemoved"
+
+	auditTimeField  = "time"
+	auditEventField = "event"
+)
+
+// AuditLogger logs scheduling events with security implications.
+// The audit log is a separate stream from the main log.
+type AuditLogger interface {
+	// Log an audit event with the fields passed in.
+	Audit(event string, fields map[string]string)
+}
+
+var auditLock sync.RWMutex
+var auditLogger AuditLogger = nopAuditLogger{}
+
+// Get the audit logger. A no-op logger is returned if no audit logger has been set.
+func AuditLog() AuditLogger {
+	auditLock.RLock()
+	defer auditLock.RUnlock()
+	return auditLogger
+}
+
+// Replace the audit logger, a nil logger turns off audit logging.
+func SetAuditLogger(logger AuditLogger) {
+	auditLock.Lock()
+	defer auditLock.Unlock()
+	if logger == nil {
+		logger = nopAuditLogger{}
+	}
+	auditLogger = logger
+}
+
+// Set up the audit log to write to the file specified. The file is created if it does not exist
+// and new events are appended.
+func InitAuditLog(path string) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+	if err != nil {
+		return err
+	}
+	SetAuditLogger(NewAuditLogger(file))
+	return nil
+}
+
+// Create an audit logger that writes each event as a single line of JSON to the writer.
+func NewAuditLogger(writer io.Writer) AuditLogger {
+	return &jsonAuditLogger{
+		writer: writer,
+	}
+}
+
+type jsonAuditLogger struct {
+	writer io.Writer
+
+	sync.Mutex
+}
+
+// The event type and time are added to the fields. Fields passed in with the same key are overwritten.
+func (l *jsonAuditLogger) Audit(event string, fields map[string]string) {
+	entry := make(map[string]string, len(fields)+2)
+	for key, value := range fields {
+		entry[key] = value
+	}
+	entry[auditTimeField] = time.Now().Format(time.RFC3339Nano)
+	entry[auditEventField] = event
+	line, err := json.Marshal(entry)
+	if err != nil {
+		Logger().Warn("failed to marshal audit event",
+			zap.String("event", event),
+			zap.Error(err))
+		return
+	}
+	l.Lock()
+	defer l.Unlock()
+	if _, err = l.writer.Write(append(line, '\n')); err != nil {
+		Logger().Warn("failed to write audit event",
+			zap.String("event", event),
+			zap.Error(err))
+	}
+}
+
+type nopAuditLogger struct{}
+
+func (nopAuditLogger) Audit(string, map[string]string) {}
@@ -0,0 +1,84 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestAuditLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewAuditLogger(&buf)
+	logger.Audit(AuditAllocation, map[string]string{"applicationID": "app-1", "event": "overwritten"})
+	logger.Audit(AuditACLDenied, nil)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, len(lines), 2, "expected one line per event")
+	var entry map[string]string
+	err := json.Unmarshal([]byte(lines[0]), &entry)
+	assert.NilError(t, err, "audit line is not valid JSON")
+	assert.Equal(t, entry["event"], AuditAllocation, "event type not set")
+	assert.Equal(t, entry["applicationID"], "app-1", "field not set")
+	_, err = time.Parse(time.RFC3339Nano, entry["time"])
+	assert.NilError(t, err, "time not set correctly")
+	err = json.Unmarshal([]byte(lines[1]), &entry)
+	assert.NilError(t, err, "audit line is not valid JSON")
+	assert.Equal(t, entry["event"], AuditACLDenied, "event type not set")
+}
+
+func TestSetAuditLogger(t *testing.T) {
+	defer SetAuditLogger(nil)
+	// default is a no-op logger
+	_, ok := AuditLog().(nopAuditLogger)
+	assert.Assert(t, ok, "default audit logger should be a no-op logger")
+
+	var buf bytes.Buffer
+	SetAuditLogger(NewAuditLogger(&buf))
+	AuditLog().Audit(AuditNodeRejected, map[string]string{"nodeID": "node-1"})
+	assert.Assert(t, strings.Contains(buf.String(), `"nodeID":"node-1"`), "event not written to the set logger")
+
+	SetAuditLogger(nil)
+	_, ok = AuditLog().(nopAuditLogger)
+	assert.Assert(t, ok, "nil should reset the audit logger to a no-op logger")
+}
+
+func TestInitAuditLog(t *testing.T) {
+	defer SetAuditLogger(nil)
+	dir, err := ioutil.TempDir("", "audit")
+	assert.NilError(t, err, "failed to create temp dir")
+	path := filepath.Join(dir, "audit.log")
+	err = InitAuditLog(path)
+	assert.NilError(t, err, "audit log init failed")
+	AuditLog().Audit(AuditAllocation, map[string]string{"uuid": "uuid-1"})
+	var content []byte
+	content, err = ioutil.ReadFile(path)
+	assert.NilError(t, err, "failed to read audit log")
+	assert.Assert(t, strings.Contains(string(content), `"uuid":"uuid-1"`), "event not written to the audit log file")
+
+	err = InitAuditLog(filepath.Join(dir, "missing", "audit.log"))
+	assert.Assert(t, err != nil, "audit log in a missing directory should have failed")
+}
@@ -28,6 +28,8 @@ const (
 	Namespace = "yunikorn"
 	// SchedulerSubsystem - subsystem name used by scheduler
 	SchedulerSubsystem = "scheduler"
+	// AppSubsystem - subsystem name used by application metrics
+	AppSubsystem = "app"
 	// EventSubsystem - subsystem name used by event cache
 	EventSubsystem = "event"
 	// replacement of invalid byte for prometheus metric names
@@ -71,6 +73,32 @@ type CoreSchedulerMetrics interface {
 	IncReleasedContainer()
 	AddReleasedContainers(value int)
 
+	// Metrics Ops related to scheduling cycles
+	IncSchedulingCycle(partition string)
+	getSchedulingCycles(partition string) (int, error)
+
+	// Metrics Ops related to quota violations
+	SetQuotaViolations(partition string, value int)
+	getQuotaViolations(partition string) (int, error)
+
+	// Metrics Ops related to allocation recovery
+	SetRecoveredAllocations(partition string, value int)
+	getRecoveredAllocations(partition string) (int, error)
+	SetFailedRecoveries(partition string, value int)
+	getFailedRecoveries(partition string) (int, error)
+
+	// Metrics Ops related to the scheduler backlog
+	SetBacklogAsks(partition string, value int)
+	getBacklogAsks(partition string) (int, error)
+
+	// Metrics Ops related to node registration timeouts
+	IncNodeRegistrationTimeout()
+	getNodeRegistrationTimeouts() (int, error)
+
+	// Metrics Ops related to compacted allocations
+	AddCompactedAllocations(value int)
+	getCompactedAllocations() (int, error)
+
 	// Metrics Ops related to TotalApplicationsAdded
 	IncTotalApplicationsAdded()
 	AddTotalApplicationsAdded(value int)
@@ -114,6 +142,7 @@ type CoreSchedulerMetrics interface {
 	ObserveNodeSortingLatency(start time.Time)
 	ObserveAppSortingLatency(start time.Time)
 	ObserveQueueSortingLatency(start time.Time)
+	ObserveAppAllocationLatency(queueName string, latency time.Duration)
 }
 
 type CoreEventMetrics interface {
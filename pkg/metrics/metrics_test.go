@@ -56,3 +56,77 @@ func generateRandomString(len int) string {
 	}
 	return string(randomBytes)
 }
+
+func TestSchedulingCycles(t *testing.T) {
+	sm := GetSchedulerMetrics()
+	before, err := sm.getSchedulingCycles("cycles-test")
+	assert.NilError(t, err, "failed to read scheduling cycles")
+	sm.IncSchedulingCycle("cycles-test")
+	sm.IncSchedulingCycle("cycles-test")
+	sm.IncSchedulingCycle("other")
+	var after int
+	after, err = sm.getSchedulingCycles("cycles-test")
+	assert.NilError(t, err, "failed to read scheduling cycles")
+	assert.Equal(t, after-before, 2, "scheduling cycles not counted per partition")
+}
+
+func TestQuotaViolations(t *testing.T) {
+	sm := GetSchedulerMetrics()
+	sm.SetQuotaViolations("quota-test", 3)
+	sm.SetQuotaViolations("other", 1)
+	violations, err := sm.getQuotaViolations("quota-test")
+	assert.NilError(t, err, "failed to read quota violations")
+	assert.Equal(t, violations, 3, "quota violations not set per partition")
+	sm.SetQuotaViolations("quota-test", 0)
+	violations, err = sm.getQuotaViolations("quota-test")
+	assert.NilError(t, err, "failed to read quota violations")
+	assert.Equal(t, violations, 0, "quota violations not reset")
+}
+
+func TestRecoveryCounters(t *testing.T) {
+	sm := GetSchedulerMetrics()
+	sm.SetRecoveredAllocations("recovery-test", 5)
+	sm.SetFailedRecoveries("recovery-test", 2)
+	recovered, err := sm.getRecoveredAllocations("recovery-test")
+	assert.NilError(t, err, "failed to read recovered allocations")
+	assert.Equal(t, recovered, 5, "recovered allocations not set")
+	var failed int
+	failed, err = sm.getFailedRecoveries("recovery-test")
+	assert.NilError(t, err, "failed to read failed recoveries")
+	assert.Equal(t, failed, 2, "failed recoveries not set")
+	failed, err = sm.getFailedRecoveries("other")
+	assert.NilError(t, err, "failed to read failed recoveries")
+	assert.Equal(t, failed, 0, "failed recoveries should be set per partition")
+}
+
+func TestBacklogAsks(t *testing.T) {
+	sm := GetSchedulerMetrics()
+	sm.SetBacklogAsks("backlog-test", 4)
+	asks, err := sm.getBacklogAsks("backlog-test")
+	assert.NilError(t, err, "failed to read backlog asks")
+	assert.Equal(t, asks, 4, "backlog asks not set")
+	asks, err = sm.getBacklogAsks("other")
+	assert.NilError(t, err, "failed to read backlog asks")
+	assert.Equal(t, asks, 0, "backlog asks should be set per partition")
+}
+
+func TestCompactedAllocations(t *testing.T) {
+	sm := GetSchedulerMetrics()
+	before, err := sm.getCompactedAllocations()
+	assert.NilError(t, err, "failed to read compacted allocations")
+	sm.AddCompactedAllocations(3)
+	after, err := sm.getCompactedAllocations()
+	assert.NilError(t, err, "failed to read compacted allocations")
+	assert.Equal(t, after-before, 3, "compacted allocations not counted")
+}
+
+func TestNodeRegistrationTimeouts(t *testing.T) {
+	sm := GetSchedulerMetrics()
+	before, err := sm.getNodeRegistrationTimeouts()
+	assert.NilError(t, err, "failed to read node registration timeouts")
+	sm.IncNodeRegistrationTimeout()
+	var after int
+	after, err = sm.getNodeRegistrationTimeouts()
+	assert.NilError(t, err, "failed to read node registration timeouts")
+	assert.Equal(t, after-before, 1, "node registration timeout not counted")
+}
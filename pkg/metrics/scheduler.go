@@ -51,6 +51,13 @@ type SchedulerMetrics struct {
 	schedulingErrors           prometheus.Counter
 	releasedContainers         prometheus.Counter
 	scheduleApplications       *prometheus.CounterVec
+	schedulingCycles           *prometheus.CounterVec
+	quotaViolations            *prometheus.GaugeVec
+	recoveredAllocations       *prometheus.GaugeVec
+	failedRecoveries           *prometheus.GaugeVec
+	backlogAsks                *prometheus.GaugeVec
+	nodeRegistrationTimeouts   prometheus.Counter
+	compactedAllocations       prometheus.Counter
 	totalApplicationsAdded     prometheus.Counter
 	totalApplicationsRejected  prometheus.Counter
 	totalApplicationsRunning   prometheus.Gauge
@@ -62,6 +69,7 @@ type SchedulerMetrics struct {
 	nodeSortingLatency         prometheus.Histogram
 	appSortingLatency          prometheus.Histogram
 	queueSortingLatency        prometheus.Histogram
+	appAllocationLatency       *prometheus.HistogramVec
 	lock                       sync.RWMutex
 }
 
@@ -172,13 +180,86 @@ func initSchedulerMetrics() *SchedulerMetrics {
 		},
 	)
 
+	// scheduling cycles started, per partition
+	s.schedulingCycles = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "scheduling_cycles_total",
+			Help:      "Total number of scheduling cycles started, per partition.",
+		}, []string{"partition"})
+
+	// applications exceeding the user quota, per partition
+	s.quotaViolations = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "quota_violations_total",
+			Help:      "Number of applications with allocations exceeding the user quota, per partition.",
+		}, []string{"partition"})
+
+	// allocations recovered and failed to recover since the partition was started, per partition
+	s.recoveredAllocations = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "recovered_allocations",
+			Help:      "Number of allocations recovered since the partition was started, per partition.",
+		}, []string{"partition"})
+	s.failedRecoveries = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "failed_recoveries",
+			Help:      "Number of allocations that could not be recovered since the partition was started, per partition.",
+		}, []string{"partition"})
+
+	// asks with pending allocations, per partition
+	s.backlogAsks = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: SchedulerSubsystem,
+			Name:      "backlog_asks",
+			Help:      "Number of asks with pending allocations, per partition.",
+		}, []string{"partition"})
+
+	// nodes removed because the registration did not complete in time
+	s.nodeRegistrationTimeouts = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "node_registration_timeout_total",
+			Help:      "Total number of nodes removed because the registration did not complete within the timeout.",
+		})
+
+	// stale allocations removed from the partitions
+	s.compactedAllocations = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "compacted_allocations_total",
+			Help:      "Total number of stale allocations removed from the partitions.",
+		})
+
+	// latency between ask creation and allocation, per queue
+	s.appAllocationLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: AppSubsystem,
+			Name:      "allocation_latency_seconds",
+			Help:      "latency between ask submission and allocation in seconds",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 10, 7), //start from 1ms
+		}, []string{"queue"})
+
 	var metricsList = []prometheus.Collector{
 		s.allocations,
 		s.scheduleApplications,
+		s.schedulingCycles,
+		s.quotaViolations,
+		s.recoveredAllocations,
+		s.failedRecoveries,
+		s.backlogAsks,
+		s.nodeRegistrationTimeouts,
+		s.compactedAllocations,
 		s.schedulingLatency,
 		s.nodeSortingLatency,
 		s.queueSortingLatency,
 		s.appSortingLatency,
+		s.appAllocationLatency,
 		s.totalApplicationsRunning,
 		s.totalApplicationsCompleted,
 		s.activeNodes,
@@ -226,6 +307,108 @@ func (m *SchedulerMetrics) ObserveQueueSortingLatency(start time.Time) {
 	m.queueSortingLatency.Observe(SinceInSeconds(start))
 }
 
+func (m *SchedulerMetrics) ObserveAppAllocationLatency(queueName string, latency time.Duration) {
+	m.appAllocationLatency.With(prometheus.Labels{"queue": queueName}).Observe(latency.Seconds())
+}
+
+// Metrics Ops related to schedulingCycles
+func (m *SchedulerMetrics) IncSchedulingCycle(partition string) {
+	m.schedulingCycles.With(prometheus.Labels{"partition": partition}).Inc()
+}
+
+func (m *SchedulerMetrics) getSchedulingCycles(partition string) (int, error) {
+	metricDto := &dto.Metric{}
+	err := m.schedulingCycles.With(prometheus.Labels{"partition": partition}).Write(metricDto)
+	if err == nil {
+		return int(*metricDto.Counter.Value), nil
+	}
+	return -1, err
+}
+
+// Metrics Ops related to quotaViolations
+func (m *SchedulerMetrics) SetQuotaViolations(partition string, value int) {
+	m.quotaViolations.With(prometheus.Labels{"partition": partition}).Set(float64(value))
+}
+
+func (m *SchedulerMetrics) getQuotaViolations(partition string) (int, error) {
+	metricDto := &dto.Metric{}
+	err := m.quotaViolations.With(prometheus.Labels{"partition": partition}).Write(metricDto)
+	if err == nil {
+		return int(*metricDto.Gauge.Value), nil
+	}
+	return -1, err
+}
+
+// Metrics Ops related to recoveredAllocations
+func (m *SchedulerMetrics) SetRecoveredAllocations(partition string, value int) {
+	m.recoveredAllocations.With(prometheus.Labels{"partition": partition}).Set(float64(value))
+}
+
+func (m *SchedulerMetrics) getRecoveredAllocations(partition string) (int, error) {
+	metricDto := &dto.Metric{}
+	err := m.recoveredAllocations.With(prometheus.Labels{"partition": partition}).Write(metricDto)
+	if err == nil {
+		return int(*metricDto.Gauge.Value), nil
+	}
+	return -1, err
+}
+
+// Metrics Ops related to failedRecoveries
+func (m *SchedulerMetrics) SetFailedRecoveries(partition string, value int) {
+	m.failedRecoveries.With(prometheus.Labels{"partition": partition}).Set(float64(value))
+}
+
+func (m *SchedulerMetrics) getFailedRecoveries(partition string) (int, error) {
+	metricDto := &dto.Metric{}
+	err := m.failedRecoveries.With(prometheus.Labels{"partition": partition}).Write(metricDto)
+	if err == nil {
+		return int(*metricDto.Gauge.Value), nil
+	}
+	return -1, err
+}
+
+// Metrics Ops related to backlogAsks
+func (m *SchedulerMetrics) SetBacklogAsks(partition string, value int) {
+	m.backlogAsks.With(prometheus.Labels{"partition": partition}).Set(float64(value))
+}
+
+func (m *SchedulerMetrics) getBacklogAsks(partition string) (int, error) {
+	metricDto := &dto.Metric{}
+	err := m.backlogAsks.With(prometheus.Labels{"partition": partition}).Write(metricDto)
+	if err == nil {
+		return int(*metricDto.Gauge.Value), nil
+	}
+	return -1, err
+}
+
+// Metrics Ops related to nodeRegistrationTimeouts
+func (m *SchedulerMetrics) IncNodeRegistrationTimeout() {
+	m.nodeRegistrationTimeouts.Inc()
+}
+
+func (m *SchedulerMetrics) getNodeRegistrationTimeouts() (int, error) {
+	metricDto := &dto.Metric{}
+	err := m.nodeRegistrationTimeouts.Write(metricDto)
+	if err == nil {
+		return int(*metricDto.Counter.Value), nil
+	}
+	return -1, err
+}
+
+// Metrics Ops related to compactedAllocations
+func (m *SchedulerMetrics) AddCompactedAllocations(value int) {
+	m.compactedAllocations.Add(float64(value))
+}
+
+func (m *SchedulerMetrics) getCompactedAllocations() (int, error) {
+	metricDto := &dto.Metric{}
+	err := m.compactedAllocations.Write(metricDto)
+	if err == nil {
+		return int(*metricDto.Counter.Value), nil
+	}
+	return -1, err
+}
+
 // Define and implement all the metrics ops for Prometheus.
 // Metrics Ops related to allocationScheduleSuccesses
 func (m *SchedulerMetrics) IncAllocatedContainer() {
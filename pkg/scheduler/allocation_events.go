@@ -0,0 +1,91 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+)
+
+type AllocationEventType string
+
+const (
+	AllocationAdded    AllocationEventType = "Added"
+	AllocationReleased AllocationEventType = "Released"
+)
+
+// An allocation committed in or released from the partition.
+type AllocationEvent struct {
+	Type       AllocationEventType
+	Allocation *objects.Allocation
+	Timestamp  time.Time
+}
+
+// Register the channel to receive all allocation events of the partition.
+// Events are sent without blocking: an event is dropped if the channel is full.
+// Registering a channel that is already registered is a no-op.
+func (pc *PartitionContext) ListenForAllocationEvents(ch chan<- AllocationEvent) {
+	pc.Lock()
+	defer pc.Unlock()
+	for _, listener := range pc.allocListeners {
+		if listener == ch {
+			return
+		}
+	}
+	pc.allocListeners = append(pc.allocListeners, ch)
+}
+
+// Stop sending allocation events to the channel. The channel is not closed.
+func (pc *PartitionContext) StopListeningForAllocationEvents(ch chan<- AllocationEvent) {
+	pc.Lock()
+	defer pc.Unlock()
+	for i, listener := range pc.allocListeners {
+		if listener == ch {
+			pc.allocListeners = append(pc.allocListeners[:i], pc.allocListeners[i+1:]...)
+			return
+		}
+	}
+}
+
+// Send the event for the allocation to all registered listeners, a listener that is not ready misses the event.
+// Unlocked version must be called holding the partition lock.
+func (pc *PartitionContext) sendAllocationEventInternal(eventType AllocationEventType, alloc *objects.Allocation) {
+	if len(pc.allocListeners) == 0 {
+		return
+	}
+	event := AllocationEvent{
+		Type:       eventType,
+		Allocation: alloc,
+		Timestamp:  time.Now(),
+	}
+	for _, listener := range pc.allocListeners {
+		select {
+		case listener <- event:
+		default:
+			log.Logger().Warn("allocation event listener is full, event dropped",
+				zap.String("partition", pc.Name),
+				zap.String("eventType", string(eventType)),
+				zap.String("uuid", alloc.UUID))
+		}
+	}
+}
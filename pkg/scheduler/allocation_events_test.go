@@ -0,0 +1,160 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"sync"
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+)
+
+// Drain the events from the channel and return the number of events per type and the UUIDs seen.
+func drainAllocationEvents(ch chan AllocationEvent) (map[AllocationEventType]int, map[string]bool) {
+	counts := make(map[AllocationEventType]int)
+	uuids := make(map[string]bool)
+	for {
+		select {
+		case event := <-ch:
+			counts[event.Type]++
+			uuids[event.Allocation.UUID] = true
+		default:
+			return counts, uuids
+		}
+	}
+}
+
+func TestAllocationEventListeners(t *testing.T) {
+	partition := createQueuesNodes(t)
+	if partition == nil {
+		t.Fatal("partition create failed")
+	}
+	first := make(chan AllocationEvent, 10)
+	second := make(chan AllocationEvent, 10)
+	stopped := make(chan AllocationEvent, 10)
+	full := make(chan AllocationEvent, 1)
+	for _, ch := range []chan AllocationEvent{first, second, stopped, full, first} {
+		partition.ListenForAllocationEvents(ch)
+	}
+	assert.Equal(t, len(partition.allocListeners), 4, "duplicate listener should not be registered")
+
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	for _, appID := range []string{appID1, appID2} {
+		app := newApplication(appID, "default", "root.leaf")
+		err := partition.AddApplication(app)
+		assert.NilError(t, err, "failed to add %s to partition", appID)
+		err = app.AddAllocationAsk(newAllocationAskRepeat("alloc-"+appID, appID, res, 2))
+		assert.NilError(t, err, "failed to add ask to %s", appID)
+	}
+	allocated := make(map[string]string)
+	for i := 0; i < 4; i++ {
+		alloc := partition.tryAllocate()
+		if alloc == nil {
+			t.Fatalf("allocation %d did not return any allocation", i)
+		}
+		allocated[alloc.UUID] = alloc.ApplicationID
+	}
+	counts, uuids := drainAllocationEvents(stopped)
+	assert.Equal(t, counts[AllocationAdded], 4, "unexpected added events for the stopped listener")
+	assert.Equal(t, len(uuids), 4, "unexpected allocations for the stopped listener")
+	partition.StopListeningForAllocationEvents(stopped)
+
+	// release all allocations concurrently
+	var wg sync.WaitGroup
+	for uuid, appID := range allocated {
+		wg.Add(1)
+		go func(appID, uuid string) {
+			defer wg.Done()
+			partition.removeAllocation(appID, uuid)
+		}(appID, uuid)
+	}
+	wg.Wait()
+
+	for _, ch := range []chan AllocationEvent{first, second} {
+		counts, uuids = drainAllocationEvents(ch)
+		assert.Equal(t, counts[AllocationAdded], 4, "unexpected added events")
+		assert.Equal(t, counts[AllocationReleased], 4, "unexpected released events")
+		assert.Equal(t, len(uuids), len(allocated), "unexpected allocations in events")
+		for uuid := range uuids {
+			_, ok := allocated[uuid]
+			assert.Assert(t, ok, "unexpected allocation in event: %s", uuid)
+		}
+	}
+	counts, _ = drainAllocationEvents(stopped)
+	assert.Equal(t, len(counts), 0, "stopped listener should not receive events")
+	// the full listener only received the first event, the rest was dropped without blocking
+	counts, _ = drainAllocationEvents(full)
+	assert.Equal(t, counts[AllocationAdded], 1, "full listener should only have the first event")
+	assert.Equal(t, counts[AllocationReleased], 0, "full listener should not have released events")
+}
+
+func TestAllocationEventsReleasePaths(t *testing.T) {
+	partition := createQueuesNodes(t)
+	if partition == nil {
+		t.Fatal("partition create failed")
+	}
+	ch := make(chan AllocationEvent, 20)
+	partition.ListenForAllocationEvents(ch)
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	allocate := func(appID string, count int) map[string]bool {
+		app := partition.getApplication(appID)
+		if app == nil {
+			app = newApplication(appID, "default", "root.leaf")
+			err := partition.AddApplication(app)
+			assert.NilError(t, err, "failed to add %s to partition", appID)
+		}
+		err := app.AddAllocationAsk(newAllocationAskRepeat("alloc-"+appID, appID, res, int32(count)))
+		assert.NilError(t, err, "failed to add ask to %s", appID)
+		uuids := make(map[string]bool)
+		for i := 0; i < count; i++ {
+			alloc := partition.tryAllocate()
+			assert.Assert(t, alloc != nil, "allocation %d for %s failed", i, appID)
+			uuids[alloc.UUID] = true
+		}
+		drainAllocationEvents(ch)
+		return uuids
+	}
+
+	// removing the application releases its allocations
+	allocated := allocate(appID1, 2)
+	partition.removeApplication(appID1)
+	counts, uuids := drainAllocationEvents(ch)
+	assert.Equal(t, counts[AllocationReleased], 2, "application removal should send released events")
+	assert.DeepEqual(t, uuids, allocated)
+
+	// removing the node releases the allocations on the node
+	allocated = allocate(appID2, 1)
+	var nodeID string
+	for uuid := range allocated {
+		nodeID = partition.GetAllocationByUUID(uuid).NodeID
+	}
+	partition.removeNode(nodeID)
+	counts, uuids = drainAllocationEvents(ch)
+	assert.Equal(t, counts[AllocationReleased], 1, "node removal should send released events")
+	assert.DeepEqual(t, uuids, allocated)
+
+	// clearing the partition releases everything
+	allocated = allocate(appID2, 2)
+	partition.clearPartition()
+	counts, uuids = drainAllocationEvents(ch)
+	assert.Equal(t, counts[AllocationReleased], 2, "clearing the partition should send released events")
+	assert.DeepEqual(t, uuids, allocated)
+}
@@ -0,0 +1,99 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+)
+
+// maximum number of allocations kept per application to calculate the allocation rate, oldest entries are
+// overwritten first
+const maxAppAllocationHistory = 100
+
+// Predict how long the application needs to get its pending resources allocated.
+// The prediction divides the pending resource of the application by the rate at which the application got
+// resources allocated. The rate is measured over the last maxAppAllocationHistory allocations of the application,
+// from the oldest of those allocations up to now: an application that stopped getting allocations slows down.
+// The longest time over all pending resource types is returned. Returns 0 if nothing is pending.
+// Returns an error if the application is not found or the history does not contain enough allocations for the
+// application to calculate the rate for all pending resource types.
+func (pc *PartitionContext) GetApplicationCompletionPrediction(appID string) (time.Duration, error) {
+	pc.RLock()
+	defer pc.RUnlock()
+	app := pc.applications[appID]
+	if app == nil {
+		return 0, fmt.Errorf("application %s not found in partition %s", appID, pc.Name)
+	}
+	pending := app.GetPendingResource()
+	if resources.IsZero(pending) {
+		return 0, nil
+	}
+	buffer := pc.appAllocationHistory[appID]
+	if buffer == nil || buffer.Len() < 2 {
+		return 0, fmt.Errorf("insufficient allocation history for application %s", appID)
+	}
+	// the oldest allocation starts the measured period: it does not count towards the rate
+	entries := buffer.since(time.Time{})
+	allocated := resources.NewResource()
+	for _, entry := range entries[1:] {
+		allocated.AddTo(entry.Resource)
+	}
+	period := time.Since(entries[0].Timestamp)
+	if period <= 0 {
+		return 0, fmt.Errorf("insufficient allocation history for application %s", appID)
+	}
+	var eta time.Duration
+	for name, quantity := range pending.Resources {
+		if quantity <= 0 {
+			continue
+		}
+		allocatedQuantity := allocated.Resources[name]
+		if allocatedQuantity <= 0 {
+			return 0, fmt.Errorf("insufficient allocation history for resource %s of application %s", name, appID)
+		}
+		// pending / (allocated / period) for this resource type
+		typeETA := time.Duration(float64(period) * float64(quantity) / float64(allocatedQuantity))
+		if typeETA > eta {
+			eta = typeETA
+		}
+	}
+	return eta, nil
+}
+
+// Record the allocation in the history of the application used to calculate the allocation rate.
+// Unlocked version must be called holding the partition lock.
+func (pc *PartitionContext) addAppAllocationHistory(alloc *objects.Allocation) {
+	buffer := pc.appAllocationHistory[alloc.ApplicationID]
+	if buffer == nil {
+		buffer = newAllocHistoryBuffer(maxAppAllocationHistory)
+		pc.appAllocationHistory[alloc.ApplicationID] = buffer
+	}
+	buffer.add(AllocationHistoryEntry{
+		AppID:         alloc.ApplicationID,
+		AllocationKey: alloc.AllocationKey,
+		NodeID:        alloc.NodeID,
+		Resource:      alloc.AllocatedResource.Clone(),
+		Timestamp:     time.Now(),
+		Action:        AllocationHistoryAllocated,
+	})
+}
@@ -0,0 +1,121 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+)
+
+func TestGetApplicationCompletionPrediction(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	_, err = partition.GetApplicationCompletionPrediction(appID1)
+	assert.ErrorContains(t, err, "not found")
+
+	app := newApplication(appID1, "default", defQueue)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "add application to partition should not have failed")
+	// nothing pending: nothing to wait for
+	eta, err := partition.GetApplicationCompletionPrediction(appID1)
+	assert.NilError(t, err, "app without pending asks should not fail")
+	assert.Equal(t, eta, time.Duration(0), "app without pending asks should not wait")
+
+	// 100 memory pending
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 10})
+	err = app.AddAllocationAsk(newAllocationAskRepeat("alloc-1", appID1, res, 10))
+	assert.NilError(t, err, "failed to add ask to app")
+	_, err = partition.GetApplicationCompletionPrediction(appID1)
+	assert.ErrorContains(t, err, "insufficient allocation history")
+
+	history := newAllocHistoryBuffer(maxAppAllocationHistory)
+	partition.appAllocationHistory[appID1] = history
+	addEntry := func(timestamp time.Time) {
+		history.add(AllocationHistoryEntry{
+			AppID:         appID1,
+			AllocationKey: "alloc-1",
+			NodeID:        nodeID1,
+			Resource:      res.Clone(),
+			Timestamp:     timestamp,
+			Action:        AllocationHistoryAllocated,
+		})
+	}
+	// a single allocation does not give a rate
+	start := time.Now().Add(-20 * time.Second)
+	addEntry(start)
+	_, err = partition.GetApplicationCompletionPrediction(appID1)
+	assert.ErrorContains(t, err, "insufficient allocation history")
+
+	// allocate 10 memory every 2 seconds up to now: 5 memory per second
+	for i := 1; i <= 10; i++ {
+		addEntry(start.Add(time.Duration(i) * 2 * time.Second))
+	}
+	eta, err = partition.GetApplicationCompletionPrediction(appID1)
+	assert.NilError(t, err, "prediction should not have failed")
+	expected := 20 * time.Second
+	assert.Assert(t, math.Abs(float64(eta-expected)) <= 0.1*float64(expected), "eta %v not within 10%% of %v", eta, expected)
+
+	// the period runs up to now: without new allocations the rate drops
+	stalled := newAllocHistoryBuffer(maxAppAllocationHistory)
+	for _, entry := range history.since(time.Time{}) {
+		entry.Timestamp = entry.Timestamp.Add(-20 * time.Second)
+		stalled.add(entry)
+	}
+	partition.appAllocationHistory[appID1] = stalled
+	eta, err = partition.GetApplicationCompletionPrediction(appID1)
+	assert.NilError(t, err, "prediction should not have failed")
+	expected = 40 * time.Second
+	assert.Assert(t, math.Abs(float64(eta-expected)) <= 0.1*float64(expected), "eta %v not within 10%% of %v", eta, expected)
+	partition.appAllocationHistory[appID1] = history
+
+	// a pending resource type without allocations cannot be predicted
+	err = app.AddAllocationAsk(newAllocationAsk("alloc-2", appID1, resources.NewResourceFromMap(map[string]resources.Quantity{resources.VCORE: 1})))
+	assert.NilError(t, err, "failed to add ask to app")
+	_, err = partition.GetApplicationCompletionPrediction(appID1)
+	assert.ErrorContains(t, err, fmt.Sprintf("resource %s", resources.VCORE))
+}
+
+func TestAppAllocationHistory(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	err = partition.AddNode(newNodeMaxResource(nodeID1, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 200})), nil)
+	assert.NilError(t, err, "add node to partition should not have failed")
+	app := newApplication(appID1, "default", defQueue)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "add application to partition should not have failed")
+	err = app.AddAllocationAsk(newAllocationAskRepeat("alloc-1", appID1, res, 150))
+	assert.NilError(t, err, "failed to add ask to app")
+	// every allocation is recorded, the oldest are dropped
+	for i := 0; i < 150; i++ {
+		assert.Assert(t, partition.tryAllocate() != nil, "allocation %d failed", i)
+	}
+	assert.Equal(t, partition.appAllocationHistory[appID1].Len(), maxAppAllocationHistory, "app history should be capped")
+
+	// removing the application drops the history
+	partition.removeApplication(appID1)
+	_, ok := partition.appAllocationHistory[appID1]
+	assert.Assert(t, !ok, "history of removed application should be dropped")
+}
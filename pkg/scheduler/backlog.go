@@ -0,0 +1,72 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"time"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/metrics"
+)
+
+// Unscheduled work in a partition: the asks that still have allocations pending.
+type SchedulerBacklog struct {
+	TotalPendingAsks     int                 // number of asks with pending allocations
+	TotalPendingResource *resources.Resource // resources requested by the pending allocations
+	OldestAskAge         time.Duration       // time since the oldest pending ask was created
+	AppCount             int                 // number of applications with pending asks
+	QueueBreakdown       map[string]int      // number of pending asks per queue path
+}
+
+// Return the backlog of the partition.
+func (pc *PartitionContext) GetSchedulerBacklog() SchedulerBacklog {
+	return pc.getSchedulerBacklog(time.Now())
+}
+
+// Update the backlog metric of the partition, called periodically by the partition manager.
+func (pc *PartitionContext) updateBacklogMetric() {
+	metrics.GetSchedulerMetrics().SetBacklogAsks(pc.Name, pc.getSchedulerBacklog(time.Now()).TotalPendingAsks)
+}
+
+func (pc *PartitionContext) getSchedulerBacklog(now time.Time) SchedulerBacklog {
+	backlog := SchedulerBacklog{
+		TotalPendingResource: resources.NewResource(),
+		QueueBreakdown:       make(map[string]int),
+	}
+	var oldest time.Time
+	for _, app := range pc.GetApplications() {
+		asks := app.GetPendingAsks()
+		if len(asks) == 0 {
+			continue
+		}
+		backlog.AppCount++
+		backlog.TotalPendingAsks += len(asks)
+		backlog.QueueBreakdown[app.GetQueueName()] += len(asks)
+		for _, ask := range asks {
+			backlog.TotalPendingResource.AddTo(resources.Multiply(ask.AllocatedResource, int64(ask.GetPendingAskRepeat())))
+			if created := ask.GetCreateTime(); oldest.IsZero() || created.Before(oldest) {
+				oldest = created
+			}
+		}
+	}
+	if !oldest.IsZero() {
+		backlog.OldestAskAge = now.Sub(oldest)
+	}
+	return backlog
+}
@@ -0,0 +1,118 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/metrics"
+)
+
+func TestGetSchedulerBacklog(t *testing.T) {
+	partition, err := newConfiguredPartition()
+	assert.NilError(t, err, "partition create failed")
+	backlog := partition.GetSchedulerBacklog()
+	assert.Equal(t, backlog.TotalPendingAsks, 0, "empty partition should not have pending asks")
+	assert.Assert(t, resources.IsZero(backlog.TotalPendingResource), "empty partition should not have pending resources")
+	assert.Equal(t, backlog.OldestAskAge, time.Duration(0), "empty partition should not have an oldest ask")
+	assert.Equal(t, backlog.AppCount, 0, "empty partition should not have apps in the backlog")
+	assert.Equal(t, len(backlog.QueueBreakdown), 0, "empty partition should not have queues in the backlog")
+
+	// app-1 in root.leaf with two asks, app-2 in root.parent.sub-leaf with one, app-3 without asks
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	app1 := newApplication(appID1, "default", "root.leaf")
+	err = partition.AddApplication(app1)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	ask := newAllocationAskRepeat("alloc-1", appID1, res, 2)
+	err = app1.AddAllocationAsk(ask)
+	assert.NilError(t, err, "failed to add ask alloc-1 to app-1")
+	oldest := ask.GetCreateTime()
+	err = app1.AddAllocationAsk(newAllocationAsk("alloc-2", appID1, res))
+	assert.NilError(t, err, "failed to add ask alloc-2 to app-1")
+	app2 := newApplication(appID2, "default", "root.parent.sub-leaf")
+	err = partition.AddApplication(app2)
+	assert.NilError(t, err, "failed to add app-2 to partition")
+	err = app2.AddAllocationAsk(newAllocationAskRepeat("alloc-3", appID2, res, 3))
+	assert.NilError(t, err, "failed to add ask alloc-3 to app-2")
+	err = partition.AddApplication(newApplication("app-3", "default", "root.leaf"))
+	assert.NilError(t, err, "failed to add app-3 to partition")
+
+	backlog = partition.getSchedulerBacklog(oldest.Add(time.Minute))
+	assert.Equal(t, backlog.TotalPendingAsks, 3, "unexpected pending asks")
+	assert.Assert(t, resources.Equals(backlog.TotalPendingResource, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 6})), "unexpected pending resource: %s", backlog.TotalPendingResource)
+	assert.Equal(t, backlog.OldestAskAge, time.Minute, "unexpected oldest ask age")
+	assert.Equal(t, backlog.AppCount, 2, "unexpected apps in the backlog")
+	assert.DeepEqual(t, backlog.QueueBreakdown, map[string]int{"root.leaf": 2, "root.parent.sub-leaf": 1})
+	assert.Assert(t, resources.Equals(backlog.TotalPendingResource, partition.root.GetPendingResource()), "backlog should match the root pending resource")
+
+	// asks without pending allocations are not part of the backlog
+	_ = app2.RemoveAllocationAsk("alloc-3")
+	backlog = partition.GetSchedulerBacklog()
+	assert.Equal(t, backlog.TotalPendingAsks, 2, "removed ask should not be pending")
+	assert.Equal(t, backlog.AppCount, 1, "app without pending asks should not be counted")
+	assert.DeepEqual(t, backlog.QueueBreakdown, map[string]int{"root.leaf": 2})
+}
+
+func TestUpdateBacklogMetric(t *testing.T) {
+	partition, err := newConfiguredPartition()
+	assert.NilError(t, err, "partition create failed")
+	partition.Name = "backlog-metric"
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	app := newApplication(appID1, "default", "root.leaf")
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	err = app.AddAllocationAsk(newAllocationAskRepeat("alloc-1", appID1, res, 2))
+	assert.NilError(t, err, "failed to add ask alloc-1 to app-1")
+	err = app.AddAllocationAsk(newAllocationAsk("alloc-2", appID1, res))
+	assert.NilError(t, err, "failed to add ask alloc-2 to app-1")
+
+	// reading the backlog does not change the metric, the update does
+	partition.GetSchedulerBacklog()
+	assert.Equal(t, getBacklogAsks(t, partition.Name), -1.0, "metric should not be set by reading the backlog")
+	partition.updateBacklogMetric()
+	assert.Equal(t, getBacklogAsks(t, partition.Name), 2.0, "unexpected backlog metric")
+	_ = app.RemoveAllocationAsk("alloc-2")
+	partition.updateBacklogMetric()
+	assert.Equal(t, getBacklogAsks(t, partition.Name), 1.0, "backlog metric not updated")
+}
+
+// read the backlog asks gauge of the partition from the registered metrics, -1 if not set
+func getBacklogAsks(t *testing.T, partition string) float64 {
+	metrics.GetSchedulerMetrics()
+	families, err := prometheus.DefaultGatherer.Gather()
+	assert.NilError(t, err, "failed to gather metrics")
+	for _, family := range families {
+		if family.GetName() != "yunikorn_scheduler_backlog_asks" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "partition" && label.GetValue() == partition {
+					return metric.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+	return -1
+}
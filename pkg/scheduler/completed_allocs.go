@@ -0,0 +1,108 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"time"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+)
+
+// Totals for the allocations that were removed from the partition.
+// The resource time is the quantity of each resource type multiplied by the run time of the allocation in seconds.
+type CompletedAllocationStats struct {
+	TotalAllocations int64                                     // number of allocations completed
+	ResourceSeconds  map[string]float64                        // resource time per resource type
+	QueueBreakdown   map[string]*QueueCompletedAllocationStats // totals per queue path
+}
+
+// Totals for the allocations of a single queue that were removed from the partition.
+type QueueCompletedAllocationStats struct {
+	Allocations     int64              // number of allocations completed in the queue
+	ResourceSeconds map[string]float64 // resource time per resource type
+}
+
+func newCompletedAllocationStats() *CompletedAllocationStats {
+	return &CompletedAllocationStats{
+		ResourceSeconds: make(map[string]float64),
+		QueueBreakdown:  make(map[string]*QueueCompletedAllocationStats),
+	}
+}
+
+// Add the allocation to the totals, the run time is measured from the creation of the allocation to the removal.
+// Not locked, must be called holding the partition lock.
+func (cs *CompletedAllocationStats) add(alloc *objects.Allocation, removed time.Time) {
+	seconds := removed.Sub(alloc.GetCreateTime()).Seconds()
+	if seconds < 0 {
+		seconds = 0
+	}
+	queueStats := cs.QueueBreakdown[alloc.QueueName]
+	if queueStats == nil {
+		queueStats = &QueueCompletedAllocationStats{
+			ResourceSeconds: make(map[string]float64),
+		}
+		cs.QueueBreakdown[alloc.QueueName] = queueStats
+	}
+	cs.TotalAllocations++
+	queueStats.Allocations++
+	if alloc.AllocatedResource == nil {
+		return
+	}
+	for name, quantity := range alloc.AllocatedResource.Resources {
+		resourceTime := float64(quantity) * seconds
+		cs.ResourceSeconds[name] += resourceTime
+		queueStats.ResourceSeconds[name] += resourceTime
+	}
+}
+
+// Return a deep copy of the totals.
+func (cs *CompletedAllocationStats) clone() CompletedAllocationStats {
+	stats := CompletedAllocationStats{
+		TotalAllocations: cs.TotalAllocations,
+		ResourceSeconds:  copyResourceSeconds(cs.ResourceSeconds),
+		QueueBreakdown:   make(map[string]*QueueCompletedAllocationStats, len(cs.QueueBreakdown)),
+	}
+	for queuePath, queueStats := range cs.QueueBreakdown {
+		stats.QueueBreakdown[queuePath] = &QueueCompletedAllocationStats{
+			Allocations:     queueStats.Allocations,
+			ResourceSeconds: copyResourceSeconds(queueStats.ResourceSeconds),
+		}
+	}
+	return stats
+}
+
+func copyResourceSeconds(resourceSeconds map[string]float64) map[string]float64 {
+	result := make(map[string]float64, len(resourceSeconds))
+	for name, value := range resourceSeconds {
+		result[name] = value
+	}
+	return result
+}
+
+// Return the totals for the allocations removed from the partition since it was created.
+func (pc *PartitionContext) GetCompletedAllocationStats() CompletedAllocationStats {
+	pc.RLock()
+	defer pc.RUnlock()
+	return pc.completedAllocStats.clone()
+}
+
+// Unlocked version must be called holding the partition lock.
+func (pc *PartitionContext) addCompletedAllocation(alloc *objects.Allocation) {
+	pc.completedAllocStats.add(alloc, time.Now())
+}
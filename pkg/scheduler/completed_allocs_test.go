@@ -0,0 +1,127 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+)
+
+func TestCompletedAllocationStatsAdd(t *testing.T) {
+	stats := newCompletedAllocationStats()
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10, "second": 2})
+	for i := 0; i < 100; i++ {
+		ask := newAllocationAsk("alloc", appID1, res)
+		ask.QueueName = "root.leaf"
+		if i%2 == 0 {
+			ask.QueueName = "root.parent.sub-leaf"
+		}
+		alloc := objects.NewAllocation("uuid", nodeID1, ask)
+		stats.add(alloc, alloc.GetCreateTime().Add(30*time.Second))
+	}
+	withinOnePercent := func(actual, expected float64) bool {
+		return math.Abs(actual-expected) <= expected/100
+	}
+	assert.Equal(t, stats.TotalAllocations, int64(100), "unexpected number of completed allocations")
+	assert.Assert(t, withinOnePercent(stats.ResourceSeconds["first"], 30000), "unexpected resource time for first: %f", stats.ResourceSeconds["first"])
+	assert.Assert(t, withinOnePercent(stats.ResourceSeconds["second"], 6000), "unexpected resource time for second: %f", stats.ResourceSeconds["second"])
+	assert.Equal(t, len(stats.QueueBreakdown), 2, "unexpected queues in the breakdown")
+	for _, queuePath := range []string{"root.leaf", "root.parent.sub-leaf"} {
+		queueStats := stats.QueueBreakdown[queuePath]
+		assert.Assert(t, queueStats != nil, "queue %s missing from the breakdown", queuePath)
+		assert.Equal(t, queueStats.Allocations, int64(50), "unexpected completed allocations for queue %s", queuePath)
+		assert.Assert(t, withinOnePercent(queueStats.ResourceSeconds["first"], 15000), "unexpected resource time for queue %s: %f", queuePath, queueStats.ResourceSeconds["first"])
+	}
+
+	// the clone does not change with the stats
+	clone := stats.clone()
+	ask := newAllocationAsk("alloc", appID1, res)
+	ask.QueueName = "root.leaf"
+	alloc := objects.NewAllocation("uuid", nodeID1, ask)
+	// removal before the creation time does not count as negative time
+	stats.add(alloc, alloc.GetCreateTime().Add(-time.Minute))
+	assert.Equal(t, stats.TotalAllocations, int64(101), "allocation not counted")
+	assert.Assert(t, withinOnePercent(stats.ResourceSeconds["first"], 30000), "negative run time changed the resource time: %f", stats.ResourceSeconds["first"])
+	assert.Equal(t, clone.TotalAllocations, int64(100), "clone changed with the stats")
+	assert.Equal(t, clone.QueueBreakdown["root.leaf"].Allocations, int64(50), "clone breakdown changed with the stats")
+}
+
+func TestGetCompletedAllocationStats(t *testing.T) {
+	partition, err := newConfiguredPartition()
+	assert.NilError(t, err, "partition create failed")
+	stats := partition.GetCompletedAllocationStats()
+	assert.Equal(t, stats.TotalAllocations, int64(0), "new partition should not have completed allocations")
+	assert.Equal(t, len(stats.QueueBreakdown), 0, "new partition should not have queues in the breakdown")
+
+	app := newApplication(appID1, "default", "root.leaf")
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app to partition")
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10})
+	for _, nodeID := range []string{nodeID1, nodeID2} {
+		ask := newAllocationAsk("alloc-"+nodeID, appID1, res)
+		ask.QueueName = "root.leaf"
+		err = partition.AddNode(newNodeMaxResource(nodeID, nodeRes), []*objects.Allocation{objects.NewAllocation("uuid-"+nodeID, nodeID, ask)})
+		assert.NilError(t, err, "failed to add node %s to partition", nodeID)
+	}
+
+	// release an allocation
+	released := partition.removeAllocation(appID1, "uuid-"+nodeID1)
+	assert.Equal(t, len(released), 1, "allocation should have been released")
+	stats = partition.GetCompletedAllocationStats()
+	assert.Equal(t, stats.TotalAllocations, int64(1), "released allocation not counted")
+	assert.Equal(t, stats.QueueBreakdown["root.leaf"].Allocations, int64(1), "released allocation not counted for the queue")
+	assert.Assert(t, stats.ResourceSeconds["first"] >= 0, "unexpected resource time: %f", stats.ResourceSeconds["first"])
+
+	// remove the node with an allocation
+	released = partition.removeNode(nodeID2)
+	assert.Equal(t, len(released), 1, "allocation should have been removed with the node")
+	stats = partition.GetCompletedAllocationStats()
+	assert.Equal(t, stats.TotalAllocations, int64(2), "allocation removed with the node not counted")
+	assert.Equal(t, stats.QueueBreakdown["root.leaf"].Allocations, int64(2), "allocation removed with the node not counted for the queue")
+
+	// remove the application with an allocation
+	ask := newAllocationAsk("alloc-3", appID1, res)
+	ask.QueueName = "root.leaf"
+	err = partition.AddNode(newNodeMaxResource("node-3", nodeRes), []*objects.Allocation{objects.NewAllocation("uuid-3", "node-3", ask)})
+	assert.NilError(t, err, "failed to add node-3 to partition")
+	released = partition.removeApplication(appID1)
+	assert.Equal(t, len(released), 1, "allocation should have been removed with the application")
+	stats = partition.GetCompletedAllocationStats()
+	assert.Equal(t, stats.TotalAllocations, int64(3), "allocation removed with the application not counted")
+
+	// clear the partition with an allocation
+	app = newApplication(appID2, "default", "root.leaf")
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app to partition")
+	ask = newAllocationAsk("alloc-4", appID2, res)
+	ask.QueueName = "root.leaf"
+	err = partition.AddNode(newNodeMaxResource("node-4", nodeRes), []*objects.Allocation{objects.NewAllocation("uuid-4", "node-4", ask)})
+	assert.NilError(t, err, "failed to add node-4 to partition")
+	released = partition.clearPartition()
+	assert.Equal(t, len(released), 1, "allocation should have been removed when clearing the partition")
+	stats = partition.GetCompletedAllocationStats()
+	assert.Equal(t, stats.TotalAllocations, int64(4), "allocation removed when clearing the partition not counted")
+}
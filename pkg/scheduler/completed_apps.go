@@ -0,0 +1,207 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+)
+
+// maximum number of removed queues for which the history is kept, the oldest removal is dropped first
+const maxRemovedQueueHistories = 100
+
+// Summary of an application that was removed from the partition.
+// The total allocated resource is the sum of all allocations made over the lifetime of the application.
+type CompletedAppSummary struct {
+	ApplicationID  string
+	User           string
+	QueueName      string
+	TotalAllocated *resources.Resource
+	StartTime      time.Time
+	EndTime        time.Time
+}
+
+// Ring buffer of completed applications, the oldest entry is overwritten first.
+type completedAppBuffer struct {
+	*common.RingBuffer
+}
+
+func newCompletedAppBuffer(size int) *completedAppBuffer {
+	return &completedAppBuffer{
+		RingBuffer: common.NewRingBuffer(size),
+	}
+}
+
+// Add the summary to the buffer, evicting the oldest entry if the buffer is full.
+// Returns the evicted entry and true if an entry was evicted.
+func (b *completedAppBuffer) add(summary CompletedAppSummary) (CompletedAppSummary, bool) {
+	var evicted CompletedAppSummary
+	full := b.Size() > 0 && b.Len() == b.Size()
+	if full {
+		evicted = b.Get(0).(CompletedAppSummary)
+	}
+	b.Add(summary)
+	return evicted, full
+}
+
+// Return a copy of at most limit entries, newest entry first. A limit of 0 or less returns all entries.
+func (b *completedAppBuffer) newest(limit int) []CompletedAppSummary {
+	count := b.Len()
+	if limit <= 0 || limit > count {
+		limit = count
+	}
+	result := make([]CompletedAppSummary, limit)
+	for i := 0; i < limit; i++ {
+		result[i] = b.Get(count - 1 - i).(CompletedAppSummary)
+	}
+	return result
+}
+
+// Return a buffer of the new size that keeps the newest entries of this buffer.
+func (b *completedAppBuffer) resize(size int) *completedAppBuffer {
+	resized := newCompletedAppBuffer(size)
+	entries := b.newest(size)
+	for i := len(entries) - 1; i >= 0; i-- {
+		resized.add(entries[i])
+	}
+	return resized
+}
+
+// Return the applications that completed in the queue, newest first.
+// At most limit entries are returned, a limit of 0 or less returns all entries kept for the queue.
+// Applications complete just before an unmanaged queue is removed: the history of a removed queue is kept
+// until maxRemovedQueueHistories other queues were removed after it.
+func (pc *PartitionContext) GetQueueCompletedApplications(queuePath string, limit int) []CompletedAppSummary {
+	pc.RLock()
+	defer pc.RUnlock()
+	buffer := pc.completedApps[strings.ToLower(queuePath)]
+	if buffer == nil {
+		return nil
+	}
+	if limit <= 0 || limit > pc.completedAppsLimit {
+		limit = pc.completedAppsLimit
+	}
+	return buffer.newest(limit)
+}
+
+// Record the application in the completed history of its queue.
+// The history per queue is capped at the completed applications limit of the partition.
+// Unlocked version must be called holding the partition lock.
+func (pc *PartitionContext) addCompletedApp(app *objects.Application) {
+	if pc.completedAppsLimit <= 0 {
+		pc.forgetRestartCount(app.ApplicationID)
+		return
+	}
+	queuePath := strings.ToLower(app.GetQueueName())
+	buffer := pc.completedApps[queuePath]
+	if buffer == nil {
+		buffer = newCompletedAppBuffer(pc.completedAppsLimit)
+	} else if buffer.Size() != pc.completedAppsLimit {
+		// the entries that do not fit in the new size are dropped, oldest first
+		for i := 0; i < buffer.Len()-pc.completedAppsLimit; i++ {
+			pc.forgetCompletedApp(buffer.Get(i).(CompletedAppSummary).ApplicationID)
+		}
+		buffer = buffer.resize(pc.completedAppsLimit)
+	}
+	pc.completedAppIndex[app.ApplicationID]++
+	evicted, ok := buffer.add(CompletedAppSummary{
+		ApplicationID:  app.ApplicationID,
+		User:           app.GetUser().User,
+		QueueName:      app.GetQueueName(),
+		TotalAllocated: app.GetTotalAllocatedResource(),
+		StartTime:      app.GetCreateTime(),
+		EndTime:        time.Now(),
+	})
+	if ok {
+		pc.forgetCompletedApp(evicted.ApplicationID)
+	}
+	pc.completedApps[queuePath] = buffer
+}
+
+// Remove one entry for the application from the completed application index.
+// Unlocked version must be called holding the partition lock.
+func (pc *PartitionContext) forgetCompletedApp(appID string) {
+	pc.completedAppIndex[appID]--
+	if pc.completedAppIndex[appID] <= 0 {
+		delete(pc.completedAppIndex, appID)
+		pc.forgetRestartCount(appID)
+	}
+}
+
+// Drop the restart count of the application if it is not active and no longer part of any completed history:
+// a restart can not be detected anymore.
+// Unlocked version must be called holding the partition lock.
+func (pc *PartitionContext) forgetRestartCount(appID string) {
+	if pc.applications[appID] == nil && pc.completedAppIndex[appID] == 0 {
+		delete(pc.applicationRestartCounts, appID)
+	}
+}
+
+// Find the queues that were removed since the last call and drop the histories of the oldest removed queues.
+func (pc *PartitionContext) pruneQueueHistories() {
+	pc.Lock()
+	defer pc.Unlock()
+	for queuePath := range pc.completedApps {
+		pc.trackRemovedQueue(queuePath)
+	}
+	for queuePath := range pc.queueAllocationHistory {
+		pc.trackRemovedQueue(queuePath)
+	}
+}
+
+// Record the removal of the queue if it no longer exists, dropping the histories of the queue that drops out of
+// the removed queues. A queue that exists again is no longer tracked as removed.
+// Unlocked version must be called holding the partition lock.
+func (pc *PartitionContext) trackRemovedQueue(queuePath string) {
+	if pc.getQueue(queuePath) != nil {
+		pc.removedQueues.remove(queuePath)
+		return
+	}
+	if pc.removedQueues.contains(queuePath) {
+		return
+	}
+	if evicted, ok := pc.removedQueues.add(queuePath); ok {
+		if buffer := pc.completedApps[evicted]; buffer != nil {
+			for i := 0; i < buffer.Len(); i++ {
+				pc.forgetCompletedApp(buffer.Get(i).(CompletedAppSummary).ApplicationID)
+			}
+		}
+		delete(pc.completedApps, evicted)
+		delete(pc.queueAllocationHistory, evicted)
+	}
+}
+
+// Return true if the application is part of the completed history of any queue.
+// Unlocked version must be called holding the partition lock.
+func (pc *PartitionContext) isCompletedApp(appID string) bool {
+	return pc.completedAppIndex[appID] > 0
+}
+
+// Return the number of times the application was added again after it completed.
+// A restart is only detected while the completed application is kept in the history of a queue.
+// The count is dropped when the application is not active and no longer kept in any history.
+func (pc *PartitionContext) GetApplicationRestartCount(appID string) int {
+	pc.RLock()
+	defer pc.RUnlock()
+	return pc.applicationRestartCounts[appID]
+}
@@ -0,0 +1,241 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+)
+
+func completedAppIDs(apps []CompletedAppSummary) []string {
+	ids := make([]string, len(apps))
+	for i, app := range apps {
+		ids[i] = app.ApplicationID
+	}
+	return ids
+}
+
+func TestCompletedAppBuffer(t *testing.T) {
+	buffer := newCompletedAppBuffer(0)
+	buffer.add(CompletedAppSummary{ApplicationID: "app-0"})
+	assert.Equal(t, len(buffer.newest(0)), 0, "zero size buffer should not keep entries")
+
+	buffer = newCompletedAppBuffer(3)
+	for i := 1; i <= 5; i++ {
+		buffer.add(CompletedAppSummary{ApplicationID: fmt.Sprintf("app-%d", i)})
+	}
+	assert.DeepEqual(t, completedAppIDs(buffer.newest(0)), []string{"app-5", "app-4", "app-3"})
+	assert.DeepEqual(t, completedAppIDs(buffer.newest(2)), []string{"app-5", "app-4"})
+	assert.DeepEqual(t, completedAppIDs(buffer.newest(10)), []string{"app-5", "app-4", "app-3"})
+
+	smaller := buffer.resize(2)
+	assert.DeepEqual(t, completedAppIDs(smaller.newest(0)), []string{"app-5", "app-4"})
+	larger := buffer.resize(4)
+	larger.add(CompletedAppSummary{ApplicationID: "app-6"})
+	larger.add(CompletedAppSummary{ApplicationID: "app-7"})
+	assert.DeepEqual(t, completedAppIDs(larger.newest(0)), []string{"app-7", "app-6", "app-5", "app-4"})
+}
+
+func TestGetQueueCompletedApplications(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	assert.Equal(t, partition.completedAppsLimit, configs.DefaultCompletedApplicationsLimit, "default limit not set")
+	partition.setPartitionProperties(map[string]string{configs.CompletedApplicationsLimit: "3"})
+	assert.Equal(t, partition.completedAppsLimit, 3, "limit not set from the properties")
+	assert.Assert(t, partition.GetQueueCompletedApplications(defQueue, 0) == nil, "new queue should not have completed apps")
+
+	// an application with an allocation
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10})
+	allocRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 2})
+	err = partition.AddApplication(newApplication("app-1", "default", defQueue))
+	assert.NilError(t, err, "add application to partition should not have failed")
+	alloc := objects.NewAllocation("alloc-1-uuid", nodeID1, newAllocationAsk("alloc-1", "app-1", allocRes))
+	err = partition.AddNode(newNodeMaxResource(nodeID1, nodeRes), []*objects.Allocation{alloc})
+	assert.NilError(t, err, "add node to partition should not have failed")
+	partition.removeApplication("app-1")
+	completed := partition.GetQueueCompletedApplications(defQueue, 0)
+	assert.Equal(t, len(completed), 1, "completed application not recorded")
+	assert.Equal(t, completed[0].QueueName, defQueue, "unexpected queue")
+	assert.Assert(t, resources.Equals(completed[0].TotalAllocated, allocRes), "unexpected total allocated")
+	assert.Assert(t, !completed[0].EndTime.Before(completed[0].StartTime), "end time before start time")
+
+	// oldest entries are evicted beyond the limit
+	for i := 2; i <= 5; i++ {
+		appID := fmt.Sprintf("app-%d", i)
+		err = partition.AddApplication(newApplication(appID, "default", defQueue))
+		assert.NilError(t, err, "add application %s to partition should not have failed", appID)
+		partition.removeApplication(appID)
+	}
+	assert.DeepEqual(t, completedAppIDs(partition.GetQueueCompletedApplications(defQueue, 0)), []string{"app-5", "app-4", "app-3"})
+	assert.DeepEqual(t, completedAppIDs(partition.GetQueueCompletedApplications("ROOT.DEFAULT", 2)), []string{"app-5", "app-4"})
+	assert.Assert(t, partition.GetQueueCompletedApplications("root.unknown", 0) == nil, "unknown queue should not have completed apps")
+
+	// disabling the history keeps the existing entries but records nothing new
+	partition.setPartitionProperties(map[string]string{configs.CompletedApplicationsLimit: "0"})
+	err = partition.AddApplication(newApplication("app-6", "default", defQueue))
+	assert.NilError(t, err, "add application to partition should not have failed")
+	partition.removeApplication("app-6")
+	partition.setPartitionProperties(nil)
+	assert.DeepEqual(t, completedAppIDs(partition.GetQueueCompletedApplications(defQueue, 0)), []string{"app-5", "app-4", "app-3"})
+}
+
+func TestCompletedApplicationsConcurrent(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	const apps = 200
+	for i := 0; i < apps; i++ {
+		err = partition.AddApplication(newApplication(fmt.Sprintf("app-%d", i), "default", defQueue))
+		assert.NilError(t, err, "add application to partition should not have failed")
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < apps; i++ {
+		wg.Add(2)
+		go func(appID string) {
+			defer wg.Done()
+			partition.removeApplication(appID)
+		}(fmt.Sprintf("app-%d", i))
+		go func() {
+			defer wg.Done()
+			partition.GetQueueCompletedApplications(defQueue, 10)
+		}()
+	}
+	wg.Wait()
+	completed := partition.GetQueueCompletedApplications(defQueue, 0)
+	assert.Equal(t, len(completed), configs.DefaultCompletedApplicationsLimit, "history should be full")
+	seen := make(map[string]bool)
+	for _, app := range completed {
+		assert.Assert(t, !seen[app.ApplicationID], "application %s recorded twice", app.ApplicationID)
+		seen[app.ApplicationID] = true
+	}
+}
+
+func TestGetApplicationRestartCount(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	assert.Equal(t, partition.GetApplicationRestartCount(appID1), 0, "unknown application should not have restarts")
+
+	err = partition.AddApplication(newApplication(appID1, "default", defQueue))
+	assert.NilError(t, err, "add application to partition should not have failed")
+	assert.Equal(t, partition.GetApplicationRestartCount(appID1), 0, "first submission is not a restart")
+	partition.removeApplication(appID1)
+
+	err = partition.AddApplication(newApplication(appID1, "default", defQueue))
+	assert.NilError(t, err, "resubmit of completed application should not have failed")
+	assert.Equal(t, partition.GetApplicationRestartCount(appID1), 1, "second submission should be counted")
+	partition.removeApplication(appID1)
+
+	err = partition.AddApplication(newApplication(appID1, "default", defQueue))
+	assert.NilError(t, err, "resubmit of completed application should not have failed")
+	assert.Equal(t, partition.GetApplicationRestartCount(appID1), 2, "third submission should be counted")
+	assert.Equal(t, partition.GetApplicationRestartCount(appID2), 0, "other application should not have restarts")
+
+	// without history a restart cannot be detected
+	partition.setPartitionProperties(map[string]string{configs.CompletedApplicationsLimit: "0"})
+	partition.removeApplication(appID1)
+	partition.completedApps = make(map[string]*completedAppBuffer)
+	partition.completedAppIndex = make(map[string]int)
+	err = partition.AddApplication(newApplication(appID1, "default", defQueue))
+	assert.NilError(t, err, "resubmit of completed application should not have failed")
+	assert.Equal(t, partition.GetApplicationRestartCount(appID1), 2, "restart without history should not be counted")
+	// completing without history drops the count
+	partition.removeApplication(appID1)
+	assert.Equal(t, partition.GetApplicationRestartCount(appID1), 0, "count should be dropped without history")
+	assert.Equal(t, len(partition.applicationRestartCounts), 0, "restart counts should be empty")
+}
+
+func TestApplicationRestartCountEviction(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	partition.setPartitionProperties(map[string]string{configs.CompletedApplicationsLimit: "2"})
+	for i := 0; i < 2; i++ {
+		err = partition.AddApplication(newApplication(appID1, "default", defQueue))
+		assert.NilError(t, err, "add application to partition should not have failed")
+		partition.removeApplication(appID1)
+	}
+	assert.Equal(t, partition.GetApplicationRestartCount(appID1), 1, "resubmit should be counted")
+	assert.Equal(t, partition.completedAppIndex[appID1], 2, "both completions should be indexed")
+	assert.Assert(t, partition.isCompletedApp(appID1), "application should be completed")
+
+	// evict one completion: the application is still in the history
+	err = partition.AddApplication(newApplication(appID2, "default", defQueue))
+	assert.NilError(t, err, "add application to partition should not have failed")
+	partition.removeApplication(appID2)
+	assert.Equal(t, partition.completedAppIndex[appID1], 1, "evicted completion should be removed from the index")
+	assert.Equal(t, partition.GetApplicationRestartCount(appID1), 1, "count should be kept while in the history")
+
+	// evict the last completion: the count is dropped
+	err = partition.AddApplication(newApplication("app-3", "default", defQueue))
+	assert.NilError(t, err, "add application to partition should not have failed")
+	partition.removeApplication("app-3")
+	assert.Assert(t, !partition.isCompletedApp(appID1), "evicted application should not be completed")
+	assert.Equal(t, partition.GetApplicationRestartCount(appID1), 0, "count should be dropped after eviction")
+	_, ok := partition.completedAppIndex[appID1]
+	assert.Assert(t, !ok, "evicted application should not be in the index")
+
+	// shrinking the history drops the oldest entries from the index
+	partition.setPartitionProperties(map[string]string{configs.CompletedApplicationsLimit: "1"})
+	err = partition.AddApplication(newApplication(appID1, "default", defQueue))
+	assert.NilError(t, err, "add application to partition should not have failed")
+	partition.removeApplication(appID1)
+	assert.Equal(t, len(partition.completedAppIndex), 1, "only the newest completion should be indexed")
+	assert.Assert(t, partition.isCompletedApp(appID1), "newest application should be completed")
+}
+
+func TestPruneQueueHistories(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	err = partition.AddApplication(newApplication(appID1, "default", defQueue))
+	assert.NilError(t, err, "add application to partition should not have failed")
+	partition.removeApplication(appID1)
+	// histories of queues that no longer exist
+	for i := 0; i < maxRemovedQueueHistories; i++ {
+		queuePath := fmt.Sprintf("root.removed-%d", i)
+		partition.completedApps[queuePath] = newCompletedAppBuffer(1)
+	}
+	partition.pruneQueueHistories()
+	assert.Equal(t, len(partition.completedApps), maxRemovedQueueHistories+1, "histories within the limit should be kept")
+	assert.Assert(t, partition.completedApps[defQueue] != nil, "history of an existing queue should be kept")
+
+	// each new removal drops the history of the oldest removed queue
+	partition.completedApps["root.removed-new"] = newCompletedAppBuffer(1)
+	partition.queueAllocationHistory["root.removed-new"] = newAllocHistoryBuffer(1)
+	partition.pruneQueueHistories()
+	assert.Equal(t, len(partition.completedApps), maxRemovedQueueHistories+1, "history of the oldest removed queue should have been dropped")
+	assert.Assert(t, partition.completedApps["root.removed-new"] != nil, "history of the newest removed queue should be kept")
+	assert.Assert(t, partition.completedApps[defQueue] != nil, "history of an existing queue should be kept")
+
+	// the allocation history of a removed queue is dropped with the completed applications
+	partition.queueAllocationHistory["root.removed-last"] = newAllocHistoryBuffer(1)
+	partition.pruneQueueHistories()
+	assert.Assert(t, partition.queueAllocationHistory["root.removed-new"] != nil, "allocation history of a recently removed queue should be kept")
+	for i := 0; i < maxRemovedQueueHistories; i++ {
+		partition.queueAllocationHistory[fmt.Sprintf("root.other-%d", i)] = newAllocHistoryBuffer(1)
+		partition.pruneQueueHistories()
+	}
+	assert.Assert(t, partition.queueAllocationHistory["root.removed-new"] == nil, "allocation history of the oldest removed queue should have been dropped")
+	assert.Assert(t, partition.completedApps["root.removed-new"] == nil, "completed history of the oldest removed queue should have been dropped")
+	assert.Equal(t, len(partition.queueAllocationHistory)+len(partition.completedApps), maxRemovedQueueHistories+1, "unexpected number of histories")
+}
@@ -0,0 +1,51 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v2"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
+)
+
+// Return the SHA-256 checksum of the active configuration of the partition as a hex string.
+// The checksum only changes when the configuration applied to the partition changes.
+func (pc *PartitionContext) GetConfigChecksum() string {
+	pc.RLock()
+	defer pc.RUnlock()
+	return pc.configChecksum
+}
+
+// Calculate the checksum over the serialised partition configuration.
+// The serialisation sorts map keys: the same configuration always gives the same checksum.
+func getConfigChecksum(conf configs.PartitionConfig) string {
+	content, err := yaml.Marshal(&conf)
+	if err != nil {
+		log.Logger().Warn("failed to serialise partition configuration for the checksum",
+			zap.String("partitionName", conf.Name),
+			zap.Error(err))
+		return ""
+	}
+	return fmt.Sprintf("%X", sha256.Sum256(content))
+}
@@ -0,0 +1,74 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+)
+
+func checksumConfig(queues ...string) configs.PartitionConfig {
+	children := make([]configs.QueueConfig, 0, len(queues))
+	for _, name := range queues {
+		children = append(children, configs.QueueConfig{Name: name})
+	}
+	return configs.PartitionConfig{
+		Name: "test",
+		Queues: []configs.QueueConfig{
+			{
+				Name:       "root",
+				Parent:     true,
+				SubmitACL:  "*",
+				Properties: map[string]string{"first": "value", "second": "value"},
+				Queues:     children,
+			},
+		},
+	}
+}
+
+func TestGetConfigChecksum(t *testing.T) {
+	partition, err := newPartitionContext(checksumConfig("default"), rmID, nil)
+	assert.NilError(t, err, "partition create failed")
+	initial := partition.GetConfigChecksum()
+	assert.Equal(t, len(initial), 64, "checksum should be a hex encoded SHA-256: %s", initial)
+
+	// re-applying the same config does not change the checksum
+	err = partition.updatePartitionDetails(checksumConfig("default"))
+	assert.NilError(t, err, "config update failed")
+	assert.Equal(t, partition.GetConfigChecksum(), initial, "checksum changed for unchanged config")
+
+	// adding a queue changes the checksum
+	err = partition.updatePartitionDetails(checksumConfig("default", "other"))
+	assert.NilError(t, err, "config update failed")
+	added := partition.GetConfigChecksum()
+	assert.Assert(t, added != initial, "checksum not changed after adding a queue")
+
+	// removing the queue again goes back to the initial checksum
+	err = partition.updatePartitionDetails(checksumConfig("default"))
+	assert.NilError(t, err, "config update failed")
+	assert.Equal(t, partition.GetConfigChecksum(), initial, "checksum not restored after removing the queue")
+
+	// a failed update leaves the checksum unchanged
+	err = partition.updatePartitionDetails(configs.PartitionConfig{Name: "test"})
+	assert.Assert(t, err != nil, "update without root queue should have failed")
+	assert.Equal(t, partition.GetConfigChecksum(), initial, "checksum changed by a failed update")
+}
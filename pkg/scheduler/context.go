@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"math"
 	"sync"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -93,6 +94,21 @@ func newClusterContext() *ClusterContext {
 	return cc
 }
 
+// Return the shortest scheduling interval of all partitions.
+// The default interval is returned if there are no partitions.
+func (cc *ClusterContext) getSchedulingInterval() time.Duration {
+	var interval time.Duration
+	for _, psc := range cc.GetPartitionMapClone() {
+		if partInterval := psc.GetSchedulingInterval(); interval == 0 || partInterval < interval {
+			interval = partInterval
+		}
+	}
+	if interval == 0 {
+		interval = time.Duration(configs.DefaultSchedulingIntervalMs) * time.Millisecond
+	}
+	return interval
+}
+
 func (cc *ClusterContext) setEventHandler(rmHandler handler.EventHandler) {
 	cc.rmEventHandler = rmHandler
 }
@@ -100,7 +116,9 @@ func (cc *ClusterContext) setEventHandler(rmHandler handler.EventHandler) {
 // The main scheduling routine.
 // Process each partition in the scheduler, walk over each queue and app to check if anything can be scheduled.
 // This can be forked into a go routine per partition if needed to increase parallel allocations
-func (cc *ClusterContext) schedule() {
+// Returns true if an allocation was made in any of the partitions.
+func (cc *ClusterContext) schedule() bool {
+	scheduled := false
 	// schedule each partition defined in the cluster
 	for _, psc := range cc.GetPartitionMapClone() {
 		// if there are no resources in the partition just skip
@@ -111,13 +129,16 @@ func (cc *ClusterContext) schedule() {
 		if psc.isStopped() {
 			continue
 		}
+		psc.startSchedulingCycle()
 		// try reservations first
 		alloc := psc.tryReservedAllocate()
 		// nothing reserved that can be allocated try normal allocate
 		if alloc == nil {
 			alloc = psc.tryAllocate()
 		}
+		psc.recordSchedulingCycle(alloc)
 		if alloc != nil {
+			scheduled = true
 			// TODO: The alloc is passed to the RM twice why do we need event + callback?
 			// See YUNIKORN-462, there are two separate communications for the same allocation
 			// between the core and the shim they should be merged into one communication.
@@ -146,6 +167,7 @@ func (cc *ClusterContext) schedule() {
 			}
 		}
 	}
+	return scheduled
 }
 
 func (cc *ClusterContext) processRMRegistrationEvent(event *rmevent.RMRegistrationEvent) {
@@ -498,6 +520,7 @@ func (cc *ClusterContext) processApplications(request *si.UpdateRequest) {
 	}
 }
 
+// Return true if the preemption cycle should run. The cycle only includes partitions that allow preemption.
 func (cc *ClusterContext) NeedPreemption() bool {
 	cc.RLock()
 	defer cc.RUnlock()
@@ -533,16 +556,20 @@ func (cc *ClusterContext) updateNodes(request *si.UpdateRequest) {
 			switch update.Action {
 			case si.UpdateNodeInfo_UPDATE:
 				if sr := update.SchedulableResource; sr != nil {
-					newCapacity := resources.NewResourceFromProto(sr)
-					node.SetCapacity(newCapacity)
+					partition.updateNodeCapacity(node.NodeID, resources.NewResourceFromProto(sr))
 				}
 				if or := update.OccupiedResource; or != nil {
 					newOccupied := resources.NewResourceFromProto(or)
 					node.SetOccupiedResource(newOccupied)
 				}
+				// attributes are added or replaced, never removed
+				if len(update.Attributes) != 0 {
+					partition.UpdateNodeAttributes(node.NodeID, update.Attributes)
+				}
 			case si.UpdateNodeInfo_DRAIN_NODE:
 				// set the state to not schedulable
 				node.SetSchedulable(false)
+				partition.addNodeEvent(node.NodeID, NodeMarkedDraining, nil)
 			case si.UpdateNodeInfo_DRAIN_TO_SCHEDULABLE:
 				// set the state to schedulable
 				node.SetSchedulable(true)
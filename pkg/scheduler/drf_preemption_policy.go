@@ -22,6 +22,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/security"
 	"github.com/apache/incubator-yunikorn-core/pkg/interfaces"
 	"github.com/apache/incubator-yunikorn-core/pkg/log"
 	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
@@ -139,6 +140,10 @@ func trySurgicalPreemptionOnNode(preemptionPartitionCtx *preemptionPartitionCont
 
 	toReleaseAllocations := make(map[string]*objects.Allocation)
 	totalReleasedResource := resources.NewResource()
+	var preemptorUser security.UserGroup
+	if app := preemptionPartitionCtx.getApplication(candidate.ApplicationID); app != nil {
+		preemptorUser = app.GetUserGroup()
+	}
 
 	// Otherwise, try to do preemption, list all allocations on the node.
 	// Fixme: this operation has too many copies, should avoid for better perf
@@ -150,6 +155,11 @@ func trySurgicalPreemptionOnNode(preemptionPartitionCtx *preemptionPartitionCont
 			continue
 		}
 
+		// Skip allocations owned by a user with more privileges than the preemptor
+		if !canPreemptAllocation(preemptionPartitionCtx, preemptorUser, preemptQueue.schedulingQueue, alloc) {
+			continue
+		}
+
 		// Skip when the queue has <= 0 preempt-able resource
 		if resources.CompUsageRatio(preemptQueue.resources.preemptable, resources.Zero, preemptionPartitionCtx.partitionTotalResource) <= 0 {
 			continue
@@ -188,6 +198,16 @@ func trySurgicalPreemptionOnNode(preemptionPartitionCtx *preemptionPartitionCont
 	return nil
 }
 
+// Allocations owned by a user with admin access to the queue of the allocation can only be preempted
+// by a user that also has admin access to that queue.
+func canPreemptAllocation(preemptionPartitionCtx *preemptionPartitionContext, preemptor security.UserGroup, preemptQueue *objects.Queue, alloc *objects.Allocation) bool {
+	app := preemptionPartitionCtx.getApplication(alloc.ApplicationID)
+	if app == nil || !preemptQueue.CheckAdminAccess(app.GetUserGroup()) {
+		return true
+	}
+	return preemptQueue.CheckAdminAccess(preemptor)
+}
+
 func crossQueuePreemptionAllocate(preemptionPartitionContext *preemptionPartitionContext, nodeIterator interfaces.NodeIterator, candidate *objects.AllocationAsk) *objects.Allocation {
 	if preemptionPartitionContext == nil {
 		return nil
@@ -205,6 +225,12 @@ func crossQueuePreemptionAllocate(preemptionPartitionContext *preemptionPartitio
 	var preemptResult *singleNodePreemptResult = nil
 
 	for nodeIterator.HasNext() {
+		// stop if preemption was disabled while we were looking for victims
+		if !preemptionPartitionContext.isEnabled() {
+			log.Logger().Debug("preemption disabled, aborting preemption for candidate",
+				zap.String("allocationKey", candidate.AllocationKey))
+			return nil
+		}
 		node, ok := nodeIterator.Next().(*objects.Node)
 		if !ok {
 			log.Logger().Debug("Node iterator failed to return a node")
@@ -0,0 +1,186 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/security"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+)
+
+func newPreemptionQueueContext(queue *objects.Queue, preemptable *resources.Resource) *preemptionQueueContext {
+	ctx := &preemptionQueueContext{
+		queuePath:       queue.QueuePath,
+		schedulingQueue: queue,
+		resources:       newQueuePreemptCalcResource(),
+		children:        make(map[string]*preemptionQueueContext),
+	}
+	ctx.resources.preemptable = preemptable
+	return ctx
+}
+
+func TestSurgicalPreemptionUserPrivilege(t *testing.T) {
+	conf := configs.PartitionConfig{
+		Name: "test",
+		Queues: []configs.QueueConfig{
+			{
+				Name:      "root",
+				Parent:    true,
+				SubmitACL: "*",
+				AdminACL:  "admin",
+				Queues: []configs.QueueConfig{
+					{Name: "high"},
+					{Name: "low"},
+				},
+			},
+		},
+	}
+	partition, err := newPartitionContext(conf, rmID, nil)
+	assert.NilError(t, err, "partition create failed")
+	apps := map[string]security.UserGroup{
+		"app-admin":     {User: "admin"},
+		"app-other":     {User: "other"},
+		"app-low":       {User: "user"},
+		"app-low-admin": {User: "admin"},
+	}
+	queues := map[string]string{"app-admin": "root.high", "app-other": "root.high", "app-low": "root.low", "app-low-admin": "root.low"}
+	for appID, user := range apps {
+		err = partition.AddApplication(objects.NewApplication(appID, "test", queues[appID], user, nil, nil, rmID))
+		assert.NilError(t, err, "failed to add application %s", appID)
+	}
+
+	// fill two nodes: one with an allocation of the privileged user and one of a regular user
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10})
+	newNodeAlloc := func(nodeID, appID string) *objects.Node {
+		ask := newAllocationAsk("alloc-"+appID, appID, res)
+		ask.QueueName = queues[appID]
+		err = partition.AddNode(newNodeMaxResource(nodeID, res), []*objects.Allocation{objects.NewAllocation("uuid-"+appID, nodeID, ask)})
+		assert.NilError(t, err, "failed to add node %s", nodeID)
+		return partition.GetNode(nodeID)
+	}
+	adminNode := newNodeAlloc(nodeID1, "app-admin")
+	otherNode := newNodeAlloc(nodeID2, "app-other")
+
+	highQueue := newPreemptionQueueContext(partition.GetQueue("root.high"), res)
+	lowQueue := newPreemptionQueueContext(partition.GetQueue("root.low"), resources.NewResource())
+	preemptionCtx := &preemptionPartitionContext{
+		partition:              partition,
+		partitionTotalResource: partition.GetTotalPartitionResource(),
+		leafQueues:             map[string]*preemptionQueueContext{"root.high": highQueue, "root.low": lowQueue},
+	}
+
+	// a low privileged user cannot preempt the admin allocation even if the queue is over its share
+	candidate := newAllocationAsk("ask-low", "app-low", resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5}))
+	candidate.QueueName = "root.low"
+	result := trySurgicalPreemptionOnNode(preemptionCtx, lowQueue, adminNode, candidate, map[string]*resources.Resource{})
+	assert.Assert(t, result == nil, "admin allocation should not have been preempted by a regular user")
+	// allocations of other regular users can be preempted
+	result = trySurgicalPreemptionOnNode(preemptionCtx, lowQueue, otherNode, candidate, map[string]*resources.Resource{})
+	assert.Assert(t, result != nil, "allocation of a regular user should have been preempted")
+	assert.Equal(t, len(result.toReleaseAllocations), 1, "expected one allocation to be released")
+
+	// a user with the same privileges can preempt the admin allocation
+	candidate = newAllocationAsk("ask-low-admin", "app-low-admin", resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5}))
+	candidate.QueueName = "root.low"
+	result = trySurgicalPreemptionOnNode(preemptionCtx, lowQueue, adminNode, candidate, map[string]*resources.Resource{})
+	assert.Assert(t, result != nil, "admin allocation should have been preempted by an admin user")
+	assert.Assert(t, result.toReleaseAllocations["uuid-app-admin"] != nil, "admin allocation not released")
+}
+
+func TestPreemptionToggle(t *testing.T) {
+	conf := configs.PartitionConfig{
+		Name: "test",
+		Queues: []configs.QueueConfig{
+			{
+				Name:      "root",
+				Parent:    true,
+				SubmitACL: "*",
+				Queues: []configs.QueueConfig{
+					{Name: "high", Resources: configs.Resources{Guaranteed: map[string]string{"first": "8"}}},
+					{Name: "low", Resources: configs.Resources{Guaranteed: map[string]string{"first": "2"}}},
+				},
+			},
+		},
+	}
+	partition, err := newPartitionContext(conf, rmID, nil)
+	assert.NilError(t, err, "partition create failed")
+	assert.Assert(t, !partition.GetIsPreemptable(), "preemption should be disabled by default")
+	err = partition.AddApplication(newApplication("app-low", "test", "root.low"))
+	assert.NilError(t, err, "failed to add application")
+	// fill the node with an allocation of the low queue: the low queue is way over its guarantee
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10})
+	ask := newAllocationAsk("alloc-low", "app-low", res)
+	ask.QueueName = "root.low"
+	err = partition.AddNode(newNodeMaxResource(nodeID1, res), []*objects.Allocation{objects.NewAllocation("uuid-low", nodeID1, ask)})
+	assert.NilError(t, err, "failed to add node")
+	node := partition.GetNode(nodeID1)
+	candidate := newAllocationAsk("ask-high", "app-high", resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5}))
+	candidate.QueueName = "root.high"
+
+	// enabling the partition does not start the preemption cycle: the cluster gate is off by default
+	scheduler := &Scheduler{clusterContext: &ClusterContext{partitions: map[string]*PartitionContext{partition.Name: partition}}}
+	partition.SetIsPreemptable(true)
+	assert.Assert(t, !scheduler.clusterContext.NeedPreemption(), "cluster should not need preemption")
+	scheduler.SingleStepPreemption()
+	assert.Assert(t, scheduler.preemptionContext == nil, "preemption cycle should not have run")
+
+	// cluster gate on, partition disabled: the cycle runs without the partition
+	scheduler.clusterContext.needPreemption = true
+	partition.SetIsPreemptable(false)
+	scheduler.SingleStepPreemption()
+	assert.Assert(t, scheduler.preemptionContext != nil, "preemption cycle should have run")
+	assert.Assert(t, scheduler.preemptionContext.partitions[partition.Name] == nil, "disabled partition should not be part of the cycle")
+
+	// enabled: the cycle runs and finds the low queue allocation as a victim
+	partition.SetIsPreemptable(true)
+	scheduler.SingleStepPreemption()
+	assert.Assert(t, scheduler.preemptionContext != nil, "preemption cycle should have run")
+	ctx := scheduler.preemptionContext.partitions[partition.Name]
+	if ctx == nil {
+		t.Fatal("partition not part of the preemption cycle")
+	}
+	expected := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 8})
+	assert.Assert(t, resources.Equals(ctx.leafQueues["root.low"].resources.preemptable, expected), "unexpected preemptable resource: %s", ctx.leafQueues["root.low"].resources.preemptable)
+
+	// disabled mid cycle: the in flight preemption is aborted even though the victim is eligible
+	partition.SetIsPreemptable(false)
+	alloc := crossQueuePreemptionAllocate(ctx, newDefaultNodeIterator([]*objects.Node{node}), candidate)
+	assert.Assert(t, alloc == nil, "preemption should have been aborted")
+	ctx.leafQueues["root.low"].resources.preemptable = resources.NewResource()
+	calculateIdealResources(scheduler)
+	assert.Assert(t, resources.IsZero(ctx.leafQueues["root.low"].resources.preemptable), "disabled partition should have been skipped")
+
+	// disabled: further cycles skip the partition
+	scheduler.SingleStepPreemption()
+	assert.Assert(t, scheduler.preemptionContext.partitions[partition.Name] == nil, "disabled partition should not be part of the cycle")
+
+	// re-enabled: preemption resumes
+	partition.SetIsPreemptable(true)
+	scheduler.SingleStepPreemption()
+	assert.Assert(t, scheduler.preemptionContext != nil, "preemption cycle should have run")
+	ctx = scheduler.preemptionContext.partitions[partition.Name]
+	alloc = crossQueuePreemptionAllocate(ctx, newDefaultNodeIterator([]*objects.Node{node}), candidate)
+	assert.Assert(t, alloc != nil, "preemption should have resumed")
+	assert.Equal(t, alloc.NodeID, nodeID1, "unexpected node for the preemption allocation")
+}
@@ -21,7 +21,10 @@ package scheduler
 import (
 	"math"
 
+	"go.uber.org/zap"
+
 	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
 )
 
 // Temp object for better readability.
@@ -39,6 +42,11 @@ type queuePreemptCalcResourceByType struct {
 func calculateIdealResources(scheduler *Scheduler) {
 	// for each partition
 	for partitionName, preemptionPartitionContext := range scheduler.preemptionContext.partitions {
+		if !preemptionPartitionContext.isEnabled() {
+			log.Logger().Debug("preemption disabled during the preemption cycle, skipping partition",
+				zap.String("partitionName", partitionName))
+			continue
+		}
 		calculateIdealResourcesForPartition(scheduler, partitionName, preemptionPartitionContext)
 	}
 }
@@ -0,0 +1,139 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"sort"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+)
+
+// An application using more than its fair share of the guaranteed resources of its queue while another application
+// in the same queue has pending requests. The excess is the part of the allocated resources above the fair share.
+type FairShareViolation struct {
+	ApplicationID        string
+	QueueName            string
+	Excess               *resources.Resource
+	StarvedApplicationID string
+	QueueFairShare       float64 // fair share ratio of the queue compared to its siblings, see GetQueueSiblingFairShare
+}
+
+// Return all fair share violations in the partition sorted on queue, application and starved application.
+// The fair share of an application is the weighted fair share of the queue divided evenly over the applications in
+// the queue that have allocated or pending resources. The weighted fair share of the queue is its guaranteed
+// resource limited by its fair entitlement compared to its siblings. Only resource types with a guarantee are
+// considered.
+// A violation is returned for each combination of an application above its fair share and a starved application.
+func (pc *PartitionContext) GetFairShareViolations() []FairShareViolation {
+	pc.RLock()
+	defer pc.RUnlock()
+	appsByQueue := make(map[*objects.Queue][]*objects.Application)
+	for _, app := range pc.applications {
+		queue := app.GetQueue()
+		if queue == nil {
+			continue
+		}
+		if resources.IsZero(app.GetAllocatedResource()) && resources.IsZero(app.GetPendingResource()) {
+			continue
+		}
+		appsByQueue[queue] = append(appsByQueue[queue], app)
+	}
+	violations := make([]FairShareViolation, 0)
+	for queue, apps := range appsByQueue {
+		guaranteed := queue.GetGuaranteedResource()
+		if len(apps) < 2 || resources.IsZero(guaranteed) {
+			continue
+		}
+		fairShare := resources.MultiplyBy(getWeightedFairShare(guaranteed, queue.GetFairEntitlement()), 1/float64(len(apps)))
+		queueFairShare := queue.GetSiblingFairShare()
+		var starved []string
+		for _, app := range apps {
+			if resources.StrictlyGreaterThanZero(app.GetPendingResource()) {
+				starved = append(starved, app.ApplicationID)
+			}
+		}
+		for _, app := range apps {
+			excess := getFairShareExcess(app.GetAllocatedResource(), fairShare)
+			if resources.IsZero(excess) {
+				continue
+			}
+			for _, starvedID := range starved {
+				if starvedID == app.ApplicationID {
+					continue
+				}
+				violations = append(violations, FairShareViolation{
+					ApplicationID:        app.ApplicationID,
+					QueueName:            queue.QueuePath,
+					Excess:               excess,
+					StarvedApplicationID: starvedID,
+					QueueFairShare:       queueFairShare,
+				})
+			}
+		}
+	}
+	sort.Slice(violations, func(i, j int) bool {
+		l := violations[i]
+		r := violations[j]
+		if l.QueueName != r.QueueName {
+			return l.QueueName < r.QueueName
+		}
+		if l.ApplicationID != r.ApplicationID {
+			return l.ApplicationID < r.ApplicationID
+		}
+		return l.StarvedApplicationID < r.StarvedApplicationID
+	})
+	return violations
+}
+
+// Return the fair share ratio of the queue compared to its siblings, see Queue.GetSiblingFairShare.
+// Returns -1 if the queue does not exist.
+func (pc *PartitionContext) GetQueueSiblingFairShare(queuePath string) float64 {
+	queue := pc.GetQueue(queuePath)
+	if queue == nil {
+		return -1
+	}
+	return queue.GetSiblingFairShare()
+}
+
+// Return the guaranteed resource limited by the fair entitlement for the resource types in the guaranteed resource.
+// A resource type without an entitlement is not limited.
+func getWeightedFairShare(guaranteed, entitlement *resources.Resource) *resources.Resource {
+	share := guaranteed.Clone()
+	if entitlement == nil {
+		return share
+	}
+	for name, quantity := range share.Resources {
+		if entitled, ok := entitlement.Resources[name]; ok && entitled < quantity {
+			share.Resources[name] = entitled
+		}
+	}
+	return share
+}
+
+// Return the part of the allocated resource above the fair share for the resource types in the fair share.
+func getFairShareExcess(allocated, fairShare *resources.Resource) *resources.Resource {
+	excess := resources.NewResource()
+	for name, share := range fairShare.Resources {
+		if used := allocated.Resources[name]; used > share {
+			excess.Resources[name] = used - share
+		}
+	}
+	return excess
+}
@@ -0,0 +1,226 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+)
+
+func newFairSharePartition(t *testing.T, guaranteed map[string]string) *PartitionContext {
+	conf := configs.PartitionConfig{
+		Name: "test",
+		Queues: []configs.QueueConfig{
+			{
+				Name:      "root",
+				Parent:    true,
+				SubmitACL: "*",
+				Queues: []configs.QueueConfig{
+					{
+						Name:      "default",
+						Resources: configs.Resources{Guaranteed: guaranteed},
+					},
+				},
+			},
+		},
+	}
+	partition, err := newPartitionContext(conf, rmID, nil)
+	assert.NilError(t, err, "partition create failed")
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 20, "second": 20})
+	err = partition.AddNode(newNodeMaxResource(nodeID1, res), nil)
+	assert.NilError(t, err, "node add failed")
+	return partition
+}
+
+func TestGetFairShareViolations(t *testing.T) {
+	partition := newFairSharePartition(t, map[string]string{"first": "10"})
+	assert.Equal(t, len(partition.GetFairShareViolations()), 0, "empty partition should not have violations")
+
+	// app-1 takes 8 of the guaranteed 10 while it is the only app
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1, "second": 1})
+	app1 := newApplication(appID1, "default", defQueue)
+	err := partition.AddApplication(app1)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	err = app1.AddAllocationAsk(newAllocationAskRepeat("alloc-1", appID1, res, 8))
+	assert.NilError(t, err, "failed to add ask to app-1")
+	for i := 0; i < 8; i++ {
+		if alloc := partition.tryAllocate(); alloc == nil {
+			t.Fatalf("allocation %d for app-1 failed", i)
+		}
+	}
+	assert.Equal(t, len(partition.GetFairShareViolations()), 0, "single app should not have violations")
+
+	// app-2 has a pending ask: app-1 is 3 above the fair share of 5
+	app2 := newApplication(appID2, "default", defQueue)
+	err = partition.AddApplication(app2)
+	assert.NilError(t, err, "failed to add app-2 to partition")
+	err = app2.AddAllocationAsk(newAllocationAsk("alloc-2", appID2, res))
+	assert.NilError(t, err, "failed to add ask to app-2")
+	violations := partition.GetFairShareViolations()
+	assert.Equal(t, len(violations), 1, "expected one violation: %v", violations)
+	assert.Equal(t, violations[0].ApplicationID, appID1, "unexpected greedy app")
+	assert.Equal(t, violations[0].StarvedApplicationID, appID2, "unexpected starved app")
+	assert.Equal(t, violations[0].QueueName, defQueue, "unexpected queue")
+	// the only child of root uses 8 of the 20 of the node
+	assert.Equal(t, violations[0].QueueFairShare, 0.4, "unexpected queue fair share")
+	// resources without a guarantee are not part of the excess
+	expected := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 3})
+	assert.Assert(t, resources.Equals(violations[0].Excess, expected), "unexpected excess: %s", violations[0].Excess)
+
+	// nothing pending: no violation
+	if alloc := partition.tryAllocate(); alloc == nil || alloc.ApplicationID != appID2 {
+		t.Fatalf("allocation for app-2 failed: %v", alloc)
+	}
+	assert.Equal(t, len(partition.GetFairShareViolations()), 0, "no violation expected without pending asks")
+}
+
+func TestGetFairShareViolationsNoGuarantee(t *testing.T) {
+	partition := newFairSharePartition(t, nil)
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5})
+	app1 := newApplication(appID1, "default", defQueue)
+	err := partition.AddApplication(app1)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	err = app1.AddAllocationAsk(newAllocationAsk("alloc-1", appID1, res))
+	assert.NilError(t, err, "failed to add ask to app-1")
+	if alloc := partition.tryAllocate(); alloc == nil {
+		t.Fatal("allocation for app-1 failed")
+	}
+	app2 := newApplication(appID2, "default", defQueue)
+	err = partition.AddApplication(app2)
+	assert.NilError(t, err, "failed to add app-2 to partition")
+	err = app2.AddAllocationAsk(newAllocationAsk("alloc-2", appID2, res))
+	assert.NilError(t, err, "failed to add ask to app-2")
+	assert.Equal(t, len(partition.GetFairShareViolations()), 0, "queue without guarantee should not have violations")
+}
+
+func TestGetFairShareViolationsWeighted(t *testing.T) {
+	conf := configs.PartitionConfig{
+		Name: "test",
+		Queues: []configs.QueueConfig{
+			{
+				Name:      "root",
+				Parent:    true,
+				SubmitACL: "*",
+				Queues: []configs.QueueConfig{
+					{
+						Name:       "heavy",
+						Properties: map[string]string{configs.QueueWeight: "3"},
+					}, {
+						Name:      "light",
+						Resources: configs.Resources{Guaranteed: map[string]string{"first": "20"}},
+					},
+				},
+			},
+		},
+	}
+	partition, err := newPartitionContext(conf, rmID, nil)
+	assert.NilError(t, err, "partition create failed")
+	err = partition.AddNode(newNodeMaxResource(nodeID1, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 40})), nil)
+	assert.NilError(t, err, "node add failed")
+
+	// the light queue is entitled to 10 of the 40: below its guarantee of 20
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	app1 := newApplication(appID1, "default", "root.light")
+	err = partition.AddApplication(app1)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	err = app1.AddAllocationAsk(newAllocationAskRepeat("alloc-1", appID1, res, 8))
+	assert.NilError(t, err, "failed to add ask to app-1")
+	for i := 0; i < 8; i++ {
+		if alloc := partition.tryAllocate(); alloc == nil {
+			t.Fatalf("allocation %d for app-1 failed", i)
+		}
+	}
+	app2 := newApplication(appID2, "default", "root.light")
+	err = partition.AddApplication(app2)
+	assert.NilError(t, err, "failed to add app-2 to partition")
+	err = app2.AddAllocationAsk(newAllocationAsk("alloc-2", appID2, res))
+	assert.NilError(t, err, "failed to add ask to app-2")
+
+	// app-1 is 3 above the weighted fair share of 5, it is below half of the guarantee
+	violations := partition.GetFairShareViolations()
+	assert.Equal(t, len(violations), 1, "expected one violation: %v", violations)
+	assert.Equal(t, violations[0].ApplicationID, appID1, "unexpected greedy app")
+	assert.Equal(t, violations[0].QueueName, "root.light", "unexpected queue")
+	expected := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 3})
+	assert.Assert(t, resources.Equals(violations[0].Excess, expected), "unexpected excess: %s", violations[0].Excess)
+}
+
+func TestGetQueueSiblingFairShare(t *testing.T) {
+	conf := configs.PartitionConfig{
+		Name: "test",
+		Queues: []configs.QueueConfig{
+			{
+				Name:      "root",
+				Parent:    true,
+				SubmitACL: "*",
+				Queues: []configs.QueueConfig{
+					{
+						Name:       "heavy",
+						Properties: map[string]string{configs.QueueWeight: "2"},
+					}, {
+						Name: "light",
+					},
+				},
+			},
+		},
+	}
+	partition, err := newPartitionContext(conf, rmID, nil)
+	assert.NilError(t, err, "partition create failed")
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 30})
+	err = partition.AddNode(newNodeMaxResource(nodeID1, res), nil)
+	assert.NilError(t, err, "node add failed")
+	assert.Equal(t, partition.GetQueueSiblingFairShare("root"), -1.0, "root should not have a sibling fair share")
+	assert.Equal(t, partition.GetQueueSiblingFairShare("root.unknown"), -1.0, "unknown queue should not have a fair share")
+	assert.Equal(t, partition.GetQueueSiblingFairShare("root.heavy"), 0.0, "unused queue should have a zero fair share")
+
+	// the heavy queue is entitled to 20 of the 30, the light queue to 10
+	res = resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	for appID, queue := range map[string]string{appID1: "root.heavy", appID2: "root.light"} {
+		err = partition.AddApplication(newApplication(appID, "default", queue))
+		assert.NilError(t, err, "failed to add %s to partition", appID)
+	}
+	err = partition.getApplication(appID1).AddAllocationAsk(newAllocationAskRepeat("alloc-1", appID1, res, 10))
+	assert.NilError(t, err, "failed to add ask to app-1")
+	err = partition.getApplication(appID2).AddAllocationAsk(newAllocationAskRepeat("alloc-2", appID2, res, 5))
+	assert.NilError(t, err, "failed to add ask to app-2")
+	for i := 0; i < 15; i++ {
+		if alloc := partition.tryAllocate(); alloc == nil {
+			t.Fatalf("allocation %d did not return any allocation", i)
+		}
+	}
+	// twice the allocation with twice the weight is the same share
+	assert.Equal(t, partition.GetQueueSiblingFairShare("root.heavy"), 0.5, "unexpected fair share for heavy queue")
+	assert.Equal(t, partition.GetQueueSiblingFairShare("root.light"), 0.5, "unexpected fair share for light queue")
+
+	err = partition.getApplication(appID1).AddAllocationAsk(newAllocationAskRepeat("alloc-3", appID1, res, 10))
+	assert.NilError(t, err, "failed to add ask to app-1")
+	err = partition.getApplication(appID2).AddAllocationAsk(newAllocationAskRepeat("alloc-4", appID2, res, 5))
+	assert.NilError(t, err, "failed to add ask to app-2")
+	for i := 0; i < 15; i++ {
+		if alloc := partition.tryAllocate(); alloc == nil {
+			t.Fatalf("allocation %d did not return any allocation", i)
+		}
+	}
+	assert.Equal(t, partition.GetQueueSiblingFairShare("root.heavy"), 1.0, "heavy queue should be at its fair share")
+	assert.Equal(t, partition.GetQueueSiblingFairShare("root.light"), 1.0, "light queue should be at its fair share")
+}
@@ -0,0 +1,52 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+)
+
+// Reserve nodes for all asks of the gang or for none of them, see Queue.TryReserveForGang.
+// The gang is reserved in the queue of the first ask, all asks must belong to applications in that leaf queue.
+// Returns nil if the gang cannot be reserved.
+func (pc *PartitionContext) TryReserveForGang(gang []*objects.AllocationAsk) []*objects.Reservation {
+	if len(gang) == 0 {
+		return nil
+	}
+	queue := pc.GetQueue(gang[0].QueueName)
+	if queue == nil {
+		return nil
+	}
+	// count the reservations before they are made: the scheduling cycle uses the count to find reserved apps
+	pc.Lock()
+	for _, ask := range gang {
+		pc.reservedApps[ask.ApplicationID]++
+	}
+	pc.Unlock()
+	reservations := queue.TryReserveForGang(gang, pc.GetNodeIterator)
+	if reservations == nil {
+		pc.Lock()
+		defer pc.Unlock()
+		for _, ask := range gang {
+			pc.unReserveCount(ask.ApplicationID, 1)
+		}
+		return nil
+	}
+	return reservations
+}
@@ -0,0 +1,207 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+)
+
+// create a partition with the nodes, each node allows the number of reservations given
+func newGangPartition(t *testing.T, nodes int, maxReservations string) *PartitionContext {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10})
+	attributes := map[string]string{"node.maxreservations": maxReservations}
+	for i := 0; i < nodes; i++ {
+		err = partition.AddNode(newNodeWithAttributes(fmt.Sprintf("node-%d", i), nodeRes, attributes), nil)
+		assert.NilError(t, err, "add node to partition should not have failed")
+	}
+	return partition
+}
+
+// add an application in root.default with a gang of equal asks
+func addGang(t *testing.T, partition *PartitionContext, appID string, size int, askSize resources.Quantity) []*objects.AllocationAsk {
+	app := newApplication(appID, "default", defQueue)
+	err := partition.AddApplication(app)
+	assert.NilError(t, err, "add application %s to partition should not have failed", appID)
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": askSize})
+	gang := make([]*objects.AllocationAsk, size)
+	for i := range gang {
+		gang[i] = newAllocationAsk(fmt.Sprintf("%s-ask-%d", appID, i), appID, res)
+		err = app.AddAllocationAsk(gang[i])
+		assert.NilError(t, err, "add ask to application %s should not have failed", appID)
+	}
+	return gang
+}
+
+func TestTryReserveForGang(t *testing.T) {
+	partition := newGangPartition(t, 3, "2")
+	assert.Assert(t, partition.TryReserveForGang(nil) == nil, "empty gang should not be reserved")
+
+	// two asks fit on one node: nodes are reused within the gang while they have room and allow reservations
+	gang := addGang(t, partition, appID1, 4, 5)
+	reservations := partition.TryReserveForGang(gang)
+	assert.Equal(t, len(reservations), 4, "all gang members should have been reserved")
+	perNode := make(map[string]int)
+	for _, res := range reservations {
+		assert.Equal(t, res.ApplicationID, appID1, "unexpected application reserved")
+		perNode[res.NodeID]++
+	}
+	for nodeID, count := range perNode {
+		assert.Equal(t, count, 2, "unexpected gang members reserved on node %s", nodeID)
+	}
+	app := partition.getApplication(appID1)
+	assert.Equal(t, len(app.GetReservations()), 4, "reservations not tracked on the application")
+	assert.Equal(t, partition.reservedApps[appID1], 4, "reservations not tracked on the partition")
+
+	// the gang is allocated from the reservations, which are tracked on the queue
+	alloc := partition.tryReservedAllocate()
+	if alloc == nil {
+		t.Fatal("reserved gang member should have been allocated")
+	}
+	assert.Equal(t, alloc.Result, objects.AllocatedReserved, "allocation should have come from a reservation")
+	assert.Equal(t, len(app.GetReservations()), 3, "allocated reservation not removed from the application")
+	assert.Equal(t, partition.reservedApps[appID1], 3, "allocated reservation not removed from the partition")
+}
+
+func TestTryReserveForGangAllOrNothing(t *testing.T) {
+	partition := newGangPartition(t, 2, "1")
+	// three asks that each need a node of their own do not fit on two nodes
+	gang := addGang(t, partition, appID1, 3, 8)
+	assert.Assert(t, partition.TryReserveForGang(gang) == nil, "gang larger than the cluster should not be reserved")
+	assert.Equal(t, len(partition.getApplication(appID1).GetReservations()), 0, "provisional reservations not released")
+	for _, node := range partition.GetNodes() {
+		assert.Assert(t, !node.IsReserved(), "provisional reservation left on node %s", node.NodeID)
+	}
+	assert.Equal(t, partition.reservedApps[appID1], 0, "failed gang tracked on the partition")
+
+	// a reserved node is not available for the next gang
+	gang = addGang(t, partition, appID2, 2, 8)
+	assert.Equal(t, len(partition.TryReserveForGang(gang)), 2, "gang that fits the cluster should have been reserved")
+	gang = addGang(t, partition, "app-3", 1, 1)
+	assert.Assert(t, partition.TryReserveForGang(gang) == nil, "gang should not be reserved on reserved nodes")
+
+	// all asks must belong to the queue
+	other := newApplication("app-4", "default", defQueue)
+	gang = append(addGang(t, partition, "app-5", 1, 1), newAllocationAsk("alloc-other", other.ApplicationID, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})))
+	assert.Assert(t, partition.TryReserveForGang(gang) == nil, "gang with an application outside the queue should not be reserved")
+}
+
+func TestTryReserveForGangConcurrent(t *testing.T) {
+	const gangs = 8
+	const gangSize = 3
+	// room for half of the gangs, each member needs a node of its own
+	partition := newGangPartition(t, gangs*gangSize/2, "1")
+	gangAsks := make(map[string][]*objects.AllocationAsk)
+	for i := 0; i < gangs; i++ {
+		appID := fmt.Sprintf("app-%d", i)
+		gangAsks[appID] = addGang(t, partition, appID, gangSize, 8)
+	}
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	results := make(map[string][]*objects.Reservation)
+	for appID, gang := range gangAsks {
+		wg.Add(1)
+		go func(appID string, gang []*objects.AllocationAsk) {
+			defer wg.Done()
+			reservations := partition.TryReserveForGang(gang)
+			mutex.Lock()
+			defer mutex.Unlock()
+			results[appID] = reservations
+		}(appID, gang)
+	}
+	wg.Wait()
+
+	reservedNodes := make(map[string]string)
+	succeeded := 0
+	for appID, reservations := range results {
+		app := partition.getApplication(appID)
+		if reservations == nil {
+			assert.Equal(t, len(app.GetReservations()), 0, "failed gang %s left reservations", appID)
+			assert.Equal(t, partition.reservedApps[appID], 0, "failed gang %s tracked on the partition", appID)
+			continue
+		}
+		succeeded++
+		assert.Equal(t, len(reservations), gangSize, "gang %s partially reserved", appID)
+		assert.Equal(t, len(app.GetReservations()), gangSize, "gang %s reservations not tracked on the application", appID)
+		assert.Equal(t, partition.reservedApps[appID], gangSize, "gang %s reservations not tracked on the partition", appID)
+		for _, res := range reservations {
+			other, ok := reservedNodes[res.NodeID]
+			assert.Assert(t, !ok, "node %s reserved for gang %s and %s", res.NodeID, appID, other)
+			reservedNodes[res.NodeID] = appID
+		}
+	}
+	assert.Assert(t, succeeded <= gangs/2, "more gangs reserved than fit the cluster: %d", succeeded)
+	reservedCount := 0
+	for _, node := range partition.GetNodes() {
+		reservedCount += node.GetReservationCount()
+	}
+	assert.Equal(t, reservedCount, succeeded*gangSize, "node reservations do not match the reserved gangs")
+}
+
+func TestTryReserveForGangConstraints(t *testing.T) {
+	newZonePartition := func() *PartitionContext {
+		partition, err := newBasePartition()
+		assert.NilError(t, err, "partition create failed")
+		nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10})
+		for i, zone := range []string{"zone-a", "zone-a", "zone-b", "zone-b"} {
+			attributes := map[string]string{"node.maxreservations": "4", objects.TopologyZoneLabel: zone}
+			err = partition.AddNode(newNodeWithAttributes(fmt.Sprintf("node-%d", i), nodeRes, attributes), nil)
+			assert.NilError(t, err, "add node to partition should not have failed")
+		}
+		return partition
+	}
+
+	// each member is placed in its own required zone
+	partition := newZonePartition()
+	gang := addGang(t, partition, appID1, 2, 1)
+	gang[0].RequiredTopologyKey = objects.TopologyZoneLabel
+	gang[0].RequiredTopologyValue = "zone-a"
+	gang[1].RequiredTopologyKey = objects.TopologyZoneLabel
+	gang[1].RequiredTopologyValue = "zone-b"
+	reservations := partition.TryReserveForGang(gang)
+	assert.Equal(t, len(reservations), 2, "all gang members should have been reserved")
+	for i, zone := range []string{"zone-a", "zone-b"} {
+		node := partition.GetNode(reservations[i].NodeID)
+		assert.Equal(t, node.GetAttribute(objects.TopologyZoneLabel), zone, "member %d not placed in its required zone", i)
+	}
+
+	// the allocations per node of the application include the gang members already placed
+	partition = newZonePartition()
+	gang = addGang(t, partition, appID1, 3, 1)
+	app := partition.getApplication(appID1)
+	app.SetSchedulingConstraints(objects.SchedulingConstraints{MaxAllocationsPerNode: 1, RequiredLabels: map[string]string{objects.TopologyZoneLabel: "zone-b"}})
+	assert.Assert(t, partition.TryReserveForGang(gang) == nil, "gang needing more nodes than allowed should not be reserved")
+	assert.Equal(t, len(app.GetReservations()), 0, "provisional reservations not released")
+	app.SetSchedulingConstraints(objects.SchedulingConstraints{MaxAllocationsPerNode: 1})
+	reservations = partition.TryReserveForGang(gang)
+	assert.Equal(t, len(reservations), 3, "all gang members should have been reserved")
+	nodes := make(map[string]bool)
+	for _, res := range reservations {
+		assert.Assert(t, !nodes[res.NodeID], "node %s used twice for the application", res.NodeID)
+		nodes[res.NodeID] = true
+	}
+}
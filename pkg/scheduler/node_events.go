@@ -0,0 +1,89 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"time"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+)
+
+const (
+	// maximum number of events kept in the history of a node, oldest events are dropped first
+	maxNodeEvents = 50
+	// maximum number of removed nodes for which the history is kept, the oldest removal is dropped first
+	maxRemovedNodeEventLogs = 100
+)
+
+type NodeEventType string
+
+const (
+	NodeAdded             NodeEventType = "Added"
+	NodeRemoved           NodeEventType = "Removed"
+	NodeResized           NodeEventType = "Resized"
+	NodeMarkedDraining    NodeEventType = "MarkedDraining"
+	NodeAllocationAdded   NodeEventType = "AllocationAdded"
+	NodeAllocationRemoved NodeEventType = "AllocationRemoved"
+)
+
+// A change in the life cycle of a node or the allocations on the node.
+// The resource is the node capacity for node changes and the allocated resource for allocation changes.
+type NodeEvent struct {
+	Type     NodeEventType
+	Time     time.Time
+	Resource *resources.Resource
+}
+
+// Return a copy of the recorded events for the node, oldest event first.
+// The history of a removed node is kept until maxRemovedNodeEventLogs other nodes were removed after it.
+func (pc *PartitionContext) GetNodeEventHistory(nodeID string) []NodeEvent {
+	pc.RLock()
+	defer pc.RUnlock()
+	history := pc.nodeEventLog[nodeID]
+	if history == nil {
+		return nil
+	}
+	events := make([]NodeEvent, len(history))
+	copy(events, history)
+	return events
+}
+
+// Record an event for the node.
+func (pc *PartitionContext) addNodeEvent(nodeID string, eventType NodeEventType, res *resources.Resource) {
+	pc.Lock()
+	defer pc.Unlock()
+	pc.addNodeEventInternal(nodeID, eventType, res)
+}
+
+// Record an event for the node, the history is capped at maxNodeEvents.
+// Unlocked version must be called holding the partition lock.
+func (pc *PartitionContext) addNodeEventInternal(nodeID string, eventType NodeEventType, res *resources.Resource) {
+	event := NodeEvent{
+		Type: eventType,
+		Time: time.Now(),
+	}
+	if res != nil {
+		event.Resource = res.Clone()
+	}
+	history := append(pc.nodeEventLog[nodeID], event)
+	if len(history) > maxNodeEvents {
+		history = history[len(history)-maxNodeEvents:]
+	}
+	pc.nodeEventLog[nodeID] = history
+}
@@ -0,0 +1,214 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"fmt"
+	"math/bits"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+)
+
+func init() {
+	RegisterFeature("topology-spread")
+}
+
+// The node attribute that explicitly sets the size class of a node, for example small, medium or large.
+const NodeSizeLabel = "yunikorn.apache.org/node-size"
+
+// The nodes that fall in one bucket of the node capacity distribution.
+type CapacityBucket struct {
+	Count     int
+	Capacity  *resources.Resource
+	Allocated *resources.Resource
+}
+
+// Return the nodes in the partition that have the label, an attribute of the node, set to the value.
+func (pc *PartitionContext) GetNodesByLabel(labelKey, labelValue string) []*objects.Node {
+	pc.RLock()
+	defer pc.RUnlock()
+	return pc.getNodesByID(pc.nodesByLabel[labelKey][labelValue])
+}
+
+// Add or replace attributes of a node in the partition and keep the label index in sync.
+// Returns false if the node is not part of the partition.
+func (pc *PartitionContext) UpdateNodeAttributes(nodeID string, attributes map[string]string) bool {
+	pc.Lock()
+	defer pc.Unlock()
+	node := pc.nodes[nodeID]
+	if node == nil {
+		return false
+	}
+	pc.removeNodeLabels(nodeID, node.GetAttributes())
+	node.UpdateAttributes(attributes)
+	pc.addNodeLabels(nodeID, node.GetAttributes())
+	return true
+}
+
+// Return the nodes in the partition grouped on the value of the node group key attribute.
+// Nodes that do not have the attribute set are in the group with the empty name.
+func (pc *PartitionContext) GetNodeGroups() map[string][]*objects.Node {
+	pc.RLock()
+	defer pc.RUnlock()
+	groups := make(map[string][]*objects.Node, len(pc.nodeGroups))
+	for group, nodeIDs := range pc.nodeGroups {
+		groups[group] = pc.getNodesByID(nodeIDs)
+	}
+	return groups
+}
+
+// Return the node attribute used to group the nodes.
+func (pc *PartitionContext) GetNodeGroupKey() string {
+	pc.RLock()
+	defer pc.RUnlock()
+	return pc.nodeGroupKey
+}
+
+// Set the node attribute used to group the nodes, the node group index is rebuilt if the key changes.
+// Unlocked version must be called holding the partition lock.
+func (pc *PartitionContext) setNodeGroupKey(groupKey string) {
+	if groupKey == pc.nodeGroupKey && pc.nodeGroups != nil {
+		return
+	}
+	pc.nodeGroupKey = groupKey
+	pc.nodeGroups = make(map[string][]string)
+	for nodeID, node := range pc.nodes {
+		group := node.GetAttribute(groupKey)
+		pc.nodeGroups[group] = append(pc.nodeGroups[group], nodeID)
+	}
+}
+
+// Return the node count, total capacity and allocated capacity per node size.
+// The size of a node is the value of the node size label. Nodes without the label are bucketed on their memory
+// capacity in power of two tiers, a node without memory ends up in the "memory:0" tier.
+func (pc *PartitionContext) GetNodeCapacityDistribution() map[string]CapacityBucket {
+	pc.RLock()
+	defer pc.RUnlock()
+	distribution := make(map[string]CapacityBucket)
+	for _, node := range pc.nodes {
+		capacity := node.GetCapacity()
+		size := node.GetAttribute(NodeSizeLabel)
+		if size == "" {
+			size = memoryTier(capacity.Resources[resources.MEMORY])
+		}
+		bucket, ok := distribution[size]
+		if !ok {
+			bucket = CapacityBucket{Capacity: resources.NewResource(), Allocated: resources.NewResource()}
+		}
+		bucket.Count++
+		bucket.Capacity.AddTo(capacity)
+		bucket.Allocated.AddTo(node.GetAllocatedResource())
+		distribution[size] = bucket
+	}
+	return distribution
+}
+
+// Return the name of the power of two memory tier the quantity falls in: the tier [lower, 2*lower)
+// is named "memory:<lower>-<2*lower>".
+func memoryTier(memory resources.Quantity) string {
+	if memory <= 0 {
+		return "memory:0"
+	}
+	lower := uint64(1) << uint(bits.Len64(uint64(memory))-1)
+	return fmt.Sprintf("memory:%d-%d", lower, lower<<1)
+}
+
+// Return the nodes grouped on the value of the topology key, nodes without the topology key are not returned.
+// The node group index is used if the topology key is the node group key, the label index otherwise.
+func (pc *PartitionContext) getNodesByTopology(topologyKey string) map[string][]*objects.Node {
+	pc.RLock()
+	defer pc.RUnlock()
+	index := pc.nodesByLabel[topologyKey]
+	if topologyKey == pc.nodeGroupKey {
+		index = pc.nodeGroups
+	}
+	groups := make(map[string][]*objects.Node, len(index))
+	for value, nodeIDs := range index {
+		if value == "" {
+			continue
+		}
+		groups[value] = pc.getNodesByID(nodeIDs)
+	}
+	return groups
+}
+
+// Return the nodes for the node IDs, unknown node IDs are skipped.
+// Unlocked version must be called holding the partition lock.
+func (pc *PartitionContext) getNodesByID(nodeIDs []string) []*objects.Node {
+	nodes := make([]*objects.Node, 0, len(nodeIDs))
+	for _, nodeID := range nodeIDs {
+		if node := pc.nodes[nodeID]; node != nil {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// Add the node to the label index for each of the labels and to the node group index.
+// Unlocked version must be called holding the partition lock.
+func (pc *PartitionContext) addNodeLabels(nodeID string, labels map[string]string) {
+	group := labels[pc.nodeGroupKey]
+	pc.nodeGroups[group] = append(pc.nodeGroups[group], nodeID)
+	for key, value := range labels {
+		values := pc.nodesByLabel[key]
+		if values == nil {
+			values = make(map[string][]string)
+			pc.nodesByLabel[key] = values
+		}
+		values[value] = append(values[value], nodeID)
+	}
+}
+
+// Remove the node from the label index for each of the labels and from the node group index.
+// Empty entries are cleaned up.
+// Unlocked version must be called holding the partition lock.
+func (pc *PartitionContext) removeNodeLabels(nodeID string, labels map[string]string) {
+	group := labels[pc.nodeGroupKey]
+	if nodeIDs := removeNodeID(pc.nodeGroups[group], nodeID); len(nodeIDs) == 0 {
+		delete(pc.nodeGroups, group)
+	} else {
+		pc.nodeGroups[group] = nodeIDs
+	}
+	for key, value := range labels {
+		values := pc.nodesByLabel[key]
+		if values == nil {
+			continue
+		}
+		nodeIDs := removeNodeID(values[value], nodeID)
+		if len(nodeIDs) == 0 {
+			delete(values, value)
+		} else {
+			values[value] = nodeIDs
+		}
+		if len(values) == 0 {
+			delete(pc.nodesByLabel, key)
+		}
+	}
+}
+
+// Remove the node ID from the list, the list is changed in place.
+func removeNodeID(nodeIDs []string, nodeID string) []string {
+	for i, id := range nodeIDs {
+		if id == nodeID {
+			return append(nodeIDs[:i], nodeIDs[i+1:]...)
+		}
+	}
+	return nodeIDs
+}
@@ -0,0 +1,191 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"sort"
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+	siCommon "github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/common"
+	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
+)
+
+func nodeIDs(nodes []*objects.Node) []string {
+	ids := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		ids = append(ids, node.NodeID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestGetNodesByLabel(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10})
+	err = partition.AddNode(newNodeWithAttributes(nodeID1, nodeRes, map[string]string{"zone": "a", "gpu": "true"}), nil)
+	assert.NilError(t, err, "add node to partition should not have failed")
+	err = partition.AddNode(newNodeWithAttributes(nodeID2, nodeRes, map[string]string{"zone": "a"}), nil)
+	assert.NilError(t, err, "add node to partition should not have failed")
+	err = partition.AddNode(newNodeWithAttributes("node-3", nodeRes, map[string]string{"zone": "b"}), nil)
+	assert.NilError(t, err, "add node to partition should not have failed")
+
+	assert.DeepEqual(t, nodeIDs(partition.GetNodesByLabel("zone", "a")), []string{nodeID1, nodeID2})
+	assert.DeepEqual(t, nodeIDs(partition.GetNodesByLabel("zone", "b")), []string{"node-3"})
+	assert.DeepEqual(t, nodeIDs(partition.GetNodesByLabel("gpu", "true")), []string{nodeID1})
+	// absent labels and values
+	assert.Equal(t, len(partition.GetNodesByLabel("zone", "c")), 0, "absent value should return no nodes")
+	assert.Equal(t, len(partition.GetNodesByLabel("rack", "a")), 0, "absent label should return no nodes")
+
+	// remove a node
+	partition.removeNode(nodeID1)
+	assert.DeepEqual(t, nodeIDs(partition.GetNodesByLabel("zone", "a")), []string{nodeID2})
+	assert.Equal(t, len(partition.GetNodesByLabel("gpu", "true")), 0, "removed node should not be returned")
+	_, ok := partition.nodesByLabel["gpu"]
+	assert.Assert(t, !ok, "empty label should have been removed from the index")
+
+	// update the label of a node
+	assert.Assert(t, partition.UpdateNodeAttributes(nodeID2, map[string]string{"zone": "b"}), "update of an existing node failed")
+	assert.Equal(t, len(partition.GetNodesByLabel("zone", "a")), 0, "updated node should not be returned for the old value")
+	assert.DeepEqual(t, nodeIDs(partition.GetNodesByLabel("zone", "b")), []string{nodeID2, "node-3"})
+	assert.Assert(t, !partition.UpdateNodeAttributes("unknown", map[string]string{"zone": "b"}), "update of an unknown node should fail")
+}
+
+func TestGetNodeGroups(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	assert.Equal(t, partition.GetNodeGroupKey(), configs.DefaultNodeGroupKey, "unexpected default group key")
+	assert.Equal(t, len(partition.GetNodeGroups()), 0, "empty partition should not have groups")
+
+	zone := configs.DefaultNodeGroupKey
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10})
+	nodes := map[string]map[string]string{
+		nodeID1:  {zone: "zone-a", "rack": "r1"},
+		nodeID2:  {zone: "zone-a", "rack": "r2"},
+		"node-3": {zone: "zone-b", "rack": "r1"},
+		"node-4": {"rack": "r2"},
+	}
+	for nodeID, attributes := range nodes {
+		err = partition.AddNode(newNodeWithAttributes(nodeID, nodeRes, attributes), nil)
+		assert.NilError(t, err, "add node %s to partition should not have failed", nodeID)
+	}
+	groups := partition.GetNodeGroups()
+	assert.Equal(t, len(groups), 3, "unexpected groups: %v", groups)
+	assert.DeepEqual(t, nodeIDs(groups["zone-a"]), []string{nodeID1, nodeID2})
+	assert.DeepEqual(t, nodeIDs(groups["zone-b"]), []string{"node-3"})
+	// node without a zone label is in the empty group
+	assert.DeepEqual(t, nodeIDs(groups[""]), []string{"node-4"})
+
+	// membership follows attribute updates and node removal
+	assert.Assert(t, partition.UpdateNodeAttributes("node-4", map[string]string{zone: "zone-b"}), "update of an existing node failed")
+	partition.removeNode(nodeID1)
+	groups = partition.GetNodeGroups()
+	assert.Equal(t, len(groups), 2, "unexpected groups: %v", groups)
+	assert.DeepEqual(t, nodeIDs(groups["zone-a"]), []string{nodeID2})
+	assert.DeepEqual(t, nodeIDs(groups["zone-b"]), []string{"node-3", "node-4"})
+
+	// changing the group key rebuilds the groups
+	partition.setPartitionProperties(map[string]string{configs.NodeGroupKey: "rack"})
+	assert.Equal(t, partition.GetNodeGroupKey(), "rack", "group key not changed")
+	groups = partition.GetNodeGroups()
+	assert.Equal(t, len(groups), 2, "unexpected groups: %v", groups)
+	assert.DeepEqual(t, nodeIDs(groups["r1"]), []string{"node-3"})
+	assert.DeepEqual(t, nodeIDs(groups["r2"]), []string{nodeID2, "node-4"})
+	assert.DeepEqual(t, nodeIDs(partition.getNodesByTopologyValue("rack", "r2")), []string{nodeID2, "node-4"})
+	assert.DeepEqual(t, nodeIDs(partition.getNodesByTopologyValue(zone, "zone-b")), []string{"node-3", "node-4"})
+}
+
+func TestGetNodeCapacityDistribution(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	assert.Equal(t, len(partition.GetNodeCapacityDistribution()), 0, "empty partition should not have buckets")
+
+	small := resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 1000, resources.VCORE: 1000})
+	medium := resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 4000, resources.VCORE: 4000})
+	large := resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 16000, resources.VCORE: 16000})
+	nodes := map[string]*resources.Resource{
+		nodeID1:  small,
+		nodeID2:  small,
+		"node-3": medium,
+		"node-4": large,
+		"node-5": large,
+		"node-6": large,
+	}
+	for nodeID, nodeRes := range nodes {
+		err = partition.AddNode(newNodeMaxResource(nodeID, nodeRes), nil)
+		assert.NilError(t, err, "add node %s to partition should not have failed", nodeID)
+	}
+	dist := partition.GetNodeCapacityDistribution()
+	assert.Equal(t, len(dist), 3, "unexpected buckets: %v", dist)
+	expected := map[string]struct {
+		count    int
+		capacity *resources.Resource
+	}{
+		"memory:512-1024":   {2, resources.Multiply(small, 2)},
+		"memory:2048-4096":  {1, medium},
+		"memory:8192-16384": {3, resources.Multiply(large, 3)},
+	}
+	for tier, exp := range expected {
+		bucket, ok := dist[tier]
+		assert.Assert(t, ok, "bucket %s not found: %v", tier, dist)
+		assert.Equal(t, bucket.Count, exp.count, "unexpected node count for %s", tier)
+		assert.Assert(t, resources.Equals(bucket.Capacity, exp.capacity), "unexpected capacity for %s: %v", tier, bucket.Capacity)
+		assert.Assert(t, resources.IsZero(bucket.Allocated), "unexpected allocated for %s: %v", tier, bucket.Allocated)
+	}
+
+	// the size label overrides the memory tier
+	assert.Assert(t, partition.UpdateNodeAttributes("node-3", map[string]string{NodeSizeLabel: "large"}), "update of an existing node failed")
+	assert.Assert(t, partition.UpdateNodeAttributes("node-4", map[string]string{NodeSizeLabel: "large"}), "update of an existing node failed")
+	dist = partition.GetNodeCapacityDistribution()
+	assert.Equal(t, len(dist), 3, "unexpected buckets: %v", dist)
+	_, ok := dist["memory:2048-4096"]
+	assert.Assert(t, !ok, "labelled node should not be in a memory tier")
+	assert.Equal(t, dist["large"].Count, 2, "unexpected node count for the large label")
+	assert.Assert(t, resources.Equals(dist["large"].Capacity, resources.Add(medium, large)), "unexpected capacity for the large label")
+	assert.Equal(t, dist["memory:8192-16384"].Count, 2, "unexpected node count for the large memory tier")
+
+	assert.Equal(t, memoryTier(0), "memory:0", "unexpected tier for a node without memory")
+	assert.Equal(t, memoryTier(1), "memory:1-2", "unexpected tier for the smallest memory")
+	assert.Equal(t, memoryTier(1024), "memory:1024-2048", "unexpected tier for a power of two")
+}
+
+func TestUpdateNodeLabelsEvent(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	cc := &ClusterContext{partitions: map[string]*PartitionContext{partition.Name: partition}}
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10})
+	err = partition.AddNode(newNodeWithAttributes(nodeID1, nodeRes, map[string]string{"zone": "a"}), nil)
+	assert.NilError(t, err, "add node to partition should not have failed")
+
+	cc.updateNodes(&si.UpdateRequest{
+		UpdatedNodes: []*si.UpdateNodeInfo{{
+			NodeID:     nodeID1,
+			Attributes: map[string]string{siCommon.NodePartition: partition.Name, "zone": "b"},
+			Action:     si.UpdateNodeInfo_UPDATE,
+		}},
+	})
+	assert.Equal(t, len(partition.GetNodesByLabel("zone", "a")), 0, "old label value should not return the node")
+	assert.DeepEqual(t, nodeIDs(partition.GetNodesByLabel("zone", "b")), []string{nodeID1})
+	assert.Equal(t, partition.GetNode(nodeID1).GetAttribute("zone"), "b", "node attribute not updated")
+}
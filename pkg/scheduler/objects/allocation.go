@@ -20,6 +20,7 @@ package objects
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/apache/incubator-yunikorn-core/pkg/common"
 	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
@@ -55,6 +56,8 @@ type Allocation struct {
 	AllocatedResource *resources.Resource
 	Result            allocationResult
 	Releases          []*Allocation
+
+	createTime time.Time // the time this allocation was created
 }
 
 func NewAllocation(uuid, nodeID string, ask *AllocationAsk) *Allocation {
@@ -70,6 +73,7 @@ func NewAllocation(uuid, nodeID string, ask *AllocationAsk) *Allocation {
 		Priority:          ask.priority,
 		AllocatedResource: ask.AllocatedResource,
 		Result:            Allocated,
+		createTime:        time.Now(),
 	}
 }
 
@@ -101,6 +105,29 @@ func NewAllocationFromSI(alloc *si.Allocation) *Allocation {
 	return NewAllocation(alloc.UUID, alloc.NodeID, ask)
 }
 
+// Return the time this allocation was created.
+func (a *Allocation) GetCreateTime() time.Time {
+	return a.createTime
+}
+
+// Return a copy of the allocation. The ask is shared with the original allocation,
+// the releases are not copied.
+func (a *Allocation) Clone() *Allocation {
+	if a == nil {
+		return nil
+	}
+	clone := *a
+	clone.AllocatedResource = a.AllocatedResource.Clone()
+	if a.Tags != nil {
+		clone.Tags = make(map[string]string, len(a.Tags))
+		for k, v := range a.Tags {
+			clone.Tags[k] = v
+		}
+	}
+	clone.Releases = nil
+	return &clone
+}
+
 // Convert the Allocation into a SI object. This is a limited set of values that gets copied into the SI.
 // We only use this to communicate *back* to the RM. All other fields are considered incoming fields from
 // the RM into the core.
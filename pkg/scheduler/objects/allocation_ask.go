@@ -20,26 +20,49 @@ package objects
 
 import (
 	"fmt"
+	"math"
+	"strconv"
 	"sync"
 	"time"
 
+	"go.uber.org/zap"
+
 	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
 	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
 )
 
+const (
+	// ask tag that sets the gang (task group) the ask belongs to
+	AskTagGangID = "gangID"
+	// ask tags that restrict the allocations to the nodes with the topology value, both tags must be set
+	AskTagRequiredTopologyKey   = "requiredTopologyKey"
+	AskTagRequiredTopologyValue = "requiredTopologyValue"
+	// ask tags that spread the allocations over the topology values, the max skew must be a positive integer
+	AskTagTopologyKey = "topologyKey"
+	AskTagMaxSkew     = "maxSkew"
+)
+
 type AllocationAsk struct {
 	// Extracted info
-	AllocationKey     string
-	AllocatedResource *resources.Resource
-	ApplicationID     string
-	PartitionName     string
-	QueueName         string
-	Tags              map[string]string
+	AllocationKey         string
+	AllocatedResource     *resources.Resource
+	ApplicationID         string
+	PartitionName         string
+	QueueName             string
+	Tags                  map[string]string
+	TopologyKey           string // node attribute used to spread the allocations, empty means no spreading
+	MaxSkew               int    // maximum difference in allocations between topology values, only used with a TopologyKey
+	RequiredTopologyKey   string // node topology label the allocations are restricted to, empty means no restriction
+	RequiredTopologyValue string // value of the required topology label, only used with a RequiredTopologyKey
+	GangID                string // name of the gang (task group) the ask is part of, empty means no gang
 
 	// Private fields need protection
 	pendingRepeatAsk int32
 	createTime       time.Time // the time this ask was created (used in reservations)
 	priority         int32
+	priorityOffset   int32                  // offset from the priority class of the application
+	constraints      *SchedulingConstraints // scheduling constraints of the application, shared and not modified
 	maxAllocations   int32
 
 	sync.RWMutex
@@ -54,8 +77,25 @@ func NewAllocationAsk(ask *si.AllocationAsk) *AllocationAsk {
 		ApplicationID:     ask.ApplicationID,
 		PartitionName:     ask.PartitionName,
 		Tags:              ask.Tags,
+		GangID:            ask.Tags[AskTagGangID],
 		createTime:        time.Now(),
 	}
+	if key, value := ask.Tags[AskTagRequiredTopologyKey], ask.Tags[AskTagRequiredTopologyValue]; key != "" && value != "" {
+		saa.RequiredTopologyKey = key
+		saa.RequiredTopologyValue = value
+	}
+	if key := ask.Tags[AskTagTopologyKey]; key != "" {
+		maxSkew, err := saa.GetIntTagValue(AskTagMaxSkew)
+		if err != nil || maxSkew <= 0 || maxSkew > math.MaxInt32 {
+			log.Logger().Warn("topology spread ignored: max skew must be a positive integer",
+				zap.String("allocationKey", saa.AllocationKey),
+				zap.String("topologyKey", key),
+				zap.String("maxSkew", ask.Tags[AskTagMaxSkew]))
+		} else {
+			saa.TopologyKey = key
+			saa.MaxSkew = int(maxSkew)
+		}
+	}
 	saa.priority = saa.normalizePriority(ask.Priority)
 	return saa
 }
@@ -89,6 +129,13 @@ func (aa *AllocationAsk) GetPendingAskRepeat() int32 {
 	return aa.pendingRepeatAsk
 }
 
+// Return the normalised priority of the ask
+func (aa *AllocationAsk) GetPriority() int32 {
+	aa.RLock()
+	defer aa.RUnlock()
+	return aa.priority
+}
+
 // Return the time this ask was created
 // Should be treated as read only not te be modified
 func (aa *AllocationAsk) GetCreateTime() time.Time {
@@ -97,6 +144,117 @@ func (aa *AllocationAsk) GetCreateTime() time.Time {
 	return aa.createTime
 }
 
+// Return the resource requested for a single allocation of this ask.
+// This is the same as the AllocatedResource of the ask.
+func (aa *AllocationAsk) GetResourcePerCount() *resources.Resource {
+	return aa.AllocatedResource
+}
+
+// Return the resource requested for all allocations of this ask: the resource per allocation multiplied
+// by the maximum number of allocations. This does not change when allocations are made.
+func (aa *AllocationAsk) GetTotalRequestedResource() *resources.Resource {
+	aa.RLock()
+	defer aa.RUnlock()
+	return resources.Multiply(aa.AllocatedResource, int64(aa.maxAllocations))
+}
+
+// Create a copy of the ask including the pending repeats, priority, priority offset and creation time.
+func (aa *AllocationAsk) Clone() *AllocationAsk {
+	aa.RLock()
+	defer aa.RUnlock()
+	return &AllocationAsk{
+		AllocationKey:         aa.AllocationKey,
+		AllocatedResource:     aa.AllocatedResource.Clone(),
+		ApplicationID:         aa.ApplicationID,
+		PartitionName:         aa.PartitionName,
+		QueueName:             aa.QueueName,
+		Tags:                  aa.Tags,
+		TopologyKey:           aa.TopologyKey,
+		MaxSkew:               aa.MaxSkew,
+		RequiredTopologyKey:   aa.RequiredTopologyKey,
+		RequiredTopologyValue: aa.RequiredTopologyValue,
+		GangID:                aa.GangID,
+		pendingRepeatAsk:      aa.pendingRepeatAsk,
+		createTime:            aa.createTime,
+		priority:              aa.priority,
+		priorityOffset:        aa.priorityOffset,
+		constraints:           aa.constraints,
+		maxAllocations:        aa.maxAllocations,
+	}
+}
+
+// Return true if the allocations for this ask must be spread over the topology values.
+func (aa *AllocationAsk) HasTopologySpread() bool {
+	return aa.TopologyKey != "" && aa.MaxSkew > 0
+}
+
+// Return true if the allocations for this ask must be placed on nodes with the required topology value.
+func (aa *AllocationAsk) HasRequiredTopology() bool {
+	return aa.RequiredTopologyKey != ""
+}
+
+// Return the value of the tag and true if the tag is set on the ask, an empty string and false otherwise.
+func (aa *AllocationAsk) GetTagValue(key string) (string, bool) {
+	value, ok := aa.Tags[key]
+	return value, ok
+}
+
+// Return the value of the tag as an integer.
+// An error is returned if the tag is not set on the ask or the value is not an integer.
+func (aa *AllocationAsk) GetIntTagValue(key string) (int64, error) {
+	value, ok := aa.GetTagValue(key)
+	if !ok {
+		return 0, fmt.Errorf("tag %s not set on ask %s", key, aa.AllocationKey)
+	}
+	number, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("tag %s on ask %s is not an integer: %s", key, aa.AllocationKey, value)
+	}
+	return number, nil
+}
+
+// Return the value of the tag as a duration, the value must be parsable by time.ParseDuration.
+// An error is returned if the tag is not set on the ask or the value is not a duration.
+func (aa *AllocationAsk) GetDurationTagValue(key string) (time.Duration, error) {
+	value, ok := aa.GetTagValue(key)
+	if !ok {
+		return 0, fmt.Errorf("tag %s not set on ask %s", key, aa.AllocationKey)
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("tag %s on ask %s is not a duration: %s", key, aa.AllocationKey, value)
+	}
+	return duration, nil
+}
+
+// Return the priority of the ask including the offset of the priority class of the application.
+func (aa *AllocationAsk) GetEffectivePriority() int32 {
+	aa.RLock()
+	defer aa.RUnlock()
+	return aa.priority + aa.priorityOffset
+}
+
+// Set the offset of the priority class of the application
+func (aa *AllocationAsk) setPriorityOffset(offset int32) {
+	aa.Lock()
+	defer aa.Unlock()
+	aa.priorityOffset = offset
+}
+
+// Return the scheduling constraints of the application, nil if the application has no constraints.
+// Should be treated as read only not to be modified
+func (aa *AllocationAsk) GetSchedulingConstraints() *SchedulingConstraints {
+	aa.RLock()
+	defer aa.RUnlock()
+	return aa.constraints
+}
+
+func (aa *AllocationAsk) setSchedulingConstraints(constraints *SchedulingConstraints) {
+	aa.Lock()
+	defer aa.Unlock()
+	aa.constraints = constraints
+}
+
 // Set the queue name after it is added to the application
 func (aa *AllocationAsk) setQueue(queueName string) {
 	aa.Lock()
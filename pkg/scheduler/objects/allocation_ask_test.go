@@ -25,6 +25,7 @@ import (
 	"gotest.tools/assert"
 
 	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
 )
 
 func TestPendingAskRepeat(t *testing.T) {
@@ -57,3 +58,117 @@ func TestGetCreateTime(t *testing.T) {
 		t.Fatal("create time stamp should have been modified")
 	}
 }
+
+func TestAskRequestedResource(t *testing.T) {
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10, "second": 5})
+	ask := newAllocationAsk("alloc-1", "app-1", res)
+	assert.Assert(t, resources.Equals(ask.GetResourcePerCount(), res), "single ask: unexpected resource per count")
+	assert.Assert(t, resources.Equals(ask.GetTotalRequestedResource(), res), "single ask: total should be the same as per count")
+
+	ask = newAllocationAskRepeat("alloc-2", "app-1", res, 3)
+	assert.Assert(t, resources.Equals(ask.GetResourcePerCount(), res), "repeat ask: unexpected resource per count")
+	expected := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 30, "second": 15})
+	assert.Assert(t, resources.Equals(ask.GetTotalRequestedResource(), expected), "repeat ask: unexpected total requested resource")
+	// allocating does not change the total requested
+	assert.Assert(t, ask.UpdatePendingAskRepeat(-1), "decrease of pending ask should not have failed")
+	assert.Assert(t, resources.Equals(ask.GetTotalRequestedResource(), expected), "total requested changed after allocation")
+	assert.Assert(t, resources.Equals(ask.GetResourcePerCount(), res), "per count changed after allocation")
+}
+
+func TestGetTagValue(t *testing.T) {
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10})
+	ask := newAllocationAsk("alloc-1", "app-1", res)
+	// nil tags
+	value, ok := ask.GetTagValue("string")
+	assert.Assert(t, !ok, "tag should not be found on ask without tags")
+	assert.Equal(t, value, "", "missing tag should return empty value")
+	number, err := ask.GetIntTagValue("int")
+	assert.ErrorContains(t, err, "not set", "missing int tag should have failed")
+	assert.Equal(t, number, int64(0), "missing int tag should return zero")
+	duration, err := ask.GetDurationTagValue("duration")
+	assert.ErrorContains(t, err, "not set", "missing duration tag should have failed")
+	assert.Equal(t, duration, time.Duration(0), "missing duration tag should return zero")
+
+	ask.Tags = map[string]string{
+		"string":       "value",
+		"empty":        "",
+		"int":          "-42",
+		"int-bad":      "4.2",
+		"duration":     "1m30s",
+		"duration-bad": "90",
+	}
+	value, ok = ask.GetTagValue("string")
+	assert.Assert(t, ok, "tag should be found")
+	assert.Equal(t, value, "value", "unexpected tag value")
+	value, ok = ask.GetTagValue("empty")
+	assert.Assert(t, ok, "empty tag should be found")
+	assert.Equal(t, value, "", "unexpected empty tag value")
+	_, ok = ask.GetTagValue("unknown")
+	assert.Assert(t, !ok, "unknown tag should not be found")
+
+	number, err = ask.GetIntTagValue("int")
+	assert.NilError(t, err, "int tag should have been parsed")
+	assert.Equal(t, number, int64(-42), "unexpected int tag value")
+	number, err = ask.GetIntTagValue("int-bad")
+	assert.ErrorContains(t, err, "not an integer", "malformed int tag should have failed")
+	assert.Equal(t, number, int64(0), "malformed int tag should return zero")
+	_, err = ask.GetIntTagValue("unknown")
+	assert.ErrorContains(t, err, "not set", "unknown int tag should have failed")
+
+	duration, err = ask.GetDurationTagValue("duration")
+	assert.NilError(t, err, "duration tag should have been parsed")
+	assert.Equal(t, duration, 90*time.Second, "unexpected duration tag value")
+	duration, err = ask.GetDurationTagValue("duration-bad")
+	assert.ErrorContains(t, err, "not a duration", "malformed duration tag should have failed")
+	assert.Equal(t, duration, time.Duration(0), "malformed duration tag should return zero")
+	_, err = ask.GetDurationTagValue("empty")
+	assert.ErrorContains(t, err, "not a duration", "empty duration tag should have failed")
+}
+
+func TestNewAllocationAskTopology(t *testing.T) {
+	newAsk := func(tags map[string]string) *AllocationAsk {
+		return NewAllocationAsk(&si.AllocationAsk{
+			AllocationKey:  "alloc-1",
+			ApplicationID:  "app-1",
+			ResourceAsk:    resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1}).ToProto(),
+			MaxAllocations: 1,
+			Tags:           tags,
+		})
+	}
+	ask := newAsk(nil)
+	assert.Assert(t, !ask.HasRequiredTopology(), "ask without tags should not have a required topology")
+
+	ask = newAsk(map[string]string{AskTagRequiredTopologyKey: TopologyZoneLabel, AskTagRequiredTopologyValue: "zone-a"})
+	assert.Assert(t, ask.HasRequiredTopology(), "required topology not set from the tags")
+	assert.Equal(t, ask.RequiredTopologyKey, TopologyZoneLabel, "unexpected required topology key")
+	assert.Equal(t, ask.RequiredTopologyValue, "zone-a", "unexpected required topology value")
+	clone := ask.Clone()
+	assert.Equal(t, clone.RequiredTopologyKey, TopologyZoneLabel, "required topology key not cloned")
+	assert.Equal(t, clone.RequiredTopologyValue, "zone-a", "required topology value not cloned")
+
+	// both tags must be set
+	ask = newAsk(map[string]string{AskTagRequiredTopologyKey: TopologyZoneLabel})
+	assert.Assert(t, !ask.HasRequiredTopology(), "required topology without value should not be set")
+	ask = newAsk(map[string]string{AskTagRequiredTopologyValue: "zone-a"})
+	assert.Assert(t, !ask.HasRequiredTopology(), "required topology without key should not be set")
+
+
+	// topology spread
+	ask = newAsk(map[string]string{AskTagTopologyKey: TopologyZoneLabel, AskTagMaxSkew: "2"})
+	assert.Assert(t, ask.HasTopologySpread(), "topology spread not set from the tags")
+	assert.Equal(t, ask.TopologyKey, TopologyZoneLabel, "unexpected topology key")
+	assert.Equal(t, ask.MaxSkew, 2, "unexpected max skew")
+	clone = ask.Clone()
+	assert.Equal(t, clone.TopologyKey, TopologyZoneLabel, "topology key not cloned")
+	assert.Equal(t, clone.MaxSkew, 2, "max skew not cloned")
+	// the max skew must be a positive integer
+	for _, maxSkew := range []string{"", "0", "-1", "1.5", "x"} {
+		tags := map[string]string{AskTagTopologyKey: TopologyZoneLabel}
+		if maxSkew != "" {
+			tags[AskTagMaxSkew] = maxSkew
+		}
+		ask = newAsk(tags)
+		assert.Assert(t, !ask.HasTopologySpread(), "topology spread with max skew %q should not be set", maxSkew)
+		assert.Equal(t, ask.TopologyKey, "", "topology key with max skew %q should not be set", maxSkew)
+	}
+}
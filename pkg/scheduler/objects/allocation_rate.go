@@ -0,0 +1,62 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objects
+
+import (
+	"time"
+)
+
+// The longest window the allocation rate can be calculated for, counts older than this are overwritten.
+const MaxAllocationRateWindow = 10 * time.Minute
+
+// Number of allocations per second for the last MaxAllocationRateWindow.
+// The buckets are created on the first allocation. Not locked, the owner must protect access.
+type allocationCounter struct {
+	counts  []int64 // allocations in the second
+	seconds []int64 // the second since the epoch the count is for
+}
+
+// Count an allocation made at the time.
+func (ac *allocationCounter) add(now time.Time) {
+	if ac.counts == nil {
+		size := int(MaxAllocationRateWindow / time.Second)
+		ac.counts = make([]int64, size)
+		ac.seconds = make([]int64, size)
+	}
+	second := now.Unix()
+	idx := second % int64(len(ac.seconds))
+	if ac.seconds[idx] != second {
+		ac.seconds[idx] = second
+		ac.counts[idx] = 0
+	}
+	ac.counts[idx]++
+}
+
+// Return the number of allocations in the window that ends at the time, the window is truncated to whole seconds.
+func (ac *allocationCounter) count(now time.Time, window time.Duration) int64 {
+	last := now.Unix()
+	first := last - int64(window/time.Second)
+	var total int64
+	for idx, second := range ac.seconds {
+		if second > first && second <= last {
+			total += ac.counts[idx]
+		}
+	}
+	return total
+}
@@ -54,6 +54,26 @@ func TestNewAlloc(t *testing.T) {
 	assert.Equal(t, allocStr, expected, "Strings should have been equal")
 }
 
+func TestAllocClone(t *testing.T) {
+	var nilAlloc *Allocation
+	assert.Assert(t, nilAlloc.Clone() == nil, "clone of nil allocation should be nil")
+
+	res, err := resources.NewResourceFromConf(map[string]string{"first": "1"})
+	assert.NilError(t, err, "Resource creation failed")
+	ask := newAllocationAsk("ask-1", "app-1", res)
+	ask.Tags = map[string]string{"key": "value"}
+	alloc := NewAllocation("test-uuid", "node-1", ask)
+	clone := alloc.Clone()
+	assert.Equal(t, clone.UUID, alloc.UUID, "UUID not copied")
+	assert.Equal(t, clone.GetCreateTime(), alloc.GetCreateTime(), "create time not copied")
+	assert.Assert(t, !alloc.GetCreateTime().IsZero(), "create time not set")
+	// changes to the clone must not change the original
+	clone.AllocatedResource.AddTo(res)
+	clone.Tags["key"] = "changed"
+	assert.Assert(t, resources.Equals(alloc.AllocatedResource, res), "allocated resource of original changed")
+	assert.Equal(t, alloc.Tags["key"], "value", "tags of original changed")
+}
+
 func TestNewReservedAlloc(t *testing.T) {
 	res, err := resources.NewResourceFromConf(map[string]string{"first": "1"})
 	assert.NilError(t, err, "Resource creation failed")
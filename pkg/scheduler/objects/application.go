@@ -21,6 +21,7 @@ package objects
 import (
 	"fmt"
 	"math"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -35,15 +36,39 @@ import (
 	"github.com/apache/incubator-yunikorn-core/pkg/handler"
 	"github.com/apache/incubator-yunikorn-core/pkg/interfaces"
 	"github.com/apache/incubator-yunikorn-core/pkg/log"
+	"github.com/apache/incubator-yunikorn-core/pkg/metrics"
 	"github.com/apache/incubator-yunikorn-core/pkg/rmproxy/rmevent"
 	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
 )
 
+const (
+	// application tag that sets the maximum number of reservations for the application
+	appTagMaxReservations = "application.maxreservations"
+	// application tag that sets the maximum number of allocations the application can have at the same time
+	appTagMaxConcurrentAllocations = "application.maxconcurrentallocations"
+	// application tag that sets the priority class of the application
+	AppTagPriorityClassName = "priorityClassName"
+	// application tags that set the scheduling constraints of the application
+	appTagRequiredLabels        = "application.constraints.requiredlabels"
+	appTagForbiddenLabels       = "application.constraints.forbiddenlabels"
+	appTagRequiredNodeCount     = "application.constraints.requirednodecount"
+	appTagMaxAllocationsPerNode = "application.constraints.maxallocationspernode"
+)
+
 var (
 	reservationDelay = 2 * time.Second
 	startingTimeout  = time.Minute * 5
 )
 
+// The result of placing an application by the placement rules: the rule that placed the application,
+// the rule parameter from the configuration (empty if the rule has none) and the queue the rule resolved.
+type PlacementAudit struct {
+	RuleName      string
+	RuleParam     string
+	ResolvedQueue string
+	Time          time.Time
+}
+
 type Application struct {
 	ApplicationID  string
 	Partition      string
@@ -51,17 +76,31 @@ type Application struct {
 	SubmissionTime time.Time
 
 	// Private fields need protection
-	queue             *Queue                    // queue the application is running in
-	pending           *resources.Resource       // pending resources from asks for the app
-	reservations      map[string]*reservation   // a map of reservations
-	requests          map[string]*AllocationAsk // a map of asks
-	sortedRequests    []*AllocationAsk
-	user              security.UserGroup     // owner of the application
-	tags              map[string]string      // application tags used in scheduling
-	allocatedResource *resources.Resource    // total allocated resources
-	allocations       map[string]*Allocation // list of all allocations
-	stateMachine      *fsm.FSM               // application state machine
-	stateTimer        *time.Timer            // timer for state time
+	queue                 *Queue                    // queue the application is running in
+	pending               *resources.Resource       // pending resources from asks for the app
+	reservations          map[string]*reservation   // a map of reservations
+	requests              map[string]*AllocationAsk // a map of asks
+	sortedRequests        []*AllocationAsk
+	user                  security.UserGroup     // owner of the application
+	tags                  map[string]string      // application tags used in scheduling
+	allocatedResource     *resources.Resource    // total allocated resources
+	totalAllocated        *resources.Resource    // sum of all allocations over the lifetime of the application
+	allocations           map[string]*Allocation // list of all allocations
+	stateMachine          *fsm.FSM               // application state machine
+	stateTimer            *time.Timer            // timer for state time
+	maxAllocationTime     time.Duration          // longest time between ask creation and allocation
+	avgAllocationTime     time.Duration          // running average of the time between ask creation and allocation
+	allocationCount       int64                  // number of allocations used in the running average
+	createdAt             time.Time              // time the application was created, never changes
+	maxReservationsPerApp int                    // maximum number of reservations for the application, 0 means no limit
+	maxConcurrentAllocs   int                    // maximum number of allocations for the application at the same time, 0 means no limit
+	eventLog              *applicationEventLog   // latest events in the life cycle of the application
+	placement             *PlacementAudit        // placement rule result, nil if not placed by the rules
+	priorityClassName     string                 // priority class of the application, empty if not set
+	priorityOffset        int32                  // priority offset of the class, added to the priority of each ask
+	constraints           *SchedulingConstraints // constraints on the nodes used by the asks, nil if not set
+	completedGangAllocs   map[string]int         // number of released allocations per gang (task group)
+	isSimulation          bool                   // application is part of a simulated partition, no metrics or events
 
 	rmEventHandler handler.EventHandler
 	rmID           string
@@ -70,20 +109,45 @@ type Application struct {
 }
 
 func newBlankApplication(appID, partition, queueName string, ugi security.UserGroup, tags map[string]string) *Application {
-	return &Application{
+	now := time.Now()
+	app := &Application{
 		ApplicationID:     appID,
 		Partition:         partition,
 		QueueName:         queueName,
-		SubmissionTime:    time.Now(),
+		SubmissionTime:    now,
+		createdAt:         now,
 		user:              ugi,
 		tags:              tags,
 		pending:           resources.NewResource(),
 		allocatedResource: resources.NewResource(),
+		totalAllocated:    resources.NewResource(),
 		requests:          make(map[string]*AllocationAsk),
 		reservations:      make(map[string]*reservation),
 		allocations:       make(map[string]*Allocation),
 		stateMachine:      NewAppState(),
+		eventLog:          newApplicationEventLog(),
+	}
+	app.maxReservationsPerApp = getLimitFromTag(appID, tags, appTagMaxReservations)
+	app.maxConcurrentAllocs = getLimitFromTag(appID, tags, appTagMaxConcurrentAllocations)
+	app.constraints = getConstraintsFromTags(appID, tags)
+	return app
+}
+
+// Get a limit from the application tags. Returns 0, which means no limit, if the tag is not set or invalid.
+func getLimitFromTag(appID string, tags map[string]string, key string) int {
+	value, ok := tags[key]
+	if !ok {
+		return 0
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 0 {
+		log.Logger().Warn("ignoring invalid application limit",
+			zap.String("appID", appID),
+			zap.String("tag", key),
+			zap.String("limit", value))
+		return 0
 	}
+	return limit
 }
 
 func NewApplication(appID, partition, queueName string, ugi security.UserGroup, tags map[string]string, eventHandler handler.EventHandler, rmID string) *Application {
@@ -101,6 +165,12 @@ func (sa *Application) String() string {
 		sa.ApplicationID, sa.Partition, sa.QueueName, sa.SubmissionTime)
 }
 
+// Return the time the application was created.
+// The creation time is set when the application is created and never changes: no locking needed.
+func (sa *Application) GetCreateTime() time.Time {
+	return sa.createdAt
+}
+
 // Set the reservation delay.
 // Set when the cluster context is created to disable reservation.
 func SetReservationDelay(delay time.Duration) {
@@ -154,6 +224,7 @@ func (sa *Application) OnStateChange(event *fsm.Event) {
 		StateTransitionTimestamp: time.Now().UnixNano(),
 		Message:                  fmt.Sprintf("{Status change triggered by the event : %v}", event),
 	})
+	sa.RecordEvent(AppStateChanged, fmt.Sprintf("state changed from %s to %s on %s", event.Src, event.Dst, event.Event), nil)
 
 	if sa.rmEventHandler != nil {
 		sa.rmEventHandler.HandleEvent(
@@ -213,6 +284,34 @@ func (sa *Application) GetReservations() []string {
 	return keys
 }
 
+// Return the time each reservation for the app was made.
+// This will return an empty array if there are no reservations.
+func (sa *Application) GetReservationTimes() []time.Time {
+	sa.RLock()
+	defer sa.RUnlock()
+	times := make([]time.Time, 0, len(sa.reservations))
+	for _, reserve := range sa.reservations {
+		times = append(times, reserve.reservedAt)
+	}
+	return times
+}
+
+// Return the nodes this app has reservations on with the total resources reserved on each node.
+// This will return an empty map if there are no reservations.
+func (sa *Application) GetReservedNodes() map[string]*resources.Resource {
+	sa.RLock()
+	defer sa.RUnlock()
+	nodes := make(map[string]*resources.Resource)
+	for _, reserve := range sa.reservations {
+		if res, ok := nodes[reserve.nodeID]; ok {
+			res.AddTo(reserve.ask.AllocatedResource)
+		} else {
+			nodes[reserve.nodeID] = reserve.ask.AllocatedResource.Clone()
+		}
+	}
+	return nodes
+}
+
 // Return the allocation ask for the key, nil if not found
 func (sa *Application) GetSchedulingAllocationAsk(allocationKey string) *AllocationAsk {
 	sa.RLock()
@@ -220,6 +319,61 @@ func (sa *Application) GetSchedulingAllocationAsk(allocationKey string) *Allocat
 	return sa.requests[allocationKey]
 }
 
+// Return a list of all asks for this application that still have repeats pending.
+func (sa *Application) GetPendingAsks() []*AllocationAsk {
+	sa.RLock()
+	defer sa.RUnlock()
+	asks := make([]*AllocationAsk, 0)
+	for _, ask := range sa.requests {
+		if ask.GetPendingAskRepeat() > 0 {
+			asks = append(asks, ask)
+		}
+	}
+	return asks
+}
+
+// Return the number of asks for this application that still have repeats pending.
+func (sa *Application) getPendingAskCount() int {
+	sa.RLock()
+	defer sa.RUnlock()
+	var count int
+	for _, ask := range sa.requests {
+		if ask.GetPendingAskRepeat() > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// Return true if any ask of this application still has repeats pending.
+func (sa *Application) HasPendingAsks() bool {
+	sa.RLock()
+	defer sa.RUnlock()
+	return sa.hasPendingAsks()
+}
+
+// Unlocked version must be called holding the application lock
+func (sa *Application) hasPendingAsks() bool {
+	for _, ask := range sa.requests {
+		if ask.GetPendingAskRepeat() > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Return true if the application has any allocations.
+func (sa *Application) HasActiveAllocations() bool {
+	sa.RLock()
+	defer sa.RUnlock()
+	return sa.hasActiveAllocations()
+}
+
+// Unlocked version must be called holding the application lock
+func (sa *Application) hasActiveAllocations() bool {
+	return len(sa.allocations) > 0
+}
+
 // Return the allocated resources for this application
 func (sa *Application) GetAllocatedResource() *resources.Resource {
 	sa.RLock()
@@ -266,6 +420,7 @@ func (sa *Application) RemoveAllocationAsk(allocKey string) int {
 		deltaPendingResource = sa.pending
 		sa.pending = resources.NewResource()
 		sa.requests = make(map[string]*AllocationAsk)
+		sa.RecordEvent(AppAskRemoved, "all asks removed", deltaPendingResource)
 	} else {
 		// cleanup the reservation for this allocation
 		for _, key := range sa.GetAskReservations(allocKey) {
@@ -286,16 +441,17 @@ func (sa *Application) RemoveAllocationAsk(allocKey string) int {
 			deltaPendingResource = resources.MultiplyBy(ask.AllocatedResource, float64(ask.GetPendingAskRepeat()))
 			sa.pending.SubFrom(deltaPendingResource)
 			delete(sa.requests, allocKey)
+			sa.RecordEvent(AppAskRemoved, fmt.Sprintf("ask %s removed", allocKey), deltaPendingResource)
 		}
 	}
 	// clean up the queue pending resources
 	sa.queue.decPendingResource(deltaPendingResource)
 	// Check if we need to change state based on the ask removal:
-	// 1) if pending is zero (no more asks left)
-	// 2) if confirmed allocations is zero (nothing is running)
+	// 1) if there are no pending asks left
+	// 2) if there are no allocations (nothing is running)
 	// Change the state to waiting.
-	// When the resource trackers are zero we should not expect anything to come in later.
-	if resources.IsZero(sa.pending) && resources.IsZero(sa.allocatedResource) {
+	// When both are empty we should not expect anything to come in later.
+	if !sa.hasPendingAsks() && !sa.hasActiveAllocations() {
 		if err := sa.HandleApplicationEvent(waitApplication); err != nil {
 			log.Logger().Warn("Application state not changed to Waiting while updating ask(s)",
 				zap.String("currentState", sa.CurrentState()),
@@ -318,6 +474,8 @@ func (sa *Application) AddAllocationAsk(ask *AllocationAsk) error {
 		return fmt.Errorf("invalid ask added to app %s: %v", sa.ApplicationID, ask)
 	}
 	ask.setQueue(sa.queue.QueuePath)
+	ask.setPriorityOffset(sa.priorityOffset)
+	ask.setSchedulingConstraints(sa.constraints)
 	delta := resources.Multiply(ask.AllocatedResource, int64(ask.GetPendingAskRepeat()))
 
 	var oldAskResource *resources.Resource = nil
@@ -337,6 +495,7 @@ func (sa *Application) AddAllocationAsk(ask *AllocationAsk) error {
 		}
 	}
 	sa.requests[ask.AllocationKey] = ask
+	sa.RecordEvent(AppAskAdded, fmt.Sprintf("ask %s added", ask.AllocationKey), delta)
 
 	// Update total pending resource
 	delta.SubFrom(oldAskResource)
@@ -430,6 +589,12 @@ func (sa *Application) Reserve(node *Node, ask *AllocationAsk) error {
 	if !sa.canAskReserve(ask) {
 		return fmt.Errorf("reservation of ask exceeds pending repeat, pending ask repeat %d", ask.GetPendingAskRepeat())
 	}
+	if sa.maxReservationsPerApp > 0 && len(sa.reservations) >= sa.maxReservationsPerApp {
+		return fmt.Errorf("reservation creation failed, appID %s reached the reservation limit %d", sa.ApplicationID, sa.maxReservationsPerApp)
+	}
+	if constraints := ask.GetSchedulingConstraints(); constraints != nil && !constraints.AllowsNode(node, sa.ApplicationID) {
+		return fmt.Errorf("reservation creation failed, node %s does not pass the scheduling constraints of appID %s", node.NodeID, sa.ApplicationID)
+	}
 	// check if we can reserve the node before reserving on the app
 	if err := node.Reserve(sa, ask); err != nil {
 		return err
@@ -490,6 +655,36 @@ func (sa *Application) unReserveInternal(node *Node, ask *AllocationAsk) (int, e
 	return 0, nil
 }
 
+// Remove all reservations for the application that are older than the threshold at the time passed in.
+// The reservations are removed from the node and the queue as well.
+// The return value is the number of reservations released
+func (sa *Application) RemoveStaleReservations(threshold time.Duration, now time.Time) int {
+	sa.Lock()
+	defer sa.Unlock()
+	var toRelease int
+	for key, reserve := range sa.reservations {
+		if reserve.getAge(now) <= threshold {
+			continue
+		}
+		releases, err := sa.unReserveInternal(reserve.node, reserve.ask)
+		if err != nil {
+			log.Logger().Warn("Removal of stale reservation failed",
+				zap.String("appID", sa.ApplicationID),
+				zap.String("reservationKey", key),
+				zap.Error(err))
+			continue
+		}
+		log.Logger().Info("stale reservation removed",
+			zap.String("appID", sa.ApplicationID),
+			zap.String("reservationKey", key),
+			zap.Duration("threshold", threshold))
+		// clean up the queue reservation
+		sa.queue.UnReserve(sa.ApplicationID, releases)
+		toRelease += releases
+	}
+	return toRelease
+}
+
 // Return the allocation reservations on any node.
 // The returned array is 0 or more keys into the reservations map.
 // No locking must be called while holding the lock
@@ -557,17 +752,22 @@ func (sa *Application) getOutstandingRequests(headRoom *resources.Resource, tota
 }
 
 // Try a regular allocation of the pending requests
-func (sa *Application) tryAllocate(headRoom *resources.Resource, nodeIterator func() interfaces.NodeIterator) *Allocation {
+func (sa *Application) tryAllocate(headRoom *resources.Resource, nodeIterator func(ask *AllocationAsk) interfaces.NodeIterator) *Allocation {
 	sa.Lock()
 	defer sa.Unlock()
+	// nothing to allocate: do not sort the requests
+	if !sa.hasPendingAsks() {
+		return nil
+	}
 	// make sure the request are sorted
 	sa.sortRequests(false)
 	// get all the requests from the app sorted in order
 	for _, request := range sa.sortedRequests {
 		// resource must fit in headroom otherwise skip the request
 		if !resources.FitIn(headRoom, request.AllocatedResource) {
+			sa.RecordEvent(AppQuotaDenied, fmt.Sprintf("ask %s does not fit in the headroom of queue %s", request.AllocationKey, sa.QueueName), request.AllocatedResource)
 			// post scheduling events via the event plugin
-			if eventCache := events.GetEventCache(); eventCache != nil {
+			if eventCache := events.GetEventCache(); eventCache != nil && !sa.isSimulation {
 				message := fmt.Sprintf("Application %s does not fit into %s queue", request.ApplicationID, sa.QueueName)
 				if event, err := events.CreateRequestEventRecord(request.AllocationKey, request.ApplicationID, "InsufficientQueueResources", message); err != nil {
 					log.Logger().Warn("Event creation failed",
@@ -579,7 +779,7 @@ func (sa *Application) tryAllocate(headRoom *resources.Resource, nodeIterator fu
 			}
 			continue
 		}
-		iterator := nodeIterator()
+		iterator := nodeIterator(request)
 		if iterator != nil {
 			alloc := sa.tryNodes(request, iterator)
 			// have a candidate return it
@@ -593,7 +793,7 @@ func (sa *Application) tryAllocate(headRoom *resources.Resource, nodeIterator fu
 }
 
 // Try a reserved allocation of an outstanding reservation
-func (sa *Application) tryReservedAllocate(headRoom *resources.Resource, nodeIterator func() interfaces.NodeIterator) *Allocation {
+func (sa *Application) tryReservedAllocate(headRoom *resources.Resource, nodeIterator func(ask *AllocationAsk) interfaces.NodeIterator) *Allocation {
 	sa.Lock()
 	defer sa.Unlock()
 	// process all outstanding reservations and pick the first one that fits
@@ -615,6 +815,10 @@ func (sa *Application) tryReservedAllocate(headRoom *resources.Resource, nodeIte
 			alloc := newReservedAllocation(Unreserved, reserve.nodeID, unreserveAsk)
 			return alloc
 		}
+		// the constraints might have changed or been reached since the reservation was made
+		if constraints := ask.GetSchedulingConstraints(); constraints != nil && !constraints.AllowsNode(reserve.node, sa.ApplicationID) {
+			return newReservedAllocation(Unreserved, reserve.nodeID, ask)
+		}
 		// check if this fits in the queue's head room
 		if !resources.FitIn(headRoom, ask.AllocatedResource) {
 			continue
@@ -629,7 +833,7 @@ func (sa *Application) tryReservedAllocate(headRoom *resources.Resource, nodeIte
 	}
 	// lets try this on all other nodes
 	for _, reserve := range sa.reservations {
-		iterator := nodeIterator()
+		iterator := nodeIterator(reserve.ask)
 		if iterator != nil {
 			alloc := sa.tryNodesNoReserve(reserve.ask, iterator, reserve.nodeID)
 			// have a candidate return it, including the node that was reserved
@@ -809,6 +1013,25 @@ func (sa *Application) SetQueueName(queuePath string) {
 	sa.QueueName = queuePath
 }
 
+// Record how the placement rules placed the application.
+func (sa *Application) SetPlacementAudit(audit PlacementAudit) {
+	sa.Lock()
+	defer sa.Unlock()
+	sa.placement = &audit
+}
+
+// Return how the placement rules placed the application, nil if the application was not placed by the rules.
+// The audit is not changed when the application changes queues after placement.
+func (sa *Application) GetPlacementAudit() *PlacementAudit {
+	sa.RLock()
+	defer sa.RUnlock()
+	if sa.placement == nil {
+		return nil
+	}
+	audit := *sa.placement
+	return &audit
+}
+
 // Set the leaf queue the application runs in.
 func (sa *Application) SetQueue(queue *Queue) {
 	sa.Lock()
@@ -817,6 +1040,21 @@ func (sa *Application) SetQueue(queue *Queue) {
 	sa.queue = queue
 }
 
+// Mark the application as part of a simulated partition: no metrics are updated and no events are sent.
+func (sa *Application) SetSimulation() {
+	sa.Lock()
+	defer sa.Unlock()
+	sa.isSimulation = true
+}
+
+// Return the allocation based on the uuid of the allocation.
+// returns nil if the allocation is not found
+func (sa *Application) GetAllocation(uuid string) *Allocation {
+	sa.RLock()
+	defer sa.RUnlock()
+	return sa.allocations[uuid]
+}
+
 // get a copy of all allocations of the application
 func (sa *Application) GetAllAllocations() []*Allocation {
 	sa.RLock()
@@ -829,7 +1067,9 @@ func (sa *Application) GetAllAllocations() []*Allocation {
 	return allocations
 }
 
-// Add a new Allocation to the application
+// Add a new Allocation to the application.
+// The concurrent allocation limit is not checked: it only applies to new scheduling decisions, an existing
+// allocation, like a recovered one, is always added.
 func (sa *Application) AddAllocation(info *Allocation) {
 	// add the allocation
 	sa.Lock()
@@ -837,6 +1077,82 @@ func (sa *Application) AddAllocation(info *Allocation) {
 	sa.addAllocationInternal(info)
 }
 
+// Return the maximum number of allocations the application can have at the same time, 0 means no limit.
+func (sa *Application) GetMaxConcurrentAllocations() int {
+	sa.RLock()
+	defer sa.RUnlock()
+	return sa.maxConcurrentAllocs
+}
+
+// Set the priority class of the application and the offset the class adds to the priority of the asks.
+// The offset is applied to the asks already registered and to all asks added later.
+func (sa *Application) SetPriorityClass(className string, priorityOffset int32) {
+	sa.Lock()
+	defer sa.Unlock()
+	sa.priorityClassName = className
+	sa.priorityOffset = priorityOffset
+	for _, ask := range sa.requests {
+		ask.setPriorityOffset(priorityOffset)
+	}
+}
+
+// Return the priority class of the application, empty if not set.
+func (sa *Application) GetPriorityClassName() string {
+	sa.RLock()
+	defer sa.RUnlock()
+	return sa.priorityClassName
+}
+
+// Return the priority offset of the priority class of the application.
+func (sa *Application) GetPriorityOffset() int32 {
+	sa.RLock()
+	defer sa.RUnlock()
+	return sa.priorityOffset
+}
+
+// Set the scheduling constraints of the application, empty constraints remove all constraints.
+// The constraints are applied to the asks already registered and to all asks added later.
+// The constraints set by the shim on submit are read from the application tags when the application is created.
+func (sa *Application) SetSchedulingConstraints(constraints SchedulingConstraints) {
+	sa.Lock()
+	defer sa.Unlock()
+	sa.constraints = nil
+	if !constraints.IsEmpty() {
+		cloned := constraints.clone()
+		sa.constraints = &cloned
+	}
+	for _, ask := range sa.requests {
+		ask.setSchedulingConstraints(sa.constraints)
+	}
+}
+
+// Return a copy of the scheduling constraints of the application.
+func (sa *Application) GetSchedulingConstraints() SchedulingConstraints {
+	sa.RLock()
+	defer sa.RUnlock()
+	if sa.constraints == nil {
+		return SchedulingConstraints{}
+	}
+	return sa.constraints.clone()
+}
+
+// Return true if the application cannot get a new allocation without going over the concurrent allocation limit.
+func (sa *Application) IsAllocationLimitReached() bool {
+	sa.RLock()
+	defer sa.RUnlock()
+	return sa.isAllocationLimitReached()
+}
+
+// No locking must be called while holding the lock
+func (sa *Application) isAllocationLimitReached() bool {
+	return sa.maxConcurrentAllocs > 0 && len(sa.allocations) >= sa.maxConcurrentAllocs
+}
+
+// Record that the application was skipped while scheduling as it reached the concurrent allocation limit.
+func (sa *Application) recordLimitDenied() {
+	sa.RecordEvent(AppRateLimitDenied, fmt.Sprintf("concurrent allocation limit %d reached", sa.GetMaxConcurrentAllocations()), nil)
+}
+
 // Add the Allocation to the application
 // No locking must be called while holding the lock
 func (sa *Application) addAllocationInternal(info *Allocation) {
@@ -849,6 +1165,74 @@ func (sa *Application) addAllocationInternal(info *Allocation) {
 	}
 	sa.allocations[info.UUID] = info
 	sa.allocatedResource = resources.Add(sa.allocatedResource, info.AllocatedResource)
+	sa.totalAllocated = resources.Add(sa.totalAllocated, info.AllocatedResource)
+	sa.RecordEvent(AppAllocationAdded, fmt.Sprintf("allocation %s added on node %s", info.UUID, info.NodeID), info.AllocatedResource)
+}
+
+// Update the allocation latency of the app for an allocation that was confirmed by the partition.
+// An allocation that is reverted is never confirmed and does not change the latency.
+func (sa *Application) UpdateAllocationTime(ask *AllocationAsk) {
+	sa.Lock()
+	defer sa.Unlock()
+	sa.updateAllocationTime(ask)
+}
+
+// Update the allocation latency tracking for the app based on the ask that was just allocated.
+// No locking must be called while holding the lock
+func (sa *Application) updateAllocationTime(ask *AllocationAsk) {
+	latency := time.Since(ask.GetCreateTime())
+	if latency > sa.maxAllocationTime {
+		sa.maxAllocationTime = latency
+	}
+	sa.allocationCount++
+	sa.avgAllocationTime += (latency - sa.avgAllocationTime) / time.Duration(sa.allocationCount)
+	if sa.queue != nil && !sa.isSimulation {
+		metrics.GetSchedulerMetrics().ObserveAppAllocationLatency(sa.queue.QueuePath, latency)
+	}
+}
+
+// Revert an allocation made by the scheduler that could not be processed by the partition.
+// The allocation is removed from the app, node and queue and the ask repeat is restored.
+func (sa *Application) RevertAllocation(node *Node, alloc *Allocation) {
+	sa.Lock()
+	defer sa.Unlock()
+	node.RemoveAllocation(alloc.UUID)
+	if err := sa.queue.DecAllocatedResource(alloc.AllocatedResource); err != nil {
+		log.Logger().Warn("queue update failed unexpectedly",
+			zap.Error(err))
+	}
+	if _, err := sa.updateAskRepeatInternal(alloc.Ask, 1); err != nil {
+		log.Logger().Warn("ask repeat update failed unexpectedly",
+			zap.Error(err))
+	}
+	if sa.allocations[alloc.UUID] == alloc {
+		delete(sa.allocations, alloc.UUID)
+		sa.allocatedResource = resources.Sub(sa.allocatedResource, alloc.AllocatedResource)
+		sa.totalAllocated = resources.Sub(sa.totalAllocated, alloc.AllocatedResource)
+		sa.RecordEvent(AppAllocationFreed, fmt.Sprintf("allocation %s reverted", alloc.UUID), alloc.AllocatedResource)
+	}
+}
+
+// Return the sum of all allocations made for the app over its lifetime.
+// Allocations that are released are still included.
+func (sa *Application) GetTotalAllocatedResource() *resources.Resource {
+	sa.RLock()
+	defer sa.RUnlock()
+	return sa.totalAllocated.Clone()
+}
+
+// Return the longest time between ask creation and allocation for the app.
+func (sa *Application) GetMaxAllocationTime() time.Duration {
+	sa.RLock()
+	defer sa.RUnlock()
+	return sa.maxAllocationTime
+}
+
+// Return the average time between ask creation and allocation for the app.
+func (sa *Application) GetAvgAllocationTime() time.Duration {
+	sa.RLock()
+	defer sa.RUnlock()
+	return sa.avgAllocationTime
 }
 
 // Remove a specific allocation from the application.
@@ -863,8 +1247,10 @@ func (sa *Application) RemoveAllocation(uuid string) *Allocation {
 		// When app has the allocation, update map, and update allocated resource of the app
 		sa.allocatedResource = resources.Sub(sa.allocatedResource, alloc.AllocatedResource)
 		delete(sa.allocations, uuid)
-		// When the resource trackers are zero we should not expect anything to come in later.
-		if resources.IsZero(sa.pending) && resources.IsZero(sa.allocatedResource) {
+		sa.trackCompletedGangAllocation(alloc)
+		sa.RecordEvent(AppAllocationFreed, fmt.Sprintf("allocation %s released", uuid), alloc.AllocatedResource)
+		// When there are no asks and allocations left we should not expect anything to come in later.
+		if !sa.hasPendingAsks() && !sa.hasActiveAllocations() {
 			if err := sa.HandleApplicationEvent(waitApplication); err != nil {
 				log.Logger().Warn("Application state not changed to Waiting while removing some allocation(s)",
 					zap.String("currentState", sa.CurrentState()),
@@ -886,12 +1272,14 @@ func (sa *Application) RemoveAllAllocations() []*Allocation {
 	allocationsToRelease := make([]*Allocation, 0)
 	for _, alloc := range sa.allocations {
 		allocationsToRelease = append(allocationsToRelease, alloc)
+		sa.trackCompletedGangAllocation(alloc)
+		sa.RecordEvent(AppAllocationFreed, fmt.Sprintf("allocation %s released", alloc.UUID), alloc.AllocatedResource)
 	}
 	// cleanup allocated resource for app
 	sa.allocatedResource = resources.NewResource()
 	sa.allocations = make(map[string]*Allocation)
-	// When the resource trackers are zero we should not expect anything to come in later.
-	if resources.IsZero(sa.pending) {
+	// When there are no asks left we should not expect anything to come in later.
+	if !sa.hasPendingAsks() {
 		if err := sa.HandleApplicationEvent(waitApplication); err != nil {
 			log.Logger().Warn("Application state not changed to Waiting while removing all allocations",
 				zap.String("currentState", sa.CurrentState()),
@@ -903,12 +1291,17 @@ func (sa *Application) RemoveAllAllocations() []*Allocation {
 
 // get a copy of the user details for the application
 func (sa *Application) GetUser() security.UserGroup {
-	sa.Lock()
-	defer sa.Unlock()
+	sa.RLock()
+	defer sa.RUnlock()
 
 	return sa.user
 }
 
+// Get a copy of the user and groups of the application, used for the ACL checks of the application.
+func (sa *Application) GetUserGroup() security.UserGroup {
+	return sa.GetUser()
+}
+
 // Get a tag from the application
 // Note: Tags are not case sensitive
 func (sa *Application) GetTag(tag string) string {
@@ -924,3 +1317,15 @@ func (sa *Application) GetTag(tag string) string {
 	}
 	return tagVal
 }
+
+// Get a copy of all tags from the application
+func (sa *Application) GetTags() map[string]string {
+	sa.RLock()
+	defer sa.RUnlock()
+
+	tags := make(map[string]string, len(sa.tags))
+	for key, val := range sa.tags {
+		tags[key] = val
+	}
+	return tags
+}
@@ -0,0 +1,122 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objects
+
+import (
+	"sync"
+	"time"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+)
+
+const (
+	// maximum number of events kept in the log of an application, oldest events are overwritten first
+	maxApplicationEvents = 50
+	// time during which the same denial is only recorded once
+	denialEventWindow = time.Minute
+)
+
+type ApplicationEventType string
+
+const (
+	AppAdmitted        ApplicationEventType = "Admitted"
+	AppAskAdded        ApplicationEventType = "AskAdded"
+	AppAskRemoved      ApplicationEventType = "AskRemoved"
+	AppAllocationAdded ApplicationEventType = "AllocationAdded"
+	AppAllocationFreed ApplicationEventType = "AllocationReleased"
+	AppStateChanged    ApplicationEventType = "StateChanged"
+	AppQuotaDenied     ApplicationEventType = "QuotaDenied"
+	AppRateLimitDenied ApplicationEventType = "RateLimitDenied"
+)
+
+// A change in the life cycle of an application, the resource is only set for events that change resources.
+type ApplicationEvent struct {
+	Type        ApplicationEventType
+	Time        time.Time
+	Description string
+	Resource    *resources.Resource
+}
+
+// Ring buffer of the latest application events.
+// The log has its own lock as state changes are recorded with and without the application lock held.
+type applicationEventLog struct {
+	events  *common.RingBuffer
+	denials map[string]time.Time // time a denial was last recorded, keyed by type and description
+
+	sync.Mutex
+}
+
+func newApplicationEventLog() *applicationEventLog {
+	return &applicationEventLog{
+		events:  common.NewRingBuffer(maxApplicationEvents),
+		denials: make(map[string]time.Time),
+	}
+}
+
+// Add the event to the log, overwriting the oldest event if the log is full.
+// Denials are retried each scheduling cycle: the same denial, which names the ask, is only recorded once
+// within the denial window.
+func (el *applicationEventLog) add(event ApplicationEvent) {
+	el.Lock()
+	defer el.Unlock()
+	if event.Type == AppQuotaDenied || event.Type == AppRateLimitDenied {
+		// forget the denials outside the window
+		for key, recorded := range el.denials {
+			if event.Time.Sub(recorded) >= denialEventWindow {
+				delete(el.denials, key)
+			}
+		}
+		key := string(event.Type) + ":" + event.Description
+		if _, ok := el.denials[key]; ok {
+			return
+		}
+		el.denials[key] = event.Time
+	}
+	el.events.Add(event)
+}
+
+// Return a copy of the events, oldest event first.
+func (el *applicationEventLog) getEvents() []ApplicationEvent {
+	el.Lock()
+	defer el.Unlock()
+	events := make([]ApplicationEvent, el.events.Len())
+	for i := range events {
+		events[i] = el.events.Get(i).(ApplicationEvent)
+	}
+	return events
+}
+
+// Record an event for the application.
+func (sa *Application) RecordEvent(eventType ApplicationEventType, description string, res *resources.Resource) {
+	event := ApplicationEvent{
+		Type:        eventType,
+		Time:        time.Now(),
+		Description: description,
+	}
+	if res != nil {
+		event.Resource = res.Clone()
+	}
+	sa.eventLog.add(event)
+}
+
+// Return a copy of the recorded events for the application, oldest event first.
+func (sa *Application) GetEventLog() []ApplicationEvent {
+	return sa.eventLog.getEvents()
+}
@@ -121,19 +121,29 @@ func NewAppState() *fsm.FSM {
 				event.Args[0].(*Application).ClearStartingTimer()
 			},
 			fmt.Sprintf("leave_%s", New.String()): func(event *fsm.Event) {
-				metrics.GetSchedulerMetrics().IncTotalApplicationsAdded()
+				if !event.Args[0].(*Application).isSimulation {
+					metrics.GetSchedulerMetrics().IncTotalApplicationsAdded()
+				}
 			},
 			fmt.Sprintf("enter_%s", Rejected.String()): func(event *fsm.Event) {
-				metrics.GetSchedulerMetrics().IncTotalApplicationsRejected()
+				if !event.Args[0].(*Application).isSimulation {
+					metrics.GetSchedulerMetrics().IncTotalApplicationsRejected()
+				}
 			},
 			fmt.Sprintf("enter_%s", Running.String()): func(event *fsm.Event) {
-				metrics.GetSchedulerMetrics().IncTotalApplicationsRunning()
+				if !event.Args[0].(*Application).isSimulation {
+					metrics.GetSchedulerMetrics().IncTotalApplicationsRunning()
+				}
 			},
 			fmt.Sprintf("leave_%s", Running.String()): func(event *fsm.Event) {
-				metrics.GetSchedulerMetrics().DecTotalApplicationsRunning()
+				if !event.Args[0].(*Application).isSimulation {
+					metrics.GetSchedulerMetrics().DecTotalApplicationsRunning()
+				}
 			},
 			fmt.Sprintf("enter_%s", Completed.String()): func(event *fsm.Event) {
-				metrics.GetSchedulerMetrics().IncTotalApplicationsCompleted()
+				if !event.Args[0].(*Application).isSimulation {
+					metrics.GetSchedulerMetrics().IncTotalApplicationsCompleted()
+				}
 			},
 		},
 	)
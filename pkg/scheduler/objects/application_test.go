@@ -20,15 +20,26 @@ package objects
 
 import (
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"gotest.tools/assert"
 
 	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/security"
+	"github.com/apache/incubator-yunikorn-core/pkg/interfaces"
 )
 
 // test basic reservations
+func TestGetUserGroup(t *testing.T) {
+	user := security.UserGroup{User: "testuser", Groups: []string{"group1", "group2"}}
+	app := newBlankApplication(appID1, "default", "root.unknown", user, nil)
+	got := app.GetUserGroup()
+	assert.Equal(t, got.User, "testuser", "unexpected user")
+	assert.Equal(t, strings.Join(got.Groups, ","), "group1,group2", "unexpected groups")
+}
+
 func TestAppReservation(t *testing.T) {
 	app := newApplication(appID1, "default", "root.unknown")
 	if app == nil || app.ApplicationID != appID1 {
@@ -124,6 +135,74 @@ func TestAppReservation(t *testing.T) {
 }
 
 // test multiple reservations from one allocation
+func TestAppReservationLimit(t *testing.T) {
+	app := newApplicationWithTags(appID1, "default", "root.unknown", map[string]string{appTagMaxReservations: "1"})
+	queue, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	app.queue = queue
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5})
+	ask1 := newAllocationAsk("alloc-1", appID1, res)
+	ask2 := newAllocationAsk("alloc-2", appID1, res)
+	err = app.AddAllocationAsk(ask1)
+	assert.NilError(t, err, "ask should have been added to app")
+	err = app.AddAllocationAsk(ask2)
+	assert.NilError(t, err, "ask should have been added to app")
+	node1 := newNode(nodeID1, map[string]resources.Quantity{"first": 10})
+	node2 := newNode("node-2", map[string]resources.Quantity{"first": 10})
+
+	err = app.Reserve(node1, ask1)
+	assert.NilError(t, err, "reservation below the limit should not have failed")
+	err = app.Reserve(node2, ask2)
+	assert.ErrorContains(t, err, "reservation limit 1", "reservation above the app limit should have failed")
+	assert.Equal(t, len(app.GetReservations()), 1, "unexpected reservations on app")
+	assert.Assert(t, app.IsReservedOnNode(nodeID1), "existing reservation removed")
+	assert.Assert(t, !node2.IsReserved(), "node should not be reserved after the app rejected the reservation")
+
+	// no or invalid tag means no limit
+	app = newApplicationWithTags(appID2, "default", "root.unknown", map[string]string{appTagMaxReservations: "-1"})
+	assert.Equal(t, app.maxReservationsPerApp, 0, "invalid limit should have been ignored")
+}
+
+func TestAppReservationConstraints(t *testing.T) {
+	app := newApplication(appID1, "default", "root.unknown")
+	queue, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	app.queue = queue
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5})
+	ask := newAllocationAskRepeat(aKey, appID1, res, 2)
+	err = app.AddAllocationAsk(ask)
+	assert.NilError(t, err, "ask should have been added to app")
+	node := newNode(nodeID1, map[string]resources.Quantity{"first": 20})
+
+	// the node already has the maximum number of allocations of the app
+	app.SetSchedulingConstraints(SchedulingConstraints{MaxAllocationsPerNode: 1})
+	node.AddAllocation(newAllocation(appID1, "uuid-1", nodeID1, "root.unknown", res))
+	err = app.Reserve(node, ask)
+	assert.ErrorContains(t, err, "scheduling constraints", "reservation on a node at the maximum should have failed")
+	assert.Assert(t, !node.IsReserved(), "node should not be reserved after the app rejected the reservation")
+	node.RemoveAllocation("uuid-1")
+	err = app.Reserve(node, ask)
+	assert.NilError(t, err, "reservation below the maximum should not have failed")
+
+	// the reserved node no longer passes the constraints: the reservation is removed not allocated
+	app.SetSchedulingConstraints(SchedulingConstraints{RequiredLabels: map[string]string{"zone": "zone-a"}})
+	noNodes := func(ask *AllocationAsk) interfaces.NodeIterator { return nil }
+	alloc := app.tryReservedAllocate(res, noNodes)
+	if alloc == nil {
+		t.Fatal("reserved allocate should have returned an unreserve")
+	}
+	assert.Equal(t, alloc.Result, Unreserved, "reserved node that fails the constraints should be unreserved")
+	assert.Equal(t, alloc.NodeID, nodeID1, "unexpected node for the unreserve")
+
+	// the reserved node passes the constraints: allocated on the reserved node
+	app.SetSchedulingConstraints(SchedulingConstraints{MaxAllocationsPerNode: 1})
+	alloc = app.tryReservedAllocate(res, noNodes)
+	if alloc == nil {
+		t.Fatal("reserved allocate should have returned an allocation")
+	}
+	assert.Equal(t, alloc.Result, AllocatedReserved, "reserved node that passes the constraints should be allocated")
+}
+
 func TestAppAllocReservation(t *testing.T) {
 	app := newApplication(appID1, "default", "root.unknown")
 	if app == nil || app.ApplicationID != appID1 {
@@ -188,6 +267,139 @@ func TestAppAllocReservation(t *testing.T) {
 	}
 }
 
+func TestRemoveStaleReservations(t *testing.T) {
+	app := newApplication(appID1, "default", "root.unknown")
+	queue, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	app.queue = queue
+	assert.Equal(t, app.RemoveStaleReservations(0, time.Now()), 0, "new app should not have reservations to remove")
+
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5})
+	ask := newAllocationAskRepeat(aKey, appID1, res, 2)
+	err = app.AddAllocationAsk(ask)
+	assert.NilError(t, err, "ask should have been added to app")
+	node1 := newNode(nodeID1, map[string]resources.Quantity{"first": 10})
+	err = app.Reserve(node1, ask)
+	assert.NilError(t, err, "reservation should not have failed")
+	queue.Reserve(appID1)
+	node2 := newNode("node-2", map[string]resources.Quantity{"first": 10})
+	err = app.Reserve(node2, ask)
+	assert.NilError(t, err, "reservation should not have failed")
+	queue.Reserve(appID1)
+
+	// age the reservation on node-1 only
+	now := time.Now()
+	app.reservations[nodeID1+"|"+aKey].reservedAt = now.Add(-time.Hour)
+	app.reservations["node-2|"+aKey].reservedAt = now.Add(-time.Minute)
+	assert.Equal(t, app.RemoveStaleReservations(time.Minute, now), 1, "expected one stale reservation to be removed")
+	assert.Assert(t, !app.IsReservedOnNode(nodeID1), "stale reservation should have been removed from app")
+	assert.Assert(t, !node1.IsReserved(), "stale reservation should have been removed from node")
+	assert.Assert(t, app.IsReservedOnNode("node-2"), "new reservation should not have been removed from app")
+	assert.Assert(t, node2.IsReserved(), "new reservation should not have been removed from node")
+	assert.Equal(t, queue.getReservedApps()[appID1], 1, "queue reservations not updated")
+}
+
+func TestAllocationTime(t *testing.T) {
+	app := newApplication(appID1, "default", "root.unknown")
+	queue, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	app.queue = queue
+	assert.Equal(t, app.GetMaxAllocationTime(), time.Duration(0), "new app should not have a max allocation time")
+	assert.Equal(t, app.GetAvgAllocationTime(), time.Duration(0), "new app should not have an avg allocation time")
+
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	ask := newAllocationAsk(aKey, appID1, res)
+	err = app.AddAllocationAsk(ask)
+	assert.NilError(t, err, "ask should have been added to app")
+	node := newNode(nodeID1, map[string]resources.Quantity{"first": 10})
+
+	// first allocation: submitted an hour ago
+	ask.createTime = time.Now().Add(-time.Hour)
+	alloc := app.tryNode(node, ask)
+	assert.Assert(t, alloc != nil, "allocation should have been made")
+	assert.Equal(t, app.GetMaxAllocationTime(), time.Duration(0), "unconfirmed allocation should not change the max allocation time")
+	app.UpdateAllocationTime(alloc.Ask)
+	maxTime := app.GetMaxAllocationTime()
+	assert.Assert(t, maxTime >= time.Hour && maxTime < time.Hour+time.Minute, "unexpected max allocation time: %v", maxTime)
+	assert.Equal(t, app.GetAvgAllocationTime(), maxTime, "avg should be equal to max after one allocation")
+
+	// second allocation: submitted a minute ago, max stays, avg halves
+	ask2 := newAllocationAsk("alloc-2", appID1, res)
+	err = app.AddAllocationAsk(ask2)
+	assert.NilError(t, err, "ask should have been added to app")
+	ask2.createTime = time.Now().Add(-time.Minute)
+	alloc = app.tryNode(node, ask2)
+	assert.Assert(t, alloc != nil, "allocation should have been made")
+	app.UpdateAllocationTime(alloc.Ask)
+	assert.Equal(t, app.GetMaxAllocationTime(), maxTime, "max allocation time should not have changed")
+	avgTime := app.GetAvgAllocationTime()
+	assert.Assert(t, avgTime >= 30*time.Minute+30*time.Second && avgTime < 31*time.Minute, "unexpected avg allocation time: %v", avgTime)
+}
+
+func TestHasPendingAsksAndAllocations(t *testing.T) {
+	app := newApplication(appID1, "default", "root.unknown")
+	queue, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	app.queue = queue
+	assert.Assert(t, !app.HasPendingAsks(), "new app should not have pending asks")
+	assert.Assert(t, !app.HasActiveAllocations(), "new app should not have allocations")
+
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	ask := newAllocationAskRepeat(aKey, appID1, res, 2)
+	err = app.AddAllocationAsk(ask)
+	assert.NilError(t, err, "ask should have been added to app")
+	assert.Assert(t, app.HasPendingAsks(), "app with ask should have pending asks")
+	assert.Assert(t, !app.HasActiveAllocations(), "app without allocations should not have allocations")
+
+	// mid allocation: one repeat allocated one pending
+	node := newNode(nodeID1, map[string]resources.Quantity{"first": 10})
+	alloc := app.tryNode(node, ask)
+	assert.Assert(t, alloc != nil, "allocation should have been made")
+	assert.Assert(t, app.HasPendingAsks(), "app should still have a pending ask repeat")
+	assert.Assert(t, app.HasActiveAllocations(), "app should have an allocation")
+
+	// all repeats allocated
+	alloc = app.tryNode(node, ask)
+	assert.Assert(t, alloc != nil, "allocation should have been made")
+	assert.Assert(t, !app.HasPendingAsks(), "fully allocated app should not have pending asks")
+	assert.Assert(t, app.HasActiveAllocations(), "fully allocated app should have allocations")
+	assert.Assert(t, app.tryAllocate(resources.Multiply(res, 10), nil) == nil, "app without pending asks should not allocate")
+
+	// completed: all allocations released
+	released := app.RemoveAllAllocations()
+	assert.Equal(t, len(released), 2, "expected two allocations to be released")
+	assert.Assert(t, !app.HasPendingAsks(), "completed app should not have pending asks")
+	assert.Assert(t, !app.HasActiveAllocations(), "completed app should not have allocations")
+	assert.Assert(t, app.IsWaiting(), "app should have moved to waiting: %s", app.CurrentState())
+}
+
+func TestGetReservedNodes(t *testing.T) {
+	app := newApplication(appID1, "default", "root.unknown")
+	queue, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	app.queue = queue
+	assert.Equal(t, len(app.GetReservedNodes()), 0, "new app should not have reserved nodes")
+
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5})
+	ask := newAllocationAskRepeat(aKey, appID1, res, 2)
+	err = app.AddAllocationAsk(ask)
+	assert.NilError(t, err, "ask should have been added to app")
+	node1 := newNode(nodeID1, map[string]resources.Quantity{"first": 10})
+	err = app.Reserve(node1, ask)
+	assert.NilError(t, err, "reservation should not have failed")
+	node2 := newNode("node-2", map[string]resources.Quantity{"first": 10})
+	err = app.Reserve(node2, ask)
+	assert.NilError(t, err, "reservation should not have failed")
+
+	nodes := app.GetReservedNodes()
+	assert.Equal(t, len(nodes), 2, "expected two reserved nodes")
+	assert.Assert(t, resources.Equals(nodes[nodeID1], res), "unexpected reserved resource on node-1")
+	assert.Assert(t, resources.Equals(nodes["node-2"], res), "unexpected reserved resource on node-2")
+	// the returned resources are a copy
+	nodes[nodeID1].AddTo(res)
+	assert.Assert(t, resources.Equals(ask.AllocatedResource, res), "ask resource changed via reserved nodes")
+}
+
 // test update allocation repeat
 func TestUpdateRepeat(t *testing.T) {
 	app := newApplication(appID1, "default", "root.unknown")
@@ -524,6 +736,82 @@ func TestStateChangeOnAskUpdate(t *testing.T) {
 	assert.Assert(t, app.IsWaiting(), "Application did not change as expected: %s", app.CurrentState())
 }
 
+func TestAddAllocationLimit(t *testing.T) {
+	app := newApplicationWithTags(appID1, "default", "root.a", map[string]string{appTagMaxConcurrentAllocations: "2"})
+	assert.Equal(t, app.GetMaxConcurrentAllocations(), 2, "limit not set from tag")
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	app.AddAllocation(newAllocation(appID1, "uuid-1", nodeID1, "root.a", res))
+	assert.Assert(t, !app.IsAllocationLimitReached(), "app should not be at its limit")
+	app.AddAllocation(newAllocation(appID1, "uuid-2", nodeID1, "root.a", res))
+	assert.Assert(t, app.IsAllocationLimitReached(), "app should be at its limit")
+	// the limit only applies to scheduling: an existing allocation is always added
+	app.AddAllocation(newAllocation(appID1, "uuid-3", nodeID1, "root.a", res))
+	assert.Equal(t, len(app.GetAllAllocations()), 3, "allocation over the limit should have been added")
+	assert.Assert(t, app.IsAllocationLimitReached(), "app should be over its limit")
+	app.RemoveAllocation("uuid-1")
+	assert.Assert(t, app.IsAllocationLimitReached(), "app should still be at its limit")
+	app.RemoveAllocation("uuid-2")
+	assert.Assert(t, !app.IsAllocationLimitReached(), "app should be below its limit")
+
+	// invalid or missing tag means no limit
+	app = newApplicationWithTags(appID2, "default", "root.a", map[string]string{appTagMaxConcurrentAllocations: "-1"})
+	assert.Equal(t, app.GetMaxConcurrentAllocations(), 0, "invalid limit should be ignored")
+	app = newApplication(appID2, "default", "root.a")
+	assert.Equal(t, app.GetMaxConcurrentAllocations(), 0, "no tag should not set a limit")
+	assert.Assert(t, !app.IsAllocationLimitReached(), "app without limit should never be at the limit")
+}
+
+func TestApplicationEventLog(t *testing.T) {
+	app := newApplication(appID1, "default", "root.a")
+	assert.Equal(t, len(app.GetEventLog()), 0, "new application should not have events")
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	for i := 0; i < maxApplicationEvents+10; i++ {
+		app.RecordEvent(AppAskAdded, "event-"+strconv.Itoa(i), res)
+	}
+	events := app.GetEventLog()
+	assert.Equal(t, len(events), maxApplicationEvents, "event log should be capped")
+	assert.Equal(t, events[0].Description, "event-10", "oldest events should have been evicted")
+	assert.Equal(t, events[maxApplicationEvents-1].Description, "event-"+strconv.Itoa(maxApplicationEvents+9), "newest event should be last")
+	assert.Assert(t, resources.Equals(events[0].Resource, res), "event resource not set")
+
+	// repeated denials are only recorded once
+	app = newApplication(appID2, "default", "root.a")
+	app.RecordEvent(AppQuotaDenied, "denied", nil)
+	app.RecordEvent(AppQuotaDenied, "denied", nil)
+	assert.Equal(t, len(app.GetEventLog()), 1, "repeated denial should not be recorded")
+	app.RecordEvent(AppQuotaDenied, "other", nil)
+	app.RecordEvent(AppRateLimitDenied, "other", nil)
+	app.RecordEvent(AppQuotaDenied, "other", nil)
+	assert.Equal(t, len(app.GetEventLog()), 3, "changed denials should be recorded once")
+}
+
+func TestApplicationEventLogDenials(t *testing.T) {
+	eventLog := newApplicationEventLog()
+	start := time.Now()
+	denial := func(ask string, offset time.Duration) ApplicationEvent {
+		return ApplicationEvent{Type: AppQuotaDenied, Time: start.Add(offset), Description: "ask " + ask + " denied"}
+	}
+	// two asks alternating denials every cycle are recorded once each
+	for i := 0; i < 2*maxApplicationEvents; i++ {
+		ask := "ask-1"
+		if i%2 == 1 {
+			ask = "ask-2"
+		}
+		eventLog.add(denial(ask, time.Duration(i)*time.Millisecond))
+	}
+	assert.Equal(t, len(eventLog.getEvents()), 2, "alternating denials should only be recorded once per ask")
+	// other events are not deduplicated
+	eventLog.add(ApplicationEvent{Type: AppAskAdded, Time: start, Description: "ask ask-3 added"})
+	eventLog.add(ApplicationEvent{Type: AppAskAdded, Time: start, Description: "ask ask-3 added"})
+	assert.Equal(t, len(eventLog.getEvents()), 4, "other events should always be recorded")
+	// after the window the denial is recorded again
+	eventLog.add(denial("ask-1", denialEventWindow+time.Millisecond))
+	assert.Equal(t, len(eventLog.getEvents()), 5, "denial after the window should be recorded")
+	assert.Equal(t, len(eventLog.denials), 1, "expired denials should have been removed")
+	eventLog.add(denial("ask-1", denialEventWindow+time.Second))
+	assert.Equal(t, len(eventLog.getEvents()), 5, "repeated denial within the new window should not be recorded")
+}
+
 func TestAllocations(t *testing.T) {
 	app := newApplication(appID1, "default", "root.a")
 
@@ -564,6 +852,9 @@ func TestAllocations(t *testing.T) {
 	}
 	allocs = app.GetAllAllocations()
 	assert.Equal(t, len(allocs), 0)
+	// the lifetime total includes the removed allocations
+	total := resources.Multiply(res, 3)
+	assert.Assert(t, resources.Equals(app.GetTotalAllocatedResource(), total), "unexpected total allocated: %v", app.GetTotalAllocatedResource())
 }
 
 func TestQueueUpdate(t *testing.T) {
@@ -646,3 +937,97 @@ func TestOnStatusChangeCalled(t *testing.T) {
 	assert.Equal(t, app.CurrentState(), Accepted.String(), "application state has been changed unexpectedly")
 	assert.Assert(t, !testHandler.isHandled(), "unexpected event send to the RM")
 }
+
+func TestSetPriorityClass(t *testing.T) {
+	app := newApplication(appID1, "default", "root.unknown")
+	queue, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	app.queue = queue
+	assert.Equal(t, app.GetPriorityClassName(), "", "new app should not have a priority class")
+	assert.Equal(t, app.GetPriorityOffset(), int32(0), "new app should not have a priority offset")
+
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5})
+	ask := newAllocationAsk(aKey, appID1, res)
+	ask.setPriority(10)
+	err = app.AddAllocationAsk(ask)
+	assert.NilError(t, err, "ask should have been added to app")
+	assert.Equal(t, ask.GetEffectivePriority(), int32(10), "effective priority without class should be the ask priority")
+
+	// existing asks are updated
+	app.SetPriorityClass("high", 100)
+	assert.Equal(t, app.GetPriorityClassName(), "high", "priority class not set")
+	assert.Equal(t, app.GetPriorityOffset(), int32(100), "priority offset not set")
+	assert.Equal(t, ask.GetPriority(), int32(10), "raw ask priority should not change")
+	assert.Equal(t, ask.GetEffectivePriority(), int32(110), "class offset not added to existing ask")
+
+	// new asks get the offset on add
+	ask2 := newAllocationAsk("alloc-2", appID1, res)
+	ask2.setPriority(-5)
+	err = app.AddAllocationAsk(ask2)
+	assert.NilError(t, err, "ask should have been added to app")
+	assert.Equal(t, ask2.GetEffectivePriority(), int32(95), "class offset not added to new ask")
+
+	// negative offsets lower the priority
+	app.SetPriorityClass("low", -20)
+	assert.Equal(t, ask.GetEffectivePriority(), int32(-10), "negative offset not applied")
+	assert.Equal(t, ask2.GetEffectivePriority(), int32(-25), "negative offset not applied")
+	assert.Equal(t, ask.Clone().GetEffectivePriority(), int32(-10), "offset not copied on clone")
+}
+
+func TestSetSchedulingConstraints(t *testing.T) {
+	app := newApplication(appID1, "default", "root.unknown")
+	queue, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	app.queue = queue
+	assert.Assert(t, app.GetSchedulingConstraints().IsEmpty(), "new app should not have constraints")
+
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5})
+	ask := newAllocationAsk(aKey, appID1, res)
+	err = app.AddAllocationAsk(ask)
+	assert.NilError(t, err, "ask should have been added to app")
+	assert.Assert(t, ask.GetSchedulingConstraints() == nil, "ask should not have constraints")
+
+	// existing asks are updated and the constraints are copied
+	labels := map[string]string{"zone": "zone-a"}
+	app.SetSchedulingConstraints(SchedulingConstraints{RequiredLabels: labels, MaxAllocationsPerNode: 2})
+	labels["zone"] = "zone-b"
+	constraints := app.GetSchedulingConstraints()
+	assert.DeepEqual(t, constraints.RequiredLabels, map[string]string{"zone": "zone-a"})
+	assert.Equal(t, constraints.MaxAllocationsPerNode, 2, "max allocations per node not set")
+	assert.Assert(t, ask.GetSchedulingConstraints() != nil, "constraints not set on existing ask")
+	assert.Equal(t, ask.GetSchedulingConstraints().RequiredLabels["zone"], "zone-a", "unexpected constraints on existing ask")
+
+	// new asks get the constraints on add
+	ask2 := newAllocationAsk("alloc-2", appID1, res)
+	err = app.AddAllocationAsk(ask2)
+	assert.NilError(t, err, "ask should have been added to app")
+	assert.Assert(t, ask2.GetSchedulingConstraints() != nil, "constraints not set on new ask")
+	assert.Assert(t, ask2.Clone().GetSchedulingConstraints() != nil, "constraints not copied on clone")
+
+	// empty constraints remove the constraints
+	app.SetSchedulingConstraints(SchedulingConstraints{})
+	assert.Assert(t, app.GetSchedulingConstraints().IsEmpty(), "constraints not removed from app")
+	assert.Assert(t, ask.GetSchedulingConstraints() == nil, "constraints not removed from ask")
+	assert.Assert(t, ask2.GetSchedulingConstraints() == nil, "constraints not removed from ask")
+}
+
+func TestSchedulingConstraintsFromTags(t *testing.T) {
+	app := newApplication(appID1, "default", "root.unknown")
+	assert.Assert(t, app.GetSchedulingConstraints().IsEmpty(), "app without tags should not have constraints")
+
+	tags := map[string]string{
+		appTagRequiredLabels:        "zone=zone-a, disk=ssd",
+		appTagForbiddenLabels:       "gpu=true,invalid",
+		appTagRequiredNodeCount:     "2",
+		appTagMaxAllocationsPerNode: "-1",
+	}
+	app = newApplicationWithTags(appID2, "default", "root.unknown", tags)
+	constraints := app.GetSchedulingConstraints()
+	assert.DeepEqual(t, constraints.RequiredLabels, map[string]string{"zone": "zone-a", "disk": "ssd"})
+	assert.DeepEqual(t, constraints.ForbiddenLabels, map[string]string{"gpu": "true"})
+	assert.Equal(t, constraints.RequiredNodeCount, 2, "required node count not set from tag")
+	assert.Equal(t, constraints.MaxAllocationsPerNode, 0, "invalid max allocations per node should be ignored")
+
+	app = newApplicationWithTags("app-3", "default", "root.unknown", map[string]string{appTagRequiredLabels: "=zone-a"})
+	assert.Assert(t, app.GetSchedulingConstraints().IsEmpty(), "invalid labels should not set constraints")
+}
@@ -20,6 +20,9 @@ package objects
 
 import (
 	"fmt"
+	"math"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -28,10 +31,36 @@ import (
 	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
 	"github.com/apache/incubator-yunikorn-core/pkg/log"
 	"github.com/apache/incubator-yunikorn-core/pkg/plugins"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/policies"
 	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/common"
 	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
 )
 
+const (
+	// node attribute that sets the maximum number of reservations allowed on the node
+	nodeAttrMaxReservations = "node.maxreservations"
+	// number of reservations allowed on a node if not set in the node attributes
+	defaultMaxReservationsPerNode = 1
+)
+
+// Well-known node attributes that describe the topology of the node.
+const (
+	TopologyZoneLabel     = "topology.kubernetes.io/zone"
+	TopologyRegionLabel   = "topology.kubernetes.io/region"
+	TopologyHostnameLabel = "kubernetes.io/hostname"
+	NodeRoleWorkerLabel   = "node-role.kubernetes.io/worker"
+)
+
+// Node attributes that are copied into the topology labels of the node.
+var topologyLabelKeys = []string{
+	TopologyZoneLabel,
+	TopologyRegionLabel,
+	TopologyHostnameLabel,
+	NodeRoleWorkerLabel,
+	common.HostName,
+	common.RackName,
+}
+
 type Node struct {
 	// Fields for fast access These fields are considered read only.
 	// Values should only be set when creating a new node and never changed.
@@ -42,15 +71,18 @@ type Node struct {
 
 	// Private fields need protection
 	attributes        map[string]string
+	topologyLabels    map[string]string // topology attributes of the node, rebuilt when the attributes change
 	totalResource     *resources.Resource
 	occupiedResource  *resources.Resource
 	allocatedResource *resources.Resource
 	availableResource *resources.Resource
 	allocations       map[string]*Allocation
+	appAllocations    map[string]int // number of allocations on the node per application
 	schedulable       bool
 
-	preempting   *resources.Resource     // resources considered for preemption
-	reservations map[string]*reservation // a map of reservations
+	preempting             *resources.Resource     // resources considered for preemption
+	reservations           map[string]*reservation // a map of reservations
+	maxReservationsPerNode int                     // maximum number of reservations allowed on the node, 0 means the default
 
 	sync.RWMutex
 }
@@ -68,6 +100,7 @@ func NewNode(proto *si.NewNodeInfo) *Node {
 		allocatedResource: resources.NewResource(),
 		occupiedResource:  resources.NewResourceFromProto(proto.OccupiedResource),
 		allocations:       make(map[string]*Allocation),
+		appAllocations:    make(map[string]int),
 		schedulable:       true,
 	}
 	// initialise available resources
@@ -83,6 +116,46 @@ func NewNode(proto *si.NewNodeInfo) *Node {
 	return sn
 }
 
+// Create a copy of the node that can be used without changing this node.
+// The allocations, including their asks, are copied and not shared with this node.
+// Reservations are not copied: the copy of the node is never reserved.
+func (sn *Node) Clone() *Node {
+	sn.RLock()
+	defer sn.RUnlock()
+	clone := &Node{
+		NodeID:                 sn.NodeID,
+		Hostname:               sn.Hostname,
+		Rackname:               sn.Rackname,
+		Partition:              sn.Partition,
+		attributes:             make(map[string]string, len(sn.attributes)),
+		topologyLabels:         sn.topologyLabels,
+		totalResource:          sn.totalResource.Clone(),
+		occupiedResource:       sn.occupiedResource.Clone(),
+		allocatedResource:      sn.allocatedResource.Clone(),
+		availableResource:      sn.availableResource.Clone(),
+		allocations:            make(map[string]*Allocation, len(sn.allocations)),
+		appAllocations:         make(map[string]int, len(sn.appAllocations)),
+		schedulable:            sn.schedulable,
+		preempting:             sn.preempting.Clone(),
+		reservations:           make(map[string]*reservation),
+		maxReservationsPerNode: sn.maxReservationsPerNode,
+	}
+	for key, value := range sn.attributes {
+		clone.attributes[key] = value
+	}
+	for uuid, alloc := range sn.allocations {
+		allocClone := alloc.Clone()
+		if alloc.Ask != nil {
+			allocClone.Ask = alloc.Ask.Clone()
+		}
+		clone.allocations[uuid] = allocClone
+	}
+	for appID, count := range sn.appAllocations {
+		clone.appAllocations[appID] = count
+	}
+	return clone
+}
+
 func (sn *Node) String() string {
 	if sn == nil {
 		return "node is nil"
@@ -92,22 +165,91 @@ func (sn *Node) String() string {
 }
 
 // Set the attributes and fast access fields.
-// Unlocked call: should only be called on create or from test code
+// Unlocked call: should only be called on create, from test code or holding the node lock
 func (sn *Node) initializeAttribute(newAttributes map[string]string) {
 	sn.attributes = newAttributes
 
 	sn.Hostname = sn.attributes[common.HostName]
 	sn.Rackname = sn.attributes[common.RackName]
 	sn.Partition = sn.attributes[common.NodePartition]
+	sn.topologyLabels = make(map[string]string)
+	for _, key := range topologyLabelKeys {
+		if value, ok := sn.attributes[key]; ok {
+			sn.topologyLabels[key] = value
+		}
+	}
+	sn.maxReservationsPerNode = 0
+	if value, ok := sn.attributes[nodeAttrMaxReservations]; ok {
+		if limit, err := strconv.Atoi(value); err == nil && limit > 0 {
+			sn.maxReservationsPerNode = limit
+		} else {
+			log.Logger().Warn("ignoring invalid node reservation limit",
+				zap.String("nodeID", sn.NodeID),
+				zap.String("limit", value))
+		}
+	}
 }
 
 // Get an attribute by name. The most used attributes can be directly accessed via the
 // fields: HostName, RackName and Partition.
-// This is a lock free call. All attributes are considered read only
 func (sn *Node) GetAttribute(key string) string {
+	sn.RLock()
+	defer sn.RUnlock()
 	return sn.attributes[key]
 }
 
+// Return the host name of the node, the field is updated when the attributes change.
+func (sn *Node) GetHostname() string {
+	sn.RLock()
+	defer sn.RUnlock()
+	return sn.Hostname
+}
+
+// Return the rack name of the node, the field is updated when the attributes change.
+func (sn *Node) GetRackname() string {
+	sn.RLock()
+	defer sn.RUnlock()
+	return sn.Rackname
+}
+
+// Return a copy of the well-known topology attributes set on the node.
+// The labels are extracted when the attributes are set, attributes that are not set are not returned.
+func (sn *Node) GetTopologyLabels() map[string]string {
+	sn.RLock()
+	defer sn.RUnlock()
+	labels := make(map[string]string, len(sn.topologyLabels))
+	for key, value := range sn.topologyLabels {
+		labels[key] = value
+	}
+	return labels
+}
+
+// Return a copy of all attributes of the node.
+func (sn *Node) GetAttributes() map[string]string {
+	sn.RLock()
+	defer sn.RUnlock()
+	attributes := make(map[string]string, len(sn.attributes))
+	for key, value := range sn.attributes {
+		attributes[key] = value
+	}
+	return attributes
+}
+
+// Add the attributes to the node, existing attributes with the same key are replaced.
+// The attribute map is replaced not changed as it is shared with clones of the node.
+func (sn *Node) UpdateAttributes(attributes map[string]string) {
+	sn.Lock()
+	defer sn.Unlock()
+	newAttributes := make(map[string]string, len(sn.attributes)+len(attributes))
+	for key, value := range sn.attributes {
+		newAttributes[key] = value
+	}
+	for key, value := range attributes {
+		newAttributes[key] = value
+	}
+	sn.initializeAttribute(newAttributes)
+}
+
 // Return an array of all reservation keys for the node.
 // This will return an empty array if there are no reservations.
 // Visible for tests
@@ -127,7 +269,28 @@ func (sn *Node) GetCapacity() *resources.Resource {
 	return sn.totalResource.Clone()
 }
 
-func (sn *Node) SetCapacity(newCapacity *resources.Resource) {
+// Update the capacity of the node and the available resource based on the new capacity.
+// The capacity cannot be set to less than the resources allocated on the node, in that case the
+// capacity is not changed and an error is returned.
+func (sn *Node) SetCapacity(newCapacity *resources.Resource) error {
+	sn.Lock()
+	defer sn.Unlock()
+	if resources.Equals(sn.totalResource, newCapacity) {
+		log.Logger().Debug("skip updating capacity, not changed")
+		return nil
+	}
+	if !resources.FitIn(newCapacity, sn.allocatedResource) {
+		return fmt.Errorf("node %s capacity %s is less than the allocated resources %s", sn.NodeID, newCapacity, sn.allocatedResource)
+	}
+	sn.totalResource = newCapacity
+	sn.refreshAvailableResource()
+	return nil
+}
+
+// Update the capacity of the node to the capacity reported by the RM, see SetCapacity.
+// The capacity reported by the RM is always applied: the node is over allocated if the new capacity is less than
+// the resources allocated on the node.
+func (sn *Node) SetReportedCapacity(newCapacity *resources.Resource) {
 	sn.Lock()
 	defer sn.Unlock()
 	if resources.Equals(sn.totalResource, newCapacity) {
@@ -138,6 +301,14 @@ func (sn *Node) SetCapacity(newCapacity *resources.Resource) {
 	sn.refreshAvailableResource()
 }
 
+// Return true if the resources allocated on the node do not fit in the capacity of the node.
+// This happens when the RM reports a capacity less than the allocated resources.
+func (sn *Node) IsOverAllocated() bool {
+	sn.RLock()
+	defer sn.RUnlock()
+	return !resources.FitIn(sn.totalResource, sn.allocatedResource)
+}
+
 func (sn *Node) GetOccupiedResource() *resources.Resource {
 	sn.RLock()
 	defer sn.RUnlock()
@@ -193,6 +364,14 @@ func (sn *Node) GetAllAllocations() []*Allocation {
 	return arr
 }
 
+// Return the number of allocations of the application on this node.
+func (sn *Node) GetApplicationAllocationCount(appID string) int {
+	sn.RLock()
+	defer sn.RUnlock()
+
+	return sn.appAllocations[appID]
+}
+
 // Set the node to unschedulable.
 // This will cause the node to be skipped during the scheduling cycle.
 // Visible for testing only
@@ -216,6 +395,181 @@ func (sn *Node) GetAllocatedResource() *resources.Resource {
 	return sn.allocatedResource.Clone()
 }
 
+// Return the utilisation of the resource type as the ratio of allocated over capacity.
+// Returns 0 if the node has no capacity for the resource type.
+func (sn *Node) GetResourceUtilization(name string) float64 {
+	sn.RLock()
+	defer sn.RUnlock()
+	return sn.getResourceUtilization(name)
+}
+
+// Return the utilisation of the vcore resource, 0 if the node has no vcore capacity.
+func (sn *Node) GetCPUUtilization() float64 {
+	return sn.GetResourceUtilization(resources.VCORE)
+}
+
+// Return the utilisation of the memory resource, 0 if the node has no memory capacity.
+func (sn *Node) GetMemoryUtilization() float64 {
+	return sn.GetResourceUtilization(resources.MEMORY)
+}
+
+// Return the utilisation of the ephemeral storage resource, 0 if the node has no storage capacity.
+func (sn *Node) GetStorageUtilization() float64 {
+	return sn.GetResourceUtilization(resources.STORAGE)
+}
+
+// Return the highest utilisation over all resource types of the node.
+func (sn *Node) GetDominantUtilization() float64 {
+	sn.RLock()
+	defer sn.RUnlock()
+	return sn.getDominantUtilization()
+}
+
+// Unlocked version must be called holding the node lock.
+func (sn *Node) getDominantUtilization() float64 {
+	var dominant float64
+	if sn.totalResource == nil {
+		return dominant
+	}
+	for name := range sn.totalResource.Resources {
+		if util := sn.getResourceUtilization(name); util > dominant {
+			dominant = util
+		}
+	}
+	return dominant
+}
+
+// Return the number of allocations on the node per dominant share of the node capacity: the number of allocations
+// of the average size on the node that would fill the whole node. The dominant share is the highest utilisation
+// over all resource types, which makes the density independent of the units of the resource types.
+// Returns 0 if the node has no allocations or the allocations do not use any of the node capacity.
+func (sn *Node) GetAllocationDensity() float64 {
+	sn.RLock()
+	defer sn.RUnlock()
+	dominant := sn.getDominantUtilization()
+	if dominant <= 0 {
+		return 0
+	}
+	return float64(len(sn.allocations)) / dominant
+}
+
+// Unlocked version must be called holding the node lock.
+func (sn *Node) getResourceUtilization(name string) float64 {
+	if sn.totalResource == nil {
+		return 0
+	}
+	total := sn.totalResource.Resources[name]
+	if total <= 0 {
+		return 0
+	}
+	var allocated resources.Quantity
+	if sn.allocatedResource != nil {
+		allocated = sn.allocatedResource.Resources[name]
+	}
+	return float64(allocated) / float64(total)
+}
+
+// Return the score of the node for placing the ask according to the policy, a higher score is preferred.
+// The score is based on the utilisation of the node after the ask is placed:
+// - binpacking: the utilisation
+// - fair: the negative utilisation
+// - bestfit: the negative norm of the share of the resources left unused
+// - composite: not scored, nodes are only ordered when sorted with all chained policies
+// If resource weights are set in the policy the weighted share is used, otherwise the dominant share.
+// A node the ask does not fit on has the lowest possible score. A nil ask scores the node as it is.
+func (sn *Node) Score(ask *AllocationAsk, policy *policies.NodeSortingPolicy) float64 {
+	var askRes *resources.Resource
+	if ask != nil {
+		askRes = ask.AllocatedResource
+	}
+	return sn.score(askRes, policy.PolicyType, policy.GetResourceWeights())
+}
+
+// Calculate the node score for the resource, weights are passed in to allow reuse while sorting.
+func (sn *Node) score(askRes *resources.Resource, policyType policies.SortingPolicy, weights map[string]float64) float64 {
+	sn.RLock()
+	defer sn.RUnlock()
+	return scoreResources(sn.totalResource, sn.availableResource, askRes, policyType, weights)
+}
+
+// Calculate the score for placing the ask on the total and available resources, which are not modified.
+func scoreResources(total, available, askRes *resources.Resource, policyType policies.SortingPolicy, weights map[string]float64) float64 {
+	if askRes != nil {
+		if !resources.FitIn(available, askRes) {
+			return math.Inf(-1)
+		}
+		available = resources.Sub(available, askRes)
+	}
+	// share of each resource that is left unused after placement
+	unused := make(map[string]float64)
+	if total != nil {
+		for name, quantity := range total.Resources {
+			if quantity > 0 {
+				unused[name] = float64(available.Resources[name]) / float64(quantity)
+			}
+		}
+	}
+	switch policyType {
+	case policies.BinPackingPolicy:
+		return utilisation(unused, weights)
+	case policies.FairnessPolicy:
+		return -utilisation(unused, weights)
+	case policies.BestFitPolicy:
+		return -unusedNorm(unused, weights)
+	default:
+		return 0
+	}
+}
+
+// Utilisation based on the unused shares: the weighted average if weights are set, otherwise the dominant share.
+// Without any resources the node is considered fully utilised.
+func utilisation(unused map[string]float64, weights map[string]float64) float64 {
+	if len(unused) == 0 {
+		return 1
+	}
+	if len(weights) == 0 {
+		var dominant float64
+		for _, share := range unused {
+			dominant = math.Max(dominant, 1-share)
+		}
+		return dominant
+	}
+	var used, totalWeight float64
+	for name, share := range unused {
+		weight := resourceWeight(weights, name)
+		used += weight * (1 - share)
+		totalWeight += weight
+	}
+	if totalWeight == 0 {
+		return 1
+	}
+	return used / totalWeight
+}
+
+// Euclidean norm of the (weighted) unused shares. Without weights all resources count the same.
+func unusedNorm(unused map[string]float64, weights map[string]float64) float64 {
+	var sum float64
+	for name, share := range unused {
+		sum += resourceWeight(weights, name) * share * share
+	}
+	return math.Sqrt(sum)
+}
+
+// Return the weight for the resource, the default weight if not set.
+func resourceWeight(weights map[string]float64, name string) float64 {
+	if weight, ok := weights[name]; ok {
+		return weight
+	}
+	return policies.DefaultResourceWeight
+}
+
+// Get the total and available resource on this node, taken under one lock.
+func (sn *Node) getScoreResources() (*resources.Resource, *resources.Resource) {
+	sn.RLock()
+	defer sn.RUnlock()
+	return sn.totalResource.Clone(), sn.availableResource.Clone()
+}
+
 // Get the available resource on this node.
 func (sn *Node) GetAvailableResource() *resources.Resource {
 	sn.Lock()
@@ -223,6 +577,48 @@ func (sn *Node) GetAvailableResource() *resources.Resource {
 	return sn.availableResource.Clone()
 }
 
+// Get the ephemeral storage available on this node, 0 if the node has no storage capacity.
+func (sn *Node) GetAvailableEphemeralStorage() int64 {
+	sn.RLock()
+	defer sn.RUnlock()
+	if sn.availableResource == nil {
+		return 0
+	}
+	return int64(sn.availableResource.Resources[resources.STORAGE])
+}
+
+// The result of checking an ask against the available resources of a node.
+// The resource types are sorted by name. The headroom is the available resource minus the ask, it is negative for
+// the unsatisfied resource types.
+type NodeFitReport struct {
+	CanFit               bool
+	SatisfiedResources   []string
+	UnsatisfiedResources []string
+	Headroom             *resources.Resource
+}
+
+// Return a report on the resource types of the ask that fit in the available resources of the node.
+// Only resources are checked, the node state, reservations and predicates are not.
+func (sn *Node) GetAllocationFitReport(ask *AllocationAsk) NodeFitReport {
+	available := sn.GetAvailableResource()
+	report := NodeFitReport{
+		SatisfiedResources:   make([]string, 0),
+		UnsatisfiedResources: make([]string, 0),
+		Headroom:             resources.Sub(available, ask.AllocatedResource),
+	}
+	for name, value := range ask.AllocatedResource.Resources {
+		if value > available.Resources[name] {
+			report.UnsatisfiedResources = append(report.UnsatisfiedResources, name)
+		} else {
+			report.SatisfiedResources = append(report.SatisfiedResources, name)
+		}
+	}
+	sort.Strings(report.SatisfiedResources)
+	sort.Strings(report.UnsatisfiedResources)
+	report.CanFit = len(report.UnsatisfiedResources) == 0
+	return report
+}
+
 func (sn *Node) FitInNode(resRequest *resources.Resource) bool {
 	sn.RLock()
 	defer sn.RUnlock()
@@ -268,6 +664,11 @@ func (sn *Node) RemoveAllocation(uuid string) *Allocation {
 	alloc := sn.allocations[uuid]
 	if alloc != nil {
 		delete(sn.allocations, uuid)
+		if sn.appAllocations[alloc.ApplicationID] <= 1 {
+			delete(sn.appAllocations, alloc.ApplicationID)
+		} else {
+			sn.appAllocations[alloc.ApplicationID]--
+		}
 		sn.allocatedResource.SubFrom(alloc.AllocatedResource)
 		sn.availableResource.AddTo(alloc.AllocatedResource)
 		return alloc
@@ -289,6 +690,7 @@ func (sn *Node) AddAllocation(alloc *Allocation) bool {
 	res := alloc.AllocatedResource
 	if resources.FitIn(sn.availableResource, res) {
 		sn.allocations[alloc.UUID] = alloc
+		sn.appAllocations[alloc.ApplicationID]++
 		sn.allocatedResource.AddTo(res)
 		sn.availableResource.SubFrom(res)
 		return true
@@ -378,6 +780,22 @@ func (sn *Node) preAllocateCheck(res *resources.Resource, resKey string, preempt
 	return nil
 }
 
+// Return the number of reservations on the node.
+func (sn *Node) GetReservationCount() int {
+	sn.RLock()
+	defer sn.RUnlock()
+	return len(sn.reservations)
+}
+
+// Return the maximum number of reservations allowed on the node, the default if not set.
+// Unlocked version must be called holding the node lock.
+func (sn *Node) getMaxReservations() int {
+	if sn.maxReservationsPerNode <= 0 {
+		return defaultMaxReservationsPerNode
+	}
+	return sn.maxReservationsPerNode
+}
+
 // Return if the node has been reserved by any application
 func (sn *Node) IsReserved() bool {
 	sn.RLock()
@@ -410,8 +828,8 @@ func (sn *Node) isReservedForApp(key string) bool {
 func (sn *Node) Reserve(app *Application, ask *AllocationAsk) error {
 	sn.Lock()
 	defer sn.Unlock()
-	if len(sn.reservations) > 0 {
-		return fmt.Errorf("node is already reserved, nodeID %s", sn.NodeID)
+	if limit := sn.getMaxReservations(); len(sn.reservations) >= limit {
+		return fmt.Errorf("node is already reserved, nodeID %s, reservation limit %d", sn.NodeID, limit)
 	}
 	appReservation := newReservation(sn, app, ask, false)
 	// this should really not happen just guard against panic
@@ -19,11 +19,13 @@
 package objects
 
 import (
+	"math"
 	"testing"
 
 	"gotest.tools/assert"
 
 	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/policies"
 	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/common"
 )
 
@@ -200,6 +202,44 @@ func TestCanAllocate(t *testing.T) {
 	}
 }
 
+func TestGetAllocationFitReport(t *testing.T) {
+	node := newNode(nodeID1, map[string]resources.Quantity{"first": 10, "second": 5, "third": 0})
+	if node == nil || node.NodeID != nodeID1 {
+		t.Fatalf("node create failed which should not have %v", node)
+	}
+	tests := []struct {
+		name        string
+		ask         map[string]resources.Quantity
+		canFit      bool
+		satisfied   []string
+		unsatisfied []string
+		headroom    map[string]resources.Quantity
+	}{
+		{"empty ask", map[string]resources.Quantity{}, true, []string{}, []string{}, map[string]resources.Quantity{"first": 10, "second": 5, "third": 0}},
+		{"all fit", map[string]resources.Quantity{"first": 5, "second": 5}, true, []string{"first", "second"}, []string{}, map[string]resources.Quantity{"first": 5, "second": 0, "third": 0}},
+		{"one too large", map[string]resources.Quantity{"first": 5, "second": 6}, false, []string{"first"}, []string{"second"}, map[string]resources.Quantity{"first": 5, "second": -1, "third": 0}},
+		{"all too large", map[string]resources.Quantity{"first": 11, "second": 6}, false, []string{}, []string{"first", "second"}, map[string]resources.Quantity{"first": -1, "second": -1, "third": 0}},
+		{"unknown type", map[string]resources.Quantity{"first": 1, "fourth": 1}, false, []string{"first"}, []string{"fourth"}, map[string]resources.Quantity{"first": 9, "second": 5, "third": 0, "fourth": -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ask := newAllocationAsk("alloc-1", appID1, resources.NewResourceFromMap(tt.ask))
+			report := node.GetAllocationFitReport(ask)
+			assert.Equal(t, report.CanFit, tt.canFit, "unexpected fit result")
+			assert.DeepEqual(t, report.SatisfiedResources, tt.satisfied)
+			assert.DeepEqual(t, report.UnsatisfiedResources, tt.unsatisfied)
+			assert.Assert(t, resources.Equals(report.Headroom, resources.NewResourceFromMap(tt.headroom)), "unexpected headroom: %v", report.Headroom)
+		})
+	}
+	// the report is based on the available resources
+	added := node.AddAllocation(newAllocation(appID1, "uuid-1", nodeID1, "root.default", resources.NewResourceFromMap(map[string]resources.Quantity{"first": 8})))
+	assert.Assert(t, added, "failed to add allocation to node")
+	report := node.GetAllocationFitReport(newAllocationAsk("alloc-2", appID1, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5})))
+	assert.Assert(t, !report.CanFit, "ask should not fit after the allocation")
+	assert.DeepEqual(t, report.UnsatisfiedResources, []string{"first"})
+	assert.Assert(t, resources.Equals(report.Headroom, resources.NewResourceFromMap(map[string]resources.Quantity{"first": -3, "second": 5})), "unexpected headroom: %v", report.Headroom)
+}
+
 func TestPreemptingResources(t *testing.T) {
 	node := newNode(nodeID1, map[string]resources.Quantity{"first": 10})
 	if node == nil || node.NodeID != nodeID1 {
@@ -303,6 +343,42 @@ func TestNodeReservation(t *testing.T) {
 	assert.Equal(t, num, 1, "un-reserve app should have released ")
 }
 
+func TestNodeReservationLimit(t *testing.T) {
+	node := newNode(nodeID1, map[string]resources.Quantity{"first": 10})
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5})
+	assert.Equal(t, node.GetReservationCount(), 0, "new node should not have reservations")
+
+	// default limit: one reservation
+	app1 := newApplication(appID1, "default", "root.unknown")
+	ask1 := newAllocationAsk("alloc-1", appID1, res)
+	err := node.Reserve(app1, ask1)
+	assert.NilError(t, err, "first reservation should not have failed")
+	app2 := newApplication(appID2, "default", "root.unknown")
+	ask2 := newAllocationAsk("alloc-2", appID2, res)
+	err = node.Reserve(app2, ask2)
+	assert.ErrorContains(t, err, "reservation limit 1", "reservation above the default limit should have failed")
+	assert.Equal(t, node.GetReservationCount(), 1, "unexpected reservation count")
+
+	// invalid limit is ignored
+	node.UpdateAttributes(map[string]string{nodeAttrMaxReservations: "none"})
+	err = node.Reserve(app2, ask2)
+	assert.ErrorContains(t, err, "reservation limit 1", "invalid limit should not have been used")
+
+	// raise the limit: node at its limit rejects the next reservation
+	node.UpdateAttributes(map[string]string{nodeAttrMaxReservations: "2"})
+	err = node.Reserve(app2, ask2)
+	assert.NilError(t, err, "reservation below the limit should not have failed")
+	assert.Equal(t, node.GetReservationCount(), 2, "unexpected reservation count")
+	app3 := newApplication("app-3", "default", "root.unknown")
+	err = node.Reserve(app3, newAllocationAsk("alloc-3", "app-3", res))
+	assert.ErrorContains(t, err, "reservation limit 2", "reservation above the limit should have failed")
+	// existing reservations are not affected
+	assert.Equal(t, node.GetReservationCount(), 2, "reservation count changed by rejected reservation")
+	assert.Assert(t, node.isReservedForApp(reservationKey(nil, app1, ask1)), "reservation for app-1 removed")
+	assert.Assert(t, node.isReservedForApp(reservationKey(nil, app2, ask2)), "reservation for app-2 removed")
+	assert.Assert(t, !node.isReservedForApp("app-3"), "reservation for app-3 should not exist")
+}
+
 func TestUnReserveApps(t *testing.T) {
 	node := newNode(nodeID1, map[string]resources.Quantity{"first": 10})
 	if node == nil || node.NodeID != nodeID1 {
@@ -402,6 +478,63 @@ func TestAttributes(t *testing.T) {
 	assert.Equal(t, "partition1", value, "node attributes not set, expected 'partition1' got '%v'", value)
 	value = node.GetAttribute("something")
 	assert.Equal(t, "just a text", value, "node attributes not set, expected 'just a text' got '%v'", value)
+
+	node.UpdateAttributes(map[string]string{common.HostName: "host1", common.RackName: "rack1"})
+	assert.Equal(t, "host1", node.GetHostname(), "host name not updated with the attributes")
+	assert.Equal(t, "rack1", node.GetRackname(), "rack name not updated with the attributes")
+}
+
+func TestGetTopologyLabels(t *testing.T) {
+	all := map[string]string{
+		TopologyZoneLabel:     "zone-1",
+		TopologyRegionLabel:   "region-1",
+		TopologyHostnameLabel: "host-1",
+		NodeRoleWorkerLabel:   "",
+		common.HostName:       "host-1",
+		common.RackName:       "rack-1",
+	}
+	attributes := map[string]string{"something": "just a text"}
+	for key, value := range all {
+		attributes[key] = value
+	}
+	node := NewNode(newProto(testNode, nil, nil, attributes))
+	assert.DeepEqual(t, node.GetTopologyLabels(), all)
+
+	// some labels set, the returned map is a copy
+	node = NewNode(newProto(testNode, nil, nil, map[string]string{
+		TopologyZoneLabel: "zone-2",
+		"something":       "just a text",
+	}))
+	labels := node.GetTopologyLabels()
+	assert.DeepEqual(t, labels, map[string]string{TopologyZoneLabel: "zone-2"})
+	labels[TopologyRegionLabel] = "region-2"
+	assert.Equal(t, len(node.GetTopologyLabels()), 1, "topology labels of the node changed")
+
+	// updated attributes rebuild the labels
+	node.UpdateAttributes(map[string]string{TopologyRegionLabel: "region-2"})
+	assert.DeepEqual(t, node.GetTopologyLabels(), map[string]string{TopologyZoneLabel: "zone-2", TopologyRegionLabel: "region-2"})
+	assert.DeepEqual(t, node.Clone().GetTopologyLabels(), node.GetTopologyLabels())
+
+	// no labels set
+	node = NewNode(newProto(testNode, nil, nil, map[string]string{"something": "just a text"}))
+	assert.Equal(t, len(node.GetTopologyLabels()), 0, "node should not have topology labels")
+}
+
+func TestNodeClone(t *testing.T) {
+	node := newNode(nodeID1, map[string]resources.Quantity{"first": 100})
+	half := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 50})
+	node.AddAllocation(newAllocation(appID1, "1", nodeID1, "queue-1", half))
+	clone := node.Clone()
+	assert.Assert(t, resources.Equals(clone.GetAllocatedResource(), half), "allocated resource not copied")
+	alloc := node.GetAllocation("1")
+	cloneAlloc := clone.GetAllocation("1")
+	assert.Assert(t, cloneAlloc != nil && cloneAlloc != alloc, "allocation not copied")
+	assert.Assert(t, cloneAlloc.Ask != alloc.Ask, "allocation ask shared with clone")
+	// changes to the clone must not change the original
+	clone.AddAllocation(newAllocation(appID1, "2", nodeID1, "queue-1", half))
+	clone.RemoveAllocation("1")
+	assert.Equal(t, len(node.GetAllAllocations()), 1, "allocations of original changed")
+	assert.Assert(t, resources.Equals(node.GetAllocatedResource(), half), "allocated resource of original changed")
 }
 
 func TestAddAllocation(t *testing.T) {
@@ -486,6 +619,28 @@ func TestRemoveAllocation(t *testing.T) {
 	}
 }
 
+func TestGetApplicationAllocationCount(t *testing.T) {
+	node := newNode("node-123", map[string]resources.Quantity{"first": 100})
+	assert.Equal(t, node.GetApplicationAllocationCount(appID1), 0, "empty node should not have allocations")
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10})
+	node.AddAllocation(newAllocation(appID1, "1", nodeID1, "queue-1", res))
+	node.AddAllocation(newAllocation(appID1, "2", nodeID1, "queue-1", res))
+	node.AddAllocation(newAllocation("app-2", "3", nodeID1, "queue-1", res))
+	assert.Equal(t, node.GetApplicationAllocationCount(appID1), 2, "unexpected allocation count for app-1")
+	assert.Equal(t, node.GetApplicationAllocationCount("app-2"), 1, "unexpected allocation count for app-2")
+	// a failed add must not change the count
+	node.AddAllocation(newAllocation(appID1, "4", nodeID1, "queue-1", resources.NewResourceFromMap(map[string]resources.Quantity{"first": 100})))
+	assert.Equal(t, node.GetApplicationAllocationCount(appID1), 2, "allocation that does not fit should not be counted")
+	clone := node.Clone()
+	node.RemoveAllocation("1")
+	assert.Equal(t, node.GetApplicationAllocationCount(appID1), 1, "count not decreased on remove")
+	assert.Equal(t, clone.GetApplicationAllocationCount(appID1), 2, "clone count changed on remove from the original")
+	node.RemoveAllocation("2")
+	node.RemoveAllocation("2")
+	assert.Equal(t, node.GetApplicationAllocationCount(appID1), 0, "count not cleared on remove of the last allocation")
+	assert.Equal(t, len(node.appAllocations), 1, "application entry not removed")
+}
+
 func TestGetAllocation(t *testing.T) {
 	node := newNode("node-123", map[string]resources.Quantity{"first": 100, "second": 200})
 	if !resources.IsZero(node.GetAllocatedResource()) {
@@ -560,7 +715,8 @@ func TestUpdateResources(t *testing.T) {
 
 	// adjust capacity check available updated
 	total = resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5, "second": 5})
-	node.SetCapacity(total)
+	err := node.SetCapacity(total)
+	assert.NilError(t, err, "capacity update should not have failed")
 	if !resources.Equals(total, node.GetCapacity()) {
 		t.Errorf("total resources should have been updated to: %s, got %s", total, node.GetCapacity())
 	}
@@ -604,4 +760,127 @@ func TestUpdateResources(t *testing.T) {
 	if !resources.Equals(available, node.GetAvailableResource()) {
 		t.Errorf("available resources should have been updated to: %s, got %s", available, node.GetAvailableResource())
 	}
+
+	// a resize cannot drop the capacity below the allocated resources
+	assert.Assert(t, !node.IsOverAllocated(), "node should not be over allocated")
+	shrunk := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 4, "second": 10})
+	err = node.SetCapacity(shrunk)
+	assert.ErrorContains(t, err, "less than the allocated resources")
+	assert.Assert(t, resources.Equals(total, node.GetCapacity()), "capacity should not have changed: %s", node.GetCapacity())
+	assert.Assert(t, resources.Equals(available, node.GetAvailableResource()), "available should not have changed: %s", node.GetAvailableResource())
+
+	// the capacity set by the RM is applied and over allocates the node
+	node.SetReportedCapacity(shrunk)
+	assert.Assert(t, resources.Equals(shrunk, node.GetCapacity()), "capacity should have changed: %s", node.GetCapacity())
+	assert.Assert(t, node.IsOverAllocated(), "node should be over allocated")
+}
+
+func TestGetAllocationDensity(t *testing.T) {
+	node := newNodeRes("node-nil", nil)
+	assert.Equal(t, node.GetAllocationDensity(), float64(0), "nil capacity node should have no density")
+	node = newNode("node-0", map[string]resources.Quantity{})
+	assert.Equal(t, node.GetAllocationDensity(), float64(0), "zero capacity node should have no density")
+
+	// the dominant share is used: 2 allocations use 0.4 of the node
+	node = newNode(nodeID1, map[string]resources.Quantity{"first": 15, "second": 5})
+	assert.Equal(t, node.GetAllocationDensity(), float64(0), "node without allocations should have no density")
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1, "second": 1})
+	node.AddAllocation(newAllocation(appID1, "1", nodeID1, "queue-1", res))
+	node.AddAllocation(newAllocation(appID1, "2", nodeID1, "queue-1", res))
+	assert.Equal(t, node.GetAllocationDensity(), 5.0, "unexpected density")
+
+	// the units of a resource type do not change the density
+	node = newNode("node-2", map[string]resources.Quantity{"first": 15000000, "second": 5})
+	res = resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1000, "second": 1})
+	node.AddAllocation(newAllocation(appID1, "1", "node-2", "queue-1", res))
+	node.AddAllocation(newAllocation(appID1, "2", "node-2", "queue-1", res))
+	assert.Equal(t, node.GetAllocationDensity(), 5.0, "density should not depend on the resource units")
+
+	// allocations that do not use any capacity
+	node = newNode("node-3", map[string]resources.Quantity{"first": 15})
+	node.AddAllocation(newAllocation(appID1, "1", "node-3", "queue-1", resources.NewResource()))
+	assert.Equal(t, node.GetAllocationDensity(), float64(0), "allocations without usage should have no density")
+}
+
+func TestGetUtilization(t *testing.T) {
+	// zero capacity node
+	node := newNode("node-0", map[string]resources.Quantity{})
+	assert.Equal(t, node.GetCPUUtilization(), float64(0), "zero capacity node should have no cpu utilisation")
+	assert.Equal(t, node.GetMemoryUtilization(), float64(0), "zero capacity node should have no memory utilisation")
+	assert.Equal(t, node.GetStorageUtilization(), float64(0), "zero capacity node should have no storage utilisation")
+	assert.Equal(t, node.GetDominantUtilization(), float64(0), "zero capacity node should have no utilisation")
+	node = newNodeRes("node-nil", nil)
+	assert.Equal(t, node.GetDominantUtilization(), float64(0), "nil capacity node should have no utilisation")
+
+	// capacity for memory only, zero vcore capacity
+	node = newNode("node-1", map[string]resources.Quantity{resources.MEMORY: 100, resources.VCORE: 0})
+	node.AddAllocation(newAllocation(appID1, "1", nodeID1, "queue-1", resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 25})))
+	assert.Equal(t, node.GetMemoryUtilization(), 0.25, "unexpected memory utilisation")
+	assert.Equal(t, node.GetCPUUtilization(), float64(0), "zero vcore capacity should have no cpu utilisation")
+	assert.Equal(t, node.GetDominantUtilization(), 0.25, "unexpected dominant utilisation")
+
+	node = newNode("node-2", map[string]resources.Quantity{resources.MEMORY: 100, resources.VCORE: 10, "gpu": 4})
+	node.AddAllocation(newAllocation(appID1, "1", nodeID1, "queue-1", resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 50, resources.VCORE: 2, "gpu": 3})))
+	assert.Equal(t, node.GetMemoryUtilization(), 0.5, "unexpected memory utilisation")
+	assert.Equal(t, node.GetCPUUtilization(), 0.2, "unexpected cpu utilisation")
+	assert.Equal(t, node.GetResourceUtilization("gpu"), 0.75, "unexpected gpu utilisation")
+	assert.Equal(t, node.GetDominantUtilization(), 0.75, "unexpected dominant utilisation")
+
+	node = newNode("node-3", map[string]resources.Quantity{resources.MEMORY: 100, resources.STORAGE: 1000})
+	node.AddAllocation(newAllocation(appID1, "1", nodeID1, "queue-1", resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 10, resources.STORAGE: 400})))
+	assert.Equal(t, node.GetStorageUtilization(), 0.4, "unexpected storage utilisation")
+	assert.Equal(t, node.GetDominantUtilization(), 0.4, "unexpected dominant utilisation")
+}
+
+func TestGetAvailableEphemeralStorage(t *testing.T) {
+	node := newNode("node-0", map[string]resources.Quantity{resources.MEMORY: 100})
+	assert.Equal(t, node.GetAvailableEphemeralStorage(), int64(0), "node without storage capacity should have no storage available")
+	node = newNodeRes("node-nil", nil)
+	assert.Equal(t, node.GetAvailableEphemeralStorage(), int64(0), "nil capacity node should have no storage available")
+
+	node = newNode("node-1", map[string]resources.Quantity{resources.MEMORY: 100, resources.STORAGE: 1000})
+	assert.Equal(t, node.GetAvailableEphemeralStorage(), int64(1000), "unexpected available storage on empty node")
+	alloc := newAllocation(appID1, "1", nodeID1, "queue-1", resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 10, resources.STORAGE: 400}))
+	node.AddAllocation(alloc)
+	assert.Equal(t, node.GetAvailableEphemeralStorage(), int64(600), "unexpected available storage after allocation")
+	// an ask that needs more storage than available does not fit
+	err := node.preAllocateCheck(resources.NewResourceFromMap(map[string]resources.Quantity{resources.STORAGE: 700}), "", false)
+	assert.ErrorContains(t, err, "larger than currently available", "storage exceeding the available storage should not have fitted")
+	err = node.preAllocateCheck(resources.NewResourceFromMap(map[string]resources.Quantity{resources.STORAGE: 600}), "", false)
+	assert.NilError(t, err, "storage within the available storage should have fitted")
+	node.RemoveAllocation(alloc.UUID)
+	assert.Equal(t, node.GetAvailableEphemeralStorage(), int64(1000), "unexpected available storage after removal")
+}
+
+func TestNodeScore(t *testing.T) {
+	total := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 100, "vcore": 10})
+	// memory 50% and vcore 20% used before placement
+	node := newNodeInternal(testNode, total, resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 50, "vcore": 2}))
+	ask := newAllocationAsk(aKey, appID1, resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10, "vcore": 6}))
+	binPacking := policies.NewNodeSortingPolicy("binpacking", nil)
+	fair := policies.NewNodeSortingPolicy("fair", nil)
+	bestFit := policies.NewNodeSortingPolicy("bestfit", nil)
+
+	// no ask: dominant utilisation of the node as it is
+	assert.Equal(t, node.Score(nil, binPacking), 0.5, "unexpected bin packing score without ask")
+	assert.Equal(t, node.Score(nil, fair), -0.5, "unexpected fair score without ask")
+	// after placement memory is 60% and vcore 80% used
+	assert.Equal(t, node.Score(ask, binPacking), 0.8, "unexpected bin packing score")
+	assert.Equal(t, node.Score(ask, fair), -0.8, "unexpected fair score")
+	expected := -math.Sqrt(0.4*0.4 + 0.2*0.2)
+	assert.Assert(t, math.Abs(node.Score(ask, bestFit)-expected) < 1e-9, "unexpected best fit score %f", node.Score(ask, bestFit))
+
+	// weights use the weighted average utilisation: (3*0.6 + 1*0.8) / 4
+	weighted := policies.NewNodeSortingPolicy("binpacking", map[string]string{"binpacking.memoryWeight": "3"})
+	assert.Assert(t, math.Abs(node.Score(ask, weighted)-0.65) < 1e-9, "unexpected weighted score %f", node.Score(ask, weighted))
+
+	// ask does not fit: lowest score for all policies
+	large := newAllocationAsk(aKey, appID1, resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 60}))
+	for _, policy := range []*policies.NodeSortingPolicy{binPacking, fair, bestFit} {
+		assert.Assert(t, math.IsInf(node.Score(large, policy), -1), "ask that does not fit should have the lowest score for %s", policy.PolicyType)
+	}
+
+	// a node without resources is considered full
+	empty := newNode("empty", nil)
+	assert.Equal(t, empty.Score(nil, binPacking), 1.0, "node without resources should be full")
 }
@@ -20,6 +20,8 @@ package objects
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -37,7 +39,11 @@ import (
 	"github.com/apache/incubator-yunikorn-core/pkg/webservice/dao"
 )
 
-const appTagNamespaceResourceQuota = "namespace.resourcequota"
+const (
+	appTagNamespaceResourceQuota = "namespace.resourcequota"
+	// weight of a queue that does not have the weight property set
+	defaultQueueWeight = 1.0
+)
 
 // Represents Queue inside Scheduler
 type Queue struct {
@@ -66,6 +72,13 @@ type Queue struct {
 	isManaged          bool                // queue is part of the config, not auto created
 	stateMachine       *fsm.FSM            // the state of the queue for scheduling
 	stateTime          time.Time           // last time the state was updated (needed for cleanup)
+	drainRequested     bool                // queue is draining on request, not because it was removed from the config
+	allocationCounts   allocationCounter   // allocations made in the queue per second
+	maxConcurrentApps  int                 // maximum number of applications in a leaf queue, 0 means no limit
+	weight             float64             // weight of the queue compared to its siblings
+	aggregateProps     map[string]string   // properties merged with the parent properties, nil if not calculated
+	aggregateVersion   uint64              // changed each time the aggregate properties are dropped
+	isSimulation       bool                // queue is part of a simulated partition, metrics are not updated
 
 	sync.RWMutex
 }
@@ -80,6 +93,7 @@ func newBlankQueue() *Queue {
 		allocatedResource: resources.NewResource(),
 		preempting:        resources.NewResource(),
 		pending:           resources.NewResource(),
+		weight:            defaultQueueWeight,
 	}
 }
 
@@ -91,6 +105,9 @@ func NewConfiguredQueue(conf configs.QueueConfig, parent *Queue) (*Queue, error)
 	sq.QueuePath = strings.ToLower(conf.Name)
 	sq.parent = parent
 	sq.isManaged = true
+	if parent != nil {
+		sq.isSimulation = parent.IsSimulation()
+	}
 
 	// update the properties
 	if err := sq.setQueueConfig(conf); err != nil {
@@ -105,8 +122,6 @@ func NewConfiguredQueue(conf configs.QueueConfig, parent *Queue) (*Queue, error)
 		if err != nil {
 			return nil, fmt.Errorf("configured queue creation failed: %s", err)
 		}
-		// pull the properties from the parent that should be set on the child
-		sq.mergeProperties(parent.getProperties(), conf.Properties)
 	}
 	sq.UpdateSortType()
 
@@ -135,6 +150,7 @@ func NewDynamicQueue(name string, leaf bool, parent *Queue) (*Queue, error) {
 	sq.parent = parent
 	sq.isManaged = false
 	sq.isLeaf = leaf
+	sq.isSimulation = parent.IsSimulation()
 
 	// add to the parent, we might have a partition lock already
 	// still need to make sure we lock the parent so we do not interfere with scheduling
@@ -143,7 +159,7 @@ func NewDynamicQueue(name string, leaf bool, parent *Queue) (*Queue, error) {
 		return nil, fmt.Errorf("dynamic queue creation failed: %s", err)
 	}
 	// pull the properties from the parent that should be set on the child
-	sq.setTemplateProperties(parent.getProperties())
+	sq.setTemplateProperties(parent.GetAggregateProperties())
 	sq.UpdateSortType()
 	log.Logger().Debug("dynamic queue added to scheduler",
 		zap.String("queueName", sq.QueuePath))
@@ -151,6 +167,15 @@ func NewDynamicQueue(name string, leaf bool, parent *Queue) (*Queue, error) {
 	return sq, nil
 }
 
+// Return the value of the property set on this queue and true, an empty string and false if the property is not set.
+// Only the properties of the queue itself are checked, not the ones inherited from the parent.
+func (sq *Queue) GetProperty(key string) (string, bool) {
+	sq.RLock()
+	defer sq.RUnlock()
+	value, ok := sq.properties[key]
+	return value, ok
+}
+
 // Return a copy of the properties for this queue
 // Will never return a nil, can return an empty map.
 func (sq *Queue) getProperties() map[string]string {
@@ -163,27 +188,66 @@ func (sq *Queue) getProperties() map[string]string {
 	return props
 }
 
-// Merge the properties from the parent queue and the config in the set from new queue
-// lock free call
-func (sq *Queue) mergeProperties(parent, config map[string]string) {
-	// clean out all existing values (handles update case)
-	sq.properties = make(map[string]string)
-	// Set the parent properties
-	if len(parent) != 0 {
-		for key, value := range parent {
-			sq.properties[key] = value
+// Return the properties of the queue merged with the properties of its parents, the value closest to the queue wins.
+// The merged set is calculated on first use and cached until the properties of the queue or one of its parents change.
+// Unmanaged queues do not inherit, they only have the properties set from the template of the parent.
+// The weight is not inherited: it is relative to the siblings of the queue it is set on.
+// Will never return a nil, can return an empty map.
+func (sq *Queue) GetAggregateProperties() map[string]string {
+	sq.RLock()
+	merged := sq.aggregateProps
+	version := sq.aggregateVersion
+	parent := sq.parent
+	inherit := sq.isManaged
+	sq.RUnlock()
+	if merged == nil {
+		// the parent returns a copy: safe to change
+		if parent != nil && inherit {
+			merged = parent.GetAggregateProperties()
+			delete(merged, configs.QueueWeight)
+		} else {
+			merged = make(map[string]string)
 		}
-	}
-	// merge the config properties
-	if len(config) > 0 {
-		for key, value := range config {
-			sq.properties[key] = value
+		// the parent cannot be locked while holding the queue lock: merge first and only store the result if the
+		// aggregate was not dropped while merging, the result could be stale otherwise
+		sq.Lock()
+		for key, value := range sq.properties {
+			merged[key] = value
 		}
+		if sq.aggregateVersion == version {
+			sq.aggregateProps = merged
+		}
+		sq.Unlock()
+	}
+	// the cached map is replaced, never changed
+	props := make(map[string]string, len(merged))
+	for key, value := range merged {
+		props[key] = value
+	}
+	return props
+}
+
+// Drop the cached aggregate properties of the queue and all its descendants.
+// The settings derived from the properties of the descendants are updated with the new aggregate.
+func (sq *Queue) updateAggregateProperties() {
+	sq.Lock()
+	sq.dropAggregateProperties()
+	sq.Unlock()
+	for _, child := range sq.GetCopyOfChildren() {
+		child.updateAggregateProperties()
+		child.UpdateSortType()
 	}
 }
 
+// Drop the cached aggregate properties of the queue, a merge that is in progress will not be stored.
+// lock free call, must be called holding the queue lock
+func (sq *Queue) dropAggregateProperties() {
+	sq.aggregateProps = nil
+	sq.aggregateVersion++
+}
+
 // Set the properties that the dynamic child queue inherits from the parent
-// The properties list for the parent must be retrieved using getProperties()
+// The properties list for the parent must be retrieved using GetAggregateProperties()
 // This currently only sets the sort policy as it is set on the parent
 // Further implementation is part of YUNIKORN-193
 // lock free call
@@ -200,8 +264,10 @@ func (sq *Queue) setTemplateProperties(parent map[string]string) {
 
 func (sq *Queue) SetQueueConfig(conf configs.QueueConfig) error {
 	sq.Lock()
-	defer sq.Unlock()
-	return sq.setQueueConfig(conf)
+	err := sq.setQueueConfig(conf)
+	sq.Unlock()
+	sq.updateAggregateProperties()
+	return err
 }
 
 // Apply all the properties to the queue from the config
@@ -262,44 +328,231 @@ func (sq *Queue) setQueueConfig(conf configs.QueueConfig) error {
 	return nil
 }
 
-// Update the sortType for the queue based on the current properties
+// Update the sortType and the concurrent application limit for the queue based on the current properties
 func (sq *Queue) UpdateSortType() {
+	// merge before locking: the parents are locked while merging
+	props := sq.GetAggregateProperties()
 	sq.Lock()
 	defer sq.Unlock()
+	sq.maxConcurrentApps = 0
+	// the weight applies to parent and leaf queues
+	sq.weight = defaultQueueWeight
+	if value, ok := props[configs.QueueWeight]; ok {
+		weight, err := configs.ParseQueueWeight(value)
+		if err != nil {
+			log.Logger().Debug("queue weight property configuration error",
+				zap.Error(err))
+		} else {
+			sq.weight = weight
+		}
+	}
+	// set the sorting type for parent queues
+	if !sq.isLeaf {
+		sq.sortType = policies.FairSortPolicy
+		return
+	}
 	// set the defaults, override with what is in the configured properties
-	if sq.isLeaf {
-		// walk over all properties and process
-		var err error
-		sq.sortType = policies.Undefined
-		for key, value := range sq.properties {
-			if key == configs.ApplicationSortPolicy {
-				sq.sortType, err = policies.SortPolicyFromString(value)
-				if err != nil {
-					log.Logger().Debug("application sort property configuration error",
-						zap.Error(err))
-				}
+	// walk over all properties and process
+	var err error
+	policy := policies.Undefined
+	for key, value := range props {
+		switch key {
+		case configs.ApplicationSortPolicy:
+			policy, err = policies.SortPolicyFromString(value)
+			if err != nil {
+				log.Logger().Debug("application sort property configuration error",
+					zap.Error(err))
 			}
+		case configs.MaxConcurrentApplications:
+			if sq.maxConcurrentApps, err = parseMaxConcurrentApplications(value); err != nil {
+				log.Logger().Debug("max concurrent applications property configuration error",
+					zap.Error(err))
+			}
+		case configs.QueueWeight:
+			// processed for all queues above
+		default:
 			// for now skip the rest just log them
 			log.Logger().Debug("queue property skipped",
 				zap.String("key", key),
 				zap.String("value", value))
 		}
-		// if it is not defined default to fifo
-		if sq.sortType == policies.Undefined {
-			sq.sortType = policies.FifoSortPolicy
-		}
-		return
 	}
-	// set the sorting type for parent queues
-	sq.sortType = policies.FairSortPolicy
+	// if it is not defined default to fifo
+	if policy == policies.Undefined {
+		policy = policies.FifoSortPolicy
+	}
+	if err = sq.setApplicationSortingPolicy(policy); err != nil {
+		log.Logger().Debug("application sort property configuration error",
+			zap.Error(err))
+	}
 }
 
-func (sq *Queue) GetQueuePath() string {
+// Parse the max concurrent applications property, a negative or non numeric value is an error.
+func parseMaxConcurrentApplications(value string) (int, error) {
+	limit, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s value %s: %v", configs.MaxConcurrentApplications, value, err)
+	}
+	if limit < 0 {
+		return 0, fmt.Errorf("invalid %s value %s: cannot be negative", configs.MaxConcurrentApplications, value)
+	}
+	return limit, nil
+}
+
+// Return the weight of the queue compared to its siblings.
+func (sq *Queue) GetWeight() float64 {
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.weight
+}
+
+// Return the maximum number of applications allowed in the queue at the same time, 0 means no limit.
+func (sq *Queue) GetMaxConcurrentApplications() int {
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.maxConcurrentApps
+}
+
+// Return the number of applications currently in the queue.
+func (sq *Queue) GetConcurrentApplicationCount() int {
+	sq.RLock()
+	defer sq.RUnlock()
+	return len(sq.applications)
+}
+
+// Return the policy used to sort the applications in the queue.
+// Parent queues always sort their child queues fair.
+func (sq *Queue) GetApplicationSortingPolicy() policies.SortPolicy {
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.sortType
+}
+
+// Change the policy used to sort the applications in a leaf queue.
+// The change is picked up by the next scheduling cycle. The policy is also stored in the queue properties, a config
+// reload replaces it with the configured policy.
+func (sq *Queue) SetApplicationSortingPolicy(policy policies.SortPolicy) error {
 	sq.Lock()
 	defer sq.Unlock()
+	if err := sq.setApplicationSortingPolicy(policy); err != nil {
+		return err
+	}
+	// the properties can be shared with the config: replace, do not modify
+	props := make(map[string]string, len(sq.properties)+1)
+	for key, value := range sq.properties {
+		props[key] = value
+	}
+	props[configs.ApplicationSortPolicy] = policy.String()
+	sq.properties = props
+	sq.dropAggregateProperties()
+	return nil
+}
+
+// Set the policy used to sort the applications, used by the config and the runtime updates.
+// Locked call: the queue write lock must be held.
+func (sq *Queue) setApplicationSortingPolicy(policy policies.SortPolicy) error {
+	if err := sq.checkApplicationSortingPolicy(policy); err != nil {
+		return err
+	}
+	sq.sortType = policy
+	return nil
+}
+
+// Check if the policy can be used to sort the applications in the queue.
+func (sq *Queue) checkApplicationSortingPolicy(policy policies.SortPolicy) error {
+	if !sq.isLeaf {
+		return fmt.Errorf("cannot set application sort policy on parent queue %s", sq.QueuePath)
+	}
+	if policy == policies.Undefined {
+		return fmt.Errorf("cannot set undefined application sort policy on queue %s", sq.QueuePath)
+	}
+	return nil
+}
+
+// Update the properties of the queue at runtime, the properties passed in are merged into the current properties.
+// All keys and values are checked before any change is made: an unknown key or an invalid value fails the whole update.
+// Like the runtime sort policy change a config reload replaces the updated properties with the configured ones.
+// The descendants of the queue inherit the updated properties.
+func (sq *Queue) SetProperties(props map[string]string) error {
+	if err := sq.setProperties(props); err != nil {
+		return err
+	}
+	sq.updateAggregateProperties()
+	return nil
+}
+
+func (sq *Queue) setProperties(props map[string]string) error {
+	sq.Lock()
+	defer sq.Unlock()
+	sortType := sq.sortType
+	maxApps := sq.maxConcurrentApps
+	weight := sq.weight
+	for key, value := range props {
+		switch key {
+		case configs.ApplicationSortPolicy:
+			policy, err := policies.SortPolicyFromString(value)
+			if err != nil {
+				return err
+			}
+			if err = sq.checkApplicationSortingPolicy(policy); err != nil {
+				return err
+			}
+			sortType = policy
+		case configs.MaxConcurrentApplications:
+			if !sq.isLeaf {
+				return fmt.Errorf("cannot set max concurrent applications on parent queue %s", sq.QueuePath)
+			}
+			limit, err := parseMaxConcurrentApplications(value)
+			if err != nil {
+				return err
+			}
+			maxApps = limit
+		case configs.QueueWeight:
+			parsed, err := configs.ParseQueueWeight(value)
+			if err != nil {
+				return err
+			}
+			weight = parsed
+		default:
+			return fmt.Errorf("unknown property %s for queue %s", key, sq.QueuePath)
+		}
+	}
+	// the properties can be shared with the config: replace, do not modify
+	merged := make(map[string]string, len(sq.properties)+len(props))
+	for key, value := range sq.properties {
+		merged[key] = value
+	}
+	for key, value := range props {
+		merged[key] = value
+	}
+	sq.properties = merged
+	if sortType != sq.sortType {
+		// checked above: cannot fail
+		_ = sq.setApplicationSortingPolicy(sortType)
+	}
+	sq.maxConcurrentApps = maxApps
+	sq.weight = weight
+	return nil
+}
+
+// Return the fully qualified path of the queue.
+// The path is cached on the queue when it is created, the parent of a queue never changes.
+func (sq *Queue) GetQueuePath() string {
+	sq.RLock()
+	defer sq.RUnlock()
 	return sq.QueuePath
 }
 
+// Return the depth of the queue in the hierarchy, the root queue has depth 0.
+// The parent link is set on creation and never changes: no locks are taken.
+func (sq *Queue) GetQueueDepth() int {
+	depth := 0
+	for parent := sq.parent; parent != nil; parent = parent.parent {
+		depth++
+	}
+	return depth
+}
+
 // Is the queue marked for deletion and can only handle existing application requests.
 // No new applications will be accepted.
 func (sq *Queue) IsDraining() bool {
@@ -351,6 +604,72 @@ func (sq *Queue) GetGuaranteedResource() *resources.Resource {
 	return sq.guaranteedResource
 }
 
+// Return the load of the queue: the dominant fraction of the load resource that is allocated.
+// The load resource is the guaranteed resource, falling back to the max resource of the queue or its parents.
+// Returns -1 if the load cannot be measured as none of these are set.
+// The load is larger than 1 if the queue uses more than its guaranteed resource.
+func (sq *Queue) GetQueueLoad() float64 {
+	total := sq.getLoadResource()
+	if total == nil {
+		return -1
+	}
+	return sq.GetAllocatedResource().DominantFraction(total)
+}
+
+// Return the load of the queue for a single resource type.
+// Returns -1 if the load cannot be measured as the load resource does not define the type.
+func (sq *Queue) GetQueueLoadByResource(resourceType string) float64 {
+	total := sq.getLoadResource()
+	if total == nil || total.Resources[resourceType] <= 0 {
+		return -1
+	}
+	return float64(sq.GetAllocatedResource().Resources[resourceType]) / float64(total.Resources[resourceType])
+}
+
+// Return the fair share ratio of the queue compared to its siblings.
+// The ratio is the dominant share of the allocated resource of the queue in its fair entitlement. The entitlement is
+// the load resource of the parent divided over the children of the parent in proportion to their weight.
+// A ratio below 1 means the queue uses less than its fair share, above 1 more than its fair share.
+// Returns -1 for the root queue or if the parent has no resource to divide.
+func (sq *Queue) GetSiblingFairShare() float64 {
+	entitlement := sq.GetFairEntitlement()
+	if entitlement == nil {
+		return -1
+	}
+	return sq.GetAllocatedResource().DominantFraction(entitlement)
+}
+
+// Return the fair entitlement of the queue: the load resource of the parent divided over the children of the parent
+// in proportion to their weight.
+// Returns nil for the root queue or if the parent has no resource to divide.
+func (sq *Queue) GetFairEntitlement() *resources.Resource {
+	if sq.parent == nil {
+		return nil
+	}
+	total := sq.parent.getLoadResource()
+	if total == nil {
+		return nil
+	}
+	var weights float64
+	for _, sibling := range sq.parent.GetCopyOfChildren() {
+		weights += sibling.GetWeight()
+	}
+	entitlement := resources.MultiplyBy(total, sq.GetWeight()/weights)
+	if resources.IsZero(entitlement) {
+		return nil
+	}
+	return entitlement
+}
+
+// Return the resource the load of the queue is measured against.
+// Lock free call all locks are taken when needed in called functions
+func (sq *Queue) getLoadResource() *resources.Resource {
+	if guaranteed := sq.GetGuaranteedResource(); resources.StrictlyGreaterThanZero(guaranteed) {
+		return guaranteed
+	}
+	return sq.GetMaxResource()
+}
+
 // Check if the user has access to the queue to submit an application recursively.
 // This will check the submit ACL and the admin ACL.
 func (sq *Queue) CheckSubmitAccess(user security.UserGroup) bool {
@@ -374,13 +693,74 @@ func (sq *Queue) CheckAdminAccess(user security.UserGroup) bool {
 	return allow
 }
 
-// Convert the queue hierarchy into an object for the webservice
-
+// Convert the queue hierarchy into an object for the webservice.
+// Children are added in alphabetical order at each level of the hierarchy.
 func (sq *Queue) GetQueueInfos() dao.QueueDAOInfo {
-	queueInfo := dao.QueueDAOInfo{}
-	for _, child := range sq.GetCopyOfChildren() {
-		queueInfo.ChildQueues = append(queueInfo.ChildQueues, child.GetQueueInfos())
+	var childInfos []dao.QueueDAOInfo
+	for _, child := range sq.getSortedChildren() {
+		childInfos = append(childInfos, child.GetQueueInfos())
+	}
+	return sq.getQueueInfo(childInfos)
+}
+
+// Return the queue hierarchy limited to the queues that match the filter.
+// Ancestors of a matching queue are always included, subtrees without a matching queue are left out.
+// The queue this is called on is always returned, even if nothing in the hierarchy matches.
+func (sq *Queue) GetQueueInfosFiltered(filter dao.QueueFilter) dao.QueueDAOInfo {
+	queueInfo, _ := sq.getQueueInfosFiltered(filter)
+	return queueInfo
+}
+
+// Return the filtered queue info and true if the queue or one of its descendants matches the filter.
+func (sq *Queue) getQueueInfosFiltered(filter dao.QueueFilter) (dao.QueueDAOInfo, bool) {
+	var childInfos []dao.QueueDAOInfo
+	for _, child := range sq.getSortedChildren() {
+		if childInfo, ok := child.getQueueInfosFiltered(filter); ok {
+			childInfos = append(childInfos, childInfo)
+		}
 	}
+	queueInfo := sq.getQueueInfo(childInfos)
+	return queueInfo, len(childInfos) > 0 || queueInfoMatches(queueInfo, filter)
+}
+
+// Return true if the queue info matches all criteria set in the filter.
+func queueInfoMatches(queueInfo dao.QueueDAOInfo, filter dao.QueueFilter) bool {
+	if filter.MinLoad != nil && queueInfo.Load < *filter.MinLoad {
+		return false
+	}
+	if filter.MaxLoad != nil && queueInfo.Load > *filter.MaxLoad {
+		return false
+	}
+	if filter.State != "" && !strings.EqualFold(queueInfo.Status, filter.State) {
+		return false
+	}
+	if filter.HasApps && queueInfo.ConcurrentApplications == 0 {
+		return false
+	}
+	return true
+}
+
+// Return the children of the queue sorted by name.
+func (sq *Queue) getSortedChildren() []*Queue {
+	children := sq.GetCopyOfChildren()
+	names := make([]string, 0, len(children))
+	for name := range children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	sorted := make([]*Queue, len(names))
+	for i, name := range names {
+		sorted[i] = children[name]
+	}
+	return sorted
+}
+
+// Return the info of this queue with the child infos passed in.
+func (sq *Queue) getQueueInfo(childInfos []dao.QueueDAOInfo) dao.QueueDAOInfo {
+	queueInfo := dao.QueueDAOInfo{}
+	queueInfo.ChildQueues = childInfos
+	queueInfo.Load = sq.GetQueueLoad()
+	queueInfo.Properties = sq.GetAggregateProperties()
 
 	// children are done we can now lock just this queue.
 	sq.RLock()
@@ -394,13 +774,56 @@ func (sq *Queue) GetQueueInfos() dao.QueueDAOInfo {
 		AbsUsedCapacity: resources.CalculateAbsUsedCapacity(
 			sq.maxResource, sq.allocatedResource).DAOString(),
 	}
-	queueInfo.Properties = make(map[string]string)
-	for k, v := range sq.properties {
-		queueInfo.Properties[k] = v
-	}
+	queueInfo.MaxConcurrentApplications = sq.maxConcurrentApps
+	queueInfo.ConcurrentApplications = len(sq.applications)
 	return queueInfo
 }
 
+// Convert the queue hierarchy back into the configuration that would create it.
+// Only managed queues are part of the configuration: unmanaged queues and queues that are marked for removal
+// are not exported. The max resource of the root queue is set based on the registered nodes and is not exported.
+// Children are exported in alphabetical order.
+func (sq *Queue) ExportConfig() configs.QueueConfig {
+	children := sq.GetCopyOfChildren()
+	names := make([]string, 0, len(children))
+	for name := range children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var childConfigs []configs.QueueConfig
+	for _, name := range names {
+		child := children[name]
+		if !child.IsManaged() || child.IsDraining() {
+			continue
+		}
+		childConfigs = append(childConfigs, child.ExportConfig())
+	}
+
+	// children are done we can now lock just this queue.
+	sq.RLock()
+	defer sq.RUnlock()
+	conf := configs.QueueConfig{
+		Name:      sq.Name,
+		Parent:    !sq.isLeaf,
+		AdminACL:  sq.adminACL.String(),
+		SubmitACL: sq.submitACL.String(),
+		Queues:    childConfigs,
+	}
+	if sq.parent != nil && sq.maxResource != nil {
+		conf.Resources.Max = sq.maxResource.ToConf()
+	}
+	if sq.guaranteedResource != nil {
+		conf.Resources.Guaranteed = sq.guaranteedResource.ToConf()
+	}
+	if len(sq.properties) != 0 {
+		conf.Properties = make(map[string]string)
+		for key, value := range sq.properties {
+			conf.Properties[key] = value
+		}
+	}
+	return conf
+}
+
 // Return the pending resources for this queue
 func (sq *Queue) GetPendingResource() *resources.Resource {
 	sq.RLock()
@@ -440,16 +863,21 @@ func (sq *Queue) decPendingResource(delta *resources.Resource) {
 
 // Add  app to the queue. All checks are assumed to have passed before we get here.
 // No update of pending resource is needed as it should not have any requests yet.
-// Replaces the existing application without further checks.
-func (sq *Queue) AddApplication(app *Application) {
+// Replaces the existing application without further checks. A new application is rejected if the queue
+// has reached the maximum number of concurrent applications. The limit does not apply to a simulated queue:
+// it copies the applications of the real queue, which could be over a lowered limit.
+func (sq *Queue) AddApplication(app *Application) error {
 	sq.Lock()
 	defer sq.Unlock()
+	if _, ok := sq.applications[app.ApplicationID]; !ok && !sq.isSimulation && sq.maxConcurrentApps > 0 && len(sq.applications) >= sq.maxConcurrentApps {
+		return fmt.Errorf("queue %s has reached the maximum of %d concurrent applications", sq.QueuePath, sq.maxConcurrentApps)
+	}
 	sq.applications[app.ApplicationID] = app
 	// YUNIKORN-199: update the quota from the namespace
 	// get the tag with the quota
 	quota := app.GetTag(appTagNamespaceResourceQuota)
 	if quota == "" {
-		return
+		return nil
 	}
 	// need to set a quota: convert json string to resource
 	res, err := resources.NewResourceFromString(quota)
@@ -457,20 +885,21 @@ func (sq *Queue) AddApplication(app *Application) {
 		log.Logger().Error("application resource quota conversion failure",
 			zap.String("json quota string", quota),
 			zap.Error(err))
-		return
+		return nil
 	}
 	if !resources.StrictlyGreaterThanZero(res) {
 		log.Logger().Error("application resource quota has at least one 0 value: cannot set queue limit",
 			zap.String("maxResource", res.String()))
-		return
+		return nil
 	}
 	// set the quota
 	if sq.isManaged {
 		log.Logger().Warn("Trying to set max resources set on a queue that is not an unmanaged leaf",
 			zap.String("queueName", sq.QueuePath))
-		return
+		return nil
 	}
 	sq.maxResource = res
+	return nil
 }
 
 // Remove the app from the list of tracked applications. Make sure that the app
@@ -511,6 +940,127 @@ func (sq *Queue) getCopyOfApps() map[string]*Application {
 	return appsCopy
 }
 
+// Return the pending ask with the largest dominant resource demand in the queue hierarchy starting at this queue.
+// The demand is compared against the maximum resources for this queue.
+// Returns nil if there are no pending asks.
+func (sq *Queue) GetLargestPendingAsk() *AllocationAsk {
+	return sq.findPendingAsk(1)
+}
+
+// Return the pending ask with the smallest dominant resource demand in the queue hierarchy starting at this queue.
+// The demand is compared against the maximum resources for this queue.
+// Returns nil if there are no pending asks.
+func (sq *Queue) GetSmallestPendingAsk() *AllocationAsk {
+	return sq.findPendingAsk(-1)
+}
+
+// Find the pending ask that compares in the requested direction to all other pending asks:
+// 1 returns the largest, -1 returns the smallest ask.
+func (sq *Queue) findPendingAsk(direction int) *AllocationAsk {
+	if resources.IsZero(sq.GetPendingResource()) {
+		return nil
+	}
+	total := sq.GetMaxResource()
+	var found *AllocationAsk
+	for _, ask := range sq.GetAllPendingAsks() {
+		if found == nil || resources.CompUsageRatio(ask.AllocatedResource, found.AllocatedResource, total) == direction {
+			found = ask
+		}
+	}
+	return found
+}
+
+// Remove all allocations from the applications in the queue hierarchy starting at this queue.
+// The allocated resource of each queue in the hierarchy is zeroed, the queues above this queue are updated for
+// the released usage. The removed allocations are returned, the nodes are not updated.
+// Lock free call all locks are taken when needed in called functions
+func (sq *Queue) ClearAllAllocations() []*Allocation {
+	used := sq.GetAllocatedResource()
+	released := sq.clearAllocations()
+	if sq.parent != nil {
+		if err := sq.parent.DecAllocatedResource(used); err != nil {
+			log.Logger().Warn("failed to release cleared allocations from parent queue",
+				zap.String("queueName", sq.QueuePath),
+				zap.Error(err))
+		}
+	}
+	return released
+}
+
+// Remove all allocations in the queue hierarchy and zero the allocated resource of each queue.
+// Lock free call all locks are taken when needed in called functions
+func (sq *Queue) clearAllocations() []*Allocation {
+	released := make([]*Allocation, 0)
+	if sq.IsLeafQueue() {
+		for _, app := range sq.getCopyOfApps() {
+			released = append(released, app.RemoveAllAllocations()...)
+		}
+	} else {
+		for _, child := range sq.GetCopyOfChildren() {
+			released = append(released, child.clearAllocations()...)
+		}
+	}
+	sq.Lock()
+	defer sq.Unlock()
+	sq.allocatedResource = resources.NewResource()
+	sq.updateUsedResourceMetrics()
+	return released
+}
+
+// Get all pending asks from the applications in the queue hierarchy starting at this queue.
+// An ask is pending if it has at least one repeat left, partially allocated asks are included.
+func (sq *Queue) GetAllPendingAsks() []*AllocationAsk {
+	asks := make([]*AllocationAsk, 0)
+	if sq.IsLeafQueue() {
+		for _, app := range sq.getCopyOfApps() {
+			asks = append(asks, app.GetPendingAsks()...)
+		}
+		return asks
+	}
+	for _, child := range sq.GetCopyOfChildren() {
+		asks = append(asks, child.GetAllPendingAsks()...)
+	}
+	return asks
+}
+
+// Return the lowest and highest effective priority of the pending asks from the applications in the queue
+// hierarchy starting at this queue. Returns 0, 0 if there are no pending asks.
+func (sq *Queue) GetApplicationPriorityRange() (min, max int32) {
+	asks := sq.GetAllPendingAsks()
+	if len(asks) == 0 {
+		return 0, 0
+	}
+	min = asks[0].GetEffectivePriority()
+	max = min
+	for _, ask := range asks[1:] {
+		priority := ask.GetEffectivePriority()
+		if priority < min {
+			min = priority
+		}
+		if priority > max {
+			max = priority
+		}
+	}
+	return min, max
+}
+
+// Get the number of pending asks from the applications in the queue hierarchy starting at this queue.
+// This is the length of the list returned by GetAllPendingAsks without building the list.
+func (sq *Queue) GetPendingAskCount() int {
+	if sq.IsLeafQueue() {
+		var count int
+		for _, app := range sq.getCopyOfApps() {
+			count += app.getPendingAskCount()
+		}
+		return count
+	}
+	var count int
+	for _, child := range sq.GetCopyOfChildren() {
+		count += child.GetPendingAskCount()
+	}
+	return count
+}
+
 // Get a copy of the child queues
 // This is used by the partition manager to find all queues to clean however we can not
 // guarantee that there is no new child added while we clean up since there is no overall
@@ -525,6 +1075,15 @@ func (sq *Queue) GetCopyOfChildren() map[string]*Queue {
 	return childCopy
 }
 
+// Call visit for every queue below this queue, a parent queue is visited before its children.
+// The queue itself is not visited.
+func (sq *Queue) WalkDescendants(visit func(queue *Queue)) {
+	for _, child := range sq.GetCopyOfChildren() {
+		visit(child)
+		child.WalkDescendants(visit)
+	}
+}
+
 // Check if the queue is empty
 // A parent queue is empty when it has no children left
 // A leaf queue is empty when there are no applications left
@@ -576,6 +1135,8 @@ func (sq *Queue) MarkQueueForRemoval() {
 	if sq.isManaged {
 		log.Logger().Info("marking managed queue for deletion",
 			zap.String("queue", sq.QueuePath))
+		// the queue is now awaiting removal, even if it was drained on request before
+		sq.drainRequested = false
 		if err := sq.handleQueueEvent(Remove); err != nil {
 			log.Logger().Info("failed to mark managed queue for deletion",
 				zap.String("queue", sq.QueuePath),
@@ -589,6 +1150,67 @@ func (sq *Queue) MarkQueueForRemoval() {
 	}
 }
 
+// Drain the queue on request: no new applications are accepted while the existing applications complete.
+// All children of the queue are drained too. Returns an error if the queue is already draining.
+// A managed queue drained on request is not removed when it is empty, unless it is also removed from
+// the configuration.
+func (sq *Queue) Drain() error {
+	sq.Lock()
+	defer sq.Unlock()
+	if sq.IsDraining() {
+		return fmt.Errorf("queue %s is already draining", sq.QueuePath)
+	}
+	if err := sq.handleQueueEvent(Remove); err != nil {
+		return err
+	}
+	log.Logger().Info("draining queue on request",
+		zap.String("queue", sq.QueuePath))
+	sq.drainRequested = true
+	for _, child := range sq.children {
+		if err := child.Drain(); err != nil {
+			log.Logger().Debug("child queue not drained",
+				zap.String("queue", child.QueuePath),
+				zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// Undrain a queue that was drained on request: new applications are accepted again.
+// All children of the queue that were drained on request are undrained too. Returns an error if the queue is not
+// drained on request or if the parent queue is draining. A queue removed from the configuration cannot be undrained,
+// it is only restored by adding it back to the configuration after it has been removed.
+func (sq *Queue) Undrain() error {
+	if sq.parent != nil && sq.parent.IsDraining() {
+		return fmt.Errorf("parent of queue %s is draining", sq.QueuePath)
+	}
+	sq.Lock()
+	defer sq.Unlock()
+	if !sq.drainRequested {
+		return fmt.Errorf("queue %s is not drained on request", sq.QueuePath)
+	}
+	sq.undrain()
+	return nil
+}
+
+// Move the queue and the children drained on request back into the active state.
+// Locked call, the children are locked while they are undrained.
+func (sq *Queue) undrain() {
+	log.Logger().Info("undraining queue on request",
+		zap.String("queue", sq.QueuePath))
+	// the state machine does not allow the transition out of draining, that is only used for removal
+	sq.stateMachine.SetState(Active.String())
+	sq.stateTime = time.Now()
+	sq.drainRequested = false
+	for _, child := range sq.children {
+		child.Lock()
+		if child.drainRequested {
+			child.undrain()
+		}
+		child.Unlock()
+	}
+}
+
 // Get a child queue based on the name of the child.
 func (sq *Queue) GetChildQueue(name string) *Queue {
 	sq.RLock()
@@ -603,8 +1225,8 @@ func (sq *Queue) GetChildQueue(name string) *Queue {
 func (sq *Queue) RemoveQueue() bool {
 	sq.RLock()
 	defer sq.RUnlock()
-	// cannot remove a managed queue that is running
-	if sq.isManaged && sq.IsRunning() {
+	// cannot remove a managed queue that is running or is drained on request while still configured
+	if sq.isManaged && (sq.IsRunning() || sq.drainRequested) {
 		return false
 	}
 	// cannot remove a queue that has children or applications assigned
@@ -617,6 +1239,24 @@ func (sq *Queue) RemoveQueue() bool {
 	return true
 }
 
+// Remove the dynamic (unmanaged) queues below this queue that have no applications and no allocations.
+// Children are checked before their parent: a dynamic parent queue that is left without children is removed too.
+// Managed queues are never removed. Returns the number of queues removed.
+func (sq *Queue) RemoveDynamicChildrenWithNoApps() int {
+	removed := 0
+	for _, child := range sq.GetCopyOfChildren() {
+		removed += child.RemoveDynamicChildrenWithNoApps()
+		if child.IsManaged() || !resources.IsZero(child.GetAllocatedResource()) {
+			continue
+		}
+		// fails if the queue still has children or applications
+		if child.RemoveQueue() {
+			removed++
+		}
+	}
+	return removed
+}
+
 // Is this queue a leaf or not (i.e parent)
 func (sq *Queue) IsLeafQueue() bool {
 	sq.RLock()
@@ -680,7 +1320,7 @@ func (sq *Queue) IncAllocatedResource(alloc *resources.Resource, nodeReported bo
 	// check this queue: failure stops checks if the allocation is not part of a node addition
 	newAllocated := resources.Add(sq.allocatedResource, alloc)
 	if !nodeReported {
-		if sq.maxResource != nil && !resources.FitIn(sq.maxResource, newAllocated) {
+		if sq.maxResource != nil && !newAllocated.LessThanOrEqual(sq.maxResource) {
 			return fmt.Errorf("allocation (%v) puts queue %s over maximum allocation (%v)",
 				alloc, sq.QueuePath, sq.maxResource)
 		}
@@ -701,6 +1341,34 @@ func (sq *Queue) IncAllocatedResource(alloc *resources.Resource, nodeReported bo
 	return nil
 }
 
+// Count a committed allocation for this queue and all its parents.
+func (sq *Queue) CountAllocation() {
+	sq.countAllocation(time.Now())
+}
+
+// Count an allocation committed at the time for this queue (recursively).
+func (sq *Queue) countAllocation(now time.Time) {
+	sq.Lock()
+	sq.allocationCounts.add(now)
+	parent := sq.parent
+	sq.Unlock()
+	if parent != nil {
+		parent.countAllocation(now)
+	}
+}
+
+// Return the number of allocations committed in this queue and its children during the window ending now.
+// The window is truncated to whole seconds and capped at MaxAllocationRateWindow.
+func (sq *Queue) GetAllocationCount(window time.Duration) int64 {
+	return sq.getAllocationCount(time.Now(), window)
+}
+
+func (sq *Queue) getAllocationCount(now time.Time, window time.Duration) int64 {
+	sq.RLock()
+	defer sq.RUnlock()
+	return sq.allocationCounts.count(now, window)
+}
+
 // Decrement the allocated resources for this queue (recursively)
 // Guard against going below zero resources.
 func (sq *Queue) DecAllocatedResource(alloc *resources.Resource) error {
@@ -708,7 +1376,7 @@ func (sq *Queue) DecAllocatedResource(alloc *resources.Resource) error {
 	defer sq.Unlock()
 
 	// check this queue: failure stops checks
-	if alloc != nil && !resources.FitIn(sq.allocatedResource, alloc) {
+	if alloc != nil && !sq.allocatedResource.GreaterThanOrEqual(alloc) {
 		return fmt.Errorf("released allocation (%v) is larger than '%s' queue allocation (%v)",
 			alloc, sq.QueuePath, sq.allocatedResource)
 	}
@@ -737,7 +1405,7 @@ func (sq *Queue) sortApplications() []*Application {
 		return nil
 	}
 	// Sort the applications
-	return sortApplications(sq.getCopyOfApps(), sq.getSortType(), sq.GetGuaranteedResource())
+	return sortApplications(sq.getCopyOfApps(), sq.GetApplicationSortingPolicy(), sq.getLoadResource())
 }
 
 // Return a sorted copy of the queues for this parent queue.
@@ -761,7 +1429,7 @@ func (sq *Queue) sortQueues() []*Queue {
 		}
 	}
 	// Sort the queues
-	sortQueue(sortedQueues, sq.getSortType())
+	sortQueue(sortedQueues, sq.GetApplicationSortingPolicy())
 
 	return sortedQueues
 }
@@ -838,18 +1506,54 @@ func (sq *Queue) GetMaxResource() *resources.Resource {
 	return resources.ComponentWiseMin(limit, sq.maxResource)
 }
 
-// Set the max resource for root the queue.
-// Should only happen on the root, all other queues get it from the config via properties.
-func (sq *Queue) SetMaxResource(max *resources.Resource) {
+// Set the max resource for the queue.
+// The root queue follows the size of the cluster and is set without checks.
+// For all other queues the new max must not be lower than the current allocation and must fit in the max of
+// the parent. Child queues with a max larger than the new max are reduced to fit. A nil, empty or zero max
+// removes the limit from the queue.
+func (sq *Queue) SetMaxResource(max *resources.Resource) error {
+	if sq.parent == nil {
+		sq.Lock()
+		defer sq.Unlock()
+		sq.maxResource = max.Clone()
+		return nil
+	}
+	if max != nil && (len(max.Resources) == 0 || resources.IsZero(max)) {
+		max = nil
+	}
+	// check the parent before locking this queue: locks are taken child first when allocating
+	if parentMax := sq.parent.GetMaxResource(); max != nil && parentMax != nil && !resources.FitIn(parentMax, max) {
+		return fmt.Errorf("max resource %s for queue %s is larger than the parent max %s", max, sq.GetQueuePath(), parentMax)
+	}
 	sq.Lock()
-	defer sq.Unlock()
-
-	if sq.parent != nil {
-		log.Logger().Warn("Max resources set on a queue that is not the root",
-			zap.String("queueName", sq.QueuePath))
-		return
+	if max != nil && !resources.FitIn(max, sq.allocatedResource) {
+		sq.Unlock()
+		return fmt.Errorf("max resource %s for queue %s is lower than the allocated resource %s", max, sq.QueuePath, sq.allocatedResource)
 	}
 	sq.maxResource = max.Clone()
+	sq.Unlock()
+	if max != nil {
+		for _, child := range sq.GetCopyOfChildren() {
+			child.reduceMaxResource(max)
+		}
+	}
+	return nil
+}
+
+// Reduce the max resource of the queue and its children to fit in the limit.
+// Queues without a max or with a max that fits are not changed.
+func (sq *Queue) reduceMaxResource(limit *resources.Resource) {
+	sq.Lock()
+	if sq.maxResource != nil && !resources.FitIn(limit, sq.maxResource) {
+		sq.maxResource = resources.ComponentWiseMin(sq.maxResource, limit)
+		log.Logger().Info("queue max resource reduced to fit the parent",
+			zap.String("queueName", sq.QueuePath),
+			zap.String("maxResource", sq.maxResource.String()))
+	}
+	sq.Unlock()
+	for _, child := range sq.GetCopyOfChildren() {
+		child.reduceMaxResource(limit)
+	}
 }
 
 // Try allocate pending requests. This only gets called if there is a pending request on this queue or its children.
@@ -857,12 +1561,17 @@ func (sq *Queue) SetMaxResource(max *resources.Resource) {
 // the configured queue sortPolicy. Queues without pending resources are skipped.
 // Applications are sorted based on the application sortPolicy. Applications without pending resources are skipped.
 // Lock free call this all locks are taken when needed in called functions
-func (sq *Queue) TryAllocate(iterator func() interfaces.NodeIterator) *Allocation {
+func (sq *Queue) TryAllocate(iterator func(ask *AllocationAsk) interfaces.NodeIterator) *Allocation {
 	if sq.IsLeafQueue() {
 		// get the headroom
 		headRoom := sq.getHeadRoom()
 		// process the apps (filters out app without pending requests)
 		for _, app := range sq.sortApplications() {
+			// skip the app if it has reached its limit
+			if app.IsAllocationLimitReached() {
+				app.recordLimitDenied()
+				continue
+			}
 			alloc := app.tryAllocate(headRoom, iterator)
 			if alloc != nil {
 				log.Logger().Debug("allocation found on queue",
@@ -902,7 +1611,7 @@ func (sq *Queue) GetQueueOutstandingRequests(total *[]*AllocationAsk) {
 // the configured queue sortPolicy. Queues without pending resources are skipped.
 // Applications are currently NOT sorted and are iterated over in a random order.
 // Lock free call this all locks are taken when needed in called functions
-func (sq *Queue) TryReservedAllocate(iterator func() interfaces.NodeIterator) *Allocation {
+func (sq *Queue) TryReservedAllocate(iterator func(ask *AllocationAsk) interfaces.NodeIterator) *Allocation {
 	if sq.IsLeafQueue() {
 		// skip if it has no reservations
 		reservedCopy := sq.getReservedApps()
@@ -923,6 +1632,10 @@ func (sq *Queue) TryReservedAllocate(iterator func() interfaces.NodeIterator) *A
 						zap.String("appID", appID))
 					return nil
 				}
+				if app.IsAllocationLimitReached() {
+					app.recordLimitDenied()
+					continue
+				}
 				alloc := app.tryReservedAllocate(headRoom, iterator)
 				if alloc != nil {
 					log.Logger().Debug("reservation found for allocation found on queue",
@@ -945,6 +1658,159 @@ func (sq *Queue) TryReservedAllocate(iterator func() interfaces.NodeIterator) *A
 	return nil
 }
 
+// Reserve nodes for all asks of a gang, or for none of them.
+// Each ask is placed on the first node from the iterator for that ask, so the node constraints of the ask like a
+// required topology or the scheduling constraints of the application are applied per ask. The resources and the
+// allocations per node of the asks already placed are taken into account: a node is used for as many asks of the
+// gang as fit in the available resource of the node and the reservation limit of the node allows.
+// If a node cannot be reserved, for instance because a concurrent reservation reached the node limit, the ask is
+// tried on the next node. If an ask cannot be reserved the reservations made are released and nil is returned.
+// All asks must belong to applications in this leaf queue.
+// The caller is responsible for tracking the reservations outside the queue, application and node.
+func (sq *Queue) TryReserveForGang(gang []*AllocationAsk, iterator func(ask *AllocationAsk) interfaces.NodeIterator) []*Reservation {
+	if len(gang) == 0 || !sq.IsLeafQueue() {
+		return nil
+	}
+	apps := make(map[string]*Application)
+	for _, ask := range gang {
+		app := sq.getApplication(ask.ApplicationID)
+		if app == nil {
+			log.Logger().Debug("gang ask application not found in queue",
+				zap.String("queueName", sq.QueuePath),
+				zap.String("appID", ask.ApplicationID),
+				zap.String("allocationKey", ask.AllocationKey))
+			return nil
+		}
+		apps[ask.ApplicationID] = app
+	}
+	// get the nodes for all asks before reserving: a reserved node is not returned by the iterator
+	nodeIterators := make([]interfaces.NodeIterator, len(gang))
+	for i, ask := range gang {
+		if nodeIterators[i] = iterator(ask); nodeIterators[i] == nil {
+			log.Logger().Debug("no nodes available for gang ask",
+				zap.String("queueName", sq.QueuePath),
+				zap.String("allocationKey", ask.AllocationKey))
+			return nil
+		}
+	}
+	type gangReservation struct {
+		app  *Application
+		node *Node
+		ask  *AllocationAsk
+	}
+	reserved := make([]gangReservation, 0, len(gang))
+	placement := newGangPlacement()
+	var unreserved *AllocationAsk
+	for i, ask := range gang {
+		node := placement.reserve(apps[ask.ApplicationID], ask, nodeIterators[i])
+		if node == nil {
+			unreserved = ask
+			break
+		}
+		reserved = append(reserved, gangReservation{app: apps[ask.ApplicationID], node: node, ask: ask})
+	}
+	if unreserved != nil {
+		for _, res := range reserved {
+			if _, err := res.app.UnReserve(res.node, res.ask); err != nil {
+				log.Logger().Warn("failed to release gang reservation",
+					zap.String("appID", res.app.ApplicationID),
+					zap.String("nodeID", res.node.NodeID),
+					zap.String("allocationKey", res.ask.AllocationKey),
+					zap.Error(err))
+			}
+		}
+		log.Logger().Debug("gang cannot be reserved",
+			zap.String("queueName", sq.QueuePath),
+			zap.Int("gangSize", len(gang)),
+			zap.String("unreservedKey", unreserved.AllocationKey))
+		return nil
+	}
+	result := make([]*Reservation, 0, len(reserved))
+	for _, res := range reserved {
+		sq.Reserve(res.app.ApplicationID)
+		result = append(result, &Reservation{
+			NodeID:        res.node.NodeID,
+			ApplicationID: res.app.ApplicationID,
+			AllocationKey: res.ask.AllocationKey,
+		})
+	}
+	log.Logger().Info("gang reserved",
+		zap.String("queueName", sq.QueuePath),
+		zap.Int("gangSize", len(gang)))
+	return result
+}
+
+// The nodes reserved for the members of a gang while the gang is placed.
+type gangPlacement struct {
+	available map[string]*resources.Resource // resources left on the nodes used by the gang
+	placed    map[string]int                 // gang members placed per application and node
+	used      []*Node                        // nodes used by the gang in order of first use
+}
+
+func newGangPlacement() *gangPlacement {
+	return &gangPlacement{
+		available: make(map[string]*resources.Resource),
+		placed:    make(map[string]int),
+	}
+}
+
+// Reserve a node for the gang member and return it, nil if no node could be reserved.
+// Only the nodes returned by the iterator for the ask are considered. The nodes already used by the gang are tried
+// first, in order of use, to keep the gang on as few nodes as possible. The other nodes follow in iterator order.
+func (gp *gangPlacement) reserve(app *Application, ask *AllocationAsk, nodeIterator interfaces.NodeIterator) *Node {
+	candidates := make([]*Node, 0)
+	allowed := make(map[string]bool)
+	for nodeIterator.HasNext() {
+		node, ok := nodeIterator.Next().(*Node)
+		if !ok {
+			log.Logger().Warn("Node iterator failed to return a node")
+			break
+		}
+		candidates = append(candidates, node)
+		allowed[node.NodeID] = true
+	}
+	ordered := make([]*Node, 0, len(candidates))
+	for _, node := range gp.used {
+		if allowed[node.NodeID] {
+			ordered = append(ordered, node)
+		}
+	}
+	for _, node := range candidates {
+		if _, ok := gp.available[node.NodeID]; !ok {
+			ordered = append(ordered, node)
+		}
+	}
+	maxPerNode := 0
+	if constraints := ask.GetSchedulingConstraints(); constraints != nil {
+		maxPerNode = constraints.MaxAllocationsPerNode
+	}
+	for _, node := range ordered {
+		available, ok := gp.available[node.NodeID]
+		if !ok {
+			available = node.GetAvailableResource()
+		}
+		if !resources.FitIn(available, ask.AllocatedResource) {
+			continue
+		}
+		// the iterator only checked the allocations on the node, not the gang members placed on it
+		key := app.ApplicationID + "|" + node.NodeID
+		if maxPerNode > 0 && gp.placed[key] > 0 && node.GetApplicationAllocationCount(app.ApplicationID)+gp.placed[key] >= maxPerNode {
+			continue
+		}
+		if app.Reserve(node, ask) != nil {
+			continue
+		}
+		if !ok {
+			gp.used = append(gp.used, node)
+		}
+		available.SubFrom(ask.AllocatedResource)
+		gp.available[node.NodeID] = available
+		gp.placed[key]++
+		return node
+	}
+	return nil
+}
+
 // Get a copy of the reserved app list
 // locked to prevent race conditions from event updates
 func (sq *Queue) getReservedApps() map[string]int {
@@ -991,16 +1857,27 @@ func (sq *Queue) getApplication(appID string) *Application {
 	return sq.applications[appID]
 }
 
-// get the queue sort type holding a lock
-func (sq *Queue) getSortType() policies.SortPolicy {
+// Mark the queue and all its children as part of a simulated partition.
+// Queues created below a marked queue inherit the mark.
+func (sq *Queue) SetSimulation() {
+	sq.Lock()
+	defer sq.Unlock()
+	sq.isSimulation = true
+	for _, child := range sq.children {
+		child.SetSimulation()
+	}
+}
+
+// Return true if the queue is part of a simulated partition.
+func (sq *Queue) IsSimulation() bool {
 	sq.RLock()
 	defer sq.RUnlock()
-	return sq.sortType
+	return sq.isSimulation
 }
 
-// update queue metrics when this is a leaf queue
+// update queue metrics when this is a leaf queue, a simulated queue never updates the metrics
 func (sq *Queue) updateUsedResourceMetrics() {
-	if sq.isLeaf {
+	if sq.isLeaf && !sq.isSimulation {
 		for k, v := range sq.allocatedResource.Resources {
 			metrics.GetQueueMetrics(sq.QueuePath).SetQueueUsedResourceMetrics(k, float64(v))
 		}
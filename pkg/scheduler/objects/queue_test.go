@@ -22,12 +22,15 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"gotest.tools/assert"
 
 	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
 	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/policies"
 	"github.com/apache/incubator-yunikorn-core/pkg/webservice/dao"
 )
 
@@ -81,7 +84,8 @@ func TestManagedSubQueues(t *testing.T) {
 
 	// cannot remove child with app in it
 	app := newApplication(appID1, "default", "root.parent.leaf")
-	leaf.AddApplication(app)
+	err = leaf.AddApplication(app)
+	assert.NilError(t, err, "failed to add application to queue")
 
 	// both parent and leaf are marked for removal
 	parent.MarkQueueForRemoval()
@@ -131,7 +135,8 @@ func TestDynamicSubQueues(t *testing.T) {
 
 	// cannot remove child with app in it
 	app := newApplication(appID1, "default", "root.parent.leaf")
-	leaf.AddApplication(app)
+	err = leaf.AddApplication(app)
+	assert.NilError(t, err, "failed to add application to queue")
 
 	// try to mark parent and leaf for removal
 	parent.MarkQueueForRemoval()
@@ -153,6 +158,49 @@ func TestDynamicSubQueues(t *testing.T) {
 	}
 }
 
+func TestRemoveDynamicChildrenWithNoApps(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "failed to create root queue")
+	var managed, managedLeaf, dynParent, emptyParent *Queue
+	managed, err = createManagedQueue(root, "managed", true, nil)
+	assert.NilError(t, err, "failed to create managed parent queue")
+	managedLeaf, err = createManagedQueue(managed, "leaf", false, nil)
+	assert.NilError(t, err, "failed to create managed leaf queue")
+	dynParent, err = createDynamicQueue(root, "dynamic", true)
+	assert.NilError(t, err, "failed to create dynamic parent queue")
+	var drained, active, allocated *Queue
+	drained, err = createDynamicQueue(dynParent, "drained", false)
+	assert.NilError(t, err, "failed to create dynamic leaf queue")
+	active, err = createDynamicQueue(dynParent, "active", false)
+	assert.NilError(t, err, "failed to create dynamic leaf queue")
+	allocated, err = createDynamicQueue(dynParent, "allocated", false)
+	assert.NilError(t, err, "failed to create dynamic leaf queue")
+	emptyParent, err = createDynamicQueue(root, "empty", true)
+	assert.NilError(t, err, "failed to create dynamic parent queue")
+	_, err = createDynamicQueue(emptyParent, "unused", false)
+	assert.NilError(t, err, "failed to create dynamic leaf queue")
+
+	err = active.AddApplication(newApplication("app-2", "default", active.QueuePath))
+	assert.NilError(t, err, "failed to add application to queue")
+	err = allocated.IncAllocatedResource(resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1}), false)
+	assert.NilError(t, err, "failed to set allocated resource")
+	// an app added and removed again leaves the queue drained
+	app := newApplication(appID1, "default", drained.QueuePath)
+	err = drained.AddApplication(app)
+	assert.NilError(t, err, "failed to add application to queue")
+	assert.Equal(t, root.RemoveDynamicChildrenWithNoApps(), 2, "only the empty parent and its leaf should be removed")
+	drained.RemoveApplication(app)
+
+	assert.Equal(t, root.RemoveDynamicChildrenWithNoApps(), 1, "only the drained queue should have been removed")
+	assert.Equal(t, len(root.GetCopyOfChildren()), 2, "managed and dynamic parent should remain")
+	children := dynParent.GetCopyOfChildren()
+	assert.Equal(t, len(children), 2, "dynamic queues with apps or allocations should remain")
+	assert.Assert(t, children["active"] != nil && children["allocated"] != nil, "unexpected children left: %v", children)
+	assert.Equal(t, managed.GetCopyOfChildren()["leaf"], managedLeaf, "managed queues should not be removed")
+	// nothing left to remove
+	assert.Equal(t, root.RemoveDynamicChildrenWithNoApps(), 0, "no queues should have been removed")
+}
+
 func TestPendingCalc(t *testing.T) {
 	// create the root
 	root, err := createRootQueue(nil)
@@ -241,12 +289,14 @@ func TestAddApplication(t *testing.T) {
 	app := newApplication(appID1, "default", "root.parent.leaf")
 	app.pending = pending
 	// adding the app must not update pending resources
-	leaf.AddApplication(app)
+	err = leaf.AddApplication(app)
+	assert.NilError(t, err, "failed to add application to queue")
 	assert.Equal(t, len(leaf.applications), 1, "Application was not added to the queue as expected")
 	assert.Assert(t, resources.IsZero(leaf.pending), "leaf queue pending resource not zero")
 
 	// add the same app again should not increase the number of apps
-	leaf.AddApplication(app)
+	err = leaf.AddApplication(app)
+	assert.NilError(t, err, "failed to add application to queue")
 	assert.Equal(t, len(leaf.applications), 1, "Application was not replaced in the queue as expected")
 }
 
@@ -263,13 +313,15 @@ func TestAddApplicationWithTag(t *testing.T) {
 	app := newApplication(appID1, "default", "root.leaf-man")
 
 	// adding the app to managed/Dynamic queue must not update queue settings, works
-	leaf.AddApplication(app)
+	err = leaf.AddApplication(app)
+	assert.NilError(t, err, "failed to add application to queue")
 	assert.Equal(t, len(leaf.applications), 1, "Application was not added to the managed queue as expected")
 	if leaf.GetMaxResource() != nil {
 		t.Errorf("Max resources should not be set on managed queue got: %s", leaf.GetMaxResource().String())
 	}
 	app = newApplication("app-2", "default", "root.leaf-un")
-	leafUn.AddApplication(app)
+	err = leafUn.AddApplication(app)
+	assert.NilError(t, err, "failed to add application to queue")
 	assert.Equal(t, len(leaf.applications), 1, "Application was not added to the Dynamic queue as expected")
 	if leafUn.GetMaxResource() != nil {
 		t.Errorf("Max resources should not be set on Dynamic queue got: %s", leafUn.GetMaxResource().String())
@@ -283,13 +335,15 @@ func TestAddApplicationWithTag(t *testing.T) {
 	tags[appTagNamespaceResourceQuota] = "{\"resources\":{\"first\":{\"value\":10}}}"
 	// add apps again now with the tag set
 	app = newApplicationWithTags("app-3", "default", "root.leaf-man", tags)
-	leaf.AddApplication(app)
+	err = leaf.AddApplication(app)
+	assert.NilError(t, err, "failed to add application to queue")
 	assert.Equal(t, len(leaf.applications), 2, "Application was not added to the managed queue as expected")
 	if leaf.GetMaxResource() != nil {
 		t.Errorf("Max resources should not be set on managed queue got: %s", leaf.GetMaxResource().String())
 	}
 	app = newApplicationWithTags("app-4", "default", "root.leaf-un", tags)
-	leafUn.AddApplication(app)
+	err = leafUn.AddApplication(app)
+	assert.NilError(t, err, "failed to add application to queue")
 	assert.Equal(t, len(leaf.applications), 2, "Application was not added to the Dynamic queue as expected")
 	if !resources.Equals(leafUn.GetMaxResource(), maxRes) {
 		t.Errorf("Max resources not set as expected: %s got: %v", maxRes.String(), leafUn.GetMaxResource())
@@ -298,7 +352,8 @@ func TestAddApplicationWithTag(t *testing.T) {
 	// set to illegal limit (0 value)
 	tags[appTagNamespaceResourceQuota] = "{\"resources\":{\"first\":{\"value\":0}}}"
 	app = newApplicationWithTags("app-4", "default", "root.leaf-un", tags)
-	leafUn.AddApplication(app)
+	err = leafUn.AddApplication(app)
+	assert.NilError(t, err, "failed to add application to queue")
 	assert.Equal(t, len(leaf.applications), 2, "Application was not added to the Dynamic queue as expected")
 	if !resources.Equals(leafUn.GetMaxResource(), maxRes) {
 		t.Errorf("Max resources not set as expected: %s got: %v", maxRes.String(), leafUn.GetMaxResource())
@@ -319,7 +374,8 @@ func TestRemoveApplication(t *testing.T) {
 
 	// add an app and remove it
 	app := newApplication("exists", "default", "root.leaf-man")
-	leaf.AddApplication(app)
+	err = leaf.AddApplication(app)
+	assert.NilError(t, err, "failed to add application to queue")
 	assert.Equal(t, len(leaf.applications), 1, "Application was not added to the queue as expected")
 	assert.Assert(t, resources.IsZero(leaf.pending), "leaf queue pending resource not zero")
 	leaf.RemoveApplication(nonExist)
@@ -330,7 +386,8 @@ func TestRemoveApplication(t *testing.T) {
 	// try the same again now with pending resources set
 	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10})
 	app.pending.AddTo(res)
-	leaf.AddApplication(app)
+	err = leaf.AddApplication(app)
+	assert.NilError(t, err, "failed to add application to queue")
 	assert.Equal(t, len(leaf.applications), 1, "Application was not added to the queue as expected")
 	assert.Assert(t, resources.IsZero(leaf.pending), "leaf queue pending resource not zero")
 	// update pending resources for the hierarchy
@@ -342,7 +399,8 @@ func TestRemoveApplication(t *testing.T) {
 
 	app.allocatedResource.AddTo(res)
 	app.pending = resources.NewResource()
-	leaf.AddApplication(app)
+	err = leaf.AddApplication(app)
+	assert.NilError(t, err, "failed to add application to queue")
 	assert.Equal(t, len(leaf.applications), 1, "Application was not added to the queue as expected")
 	assert.Assert(t, resources.IsZero(leaf.allocatedResource), "leaf queue pending resource not zero")
 	// update allocated resources for the hierarchy
@@ -442,7 +500,8 @@ func TestSortApplications(t *testing.T) {
 	// new app does not have pending res, does not get returned
 	app := newApplication(appID1, "default", leaf.QueuePath)
 	app.queue = leaf
-	leaf.AddApplication(app)
+	err = leaf.AddApplication(app)
+	assert.NilError(t, err, "failed to add application to queue")
 	if len(leaf.sortApplications()) != 0 {
 		t.Errorf("app without ask should not be in sorted apps: %v", app)
 	}
@@ -501,6 +560,235 @@ func TestSortQueue(t *testing.T) {
 	}
 }
 
+func TestApplicationSortingPolicy(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	var leaf, parent *Queue
+	parent, err = createManagedQueue(root, "parent", true, nil)
+	assert.NilError(t, err, "failed to create parent queue")
+	leaf, err = createManagedQueue(parent, "leaf", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	// defaults set at creation
+	assert.Equal(t, parent.GetApplicationSortingPolicy(), policies.FairSortPolicy, "unexpected parent queue policy")
+	assert.Equal(t, leaf.GetApplicationSortingPolicy(), policies.FifoSortPolicy, "unexpected leaf queue policy")
+
+	err = parent.SetApplicationSortingPolicy(policies.FifoSortPolicy)
+	assert.ErrorContains(t, err, "parent queue", "policy set on parent queue should have failed")
+	err = leaf.SetApplicationSortingPolicy(policies.Undefined)
+	assert.ErrorContains(t, err, "undefined", "undefined policy set should have failed")
+	assert.Equal(t, leaf.GetApplicationSortingPolicy(), policies.FifoSortPolicy, "leaf queue policy changed on failure")
+
+	// the oldest app has the highest usage: fifo and fair sort differently
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10})
+	for i, appID := range []string{appID1, appID2} {
+		app := newApplication(appID, "default", leaf.QueuePath)
+		app.queue = leaf
+		app.pending = res
+		app.allocatedResource = resources.Multiply(res, int64(2-i))
+		app.SubmissionTime = time.Now().Add(time.Duration(i) * time.Second)
+		err = leaf.AddApplication(app)
+		assert.NilError(t, err, "failed to add application to queue")
+	}
+	sortedApps := leaf.sortApplications()
+	assert.Equal(t, len(sortedApps), 2, "unexpected sorted apps")
+	assert.Equal(t, sortedApps[0].ApplicationID, appID1, "fifo should return the oldest app first")
+
+	err = leaf.SetApplicationSortingPolicy(policies.FairSortPolicy)
+	assert.NilError(t, err, "policy set on leaf queue failed")
+	assert.Equal(t, leaf.GetApplicationSortingPolicy(), policies.FairSortPolicy, "leaf queue policy not changed")
+	assert.Equal(t, leaf.properties[configs.ApplicationSortPolicy], "fair", "leaf queue property not changed")
+	sortedApps = leaf.sortApplications()
+	assert.Equal(t, len(sortedApps), 2, "unexpected sorted apps")
+	assert.Equal(t, sortedApps[0].ApplicationID, appID2, "fair should return the app with the lowest usage first")
+}
+
+func TestSetProperties(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	var leaf *Queue
+	leaf, err = createManagedQueueWithProps(root, "leaf", false, nil, map[string]string{"custom": "value"})
+	assert.NilError(t, err, "failed to create leaf queue")
+	assert.Equal(t, leaf.GetApplicationSortingPolicy(), policies.FifoSortPolicy, "unexpected leaf queue policy")
+
+	err = root.SetProperties(map[string]string{configs.ApplicationSortPolicy: "fair"})
+	assert.ErrorContains(t, err, "parent queue", "sort policy set on parent queue should have failed")
+	err = leaf.SetProperties(map[string]string{configs.ApplicationSortPolicy: "unknown"})
+	assert.ErrorContains(t, err, "undefined policy", "unknown sort policy should have failed")
+	// the update is all or nothing
+	err = leaf.SetProperties(map[string]string{configs.ApplicationSortPolicy: "fair", "unknown": "value"})
+	assert.ErrorContains(t, err, "unknown property", "unknown property should have failed")
+	assert.Equal(t, leaf.GetApplicationSortingPolicy(), policies.FifoSortPolicy, "leaf queue policy changed on failure")
+	assert.Equal(t, leaf.getProperties()[configs.ApplicationSortPolicy], "", "leaf queue property changed on failure")
+
+	err = leaf.SetProperties(map[string]string{configs.ApplicationSortPolicy: "stateaware"})
+	assert.NilError(t, err, "property update on leaf queue failed")
+	assert.Equal(t, leaf.GetApplicationSortingPolicy(), policies.StateAwarePolicy, "leaf queue policy not changed")
+	assert.DeepEqual(t, leaf.getProperties(), map[string]string{"custom": "value", configs.ApplicationSortPolicy: "stateaware"})
+	// an empty update is allowed and does not change anything
+	err = leaf.SetProperties(nil)
+	assert.NilError(t, err, "empty property update failed")
+	assert.Equal(t, leaf.GetApplicationSortingPolicy(), policies.StateAwarePolicy, "leaf queue policy changed by empty update")
+}
+
+func TestMaxConcurrentApplications(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	var leaf, invalid *Queue
+	leaf, err = createManagedQueueWithProps(root, "leaf", false, nil, map[string]string{configs.MaxConcurrentApplications: "2"})
+	assert.NilError(t, err, "failed to create leaf queue")
+	invalid, err = createManagedQueueWithProps(root, "invalid", false, nil, map[string]string{configs.MaxConcurrentApplications: "-1"})
+	assert.NilError(t, err, "failed to create queue with invalid limit")
+	assert.Equal(t, invalid.GetMaxConcurrentApplications(), 0, "invalid limit should not limit the queue")
+	assert.Equal(t, root.GetMaxConcurrentApplications(), 0, "parent queue should not have a limit")
+	assert.Equal(t, leaf.GetMaxConcurrentApplications(), 2, "limit not set from the properties")
+	assert.Equal(t, leaf.GetConcurrentApplicationCount(), 0, "new queue should not have applications")
+
+	app1 := newApplication(appID1, "default", leaf.QueuePath)
+	app2 := newApplication(appID2, "default", leaf.QueuePath)
+	app3 := newApplication("app-3", "default", leaf.QueuePath)
+	err = leaf.AddApplication(app1)
+	assert.NilError(t, err, "failed to add application to queue")
+	err = leaf.AddApplication(app2)
+	assert.NilError(t, err, "failed to add application to queue")
+	err = leaf.AddApplication(app3)
+	assert.ErrorContains(t, err, "maximum of 2 concurrent applications", "application over the limit should have been rejected")
+	assert.Equal(t, leaf.GetConcurrentApplicationCount(), 2, "rejected application should not be counted")
+	// replacing an existing application is not limited
+	err = leaf.AddApplication(app1)
+	assert.NilError(t, err, "replacing an application should not be limited")
+	info := leaf.GetQueueInfos()
+	assert.Equal(t, info.MaxConcurrentApplications, 2, "unexpected limit in the queue info")
+	assert.Equal(t, info.ConcurrentApplications, 2, "unexpected count in the queue info")
+
+	leaf.RemoveApplication(app1)
+	assert.Equal(t, leaf.GetConcurrentApplicationCount(), 1, "count should decrease when an application is removed")
+	err = leaf.AddApplication(app3)
+	assert.NilError(t, err, "application should be accepted after a removal")
+
+	// the limit can be changed at runtime, 0 removes it
+	err = leaf.SetProperties(map[string]string{configs.MaxConcurrentApplications: "x"})
+	assert.ErrorContains(t, err, "invalid", "non numeric limit should have been rejected")
+	err = root.SetProperties(map[string]string{configs.MaxConcurrentApplications: "1"})
+	assert.ErrorContains(t, err, "parent queue", "limit on a parent queue should have been rejected")
+	err = leaf.SetProperties(map[string]string{configs.MaxConcurrentApplications: "0"})
+	assert.NilError(t, err, "limit update failed")
+	err = leaf.AddApplication(app1)
+	assert.NilError(t, err, "application should be accepted without a limit")
+	assert.Equal(t, leaf.GetConcurrentApplicationCount(), 3, "unexpected application count")
+
+	// a simulated queue copies the applications of the real queue and ignores the limit
+	err = leaf.SetProperties(map[string]string{configs.MaxConcurrentApplications: "1"})
+	assert.NilError(t, err, "limit update failed")
+	root.SetSimulation()
+	err = leaf.AddApplication(newApplication("app-4", "default", leaf.QueuePath))
+	assert.NilError(t, err, "simulated queue should not be limited")
+	assert.Equal(t, leaf.GetConcurrentApplicationCount(), 4, "unexpected application count")
+}
+
+func TestQueueWeight(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	assert.Equal(t, root.GetWeight(), 1.0, "root should have the default weight")
+	var parent, leaf *Queue
+	parent, err = createManagedQueueWithProps(root, "parent", true, nil, map[string]string{configs.QueueWeight: "2.5"})
+	assert.NilError(t, err, "failed to create parent queue")
+	assert.Equal(t, parent.GetWeight(), 2.5, "weight not set on parent queue")
+	for _, value := range []string{"0", "-1", "x", "NaN", "Inf"} {
+		leaf, err = createManagedQueueWithProps(root, "leaf", false, nil, map[string]string{configs.QueueWeight: value})
+		assert.NilError(t, err, "failed to create leaf queue")
+		assert.Equal(t, leaf.GetWeight(), 1.0, "invalid weight %s should be ignored", value)
+		err = leaf.SetProperties(map[string]string{configs.QueueWeight: value})
+		assert.ErrorContains(t, err, "invalid", "invalid weight %s should have been rejected", value)
+	}
+	err = leaf.SetProperties(map[string]string{configs.QueueWeight: "3"})
+	assert.NilError(t, err, "weight update failed")
+	assert.Equal(t, leaf.GetWeight(), 3.0, "weight not updated")
+}
+
+func TestQueueSimulation(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	var parent, leaf *Queue
+	parent, err = createManagedQueue(root, "parent", true, nil)
+	assert.NilError(t, err, "failed to create parent queue")
+	assert.Assert(t, !root.IsSimulation() && !parent.IsSimulation(), "queues should not be marked as a simulation")
+	root.SetSimulation()
+	assert.Assert(t, root.IsSimulation(), "root queue not marked as a simulation")
+	assert.Assert(t, parent.IsSimulation(), "existing child queue not marked as a simulation")
+	// queues created after the mark inherit it
+	leaf, err = createDynamicQueue(parent, "leaf", false)
+	assert.NilError(t, err, "failed to create dynamic leaf queue")
+	assert.Assert(t, leaf.IsSimulation(), "new child queue not marked as a simulation")
+	leaf, err = createManagedQueue(root, "managed", false, nil)
+	assert.NilError(t, err, "failed to create managed leaf queue")
+	assert.Assert(t, leaf.IsSimulation(), "new managed queue not marked as a simulation")
+}
+
+func TestGetSiblingFairShare(t *testing.T) {
+	root, err := createRootQueue(map[string]string{"first": "30"})
+	assert.NilError(t, err, "queue create failed")
+	assert.Equal(t, root.GetSiblingFairShare(), -1.0, "root queue should not have a sibling fair share")
+	var heavy, light *Queue
+	heavy, err = createManagedQueueWithProps(root, "heavy", false, nil, map[string]string{configs.QueueWeight: "2"})
+	assert.NilError(t, err, "failed to create leaf queue")
+	light, err = createManagedQueue(root, "light", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	assert.Equal(t, heavy.GetSiblingFairShare(), 0.0, "unused queue should have a zero fair share")
+
+	// entitlements are 20 and 10 of the root max of 30
+	err = heavy.IncAllocatedResource(resources.NewResourceFromMap(map[string]resources.Quantity{"first": 20}), false)
+	assert.NilError(t, err, "failed to set allocated resource")
+	err = light.IncAllocatedResource(resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10}), false)
+	assert.NilError(t, err, "failed to set allocated resource")
+	assert.Equal(t, heavy.GetSiblingFairShare(), 1.0, "queue with double weight should be at its fair share")
+	assert.Equal(t, light.GetSiblingFairShare(), 1.0, "queue should be at its fair share")
+
+	err = light.DecAllocatedResource(resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5}))
+	assert.NilError(t, err, "failed to release allocated resource")
+	assert.Equal(t, light.GetSiblingFairShare(), 0.5, "queue should be below its fair share")
+
+	// nothing to divide
+	var unlimited *Queue
+	root, err = createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	unlimited, err = createManagedQueue(root, "leaf", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	assert.Equal(t, unlimited.GetSiblingFairShare(), -1.0, "parent without resources should not give a fair share")
+}
+
+func TestAllocationCount(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "queue create failed")
+	var parent, leaf *Queue
+	parent, err = createManagedQueue(root, "parent", true, nil)
+	assert.NilError(t, err, "failed to create parent queue")
+	leaf, err = createManagedQueue(parent, "leaf", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	now := time.Unix(1000, 0)
+	assert.Equal(t, leaf.getAllocationCount(now, time.Minute), int64(0), "new queue should not have allocations")
+
+	// 10 allocations in each of the last 5 seconds
+	for sec := 0; sec < 5; sec++ {
+		for i := 0; i < 10; i++ {
+			leaf.countAllocation(now.Add(-time.Duration(sec) * time.Second))
+		}
+	}
+	assert.Equal(t, leaf.getAllocationCount(now, time.Second), int64(10), "unexpected count for a 1s window")
+	assert.Equal(t, leaf.getAllocationCount(now, 1500*time.Millisecond), int64(10), "window should be truncated to whole seconds")
+	assert.Equal(t, leaf.getAllocationCount(now, 3*time.Second), int64(30), "unexpected count for a 3s window")
+	assert.Equal(t, leaf.getAllocationCount(now, time.Minute), int64(50), "unexpected count for a 1m window")
+	// counts propagate up the hierarchy
+	assert.Equal(t, parent.getAllocationCount(now, time.Minute), int64(50), "unexpected count for the parent")
+	assert.Equal(t, root.getAllocationCount(now, time.Minute), int64(50), "unexpected count for the root")
+	// the window moves with time
+	assert.Equal(t, leaf.getAllocationCount(now.Add(3*time.Second), 5*time.Second), int64(20), "unexpected count for a later window")
+	// old counts are overwritten after the maximum window
+	later := now.Add(MaxAllocationRateWindow)
+	leaf.countAllocation(later)
+	assert.Equal(t, leaf.getAllocationCount(later, time.Second), int64(1), "unexpected count for the overwritten bucket")
+	assert.Equal(t, leaf.getAllocationCount(now, 5*time.Second), int64(40), "overwritten bucket should not be counted for the old window")
+}
+
 func TestHeadroom(t *testing.T) {
 	// create the root: nil test
 	root, err := createRootQueue(nil)
@@ -798,7 +1086,8 @@ func TestGetApp(t *testing.T) {
 
 	// add app and check proper returns
 	app := newApplication(appID1, "default", leaf.QueuePath)
-	leaf.AddApplication(app)
+	err = leaf.AddApplication(app)
+	assert.NilError(t, err, "failed to add application to queue")
 	assert.Equal(t, len(leaf.applications), 1, "queue should have one app registered")
 	if leaf.getApplication(appID1) == nil {
 		t.Errorf("registered app not found using appID")
@@ -821,7 +1110,8 @@ func TestIsEmpty(t *testing.T) {
 
 	// add app and check proper returns
 	app := newApplication(appID1, "default", leaf.QueuePath)
-	leaf.AddApplication(app)
+	err = leaf.AddApplication(app)
+	assert.NilError(t, err, "failed to add application to queue")
 	assert.Equal(t, leaf.IsEmpty(), false, "queue with registered app should not be empty")
 }
 
@@ -844,7 +1134,8 @@ func TestGetOutstandingRequestMax(t *testing.T) {
 
 	app1 := newApplication(appID1, "default", "root.queue1")
 	app1.queue = queue1
-	queue1.AddApplication(app1)
+	err = queue1.AddApplication(app1)
+	assert.NilError(t, err, "failed to add application to queue")
 	var res *resources.Resource
 	res, err = resources.NewResourceFromConf(map[string]string{"cpu": "1"})
 	assert.NilError(t, err, "failed to create basic resource")
@@ -856,7 +1147,8 @@ func TestGetOutstandingRequestMax(t *testing.T) {
 
 	app2 := newApplication(appID2, "default", "root.queue2")
 	app2.queue = queue2
-	queue2.AddApplication(app2)
+	err = queue2.AddApplication(app2)
+	assert.NilError(t, err, "failed to add application to queue")
 	for i := 0; i < 20; i++ {
 		err = app2.AddAllocationAsk(
 			newAllocationAsk(fmt.Sprintf("alloc-%d", i), appID2, res))
@@ -906,6 +1198,162 @@ func TestGetOutstandingRequestMax(t *testing.T) {
 	assert.Equal(t, len(rootTotal), 5)
 }
 
+func TestClearAllAllocations(t *testing.T) {
+	// queue structure:
+	// root
+	//   - parent
+	//     - leaf1
+	//   - leaf2
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "failed to create root queue")
+	var parent, leaf1, leaf2 *Queue
+	parent, err = createManagedQueue(root, "parent", true, nil)
+	assert.NilError(t, err, "failed to create parent queue")
+	leaf1, err = createManagedQueue(parent, "leaf1", false, nil)
+	assert.NilError(t, err, "failed to create leaf1 queue")
+	leaf2, err = createManagedQueue(root, "leaf2", false, nil)
+	assert.NilError(t, err, "failed to create leaf2 queue")
+	assert.Equal(t, len(root.ClearAllAllocations()), 0, "new hierarchy should not have allocations")
+
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	allocated := make(map[string]bool)
+	addAlloc := func(app *Application, queue *Queue, uuid string) {
+		app.AddAllocation(newAllocation(app.ApplicationID, uuid, nodeID1, queue.QueuePath, res))
+		err = queue.IncAllocatedResource(res, false)
+		assert.NilError(t, err, "failed to increment allocated resource for %s", uuid)
+		allocated[uuid] = true
+	}
+	app1 := newApplication(appID1, "default", leaf1.QueuePath)
+	app1.queue = leaf1
+	err = leaf1.AddApplication(app1)
+	assert.NilError(t, err, "failed to add application to queue")
+	addAlloc(app1, leaf1, "uuid-1")
+	addAlloc(app1, leaf1, "uuid-2")
+	app2 := newApplication(appID2, "default", leaf2.QueuePath)
+	app2.queue = leaf2
+	err = leaf2.AddApplication(app2)
+	assert.NilError(t, err, "failed to add application to queue")
+	addAlloc(app2, leaf2, "uuid-3")
+	assert.Assert(t, resources.Equals(root.GetAllocatedResource(), resources.Multiply(res, 3)), "unexpected root usage")
+
+	// clearing a subtree updates the queues above it
+	released := parent.ClearAllAllocations()
+	assert.Equal(t, len(released), 2, "unexpected number of allocations released from parent")
+	assert.Assert(t, resources.IsZero(parent.GetAllocatedResource()), "parent usage not cleared")
+	assert.Assert(t, resources.IsZero(leaf1.GetAllocatedResource()), "leaf1 usage not cleared")
+	assert.Equal(t, len(app1.GetAllAllocations()), 0, "app-1 allocations not removed")
+	assert.Assert(t, resources.Equals(root.GetAllocatedResource(), res), "root usage not updated for the subtree")
+	assert.Equal(t, len(app2.GetAllAllocations()), 1, "app-2 outside the subtree should not have changed")
+
+	// clear the rest from the root
+	released = append(released, root.ClearAllAllocations()...)
+	assert.Equal(t, len(released), len(allocated), "unexpected number of allocations released")
+	for _, alloc := range released {
+		assert.Assert(t, allocated[alloc.UUID], "unexpected allocation released: %s", alloc.UUID)
+		delete(allocated, alloc.UUID)
+	}
+	for _, queue := range []*Queue{root, parent, leaf1, leaf2} {
+		assert.Assert(t, resources.IsZero(queue.GetAllocatedResource()), "usage not cleared for %s", queue.QueuePath)
+	}
+	assert.Equal(t, len(app2.GetAllAllocations()), 0, "app-2 allocations not removed")
+}
+
+func TestGetAllPendingAsks(t *testing.T) {
+	// queue structure:
+	// root
+	//   - parent
+	//     - leaf1
+	//   - leaf2
+	//   - empty
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "failed to create root queue")
+	var parent, leaf1, leaf2, empty *Queue
+	parent, err = createManagedQueue(root, "parent", true, nil)
+	assert.NilError(t, err, "failed to create parent queue")
+	leaf1, err = createManagedQueue(parent, "leaf1", false, nil)
+	assert.NilError(t, err, "failed to create leaf1 queue")
+	leaf2, err = createManagedQueue(root, "leaf2", false, nil)
+	assert.NilError(t, err, "failed to create leaf2 queue")
+	empty, err = createManagedQueue(root, "empty", false, nil)
+	assert.NilError(t, err, "failed to create empty queue")
+	assert.Equal(t, len(root.GetAllPendingAsks()), 0, "new hierarchy should not have pending asks")
+	assert.Equal(t, root.GetPendingAskCount(), 0, "new hierarchy should not have pending asks")
+
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	app1 := newApplication(appID1, "default", leaf1.QueuePath)
+	app1.queue = leaf1
+	err = leaf1.AddApplication(app1)
+	assert.NilError(t, err, "failed to add application to queue")
+	err = app1.AddAllocationAsk(newAllocationAskRepeat("alloc-1", appID1, res, 3))
+	assert.NilError(t, err, "failed to add allocation ask")
+	err = app1.AddAllocationAsk(newAllocationAsk("alloc-2", appID1, res))
+	assert.NilError(t, err, "failed to add allocation ask")
+	app2 := newApplication(appID2, "default", leaf2.QueuePath)
+	app2.queue = leaf2
+	err = leaf2.AddApplication(app2)
+	assert.NilError(t, err, "failed to add application to queue")
+	err = app2.AddAllocationAsk(newAllocationAsk("alloc-1", appID2, res))
+	assert.NilError(t, err, "failed to add allocation ask")
+
+	assert.Equal(t, len(root.GetAllPendingAsks()), 3, "unexpected pending asks for root")
+	assert.Equal(t, root.GetPendingAskCount(), 3, "unexpected pending ask count for root")
+	assert.Equal(t, parent.GetPendingAskCount(), 2, "unexpected pending ask count for parent")
+	assert.Equal(t, leaf2.GetPendingAskCount(), 1, "unexpected pending ask count for leaf2")
+	assert.Equal(t, len(empty.GetAllPendingAsks()), 0, "empty queue should not have pending asks")
+	assert.Equal(t, empty.GetPendingAskCount(), 0, "empty queue should not have pending asks")
+
+	// partially satisfied ask is still pending, fully satisfied ask is not
+	_, err = app1.updateAskRepeat("alloc-1", -2)
+	assert.NilError(t, err, "failed to update ask repeat")
+	_, err = app1.updateAskRepeat("alloc-2", -1)
+	assert.NilError(t, err, "failed to update ask repeat")
+	asks := parent.GetAllPendingAsks()
+	assert.Equal(t, len(asks), 1, "unexpected pending asks for parent")
+	assert.Equal(t, asks[0].AllocationKey, "alloc-1", "unexpected pending ask")
+	assert.Equal(t, parent.GetPendingAskCount(), 1, "unexpected pending ask count for parent")
+	assert.Equal(t, root.GetPendingAskCount(), 2, "unexpected pending ask count for root")
+}
+
+func TestGetApplicationPriorityRange(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "failed to create root queue")
+	var leaf *Queue
+	leaf, err = createManagedQueue(root, "leaf", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	min, max := root.GetApplicationPriorityRange()
+	assert.Assert(t, min == 0 && max == 0, "queue without pending asks should have an empty range: (%d, %d)", min, max)
+
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	apps := make([]*Application, 0)
+	for i, priority := range []int32{10, 50, 100} {
+		appID := "app-" + strconv.Itoa(i)
+		app := newApplication(appID, "default", leaf.QueuePath)
+		app.queue = leaf
+		err = leaf.AddApplication(app)
+		assert.NilError(t, err, "failed to add application to queue")
+		ask := newAllocationAsk("alloc-1", appID, res)
+		ask.setPriority(priority)
+		err = app.AddAllocationAsk(ask)
+		assert.NilError(t, err, "failed to add allocation ask")
+		apps = append(apps, app)
+	}
+	min, max = leaf.GetApplicationPriorityRange()
+	assert.Assert(t, min == 10 && max == 100, "unexpected range for leaf: (%d, %d)", min, max)
+	min, max = root.GetApplicationPriorityRange()
+	assert.Assert(t, min == 10 && max == 100, "unexpected range for root: (%d, %d)", min, max)
+
+	// remove the highest priority app
+	leaf.RemoveApplication(apps[2])
+	min, max = leaf.GetApplicationPriorityRange()
+	assert.Assert(t, min == 10 && max == 50, "unexpected range after removal: (%d, %d)", min, max)
+
+	// asks that are no longer pending are not part of the range
+	_, err = apps[0].updateAskRepeat("alloc-1", -1)
+	assert.NilError(t, err, "failed to update ask repeat")
+	min, max = leaf.GetApplicationPriorityRange()
+	assert.Assert(t, min == 50 && max == 50, "unexpected range after allocation: (%d, %d)", min, max)
+}
+
 func TestGetOutstandingRequestNoMax(t *testing.T) {
 	// queue structure:
 	// root
@@ -926,7 +1374,8 @@ func TestGetOutstandingRequestNoMax(t *testing.T) {
 
 	app1 := newApplication(appID1, "default", "root.queue1")
 	app1.queue = queue1
-	queue1.AddApplication(app1)
+	err = queue1.AddApplication(app1)
+	assert.NilError(t, err, "failed to add application to queue")
 	var res *resources.Resource
 	res, err = resources.NewResourceFromConf(map[string]string{"cpu": "1"})
 	assert.NilError(t, err, "failed to create basic resource")
@@ -938,7 +1387,8 @@ func TestGetOutstandingRequestNoMax(t *testing.T) {
 
 	app2 := newApplication(appID2, "default", "root.queue2")
 	app2.queue = queue2
-	queue2.AddApplication(app2)
+	err = queue2.AddApplication(app2)
+	assert.NilError(t, err, "failed to add application to queue")
 	for i := 0; i < 20; i++ {
 		err = app2.AddAllocationAsk(
 			newAllocationAsk(fmt.Sprintf("alloc-%d", i), appID2, res))
@@ -1029,7 +1479,8 @@ func TestQueueProps(t *testing.T) {
 	assert.NilError(t, err, "failed to create leaf queue")
 	assert.Equal(t, len(parent.children), 1, "leaf queue is not added to the parent queue")
 	assert.Assert(t, leaf.isLeaf && leaf.isManaged, "leaf queue is not marked as managed leaf")
-	assert.Equal(t, len(leaf.properties), 2, "leaf queue properties size incorrect")
+	assert.Equal(t, len(leaf.properties), 0, "leaf queue should not have properties of its own")
+	assert.Equal(t, len(leaf.GetAggregateProperties()), 2, "leaf queue properties size incorrect")
 
 	props = map[string]string{"first": "not inherited", configs.ApplicationSortPolicy: "stateaware"}
 	parent, err = createManagedQueueWithProps(root, "parent2", true, nil, props)
@@ -1040,6 +1491,82 @@ func TestQueueProps(t *testing.T) {
 	assert.Assert(t, leaf.isLeaf && !leaf.isManaged, "leaf queue is not marked as unmanaged leaf")
 	assert.Equal(t, len(leaf.properties), 1, "leaf queue properties size incorrect")
 	assert.Equal(t, leaf.properties[configs.ApplicationSortPolicy], "stateaware", "leaf queue property value not as expected")
+	assert.DeepEqual(t, leaf.GetAggregateProperties(), leaf.properties)
+}
+
+func TestGetAggregateProperties(t *testing.T) {
+	root, err := createManagedQueueWithProps(nil, "root", true, nil, map[string]string{"first": "root", "second": "root"})
+	assert.NilError(t, err, "failed to create root queue")
+	var parent, leaf *Queue
+	parent, err = createManagedQueueWithProps(root, "parent", true, nil, map[string]string{"second": "parent", "third": "parent"})
+	assert.NilError(t, err, "failed to create parent queue")
+	leaf, err = createManagedQueueWithProps(parent, "leaf", false, nil, map[string]string{"third": "leaf", configs.ApplicationSortPolicy: "fair"})
+	assert.NilError(t, err, "failed to create leaf queue")
+
+	assert.DeepEqual(t, root.GetAggregateProperties(), map[string]string{"first": "root", "second": "root"})
+	assert.DeepEqual(t, parent.GetAggregateProperties(), map[string]string{"first": "root", "second": "parent", "third": "parent"})
+	assert.DeepEqual(t, leaf.GetAggregateProperties(), map[string]string{"first": "root", "second": "parent", "third": "leaf", configs.ApplicationSortPolicy: "fair"})
+	// the returned map is a copy
+	props := leaf.GetAggregateProperties()
+	props["first"] = "changed"
+	assert.Equal(t, leaf.GetAggregateProperties()["first"], "root", "cached properties changed")
+
+	// the weight is not inherited
+	err = root.SetProperties(map[string]string{configs.QueueWeight: "2"})
+	assert.NilError(t, err, "root property update failed")
+	assert.Equal(t, root.GetAggregateProperties()[configs.QueueWeight], "2", "root weight not in its own aggregate")
+	_, ok := parent.GetAggregateProperties()[configs.QueueWeight]
+	assert.Assert(t, !ok, "parent should not inherit the weight")
+	err = parent.SetProperties(map[string]string{configs.QueueWeight: "3"})
+	assert.NilError(t, err, "parent property update failed")
+	assert.Equal(t, parent.GetWeight(), 3.0, "parent weight not updated")
+	_, ok = leaf.GetAggregateProperties()[configs.QueueWeight]
+	assert.Assert(t, !ok, "leaf should not inherit the weight")
+	assert.Equal(t, leaf.GetWeight(), 1.0, "leaf weight should not be inherited")
+
+	// a change on the root is picked up by the descendants, the derived settings are updated
+	err = root.SetQueueConfig(configs.QueueConfig{Name: "root", Parent: true,
+		Properties: map[string]string{"first": "root", "second": "root", configs.MaxConcurrentApplications: "5"}})
+	assert.NilError(t, err, "root config update failed")
+	assert.Equal(t, leaf.GetAggregateProperties()[configs.MaxConcurrentApplications], "5", "leaf did not inherit the update")
+	assert.Equal(t, leaf.GetMaxConcurrentApplications(), 5, "leaf limit not updated from the inherited property")
+
+	// a config update on the parent replaces its own properties
+	err = parent.SetQueueConfig(configs.QueueConfig{Name: "parent", Parent: true, Properties: map[string]string{"second": "updated"}})
+	assert.NilError(t, err, "parent config update failed")
+	assert.DeepEqual(t, leaf.GetAggregateProperties(), map[string]string{"first": "root", "second": "updated", "third": "leaf",
+		configs.ApplicationSortPolicy: "fair", configs.MaxConcurrentApplications: "5"})
+	assert.Equal(t, leaf.GetApplicationSortingPolicy(), policies.FairSortPolicy, "leaf sort policy changed")
+}
+
+// a merge running while the properties of a parent change must not leave a stale aggregate behind
+func TestGetAggregatePropertiesConcurrent(t *testing.T) {
+	root, err := createManagedQueueWithProps(nil, "root", true, nil, map[string]string{"first": "0"})
+	assert.NilError(t, err, "failed to create root queue")
+	var parent, leaf *Queue
+	parent, err = createManagedQueue(root, "parent", true, nil)
+	assert.NilError(t, err, "failed to create parent queue")
+	leaf, err = createManagedQueue(parent, "leaf", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 1; i <= 100; i++ {
+			err := root.SetQueueConfig(configs.QueueConfig{Name: "root", Parent: true,
+				Properties: map[string]string{"first": strconv.Itoa(i)}})
+			assert.NilError(t, err, "root config update failed")
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			leaf.GetAggregateProperties()
+		}
+	}()
+	wg.Wait()
+	assert.Equal(t, leaf.GetAggregateProperties()["first"], "100", "stale aggregate cached")
 }
 
 func TestMaxResource(t *testing.T) {
@@ -1056,18 +1583,54 @@ func TestMaxResource(t *testing.T) {
 	if root.GetMaxResource() != nil || parent.GetMaxResource() != nil {
 		t.Errorf("empty cluster should not have max set on root queue")
 	}
-	// try setting on the parent (nothing should change)
-	parent.SetMaxResource(res)
-	if parent.GetMaxResource() != nil {
-		t.Errorf("parent queue change should have been rejected parent: %v", parent.GetMaxResource())
-	}
 	// Set on the root should change
-	root.SetMaxResource(res)
-	if !resources.Equals(res, root.GetMaxResource()) {
+	err = root.SetMaxResource(res)
+	assert.NilError(t, err, "setting root max should not have failed")
+	if !resources.Equals(res, root.GetMaxResource()) || !resources.Equals(res, parent.GetMaxResource()) {
 		t.Errorf("root max setting not picked up by parent queue expected %v, got %v", res, parent.GetMaxResource())
 	}
 }
 
+func TestSetMaxResource(t *testing.T) {
+	root, err := createRootQueue(map[string]string{"first": "100"})
+	assert.NilError(t, err, "failed to create basic root queue")
+	var parent, leaf *Queue
+	parent, err = createManagedQueue(root, "parent", true, map[string]string{"first": "50"})
+	assert.NilError(t, err, "failed to create parent queue")
+	leaf, err = createManagedQueue(parent, "leaf", false, map[string]string{"first": "40"})
+	assert.NilError(t, err, "failed to create leaf queue")
+	err = leaf.IncAllocatedResource(resources.NewResourceFromMap(map[string]resources.Quantity{"first": 20}), false)
+	assert.NilError(t, err, "failed to increment allocated resource")
+
+	// larger than the parent max
+	err = parent.SetMaxResource(resources.NewResourceFromMap(map[string]resources.Quantity{"first": 200}))
+	assert.Assert(t, err != nil, "max larger than the parent should have failed")
+	// lower than the allocation
+	err = parent.SetMaxResource(resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10}))
+	assert.Assert(t, err != nil, "max lower than the allocation should have failed")
+	err = leaf.SetMaxResource(resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10}))
+	assert.Assert(t, err != nil, "max lower than the leaf allocation should have failed")
+	// missing resource types are a limit of 0 and larger than the parent
+	err = leaf.SetMaxResource(resources.NewResourceFromMap(map[string]resources.Quantity{"first": 30, "second": 1}))
+	assert.Assert(t, err != nil, "max with a type not in the parent should have failed")
+	assert.Assert(t, resources.Equals(parent.GetMaxResource(), resources.NewResourceFromMap(map[string]resources.Quantity{"first": 50})), "failed change should not have updated the parent")
+	assert.Assert(t, resources.Equals(leaf.GetMaxResource(), resources.NewResourceFromMap(map[string]resources.Quantity{"first": 40})), "failed change should not have updated the leaf")
+
+	// reduce the parent below the leaf max: leaf is reduced
+	err = parent.SetMaxResource(resources.NewResourceFromMap(map[string]resources.Quantity{"first": 30}))
+	assert.NilError(t, err, "valid max should not have failed")
+	assert.Assert(t, resources.Equals(parent.maxResource, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 30})), "parent max not set")
+	assert.Assert(t, resources.Equals(leaf.maxResource, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 30})), "leaf max not reduced")
+	// raise the parent: leaf is not changed
+	err = parent.SetMaxResource(resources.NewResourceFromMap(map[string]resources.Quantity{"first": 60}))
+	assert.NilError(t, err, "valid max should not have failed")
+	assert.Assert(t, resources.Equals(leaf.maxResource, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 30})), "leaf max should not have changed")
+	// remove the limit
+	err = leaf.SetMaxResource(resources.NewResource())
+	assert.NilError(t, err, "removing the max should not have failed")
+	assert.Assert(t, leaf.maxResource == nil, "leaf max not removed")
+}
+
 func TestGetQueueInfos(t *testing.T) {
 	root, err := createRootQueue(nil)
 	assert.NilError(t, err, "failed to create basic root queue: %v", err)
@@ -1154,6 +1717,133 @@ func TestGetQueueInfoPropertiesSet(t *testing.T) {
 	}
 }
 
+func TestGetQueueInfosChildOrder(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "failed to create basic root queue: %v", err)
+	var parent *Queue
+	for _, name := range []string{"z-queue", "a-queue", "m-queue"} {
+		parent, err = createManagedQueue(root, name, true, nil)
+		assert.NilError(t, err, "failed to create queue %s: %v", name, err)
+	}
+	// the order is also stable below the first level
+	for _, name := range []string{"z-child", "a-child", "m-child"} {
+		_, err = createManagedQueue(parent, name, false, nil)
+		assert.NilError(t, err, "failed to create queue %s: %v", name, err)
+	}
+	// map iteration order is random: check more than once
+	for i := 0; i < 10; i++ {
+		rootDaoInfo := root.GetQueueInfos()
+		assert.DeepEqual(t, queueInfoNames(rootDaoInfo.ChildQueues), []string{"a-queue", "m-queue", "z-queue"})
+		assert.DeepEqual(t, queueInfoNames(rootDaoInfo.ChildQueues[1].ChildQueues), []string{"a-child", "m-child", "z-child"})
+	}
+}
+
+func TestGetQueueInfosFiltered(t *testing.T) {
+	maxRes := map[string]string{"first": "10"}
+	root, err := createRootQueue(maxRes)
+	assert.NilError(t, err, "failed to create basic root queue")
+	var parent, leaf1, leaf2, leaf3 *Queue
+	parent, err = createManagedQueue(root, "parent", true, maxRes)
+	assert.NilError(t, err, "failed to create parent queue")
+	leaf1, err = createManagedQueue(parent, "leaf1", false, maxRes)
+	assert.NilError(t, err, "failed to create leaf1 queue")
+	leaf2, err = createManagedQueue(parent, "leaf2", false, maxRes)
+	assert.NilError(t, err, "failed to create leaf2 queue")
+	leaf3, err = createManagedQueue(parent, "leaf3", false, nil)
+	assert.NilError(t, err, "failed to create leaf3 queue")
+	// leaf1 is half loaded (as are parent and root), leaf2 has an app but no load, leaf3 is draining
+	err = leaf1.IncAllocatedResource(resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5}), false)
+	assert.NilError(t, err, "failed to increment allocated resource")
+	err = leaf2.AddApplication(newApplication(appID1, "default", "root.parent.leaf2"))
+	assert.NilError(t, err, "failed to add application to queue")
+	leaf3.MarkQueueForRemoval()
+
+	filtered := func(filter dao.QueueFilter) []string {
+		rootInfo := root.GetQueueInfosFiltered(filter)
+		assert.Equal(t, rootInfo.QueueName, "root", "root should always be returned")
+		if len(rootInfo.ChildQueues) == 0 {
+			return nil
+		}
+		assert.Equal(t, len(rootInfo.ChildQueues), 1, "unexpected number of children for root")
+		return queueInfoNames(rootInfo.ChildQueues[0].ChildQueues)
+	}
+	minLoad, maxLoad, noLoad := 0.4, 0.6, 0.1
+	assert.DeepEqual(t, filtered(dao.QueueFilter{}), []string{"leaf1", "leaf2", "leaf3"})
+	assert.DeepEqual(t, filtered(dao.QueueFilter{State: "draining"}), []string{"leaf3"})
+	assert.DeepEqual(t, filtered(dao.QueueFilter{MinLoad: &minLoad, MaxLoad: &maxLoad}), []string{"leaf1"})
+	assert.DeepEqual(t, filtered(dao.QueueFilter{HasApps: true}), []string{"leaf2"})
+	// leaf3 cannot calculate a load and reports -1
+	assert.DeepEqual(t, filtered(dao.QueueFilter{MaxLoad: &noLoad, State: "Active"}), []string{"leaf2"})
+	assert.Assert(t, filtered(dao.QueueFilter{State: "Stopped"}) == nil, "no queue should match the stopped state")
+
+	// a matching parent is returned without the children that do not match
+	rootInfo := root.GetQueueInfosFiltered(dao.QueueFilter{MinLoad: &minLoad})
+	assert.DeepEqual(t, queueInfoNames(rootInfo.ChildQueues), []string{"parent"})
+	assert.DeepEqual(t, queueInfoNames(rootInfo.ChildQueues[0].ChildQueues), []string{"leaf1"})
+	assert.Equal(t, rootInfo.ChildQueues[0].Load, 0.5, "unexpected parent load")
+}
+
+func queueInfoNames(infos []dao.QueueDAOInfo) []string {
+	names := make([]string, len(infos))
+	for i, info := range infos {
+		names[i] = info.QueueName
+	}
+	return names
+}
+
+func TestExportConfig(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "failed to create basic root queue: %v", err)
+	var rootMax *resources.Resource
+	rootMax, err = resources.NewResourceFromConf(map[string]string{"memory": "2048", "vcores": "10"})
+	assert.NilError(t, err, "failed to create configuration: %v", err)
+	root.SetMaxResource(rootMax)
+	conf := configs.QueueConfig{
+		Name:      "parent",
+		Parent:    true,
+		SubmitACL: "user1 group1",
+		AdminACL:  "admin",
+		Resources: configs.Resources{
+			Max:        map[string]string{"memory": "1024"},
+			Guaranteed: map[string]string{"memory": "512"},
+		},
+		Properties: map[string]string{"key": "value"},
+	}
+	var parent *Queue
+	parent, err = NewConfiguredQueue(conf, root)
+	assert.NilError(t, err, "failed to create queue: %v", err)
+	_, err = createManagedQueue(parent, "leaf-b", false, map[string]string{"memory": "100"})
+	assert.NilError(t, err, "failed to create queue: %v", err)
+	_, err = createManagedQueue(parent, "leaf-a", false, nil)
+	assert.NilError(t, err, "failed to create queue: %v", err)
+	// unmanaged and draining queues are not exported
+	_, err = createDynamicQueue(parent, "dynamic", false)
+	assert.NilError(t, err, "failed to create queue: %v", err)
+	var removed *Queue
+	removed, err = createManagedQueue(parent, "removed", false, nil)
+	assert.NilError(t, err, "failed to create queue: %v", err)
+	removed.MarkQueueForRemoval()
+
+	rootConf := root.ExportConfig()
+	assert.Equal(t, rootConf.Name, "root", "root queue name not exported")
+	assert.Assert(t, rootConf.Parent, "root should be exported as a parent")
+	assert.Equal(t, len(rootConf.Resources.Max), 0, "root max resource should not be exported")
+	assert.Equal(t, len(rootConf.Queues), 1, "root should have one child")
+	parentConf := rootConf.Queues[0]
+	assert.Equal(t, parentConf.Name, "parent", "parent queue name not exported")
+	assert.Assert(t, parentConf.Parent, "parent should be exported as a parent")
+	assert.Equal(t, parentConf.SubmitACL, "user1 group1", "submit ACL not exported")
+	assert.Equal(t, parentConf.AdminACL, "admin", "admin ACL not exported")
+	assert.DeepEqual(t, parentConf.Resources.Max, map[string]string{"memory": "1024"})
+	assert.DeepEqual(t, parentConf.Resources.Guaranteed, map[string]string{"memory": "512"})
+	assert.DeepEqual(t, parentConf.Properties, map[string]string{"key": "value"})
+	assert.Equal(t, len(parentConf.Queues), 2, "only managed queues should have been exported")
+	assert.Equal(t, parentConf.Queues[0].Name, "leaf-a", "children not exported in order")
+	assert.Equal(t, parentConf.Queues[1].Name, "leaf-b", "children not exported in order")
+	assert.Assert(t, !parentConf.Queues[1].Parent, "leaf should not be exported as a parent")
+	assert.DeepEqual(t, parentConf.Queues[1].Resources.Max, map[string]string{"memory": "100"})
+}
+
 func compareQueueInfoWithDAO(t *testing.T, queue *Queue, dao dao.QueueDAOInfo) {
 	assert.Equal(t, queue.Name, dao.QueueName)
 	assert.Equal(t, len(queue.children), len(dao.ChildQueues))
@@ -1181,3 +1871,115 @@ func compareQueueInfoWithDAO(t *testing.T, queue *Queue, dao dao.QueueDAOInfo) {
 		}
 	}
 }
+
+func TestGetLargestSmallestPendingAsk(t *testing.T) {
+	root, err := createRootQueue(map[string]string{"memory": "100", "vcore": "10"})
+	assert.NilError(t, err, "failed to create root queue")
+	var parent, leaf1, leaf2 *Queue
+	parent, err = createManagedQueue(root, "parent", true, nil)
+	assert.NilError(t, err, "failed to create parent queue")
+	leaf1, err = createManagedQueue(parent, "leaf1", false, nil)
+	assert.NilError(t, err, "failed to create leaf1 queue")
+	leaf2, err = createManagedQueue(parent, "leaf2", false, nil)
+	assert.NilError(t, err, "failed to create leaf2 queue")
+
+	// no pending asks
+	assert.Assert(t, root.GetLargestPendingAsk() == nil, "empty queue should not have a largest ask")
+	assert.Assert(t, root.GetSmallestPendingAsk() == nil, "empty queue should not have a smallest ask")
+
+	app1 := newApplication(appID1, "default", "root.parent.leaf1")
+	app1.queue = leaf1
+	err = leaf1.AddApplication(app1)
+	assert.NilError(t, err, "failed to add application to queue")
+	memLarge := newAllocationAsk("mem-large", appID1, resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 50, "vcore": 1}))
+	err = app1.AddAllocationAsk(memLarge)
+	assert.NilError(t, err, "failed to add ask")
+	small := newAllocationAsk("small", appID1, resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10, "vcore": 1}))
+	err = app1.AddAllocationAsk(small)
+	assert.NilError(t, err, "failed to add ask")
+
+	// multi resource: the vcore share is the dominant share for this ask
+	app2 := newApplication(appID2, "default", "root.parent.leaf2")
+	app2.queue = leaf2
+	err = leaf2.AddApplication(app2)
+	assert.NilError(t, err, "failed to add application to queue")
+	vcoreLarge := newAllocationAsk("vcore-large", appID2, resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10, "vcore": 8}))
+	err = app2.AddAllocationAsk(vcoreLarge)
+	assert.NilError(t, err, "failed to add ask")
+
+	assert.Equal(t, root.GetLargestPendingAsk(), vcoreLarge, "unexpected largest ask for root")
+	assert.Equal(t, root.GetSmallestPendingAsk(), small, "unexpected smallest ask for root")
+	assert.Equal(t, leaf1.GetLargestPendingAsk(), memLarge, "unexpected largest ask for leaf1")
+	assert.Equal(t, leaf1.GetSmallestPendingAsk(), small, "unexpected smallest ask for leaf1")
+	assert.Equal(t, leaf2.GetLargestPendingAsk(), vcoreLarge, "unexpected largest ask for leaf2")
+	assert.Equal(t, leaf2.GetSmallestPendingAsk(), vcoreLarge, "unexpected smallest ask for leaf2")
+
+	// removing the ask from the app removes it from the queue
+	app2.RemoveAllocationAsk("vcore-large")
+	assert.Equal(t, root.GetLargestPendingAsk(), memLarge, "unexpected largest ask for root after removal")
+	assert.Assert(t, leaf2.GetLargestPendingAsk() == nil, "leaf2 should not have pending asks")
+}
+
+// create a queue hierarchy with the given number of levels below root and return the deepest queue
+func createQueueHierarchy(b *testing.B, levels int) *Queue {
+	queue, err := createRootQueue(nil)
+	assert.NilError(b, err, "failed to create root queue")
+	for i := 1; i <= levels; i++ {
+		queue, err = createManagedQueue(queue, "level"+strconv.Itoa(i), i != levels, nil)
+		assert.NilError(b, err, "failed to create queue")
+	}
+	return queue
+}
+
+// walk the parent chain to build the path: the behaviour without a cached path
+func walkQueuePath(sq *Queue) string {
+	if sq.parent == nil {
+		return sq.Name
+	}
+	return walkQueuePath(sq.parent) + configs.DOT + sq.Name
+}
+
+func BenchmarkGetQueuePath(b *testing.B) {
+	leaf := createQueueHierarchy(b, 6)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		leaf.GetQueuePath()
+	}
+}
+
+func BenchmarkWalkQueuePath(b *testing.B) {
+	leaf := createQueueHierarchy(b, 6)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		walkQueuePath(leaf)
+	}
+}
+
+func TestGetQueueLoad(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "failed to create root queue")
+	var parent, leaf *Queue
+	parent, err = createManagedQueue(root, "parent", true, map[string]string{"first": "10", "second": "20"})
+	assert.NilError(t, err, "failed to create parent queue")
+	leaf, err = createManagedQueue(parent, "leaf", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	assert.Equal(t, root.GetQueueLoad(), -1.0, "queue without max or guaranteed should not have a load")
+	assert.Equal(t, root.GetQueueLoadByResource("first"), -1.0, "queue without max or guaranteed should not have a load")
+
+	// leaf falls back to the max of the parent
+	assert.Equal(t, leaf.GetQueueLoad(), 0.0, "empty queue should have no load")
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5, "second": 5})
+	err = leaf.IncAllocatedResource(res, false)
+	assert.NilError(t, err, "failed to increase allocated resource")
+	assert.Equal(t, leaf.GetQueueLoad(), 0.5, "half full queue should have a load of 0.5")
+	assert.Equal(t, leaf.GetQueueLoadByResource("second"), 0.25, "unexpected load for resource type")
+	assert.Equal(t, leaf.GetQueueLoadByResource("unknown"), -1.0, "unknown resource type should not have a load")
+	assert.Equal(t, parent.GetQueueLoad(), 0.5, "half full parent should have a load of 0.5")
+	err = leaf.IncAllocatedResource(res, false)
+	assert.NilError(t, err, "failed to increase allocated resource")
+	assert.Equal(t, leaf.GetQueueLoad(), 1.0, "full queue should have a load of 1.0")
+
+	// guaranteed is used before max
+	leaf.guaranteedResource = resources.NewResourceFromMap(map[string]resources.Quantity{"first": 20})
+	assert.Equal(t, leaf.GetQueueLoad(), 0.5, "load should be based on the guaranteed resource")
+}
@@ -19,15 +19,18 @@
 package objects
 
 import (
+	"time"
+
 	"go.uber.org/zap"
 
 	"github.com/apache/incubator-yunikorn-core/pkg/log"
 )
 
 type reservation struct {
-	nodeID string
-	appID  string
-	askKey string
+	nodeID     string
+	appID      string
+	askKey     string
+	reservedAt time.Time // the time the reservation was made
 	// these references must ONLY be used for ask, node and application removal otherwise
 	// the reservations cannot be removed and scheduling might be impacted.
 	app  *Application
@@ -35,6 +38,14 @@ type reservation struct {
 	ask  *AllocationAsk
 }
 
+// A reservation made for a member of a gang, see Queue.TryReserveForGang.
+// Only identifies the reservation, the reservation itself is tracked on the application and the node.
+type Reservation struct {
+	NodeID        string
+	ApplicationID string
+	AllocationKey string
+}
+
 // The reservation inside the scheduler. A reservation object is never mutated and does not use locking.
 // The key depends on where the reservation was made (node or app).
 // appBased must be true for a reservation for an app and false for a reservation on a node
@@ -47,10 +58,11 @@ func newReservation(node *Node, app *Application, ask *AllocationAsk, appBased b
 		return nil
 	}
 	res := &reservation{
-		askKey: ask.AllocationKey,
-		ask:    ask,
-		app:    app,
-		node:   node,
+		askKey:     ask.AllocationKey,
+		reservedAt: time.Now(),
+		ask:        ask,
+		app:        app,
+		node:       node,
 	}
 	if appBased {
 		res.nodeID = node.NodeID
@@ -82,6 +94,11 @@ func (r *reservation) getKey() string {
 	return r.nodeID + "|" + r.askKey
 }
 
+// Return how long the reservation has existed at the time passed in
+func (r *reservation) getAge(now time.Time) time.Duration {
+	return now.Sub(r.reservedAt)
+}
+
 func (r *reservation) String() string {
 	if r.nodeID == "" {
 		return r.node.NodeID + " -> " + r.appID + "|" + r.askKey
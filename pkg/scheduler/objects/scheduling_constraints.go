@@ -0,0 +1,122 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objects
+
+import (
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
+)
+
+// Constraints on the nodes the allocations of an application can be placed on.
+// The labels are matched against the node attributes.
+type SchedulingConstraints struct {
+	RequiredLabels        map[string]string // node attributes that must be set to the value
+	ForbiddenLabels       map[string]string // node attributes that must not be set to the value
+	RequiredNodeCount     int               // minimum number of nodes that pass the labels before any node is used, 0 means no minimum
+	MaxAllocationsPerNode int               // maximum number of allocations of the application on a node, 0 means no maximum
+}
+
+// Return a deep copy of the constraints.
+func (sc SchedulingConstraints) clone() SchedulingConstraints {
+	cloned := sc
+	if sc.RequiredLabels != nil {
+		cloned.RequiredLabels = make(map[string]string, len(sc.RequiredLabels))
+		for key, value := range sc.RequiredLabels {
+			cloned.RequiredLabels[key] = value
+		}
+	}
+	if sc.ForbiddenLabels != nil {
+		cloned.ForbiddenLabels = make(map[string]string, len(sc.ForbiddenLabels))
+		for key, value := range sc.ForbiddenLabels {
+			cloned.ForbiddenLabels[key] = value
+		}
+	}
+	return cloned
+}
+
+// Return true if no constraint is set.
+func (sc SchedulingConstraints) IsEmpty() bool {
+	return len(sc.RequiredLabels) == 0 && len(sc.ForbiddenLabels) == 0 && sc.RequiredNodeCount <= 0 && sc.MaxAllocationsPerNode <= 0
+}
+
+// Return true if the node has all required labels and none of the forbidden labels.
+func (sc SchedulingConstraints) MatchesLabels(node *Node) bool {
+	for key, value := range sc.RequiredLabels {
+		if node.GetAttribute(key) != value {
+			return false
+		}
+	}
+	for key, value := range sc.ForbiddenLabels {
+		if node.GetAttribute(key) == value {
+			return false
+		}
+	}
+	return true
+}
+
+// Return true if an allocation of the application can be placed on the node: the node matches the labels and
+// has less than the maximum number of allocations of the application.
+func (sc SchedulingConstraints) AllowsNode(node *Node, appID string) bool {
+	if !sc.MatchesLabels(node) {
+		return false
+	}
+	return sc.MaxAllocationsPerNode <= 0 || node.GetApplicationAllocationCount(appID) < sc.MaxAllocationsPerNode
+}
+
+// Get the scheduling constraints from the application tags. Returns nil if no constraint is set.
+// Labels are set as a comma separated list of key=value pairs, invalid pairs are logged and ignored.
+func getConstraintsFromTags(appID string, tags map[string]string) *SchedulingConstraints {
+	constraints := SchedulingConstraints{
+		RequiredLabels:        getLabelsFromTag(appID, tags, appTagRequiredLabels),
+		ForbiddenLabels:       getLabelsFromTag(appID, tags, appTagForbiddenLabels),
+		RequiredNodeCount:     getLimitFromTag(appID, tags, appTagRequiredNodeCount),
+		MaxAllocationsPerNode: getLimitFromTag(appID, tags, appTagMaxAllocationsPerNode),
+	}
+	if constraints.IsEmpty() {
+		return nil
+	}
+	return &constraints
+}
+
+// Get the labels from the application tag. Returns nil if the tag is not set or has no valid labels.
+func getLabelsFromTag(appID string, tags map[string]string, key string) map[string]string {
+	value, ok := tags[key]
+	if !ok {
+		return nil
+	}
+	var labels map[string]string
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			log.Logger().Warn("ignoring invalid application label",
+				zap.String("appID", appID),
+				zap.String("tag", key),
+				zap.String("label", pair))
+			continue
+		}
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		labels[parts[0]] = parts[1]
+	}
+	return labels
+}
@@ -125,39 +125,84 @@ func stateAwareFilter(apps map[string]*Application) []*Application {
 	return filteredApps
 }
 
-func SortNodes(nodes []*Node, sortType policies.SortingPolicy) {
+// Sort the nodes based on the policy passed in, the node with the highest score for the ask comes first.
+// The score for each node is calculated once before sorting as it requires locking the node.
+// The ask may be nil, nodes are then sorted on their current state.
+func SortNodes(nodes []*Node, policy *policies.NodeSortingPolicy, ask *AllocationAsk) {
 	sortingStart := time.Now()
-	switch sortType {
-	case policies.FairnessPolicy:
-		// Sort by available resource, descending order
-		sort.SliceStable(nodes, func(i, j int) bool {
-			l := nodes[i]
-			r := nodes[j]
-			return resources.CompUsageShares(l.GetAvailableResource(), r.GetAvailableResource()) > 0
-		})
-	case policies.BinPackingPolicy:
-		// Sort by available resource, ascending order
-		sort.SliceStable(nodes, func(i, j int) bool {
-			l := nodes[i]
-			r := nodes[j]
-			return resources.CompUsageShares(r.GetAvailableResource(), l.GetAvailableResource()) > 0
-		})
+	var askRes *resources.Resource
+	if ask != nil {
+		askRes = ask.AllocatedResource
+	}
+	if composite := policy.GetCompositePolicy(); composite != nil {
+		sortNodesComposite(nodes, composite, askRes)
+		metrics.GetSchedulerMetrics().ObserveNodeSortingLatency(sortingStart)
+		return
+	}
+	weights := policy.GetResourceWeights()
+	scores := make(map[string]float64, len(nodes))
+	for _, node := range nodes {
+		scores[node.NodeID] = node.score(askRes, policy.PolicyType, weights)
 	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return scores[nodes[i].NodeID] > scores[nodes[j].NodeID]
+	})
 	metrics.GetSchedulerMetrics().ObserveNodeSortingLatency(sortingStart)
 }
 
+// Sort the nodes on the scores of all chained policies of the composite policy, compared lexicographically.
+func sortNodesComposite(nodes []*Node, composite *policies.CompositeNodeSortingPolicy, askRes *resources.Resource) {
+	scores := make(map[string][]float64, len(nodes))
+	for _, policy := range composite.Policies {
+		weights := policy.GetResourceWeights()
+		if groupKey := policy.GetGroupKey(); groupKey != "" {
+			groupScores := scoreNodeGroups(nodes, groupKey, askRes, policy.PolicyType, weights)
+			for _, node := range nodes {
+				scores[node.NodeID] = append(scores[node.NodeID], groupScores[node.GetAttribute(groupKey)])
+			}
+			continue
+		}
+		for _, node := range nodes {
+			scores[node.NodeID] = append(scores[node.NodeID], node.score(askRes, policy.PolicyType, weights))
+		}
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return composite.Less(scores[nodes[i].NodeID], scores[nodes[j].NodeID])
+	})
+}
+
+// Score each group of nodes that share the value of the group key attribute as if it was one node.
+func scoreNodeGroups(nodes []*Node, groupKey string, askRes *resources.Resource, policyType policies.SortingPolicy, weights map[string]float64) map[string]float64 {
+	totals := make(map[string]*resources.Resource)
+	available := make(map[string]*resources.Resource)
+	for _, node := range nodes {
+		group := node.GetAttribute(groupKey)
+		total, avail := node.getScoreResources()
+		totals[group] = resources.Add(totals[group], total)
+		available[group] = resources.Add(available[group], avail)
+	}
+	scores := make(map[string]float64, len(totals))
+	for group, total := range totals {
+		scores[group] = scoreResources(total, available[group], askRes, policyType, weights)
+	}
+	return scores
+}
+
+// Sort the asks on the effective priority, which includes the offset of the priority class of the application.
 func sortAskByPriority(requests []*AllocationAsk, ascending bool) {
 	sort.SliceStable(requests, func(i, j int) bool {
 		l := requests[i]
 		r := requests[j]
+		lPriority := l.GetEffectivePriority()
+		rPriority := r.GetEffectivePriority()
 
-		if l.priority == r.priority {
+		if lPriority == rPriority {
 			return l.createTime.Before(r.createTime)
 		}
 
 		if ascending {
-			return l.priority < r.priority
+			return lPriority < rPriority
 		}
-		return l.priority > r.priority
+		return lPriority > rPriority
 	})
 }
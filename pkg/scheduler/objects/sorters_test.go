@@ -102,86 +102,163 @@ func TestNoQueueLimits(t *testing.T) {
 }
 
 func TestSortNodesBin(t *testing.T) {
+	binPacking := policies.NewNodeSortingPolicy("binpacking", nil)
 	// nil or empty list cannot panic
-	SortNodes(nil, policies.BinPackingPolicy)
+	SortNodes(nil, binPacking, nil)
 	list := make([]*Node, 0)
-	SortNodes(list, policies.BinPackingPolicy)
+	SortNodes(list, binPacking, nil)
 	list = append(list, newNode("node-nil", nil))
-	SortNodes(list, policies.BinPackingPolicy)
+	SortNodes(list, binPacking, nil)
 
-	// stable sort is used so equal resources stay where they were
-	res := resources.NewResourceFromMap(map[string]resources.Quantity{
-		"first": resources.Quantity(100)})
+	// stable sort is used so equal utilisation stays where it was
+	total := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 100})
+	used := func(value resources.Quantity) *resources.Resource {
+		return resources.NewResourceFromMap(map[string]resources.Quantity{"first": value})
+	}
 
-	// setup to sort ascending
+	// setup to sort descending on utilisation
 	list = make([]*Node, 3)
 	for i := 0; i < 3; i++ {
 		num := strconv.Itoa(i)
-		node := newNodeRes("node-"+num, resources.Multiply(res, int64(3-i)))
-		list[i] = node
+		list[i] = newNodeInternal("node-"+num, total, used(resources.Quantity(10+40*i)))
 	}
-	// nodes should come back in order 2 (100), 1 (200), 0 (300)
-	SortNodes(list, policies.BinPackingPolicy)
+	// nodes should come back in order 2 (90), 1 (50), 0 (10)
+	SortNodes(list, binPacking, nil)
 	assertNodeList(t, list, []int{2, 1, 0}, "bin base order")
 
-	// change node-1 on place 1 in the slice to have no res
-	list[1] = newNodeRes("node-1", resources.Multiply(res, 0))
-	// nodes should come back in order 1 (0), 2 (100), 0 (300)
-	SortNodes(list, policies.BinPackingPolicy)
-	assertNodeList(t, list, []int{2, 0, 1}, "bin no res node-1")
-
-	// change node-1 on place 0 in the slice to have 300 res
-	list[0] = newNodeRes("node-1", resources.Multiply(res, 3))
-	// nodes should come back in order 2 (100), 1 (300), 0 (300)
-	SortNodes(list, policies.BinPackingPolicy)
-	assertNodeList(t, list, []int{2, 1, 0}, "bin node-1 same as node-0")
-
-	// change node-0 on place 2 in the slice to have -300 res
-	list[2] = newNodeRes("node-0", resources.Multiply(res, -3))
-	// nodes should come back in order 0 (-300), 2 (100), 1 (300)
-	SortNodes(list, policies.BinPackingPolicy)
-	assertNodeList(t, list, []int{0, 2, 1}, "bin node-0 negative")
+	// change node-1 on place 1 in the slice to have nothing used
+	list[1] = newNodeInternal("node-1", total, used(0))
+	// nodes should come back in order 2 (90), 0 (10), 1 (0)
+	SortNodes(list, binPacking, nil)
+	assertNodeList(t, list, []int{1, 2, 0}, "bin empty node-1")
+
+	// change node-1 on place 2 in the slice to have 90 used
+	list[2] = newNodeInternal("node-1", total, used(90))
+	// nodes should come back in order 2 (90), 1 (90), 0 (10)
+	SortNodes(list, binPacking, nil)
+	assertNodeList(t, list, []int{2, 1, 0}, "bin node-1 same as node-2")
+
+	// change node-0 on place 2 in the slice to be over committed
+	list[2] = newNodeInternal("node-0", total, used(150))
+	// nodes should come back in order 0 (150), 2 (90), 1 (90)
+	SortNodes(list, binPacking, nil)
+	assertNodeList(t, list, []int{0, 2, 1}, "bin node-0 over committed")
 }
 
 func TestSortNodesFair(t *testing.T) {
+	fair := policies.NewNodeSortingPolicy("fair", nil)
 	// nil or empty list cannot panic
-	SortNodes(nil, policies.FairnessPolicy)
+	SortNodes(nil, fair, nil)
 	list := make([]*Node, 0)
-	SortNodes(list, policies.FairnessPolicy)
+	SortNodes(list, fair, nil)
 	list = append(list, newNode("node-nil", nil))
-	SortNodes(list, policies.FairnessPolicy)
+	SortNodes(list, fair, nil)
 
-	// stable sort is used so equal resources stay where they were
-	res := resources.NewResourceFromMap(map[string]resources.Quantity{
-		"first": resources.Quantity(100)})
-	// setup to sort descending
+	// stable sort is used so equal utilisation stays where it was
+	total := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 100})
+	used := func(value resources.Quantity) *resources.Resource {
+		return resources.NewResourceFromMap(map[string]resources.Quantity{"first": value})
+	}
+	// setup to sort ascending on utilisation
 	list = make([]*Node, 3)
 	for i := 0; i < 3; i++ {
 		num := strconv.Itoa(i)
-		node := newNodeRes("node-"+num, resources.Multiply(res, int64(1+i)))
-		list[i] = node
+		list[i] = newNodeInternal("node-"+num, total, used(resources.Quantity(90-40*i)))
 	}
-	// nodes should come back in order 2 (300), 1 (200), 0 (100)
-	SortNodes(list, policies.FairnessPolicy)
+	// nodes should come back in order 2 (10), 1 (50), 0 (90)
+	SortNodes(list, fair, nil)
 	assertNodeList(t, list, []int{2, 1, 0}, "fair base order")
 
-	// change node-1 on place 1 in the slice to have no res
-	list[1] = newNodeRes("node-1", resources.Multiply(res, 0))
-	// nodes should come back in order 2 (300), 0 (100), 1 (0)
-	SortNodes(list, policies.FairnessPolicy)
-	assertNodeList(t, list, []int{1, 2, 0}, "fair no res node-1")
+	// change node-1 on place 1 in the slice to be full
+	list[1] = newNodeInternal("node-1", total, used(100))
+	// nodes should come back in order 2 (10), 0 (90), 1 (100)
+	SortNodes(list, fair, nil)
+	assertNodeList(t, list, []int{1, 2, 0}, "fair full node-1")
 
-	// change node-1 on place 2 in the slice to have 300 res
-	list[2] = newNodeRes("node-1", resources.Multiply(res, 3))
-	// nodes should come back in order 2 (300), 1 (300), 0 (100)
-	SortNodes(list, policies.FairnessPolicy)
+	// change node-1 on place 2 in the slice to have 10 used
+	list[2] = newNodeInternal("node-1", total, used(10))
+	// nodes should come back in order 2 (10), 1 (10), 0 (90)
+	SortNodes(list, fair, nil)
 	assertNodeList(t, list, []int{2, 1, 0}, "fair node-1 same as node-2")
 
-	// change node-2 on place 0 in the slice to have -300 res
-	list[0] = newNodeRes("node-2", resources.Multiply(res, -3))
-	// nodes should come back in order 1 (300), 0 (100), 2 (-300)
-	SortNodes(list, policies.FairnessPolicy)
-	assertNodeList(t, list, []int{1, 0, 2}, "fair node-2 negative")
+	// change node-2 on place 0 in the slice to be over committed
+	list[0] = newNodeInternal("node-2", total, used(150))
+	// nodes should come back in order 1 (10), 0 (90), 2 (150)
+	SortNodes(list, fair, nil)
+	assertNodeList(t, list, []int{1, 0, 2}, "fair node-2 over committed")
+}
+
+func TestSortNodesBestFit(t *testing.T) {
+	bestFit := policies.NewNodeSortingPolicy("bestfit", nil)
+	total := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 100, "vcore": 100})
+	list := make([]*Node, 3)
+	// node-0 leaves the most unused, node-1 is the tightest fit, node-2 cannot fit the ask
+	list[0] = newNodeInternal("node-0", total, resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10, "vcore": 10}))
+	list[1] = newNodeInternal("node-1", total, resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 60, "vcore": 50}))
+	list[2] = newNodeInternal("node-2", total, resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 90, "vcore": 10}))
+	ask := newAllocationAsk(aKey, appID1, resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 40, "vcore": 40}))
+	SortNodes(list, bestFit, ask)
+	assertNodeList(t, list, []int{1, 0, 2}, "best fit order")
+}
+
+func TestSortNodesWeighted(t *testing.T) {
+	// node-0 has mostly memory allocated, node-1 has mostly vcore allocated
+	total := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 100, "vcore": 100})
+	list := make([]*Node, 2)
+	list[0] = newNodeInternal("node-0", total, resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 80, "vcore": 10}))
+	list[1] = newNodeInternal("node-1", total, resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10, "vcore": 70}))
+
+	// memory counts most: node with the least memory available comes first
+	SortNodes(list, policies.NewNodeSortingPolicy("binpacking", map[string]string{"binpacking.memoryWeight": "10"}), nil)
+	assert.Equal(t, list[0].NodeID, "node-0", "bin memory weighted")
+	// memory counts least: node with the least vcore available comes first
+	SortNodes(list, policies.NewNodeSortingPolicy("binpacking", map[string]string{"binpacking.memoryWeight": "0.1"}), nil)
+	assert.Equal(t, list[0].NodeID, "node-1", "bin vcore weighted")
+
+	// fair sorts descending: node with the most memory available comes first
+	SortNodes(list, policies.NewNodeSortingPolicy("fair", map[string]string{"fair.memoryWeight": "10"}), nil)
+	assert.Equal(t, list[0].NodeID, "node-1", "fair memory weighted")
+	SortNodes(list, policies.NewNodeSortingPolicy("fair", map[string]string{"fair.memoryWeight": "0", "fair.vcoreWeight": "1"}), nil)
+	assert.Equal(t, list[0].NodeID, "node-0", "fair vcore weighted")
+}
+
+func TestSortNodesComposite(t *testing.T) {
+	composite := policies.NewNodeSortingPolicy("composite", map[string]string{"composite.policies": "fair@zone,binpacking"})
+	total := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 100})
+	newZoneNode := func(nodeID, zone string, memory resources.Quantity) *Node {
+		node := newNodeInternal(nodeID, total, resources.NewResourceFromMap(map[string]resources.Quantity{"memory": memory}))
+		node.UpdateAttributes(map[string]string{"zone": zone})
+		return node
+	}
+	assertOrder := func(list []*Node, want []string, name string) {
+		got := make([]string, len(list))
+		for i, node := range list {
+			got[i] = node.NodeID
+		}
+		assert.DeepEqual(t, got, want)
+	}
+	// zone-a is 30% used, zone-b is 10% used
+	list := []*Node{
+		newZoneNode("node-0", "zone-a", 10),
+		newZoneNode("node-1", "zone-a", 50),
+		newZoneNode("node-2", "zone-b", 0),
+		newZoneNode("node-3", "zone-b", 20),
+	}
+	// least used zone first, bin packing within each zone
+	SortNodes(list, composite, nil)
+	assertOrder(list, []string{"node-3", "node-2", "node-1", "node-0"}, "zone-b first")
+
+	// packing node-3 makes zone-b the most used zone: the other zone is used before packing further
+	list[0] = newZoneNode("node-3", "zone-b", 70)
+	SortNodes(list, composite, nil)
+	assertOrder(list, []string{"node-1", "node-0", "node-3", "node-2"}, "zone-a first")
+
+	// all nodes in one zone: only bin packing is left
+	for _, node := range list {
+		node.UpdateAttributes(map[string]string{"zone": "zone-a"})
+	}
+	SortNodes(list, composite, nil)
+	assertOrder(list, []string{"node-3", "node-1", "node-0", "node-2"}, "single zone")
 }
 
 func TestSortAppsNoPending(t *testing.T) {
@@ -366,6 +443,20 @@ func TestSortAsks(t *testing.T) {
 	sortAskByPriority(list, false)
 	// asks should come back in order: 3, 2, 0, 1
 	assertAskList(t, list, []int{3, 1, 0, 2}, "descending same prio")
+
+	// the priority class offset is part of the priority: it flips the order of the asks
+	low := newAllocationAsk("ask-low", "app-1", res)
+	low.priority = 3
+	high := newAllocationAsk("ask-high", "app-1", res)
+	high.priority = 5
+	asks := []*AllocationAsk{high, low}
+	sortAskByPriority(asks, false)
+	assert.Equal(t, asks[0].AllocationKey, "ask-high", "raw priority should decide without an offset")
+	low.setPriorityOffset(10)
+	sortAskByPriority(asks, false)
+	assert.Equal(t, asks[0].AllocationKey, "ask-low", "offset should move the ask to the front")
+	sortAskByPriority(asks, true)
+	assert.Equal(t, asks[0].AllocationKey, "ask-high", "offset should move the ask to the back in ascending order")
 }
 
 // list of queues and the location of the named queue inside that list
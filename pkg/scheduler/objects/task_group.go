@@ -0,0 +1,78 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objects
+
+// The status of the members of a gang (task group) of an application.
+// Pending counts the allocations not yet made for the asks of the gang, running the allocations made
+// that have not been released and completed the allocations that have been released.
+type TaskGroupStatus struct {
+	Name      string
+	Total     int
+	Pending   int
+	Running   int
+	Completed int
+}
+
+// Return the status of each gang (task group) of the application keyed by the gang ID.
+// Asks and allocations that are not part of a gang are not included.
+func (sa *Application) GetTaskGroupStatus() map[string]TaskGroupStatus {
+	sa.RLock()
+	defer sa.RUnlock()
+
+	groups := make(map[string]*TaskGroupStatus)
+	getGroup := func(gangID string) *TaskGroupStatus {
+		group, ok := groups[gangID]
+		if !ok {
+			group = &TaskGroupStatus{Name: gangID}
+			groups[gangID] = group
+		}
+		return group
+	}
+	for _, ask := range sa.requests {
+		if ask.GangID != "" {
+			getGroup(ask.GangID).Pending += int(ask.GetPendingAskRepeat())
+		}
+	}
+	for _, alloc := range sa.allocations {
+		if alloc.Ask != nil && alloc.Ask.GangID != "" {
+			getGroup(alloc.Ask.GangID).Running++
+		}
+	}
+	for gangID, completed := range sa.completedGangAllocs {
+		getGroup(gangID).Completed += completed
+	}
+	status := make(map[string]TaskGroupStatus, len(groups))
+	for gangID, group := range groups {
+		group.Total = group.Pending + group.Running + group.Completed
+		status[gangID] = *group
+	}
+	return status
+}
+
+// Track the release of an allocation that is part of a gang.
+// No locking must be called while holding the lock
+func (sa *Application) trackCompletedGangAllocation(alloc *Allocation) {
+	if alloc.Ask == nil || alloc.Ask.GangID == "" {
+		return
+	}
+	if sa.completedGangAllocs == nil {
+		sa.completedGangAllocs = make(map[string]int)
+	}
+	sa.completedGangAllocs[alloc.Ask.GangID]++
+}
@@ -0,0 +1,81 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package objects
+
+import (
+	"fmt"
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
+)
+
+func TestGetTaskGroupStatus(t *testing.T) {
+	root, err := createRootQueue(nil)
+	assert.NilError(t, err, "failed to create root queue")
+	queue, err := createManagedQueue(root, "leaf", false, nil)
+	assert.NilError(t, err, "failed to create leaf queue")
+	app := newApplication(appID1, "default", "root.leaf")
+	app.queue = queue
+	assert.Equal(t, len(app.GetTaskGroupStatus()), 0, "new app should not have task groups")
+
+	// submit a gang of 3 and an ask outside the gang
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	asks := make([]*AllocationAsk, 3)
+	for i := range asks {
+		asks[i] = NewAllocationAsk(&si.AllocationAsk{
+			AllocationKey:  fmt.Sprintf("gang-%d", i),
+			ApplicationID:  appID1,
+			ResourceAsk:    res.ToProto(),
+			MaxAllocations: 1,
+			Tags:           map[string]string{AskTagGangID: "workers"},
+		})
+		assert.Equal(t, asks[i].GangID, "workers", "gang not set from the ask tag")
+		assert.Equal(t, asks[i].Clone().GangID, "workers", "gang not cloned")
+		err = app.AddAllocationAsk(asks[i])
+		assert.NilError(t, err, "ask should have been added to app")
+	}
+	err = app.AddAllocationAsk(newAllocationAsk("no-gang", appID1, res))
+	assert.NilError(t, err, "ask should have been added to app")
+	status := app.GetTaskGroupStatus()
+	assert.Equal(t, len(status), 1, "only the gang should be reported")
+	assert.Equal(t, status["workers"], TaskGroupStatus{Name: "workers", Total: 3, Pending: 3}, "unexpected status for submitted gang")
+
+	// allocate 2 of the 3 members
+	for i := 0; i < 2; i++ {
+		_, err = app.updateAskRepeat(asks[i].AllocationKey, -1)
+		assert.NilError(t, err, "ask repeat update should not have failed")
+		app.AddAllocation(NewAllocation(fmt.Sprintf("uuid-%d", i), nodeID1, asks[i]))
+	}
+	app.AddAllocation(newAllocation(appID1, "uuid-no-gang", nodeID1, "root.leaf", res))
+	status = app.GetTaskGroupStatus()
+	assert.Equal(t, status["workers"], TaskGroupStatus{Name: "workers", Total: 3, Pending: 1, Running: 2}, "unexpected status for partially allocated gang")
+
+	// release one member
+	assert.Assert(t, app.RemoveAllocation("uuid-0") != nil, "allocation should have been removed")
+	status = app.GetTaskGroupStatus()
+	assert.Equal(t, status["workers"], TaskGroupStatus{Name: "workers", Total: 3, Pending: 1, Running: 1, Completed: 1}, "unexpected status after release")
+
+	// release all
+	assert.Equal(t, len(app.RemoveAllAllocations()), 2, "unexpected number of released allocations")
+	status = app.GetTaskGroupStatus()
+	assert.Equal(t, status["workers"], TaskGroupStatus{Name: "workers", Total: 3, Pending: 1, Completed: 2}, "unexpected status after releasing all")
+}
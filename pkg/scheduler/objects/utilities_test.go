@@ -105,6 +105,7 @@ func newNodeInternal(nodeID string, total, occupied *resources.Resource) *Node {
 		allocatedResource: resources.NewResource(),
 		availableResource: resources.Sub(total, occupied),
 		allocations:       make(map[string]*Allocation),
+		appAllocations:    make(map[string]int),
 		schedulable:       true,
 		preempting:        resources.NewResource(),
 		reservations:      make(map[string]*reservation),
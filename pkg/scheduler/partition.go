@@ -21,8 +21,11 @@ package scheduler
 import (
 	"fmt"
 	"math"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/looplab/fsm"
@@ -32,6 +35,7 @@ import (
 	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
 	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
 	"github.com/apache/incubator-yunikorn-core/pkg/common/security"
+	"github.com/apache/incubator-yunikorn-core/pkg/events"
 	"github.com/apache/incubator-yunikorn-core/pkg/interfaces"
 	"github.com/apache/incubator-yunikorn-core/pkg/log"
 	"github.com/apache/incubator-yunikorn-core/pkg/metrics"
@@ -42,25 +46,63 @@ import (
 	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
 )
 
+const (
+	maxUUIDRetries = 10              // maximum number of new UUIDs generated to resolve a clash
+	uuidCacheTTL   = 5 * time.Second // time a used UUID is remembered
+)
+
 type PartitionContext struct {
-	RmID string // the RM the partition belongs to
-	Name string // name of the partition (logging mainly)
+	// Accessed atomically, kept first in the struct for 64-bit alignment
+	schedulingCycles     int64 // number of scheduling cycles started
+	lastSchedulingTime   int64 // start of the last scheduling cycle in nanoseconds since the epoch
+	recoveredAllocations int64 // allocations recovered since the partition was started
+	failedRecoveries     int64 // allocations that could not be recovered since the partition was started
+
+	RmID         string // the RM the partition belongs to
+	Name         string // name of the partition (logging mainly)
+	IsSimulation bool   // partition is a clone used for simulation, nothing is passed on to the RM
 
 	// Private fields need protection
-	root                   *objects.Queue                  // start of the queue hierarchy
-	applications           map[string]*objects.Application // applications assigned to this partition
-	reservedApps           map[string]int                  // applications reserved within this partition, with reservation count
-	nodes                  map[string]*objects.Node        // nodes assigned to this partition
-	allocations            map[string]*objects.Allocation  // allocations
-	placementManager       *placement.AppPlacementManager  // placement manager for this partition
-	partitionManager       *partitionManager               // manager for this partition
-	stateMachine           *fsm.FSM                        // the state of the partition for scheduling
-	stateTime              time.Time                       // last time the state was updated (needed for cleanup)
-	isPreemptable          bool                            // can allocations be preempted
-	rules                  *[]configs.PlacementRule        // placement rules to be loaded by the scheduler
-	userGroupCache         *security.UserGroupCache        // user cache per partition
-	totalPartitionResource *resources.Resource             // Total node resources
-	nodeSortingPolicy      *policies.NodeSortingPolicy     // Global Node Sorting Policies
+	root                     *objects.Queue                  // start of the queue hierarchy
+	applications             map[string]*objects.Application // applications assigned to this partition
+	reservedApps             map[string]int                  // applications reserved within this partition, with reservation count
+	nodes                    map[string]*objects.Node        // nodes assigned to this partition
+	allocations              map[string]*objects.Allocation  // allocations
+	placementManager         *placement.AppPlacementManager  // placement manager for this partition
+	partitionManager         *partitionManager               // manager for this partition
+	stateMachine             *fsm.FSM                        // the state of the partition for scheduling
+	stateTime                time.Time                       // last time the state was updated (needed for cleanup)
+	isPreemptable            bool                            // can allocations be preempted
+	rules                    *[]configs.PlacementRule        // placement rules to be loaded by the scheduler
+	userGroupCache           *security.UserGroupCache        // user cache per partition
+	totalPartitionResource   *resources.Resource             // Total node resources
+	nodeSortingPolicy        *policies.NodeSortingPolicy     // Global Node Sorting Policies
+	reservationTimeout       time.Duration                   // reservations older than this are removed, 0 means never
+	schedulingInterval       time.Duration                   // wait before the next scheduling cycle if nothing was scheduled
+	nodeRegisterTimeout      time.Duration                   // maximum time to replay the existing allocations of a new node, 0 means no timeout
+	uuidCache                *common.UUIDCache               // recently used allocation UUIDs
+	nodeEventLog             map[string][]NodeEvent          // history of events per node, kept for a limited number of removed nodes
+	removedNodes             *removedKeys                    // removed nodes that still have an event history
+	nodesByLabel             map[string]map[string][]string  // node IDs indexed by attribute key and value
+	nodeGroupKey             string                          // node attribute used to group the nodes
+	nodeGroups               map[string][]string             // node IDs indexed by the value of the node group key
+	userQuotas               map[string]*resources.Resource  // max resources per user from the partition limits
+	completedApps            map[string]*completedAppBuffer  // history of completed applications per queue path
+	removedQueues            *removedKeys                    // removed queues that still have a history
+	completedAppsLimit       int                             // maximum number of completed applications kept per queue
+	completedAppIndex        map[string]int                  // number of entries per application ID in the completed histories
+	allocCompactionInterval  int                             // partition manager runs between removing stale allocations, 0 means never
+	allocListeners           []chan<- AllocationEvent        // channels that receive the allocation events
+	queueListeners           []chan<- QueueEvent             // channels that receive the queue events
+	queueAllocationHistory   map[string]*allocHistoryBuffer  // allocations made and released per queue path
+	appAllocationHistory     map[string]*allocHistoryBuffer  // allocations made per application ID
+	priorityClasses          map[string]int32                // priority offset per priority class name
+	configChecksum           string                          // checksum of the active partition configuration
+	applicationRestartCounts map[string]int                  // number of times an application was submitted again after it completed
+	appCompletionTimes       *completionTimeBuffer           // times the applications were removed from the partition
+	partitionMaxResource     *resources.Resource             // cap on the total node resources usable by the partition, nil means no cap
+	completedAllocStats      *CompletedAllocationStats       // totals for the allocations removed from the partition
+	schedulingWaves          *schedulingWaveBuffer           // bursts of allocations in back to back scheduling cycles, locked separately
 
 	sync.RWMutex
 }
@@ -74,14 +116,28 @@ func newPartitionContext(conf configs.PartitionConfig, rmID string, cc *ClusterC
 		return nil, fmt.Errorf("partition cannot be created without name or RM, one is not set")
 	}
 	pc := &PartitionContext{
-		Name:         conf.Name,
-		RmID:         rmID,
-		stateMachine: objects.NewObjectState(),
-		stateTime:    time.Now(),
-		applications: make(map[string]*objects.Application),
-		reservedApps: make(map[string]int),
-		nodes:        make(map[string]*objects.Node),
-		allocations:  make(map[string]*objects.Allocation),
+		Name:                     conf.Name,
+		RmID:                     rmID,
+		stateMachine:             objects.NewObjectState(),
+		stateTime:                time.Now(),
+		applications:             make(map[string]*objects.Application),
+		reservedApps:             make(map[string]int),
+		nodes:                    make(map[string]*objects.Node),
+		allocations:              make(map[string]*objects.Allocation),
+		uuidCache:                common.NewUUIDCache(uuidCacheTTL),
+		nodeEventLog:             make(map[string][]NodeEvent),
+		removedNodes:             newRemovedKeys(maxRemovedNodeEventLogs),
+		nodesByLabel:             make(map[string]map[string][]string),
+		completedApps:            make(map[string]*completedAppBuffer),
+		completedAppIndex:        make(map[string]int),
+		removedQueues:            newRemovedKeys(maxRemovedQueueHistories),
+		queueAllocationHistory:   make(map[string]*allocHistoryBuffer),
+		appAllocationHistory:     make(map[string]*allocHistoryBuffer),
+		priorityClasses:          make(map[string]int32),
+		applicationRestartCounts: make(map[string]int),
+		appCompletionTimes:       newCompletionTimeBuffer(maxAppCompletionTimes),
+		completedAllocStats:      newCompletedAllocationStats(),
+		schedulingWaves:          newSchedulingWaveBuffer(maxSchedulingWaves),
 	}
 	pc.partitionManager = &partitionManager{
 		pc: pc,
@@ -116,6 +172,11 @@ func (pc *PartitionContext) initialPartitionFromConfig(conf configs.PartitionCon
 
 	// set preemption needed flag
 	pc.isPreemptable = conf.Preemption.Enabled
+	pc.setPartitionProperties(conf.Properties)
+	pc.setSchedulingInterval(conf.SchedulingIntervalMs)
+	pc.nodeRegisterTimeout = conf.NodeRegistrationTimeout
+	pc.setPriorityClasses(conf.PriorityClasses)
+	pc.setUserQuotas(conf.Limits)
 
 	pc.rules = &conf.PlacementRules
 	// We need to pass in the unlocked version of the getQueue function.
@@ -133,14 +194,15 @@ func (pc *PartitionContext) initialPartitionFromConfig(conf configs.PartitionCon
 			zap.Error(err))
 	}
 	switch configuredPolicy {
-	case policies.BinPackingPolicy, policies.FairnessPolicy:
+	case policies.BinPackingPolicy, policies.FairnessPolicy, policies.BestFitPolicy, policies.CompositePolicy:
 		log.Logger().Info("NodeSorting policy set from config",
 			zap.String("policyName", configuredPolicy.String()))
-		pc.nodeSortingPolicy = policies.NewNodeSortingPolicy(conf.NodeSortPolicy.Type)
+		pc.nodeSortingPolicy = policies.NewNodeSortingPolicy(conf.NodeSortPolicy.Type, conf.NodeSortPolicy.Parameters)
 	case policies.Unknown:
 		log.Logger().Info("NodeSorting policy not set using 'fair' as default")
-		pc.nodeSortingPolicy = policies.NewNodeSortingPolicy("fair")
+		pc.nodeSortingPolicy = policies.NewNodeSortingPolicy("fair", nil)
 	}
+	pc.configChecksum = getConfigChecksum(conf)
 	return nil
 }
 
@@ -166,6 +228,11 @@ func (pc *PartitionContext) updatePartitionDetails(conf configs.PartitionConfig)
 		// Placing an application will already have a lock on the partition context.
 		pc.placementManager = placement.NewPlacementManager(*pc.rules, pc.getQueue)
 	}
+	pc.setPartitionProperties(conf.Properties)
+	pc.setSchedulingInterval(conf.SchedulingIntervalMs)
+	pc.nodeRegisterTimeout = conf.NodeRegistrationTimeout
+	pc.setPriorityClasses(conf.PriorityClasses)
+	pc.setUserQuotas(conf.Limits)
 	// start at the root: there is only one queue
 	queueConf := conf.Queues[0]
 	root := pc.root
@@ -173,9 +240,161 @@ func (pc *PartitionContext) updatePartitionDetails(conf configs.PartitionConfig)
 	if err := root.SetQueueConfig(queueConf); err != nil {
 		return err
 	}
+	// the config has no max for the root queue: restore the one based on the nodes
+	pc.updateRootMax()
 	root.UpdateSortType()
 	// update the rest of the queues recursively
-	return pc.updateQueues(queueConf.Queues, root)
+	if err := pc.updateQueues(queueConf.Queues, root); err != nil {
+		return err
+	}
+	pc.configChecksum = getConfigChecksum(conf)
+	return nil
+}
+
+// Apply the partition properties from the config.
+// Properties that are not set are reset to their defaults.
+// NOTE: this is a lock free call. It should only be called holding the PartitionContext lock or during create.
+func (pc *PartitionContext) setPartitionProperties(props map[string]string) {
+	pc.reservationTimeout = 0
+	if value, ok := props[configs.ReservationTimeout]; ok {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout < 0 {
+			log.Logger().Warn("reservation timeout property ignored",
+				zap.String("partitionName", pc.Name),
+				zap.String("value", value))
+		} else {
+			pc.reservationTimeout = timeout
+		}
+	}
+	groupKey := configs.DefaultNodeGroupKey
+	if value, ok := props[configs.NodeGroupKey]; ok && value != "" {
+		groupKey = value
+	}
+	pc.setNodeGroupKey(groupKey)
+	pc.completedAppsLimit = configs.DefaultCompletedApplicationsLimit
+	if value, ok := props[configs.CompletedApplicationsLimit]; ok {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			log.Logger().Warn("completed applications limit property ignored",
+				zap.String("partitionName", pc.Name),
+				zap.String("value", value))
+		} else {
+			pc.completedAppsLimit = limit
+		}
+	}
+	pc.allocCompactionInterval = configs.DefaultAllocationCompactionInterval
+	if value, ok := props[configs.AllocationCompactionInterval]; ok {
+		interval, err := strconv.Atoi(value)
+		if err != nil || interval < 0 {
+			log.Logger().Warn("allocation compaction interval property ignored",
+				zap.String("partitionName", pc.Name),
+				zap.String("value", value))
+		} else {
+			pc.allocCompactionInterval = interval
+		}
+	}
+}
+
+// Set the scheduling interval from the config, a value of 0 or less sets the default.
+// NOTE: this is a lock free call. It should only be called holding the PartitionContext lock or during create.
+func (pc *PartitionContext) setSchedulingInterval(intervalMs int) {
+	if intervalMs <= 0 {
+		intervalMs = configs.DefaultSchedulingIntervalMs
+	}
+	pc.schedulingInterval = time.Duration(intervalMs) * time.Millisecond
+}
+
+// Return the time to wait before the next scheduling cycle when nothing was scheduled.
+func (pc *PartitionContext) GetSchedulingInterval() time.Duration {
+	pc.RLock()
+	defer pc.RUnlock()
+	return pc.schedulingInterval
+}
+
+// Record the start of a scheduling cycle for the partition.
+// Lock free call, the counters are updated atomically.
+func (pc *PartitionContext) startSchedulingCycle() {
+	atomic.StoreInt64(&pc.lastSchedulingTime, time.Now().UnixNano())
+	atomic.AddInt64(&pc.schedulingCycles, 1)
+	metrics.GetSchedulerMetrics().IncSchedulingCycle(pc.Name)
+}
+
+// Return the number of scheduling cycles started for the partition.
+func (pc *PartitionContext) GetSchedulingCycleCount() int64 {
+	return atomic.LoadInt64(&pc.schedulingCycles)
+}
+
+// Return the start time of the last scheduling cycle, the zero time if no cycle has run.
+func (pc *PartitionContext) GetLastSchedulingCycleTime() time.Time {
+	last := atomic.LoadInt64(&pc.lastSchedulingTime)
+	if last == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, last)
+}
+
+// Return the number of allocations recovered since the partition was started.
+func (pc *PartitionContext) GetRecoveredAllocationCount() int {
+	return int(atomic.LoadInt64(&pc.recoveredAllocations))
+}
+
+// Return the number of allocations that could not be recovered since the partition was started.
+func (pc *PartitionContext) GetFailedRecoveryCount() int {
+	return int(atomic.LoadInt64(&pc.failedRecoveries))
+}
+
+// Count the result of recovering an allocation.
+// Lock free call, the counters are updated atomically.
+func (pc *PartitionContext) countRecovery(recovered bool) {
+	if recovered {
+		metrics.GetSchedulerMetrics().SetRecoveredAllocations(pc.Name, int(atomic.AddInt64(&pc.recoveredAllocations, 1)))
+		return
+	}
+	metrics.GetSchedulerMetrics().SetFailedRecoveries(pc.Name, int(atomic.AddInt64(&pc.failedRecoveries, 1)))
+}
+
+// Reset the recovery counters, a partition that is started again recovers all allocations again.
+// Lock free call, the counters are updated atomically.
+func (pc *PartitionContext) resetRecoveryCounters() {
+	atomic.StoreInt64(&pc.recoveredAllocations, 0)
+	atomic.StoreInt64(&pc.failedRecoveries, 0)
+	metrics.GetSchedulerMetrics().SetRecoveredAllocations(pc.Name, 0)
+	metrics.GetSchedulerMetrics().SetFailedRecoveries(pc.Name, 0)
+}
+
+// Return true if allocations in the partition can be preempted.
+func (pc *PartitionContext) GetIsPreemptable() bool {
+	pc.RLock()
+	defer pc.RUnlock()
+	return pc.isPreemptable
+}
+
+// Enable or disable preemption for the partition at runtime.
+// This only selects the partitions for the preemption cycle, it does not start the cycle.
+// A preemption cycle that is in progress stops processing the partition when preemption is disabled.
+func (pc *PartitionContext) SetIsPreemptable(enabled bool) {
+	pc.Lock()
+	defer pc.Unlock()
+	if pc.isPreemptable != enabled {
+		log.Logger().Info("partition preemption changed",
+			zap.String("partitionName", pc.Name),
+			zap.Bool("enabled", enabled))
+	}
+	pc.isPreemptable = enabled
+}
+
+// Return the number of partition manager runs between removing stale allocations, 0 means never.
+func (pc *PartitionContext) getAllocCompactionInterval() int {
+	pc.RLock()
+	defer pc.RUnlock()
+	return pc.allocCompactionInterval
+}
+
+// Return the timeout after which reservations are removed, 0 means reservations do not time out.
+func (pc *PartitionContext) getReservationTimeout() time.Duration {
+	pc.RLock()
+	defer pc.RUnlock()
+	return pc.reservationTimeout
 }
 
 // Process the config structure and create a queue info tree for this partition
@@ -261,10 +480,15 @@ func (pc *PartitionContext) isStopped() bool {
 
 // Handle the state event for the partition.
 // The state machine handles the locking.
+// The recovery counters are reset when a stopped partition is started.
 func (pc *PartitionContext) handlePartitionEvent(event objects.ObjectEvent) error {
+	wasStopped := pc.isStopped()
 	err := pc.stateMachine.Event(event.String(), pc.Name)
 	if err == nil {
 		pc.stateTime = time.Now()
+		if wasStopped && event == objects.Start {
+			pc.resetRecoveryCounters()
+		}
 		return nil
 	}
 	// handle the same state transition not nil error (limit of fsm).
@@ -278,7 +502,31 @@ func (pc *PartitionContext) handlePartitionEvent(event objects.ObjectEvent) erro
 func (pc *PartitionContext) AddApplication(app *objects.Application) error {
 	pc.Lock()
 	defer pc.Unlock()
+	return pc.addApplicationInternal(app)
+}
 
+// Add a list of applications to the partition taking the partition lock only once.
+// Returns the error for each application in the same order as the applications passed in, nil if the
+// application was added, and the number of applications that were added.
+func (pc *PartitionContext) BatchAddApplications(apps []*objects.Application) ([]error, int) {
+	pc.Lock()
+	defer pc.Unlock()
+	errs := make([]error, len(apps))
+	added := 0
+	for i, app := range apps {
+		if app == nil {
+			errs[i] = fmt.Errorf("cannot add a nil application to partition %s", pc.Name)
+			continue
+		}
+		if errs[i] = pc.addApplicationInternal(app); errs[i] == nil {
+			added++
+		}
+	}
+	return errs, added
+}
+
+// Unlocked version must be called holding the partition lock
+func (pc *PartitionContext) addApplicationInternal(app *objects.Application) error {
 	if pc.isDraining() || pc.isStopped() {
 		return fmt.Errorf("partition %s is stopped cannot add a new application %s", pc.Name, app.ApplicationID)
 	}
@@ -316,14 +564,37 @@ func (pc *PartitionContext) AddApplication(app *objects.Application) error {
 		}
 	}
 	// check the queue: is a leaf queue with submit access
-	if !queue.IsLeafQueue() || !queue.CheckSubmitAccess(app.GetUser()) {
+	if !queue.IsLeafQueue() {
+		return fmt.Errorf("failed to find queue %s for application %s", queueName, appID)
+	}
+	if user := app.GetUserGroup(); !queue.CheckSubmitAccess(user) {
+		log.AuditLog().Audit(log.AuditACLDenied, map[string]string{
+			"partition":     pc.Name,
+			"applicationID": appID,
+			"queue":         queueName,
+			"user":          user.User,
+		})
 		return fmt.Errorf("failed to find queue %s for application %s", queueName, appID)
 	}
 
+	if queue.IsDraining() {
+		return fmt.Errorf("queue %s is draining, cannot add application %s", queueName, appID)
+	}
+
 	// all is OK update the app and partition
+	if err := queue.AddApplication(app); err != nil {
+		return fmt.Errorf("application %s rejected: %v", appID, err)
+	}
 	app.SetQueue(queue)
-	queue.AddApplication(app)
+	pc.applyPriorityClass(app)
 	pc.applications[appID] = app
+	if pc.isCompletedApp(appID) {
+		pc.applicationRestartCounts[appID]++
+		log.Logger().Info("application resubmitted after completion",
+			zap.String("appID", appID),
+			zap.Int("restartCount", pc.applicationRestartCounts[appID]))
+	}
+	app.RecordEvent(objects.AppAdmitted, fmt.Sprintf("admitted to queue %s", queue.QueuePath), nil)
 
 	return nil
 }
@@ -342,6 +613,7 @@ func (pc *PartitionContext) removeApplication(appID string) []*objects.Allocatio
 	// remove from partition then cleanup underlying objects
 	delete(pc.applications, appID)
 	delete(pc.reservedApps, appID)
+	delete(pc.appAllocationHistory, appID)
 
 	queueName := app.QueueName
 	// Remove all asks and thus all reservations and pending resources (queue included)
@@ -363,6 +635,9 @@ func (pc *PartitionContext) removeApplication(appID string) []*objects.Allocatio
 					zap.String("allocationId", currentUUID))
 			} else {
 				delete(pc.allocations, currentUUID)
+				pc.addCompletedAllocation(alloc)
+				pc.sendAllocationEventInternal(AllocationReleased, alloc)
+				pc.addQueueAllocationHistory(alloc, AllocationHistoryReleased)
 			}
 
 			// Remove from node: even if not found on the partition to keep things clean
@@ -382,6 +657,9 @@ func (pc *PartitionContext) removeApplication(appID string) []*objects.Allocatio
 		}
 	}
 
+	pc.addCompletedApp(app)
+	pc.appCompletionTimes.add(time.Now())
+
 	log.Logger().Debug("application removed from the scheduler",
 		zap.String("queue", queueName),
 		zap.String("applicationID", appID))
@@ -389,6 +667,45 @@ func (pc *PartitionContext) removeApplication(appID string) []*objects.Allocatio
 	return allocations
 }
 
+// Forcibly remove the application from the partition outside the normal application lifecycle.
+// The removal is recorded in the audit log and an application event is posted with the reason given.
+// Returns the allocations that were removed from the application.
+func (pc *PartitionContext) ForceRemoveApplication(appID string, reason string) []*objects.Allocation {
+	app := pc.getApplication(appID)
+	if app == nil {
+		log.Logger().Warn("force remove failed: application not found",
+			zap.String("applicationID", appID),
+			zap.String("reason", reason))
+		return nil
+	}
+	state := app.CurrentState()
+	allocations := pc.removeApplication(appID)
+	log.AuditLog().Audit(log.AuditForceRemoved, map[string]string{
+		"partition":     pc.Name,
+		"applicationID": appID,
+		"queue":         app.GetQueueName(),
+		"state":         state,
+		"allocations":   strconv.Itoa(len(allocations)),
+		"reason":        reason,
+	})
+	log.Logger().Info("application forcibly removed from the scheduler",
+		zap.String("applicationID", appID),
+		zap.String("state", state),
+		zap.Int("allocations", len(allocations)),
+		zap.String("reason", reason))
+	// post the removal via the event plugin
+	if eventCache := events.GetEventCache(); eventCache != nil {
+		if event, err := events.CreateAppEventRecord(appID, log.AuditForceRemoved, reason); err != nil {
+			log.Logger().Warn("Event creation failed",
+				zap.String("event message", reason),
+				zap.Error(err))
+		} else {
+			eventCache.AddEvent(event)
+		}
+	}
+	return allocations
+}
+
 func (pc *PartitionContext) getApplication(appID string) *objects.Application {
 	pc.RLock()
 	defer pc.RUnlock()
@@ -396,6 +713,59 @@ func (pc *PartitionContext) getApplication(appID string) *objects.Application {
 	return pc.applications[appID]
 }
 
+// Return a copy of the event log of the application, oldest event first.
+// Returns nil if the application is not part of the partition.
+func (pc *PartitionContext) GetApplicationEventLog(appID string) []objects.ApplicationEvent {
+	app := pc.getApplication(appID)
+	if app == nil {
+		return nil
+	}
+	return app.GetEventLog()
+}
+
+// Return how the placement rules placed the application.
+// An empty audit is returned if the application is not part of the partition or was not placed by the rules.
+func (pc *PartitionContext) GetApplicationPlacementAudit(appID string) objects.PlacementAudit {
+	app := pc.getApplication(appID)
+	if app == nil {
+		return objects.PlacementAudit{}
+	}
+	if audit := app.GetPlacementAudit(); audit != nil {
+		return *audit
+	}
+	return objects.PlacementAudit{}
+}
+
+// Return the full path of the queue the application is currently assigned to.
+// An error is returned if the application is not part of the partition.
+func (pc *PartitionContext) GetApplicationQueuePath(appID string) (string, error) {
+	app := pc.getApplication(appID)
+	if app == nil {
+		return "", fmt.Errorf("application %s not found in partition %s", appID, pc.Name)
+	}
+	return app.GetQueueName(), nil
+}
+
+// Return the time the application was admitted to the scheduler.
+// An error is returned if the application is not part of the partition.
+func (pc *PartitionContext) GetApplicationCreationTime(appID string) (time.Time, error) {
+	app := pc.getApplication(appID)
+	if app == nil {
+		return time.Time{}, fmt.Errorf("application %s not found in partition %s", appID, pc.Name)
+	}
+	return app.GetCreateTime(), nil
+}
+
+// Return the time since the application was admitted to the scheduler.
+// An error is returned if the application is not part of the partition.
+func (pc *PartitionContext) GetApplicationAge(appID string) (time.Duration, error) {
+	created, err := pc.GetApplicationCreationTime(appID)
+	if err != nil {
+		return 0, err
+	}
+	return time.Since(created), nil
+}
+
 // Return a copy of the map of all reservations for the partition.
 // This will return an empty map if there are no reservations.
 // Visible for tests
@@ -418,6 +788,44 @@ func (pc *PartitionContext) GetQueue(name string) *objects.Queue {
 	return pc.getQueue(name)
 }
 
+// Drain the queue and all queues below it: new applications are rejected while the existing applications complete.
+// The partition manager removes the queue after the last application is removed if the queue is a dynamic queue
+// or it has been removed from the configuration. The root queue cannot be drained.
+func (pc *PartitionContext) DrainQueue(queuePath string) error {
+	if strings.ToLower(queuePath) == configs.RootQueue {
+		return fmt.Errorf("cannot drain the root queue in partition %s", pc.Name)
+	}
+	queue := pc.GetQueue(queuePath)
+	if queue == nil {
+		return fmt.Errorf("queue %s not found in partition %s", queuePath, pc.Name)
+	}
+	return queue.Drain()
+}
+
+// Update the max resource of a queue at runtime, see Queue.SetMaxResource.
+// The max of a managed queue is owned by the configuration: a runtime change would be replaced on the next
+// reload and is rejected. Change the configuration instead. Only the max of unmanaged queues can be updated.
+func (pc *PartitionContext) UpdateQueueMaxResource(queuePath string, max *resources.Resource) error {
+	queue := pc.GetQueue(queuePath)
+	if queue == nil {
+		return fmt.Errorf("queue %s not found in partition %s", queuePath, pc.Name)
+	}
+	if queue.IsManaged() {
+		return fmt.Errorf("max resource of queue %s is set by the configuration, update the configuration instead", queuePath)
+	}
+	return queue.SetMaxResource(max)
+}
+
+// Undrain a queue that was drained on request and all queues below it that were drained on request.
+// A queue that is draining because it was removed from the configuration cannot be undrained.
+func (pc *PartitionContext) UndrainQueue(queuePath string) error {
+	queue := pc.GetQueue(queuePath)
+	if queue == nil {
+		return fmt.Errorf("queue %s not found in partition %s", queuePath, pc.Name)
+	}
+	return queue.Undrain()
+}
+
 // Get the queue from the structure based on the fully qualified name.
 // The name is not syntax checked and must be valid.
 // Returns nil if the queue is not found otherwise the queue object.
@@ -446,6 +854,72 @@ func (pc *PartitionContext) GetQueueInfos() dao.QueueDAOInfo {
 	return pc.root.GetQueueInfos()
 }
 
+// Return the queue hierarchy of the partition limited to the queues that match the filter.
+func (pc *PartitionContext) GetQueueInfosFiltered(filter dao.QueueFilter) dao.QueueDAOInfo {
+	return pc.root.GetQueueInfosFiltered(filter)
+}
+
+// Convert the live partition back into the configuration that would create it.
+// Limits are not part of the exported configuration.
+func (pc *PartitionContext) ExportConfig() configs.PartitionConfig {
+	pc.RLock()
+	defer pc.RUnlock()
+	conf := configs.PartitionConfig{
+		Name:   common.GetPartitionNameWithoutClusterID(pc.Name),
+		Queues: []configs.QueueConfig{pc.root.ExportConfig()},
+		Preemption: configs.PartitionPreemptionConfig{
+			Enabled: pc.isPreemptable,
+		},
+		NodeSortPolicy: configs.NodeSortingPolicy{
+			Type: pc.nodeSortingPolicy.PolicyType.String(),
+		},
+	}
+	if len(pc.nodeSortingPolicy.Parameters) != 0 {
+		conf.NodeSortPolicy.Parameters = make(map[string]string)
+		for key, value := range pc.nodeSortingPolicy.Parameters {
+			conf.NodeSortPolicy.Parameters[key] = value
+		}
+	}
+	if pc.rules != nil && len(*pc.rules) != 0 {
+		conf.PlacementRules = make([]configs.PlacementRule, len(*pc.rules))
+		copy(conf.PlacementRules, *pc.rules)
+	}
+	if pc.reservationTimeout != 0 {
+		conf.Properties = map[string]string{
+			configs.ReservationTimeout: pc.reservationTimeout.String(),
+		}
+	}
+	if pc.nodeGroupKey != configs.DefaultNodeGroupKey {
+		if conf.Properties == nil {
+			conf.Properties = make(map[string]string)
+		}
+		conf.Properties[configs.NodeGroupKey] = pc.nodeGroupKey
+	}
+	if pc.completedAppsLimit != configs.DefaultCompletedApplicationsLimit {
+		if conf.Properties == nil {
+			conf.Properties = make(map[string]string)
+		}
+		conf.Properties[configs.CompletedApplicationsLimit] = strconv.Itoa(pc.completedAppsLimit)
+	}
+	if pc.allocCompactionInterval != configs.DefaultAllocationCompactionInterval {
+		if conf.Properties == nil {
+			conf.Properties = make(map[string]string)
+		}
+		conf.Properties[configs.AllocationCompactionInterval] = strconv.Itoa(pc.allocCompactionInterval)
+	}
+	if interval := int(pc.schedulingInterval / time.Millisecond); interval != configs.DefaultSchedulingIntervalMs {
+		conf.SchedulingIntervalMs = interval
+	}
+	conf.NodeRegistrationTimeout = pc.nodeRegisterTimeout
+	if len(pc.priorityClasses) != 0 {
+		conf.PriorityClasses = make(map[string]int32, len(pc.priorityClasses))
+		for className, offset := range pc.priorityClasses {
+			conf.PriorityClasses[className] = offset
+		}
+	}
+	return conf
+}
+
 // Create a queue with full hierarchy. This is called when a new queue is created from a placement rule.
 // The final leaf queue does not exist otherwise we would not get here.
 // This means that at least 1 queue (a leaf queue) will be created
@@ -546,17 +1020,27 @@ func (pc *PartitionContext) AddNode(node *objects.Node, existingAllocations []*o
 	} else {
 		pc.totalPartitionResource.AddTo(node.GetCapacity())
 	}
-	pc.root.SetMaxResource(pc.totalPartitionResource)
+	pc.updateRootMax()
 
 	// Node is added to the system to allow processing of the allocations
 	pc.nodes[node.NodeID] = node
+	pc.removedNodes.remove(node.NodeID)
+	pc.addNodeLabels(node.NodeID, node.GetAttributes())
+	pc.addNodeEventInternal(node.NodeID, NodeAdded, node.GetCapacity())
 	// Add allocations that exist on the node when added
 	if len(existingAllocations) > 0 {
 		log.Logger().Info("add existing allocations",
 			zap.String("nodeID", node.NodeID),
 			zap.Int("existingAllocations", len(existingAllocations)))
+		start := time.Now()
 		for current, alloc := range existingAllocations {
-			if err := pc.addAllocation(alloc); err != nil {
+			err := pc.addAllocation(alloc)
+			// a node that takes too long to replay its allocations is removed like a failed node
+			if err == nil && pc.nodeRegisterTimeout > 0 && time.Since(start) > pc.nodeRegisterTimeout {
+				metrics.GetSchedulerMetrics().IncNodeRegistrationTimeout()
+				err = fmt.Errorf("node %s registration did not complete within %s", node.NodeID, pc.nodeRegisterTimeout)
+			}
+			if err != nil {
 				released := pc.removeNodeInternal(node.NodeID)
 				log.Logger().Info("failed to add existing allocations",
 					zap.String("nodeID", node.NodeID),
@@ -578,6 +1062,128 @@ func (pc *PartitionContext) AddNode(node *objects.Node, existingAllocations []*o
 	return nil
 }
 
+// Update the max of the root queue to the effective partition resource.
+// Unlocked version must be called holding the partition lock
+func (pc *PartitionContext) updateRootMax() {
+	if err := pc.root.SetMaxResource(pc.getEffectivePartitionResource()); err != nil {
+		log.Logger().Warn("failed to update the root queue max resource",
+			zap.String("partitionName", pc.Name),
+			zap.Error(err))
+	}
+}
+
+// Return the resources usable by the partition: the total node resources capped at the partition max resource.
+// Resource types that are not part of the partition max resource are not capped.
+// Unlocked version must be called holding the partition lock
+func (pc *PartitionContext) getEffectivePartitionResource() *resources.Resource {
+	if pc.totalPartitionResource == nil || pc.partitionMaxResource == nil {
+		return pc.totalPartitionResource
+	}
+	effective := pc.totalPartitionResource.Clone()
+	for name, max := range pc.partitionMaxResource.Resources {
+		if quantity, ok := effective.Resources[name]; ok {
+			effective.Resources[name] = resources.MinQuantity(quantity, max)
+		}
+	}
+	return effective
+}
+
+// Set the max resource of the partition, the root queue max is set to the node resources capped at this max.
+// A nil or empty resource removes the cap. The max is rejected if it contains a negative quantity or if the
+// resources already allocated in the partition would not fit.
+func (pc *PartitionContext) SetPartitionMaxResource(res *resources.Resource) error {
+	pc.Lock()
+	defer pc.Unlock()
+	if res != nil && len(res.Resources) == 0 {
+		res = nil
+	}
+	if res != nil {
+		for name, quantity := range res.Resources {
+			if quantity < 0 {
+				return fmt.Errorf("partition max resource %s has a negative quantity for %s", res, name)
+			}
+		}
+	}
+	previous := pc.partitionMaxResource
+	pc.partitionMaxResource = nil
+	if res != nil {
+		pc.partitionMaxResource = res.Clone()
+	}
+	if effective := pc.getEffectivePartitionResource(); effective != nil && !resources.FitIn(effective, pc.root.GetAllocatedResource()) {
+		pc.partitionMaxResource = previous
+		return fmt.Errorf("partition max resource %s is lower than the allocated resource %s", res, pc.root.GetAllocatedResource())
+	}
+	pc.updateRootMax()
+	log.Logger().Info("partition max resource updated",
+		zap.String("partitionName", pc.Name),
+		zap.Any("maxResource", res))
+	return nil
+}
+
+// Return the max resource of the partition, nil if the partition resources are not capped.
+func (pc *PartitionContext) GetPartitionMaxResource() *resources.Resource {
+	pc.RLock()
+	defer pc.RUnlock()
+	if pc.partitionMaxResource == nil {
+		return nil
+	}
+	return pc.partitionMaxResource.Clone()
+}
+
+// Change the capacity of a node in the partition, for instance after the node was resized.
+// The partition resources and the root queue max are updated to reflect the new node capacity.
+// The capacity is not changed if the node is not found or the new capacity is less than the allocated resources.
+func (pc *PartitionContext) ResizeNode(nodeID string, newCapacity *resources.Resource) error {
+	pc.Lock()
+	defer pc.Unlock()
+	node := pc.nodes[nodeID]
+	if node == nil {
+		return fmt.Errorf("node %s not found in partition %s", nodeID, pc.Name)
+	}
+	oldCapacity := node.GetCapacity()
+	if resources.Equals(oldCapacity, newCapacity) {
+		return nil
+	}
+	if err := node.SetCapacity(newCapacity); err != nil {
+		return err
+	}
+	pc.updateNodeCapacityInternal(nodeID, oldCapacity, newCapacity)
+	return nil
+}
+
+// Apply the capacity of a node reported by the RM. The RM is authoritative: the capacity is always applied, even
+// if it is less than the resources allocated on the node. The node is over allocated in that case and is not
+// considered for new allocations until enough allocations are released.
+// The partition resources and the root queue max are updated to reflect the new node capacity.
+func (pc *PartitionContext) updateNodeCapacity(nodeID string, newCapacity *resources.Resource) {
+	pc.Lock()
+	defer pc.Unlock()
+	node := pc.nodes[nodeID]
+	if node == nil {
+		return
+	}
+	oldCapacity := node.GetCapacity()
+	if resources.Equals(oldCapacity, newCapacity) {
+		return
+	}
+	node.SetReportedCapacity(newCapacity)
+	if node.IsOverAllocated() {
+		log.Logger().Warn("node capacity reported by the RM is less than the allocated resources",
+			zap.String("nodeID", nodeID),
+			zap.String("capacity", newCapacity.String()),
+			zap.String("allocated", node.GetAllocatedResource().String()))
+	}
+	pc.updateNodeCapacityInternal(nodeID, oldCapacity, newCapacity)
+}
+
+// Update the partition resources, the root queue max and the node history after the capacity of the node changed.
+// Unlocked version must be called holding the partition lock.
+func (pc *PartitionContext) updateNodeCapacityInternal(nodeID string, oldCapacity, newCapacity *resources.Resource) {
+	pc.totalPartitionResource = resources.Add(resources.Sub(pc.totalPartitionResource, oldCapacity), newCapacity)
+	pc.updateRootMax()
+	pc.addNodeEventInternal(nodeID, NodeResized, newCapacity)
+}
+
 // Remove a node from the partition. It returns all removed allocations.
 func (pc *PartitionContext) removeNode(nodeID string) []*objects.Allocation {
 	pc.Lock()
@@ -602,12 +1208,17 @@ func (pc *PartitionContext) removeNodeInternal(nodeID string) []*objects.Allocat
 
 	// Remove node from list of tracked nodes
 	delete(pc.nodes, nodeID)
+	pc.removeNodeLabels(nodeID, node.GetAttributes())
 	metrics.GetSchedulerMetrics().DecActiveNodes()
 
 	// found the node cleanup the node and all linked data
 	released := pc.removeNodeAllocations(node)
+	pc.addNodeEventInternal(nodeID, NodeRemoved, node.GetCapacity())
+	if evicted, ok := pc.removedNodes.add(nodeID); ok {
+		delete(pc.nodeEventLog, evicted)
+	}
 	pc.totalPartitionResource.SubFrom(node.GetCapacity())
-	pc.root.SetMaxResource(pc.totalPartitionResource)
+	pc.updateRootMax()
 
 	// unreserve all the apps that were reserved on the node
 	reservedKeys, releasedAsks := node.UnReserveApps()
@@ -622,6 +1233,28 @@ func (pc *PartitionContext) removeNodeInternal(nodeID string) []*objects.Allocat
 	return released
 }
 
+// Release all allocations in the partition at once, used when the scheduler state can no longer be trusted.
+// The allocations are removed from the applications, the queues and the nodes. The released allocations are
+// returned and must be communicated to the RM by the caller.
+func (pc *PartitionContext) clearPartition() []*objects.Allocation {
+	pc.Lock()
+	defer pc.Unlock()
+	released := pc.root.ClearAllAllocations()
+	for _, alloc := range released {
+		if node := pc.nodes[alloc.NodeID]; node != nil && node.RemoveAllocation(alloc.UUID) != nil {
+			pc.addNodeEventInternal(alloc.NodeID, NodeAllocationRemoved, alloc.AllocatedResource)
+		}
+		delete(pc.allocations, alloc.UUID)
+		pc.addCompletedAllocation(alloc)
+		pc.sendAllocationEventInternal(AllocationReleased, alloc)
+		pc.addQueueAllocationHistory(alloc, AllocationHistoryReleased)
+	}
+	log.Logger().Info("cleared all allocations from partition",
+		zap.String("partitionName", pc.Name),
+		zap.Int("allocations", len(released)))
+	return released
+}
+
 // Remove all allocations that are assigned to a node as part of the node removal. This is not part of the node object
 // as updating the applications and queues is the only goal. Applications and queues are not accessible from the node.
 // The removed allocations are returned.
@@ -656,6 +1289,10 @@ func (pc *PartitionContext) removeNodeAllocations(node *objects.Node) []*objects
 
 		// the allocation is removed so add it to the list that we return
 		released = append(released, alloc)
+		pc.addCompletedAllocation(alloc)
+		pc.addNodeEventInternal(node.NodeID, NodeAllocationRemoved, alloc.AllocatedResource)
+		pc.sendAllocationEventInternal(AllocationReleased, alloc)
+		pc.addQueueAllocationHistory(alloc, AllocationHistoryReleased)
 		log.Logger().Info("allocation removed",
 			zap.String("allocationId", allocID),
 			zap.String("nodeID", node.NodeID))
@@ -752,19 +1389,51 @@ func (pc *PartitionContext) allocate(alloc *objects.Allocation) *objects.Allocat
 
 	// Safeguard against the unlikely case that we have clashes.
 	// A clash points to entropy issues on the node.
-	if _, found := pc.allocations[alloc.UUID]; found {
-		for {
+	if !pc.isUniqueUUID(alloc.UUID) {
+		unique := false
+		for retry := 0; retry < maxUUIDRetries; retry++ {
 			allocationUUID := common.GetNewUUID()
 			log.Logger().Warn("UUID clash, random generator might be lacking entropy",
 				zap.String("uuid", alloc.UUID),
 				zap.String("new UUID", allocationUUID))
-			if pc.allocations[allocationUUID] == nil {
+			if pc.isUniqueUUID(allocationUUID) {
 				alloc.UUID = allocationUUID
+				unique = true
 				break
 			}
 		}
+		if !unique {
+			log.Logger().Error("failed to generate unique UUID, reverting allocation",
+				zap.String("appID", appID),
+				zap.String("allocationKey", alloc.AllocationKey),
+				zap.Int("retries", maxUUIDRetries))
+			metrics.GetSchedulerMetrics().IncSchedulingError()
+			app.RevertAllocation(node, alloc)
+			return nil
+		}
 	}
 	pc.allocations[alloc.UUID] = alloc
+	app.UpdateAllocationTime(alloc.Ask)
+	pc.addNodeEventInternal(alloc.NodeID, NodeAllocationAdded, alloc.AllocatedResource)
+	pc.sendAllocationEventInternal(AllocationAdded, alloc)
+	pc.addQueueAllocationHistory(alloc, AllocationHistoryAllocated)
+	pc.addAppAllocationHistory(alloc)
+	if queue := app.GetQueue(); queue != nil {
+		queue.CountAllocation()
+	}
+	// a simulated allocation is not audited and never passed back to the RM
+	if pc.IsSimulation {
+		return alloc
+	}
+	log.AuditLog().Audit(log.AuditAllocation, map[string]string{
+		"partition":         pc.Name,
+		"applicationID":     alloc.ApplicationID,
+		"allocationKey":     alloc.AllocationKey,
+		"uuid":              alloc.UUID,
+		"queue":             alloc.QueueName,
+		"nodeID":            alloc.NodeID,
+		"allocatedResource": alloc.AllocatedResource.String(),
+	})
 	log.Logger().Info("scheduler allocation processed",
 		zap.String("appID", alloc.ApplicationID),
 		zap.String("allocationKey", alloc.AllocationKey),
@@ -774,6 +1443,118 @@ func (pc *PartitionContext) allocate(alloc *objects.Allocation) *objects.Allocat
 	return alloc
 }
 
+// Simulate a scheduling cycle for the partition without changing the partition.
+// The simulation runs on a clone of the partition and allocates until no more allocations can be made.
+// The returned allocations only exist in the clone and must not be passed on to the RM.
+func (pc *PartitionContext) SimulateSchedulingCycle() []*objects.Allocation {
+	sim := pc.CloneForSimulation()
+	if sim == nil {
+		return nil
+	}
+	allocs := make([]*objects.Allocation, 0)
+	for {
+		alloc := sim.tryAllocate()
+		if alloc == nil {
+			break
+		}
+		allocs = append(allocs, alloc)
+	}
+	return allocs
+}
+
+// Create a copy of the partition that can be scheduled without changing this partition.
+// The queue structure is recreated from the exported configuration, nodes, applications and allocations
+// are copied. The clone has its own state and partition manager, which is not started, and is marked as
+// a simulation: applications in the clone do not send events to the RM and the clone does not update metrics.
+// Reservations and limits are not copied. Returns nil if the clone could not be created.
+func (pc *PartitionContext) CloneForSimulation() *PartitionContext {
+	sim, err := pc.cloneForSimulation()
+	if err != nil {
+		log.Logger().Warn("failed to clone partition for simulation",
+			zap.String("partitionName", pc.Name),
+			zap.Error(err))
+		return nil
+	}
+	return sim
+}
+
+func (pc *PartitionContext) cloneForSimulation() (*PartitionContext, error) {
+	sim, err := newPartitionContext(pc.ExportConfig(), pc.RmID, nil)
+	if err != nil {
+		return nil, err
+	}
+	sim.IsSimulation = true
+	sim.root.SetSimulation()
+	pc.RLock()
+	defer pc.RUnlock()
+	sim.Name = pc.Name
+	if pc.partitionMaxResource != nil {
+		sim.partitionMaxResource = pc.partitionMaxResource.Clone()
+	}
+	if pc.totalPartitionResource != nil {
+		sim.totalPartitionResource = pc.totalPartitionResource.Clone()
+		sim.updateRootMax()
+	}
+	for nodeID, node := range pc.nodes {
+		sim.nodes[nodeID] = node.Clone()
+		sim.addNodeLabels(nodeID, node.GetAttributes())
+	}
+	for appID, app := range pc.applications {
+		queueName := app.GetQueueName()
+		queue := sim.getQueue(queueName)
+		if queue == nil {
+			if queue, err = sim.createQueue(queueName, app.GetUser()); err != nil {
+				return nil, err
+			}
+		}
+		clone := objects.NewApplication(appID, app.Partition, queueName, app.GetUser(), app.GetTags(), nil, pc.RmID)
+		clone.SetSimulation()
+		// set before adding the asks: adding an ask applies the offset of the app to the ask
+		clone.SetPriorityClass(app.GetPriorityClassName(), app.GetPriorityOffset())
+		// constraints could have been changed after the app was created: adding an ask applies them to the ask
+		clone.SetSchedulingConstraints(app.GetSchedulingConstraints())
+		clone.SetQueue(queue)
+		if err = queue.AddApplication(clone); err != nil {
+			return nil, err
+		}
+		sim.applications[appID] = clone
+		for _, alloc := range app.GetAllAllocations() {
+			if err = queue.IncAllocatedResource(alloc.AllocatedResource, true); err != nil {
+				return nil, err
+			}
+			// use the copy of the allocation made by the node clone
+			var allocClone *objects.Allocation
+			if node := sim.nodes[alloc.NodeID]; node != nil {
+				allocClone = node.GetAllocation(alloc.UUID)
+			}
+			if allocClone == nil {
+				allocClone = alloc.Clone()
+				if alloc.Ask != nil {
+					allocClone.Ask = alloc.Ask.Clone()
+				}
+			}
+			clone.AddAllocation(allocClone)
+			sim.allocations[alloc.UUID] = allocClone
+		}
+		for _, ask := range app.GetPendingAsks() {
+			if err = clone.AddAllocationAsk(ask.Clone()); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return sim, nil
+}
+
+// Check that the UUID is not used by an existing allocation and was not handed out recently.
+// The UUID is remembered in the cache if it is unique.
+// Lock free call this must be called holding the context lock
+func (pc *PartitionContext) isUniqueUUID(uuid string) bool {
+	if _, found := pc.allocations[uuid]; found {
+		return false
+	}
+	return pc.uuidCache.Add(uuid)
+}
+
 // Process the reservation in the scheduler
 // Lock free call this must be called holding the context lock
 func (pc *PartitionContext) reserve(app *objects.Application, node *objects.Node, ask *objects.AllocationAsk) {
@@ -834,27 +1615,281 @@ func (pc *PartitionContext) unReserve(app *objects.Application, node *objects.No
 		zap.Int("reservationsRemoved", num))
 }
 
+// Remove all reservations in the partition that are older than the threshold.
+// Reservations that are not converted into an allocation, for instance because the node has become
+// unschedulable, would otherwise block the node and the ask.
+// Returns the number of reservations removed.
+func (pc *PartitionContext) RemoveStaleReservations(threshold time.Duration) int {
+	return pc.removeStaleReservations(threshold, time.Now())
+}
+
+// Remove all reservations in the partition that are older than the threshold at the time passed in.
+func (pc *PartitionContext) removeStaleReservations(threshold time.Duration, now time.Time) int {
+	pc.Lock()
+	defer pc.Unlock()
+	var removed int
+	for appID := range pc.reservedApps {
+		app := pc.applications[appID]
+		if app == nil {
+			continue
+		}
+		// this removes the reservations from the app, node and queue
+		if num := app.RemoveStaleReservations(threshold, now); num != 0 {
+			pc.unReserveCount(appID, num)
+			removed += num
+		}
+	}
+	return removed
+}
+
+// Remove the allocations from the partition that are no longer tracked by their application or node.
+// An allocation is stale if the application or the node is not part of the partition, or if the application
+// or the node does not have the allocation anymore. Only the partition entry is removed, the application and
+// the node are not changed. Returns the number of allocations removed.
+func (pc *PartitionContext) CompactAllocations() int {
+	pc.Lock()
+	defer pc.Unlock()
+	var removed int
+	for uuid, alloc := range pc.allocations {
+		app := pc.applications[alloc.ApplicationID]
+		node := pc.nodes[alloc.NodeID]
+		if app != nil && app.GetAllocation(uuid) != nil && node != nil && node.GetAllocation(uuid) != nil {
+			continue
+		}
+		log.Logger().Info("removing stale allocation from partition",
+			zap.String("partitionName", pc.Name),
+			zap.String("applicationID", alloc.ApplicationID),
+			zap.String("nodeID", alloc.NodeID),
+			zap.String("allocationUUID", uuid))
+		delete(pc.allocations, uuid)
+		removed++
+	}
+	if removed != 0 {
+		metrics.GetSchedulerMetrics().AddCompactedAllocations(removed)
+	}
+	return removed
+}
+
+// Get the total resources held by reservations in the partition.
+func (pc *PartitionContext) GetReservedCapacity() *resources.Resource {
+	pc.RLock()
+	defer pc.RUnlock()
+	return pc.getReservedCapacity()
+}
+
+// Get the total resources held by reservations in the partition.
+// Lock free call this must be called holding the context lock
+func (pc *PartitionContext) getReservedCapacity() *resources.Resource {
+	reserved := resources.NewResource()
+	for appID := range pc.reservedApps {
+		app := pc.applications[appID]
+		if app == nil {
+			continue
+		}
+		for _, res := range app.GetReservedNodes() {
+			reserved.AddTo(res)
+		}
+	}
+	return reserved
+}
+
+// Get the fraction of the partition resources held by reservations per resource type.
+// Resource types that have no total resources in the partition are not included.
+func (pc *PartitionContext) GetReservedCapacityFraction() map[string]float64 {
+	pc.RLock()
+	defer pc.RUnlock()
+	fractions := make(map[string]float64)
+	if pc.totalPartitionResource == nil {
+		return fractions
+	}
+	reserved := pc.getReservedCapacity()
+	for name, total := range pc.totalPartitionResource.Resources {
+		if total == 0 {
+			continue
+		}
+		fractions[name] = float64(reserved.Resources[name]) / float64(total)
+	}
+	return fractions
+}
+
+// Check for each pending ask in the partition if there is at least one schedulable node that could
+// satisfy the ask. Only the capacity of the node is considered, current allocations on the node are ignored.
+// A false value means that the ask can never be satisfied with the current nodes.
+// The map is keyed on the allocation key of the ask.
+func (pc *PartitionContext) GetPendingAskSatisfiability() map[string]bool {
+	nodes := pc.getNodes(false)
+	capacities := make([]*resources.Resource, len(nodes))
+	for i, node := range nodes {
+		capacities[i] = node.GetCapacity()
+	}
+	result := make(map[string]bool)
+	for _, app := range pc.GetApplications() {
+		for _, ask := range app.GetPendingAsks() {
+			satisfiable := false
+			for _, capacity := range capacities {
+				if resources.FitIn(capacity, ask.AllocatedResource) {
+					satisfiable = true
+					break
+				}
+			}
+			result[ask.AllocationKey] = satisfiable
+		}
+	}
+	return result
+}
+
 // Get the iterator for the sorted nodes list from the partition.
 // Sorting should use a copy of the node list not the main list.
-func (pc *PartitionContext) getNodeIteratorForPolicy(nodes []*objects.Node) interfaces.NodeIterator {
+func (pc *PartitionContext) getNodeIteratorForPolicy(nodes []*objects.Node, ask *objects.AllocationAsk) interfaces.NodeIterator {
 	pc.RLock()
-	configuredPolicy := pc.nodeSortingPolicy.PolicyType
+	configuredPolicy := pc.nodeSortingPolicy
 	pc.RUnlock()
-	if configuredPolicy == policies.Unknown {
+	if configuredPolicy.PolicyType == policies.Unknown {
 		return nil
 	}
 	// Sort Nodes based on the policy configured.
-	objects.SortNodes(nodes, configuredPolicy)
+	objects.SortNodes(nodes, configuredPolicy, ask)
+	// fair sorting should put the least utilised nodes first
+	if configuredPolicy.PolicyType == policies.FairnessPolicy && len(nodes) != 0 && log.IsDebugEnabled() {
+		minNode, minUtil := getNodeUtilizationExtreme(nodes, false)
+		maxNode, maxUtil := getNodeUtilizationExtreme(nodes, true)
+		log.Logger().Debug("fair node sorting result",
+			zap.String("partitionName", pc.Name),
+			zap.String("firstNode", nodes[0].NodeID),
+			zap.Float64("firstUtilization", nodes[0].GetDominantUtilization()),
+			zap.String("minNode", minNode),
+			zap.Float64("minUtilization", minUtil),
+			zap.String("maxNode", maxNode),
+			zap.Float64("maxUtilization", maxUtil))
+	}
 	return newDefaultNodeIterator(nodes)
 }
 
 // Create a node iterator for the schedulable nodes based on the policy set for this partition.
+// If the ask requires a topology value only the nodes with that value are iterated over.
+// The nodes that do not pass the scheduling constraints of the application are filtered out.
+// If the ask has a topology spread constraint the nodes that would break the constraint are filtered out
+// after sorting. The ask may be nil, in which case no filtering is performed.
 // The iterator is nil if there are no schedulable nodes available.
-func (pc *PartitionContext) GetNodeIterator() interfaces.NodeIterator {
-	if nodeList := pc.getSchedulableNodes(); len(nodeList) != 0 {
-		return pc.getNodeIteratorForPolicy(nodeList)
+func (pc *PartitionContext) GetNodeIterator(ask *objects.AllocationAsk) interfaces.NodeIterator {
+	var nodeList []*objects.Node
+	if ask != nil && ask.HasRequiredTopology() {
+		nodeList = pc.getSchedulableNodesByTopology(ask.RequiredTopologyKey, ask.RequiredTopologyValue)
+	} else {
+		nodeList = pc.getSchedulableNodes()
 	}
-	return nil
+	if ask != nil {
+		if constraints := ask.GetSchedulingConstraints(); constraints != nil {
+			nodeList = filterSchedulingConstraints(ask.ApplicationID, constraints, nodeList)
+		}
+	}
+	if len(nodeList) == 0 {
+		return nil
+	}
+	iterator := pc.getNodeIteratorForPolicy(nodeList, ask)
+	if iterator == nil || ask == nil || !ask.HasTopologySpread() {
+		return iterator
+	}
+	if nodeList = pc.filterTopologySpread(ask, nodeList); len(nodeList) == 0 {
+		return nil
+	}
+	return newDefaultNodeIterator(nodeList)
+}
+
+// Filter the node list based on the scheduling constraints of the application.
+// Nodes without the required labels or with a forbidden label are removed. If less nodes than the required node
+// count remain no node is returned. Nodes that already have the maximum number of allocations of the application
+// are removed last. The order of the nodes is not changed.
+func filterSchedulingConstraints(appID string, constraints *objects.SchedulingConstraints, nodes []*objects.Node) []*objects.Node {
+	matched := make([]*objects.Node, 0, len(nodes))
+	for _, node := range nodes {
+		if constraints.MatchesLabels(node) {
+			matched = append(matched, node)
+		}
+	}
+	if len(matched) < constraints.RequiredNodeCount {
+		return nil
+	}
+	if constraints.MaxAllocationsPerNode <= 0 {
+		return matched
+	}
+	filtered := make([]*objects.Node, 0, len(matched))
+	for _, node := range matched {
+		if node.GetApplicationAllocationCount(appID) < constraints.MaxAllocationsPerNode {
+			filtered = append(filtered, node)
+		}
+	}
+	return filtered
+}
+
+// Create a node iterator for the schedulable nodes that have the topology value set for the topology key.
+// The nodes are sorted based on the policy set for this partition.
+// The iterator is nil if there are no schedulable nodes with the topology value.
+func (pc *PartitionContext) GetTopologyAwareIterator(topologyKey, value string) interfaces.NodeIterator {
+	nodeList := pc.getSchedulableNodesByTopology(topologyKey, value)
+	if len(nodeList) == 0 {
+		return nil
+	}
+	return pc.getNodeIteratorForPolicy(nodeList, nil)
+}
+
+// Get the schedulable nodes that have the topology value set for the topology key.
+func (pc *PartitionContext) getSchedulableNodesByTopology(topologyKey, value string) []*objects.Node {
+	nodes := pc.getSchedulableNodes()
+	filtered := make([]*objects.Node, 0, len(nodes))
+	for _, node := range nodes {
+		if node.GetAttribute(topologyKey) == value {
+			filtered = append(filtered, node)
+		}
+	}
+	return filtered
+}
+
+// Filter the sorted node list based on the topology spread constraint of the ask.
+// A node is removed if an allocation on that node would cause the difference between the number of
+// allocations of the application for the node's topology value and the lowest number of allocations
+// for any topology value to exceed the maximum skew of the ask.
+// Nodes that do not have the topology key set are removed. The order of the nodes is not changed.
+func (pc *PartitionContext) filterTopologySpread(ask *objects.AllocationAsk, nodes []*objects.Node) []*objects.Node {
+	// use all topology values known in the partition, not just the schedulable nodes
+	groups := pc.getNodesByTopology(ask.TopologyKey)
+	if len(groups) == 0 {
+		return nil
+	}
+	counts := make(map[string]int, len(groups))
+	minCount := -1
+	for value, groupNodes := range groups {
+		count := 0
+		for _, node := range groupNodes {
+			for _, alloc := range node.GetAllAllocations() {
+				if alloc.ApplicationID == ask.ApplicationID {
+					count++
+				}
+			}
+		}
+		counts[value] = count
+		if minCount == -1 || count < minCount {
+			minCount = count
+		}
+	}
+	filtered := make([]*objects.Node, 0, len(nodes))
+	for _, node := range nodes {
+		value := node.GetAttribute(ask.TopologyKey)
+		if value == "" {
+			continue
+		}
+		if counts[value]+1-minCount > ask.MaxSkew {
+			continue
+		}
+		filtered = append(filtered, node)
+	}
+	return filtered
+}
+
+// Get all nodes in the partition that have the topology key set to the value.
+// The list includes reserved and unschedulable nodes.
+func (pc *PartitionContext) getNodesByTopologyValue(topologyKey, value string) []*objects.Node {
+	return pc.getNodesByTopology(topologyKey)[value]
 }
 
 // Update the reservation counter for the app
@@ -912,6 +1947,147 @@ func (pc *PartitionContext) GetApplications() []*objects.Application {
 	return appList
 }
 
+// Return the applications owned by the user or by a member of the group.
+// An empty user or group is not used in the match, if both are empty all applications are returned.
+func (pc *PartitionContext) GetApplicationsByUserGroup(user, group string) []*objects.Application {
+	pc.RLock()
+	defer pc.RUnlock()
+	var appList []*objects.Application
+	for _, app := range pc.applications {
+		if matchesUserGroup(app.GetUser(), user, group) {
+			appList = append(appList, app)
+		}
+	}
+	return appList
+}
+
+// Return true if the user or group match the owner of an application.
+func matchesUserGroup(owner security.UserGroup, user, group string) bool {
+	if user == "" && group == "" {
+		return true
+	}
+	if user != "" && owner.User == user {
+		return true
+	}
+	if group != "" {
+		for _, name := range owner.Groups {
+			if name == group {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Return the schedulable node with the largest free fraction of its dominant resource.
+// The dominant resource is the resource type with the smallest free fraction on the node.
+// Reserved and unschedulable nodes, and nodes without capacity, are not considered. Ties are
+// broken on the node ID to return a consistent result. Returns nil if there is no node that
+// can be considered.
+func (pc *PartitionContext) GetLargestFreeNode() *objects.Node {
+	var largest *objects.Node
+	var largestFree float64
+	for _, node := range pc.getSchedulableNodes() {
+		capacity := node.GetCapacity()
+		if capacity == nil {
+			continue
+		}
+		available := node.GetAvailableResource()
+		found := false
+		free := 1.0
+		for name, total := range capacity.Resources {
+			if total <= 0 {
+				continue
+			}
+			found = true
+			free = math.Min(free, float64(available.Resources[name])/float64(total))
+		}
+		if !found {
+			continue
+		}
+		if largest == nil || free > largestFree || (free == largestFree && node.NodeID < largest.NodeID) {
+			largest = node
+			largestFree = free
+		}
+	}
+	return largest
+}
+
+// Return the schedulable node with the largest available quantity of the resource type.
+// Reserved and unschedulable nodes, and nodes without capacity for the resource type, are not
+// considered. Ties are broken on the node ID to return a consistent result.
+// Returns nil if there is no node that can be considered.
+func (pc *PartitionContext) GetLargestFreeNodeByResource(resourceType string) *objects.Node {
+	var largest *objects.Node
+	var largestFree resources.Quantity
+	for _, node := range pc.getSchedulableNodes() {
+		if capacity := node.GetCapacity(); capacity == nil || capacity.Resources[resourceType] <= 0 {
+			continue
+		}
+		free := node.GetAvailableResource().Resources[resourceType]
+		if largest == nil || free > largestFree || (free == largestFree && node.NodeID < largest.NodeID) {
+			largest = node
+			largestFree = free
+		}
+	}
+	return largest
+}
+
+// Return the schedulable node with the highest dominant utilisation and that utilisation.
+// Reserved nodes are included. Ties are broken on the node ID to return a consistent result.
+// Returns an empty node ID if there are no schedulable nodes.
+func (pc *PartitionContext) GetMaxNodeUtilization() (nodeID string, utilization float64) {
+	return getNodeUtilizationExtreme(pc.getNodes(false), true)
+}
+
+// Return the schedulable node with the lowest dominant utilisation and that utilisation.
+// Reserved nodes are included. Ties are broken on the node ID to return a consistent result.
+// Returns an empty node ID if there are no schedulable nodes.
+func (pc *PartitionContext) GetMinNodeUtilization() (nodeID string, utilization float64) {
+	return getNodeUtilizationExtreme(pc.getNodes(false), false)
+}
+
+// Return the node from the list with the highest or lowest dominant utilisation.
+func getNodeUtilizationExtreme(nodes []*objects.Node, highest bool) (nodeID string, utilization float64) {
+	for _, node := range nodes {
+		util := node.GetDominantUtilization()
+		if nodeID == "" || (highest && util > utilization) || (!highest && util < utilization) ||
+			(util == utilization && node.NodeID < nodeID) {
+			nodeID = node.NodeID
+			utilization = util
+		}
+	}
+	return nodeID, utilization
+}
+
+// Return the allocation density of each node in the partition keyed by node ID.
+// The density is the number of allocations on the node per dominant share of the node capacity: many small
+// allocations give a high density, a few large allocations a low density. Nodes without capacity are not included.
+func (pc *PartitionContext) GetNodeAllocationDensity() map[string]float64 {
+	density := make(map[string]float64)
+	for _, node := range pc.GetNodes() {
+		if resources.IsZero(node.GetCapacity()) {
+			continue
+		}
+		density[node.NodeID] = node.GetAllocationDensity()
+	}
+	return density
+}
+
+// Return the average allocation density over all nodes in the partition that have capacity.
+// Returns 0 if there are no nodes with capacity.
+func (pc *PartitionContext) GetPartitionAllocationDensity() float64 {
+	density := pc.GetNodeAllocationDensity()
+	if len(density) == 0 {
+		return 0
+	}
+	var total float64
+	for _, value := range density {
+		total += value
+	}
+	return total / float64(len(density))
+}
+
 func (pc *PartitionContext) GetNodes() []*objects.Node {
 	pc.RLock()
 	defer pc.RUnlock()
@@ -926,7 +2102,10 @@ func (pc *PartitionContext) GetNodes() []*objects.Node {
 // Queue max allocation is not checked as the allocation is part of a new node addition.
 //
 // NOTE: this is a lock free call. It should only be called holding the Partition lock.
-func (pc *PartitionContext) addAllocation(alloc *objects.Allocation) error {
+func (pc *PartitionContext) addAllocation(alloc *objects.Allocation) (err error) {
+	defer func() {
+		pc.countRecovery(err == nil)
+	}()
 	if pc.isStopped() {
 		return fmt.Errorf("partition %s is stopped cannot add new allocation %s", pc.Name, alloc.AllocationKey)
 	}
@@ -977,10 +2156,28 @@ func (pc *PartitionContext) addAllocation(alloc *objects.Allocation) error {
 			alloc.ApplicationID, err)
 	}
 
-	node.AddAllocation(alloc)
-	app.RecoverAllocationAsk(alloc.Ask)
+	// the existing allocations cannot take more than the capacity of the node
+	if !node.AddAllocation(alloc) {
+		metrics.GetSchedulerMetrics().IncSchedulingError()
+		if err := queue.DecAllocatedResource(alloc.AllocatedResource); err != nil {
+			log.Logger().Warn("failed to release resources from queue",
+				zap.String("appID", alloc.ApplicationID),
+				zap.Error(err))
+		}
+		err := fmt.Errorf("allocation %s does not fit on node %s: available %v, requested %v",
+			alloc.UUID, node.NodeID, node.GetAvailableResource(), alloc.AllocatedResource)
+		log.AuditLog().Audit(log.AuditNodeRejected, map[string]string{
+			"partition":     pc.Name,
+			"nodeID":        node.NodeID,
+			"applicationID": alloc.ApplicationID,
+			"reason":        err.Error(),
+		})
+		return err
+	}
 	app.AddAllocation(alloc)
+	app.RecoverAllocationAsk(alloc.Ask)
 	pc.allocations[alloc.UUID] = alloc
+	pc.addNodeEventInternal(alloc.NodeID, NodeAllocationAdded, alloc.AllocatedResource)
 
 	log.Logger().Debug("recovered allocation",
 		zap.String("partitionName", pc.Name),
@@ -1008,31 +2205,285 @@ func (pc *PartitionContext) convertUGI(ugi *si.UserGroupInformation) (security.U
 // the element value represents number of nodes fall into this bucket.
 // if slice[9] = 3, this means there are 3 nodes resource usage is in the range 80% to 90%.
 func (pc *PartitionContext) CalculateNodesResourceUsage() map[string][]int {
+	return pc.GetUtilizationHistogram(10)
+}
+
+// Return the node utilisation histogram per resource type using the given number of buckets.
+// The range [0,1] is divided evenly: bucket i covers the range (i/buckets, (i+1)/buckets], a node
+// without usage is in the first bucket and an over allocated node ends up in the last bucket.
+// Returns nil if the bucket count is outside the supported range of 2 to 100.
+func (pc *PartitionContext) GetUtilizationHistogram(buckets int) map[string][]int {
+	if buckets < 2 || buckets > 100 {
+		return nil
+	}
 	pc.RLock()
 	defer pc.RUnlock()
 	mapResult := make(map[string][]int)
 	for _, node := range pc.nodes {
 		for name, total := range node.GetCapacity().Resources {
-			if float64(total) > 0 {
-				resourceAllocated := float64(node.GetAllocatedResource().Resources[name])
-				v := resourceAllocated / float64(total)
-				idx := int(math.Dim(math.Ceil(v*10), 1))
-				if dist, ok := mapResult[name]; !ok {
-					newDist := make([]int, 10)
-					for i := range newDist {
-						newDist[i] = 0
-					}
-					mapResult[name] = newDist
-					mapResult[name][idx]++
-				} else {
-					dist[idx]++
-				}
+			if total <= 0 {
+				continue
+			}
+			dist, ok := mapResult[name]
+			if !ok {
+				dist = make([]int, buckets)
+				mapResult[name] = dist
 			}
+			dist[utilizationBucket(node.GetResourceUtilization(name), buckets)]++
 		}
 	}
 	return mapResult
 }
 
+// Return the bucket index for the utilisation value.
+// Values within floating point error of a bucket boundary are treated as the boundary itself,
+// this makes sure that for instance 0.3 with 10 buckets lands in the third bucket.
+func utilizationBucket(utilization float64, buckets int) int {
+	scaled := utilization * float64(buckets)
+	if rounded := math.Round(scaled); math.Abs(scaled-rounded) < 1e-9 {
+		scaled = rounded
+	}
+	return int(math.Min(math.Dim(math.Ceil(scaled), 1), float64(buckets-1)))
+}
+
+// The reservation age buckets in order, each bucket covers the ages below its limit.
+// The last bucket has no limit.
+var reservationAgeBuckets = []struct {
+	name  string
+	limit time.Duration
+}{
+	{"<1s", time.Second},
+	{"1s-10s", 10 * time.Second},
+	{"10s-60s", time.Minute},
+	{"1m-10m", 10 * time.Minute},
+	{">10m", 0},
+}
+
+// Return the number of current reservations per age bucket.
+// All buckets are always present in the returned map. Very old reservations are likely to be for a stuck application.
+func (pc *PartitionContext) GetReservationAgeHistogram() map[string]int {
+	return pc.getReservationAgeHistogram(time.Now())
+}
+
+// Return the number of reservations per age bucket, with the age calculated relative to now.
+func (pc *PartitionContext) getReservationAgeHistogram(now time.Time) map[string]int {
+	pc.RLock()
+	defer pc.RUnlock()
+	histogram := make(map[string]int, len(reservationAgeBuckets))
+	for _, bucket := range reservationAgeBuckets {
+		histogram[bucket.name] = 0
+	}
+	for appID := range pc.reservedApps {
+		app := pc.applications[appID]
+		if app == nil {
+			continue
+		}
+		for _, reservedAt := range app.GetReservationTimes() {
+			histogram[reservationAgeBucket(now.Sub(reservedAt))]++
+		}
+	}
+	return histogram
+}
+
+// Return the name of the bucket the reservation age falls in.
+func reservationAgeBucket(age time.Duration) string {
+	last := len(reservationAgeBuckets) - 1
+	for _, bucket := range reservationAgeBuckets[:last] {
+		if age < bucket.limit {
+			return bucket.name
+		}
+	}
+	return reservationAgeBuckets[last].name
+}
+
+// Return a copy of the allocation with the UUID, nil if the allocation is not found.
+func (pc *PartitionContext) GetAllocationByUUID(uuid string) *objects.Allocation {
+	pc.RLock()
+	defer pc.RUnlock()
+	return pc.allocations[uuid].Clone()
+}
+
+// Return a copy of an allocation for the application and allocation key, nil if the allocation is not found.
+// If the ask was allocated multiple times the first allocation found is returned.
+func (pc *PartitionContext) GetAllocationByKey(appID, allocationKey string) *objects.Allocation {
+	pc.RLock()
+	defer pc.RUnlock()
+	app := pc.applications[appID]
+	if app == nil {
+		return nil
+	}
+	for _, alloc := range app.GetAllAllocations() {
+		if alloc.AllocationKey == allocationKey {
+			return alloc.Clone()
+		}
+	}
+	return nil
+}
+
+// Return the allocated resources of all queues in the partition keyed by the queue path.
+// Parent queues are included and track the sum of their children.
+func (pc *PartitionContext) GetAllocatedResourceByQueue() map[string]*resources.Resource {
+	return pc.getResourceByQueue((*objects.Queue).GetAllocatedResource)
+}
+
+// Return the pending resources of all queues in the partition keyed by the queue path.
+// Parent queues are included and track the sum of their children.
+func (pc *PartitionContext) GetPendingResourceByQueue() map[string]*resources.Resource {
+	return pc.getResourceByQueue((*objects.Queue).GetPendingResource)
+}
+
+// Return the number of allocations per second for all queues in the partition keyed by the queue path.
+// The rate is averaged over the window, which is truncated to whole seconds. Returns nil if the window is shorter
+// than a second or longer than the maximum window.
+func (pc *PartitionContext) GetAllocationRateByQueue(window time.Duration) map[string]float64 {
+	seconds := int64(window / time.Second)
+	if seconds < 1 || window > objects.MaxAllocationRateWindow {
+		return nil
+	}
+	pc.RLock()
+	defer pc.RUnlock()
+	result := make(map[string]float64)
+	visit := func(queue *objects.Queue) {
+		result[queue.GetQueuePath()] = float64(queue.GetAllocationCount(window)) / float64(seconds)
+	}
+	visit(pc.root)
+	pc.root.WalkDescendants(visit)
+	return result
+}
+
+// Return the load of all queues in the partition keyed by the queue path, see Queue.GetQueueLoad.
+// The map is keyed on the path, ordering of the output is left to the caller: the JSON encoding sorts the keys.
+func (pc *PartitionContext) GetHierarchicalQueueLoad() map[string]float64 {
+	pc.RLock()
+	defer pc.RUnlock()
+	result := make(map[string]float64)
+	visit := func(queue *objects.Queue) {
+		result[queue.GetQueuePath()] = queue.GetQueueLoad()
+	}
+	visit(pc.root)
+	pc.root.WalkDescendants(visit)
+	return result
+}
+
+// Return the queues that have the label set as a property with the value, sorted by queue path.
+// The wildcard value "*" matches all queues that have the label set. Inherited properties are not checked.
+func (pc *PartitionContext) GetQueueByLabel(labelKey, labelValue string) []*objects.Queue {
+	pc.RLock()
+	defer pc.RUnlock()
+	var queues []*objects.Queue
+	visit := func(queue *objects.Queue) {
+		if value, ok := queue.GetProperty(labelKey); ok && (labelValue == "*" || value == labelValue) {
+			queues = append(queues, queue)
+		}
+	}
+	visit(pc.root)
+	pc.root.WalkDescendants(visit)
+	sort.Slice(queues, func(i, j int) bool {
+		return queues[i].QueuePath < queues[j].QueuePath
+	})
+	return queues
+}
+
+// Return the number of queues at each depth of the hierarchy, the root queue is at depth 0.
+func (pc *PartitionContext) GetQueueDepthStats() map[int]int {
+	return pc.countQueuesByDepth(func(queue *objects.Queue) bool {
+		return true
+	})
+}
+
+// Return the number of leaf queues at each depth of the hierarchy.
+func (pc *PartitionContext) GetLeafQueueCountByDepth() map[int]int {
+	return pc.countQueuesByDepth(func(queue *objects.Queue) bool {
+		return queue.IsLeafQueue()
+	})
+}
+
+// Return the number of parent queues at each depth of the hierarchy.
+func (pc *PartitionContext) GetParentQueueCountByDepth() map[int]int {
+	return pc.countQueuesByDepth(func(queue *objects.Queue) bool {
+		return !queue.IsLeafQueue()
+	})
+}
+
+func (pc *PartitionContext) countQueuesByDepth(include func(queue *objects.Queue) bool) map[int]int {
+	pc.RLock()
+	defer pc.RUnlock()
+	result := make(map[int]int)
+	visit := func(queue *objects.Queue) {
+		if include(queue) {
+			result[queue.GetQueueDepth()]++
+		}
+	}
+	visit(pc.root)
+	pc.root.WalkDescendants(visit)
+	return result
+}
+
+func (pc *PartitionContext) getResourceByQueue(getResource func(queue *objects.Queue) *resources.Resource) map[string]*resources.Resource {
+	pc.RLock()
+	defer pc.RUnlock()
+	result := make(map[string]*resources.Resource)
+	visit := func(queue *objects.Queue) {
+		result[queue.GetQueuePath()] = getResource(queue).Clone()
+	}
+	visit(pc.root)
+	pc.root.WalkDescendants(visit)
+	return result
+}
+
+// Return copies of the running allocations that could be preempted to satisfy the ask, weakest first.
+// An allocation is a candidate if it has a lower priority than the ask, it runs in the subtree of the parent
+// of the ask's queue and its resources would fit the ask. Candidates are sorted by ascending priority, the most
+// recently created allocation first for equal priorities.
+// Allocations of the application that owns the ask are never returned.
+func (pc *PartitionContext) GetPreemptionCandidates(ask *objects.AllocationAsk) []*objects.Allocation {
+	if ask == nil {
+		return nil
+	}
+	pc.RLock()
+	defer pc.RUnlock()
+	var candidates []*objects.Allocation
+	for _, alloc := range pc.allocations {
+		if isPreemptionCandidate(ask, alloc) {
+			candidates = append(candidates, alloc.Clone())
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		left, right := getEffectivePriority(candidates[i]), getEffectivePriority(candidates[j])
+		if left != right {
+			return left < right
+		}
+		return candidates[i].GetCreateTime().After(candidates[j].GetCreateTime())
+	})
+	return candidates
+}
+
+// Return true if the allocation could be preempted to satisfy the ask: the allocation has a lower effective priority than
+// the effective priority of the ask, runs in the subtree of the parent of the ask's queue, is not owned by the application of the ask and
+// its resources would fit the ask.
+func isPreemptionCandidate(ask *objects.AllocationAsk, alloc *objects.Allocation) bool {
+	if getEffectivePriority(alloc) >= ask.GetEffectivePriority() || alloc.ApplicationID == ask.ApplicationID {
+		return false
+	}
+	subtree := ask.QueueName
+	if idx := strings.LastIndex(subtree, configs.DOT); idx != -1 {
+		subtree = subtree[:idx]
+	}
+	if alloc.QueueName != subtree && !strings.HasPrefix(alloc.QueueName, subtree+configs.DOT) {
+		return false
+	}
+	return resources.FitIn(alloc.AllocatedResource, ask.AllocatedResource)
+}
+
+// Return the priority of the allocation including the priority class offset of its ask.
+// Allocations without an ask only have the priority of the allocation.
+func getEffectivePriority(alloc *objects.Allocation) int32 {
+	if alloc.Ask == nil {
+		return alloc.Priority
+	}
+	return alloc.Ask.GetEffectivePriority()
+}
+
 func (pc *PartitionContext) removeAllocation(appID string, uuid string) []*objects.Allocation {
 	pc.Lock()
 	defer pc.Unlock()
@@ -1068,6 +2519,10 @@ func (pc *PartitionContext) removeAllocation(appID string, uuid string) []*objec
 		}
 		// remove from partition
 		delete(pc.allocations, alloc.UUID)
+		pc.addCompletedAllocation(alloc)
+		pc.addNodeEventInternal(alloc.NodeID, NodeAllocationRemoved, alloc.AllocatedResource)
+		pc.sendAllocationEventInternal(AllocationReleased, alloc)
+		pc.addQueueAllocationHistory(alloc, AllocationHistoryReleased)
 		// track total resources
 		total.AddTo(alloc.AllocatedResource)
 	}
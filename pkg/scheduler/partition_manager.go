@@ -39,9 +39,14 @@ type partitionManager struct {
 }
 
 // Run the manager for the partition.
-// The manager has two tasks:
-// - clean up the managed queues that are empty and removed from the configuration
-// - remove empty unmanaged queues
+// The manager has the following tasks:
+// - clean up the queues that are empty and draining: removed from the configuration or drained on request
+// - remove unmanaged queues without applications
+// - drop the histories of the oldest removed queues
+// - remove reservations that are older than the configured reservation timeout
+// - remove stale allocations from the partition every configured number of runs
+// - update the scheduler backlog metric of the partition
+// - check the applications against the user quotas and update the quota violation metric
 // When the manager exits the partition is removed from the system and must be cleaned up
 func (manager partitionManager) Run() {
 	if manager.interval == 0 {
@@ -52,10 +57,18 @@ func (manager partitionManager) Run() {
 		zap.String("partition", manager.pc.Name),
 		zap.String("interval", manager.interval.String()))
 	// exit only when the partition this manager belongs to exits
+	runs := 0
 	for {
 		time.Sleep(manager.interval)
 		runStart := time.Now()
+		runs++
+		manager.cleanDynamicQueues()
 		manager.cleanQueues(manager.pc.root)
+		manager.pc.pruneQueueHistories()
+		manager.cleanReservations()
+		manager.compactAllocations(runs)
+		manager.pc.updateBacklogMetric()
+		manager.pc.GetApplicationsExceedingQuota()
 		if manager.stop {
 			break
 		}
@@ -71,7 +84,17 @@ func (manager partitionManager) Stop() {
 	manager.stop = true
 }
 
-// Remove drained managed and empty unmanaged queues. Perform the action recursively.
+// Remove the unmanaged queues that have no applications and allocations left.
+func (manager partitionManager) cleanDynamicQueues() {
+	if removed := manager.pc.root.RemoveDynamicChildrenWithNoApps(); removed != 0 {
+		log.Logger().Info("removed dynamic queues without applications",
+			zap.String("partitionName", manager.pc.Name),
+			zap.Int("queues", removed))
+	}
+}
+
+// Remove drained managed queues. Perform the action recursively.
+// Unmanaged queues are removed by cleanDynamicQueues.
 // Only called internally and recursive, no locking
 func (manager partitionManager) cleanQueues(queue *objects.Queue) {
 	if queue == nil {
@@ -84,7 +107,7 @@ func (manager partitionManager) cleanQueues(queue *objects.Queue) {
 		}
 	}
 	// when we have done the children (or have none) this queue might be removable
-	if queue.IsDraining() || !queue.IsManaged() {
+	if queue.IsDraining() {
 		log.Logger().Debug("removing queue",
 			zap.String("queueName", queue.QueuePath),
 			zap.String("partitionName", manager.pc.Name))
@@ -106,6 +129,35 @@ func (manager partitionManager) cleanQueues(queue *objects.Queue) {
 	}
 }
 
+// Remove the reservations that have not been converted into an allocation within the reservation timeout.
+// Nothing is removed if the timeout is not set for the partition.
+func (manager partitionManager) cleanReservations() {
+	timeout := manager.pc.getReservationTimeout()
+	if timeout == 0 {
+		return
+	}
+	if removed := manager.pc.RemoveStaleReservations(timeout); removed != 0 {
+		log.Logger().Info("removed stale reservations",
+			zap.String("partitionName", manager.pc.Name),
+			zap.Int("reservations", removed),
+			zap.Duration("timeout", timeout))
+	}
+}
+
+// Remove the stale allocations from the partition once every configured number of runs.
+// Nothing is removed if the compaction interval is not set for the partition.
+func (manager partitionManager) compactAllocations(run int) {
+	interval := manager.pc.getAllocCompactionInterval()
+	if interval == 0 || run%interval != 0 {
+		return
+	}
+	if removed := manager.pc.CompactAllocations(); removed != 0 {
+		log.Logger().Warn("removed stale allocations",
+			zap.String("partitionName", manager.pc.Name),
+			zap.Int("allocations", removed))
+	}
+}
+
 // The partition has been removed from the configuration and must be removed.
 // Clean up all linked objects:
 // - queues
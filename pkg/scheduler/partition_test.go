@@ -19,15 +19,26 @@
 package scheduler
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"gotest.tools/assert"
 
+	"github.com/apache/incubator-yunikorn-core/pkg/common"
 	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
 	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
 	"github.com/apache/incubator-yunikorn-core/pkg/common/security"
+	"github.com/apache/incubator-yunikorn-core/pkg/interfaces"
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
+	"github.com/apache/incubator-yunikorn-core/pkg/metrics"
 	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/policies"
 )
 
 func TestNewPartition(t *testing.T) {
@@ -243,6 +254,168 @@ func TestAddNodeWithAllocations(t *testing.T) {
 	assert.Assert(t, resources.Equals(q.GetAllocatedResource(), appRes), "add node to partition did not update queue as expected")
 }
 
+// parse the audit log lines written to the buffer
+func readAuditLog(t *testing.T, buf *bytes.Buffer) []map[string]string {
+	entries := make([]map[string]string, 0)
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]string
+		err := json.Unmarshal([]byte(line), &entry)
+		assert.NilError(t, err, "audit log line is not valid JSON: %s", line)
+		entries = append(entries, entry)
+	}
+	buf.Reset()
+	return entries
+}
+
+func TestAuditLog(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetAuditLogger(log.NewAuditLogger(&buf))
+	defer log.SetAuditLogger(nil)
+
+	conf := configs.PartitionConfig{
+		Name: "test",
+		Queues: []configs.QueueConfig{
+			{
+				Name:   "root",
+				Parent: true,
+				Queues: []configs.QueueConfig{
+					{Name: "open", SubmitACL: "*"},
+					{Name: "restricted", SubmitACL: "admin"},
+				},
+			},
+		},
+	}
+	partition, err := newPartitionContext(conf, rmID, nil)
+	assert.NilError(t, err, "partition create failed")
+
+	// ACL deny
+	user := security.UserGroup{User: "testuser"}
+	app := objects.NewApplication(appID1, "default", "root.restricted", user, nil, nil, rmID)
+	err = partition.AddApplication(app)
+	if err == nil {
+		t.Fatal("add application without submit access should have failed")
+	}
+	entries := readAuditLog(t, &buf)
+	assert.Equal(t, len(entries), 1, "expected one audit event for the ACL deny")
+	assert.Equal(t, entries[0]["event"], log.AuditACLDenied, "unexpected event type")
+	assert.Equal(t, entries[0]["applicationID"], appID1, "unexpected application")
+	assert.Equal(t, entries[0]["queue"], "root.restricted", "unexpected queue")
+	assert.Equal(t, entries[0]["user"], "testuser", "unexpected user")
+	assert.Equal(t, entries[0]["partition"], partition.Name, "unexpected partition")
+
+	// node not added for other reasons than the node capacity: no audit event
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	node := newNodeMaxResource(nodeID1, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10}))
+	alloc := objects.NewAllocation("alloc-1-uuid", nodeID1, newAllocationAsk("alloc-1", "unknown", res))
+	err = partition.AddNode(node, []*objects.Allocation{alloc})
+	if err == nil {
+		t.Fatal("add node with unknown application allocation should have failed")
+	}
+	entries = readAuditLog(t, &buf)
+	assert.Equal(t, len(entries), 0, "unexpected audit event for an unknown application")
+
+	// node rejected: existing allocation larger than the node capacity
+	large := objects.NewApplication("app-large", "default", "root.open", user, nil, nil, rmID)
+	err = partition.AddApplication(large)
+	assert.NilError(t, err, "add application to partition should not have failed")
+	overCapacity := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 11})
+	alloc = objects.NewAllocation("alloc-2-uuid", nodeID1, newAllocationAsk("alloc-2", "app-large", overCapacity))
+	err = partition.AddNode(node, []*objects.Allocation{alloc})
+	if err == nil {
+		t.Fatal("add node with allocation larger than the node should have failed")
+	}
+	assert.Assert(t, resources.IsZero(large.GetQueue().GetAllocatedResource()), "queue usage not reverted")
+	entries = readAuditLog(t, &buf)
+	assert.Equal(t, len(entries), 1, "expected one audit event for the node rejection")
+	assert.Equal(t, entries[0]["event"], log.AuditNodeRejected, "unexpected event type")
+	assert.Equal(t, entries[0]["nodeID"], nodeID1, "unexpected node")
+	assert.Equal(t, entries[0]["applicationID"], "app-large", "unexpected application")
+	assert.Assert(t, entries[0]["reason"] != "", "rejection reason not set")
+
+	// successful allocation
+	err = partition.AddNode(node, nil)
+	assert.NilError(t, err, "add node to partition should not have failed")
+	app = objects.NewApplication(appID2, "default", "root.open", user, nil, nil, rmID)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "add application to partition should not have failed")
+	err = app.AddAllocationAsk(newAllocationAsk("alloc-1", appID2, res))
+	assert.NilError(t, err, "failed to add ask to app")
+	alloc = partition.tryAllocate()
+	if alloc == nil {
+		t.Fatal("allocation did not return any allocation")
+	}
+	entries = readAuditLog(t, &buf)
+	assert.Equal(t, len(entries), 1, "expected one audit event for the allocation")
+	assert.Equal(t, entries[0]["event"], log.AuditAllocation, "unexpected event type")
+	assert.Equal(t, entries[0]["applicationID"], appID2, "unexpected application")
+	assert.Equal(t, entries[0]["allocationKey"], "alloc-1", "unexpected allocation key")
+	assert.Equal(t, entries[0]["uuid"], alloc.UUID, "unexpected uuid")
+	assert.Equal(t, entries[0]["nodeID"], nodeID1, "unexpected node")
+	assert.Equal(t, entries[0]["queue"], "root.open", "unexpected queue")
+}
+
+func TestResizeNode(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	err = partition.ResizeNode(nodeID1, resources.NewResource())
+	assert.ErrorContains(t, err, "not found")
+
+	app := newApplication(appID1, "default", defQueue)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "add application to partition should not have failed")
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10})
+	allocRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5})
+	allocs := []*objects.Allocation{objects.NewAllocation("alloc-1-uuid", nodeID1, newAllocationAsk("alloc-1", appID1, allocRes))}
+	err = partition.AddNode(newNodeMaxResource(nodeID1, nodeRes), allocs)
+	assert.NilError(t, err, "add node to partition should not have failed")
+	err = partition.AddNode(newNodeMaxResource(nodeID2, nodeRes), nil)
+	assert.NilError(t, err, "add node to partition should not have failed")
+
+	// resize the partially allocated node up
+	newRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 20})
+	err = partition.ResizeNode(nodeID1, newRes)
+	assert.NilError(t, err, "resize up should not have failed")
+	node := partition.GetNode(nodeID1)
+	assert.Assert(t, resources.Equals(node.GetCapacity(), newRes), "node capacity not updated: %s", node.GetCapacity())
+	assert.Assert(t, resources.Equals(node.GetAvailableResource(), resources.NewResourceFromMap(map[string]resources.Quantity{"first": 15})), "node available not updated: %s", node.GetAvailableResource())
+	expected := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 30})
+	assert.Assert(t, resources.Equals(partition.GetTotalPartitionResource(), expected), "partition resource not updated: %s", partition.GetTotalPartitionResource())
+	assert.Assert(t, resources.Equals(partition.root.GetMaxResource(), expected), "root max not updated: %s", partition.root.GetMaxResource())
+	events := partition.GetNodeEventHistory(nodeID1)
+	assert.Equal(t, events[len(events)-1].Type, NodeResized, "resize event not recorded")
+
+	// resize below the allocated resources fails and changes nothing
+	err = partition.ResizeNode(nodeID1, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 4}))
+	assert.ErrorContains(t, err, "less than the allocated resources")
+	assert.Assert(t, resources.Equals(node.GetCapacity(), newRes), "node capacity should not have changed: %s", node.GetCapacity())
+	assert.Assert(t, resources.Equals(partition.GetTotalPartitionResource(), expected), "partition resource should not have changed: %s", partition.GetTotalPartitionResource())
+	assert.Assert(t, resources.Equals(partition.root.GetMaxResource(), expected), "root max should not have changed: %s", partition.root.GetMaxResource())
+
+	// resize down to the allocated resources
+	err = partition.ResizeNode(nodeID1, allocRes)
+	assert.NilError(t, err, "resize down to the allocation should not have failed")
+	expected = resources.NewResourceFromMap(map[string]resources.Quantity{"first": 15})
+	assert.Assert(t, resources.Equals(partition.GetTotalPartitionResource(), expected), "partition resource not updated: %s", partition.GetTotalPartitionResource())
+	assert.Assert(t, resources.Equals(partition.root.GetMaxResource(), expected), "root max not updated: %s", partition.root.GetMaxResource())
+
+	assert.Assert(t, !node.IsOverAllocated(), "node should not be over allocated")
+
+	// the RM is authoritative: a shrink below the allocated resources is applied
+	shrunk := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 2})
+	partition.updateNodeCapacity(nodeID1, shrunk)
+	assert.Assert(t, resources.Equals(node.GetCapacity(), shrunk), "node capacity not updated: %s", node.GetCapacity())
+	assert.Assert(t, node.IsOverAllocated(), "node should be over allocated")
+	expected = resources.NewResourceFromMap(map[string]resources.Quantity{"first": 12})
+	assert.Assert(t, resources.Equals(partition.GetTotalPartitionResource(), expected), "partition resource not updated: %s", partition.GetTotalPartitionResource())
+	assert.Assert(t, resources.Equals(partition.root.GetMaxResource(), expected), "root max not updated: %s", partition.root.GetMaxResource())
+	// unknown nodes are ignored
+	partition.updateNodeCapacity("unknown", shrunk)
+	assert.Assert(t, resources.Equals(partition.GetTotalPartitionResource(), expected), "partition resource should not have changed: %s", partition.GetTotalPartitionResource())
+}
+
 func TestRemoveNode(t *testing.T) {
 	partition, err := newBasePartition()
 	assert.NilError(t, err, "test partition create failed with error")
@@ -392,6 +565,229 @@ func TestAddApp(t *testing.T) {
 	}
 }
 
+func TestAddAppMaxConcurrent(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	leaf := partition.GetQueue(defQueue)
+	err = leaf.SetProperties(map[string]string{configs.MaxConcurrentApplications: "1"})
+	assert.NilError(t, err, "failed to set the concurrent application limit")
+
+	err = partition.AddApplication(newApplication(appID1, "default", defQueue))
+	assert.NilError(t, err, "add application to partition should not have failed")
+	app := newApplication(appID2, "default", defQueue)
+	err = partition.AddApplication(app)
+	assert.ErrorContains(t, err, "concurrent applications", "application over the limit should have been rejected")
+	assert.Assert(t, partition.getApplication(appID2) == nil, "rejected application should not be in the partition")
+	assert.Assert(t, app.GetQueue() == nil, "rejected application should not be linked to the queue")
+
+	partition.removeApplication(appID1)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "application should be accepted after a removal")
+
+	// a queue over a lowered limit can still be cloned for simulation
+	err = leaf.SetProperties(map[string]string{configs.MaxConcurrentApplications: "0"})
+	assert.NilError(t, err, "failed to remove the concurrent application limit")
+	err = partition.AddApplication(newApplication(appID1, "default", defQueue))
+	assert.NilError(t, err, "add application to partition should not have failed")
+	err = leaf.SetProperties(map[string]string{configs.MaxConcurrentApplications: "1"})
+	assert.NilError(t, err, "failed to set the concurrent application limit")
+	sim := partition.CloneForSimulation()
+	assert.Assert(t, sim != nil, "clone of a queue over the limit should have been created")
+	assert.Equal(t, len(sim.GetApplications()), 2, "applications not copied to the clone")
+}
+
+func TestBatchAddApplications(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	err = partition.AddApplication(newApplication("existing", "default", defQueue))
+	assert.NilError(t, err, "add application to partition should not have failed")
+
+	apps := []*objects.Application{
+		newApplication(appID1, "default", defQueue),
+		newApplication("existing", "default", defQueue),
+		newApplication("unknown-queue", "default", "root.unknown"),
+		nil,
+		newApplication(appID2, "default", defQueue),
+		newApplication("parent-queue", "default", "root"),
+		newApplication(appID1, "default", defQueue),
+	}
+	errs, added := partition.BatchAddApplications(apps)
+	assert.Equal(t, added, 2, "unexpected number of applications added")
+	assert.Equal(t, len(errs), len(apps), "error slice should match the applications")
+	for i, expectErr := range []bool{false, true, true, true, false, true, true} {
+		assert.Equal(t, errs[i] != nil, expectErr, "unexpected result for application %d: %v", i, errs[i])
+	}
+	assert.Equal(t, len(partition.GetApplications()), 3, "unexpected applications in the partition")
+	for _, appID := range []string{"existing", appID1, appID2} {
+		app := partition.getApplication(appID)
+		assert.Assert(t, app != nil, "application %s not found in the partition", appID)
+		assert.Assert(t, app.GetQueue() == partition.GetQueue(defQueue), "application %s not in the queue", appID)
+	}
+	for _, appID := range []string{"unknown-queue", "parent-queue"} {
+		assert.Assert(t, partition.getApplication(appID) == nil, "application %s should not have been added", appID)
+	}
+
+	// stopped partition rejects all
+	err = partition.handlePartitionEvent(objects.Stop)
+	assert.NilError(t, err, "partition state change failed unexpectedly")
+	errs, added = partition.BatchAddApplications([]*objects.Application{newApplication("app-3", "default", defQueue)})
+	assert.Equal(t, added, 0, "stopped partition should not add applications")
+	assert.Assert(t, errs[0] != nil, "stopped partition should have returned an error")
+}
+
+func TestGetApplicationQueuePath(t *testing.T) {
+	partition, err := newConfiguredPartition()
+	assert.NilError(t, err, "partition create failed")
+
+	_, err = partition.GetApplicationQueuePath(appID1)
+	if err == nil {
+		t.Error("unknown application should have returned an error")
+	}
+	app := newApplication(appID1, "default", "root.leaf")
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "add application to partition should not have failed")
+	var path string
+	path, err = partition.GetApplicationQueuePath(appID1)
+	assert.NilError(t, err, "queue path lookup should not have failed")
+	assert.Equal(t, path, "root.leaf", "unexpected queue path")
+
+	// move the application to a different queue
+	oldQueue := partition.GetQueue("root.leaf")
+	newQueue := partition.GetQueue("root.parent.sub-leaf")
+	assert.Assert(t, oldQueue != nil && newQueue != nil, "test queues not found")
+	oldQueue.RemoveApplication(app)
+	err = newQueue.AddApplication(app)
+	assert.NilError(t, err, "failed to add application to queue")
+	app.SetQueue(newQueue)
+	path, err = partition.GetApplicationQueuePath(appID1)
+	assert.NilError(t, err, "queue path lookup should not have failed")
+	assert.Equal(t, path, "root.parent.sub-leaf", "queue path not updated after move")
+
+	// removed application is not found
+	partition.removeApplication(appID1)
+	_, err = partition.GetApplicationQueuePath(appID1)
+	if err == nil {
+		t.Error("removed application should have returned an error")
+	}
+}
+
+func TestGetApplicationCreationTime(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	_, err = partition.GetApplicationCreationTime(appID1)
+	assert.ErrorContains(t, err, "not found", "unknown application should have returned an error")
+	_, err = partition.GetApplicationAge(appID1)
+	assert.ErrorContains(t, err, "not found", "unknown application should have returned an error")
+
+	before := time.Now()
+	app := newApplication(appID1, "default", "root.default")
+	after := time.Now()
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "add application to partition should not have failed")
+	var created time.Time
+	created, err = partition.GetApplicationCreationTime(appID1)
+	assert.NilError(t, err, "creation time lookup should not have failed")
+	assert.Assert(t, !created.Before(before) && !created.After(after), "creation time %v not set at construction", created)
+
+	// updating the application does not change the creation time
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 1})
+	err = app.AddAllocationAsk(newAllocationAsk("alloc-1", appID1, res))
+	assert.NilError(t, err, "ask should have been added to app")
+	app.SubmissionTime = time.Now().Add(time.Hour)
+	var updated time.Time
+	updated, err = partition.GetApplicationCreationTime(appID1)
+	assert.NilError(t, err, "creation time lookup should not have failed")
+	assert.Equal(t, updated, created, "creation time changed after application update")
+
+	var age time.Duration
+	age, err = partition.GetApplicationAge(appID1)
+	assert.NilError(t, err, "age lookup should not have failed")
+	assert.Assert(t, age >= 0 && age <= time.Since(before), "unexpected application age %v", age)
+}
+
+func TestGetApplicationsByUserGroup(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	assert.Equal(t, len(partition.GetApplicationsByUserGroup("", "")), 0, "empty partition should not return applications")
+
+	owners := map[string]security.UserGroup{
+		"app-alice": {User: "alice", Groups: []string{"engineering", "admin"}},
+		"app-bob":   {User: "bob", Groups: []string{"engineering"}},
+		"app-carol": {User: "carol", Groups: []string{"sales"}},
+		"app-none":  {},
+	}
+	for appID, ugi := range owners {
+		app := objects.NewApplication(appID, "default", defQueue, ugi, nil, nil, rmID)
+		err = partition.AddApplication(app)
+		assert.NilError(t, err, "add application %s to partition should not have failed", appID)
+	}
+	sortedIDs := func(user, group string) []string {
+		ids := appIDs(partition.GetApplicationsByUserGroup(user, group))
+		sort.Strings(ids)
+		return ids
+	}
+	// wildcard
+	assert.DeepEqual(t, sortedIDs("", ""), []string{"app-alice", "app-bob", "app-carol", "app-none"})
+	// user match
+	assert.DeepEqual(t, sortedIDs("alice", ""), []string{"app-alice"})
+	assert.Equal(t, len(sortedIDs("dave", "")), 0, "unknown user should not return applications")
+	// group match
+	assert.DeepEqual(t, sortedIDs("", "engineering"), []string{"app-alice", "app-bob"})
+	assert.DeepEqual(t, sortedIDs("", "admin"), []string{"app-alice"})
+	assert.Equal(t, len(sortedIDs("", "unknown")), 0, "unknown group should not return applications")
+	// combined match returns the applications of the user and the group
+	assert.DeepEqual(t, sortedIDs("carol", "engineering"), []string{"app-alice", "app-bob", "app-carol"})
+	assert.DeepEqual(t, sortedIDs("alice", "admin"), []string{"app-alice"})
+}
+
+func TestGetQueueByLabel(t *testing.T) {
+	conf := configs.PartitionConfig{
+		Name: "test",
+		Queues: []configs.QueueConfig{
+			{
+				Name:       "root",
+				Parent:     true,
+				SubmitACL:  "*",
+				Properties: map[string]string{"team": "platform"},
+				Queues: []configs.QueueConfig{
+					{
+						Name:       "prod",
+						Properties: map[string]string{"environment": "production"},
+					}, {
+						Name:       "test",
+						Properties: map[string]string{"environment": "testing"},
+					}, {
+						Name:       "parent",
+						Parent:     true,
+						Properties: map[string]string{"environment": "production"},
+						Queues: []configs.QueueConfig{
+							{
+								Name: "sub-leaf",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	partition, err := newPartitionContext(conf, rmID, nil)
+	assert.NilError(t, err, "partition create failed")
+	queuePaths := func(queues []*objects.Queue) []string {
+		paths := make([]string, len(queues))
+		for i, queue := range queues {
+			paths[i] = queue.QueuePath
+		}
+		return paths
+	}
+	// only the queues with the property set match, inherited properties do not match
+	assert.DeepEqual(t, queuePaths(partition.GetQueueByLabel("environment", "production")), []string{"root.parent", "root.prod"})
+	assert.DeepEqual(t, queuePaths(partition.GetQueueByLabel("environment", "testing")), []string{"root.test"})
+	assert.DeepEqual(t, queuePaths(partition.GetQueueByLabel("environment", "*")), []string{"root.parent", "root.prod", "root.test"})
+	assert.DeepEqual(t, queuePaths(partition.GetQueueByLabel("team", "platform")), []string{"root"})
+	assert.Equal(t, len(partition.GetQueueByLabel("environment", "staging")), 0, "unknown value should not match")
+	assert.Equal(t, len(partition.GetQueueByLabel("unknown", "*")), 0, "unknown label should not match")
+}
+
 func TestRemoveApp(t *testing.T) {
 	partition, err := newBasePartition()
 	assert.NilError(t, err, "partition create failed")
@@ -450,6 +846,95 @@ func TestRemoveApp(t *testing.T) {
 	}
 }
 
+func TestForceRemoveApplication(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetAuditLogger(log.NewAuditLogger(&buf))
+	defer log.SetAuditLogger(nil)
+
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	addAllocs := func(t *testing.T, partition *PartitionContext, app *objects.Application, count int) {
+		for i := 0; i < count; i++ {
+			key := fmt.Sprintf("alloc-%d", i)
+			alloc := objects.NewAllocation(key+"-uuid", nodeID1, newAllocationAsk(key, app.ApplicationID, res))
+			err := partition.addAllocation(alloc)
+			assert.NilError(t, err, "add allocation to partition should not have failed")
+		}
+	}
+	// rejected and completed applications are never tracked by the partition
+	tests := []struct {
+		state  string
+		allocs int
+		setup  func(t *testing.T, partition *PartitionContext, app *objects.Application)
+	}{
+		{"New", 0, func(t *testing.T, partition *PartitionContext, app *objects.Application) {}},
+		{"Accepted", 0, func(t *testing.T, partition *PartitionContext, app *objects.Application) {
+			err := app.AddAllocationAsk(newAllocationAsk("ask-1", app.ApplicationID, res))
+			assert.NilError(t, err, "failed to add ask to app")
+		}},
+		{"Starting", 2, func(t *testing.T, partition *PartitionContext, app *objects.Application) {
+			addAllocs(t, partition, app, 2)
+		}},
+		{"Running", 3, func(t *testing.T, partition *PartitionContext, app *objects.Application) {
+			addAllocs(t, partition, app, 3)
+		}},
+		{"Waiting", 0, func(t *testing.T, partition *PartitionContext, app *objects.Application) {
+			err := app.AddAllocationAsk(newAllocationAsk("ask-1", app.ApplicationID, res))
+			assert.NilError(t, err, "failed to add ask to app")
+			_ = app.RemoveAllocationAsk("")
+		}},
+		{"killed", 1, func(t *testing.T, partition *PartitionContext, app *objects.Application) {
+			addAllocs(t, partition, app, 1)
+			err := app.HandleApplicationEvent(objects.KillApplication)
+			assert.NilError(t, err, "failed to kill app")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.state, func(t *testing.T) {
+			partition, err := newBasePartition()
+			assert.NilError(t, err, "partition create failed")
+			node := newNodeMaxResource(nodeID1, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10}))
+			err = partition.AddNode(node, nil)
+			assert.NilError(t, err, "add node to partition should not have failed")
+			app := newApplication(appID1, "default", defQueue)
+			err = partition.AddApplication(app)
+			assert.NilError(t, err, "add application to partition should not have failed")
+			tt.setup(t, partition, app)
+			assert.Equal(t, app.CurrentState(), tt.state, "application not in expected state")
+			expected := app.GetAllAllocations()
+			buf.Reset()
+
+			allocs := partition.ForceRemoveApplication(appID1, "misbehaving")
+			assert.Equal(t, len(allocs), tt.allocs, "unexpected number of allocations returned")
+			assert.Equal(t, len(allocs), len(expected), "returned allocations do not match the application")
+			uuids := make(map[string]bool)
+			for _, alloc := range expected {
+				uuids[alloc.UUID] = true
+			}
+			for _, alloc := range allocs {
+				assert.Assert(t, uuids[alloc.UUID], "unexpected allocation returned: %s", alloc.UUID)
+			}
+			assert.Assert(t, partition.getApplication(appID1) == nil, "application not removed")
+			assert.Equal(t, len(partition.allocations), 0, "allocations not removed from the partition")
+			assert.Equal(t, len(node.GetAllAllocations()), 0, "allocations not removed from the node")
+
+			entries := readAuditLog(t, &buf)
+			assert.Equal(t, len(entries), 1, "expected one audit event for the force remove")
+			assert.Equal(t, entries[0]["event"], log.AuditForceRemoved, "unexpected event type")
+			assert.Equal(t, entries[0]["applicationID"], appID1, "unexpected application")
+			assert.Equal(t, entries[0]["state"], tt.state, "unexpected state")
+			assert.Equal(t, entries[0]["reason"], "misbehaving", "reason not recorded")
+		})
+	}
+
+	// unknown application: nothing removed and nothing audited
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	buf.Reset()
+	allocs := partition.ForceRemoveApplication("unknown", "misbehaving")
+	assert.Assert(t, allocs == nil, "unknown application returned allocations")
+	assert.Equal(t, buf.Len(), 0, "unknown application should not be audited")
+}
+
 func TestRemoveAppAllocs(t *testing.T) {
 	partition, err := newBasePartition()
 	assert.NilError(t, err, "partition create failed")
@@ -658,6 +1143,230 @@ func TestUpdateQueues(t *testing.T) {
 	}
 }
 
+func TestUpdateQueuesSortPolicy(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	root := partition.getQueue("root")
+	leaf := partition.getQueue(defQueue)
+	assert.Equal(t, leaf.GetApplicationSortingPolicy(), policies.FifoSortPolicy, "unexpected default policy")
+
+	conf := []configs.QueueConfig{
+		{
+			Name:       "default",
+			Parent:     false,
+			Properties: map[string]string{configs.ApplicationSortPolicy: "fair"},
+		},
+	}
+	err = partition.updateQueues(conf, root)
+	assert.NilError(t, err, "queue update from config failed")
+	assert.Equal(t, leaf.GetApplicationSortingPolicy(), policies.FairSortPolicy, "policy not updated from config")
+
+	// a runtime change is replaced by the config on the next reload
+	err = leaf.SetApplicationSortingPolicy(policies.StateAwarePolicy)
+	assert.NilError(t, err, "runtime policy update failed")
+	err = partition.updateQueues(conf, root)
+	assert.NilError(t, err, "queue update from config failed")
+	assert.Equal(t, leaf.GetApplicationSortingPolicy(), policies.FairSortPolicy, "policy not reset from config")
+}
+
+func TestUpdateQueueProperties(t *testing.T) {
+	partition := createQueuesNodes(t)
+	if partition == nil {
+		t.Fatal("partition create failed")
+	}
+	events := make(chan QueueEvent, 10)
+	partition.ListenForQueueEvents(events)
+	err := partition.UpdateQueueProperties("root.unknown", map[string]string{configs.ApplicationSortPolicy: "fair"})
+	assert.ErrorContains(t, err, "not found", "update of an unknown queue should have failed")
+	err = partition.UpdateQueueProperties("root.leaf", map[string]string{"unknown": "value"})
+	assert.ErrorContains(t, err, "unknown property", "update of an unknown property should have failed")
+	assert.Equal(t, len(events), 0, "failed updates should not send events")
+
+	// the oldest app gets the first allocation and has the highest usage: fifo and fair sort differently
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	for i, appID := range []string{appID1, appID2} {
+		app := newApplication(appID, "default", "root.leaf")
+		app.SubmissionTime = time.Now().Add(time.Duration(i) * time.Second)
+		err = partition.AddApplication(app)
+		assert.NilError(t, err, "failed to add %s to partition", appID)
+		err = app.AddAllocationAsk(newAllocationAskRepeat("alloc-"+appID, appID, res, 2))
+		assert.NilError(t, err, "failed to add ask to %s", appID)
+	}
+	alloc := partition.tryAllocate()
+	if alloc == nil {
+		t.Fatal("fifo allocation did not return any allocation")
+	}
+	assert.Equal(t, alloc.ApplicationID, appID1, "fifo should allocate to the oldest app")
+
+	err = partition.UpdateQueueProperties("root.leaf", map[string]string{configs.ApplicationSortPolicy: "fair"})
+	assert.NilError(t, err, "property update failed")
+	alloc = partition.tryAllocate()
+	if alloc == nil {
+		t.Fatal("fair allocation did not return any allocation")
+	}
+	assert.Equal(t, alloc.ApplicationID, appID2, "fair should allocate to the app with the lowest usage")
+
+	assert.Equal(t, len(events), 1, "expected one queue event")
+	event := <-events
+	assert.Equal(t, event.Type, QueuePropertyUpdated, "unexpected event type")
+	assert.Equal(t, event.QueuePath, "root.leaf", "unexpected queue in event")
+	assert.DeepEqual(t, event.Properties, map[string]string{configs.ApplicationSortPolicy: "fair"})
+
+	partition.StopListeningForQueueEvents(events)
+	err = partition.UpdateQueueProperties("root.leaf", map[string]string{configs.ApplicationSortPolicy: "fifo"})
+	assert.NilError(t, err, "property update failed")
+	assert.Equal(t, len(events), 0, "stopped listener should not receive events")
+}
+
+func TestGetAllocationRateByQueue(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	assert.Assert(t, partition.GetAllocationRateByQueue(500*time.Millisecond) == nil, "window below a second should be rejected")
+	assert.Assert(t, partition.GetAllocationRateByQueue(objects.MaxAllocationRateWindow+time.Second) == nil, "window above the maximum should be rejected")
+	assert.DeepEqual(t, partition.GetAllocationRateByQueue(time.Minute), map[string]float64{"root": 0, defQueue: 0})
+
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 100})
+	err = partition.AddNode(newNodeMaxResource(nodeID1, nodeRes), nil)
+	assert.NilError(t, err, "add node to partition should not have failed")
+	app := newApplication(appID1, "default", defQueue)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	err = app.AddAllocationAsk(newAllocationAskRepeat("alloc-1", appID1, res, 100))
+	assert.NilError(t, err, "failed to add ask alloc-1 to app-1")
+	for i := 0; i < 100; i++ {
+		if alloc := partition.tryAllocate(); alloc == nil {
+			t.Fatalf("allocation %d did not return any allocation", i)
+		}
+	}
+	// all 100 allocations fall in a 2 second window
+	rates := partition.GetAllocationRateByQueue(2 * time.Second)
+	assert.Equal(t, rates[defQueue], 50.0, "unexpected allocation rate for the leaf queue")
+	assert.Equal(t, rates["root"], 50.0, "unexpected allocation rate for the root queue")
+}
+
+func TestSetPartitionMaxResource(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	assert.Assert(t, partition.GetPartitionMaxResource() == nil, "new partition should not have a max")
+	// 100 GB of memory over two nodes
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 50, "vcore": 10})
+	err = partition.AddNode(newNodeMaxResource(nodeID1, nodeRes), nil)
+	assert.NilError(t, err, "add node1 to partition should not have failed")
+	err = partition.AddNode(newNodeMaxResource(nodeID2, nodeRes), nil)
+	assert.NilError(t, err, "add node2 to partition should not have failed")
+	assert.Assert(t, resources.Equals(partition.root.GetMaxResource(), resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 100, "vcore": 20})), "unexpected root max")
+
+	err = partition.SetPartitionMaxResource(resources.NewResourceFromMap(map[string]resources.Quantity{"memory": -1}))
+	assert.Assert(t, err != nil, "negative max should have been rejected")
+	assert.Assert(t, partition.GetPartitionMaxResource() == nil, "rejected max should not have been set")
+	// types that are not part of the max are not capped
+	err = partition.SetPartitionMaxResource(resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 50}))
+	assert.NilError(t, err, "setting the partition max should not have failed")
+	capped := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 50, "vcore": 20})
+	assert.Assert(t, resources.Equals(partition.root.GetMaxResource(), capped), "root max not capped: %s", partition.root.GetMaxResource())
+	assert.Assert(t, resources.Equals(partition.GetTotalPartitionResource(), resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 100, "vcore": 20})), "total node resources should not change")
+
+	app := newApplication(appID1, "default", defQueue)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 10})
+	err = app.AddAllocationAsk(newAllocationAskRepeat("alloc-1", appID1, res, 10))
+	assert.NilError(t, err, "failed to add ask alloc-1 to app-1")
+	for i := 0; i < 5; i++ {
+		if alloc := partition.tryAllocate(); alloc == nil {
+			t.Fatalf("allocation %d did not return any allocation", i)
+		}
+	}
+	if alloc := partition.tryAllocate(); alloc != nil {
+		t.Fatalf("allocation above the partition max should not have been made: %s", alloc)
+	}
+	assert.Equal(t, partition.GetAllocatedResource().Resources["memory"], resources.Quantity(50), "allocated should be capped at the partition max")
+
+	err = partition.SetPartitionMaxResource(resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 40}))
+	assert.Assert(t, err != nil, "max below the allocated resource should have been rejected")
+	assert.Assert(t, resources.Equals(partition.root.GetMaxResource(), capped), "root max changed by a rejected max")
+
+	// removing the cap makes all node resources available
+	err = partition.SetPartitionMaxResource(nil)
+	assert.NilError(t, err, "removing the partition max should not have failed")
+	assert.Assert(t, partition.GetPartitionMaxResource() == nil, "partition max not removed")
+	if alloc := partition.tryAllocate(); alloc == nil {
+		t.Fatal("allocation after removing the partition max should have been made")
+	}
+}
+
+func TestGetHierarchicalQueueLoad(t *testing.T) {
+	partition := createQueuesNodes(t)
+	if partition == nil {
+		t.Fatal("partition create failed")
+	}
+	// root max is the node capacity: 20
+	err := partition.GetQueue("root.parent").SetMaxResource(resources.NewResourceFromMap(map[string]resources.Quantity{"first": 16}))
+	assert.NilError(t, err, "failed to set parent max")
+	err = partition.GetQueue("root.parent.sub-leaf").SetMaxResource(resources.NewResourceFromMap(map[string]resources.Quantity{"first": 8}))
+	assert.NilError(t, err, "failed to set sub-leaf max")
+	assert.DeepEqual(t, partition.GetHierarchicalQueueLoad(), map[string]float64{
+		"root":                 0,
+		"root.leaf":            0,
+		"root.parent":          0,
+		"root.parent.sub-leaf": 0,
+	})
+
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	for appID, queue := range map[string]string{appID1: "root.leaf", appID2: "root.parent.sub-leaf"} {
+		app := newApplication(appID, "default", queue)
+		err = partition.AddApplication(app)
+		assert.NilError(t, err, "failed to add %s to partition", appID)
+	}
+	err = partition.getApplication(appID1).AddAllocationAsk(newAllocationAskRepeat("alloc-1", appID1, res, 5))
+	assert.NilError(t, err, "failed to add ask to app-1")
+	err = partition.getApplication(appID2).AddAllocationAsk(newAllocationAskRepeat("alloc-2", appID2, res, 4))
+	assert.NilError(t, err, "failed to add ask to app-2")
+	for i := 0; i < 9; i++ {
+		if alloc := partition.tryAllocate(); alloc == nil {
+			t.Fatalf("allocation %d did not return any allocation", i)
+		}
+	}
+	// queues without a max of their own use the max of the parent
+	assert.DeepEqual(t, partition.GetHierarchicalQueueLoad(), map[string]float64{
+		"root":                 0.45,
+		"root.leaf":            0.25,
+		"root.parent":          0.25,
+		"root.parent.sub-leaf": 0.5,
+	})
+}
+
+func TestGetQueueDepthStats(t *testing.T) {
+	leaf := func(name string) configs.QueueConfig {
+		return configs.QueueConfig{Name: name}
+	}
+	parent := func(name string, children ...configs.QueueConfig) configs.QueueConfig {
+		return configs.QueueConfig{Name: name, Parent: true, Queues: children}
+	}
+	conf := configs.PartitionConfig{
+		Name: "test",
+		Queues: []configs.QueueConfig{
+			parent("root",
+				parent("a",
+					parent("a1", leaf("x"), leaf("y")),
+					leaf("a2")),
+				parent("b",
+					leaf("b1"),
+					leaf("b2"),
+					parent("b3", leaf("z"))),
+				leaf("c")),
+		},
+	}
+	partition, err := newPartitionContext(conf, rmID, nil)
+	assert.NilError(t, err, "partition create failed")
+
+	assert.DeepEqual(t, partition.GetQueueDepthStats(), map[int]int{0: 1, 1: 3, 2: 5, 3: 3})
+	assert.DeepEqual(t, partition.GetLeafQueueCountByDepth(), map[int]int{1: 1, 2: 3, 3: 3})
+	assert.DeepEqual(t, partition.GetParentQueueCountByDepth(), map[int]int{0: 1, 1: 2, 2: 2})
+	assert.Equal(t, partition.GetQueue("root.b.b3.z").GetQueueDepth(), 3, "unexpected depth for leaf")
+}
+
 func TestGetQueue(t *testing.T) {
 	// get the partition
 	partition, err := newBasePartition()
@@ -686,69 +1395,332 @@ func TestGetQueue(t *testing.T) {
 	assert.Equal(t, queue, parent, "partition returned nil for existing queue name request")
 }
 
-func TestTryAllocate(t *testing.T) {
+func TestGetApplicationEventLog(t *testing.T) {
 	partition := createQueuesNodes(t)
 	if partition == nil {
 		t.Fatal("partition create failed")
 	}
-	if alloc := partition.tryAllocate(); alloc != nil {
-		t.Fatalf("empty cluster allocate returned allocation: %v", alloc.String())
-	}
-
-	// create a set of queues and apps: app-1 2 asks; app-2 1 ask (same size)
-	// sub-leaf will have an app with 2 requests and thus more unconfirmed resources compared to leaf2
-	// this should filter up in the parent and the 1st allocate should show an app-1 allocation
-	// the ask with the higher priority is the second one added alloc-2 for app-1
-	app := newApplication(appID1, "default", "root.parent.sub-leaf")
-
-	res, err := resources.NewResourceFromConf(map[string]string{"first": "1"})
-	assert.NilError(t, err, "failed to create resource")
-
-	// add to the partition
-	err = partition.AddApplication(app)
+	assert.Assert(t, partition.GetApplicationEventLog(appID1) == nil, "unknown app should not have events")
+	app := objects.NewApplication(appID1, "default", "root.leaf", security.UserGroup{},
+		map[string]string{"application.maxconcurrentallocations": "1"}, nil, rmID)
+	err := partition.AddApplication(app)
 	assert.NilError(t, err, "failed to add app-1 to partition")
-	err = app.AddAllocationAsk(newAllocationAsk("alloc-1", appID1, res))
-	assert.NilError(t, err, "failed to add ask alloc-1 to app-1")
-	err = app.AddAllocationAsk(newAllocationAskPriority("alloc-2", appID1, res, 1, 2))
-	assert.NilError(t, err, "failed to add ask alloc-2 to app-1")
 
-	app = newApplication(appID2, "default", "root.leaf")
-	// add to the partition
-	err = partition.AddApplication(app)
-	assert.NilError(t, err, "failed to add app-2 to partition")
-	err = app.AddAllocationAsk(newAllocationAskPriority("alloc-1", appID2, res, 1, 2))
-	assert.NilError(t, err, "failed to add ask alloc-1 to app-2")
+	// ask larger than the partition is denied on quota
+	large := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 30})
+	err = app.AddAllocationAsk(newAllocationAsk("alloc-1", appID1, large))
+	assert.NilError(t, err, "failed to add ask alloc-1 to app-1")
+	for i := 0; i < 2; i++ {
+		if alloc := partition.tryAllocate(); alloc != nil {
+			t.Fatalf("allocation over the quota returned: %s", alloc.String())
+		}
+	}
+	app.RemoveAllocationAsk("alloc-1")
 
-	// first allocation should be app-1 and alloc-2
+	// allocate up to the limit, the next allocation is denied on the limit
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	err = app.AddAllocationAsk(newAllocationAskRepeat("alloc-2", appID1, res, 2))
+	assert.NilError(t, err, "failed to add ask alloc-2 to app-1")
 	alloc := partition.tryAllocate()
 	if alloc == nil {
 		t.Fatal("allocation did not return any allocation")
 	}
-	assert.Equal(t, alloc.Result, objects.Allocated, "result is not the expected allocated")
-	assert.Equal(t, len(alloc.Releases), 0, "released allocations should have been 0")
-	assert.Equal(t, alloc.ApplicationID, appID1, "expected application app-1 to be allocated")
-	assert.Equal(t, alloc.AllocationKey, "alloc-2", "expected ask alloc-2 to be allocated")
-
-	// second allocation should be app-2 and alloc-1: higher up in the queue hierarchy
-	alloc = partition.tryAllocate()
-	if alloc == nil {
-		t.Fatal("allocation did not return any allocation")
+	if alloc = partition.tryAllocate(); alloc != nil {
+		t.Fatalf("allocation over the limit returned: %s", alloc.String())
 	}
-	assert.Equal(t, alloc.Result, objects.Allocated, "result is not the expected allocated")
-	assert.Equal(t, len(alloc.Releases), 0, "released allocations should have been 0")
-	assert.Equal(t, alloc.ApplicationID, appID2, "expected application app-2 to be allocated")
-	assert.Equal(t, alloc.AllocationKey, "alloc-1", "expected ask alloc-1 to be allocated")
+	partition.removeAllocation(appID1, app.GetAllAllocations()[0].UUID)
+	partition.removeApplication(appID1)
+	assert.Assert(t, partition.GetApplicationEventLog(appID1) == nil, "removed app should not have events")
 
-	// third allocation should be app-1 and alloc-1
-	alloc = partition.tryAllocate()
-	if alloc == nil {
-		t.Fatal("allocation did not return any allocation")
+	expected := []objects.ApplicationEventType{
+		objects.AppAdmitted,
+		objects.AppStateChanged, objects.AppAskAdded, objects.AppQuotaDenied, objects.AppAskRemoved, objects.AppStateChanged,
+		objects.AppStateChanged, objects.AppAskAdded, objects.AppAllocationAdded, objects.AppRateLimitDenied,
+		objects.AppAllocationFreed, objects.AppAskRemoved, objects.AppStateChanged,
+	}
+	events := app.GetEventLog()
+	got := make([]objects.ApplicationEventType, len(events))
+	for i, event := range events {
+		got[i] = event.Type
+	}
+	assert.DeepEqual(t, got, expected)
+	assert.Equal(t, events[0].Description, "admitted to queue root.leaf", "unexpected admitted description")
+	assert.Assert(t, resources.Equals(events[2].Resource, large), "ask resource not recorded")
+	for i := 1; i < len(events); i++ {
+		assert.Assert(t, !events[i].Time.Before(events[i-1].Time), "events not in chronological order")
 	}
-	assert.Equal(t, alloc.Result, objects.Allocated, "result is not the expected allocated")
-	assert.Equal(t, len(alloc.Releases), 0, "released allocations should have been 0")
-	assert.Equal(t, alloc.ApplicationID, appID1, "expected application app-1 to be allocated")
-	assert.Equal(t, alloc.AllocationKey, "alloc-1", "expected ask alloc-1 to be allocated")
-	assert.Assert(t, resources.IsZero(partition.root.GetPendingResource()), "pending resources should be set to zero")
+}
+
+func TestClearPartition(t *testing.T) {
+	partition := createQueuesNodes(t)
+	if partition == nil {
+		t.Fatal("partition create failed")
+	}
+	assert.Equal(t, len(partition.clearPartition()), 0, "empty partition should not release allocations")
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	app := newApplication(appID1, "default", "root.leaf")
+	err := partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	err = app.AddAllocationAsk(newAllocationAskRepeat("alloc-1", appID1, res, 3))
+	assert.NilError(t, err, "failed to add ask alloc-1 to app-1")
+	allocated := make(map[string]bool)
+	for i := 0; i < 3; i++ {
+		alloc := partition.tryAllocate()
+		if alloc == nil {
+			t.Fatalf("allocation %d did not return any allocation", i)
+		}
+		allocated[alloc.UUID] = true
+	}
+
+	released := partition.clearPartition()
+	assert.Equal(t, len(released), 3, "unexpected number of allocations released")
+	for _, alloc := range released {
+		assert.Assert(t, allocated[alloc.UUID], "unexpected allocation released: %s", alloc.UUID)
+	}
+	assert.Equal(t, partition.GetTotalAllocationCount(), 0, "partition allocations not removed")
+	assert.Equal(t, len(app.GetAllAllocations()), 0, "app allocations not removed")
+	assert.Assert(t, resources.IsZero(partition.root.GetAllocatedResource()), "root usage not cleared")
+	assert.Assert(t, resources.IsZero(partition.GetQueue("root.leaf").GetAllocatedResource()), "leaf usage not cleared")
+	for _, node := range partition.GetNodes() {
+		assert.Equal(t, len(node.GetAllAllocations()), 0, "node %s allocations not removed", node.NodeID)
+		assert.Assert(t, resources.IsZero(node.GetAllocatedResource()), "node %s usage not cleared", node.NodeID)
+	}
+}
+
+func TestRecoveryCounters(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	err = partition.AddApplication(newApplication(appID1, "default", defQueue))
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10})
+
+	// two allocations recovered
+	allocs := []*objects.Allocation{
+		objects.NewAllocation("uuid-1", nodeID1, newAllocationAsk("alloc-1", appID1, res)),
+		objects.NewAllocation("uuid-2", nodeID1, newAllocationAsk("alloc-2", appID1, res)),
+	}
+	err = partition.AddNode(newNodeMaxResource(nodeID1, nodeRes), allocs)
+	assert.NilError(t, err, "node with valid allocations should have been added")
+	assert.Equal(t, partition.GetRecoveredAllocationCount(), 2, "unexpected recovered allocations")
+	assert.Equal(t, partition.GetFailedRecoveryCount(), 0, "unexpected failed recoveries")
+
+	// one recovered, the allocation for an unknown application fails and stops the recovery of the node
+	allocs = []*objects.Allocation{
+		objects.NewAllocation("uuid-3", nodeID2, newAllocationAsk("alloc-3", appID1, res)),
+		objects.NewAllocation("uuid-4", nodeID2, newAllocationAsk("alloc-4", "unknown", res)),
+		objects.NewAllocation("uuid-5", nodeID2, newAllocationAsk("alloc-5", appID1, res)),
+	}
+	err = partition.AddNode(newNodeMaxResource(nodeID2, nodeRes), allocs)
+	assert.ErrorContains(t, err, "failed to find application", "node with unknown application should have failed")
+	assert.Equal(t, partition.GetRecoveredAllocationCount(), 3, "unexpected recovered allocations")
+	assert.Equal(t, partition.GetFailedRecoveryCount(), 1, "unexpected failed recoveries")
+
+	// missing UUID fails
+	allocs = []*objects.Allocation{objects.NewAllocation("", nodeID2, newAllocationAsk("alloc-6", appID1, res))}
+	err = partition.AddNode(newNodeMaxResource(nodeID2, nodeRes), allocs)
+	assert.ErrorContains(t, err, "missing UUID", "allocation without UUID should have failed")
+	assert.Equal(t, partition.GetFailedRecoveryCount(), 2, "unexpected failed recoveries")
+
+	// stopping does not reset the counters, starting again does
+	err = partition.handlePartitionEvent(objects.Stop)
+	assert.NilError(t, err, "partition stop failed")
+	assert.Equal(t, partition.GetRecoveredAllocationCount(), 3, "stop should not reset the counters")
+	err = partition.handlePartitionEvent(objects.Start)
+	assert.NilError(t, err, "partition start failed")
+	assert.Equal(t, partition.GetRecoveredAllocationCount(), 0, "recovered allocations not reset")
+	assert.Equal(t, partition.GetFailedRecoveryCount(), 0, "failed recoveries not reset")
+}
+
+func TestTryAllocateConcurrentLimit(t *testing.T) {
+	partition := createQueuesNodes(t)
+	if partition == nil {
+		t.Fatal("partition create failed")
+	}
+	res, err := resources.NewResourceFromConf(map[string]string{"first": "1"})
+	assert.NilError(t, err, "failed to create resource")
+	app := objects.NewApplication(appID1, "default", "root.leaf", security.UserGroup{},
+		map[string]string{"application.maxconcurrentallocations": "2"}, nil, rmID)
+	assert.Equal(t, app.GetMaxConcurrentAllocations(), 2, "limit not set from tag")
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	err = app.AddAllocationAsk(newAllocationAskRepeat("alloc-1", appID1, res, 4))
+	assert.NilError(t, err, "failed to add ask alloc-1 to app-1")
+
+	// only the limit gets allocated
+	var uuids []string
+	for i := 0; i < 2; i++ {
+		alloc := partition.tryAllocate()
+		if alloc == nil {
+			t.Fatalf("allocation %d did not return any allocation", i)
+		}
+		uuids = append(uuids, alloc.UUID)
+	}
+	assert.Assert(t, app.IsAllocationLimitReached(), "app should be at its limit")
+	if alloc := partition.tryAllocate(); alloc != nil {
+		t.Fatalf("allocation over the limit returned: %s", alloc.String())
+	}
+	assert.Equal(t, len(app.GetAllAllocations()), 2, "unexpected active allocations")
+	assert.Assert(t, resources.Equals(app.GetPendingResource(), resources.Multiply(res, 2)), "pending should not have changed")
+
+	// releasing one allocation allows one more
+	partition.removeAllocation(appID1, uuids[0])
+	if alloc := partition.tryAllocate(); alloc == nil {
+		t.Fatal("allocation after release did not return any allocation")
+	}
+	if alloc := partition.tryAllocate(); alloc != nil {
+		t.Fatalf("allocation over the limit returned: %s", alloc.String())
+	}
+	assert.Equal(t, len(app.GetAllAllocations()), 2, "unexpected active allocations")
+
+	// the limit does not apply to recovery: the allocation exists on the node
+	queue := partition.GetQueue("root.leaf")
+	used := queue.GetAllocatedResource()
+	alloc := objects.NewAllocation("uuid-recovered", nodeID1, newAllocationAsk("alloc-2", appID1, res))
+	alloc.QueueName = "root.leaf"
+	err = partition.addAllocation(alloc)
+	assert.NilError(t, err, "recovery over the limit should not have failed")
+	assert.Assert(t, resources.Equals(queue.GetAllocatedResource(), resources.Add(used, res)), "queue usage not updated on recovery")
+	assert.Equal(t, len(app.GetAllAllocations()), 3, "unexpected active allocations")
+	// the clone of an application over the limit keeps all its allocations
+	sim := partition.CloneForSimulation()
+	assert.Assert(t, sim != nil, "clone should have been created")
+	assert.Equal(t, len(sim.getApplication(appID1).GetAllAllocations()), 3, "unexpected allocations in the clone")
+	if alloc := partition.tryAllocate(); alloc != nil {
+		t.Fatalf("allocation over the limit returned: %s", alloc.String())
+	}
+}
+
+func TestTryAllocate(t *testing.T) {
+	partition := createQueuesNodes(t)
+	if partition == nil {
+		t.Fatal("partition create failed")
+	}
+	if alloc := partition.tryAllocate(); alloc != nil {
+		t.Fatalf("empty cluster allocate returned allocation: %v", alloc.String())
+	}
+
+	// create a set of queues and apps: app-1 2 asks; app-2 1 ask (same size)
+	// sub-leaf will have an app with 2 requests and thus more unconfirmed resources compared to leaf2
+	// this should filter up in the parent and the 1st allocate should show an app-1 allocation
+	// the ask with the higher priority is the second one added alloc-2 for app-1
+	app := newApplication(appID1, "default", "root.parent.sub-leaf")
+
+	res, err := resources.NewResourceFromConf(map[string]string{"first": "1"})
+	assert.NilError(t, err, "failed to create resource")
+
+	// add to the partition
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	err = app.AddAllocationAsk(newAllocationAsk("alloc-1", appID1, res))
+	assert.NilError(t, err, "failed to add ask alloc-1 to app-1")
+	err = app.AddAllocationAsk(newAllocationAskPriority("alloc-2", appID1, res, 1, 2))
+	assert.NilError(t, err, "failed to add ask alloc-2 to app-1")
+
+	app = newApplication(appID2, "default", "root.leaf")
+	// add to the partition
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-2 to partition")
+	err = app.AddAllocationAsk(newAllocationAskPriority("alloc-1", appID2, res, 1, 2))
+	assert.NilError(t, err, "failed to add ask alloc-1 to app-2")
+
+	// first allocation should be app-1 and alloc-2
+	alloc := partition.tryAllocate()
+	if alloc == nil {
+		t.Fatal("allocation did not return any allocation")
+	}
+	assert.Equal(t, alloc.Result, objects.Allocated, "result is not the expected allocated")
+	assert.Equal(t, len(alloc.Releases), 0, "released allocations should have been 0")
+	assert.Equal(t, alloc.ApplicationID, appID1, "expected application app-1 to be allocated")
+	assert.Equal(t, alloc.AllocationKey, "alloc-2", "expected ask alloc-2 to be allocated")
+
+	// second allocation should be app-2 and alloc-1: higher up in the queue hierarchy
+	alloc = partition.tryAllocate()
+	if alloc == nil {
+		t.Fatal("allocation did not return any allocation")
+	}
+	assert.Equal(t, alloc.Result, objects.Allocated, "result is not the expected allocated")
+	assert.Equal(t, len(alloc.Releases), 0, "released allocations should have been 0")
+	assert.Equal(t, alloc.ApplicationID, appID2, "expected application app-2 to be allocated")
+	assert.Equal(t, alloc.AllocationKey, "alloc-1", "expected ask alloc-1 to be allocated")
+
+	// third allocation should be app-1 and alloc-1
+	alloc = partition.tryAllocate()
+	if alloc == nil {
+		t.Fatal("allocation did not return any allocation")
+	}
+	assert.Equal(t, alloc.Result, objects.Allocated, "result is not the expected allocated")
+	assert.Equal(t, len(alloc.Releases), 0, "released allocations should have been 0")
+	assert.Equal(t, alloc.ApplicationID, appID1, "expected application app-1 to be allocated")
+	assert.Equal(t, alloc.AllocationKey, "alloc-1", "expected ask alloc-1 to be allocated")
+	assert.Assert(t, resources.IsZero(partition.root.GetPendingResource()), "pending resources should be set to zero")
+}
+
+// reader that always returns the same bytes: simulates a UUID source without entropy
+type fixedReader struct{}
+
+func (fixedReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0x42
+	}
+	return len(p), nil
+}
+
+func TestTryAllocateUUIDClash(t *testing.T) {
+	partition := createQueuesNodes(t)
+	if partition == nil {
+		t.Fatal("partition create failed")
+	}
+	// no entropy: every UUID generated is the same
+	previous := common.SetUUIDSource(fixedReader{})
+	defer common.SetUUIDSource(previous)
+
+	// the first allocation fills most of one node
+	res, err := resources.NewResourceFromConf(map[string]string{"first": "8"})
+	assert.NilError(t, err, "failed to create resource")
+	app1 := newApplication(appID1, "default", "root.parent.sub-leaf")
+	err = partition.AddApplication(app1)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	err = app1.AddAllocationAsk(newAllocationAsk("alloc-1", appID1, res))
+	assert.NilError(t, err, "failed to add ask alloc-1 to app-1")
+	alloc := partition.tryAllocate()
+	if alloc == nil {
+		t.Fatal("first allocation with a fixed UUID should not have failed")
+	}
+	firstNode := alloc.NodeID
+
+	// the second allocation lands on the other node, clashes and cannot be resolved: it must be reverted
+	res, err = resources.NewResourceFromConf(map[string]string{"first": "5"})
+	assert.NilError(t, err, "failed to create resource")
+	app2 := newApplication(appID2, "default", "root.leaf")
+	err = partition.AddApplication(app2)
+	assert.NilError(t, err, "failed to add app-2 to partition")
+	err = app2.AddAllocationAsk(newAllocationAskRepeat("alloc-1", appID2, res, 2))
+	assert.NilError(t, err, "failed to add ask alloc-1 to app-2")
+	alloc = partition.tryAllocate()
+	if alloc != nil {
+		t.Fatalf("allocation with a clashing UUID should have failed: %s", alloc)
+	}
+	assert.Equal(t, len(partition.allocations), 1, "unexpected allocations in the partition")
+	assert.Equal(t, len(app2.GetAllAllocations()), 0, "allocation not reverted on the app")
+	assert.Assert(t, resources.IsZero(app2.GetAllocatedResource()), "allocated resource not reverted on the app")
+	assert.Assert(t, resources.IsZero(app2.GetQueue().GetAllocatedResource()), "allocated resource not reverted on the queue")
+	assert.Equal(t, app2.GetSchedulingAllocationAsk("alloc-1").GetPendingAskRepeat(), int32(2), "ask repeat not restored")
+	assert.Equal(t, app2.GetMaxAllocationTime(), time.Duration(0), "reverted allocation should not change the allocation time")
+	for _, node := range partition.GetNodes() {
+		if node.NodeID != firstNode {
+			assert.Equal(t, len(node.GetAllAllocations()), 0, "allocation not reverted on the node")
+		}
+	}
+
+	// entropy restored: allocation succeeds
+	common.SetUUIDSource(previous)
+	alloc = partition.tryAllocate()
+	if alloc == nil {
+		t.Fatal("allocation with entropy restored should not have failed")
+	}
+	assert.Equal(t, alloc.ApplicationID, appID2, "unexpected application allocated")
+	assert.Assert(t, app2.GetMaxAllocationTime() > 0, "confirmed allocation should set the allocation time")
 }
 
 func TestTryAllocateLarge(t *testing.T) {
@@ -782,6 +1754,375 @@ func TestTryAllocateLarge(t *testing.T) {
 	assert.Equal(t, 0, len(app.GetReservations()), "ask should not have been reserved")
 }
 
+// set up a partition for the scheduling simulation: two apps each with asks that do not all fit
+func createSimulationPartition(t *testing.T) *PartitionContext {
+	partition := createQueuesNodes(t)
+	if partition == nil {
+		t.Fatal("partition create failed")
+	}
+	res, err := resources.NewResourceFromConf(map[string]string{"first": "4"})
+	assert.NilError(t, err, "failed to create resource")
+	app := newApplication(appID1, "default", "root.parent.sub-leaf")
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	err = app.AddAllocationAsk(newAllocationAskRepeat("alloc-1", appID1, res, 3))
+	assert.NilError(t, err, "failed to add ask alloc-1 to app-1")
+	app = newApplication(appID2, "default", "root.leaf")
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-2 to partition")
+	err = app.AddAllocationAsk(newAllocationAskRepeat("alloc-1", appID2, res, 3))
+	assert.NilError(t, err, "failed to add ask alloc-1 to app-2")
+	return partition
+}
+
+func TestSimulateSchedulingCycle(t *testing.T) {
+	partition := createSimulationPartition(t)
+	pending := partition.root.GetPendingResource().Clone()
+	simulated := partition.SimulateSchedulingCycle()
+	// two nodes with a capacity of 10, each fits two allocations
+	assert.Equal(t, len(simulated), 4, "unexpected number of simulated allocations")
+
+	// nothing may have changed in the real partition
+	assert.Assert(t, resources.Equals(partition.root.GetPendingResource(), pending), "pending resources changed")
+	assert.Assert(t, resources.IsZero(partition.root.GetAllocatedResource()), "allocated resources changed")
+	assert.Equal(t, len(partition.allocations), 0, "allocations added to the partition")
+	for _, node := range partition.GetNodes() {
+		assert.Equal(t, len(node.GetAllAllocations()), 0, "allocations added to node %s", node.NodeID)
+	}
+	for _, app := range partition.GetApplications() {
+		assert.Equal(t, len(app.GetAllAllocations()), 0, "allocations added to app %s", app.ApplicationID)
+		ask := app.GetSchedulingAllocationAsk("alloc-1")
+		assert.Equal(t, ask.GetPendingAskRepeat(), int32(3), "pending repeats changed for app %s", app.ApplicationID)
+	}
+
+	// the real scheduling cycle on an identical partition must give the same result
+	identical := createSimulationPartition(t)
+	actual := make([]*objects.Allocation, 0)
+	for {
+		alloc := identical.tryAllocate()
+		if alloc == nil {
+			break
+		}
+		actual = append(actual, alloc)
+	}
+	assert.Equal(t, len(actual), len(simulated), "simulation and real cycle differ")
+	count := make(map[string]int)
+	for i := range actual {
+		count[actual[i].ApplicationID]++
+		count[simulated[i].ApplicationID]--
+	}
+	for appID, diff := range count {
+		assert.Equal(t, diff, 0, "allocations for app %s differ between simulation and real cycle", appID)
+	}
+
+	// running the simulation again gives the same result
+	assert.Equal(t, len(partition.SimulateSchedulingCycle()), len(simulated), "second simulation returned a different result")
+}
+
+func TestCloneForSimulation(t *testing.T) {
+	partition := createSimulationPartition(t)
+	// existing allocation in the partition that must be copied
+	alloc := partition.tryAllocate()
+	assert.Assert(t, alloc != nil, "expected an allocation in the real partition")
+
+	// snapshot of the original partition
+	allocated := partition.root.GetAllocatedResource()
+	pending := partition.root.GetPendingResource()
+	allocCount := len(partition.allocations)
+	nodeAllocated := make(map[string]*resources.Resource)
+	nodeAllocs := make(map[string]int)
+	for _, node := range partition.GetNodes() {
+		nodeAllocated[node.NodeID] = node.GetAllocatedResource()
+		nodeAllocs[node.NodeID] = len(node.GetAllAllocations())
+	}
+	appAllocs := make(map[string]int)
+	for _, app := range partition.GetApplications() {
+		appAllocs[app.ApplicationID] = len(app.GetAllAllocations())
+	}
+
+	sim := partition.CloneForSimulation()
+	assert.Assert(t, sim != nil, "clone should have been created")
+	assert.Assert(t, sim.IsSimulation, "clone should be marked as a simulation")
+	assert.Assert(t, !partition.IsSimulation, "original should not be marked as a simulation")
+	assert.Assert(t, sim.partitionManager != partition.partitionManager, "partition manager shared with clone")
+	assert.Assert(t, sim.stateMachine != partition.stateMachine, "state machine shared with clone")
+	assert.Assert(t, sim.root != partition.root, "queue tree shared with clone")
+	assert.Assert(t, resources.Equals(sim.root.GetAllocatedResource(), allocated), "allocated resources not copied")
+	assert.Assert(t, resources.Equals(sim.root.GetPendingResource(), pending), "pending resources not copied")
+	assert.Equal(t, len(sim.allocations), allocCount, "allocations not copied")
+	for uuid, simAlloc := range sim.allocations {
+		assert.Assert(t, simAlloc != partition.allocations[uuid], "allocation %s shared with clone", uuid)
+		assert.Assert(t, simAlloc == sim.GetNode(simAlloc.NodeID).GetAllocation(uuid), "allocation %s not linked to cloned node", uuid)
+	}
+	for nodeID, node := range sim.nodes {
+		assert.Assert(t, node != partition.nodes[nodeID], "node %s shared with clone", nodeID)
+	}
+	for appID, app := range sim.applications {
+		assert.Assert(t, app != partition.applications[appID], "application %s shared with clone", appID)
+	}
+	// the queues of the clone must not update the metrics
+	assert.Assert(t, sim.root.IsSimulation(), "root queue of the clone should be marked as a simulation")
+	sim.root.WalkDescendants(func(queue *objects.Queue) {
+		assert.Assert(t, queue.IsSimulation(), "queue %s of the clone should be marked as a simulation", queue.QueuePath)
+	})
+	assert.Assert(t, !partition.root.IsSimulation(), "original root queue should not be marked as a simulation")
+
+	// full scheduling simulation on the clone
+	simAllocs := 0
+	for sim.tryAllocate() != nil {
+		simAllocs++
+	}
+	assert.Equal(t, simAllocs, 3, "unexpected number of allocations in the clone")
+	assert.Equal(t, len(sim.allocations), allocCount+simAllocs, "allocations not tracked in the clone")
+
+	// the original is unchanged
+	assert.Assert(t, resources.Equals(partition.root.GetAllocatedResource(), allocated), "allocated resources changed")
+	assert.Assert(t, resources.Equals(partition.root.GetPendingResource(), pending), "pending resources changed")
+	assert.Equal(t, len(partition.allocations), allocCount, "allocations changed")
+	for _, node := range partition.GetNodes() {
+		assert.Assert(t, resources.Equals(node.GetAllocatedResource(), nodeAllocated[node.NodeID]), "allocated resources changed on node %s", node.NodeID)
+		assert.Equal(t, len(node.GetAllAllocations()), nodeAllocs[node.NodeID], "allocations changed on node %s", node.NodeID)
+	}
+	for _, app := range partition.GetApplications() {
+		assert.Equal(t, len(app.GetAllAllocations()), appAllocs[app.ApplicationID], "allocations changed for app %s", app.ApplicationID)
+	}
+}
+
+func TestCloneForSimulationSchedulingConstraints(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	app := newApplication(appID1, "default", defQueue)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app to partition")
+	app.SetSchedulingConstraints(objects.SchedulingConstraints{RequiredLabels: map[string]string{"zone": "zone-a"}, MaxAllocationsPerNode: 1})
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 1})
+	err = app.AddAllocationAsk(newAllocationAsk("alloc-1", appID1, res))
+	assert.NilError(t, err, "failed to add ask to app")
+
+	sim := partition.CloneForSimulation()
+	assert.Assert(t, sim != nil, "clone should have been created")
+	clone := sim.getApplication(appID1)
+	constraints := clone.GetSchedulingConstraints()
+	assert.DeepEqual(t, constraints.RequiredLabels, map[string]string{"zone": "zone-a"})
+	assert.Equal(t, constraints.MaxAllocationsPerNode, 1, "max allocations per node not copied")
+	askConstraints := clone.GetSchedulingAllocationAsk("alloc-1").GetSchedulingConstraints()
+	assert.Assert(t, askConstraints != nil, "constraints not applied to the cloned ask")
+	assert.Equal(t, askConstraints.RequiredLabels["zone"], "zone-a", "unexpected constraints on the cloned ask")
+}
+
+func TestGetPendingAskSatisfiability(t *testing.T) {
+	partition := createQueuesNodes(t)
+	if partition == nil {
+		t.Fatal("partition create failed")
+	}
+	assert.Equal(t, len(partition.GetPendingAskSatisfiability()), 0, "empty partition should not have pending asks")
+
+	app := newApplication(appID1, "default", "root.parent.sub-leaf")
+	err := partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	// the nodes have a capacity of 10
+	var res *resources.Resource
+	res, err = resources.NewResourceFromConf(map[string]string{"first": "10"})
+	assert.NilError(t, err, "failed to create resource")
+	err = app.AddAllocationAsk(newAllocationAsk("alloc-fit", appID1, res))
+	assert.NilError(t, err, "failed to add ask alloc-fit to app")
+	res, err = resources.NewResourceFromConf(map[string]string{"first": "11"})
+	assert.NilError(t, err, "failed to create resource")
+	err = app.AddAllocationAsk(newAllocationAsk("alloc-large", appID1, res))
+	assert.NilError(t, err, "failed to add ask alloc-large to app")
+	res, err = resources.NewResourceFromConf(map[string]string{"unknown": "1"})
+	assert.NilError(t, err, "failed to create resource")
+	err = app.AddAllocationAsk(newAllocationAsk("alloc-unknown", appID1, res))
+	assert.NilError(t, err, "failed to add ask alloc-unknown to app")
+
+	result := partition.GetPendingAskSatisfiability()
+	assert.DeepEqual(t, result, map[string]bool{"alloc-fit": true, "alloc-large": false, "alloc-unknown": false})
+
+	// current allocations must not influence the result
+	alloc := partition.tryAllocate()
+	if alloc == nil {
+		t.Fatal("allocation did not return any allocation")
+	}
+	assert.Equal(t, alloc.AllocationKey, "alloc-fit", "expected ask alloc-fit to be allocated")
+	err = app.AddAllocationAsk(newAllocationAsk("alloc-fit-2", appID1, alloc.AllocatedResource))
+	assert.NilError(t, err, "failed to add ask alloc-fit-2 to app")
+	result = partition.GetPendingAskSatisfiability()
+	assert.DeepEqual(t, result, map[string]bool{"alloc-fit-2": true, "alloc-large": false, "alloc-unknown": false})
+}
+
+func TestTryAllocateTopologySpread(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	// multi zone cluster: zone-a has two large nodes, the other zones one small node each
+	nodes := map[string]struct {
+		zone string
+		size string
+	}{
+		"node-a1": {"zone-a", "100"},
+		"node-a2": {"zone-a", "100"},
+		"node-b1": {"zone-b", "10"},
+		"node-c1": {"zone-c", "10"},
+	}
+	for nodeID, info := range nodes {
+		var res *resources.Resource
+		res, err = resources.NewResourceFromConf(map[string]string{"vcore": info.size})
+		assert.NilError(t, err, "failed to create node resource")
+		node := newNodeWithAttributes(nodeID, res, map[string]string{"zone": info.zone})
+		err = partition.AddNode(node, nil)
+		assert.NilError(t, err, "failed to add node %s to partition", nodeID)
+	}
+
+	app := newApplication(appID1, "default", defQueue)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	res, err := resources.NewResourceFromConf(map[string]string{"vcore": "1"})
+	assert.NilError(t, err, "failed to create resource")
+	ask := newAllocationAskRepeat("alloc-1", appID1, res, 6)
+	ask.TopologyKey = "zone"
+	ask.MaxSkew = 1
+	err = app.AddAllocationAsk(ask)
+	assert.NilError(t, err, "failed to add ask to app")
+
+	zoneCount := make(map[string]int)
+	for i := 0; i < 6; i++ {
+		alloc := partition.tryAllocate()
+		if alloc == nil {
+			t.Fatalf("allocation %d did not return any allocation", i)
+		}
+		zoneCount[nodes[alloc.NodeID].zone]++
+		// after each allocation the skew must be within bounds
+		low, high := -1, 0
+		for _, zone := range []string{"zone-a", "zone-b", "zone-c"} {
+			count := zoneCount[zone]
+			if low == -1 || count < low {
+				low = count
+			}
+			if count > high {
+				high = count
+			}
+		}
+		assert.Assert(t, high-low <= ask.MaxSkew, "skew exceeded after allocation %d: %v", i, zoneCount)
+	}
+	assert.DeepEqual(t, zoneCount, map[string]int{"zone-a": 2, "zone-b": 2, "zone-c": 2})
+	assert.Equal(t, len(partition.getNodesByTopologyValue("zone", "zone-a")), 2, "unexpected nodes for zone-a")
+	assert.Equal(t, len(partition.getNodesByTopologyValue("zone", "zone-d")), 0, "unexpected nodes for unknown zone")
+}
+
+func TestGetTopologyAwareIterator(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	assert.Assert(t, partition.GetTopologyAwareIterator(objects.TopologyZoneLabel, "zone-a") == nil, "empty partition should not return an iterator")
+	res, err := resources.NewResourceFromConf(map[string]string{"vcore": "10"})
+	assert.NilError(t, err, "failed to create node resource")
+	zones := map[string]string{"node-a1": "zone-a", "node-a2": "zone-a", "node-b1": "zone-b", "node-b2": "zone-b"}
+	for nodeID, zone := range zones {
+		err = partition.AddNode(newNodeWithAttributes(nodeID, res, map[string]string{objects.TopologyZoneLabel: zone}), nil)
+		assert.NilError(t, err, "failed to add node %s to partition", nodeID)
+	}
+	iteratedZones := func(iterator interfaces.NodeIterator) map[string]int {
+		result := make(map[string]int)
+		for iterator.HasNext() {
+			node, ok := iterator.Next().(*objects.Node)
+			assert.Assert(t, ok, "iterator returned a non node object")
+			result[zones[node.NodeID]]++
+		}
+		return result
+	}
+	iterator := partition.GetTopologyAwareIterator(objects.TopologyZoneLabel, "zone-a")
+	assert.Assert(t, iterator != nil, "iterator should have been returned for zone-a")
+	assert.DeepEqual(t, iteratedZones(iterator), map[string]int{"zone-a": 2})
+	assert.Assert(t, partition.GetTopologyAwareIterator(objects.TopologyZoneLabel, "zone-c") == nil, "unknown zone should not return an iterator")
+
+	// a zone constrained ask only iterates over the nodes in that zone
+	allocRes, err := resources.NewResourceFromConf(map[string]string{"vcore": "1"})
+	assert.NilError(t, err, "failed to create resource")
+	ask := newAllocationAskRepeat("alloc-1", appID1, allocRes, 4)
+	ask.RequiredTopologyKey = objects.TopologyZoneLabel
+	ask.RequiredTopologyValue = "zone-b"
+	iterator = partition.GetNodeIterator(ask)
+	assert.Assert(t, iterator != nil, "iterator should have been returned for the ask")
+	assert.DeepEqual(t, iteratedZones(iterator), map[string]int{"zone-b": 2})
+	iterator = partition.GetNodeIterator(nil)
+	assert.DeepEqual(t, iteratedZones(iterator), map[string]int{"zone-a": 2, "zone-b": 2})
+
+	app := newApplication(appID1, "default", defQueue)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	err = app.AddAllocationAsk(ask)
+	assert.NilError(t, err, "failed to add ask to app")
+	for i := 0; i < 4; i++ {
+		alloc := partition.tryAllocate()
+		if alloc == nil {
+			t.Fatalf("allocation %d did not return any allocation", i)
+		}
+		assert.Equal(t, zones[alloc.NodeID], "zone-b", "allocation placed outside the required zone")
+	}
+}
+
+func TestGetNodeIteratorSchedulingConstraints(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	res, err := resources.NewResourceFromConf(map[string]string{"vcore": "10"})
+	assert.NilError(t, err, "failed to create node resource")
+	nodes := map[string]map[string]string{
+		"node-1": {"zone": "zone-a", "disk": "ssd"},
+		"node-2": {"zone": "zone-a", "disk": "hdd"},
+		"node-3": {"zone": "zone-b", "disk": "ssd"},
+	}
+	for nodeID, attributes := range nodes {
+		err = partition.AddNode(newNodeWithAttributes(nodeID, res, attributes), nil)
+		assert.NilError(t, err, "failed to add node %s to partition", nodeID)
+	}
+	app := newApplication(appID1, "default", defQueue)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	allocRes, err := resources.NewResourceFromConf(map[string]string{"vcore": "1"})
+	assert.NilError(t, err, "failed to create resource")
+	ask := newAllocationAskRepeat("alloc-1", appID1, allocRes, 10)
+	err = app.AddAllocationAsk(ask)
+	assert.NilError(t, err, "failed to add ask to app")
+
+	iteratedNodes := func() []string {
+		iterator := partition.GetNodeIterator(ask)
+		if iterator == nil {
+			return nil
+		}
+		var result []string
+		for iterator.HasNext() {
+			node, ok := iterator.Next().(*objects.Node)
+			assert.Assert(t, ok, "iterator returned a non node object")
+			result = append(result, node.NodeID)
+		}
+		sort.Strings(result)
+		return result
+	}
+	assert.DeepEqual(t, iteratedNodes(), []string{"node-1", "node-2", "node-3"})
+	app.SetSchedulingConstraints(objects.SchedulingConstraints{RequiredLabels: map[string]string{"zone": "zone-a"}})
+	assert.DeepEqual(t, iteratedNodes(), []string{"node-1", "node-2"})
+	app.SetSchedulingConstraints(objects.SchedulingConstraints{ForbiddenLabels: map[string]string{"disk": "hdd"}})
+	assert.DeepEqual(t, iteratedNodes(), []string{"node-1", "node-3"})
+	app.SetSchedulingConstraints(objects.SchedulingConstraints{RequiredLabels: map[string]string{"zone": "zone-a"}, ForbiddenLabels: map[string]string{"disk": "hdd"}})
+	assert.DeepEqual(t, iteratedNodes(), []string{"node-1"})
+	app.SetSchedulingConstraints(objects.SchedulingConstraints{RequiredLabels: map[string]string{"zone": "zone-a"}, RequiredNodeCount: 3})
+	assert.Assert(t, iteratedNodes() == nil, "too few matching nodes should not return an iterator")
+
+	// at most 2 allocations per node: 6 allocations over 3 nodes
+	app.SetSchedulingConstraints(objects.SchedulingConstraints{MaxAllocationsPerNode: 2})
+	perNode := make(map[string]int)
+	for i := 0; i < 6; i++ {
+		alloc := partition.tryAllocate()
+		if alloc == nil {
+			t.Fatalf("allocation %d did not return any allocation", i)
+		}
+		perNode[alloc.NodeID]++
+	}
+	assert.DeepEqual(t, perNode, map[string]int{"node-1": 2, "node-2": 2, "node-3": 2})
+	if alloc := partition.tryAllocate(); alloc != nil {
+		t.Fatalf("allocation above the per node maximum should not have been made: %s", alloc)
+	}
+	assert.Assert(t, iteratedNodes() == nil, "all nodes are at the maximum")
+}
+
 func TestAllocReserveNewNode(t *testing.T) {
 	partition := createQueuesNodes(t)
 	if partition == nil {
@@ -1042,3 +2383,1005 @@ func TestScheduleRemoveReservedAsk(t *testing.T) {
 	assert.Equal(t, len(partition.reservedApps), 1, "partition should still have reserved app")
 	assert.Equal(t, len(app.GetReservations()), 1, "application reservations should be kept at 1")
 }
+
+func TestRemoveStaleReservations(t *testing.T) {
+	partition := createQueuesNodes(t)
+	if partition == nil {
+		t.Fatal("partition create failed")
+	}
+	// nothing reserved nothing removed
+	assert.Equal(t, partition.RemoveStaleReservations(0), 0, "empty partition should not remove reservations")
+
+	res, err := resources.NewResourceFromConf(map[string]string{"first": "1"})
+	assert.NilError(t, err, "failed to create resource")
+	app := newApplication(appID1, "default", "root.parent.sub-leaf")
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	ask := newAllocationAsk("alloc-1", appID1, res)
+	err = app.AddAllocationAsk(ask)
+	assert.NilError(t, err, "failed to add ask alloc-1 to app")
+	node2 := partition.GetNode(nodeID2)
+	if node2 == nil {
+		t.Fatal("expected node-2 to be returned got nil")
+	}
+	partition.reserve(app, node2, ask)
+	assert.Equal(t, 1, len(partition.getReservations()), "partition should have reserved app")
+	times := app.GetReservationTimes()
+	assert.Equal(t, len(times), 1, "app should have one reservation")
+	reservedAt := times[0]
+
+	// reservation is not old enough
+	assert.Equal(t, partition.RemoveStaleReservations(time.Hour), 0, "reservation should not have been removed")
+	assert.Equal(t, partition.removeStaleReservations(time.Hour, reservedAt.Add(time.Hour)), 0, "reservation at the threshold should not have been removed")
+	assert.Equal(t, 1, len(partition.getReservations()), "partition should still have reserved app")
+	assert.Assert(t, app.IsReservedOnNode(nodeID2), "app should still be reserved on node-2")
+
+	// move past the threshold
+	assert.Equal(t, partition.removeStaleReservations(time.Hour, reservedAt.Add(time.Hour+time.Second)), 1, "reservation should have been removed")
+	assert.Equal(t, 0, len(partition.getReservations()), "partition should not have reserved app")
+	assert.Assert(t, !app.IsReservedOnNode(nodeID2), "app should not be reserved on node-2")
+	assert.Assert(t, !node2.IsReserved(), "node-2 should not be reserved")
+}
+
+func TestGetReservationAgeHistogram(t *testing.T) {
+	partition := createQueuesNodes(t)
+	if partition == nil {
+		t.Fatal("partition create failed")
+	}
+	empty := map[string]int{"<1s": 0, "1s-10s": 0, "10s-60s": 0, "1m-10m": 0, ">10m": 0}
+	assert.DeepEqual(t, partition.GetReservationAgeHistogram(), empty)
+
+	res, err := resources.NewResourceFromConf(map[string]string{"first": "1"})
+	assert.NilError(t, err, "failed to create resource")
+	app := newApplication(appID1, "default", "root.parent.sub-leaf")
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	ask := newAllocationAsk("alloc-1", appID1, res)
+	err = app.AddAllocationAsk(ask)
+	assert.NilError(t, err, "failed to add ask alloc-1 to app")
+	ask2 := newAllocationAsk("alloc-2", appID1, res)
+	err = app.AddAllocationAsk(ask2)
+	assert.NilError(t, err, "failed to add ask alloc-2 to app")
+	partition.reserve(app, partition.GetNode(nodeID1), ask)
+	assert.Equal(t, len(partition.getReservations()), 1, "partition should have reserved app")
+	times := app.GetReservationTimes()
+	assert.Equal(t, len(times), 1, "app should have one reservation")
+	reservedAt := times[0]
+
+	tests := []struct {
+		name     string
+		age      time.Duration
+		expected string
+	}{
+		{"new", 0, "<1s"},
+		{"just below 1s", time.Second - time.Nanosecond, "<1s"},
+		{"1s", time.Second, "1s-10s"},
+		{"10s", 10 * time.Second, "10s-60s"},
+		{"59s", 59 * time.Second, "10s-60s"},
+		{"1m", time.Minute, "1m-10m"},
+		{"10m", 10 * time.Minute, ">10m"},
+		{"1h", time.Hour, ">10m"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := map[string]int{"<1s": 0, "1s-10s": 0, "10s-60s": 0, "1m-10m": 0, ">10m": 0}
+			expected[tt.expected] = 1
+			assert.DeepEqual(t, partition.getReservationAgeHistogram(reservedAt.Add(tt.age)), expected)
+		})
+	}
+
+	// second reservation is made just after the first one
+	partition.reserve(app, partition.GetNode(nodeID2), ask2)
+	assert.Equal(t, len(app.GetReservationTimes()), 2, "app should have two reservations")
+	expected := map[string]int{"<1s": 0, "1s-10s": 2, "10s-60s": 0, "1m-10m": 0, ">10m": 0}
+	assert.DeepEqual(t, partition.getReservationAgeHistogram(reservedAt.Add(5*time.Second)), expected)
+	expected = map[string]int{"<1s": 0, "1s-10s": 0, "10s-60s": 0, "1m-10m": 2, ">10m": 0}
+	assert.DeepEqual(t, partition.getReservationAgeHistogram(reservedAt.Add(5*time.Minute)), expected)
+}
+
+func TestCompactAllocations(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	assert.Equal(t, partition.CompactAllocations(), 0, "empty partition should not have stale allocations")
+
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10})
+	allocRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	app := newApplication(appID1, "default", defQueue)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "add application to partition should not have failed")
+	var allocs []*objects.Allocation
+	for _, key := range []string{"alloc-1", "alloc-2", "alloc-3"} {
+		allocs = append(allocs, objects.NewAllocation(key+"-uuid", nodeID1, newAllocationAsk(key, appID1, allocRes)))
+	}
+	err = partition.AddNode(newNodeMaxResource(nodeID1, nodeRes), allocs)
+	assert.NilError(t, err, "add node to partition should not have failed")
+	assert.Equal(t, partition.CompactAllocations(), 0, "consistent partition should not have stale allocations")
+	assert.Equal(t, len(partition.allocations), 3, "allocations should not have been removed")
+
+	// stale entries: unknown app, unknown node, not tracked by the app and the node, removed from the app only
+	partition.allocations["unknown-app"] = objects.NewAllocation("unknown-app", nodeID1, newAllocationAsk("alloc-4", "unknown", allocRes))
+	partition.allocations["unknown-node"] = objects.NewAllocation("unknown-node", "unknown", newAllocationAsk("alloc-5", appID1, allocRes))
+	partition.allocations["untracked"] = objects.NewAllocation("untracked", nodeID1, newAllocationAsk("alloc-6", appID1, allocRes))
+	assert.Assert(t, app.RemoveAllocation("alloc-3-uuid") != nil, "allocation should have been removed from the app")
+
+	assert.Equal(t, partition.CompactAllocations(), 4, "unexpected number of stale allocations removed")
+	var remaining []string
+	for uuid := range partition.allocations {
+		remaining = append(remaining, uuid)
+	}
+	sort.Strings(remaining)
+	assert.DeepEqual(t, remaining, []string{"alloc-1-uuid", "alloc-2-uuid"})
+	assert.Equal(t, partition.CompactAllocations(), 0, "second compaction should not remove anything")
+
+	// the manager only compacts every configured number of runs
+	manager := partitionManager{pc: partition}
+	assert.Equal(t, partition.getAllocCompactionInterval(), configs.DefaultAllocationCompactionInterval, "default interval not set")
+	partition.setPartitionProperties(map[string]string{configs.AllocationCompactionInterval: "2"})
+	partition.allocations["untracked"] = objects.NewAllocation("untracked", nodeID1, newAllocationAsk("alloc-6", appID1, allocRes))
+	manager.compactAllocations(1)
+	assert.Assert(t, partition.allocations["untracked"] != nil, "compaction should not have run")
+	manager.compactAllocations(2)
+	assert.Assert(t, partition.allocations["untracked"] == nil, "compaction should have run")
+	partition.setPartitionProperties(map[string]string{configs.AllocationCompactionInterval: "0"})
+	partition.allocations["untracked"] = objects.NewAllocation("untracked", nodeID1, newAllocationAsk("alloc-6", appID1, allocRes))
+	manager.compactAllocations(2)
+	assert.Assert(t, partition.allocations["untracked"] != nil, "disabled compaction should not have run")
+}
+
+func TestGetReservedCapacity(t *testing.T) {
+	partition := createQueuesNodes(t)
+	if partition == nil {
+		t.Fatal("partition create failed")
+	}
+	assert.Assert(t, resources.IsZero(partition.GetReservedCapacity()), "empty partition should not have reserved capacity")
+	assert.DeepEqual(t, partition.GetReservedCapacityFraction(), map[string]float64{"first": 0})
+
+	// a node can only hold one reservation: add two more nodes
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10})
+	err := partition.AddNode(newNodeMaxResource("node-3", nodeRes), nil)
+	assert.NilError(t, err, "test node3 add failed unexpected")
+	err = partition.AddNode(newNodeMaxResource("node-4", nodeRes), nil)
+	assert.NilError(t, err, "test node4 add failed unexpected")
+	node1 := partition.GetNode(nodeID1)
+	node2 := partition.GetNode(nodeID2)
+	node3 := partition.GetNode("node-3")
+	node4 := partition.GetNode("node-4")
+	if node1 == nil || node2 == nil || node3 == nil || node4 == nil {
+		t.Fatal("expected nodes to be returned got nil")
+	}
+	// app-1: one ask reserved on both nodes
+	app1 := newApplication(appID1, "default", "root.parent.sub-leaf")
+	err = partition.AddApplication(app1)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	ask := newAllocationAskRepeat("alloc-1", appID1, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 2}), 2)
+	err = app1.AddAllocationAsk(ask)
+	assert.NilError(t, err, "failed to add ask alloc-1 to app-1")
+	partition.reserve(app1, node1, ask)
+	partition.reserve(app1, node2, ask)
+	// app-2: two asks reserved on different nodes
+	app2 := newApplication(appID2, "default", "root.leaf")
+	err = partition.AddApplication(app2)
+	assert.NilError(t, err, "failed to add app-2 to partition")
+	ask = newAllocationAsk("alloc-1", appID2, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 3}))
+	err = app2.AddAllocationAsk(ask)
+	assert.NilError(t, err, "failed to add ask alloc-1 to app-2")
+	partition.reserve(app2, node3, ask)
+	ask = newAllocationAsk("alloc-2", appID2, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1}))
+	err = app2.AddAllocationAsk(ask)
+	assert.NilError(t, err, "failed to add ask alloc-2 to app-2")
+	partition.reserve(app2, node4, ask)
+	assert.Equal(t, len(partition.getReservations()), 2, "partition should have reserved apps")
+
+	expected := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 8})
+	assert.Assert(t, resources.Equals(partition.GetReservedCapacity(), expected), "unexpected reserved capacity: %s", partition.GetReservedCapacity())
+	assert.DeepEqual(t, partition.GetReservedCapacityFraction(), map[string]float64{"first": 0.2})
+
+	// removing a reservation lowers the reserved capacity
+	partition.unReserve(app2, node3, app2.GetSchedulingAllocationAsk("alloc-1"))
+	expected = resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5})
+	assert.Assert(t, resources.Equals(partition.GetReservedCapacity(), expected), "unexpected reserved capacity: %s", partition.GetReservedCapacity())
+	assert.DeepEqual(t, partition.GetReservedCapacityFraction(), map[string]float64{"first": 0.125})
+}
+
+func TestReservationTimeoutProperty(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	assert.Equal(t, partition.getReservationTimeout(), time.Duration(0), "reservation timeout should not be set")
+
+	conf := configs.PartitionConfig{
+		Name: "test",
+		Queues: []configs.QueueConfig{
+			{
+				Name:      "root",
+				Parent:    true,
+				SubmitACL: "*",
+				Queues:    nil,
+			},
+		},
+		Properties: map[string]string{configs.ReservationTimeout: "10m"},
+	}
+	err = partition.updatePartitionDetails(conf)
+	assert.NilError(t, err, "update partition failed unexpected with error")
+	assert.Equal(t, partition.getReservationTimeout(), 10*time.Minute, "reservation timeout not updated")
+
+	// removing the property resets the timeout
+	conf.Properties = nil
+	err = partition.updatePartitionDetails(conf)
+	assert.NilError(t, err, "update partition failed unexpected with error")
+	assert.Equal(t, partition.getReservationTimeout(), time.Duration(0), "reservation timeout should have been reset")
+}
+
+func TestSchedulingInterval(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	assert.Equal(t, partition.GetSchedulingInterval(), 100*time.Millisecond, "default scheduling interval not set")
+
+	conf := configs.PartitionConfig{
+		Name: "test",
+		Queues: []configs.QueueConfig{
+			{
+				Name:      "root",
+				Parent:    true,
+				SubmitACL: "*",
+				Queues:    nil,
+			},
+		},
+		SchedulingIntervalMs: 10,
+	}
+	err = partition.updatePartitionDetails(conf)
+	assert.NilError(t, err, "update partition failed unexpected with error")
+	assert.Equal(t, partition.GetSchedulingInterval(), 10*time.Millisecond, "scheduling interval not updated")
+	assert.Equal(t, partition.ExportConfig().SchedulingIntervalMs, 10, "scheduling interval not exported")
+
+	// removing the setting resets the interval
+	conf.SchedulingIntervalMs = 0
+	err = partition.updatePartitionDetails(conf)
+	assert.NilError(t, err, "update partition failed unexpected with error")
+	assert.Equal(t, partition.GetSchedulingInterval(), 100*time.Millisecond, "scheduling interval should have been reset")
+	assert.Equal(t, partition.ExportConfig().SchedulingIntervalMs, 0, "default scheduling interval should not be exported")
+}
+
+func TestClusterSchedulingInterval(t *testing.T) {
+	cc := &ClusterContext{partitions: map[string]*PartitionContext{}}
+	assert.Equal(t, cc.getSchedulingInterval(), 100*time.Millisecond, "no partitions should return the default interval")
+
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	conf := configs.PartitionConfig{
+		Name: "second",
+		Queues: []configs.QueueConfig{
+			{
+				Name:      "root",
+				Parent:    true,
+				SubmitACL: "*",
+				Queues:    nil,
+			},
+		},
+		SchedulingIntervalMs: 50,
+	}
+	var second *PartitionContext
+	second, err = newPartitionContext(conf, rmID, nil)
+	assert.NilError(t, err, "second partition create failed")
+	cc.partitions[partition.Name] = partition
+	cc.partitions[second.Name] = second
+	assert.Equal(t, cc.getSchedulingInterval(), 50*time.Millisecond, "shortest interval of all partitions not returned")
+
+	// a config change is picked up on the next call
+	conf.SchedulingIntervalMs = 200
+	err = second.updatePartitionDetails(conf)
+	assert.NilError(t, err, "update partition failed unexpected with error")
+	assert.Equal(t, cc.getSchedulingInterval(), 100*time.Millisecond, "interval change not picked up")
+}
+
+func TestAddNodeRegistrationTimeout(t *testing.T) {
+	conf := configs.PartitionConfig{
+		Name: "test",
+		Queues: []configs.QueueConfig{
+			{
+				Name:      "root",
+				Parent:    true,
+				SubmitACL: "*",
+				Queues:    []configs.QueueConfig{{Name: "default"}},
+			},
+		},
+		// any replay takes longer than this
+		NodeRegistrationTimeout: time.Nanosecond,
+	}
+	partition, err := newPartitionContext(conf, rmID, nil)
+	assert.NilError(t, err, "partition create failed")
+	assert.Equal(t, partition.ExportConfig().NodeRegistrationTimeout, time.Nanosecond, "timeout not exported")
+	err = partition.AddApplication(newApplication(appID1, "default", defQueue))
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	before := getNodeRegistrationTimeouts(t)
+
+	// node without allocations has nothing to replay
+	err = partition.AddNode(newNodeMaxResource(nodeID1, resources.Multiply(res, 10)), nil)
+	assert.NilError(t, err, "node without allocations should have been added")
+
+	allocs := []*objects.Allocation{objects.NewAllocation("uuid-1", nodeID2, newAllocationAsk("alloc-1", appID1, res))}
+	err = partition.AddNode(newNodeMaxResource(nodeID2, resources.Multiply(res, 10)), allocs)
+	assert.ErrorContains(t, err, "registration did not complete", "node registration should have timed out")
+	assert.Assert(t, partition.GetNode(nodeID2) == nil, "node should have been removed")
+	app := partition.getApplication(appID1)
+	assert.Equal(t, len(app.GetAllAllocations()), 0, "replayed allocation should have been removed")
+	assert.Equal(t, getNodeRegistrationTimeouts(t)-before, 1.0, "timeout not counted")
+
+	// no timeout: the node is added
+	conf.NodeRegistrationTimeout = 0
+	err = partition.updatePartitionDetails(conf)
+	assert.NilError(t, err, "update partition failed unexpected with error")
+	err = partition.AddNode(newNodeMaxResource(nodeID2, resources.Multiply(res, 10)), allocs)
+	assert.NilError(t, err, "node should have been added without timeout")
+	assert.Equal(t, len(app.GetAllAllocations()), 1, "replayed allocation should have been added")
+	assert.Equal(t, getNodeRegistrationTimeouts(t)-before, 1.0, "unexpected timeout counted")
+}
+
+// read the node registration timeout counter from the registered metrics
+func getNodeRegistrationTimeouts(t *testing.T) float64 {
+	metrics.GetSchedulerMetrics()
+	families, err := prometheus.DefaultGatherer.Gather()
+	assert.NilError(t, err, "failed to gather metrics")
+	for _, family := range families {
+		if family.GetName() == "yunikorn_node_registration_timeout_total" {
+			return family.GetMetric()[0].GetCounter().GetValue()
+		}
+	}
+	t.Fatal("node registration timeout metric not registered")
+	return 0
+}
+
+func TestExportConfig(t *testing.T) {
+	conf := configs.PartitionConfig{
+		Name: "test",
+		Queues: []configs.QueueConfig{
+			{
+				Name:      "root",
+				Parent:    true,
+				SubmitACL: "*",
+				Queues: []configs.QueueConfig{
+					{
+						Name:     "parent",
+						Parent:   true,
+						AdminACL: "admin group1",
+						Resources: configs.Resources{
+							Max:        map[string]string{"memory": "1000", "vcore": "10"},
+							Guaranteed: map[string]string{"memory": "500"},
+						},
+						Properties: map[string]string{configs.ApplicationSortPolicy: "fair"},
+						Queues: []configs.QueueConfig{
+							{
+								Name:      "leaf1",
+								SubmitACL: "user1,user2",
+								Resources: configs.Resources{
+									Max: map[string]string{"memory": "500"},
+								},
+							},
+							{
+								Name:       "leaf2",
+								Properties: map[string]string{"key": "value"},
+							},
+						},
+					},
+					{
+						Name: "default",
+					},
+				},
+			},
+		},
+		PlacementRules: []configs.PlacementRule{
+			{
+				Name:   "provided",
+				Create: true,
+			},
+		},
+		Preemption:     configs.PartitionPreemptionConfig{Enabled: true},
+		NodeSortPolicy: configs.NodeSortingPolicy{Type: "binpacking"},
+		Properties:     map[string]string{configs.ReservationTimeout: "10m0s"},
+	}
+	partition, err := newPartitionContext(conf, rmID, nil)
+	assert.NilError(t, err, "test partition create failed with error")
+	// add a dynamic queue which should not be exported
+	_, err = partition.createQueue("root.parent.dynamic", security.UserGroup{User: "admin"})
+	assert.NilError(t, err, "dynamic queue create failed with error")
+
+	exported := partition.ExportConfig()
+	assert.Equal(t, exported.Name, "test", "partition name not exported")
+	assert.Assert(t, exported.Preemption.Enabled, "preemption not exported")
+	assert.Equal(t, exported.NodeSortPolicy.Type, "binpacking", "node sort policy not exported")
+	assert.DeepEqual(t, exported.PlacementRules, conf.PlacementRules)
+	assert.DeepEqual(t, exported.Properties, conf.Properties)
+	root := exported.Queues[0]
+	assert.Equal(t, len(root.Queues), 2, "root should have two children exported")
+	parent := root.Queues[1]
+	assert.Equal(t, parent.Name, "parent", "parent queue not exported in order")
+	assert.Equal(t, len(parent.Queues), 2, "dynamic queue should not have been exported")
+	assert.Equal(t, parent.AdminACL, "admin group1", "admin ACL not exported")
+	assert.DeepEqual(t, parent.Resources, conf.Queues[0].Queues[0].Resources)
+
+	// round trip the exported config: nothing should change
+	err = partition.updatePartitionDetails(exported)
+	assert.NilError(t, err, "update partition with exported config failed")
+	assert.DeepEqual(t, partition.ExportConfig(), exported)
+}
+
+func TestNodeEventHistory(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	assert.Assert(t, partition.GetNodeEventHistory(nodeID1) == nil, "unknown node should not have a history")
+
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10})
+	err = partition.AddNode(newNodeMaxResource(nodeID1, nodeRes), nil)
+	assert.NilError(t, err, "add node to partition should not have failed")
+	app := newApplication(appID1, "default", defQueue)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "add application to partition should not have failed")
+	askRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	err = app.AddAllocationAsk(newAllocationAskRepeat("alloc-1", appID1, askRes, 2))
+	assert.NilError(t, err, "failed to add ask to app")
+
+	// allocate twice, release one allocation and remove the node with the remaining allocation
+	alloc := partition.tryAllocate()
+	assert.Assert(t, alloc != nil, "first allocation failed")
+	released := partition.removeAllocation(appID1, alloc.UUID)
+	assert.Equal(t, len(released), 1, "allocation should have been released")
+	alloc = partition.tryAllocate()
+	assert.Assert(t, alloc != nil, "second allocation failed")
+	partition.addNodeEvent(nodeID1, NodeMarkedDraining, nil)
+	released = partition.removeNode(nodeID1)
+	assert.Equal(t, len(released), 1, "allocation should have been released with the node")
+
+	expected := []NodeEventType{NodeAdded, NodeAllocationAdded, NodeAllocationRemoved, NodeAllocationAdded, NodeMarkedDraining, NodeAllocationRemoved, NodeRemoved}
+	history := partition.GetNodeEventHistory(nodeID1)
+	assert.Equal(t, len(history), len(expected), "unexpected number of events")
+	for i, event := range history {
+		assert.Equal(t, event.Type, expected[i], "unexpected event type at %d", i)
+	}
+	assert.Assert(t, resources.Equals(history[0].Resource, nodeRes), "node added event should have the node capacity")
+	assert.Assert(t, resources.Equals(history[1].Resource, askRes), "allocation added event should have the allocated resource")
+	assert.Assert(t, history[4].Resource == nil, "draining event should not have a resource")
+	assert.Assert(t, !history[6].Time.Before(history[0].Time), "events should be in time order")
+
+	// history is capped: oldest events are dropped
+	for i := 0; i < maxNodeEvents; i++ {
+		partition.addNodeEvent(nodeID1, NodeAllocationAdded, askRes)
+	}
+	history = partition.GetNodeEventHistory(nodeID1)
+	assert.Equal(t, len(history), maxNodeEvents, "history should have been capped")
+	assert.Equal(t, history[0].Type, NodeAllocationAdded, "oldest events should have been dropped")
+
+	// the history is only kept for a limited number of removed nodes
+	for i := 0; i < maxRemovedNodeEventLogs; i++ {
+		nodeID := fmt.Sprintf("removed-%d", i)
+		err = partition.AddNode(newNodeMaxResource(nodeID, nodeRes), nil)
+		assert.NilError(t, err, "add node to partition should not have failed")
+		partition.removeNode(nodeID)
+	}
+	assert.Assert(t, partition.GetNodeEventHistory(nodeID1) == nil, "history of the oldest removed node should have been dropped")
+	assert.Assert(t, partition.GetNodeEventHistory("removed-0") != nil, "history of a recently removed node should be kept")
+	assert.Equal(t, len(partition.nodeEventLog), maxRemovedNodeEventLogs, "unexpected number of node histories")
+	// a node that is added again is not a removed node
+	err = partition.AddNode(newNodeMaxResource("removed-0", nodeRes), nil)
+	assert.NilError(t, err, "add node to partition should not have failed")
+	for _, nodeID := range []string{"extra-1", "extra-2"} {
+		err = partition.AddNode(newNodeMaxResource(nodeID, nodeRes), nil)
+		assert.NilError(t, err, "add node to partition should not have failed")
+		partition.removeNode(nodeID)
+	}
+	assert.Assert(t, partition.GetNodeEventHistory("removed-0") != nil, "history of an active node should be kept")
+	assert.Assert(t, partition.GetNodeEventHistory("removed-1") == nil, "history of the oldest removed node should have been dropped")
+}
+
+func TestGetAllocationByUUID(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	assert.Assert(t, partition.GetAllocationByUUID("unknown") == nil, "unknown allocation should return nil")
+	assert.Assert(t, partition.GetAllocationByKey(appID1, "unknown") == nil, "unknown application should return nil")
+
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	err = partition.AddNode(newNodeMaxResource(nodeID1, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10})), nil)
+	assert.NilError(t, err, "add node to partition should not have failed")
+	app := newApplication(appID1, "default", defQueue)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "add application to partition should not have failed")
+	err = app.AddAllocationAsk(newAllocationAsk("alloc-1", appID1, res))
+	assert.NilError(t, err, "failed to add ask to app")
+	alloc := partition.tryAllocate()
+	assert.Assert(t, alloc != nil, "allocation failed")
+
+	found := partition.GetAllocationByUUID(alloc.UUID)
+	assert.Assert(t, found != nil, "allocation not found by UUID")
+	assert.Equal(t, found.NodeID, nodeID1, "unexpected node for allocation")
+	assert.Assert(t, found != alloc, "allocation returned should be a copy")
+	// changing the copy should not change the allocation
+	found.AllocatedResource.AddTo(res)
+	assert.Assert(t, resources.Equals(partition.GetAllocationByUUID(alloc.UUID).AllocatedResource, res), "allocated resource should not have changed")
+
+	found = partition.GetAllocationByKey(appID1, "alloc-1")
+	assert.Assert(t, found != nil, "allocation not found by key")
+	assert.Equal(t, found.UUID, alloc.UUID, "unexpected allocation found by key")
+	assert.Assert(t, partition.GetAllocationByKey(appID1, "unknown") == nil, "unknown allocation key should return nil")
+}
+
+func TestGetResourceByQueue(t *testing.T) {
+	partition, err := newConfiguredPartition()
+	assert.NilError(t, err, "partition create failed")
+	node := newNodeMaxResource(nodeID1, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 100}))
+	err = partition.AddNode(node, nil)
+	assert.NilError(t, err, "add node to partition should not have failed")
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 2})
+	for appID, queue := range map[string]string{appID1: "root.leaf", appID2: "root.parent.sub-leaf"} {
+		app := newApplication(appID, "default", queue)
+		err = partition.AddApplication(app)
+		assert.NilError(t, err, "add application to partition should not have failed")
+		err = partition.addAllocation(objects.NewAllocation(appID+"-uuid", nodeID1, newAllocationAsk("alloc", appID, res)))
+		assert.NilError(t, err, "add allocation to partition should not have failed")
+		err = app.AddAllocationAsk(newAllocationAskRepeat("ask", appID, res, 2))
+		assert.NilError(t, err, "failed to add ask to app")
+	}
+
+	paths := []string{"root", "root.leaf", "root.parent", "root.parent.sub-leaf"}
+	for _, byQueue := range []map[string]*resources.Resource{partition.GetAllocatedResourceByQueue(), partition.GetPendingResourceByQueue()} {
+		assert.Equal(t, len(byQueue), len(paths), "unexpected number of queues: %v", byQueue)
+		for _, path := range paths {
+			assert.Assert(t, byQueue[path] != nil, "queue %s missing", path)
+		}
+		assert.Assert(t, resources.Equals(byQueue["root"], resources.Add(byQueue["root.leaf"], byQueue["root.parent"])), "root is not the sum of its children")
+		assert.Assert(t, resources.Equals(byQueue["root.parent"], byQueue["root.parent.sub-leaf"]), "parent is not the sum of its children")
+	}
+	assert.Assert(t, resources.Equals(partition.GetAllocatedResourceByQueue()["root"], resources.Multiply(res, 2)), "unexpected allocated resource on root")
+	assert.Assert(t, resources.Equals(partition.GetPendingResourceByQueue()["root"], resources.Multiply(res, 4)), "unexpected pending resource on root")
+}
+
+func TestGetPreemptionCandidates(t *testing.T) {
+	partition, err := newConfiguredPartition()
+	assert.NilError(t, err, "partition create failed")
+	node := newNodeMaxResource(nodeID1, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 100}))
+	err = partition.AddNode(node, nil)
+	assert.NilError(t, err, "add node to partition should not have failed")
+	for _, app := range []*objects.Application{
+		newApplication(appID1, "default", "root.parent.sub-leaf"),
+		newApplication(appID2, "default", "root.parent.sub-leaf"),
+		newApplication("app-3", "default", "root.leaf"),
+	} {
+		err = partition.AddApplication(app)
+		assert.NilError(t, err, "add application to partition should not have failed")
+	}
+	small := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	large := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5})
+	addAlloc := func(uuid, appID, queue string, res *resources.Resource, prio int32) {
+		alloc := objects.NewAllocation(uuid, nodeID1, newAllocationAskPriority(uuid, appID, res, 1, prio))
+		alloc.QueueName = queue
+		err = partition.addAllocation(alloc)
+		assert.NilError(t, err, "add allocation to partition should not have failed")
+		// make sure the creation times differ
+		time.Sleep(time.Millisecond)
+	}
+	addAlloc("low-old", appID2, "root.parent.sub-leaf", large, 1)
+	addAlloc("lowest", appID2, "root.parent.sub-leaf", large, 0)
+	addAlloc("low-new", appID2, "root.parent.sub-leaf", large, 1)
+	addAlloc("equal", appID2, "root.parent.sub-leaf", large, 5)
+	addAlloc("too-small", appID2, "root.parent.sub-leaf", small, 1)
+	addAlloc("other-subtree", "app-3", "root.leaf", large, 1)
+	addAlloc("own-app", appID1, "root.parent.sub-leaf", large, 1)
+
+	assert.Assert(t, partition.GetPreemptionCandidates(nil) == nil, "nil ask should not return candidates")
+	ask := newAllocationAskPriority("ask-1", appID1, large, 1, 5)
+	ask.QueueName = "root.parent.sub-leaf"
+	candidates := partition.GetPreemptionCandidates(ask)
+	uuids := make([]string, 0)
+	for _, alloc := range candidates {
+		uuids = append(uuids, alloc.UUID)
+	}
+	assert.DeepEqual(t, uuids, []string{"lowest", "low-new", "low-old"})
+
+	// an ask in a queue directly below root sees the whole partition
+	ask = newAllocationAskPriority("ask-2", "app-3", large, 1, 5)
+	ask.QueueName = "root.leaf"
+	candidates = partition.GetPreemptionCandidates(ask)
+	assert.Equal(t, len(candidates), 4, "expected all lower priority allocations of the other applications")
+	assert.Equal(t, candidates[0].UUID, "lowest", "weakest candidate not first")
+
+	// the priority class offset of the application raises the ask above the equal priority allocation
+	app := partition.getApplication(appID1)
+	app.SetPriorityClass("boost", 1)
+	ask = newAllocationAskPriority("ask-3", appID1, large, 1, 5)
+	err = app.AddAllocationAsk(ask)
+	assert.NilError(t, err, "add ask to application should not have failed")
+	ask.QueueName = "root.parent.sub-leaf"
+	candidates = partition.GetPreemptionCandidates(ask)
+	assert.Equal(t, len(candidates), 4, "expected the equal priority allocation to be a candidate")
+	assert.Equal(t, candidates[3].UUID, "equal", "strongest candidate not last")
+
+	// the priority class offset of the victim is part of its priority: a boosted allocation is not a candidate
+	app4 := newApplication("app-4", "default", "root.parent.sub-leaf")
+	err = partition.AddApplication(app4)
+	assert.NilError(t, err, "add application to partition should not have failed")
+	app4.SetPriorityClass("boost", 10)
+	boosted := newAllocationAskPriority("boosted", "app-4", large, 1, 1)
+	err = app4.AddAllocationAsk(boosted)
+	assert.NilError(t, err, "add ask to application should not have failed")
+	alloc := objects.NewAllocation("boosted", nodeID1, boosted)
+	alloc.QueueName = "root.parent.sub-leaf"
+	err = partition.addAllocation(alloc)
+	assert.NilError(t, err, "add allocation to partition should not have failed")
+	candidates = partition.GetPreemptionCandidates(ask)
+	assert.Equal(t, len(candidates), 4, "boosted allocation should not be a candidate")
+	for _, candidate := range candidates {
+		assert.Assert(t, candidate.UUID != "boosted", "boosted allocation returned as a candidate")
+	}
+}
+
+func TestCalculateNodesResourceUsage(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	err = partition.AddApplication(newApplication(appID1, "default", defQueue))
+	assert.NilError(t, err, "add application to partition should not have failed")
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 100, "second": 0})
+	for i, used := range []resources.Quantity{0, 5, 50, 100} {
+		nodeID := fmt.Sprintf("node-%d", i)
+		var allocs []*objects.Allocation
+		if used > 0 {
+			res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": used})
+			allocs = append(allocs, objects.NewAllocation(nodeID+"-uuid", nodeID, newAllocationAsk(nodeID, appID1, res)))
+		}
+		err = partition.AddNode(newNodeMaxResource(nodeID, nodeRes), allocs)
+		assert.NilError(t, err, "add node to partition should not have failed")
+	}
+	usage := partition.CalculateNodesResourceUsage()
+	// zero capacity resources are not reported
+	assert.Equal(t, len(usage), 1, "unexpected resource types: %v", usage)
+	assert.DeepEqual(t, usage["first"], []int{2, 0, 0, 0, 1, 0, 0, 0, 0, 1})
+}
+
+func TestGetUtilizationHistogram(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	assert.Assert(t, partition.GetUtilizationHistogram(1) == nil, "1 bucket should not be supported")
+	assert.Assert(t, partition.GetUtilizationHistogram(101) == nil, "101 buckets should not be supported")
+	assert.Equal(t, len(partition.GetUtilizationHistogram(10)), 0, "empty partition should not report usage")
+
+	err = partition.AddApplication(newApplication(appID1, "default", defQueue))
+	assert.NilError(t, err, "add application to partition should not have failed")
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 100, "second": 0})
+	// boundary values for 2, 10 and 100 buckets
+	for i, used := range []resources.Quantity{0, 1, 5, 10, 30, 50, 51, 99, 100} {
+		nodeID := fmt.Sprintf("node-%d", i)
+		var allocs []*objects.Allocation
+		if used > 0 {
+			res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": used})
+			allocs = append(allocs, objects.NewAllocation(nodeID+"-uuid", nodeID, newAllocationAsk(nodeID, appID1, res)))
+		}
+		err = partition.AddNode(newNodeMaxResource(nodeID, nodeRes), allocs)
+		assert.NilError(t, err, "add node to partition should not have failed")
+	}
+
+	usage := partition.GetUtilizationHistogram(2)
+	// zero capacity resources are not reported
+	assert.Equal(t, len(usage), 1, "unexpected resource types: %v", usage)
+	assert.DeepEqual(t, usage["first"], []int{6, 3})
+
+	usage = partition.GetUtilizationHistogram(10)
+	assert.DeepEqual(t, usage["first"], []int{4, 0, 1, 0, 1, 1, 0, 0, 0, 2})
+	assert.DeepEqual(t, usage, partition.CalculateNodesResourceUsage())
+
+	usage = partition.GetUtilizationHistogram(100)
+	expected := make([]int, 100)
+	// 0% and 1% in the first bucket, every other node in the bucket below its percentage
+	expected[0] = 2
+	for _, pct := range []int{5, 10, 30, 50, 51, 99, 100} {
+		expected[pct-1]++
+	}
+	assert.DeepEqual(t, usage["first"], expected)
+}
+
+func TestGetLargestFreeNode(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	var nilNode *objects.Node
+	assert.Equal(t, partition.GetLargestFreeNode(), nilNode, "empty partition should not return a node")
+	assert.Equal(t, partition.GetLargestFreeNodeByResource("first"), nilNode, "empty partition should not return a node")
+
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10, "second": 100})
+	nodes := map[string]*resources.Resource{
+		// 50% of first and 90% of second free
+		nodeID1: resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5, "second": 10}),
+		// 80% of first and 20% of second free
+		nodeID2: resources.NewResourceFromMap(map[string]resources.Quantity{"first": 2, "second": 80}),
+		// 30% of first and 60% of second free
+		"node-3": resources.NewResourceFromMap(map[string]resources.Quantity{"first": 7, "second": 40}),
+	}
+	for nodeID, occupied := range nodes {
+		err = partition.AddNode(newNodeWithResources(nodeID, nodeRes, occupied), nil)
+		assert.NilError(t, err, "add node to partition should not have failed")
+	}
+	assert.Equal(t, partition.GetLargestFreeNode().NodeID, nodeID1, "wrong node for dominant free fraction")
+	assert.Equal(t, partition.GetLargestFreeNodeByResource("first").NodeID, nodeID2, "wrong node for first resource")
+	assert.Equal(t, partition.GetLargestFreeNodeByResource("second").NodeID, nodeID1, "wrong node for second resource")
+	assert.Equal(t, partition.GetLargestFreeNodeByResource("unknown"), nilNode, "no node has the resource type")
+
+	// ties return the same node every time
+	tied := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5, "second": 10})
+	err = partition.AddNode(newNodeWithResources("node-0", nodeRes, tied), nil)
+	assert.NilError(t, err, "add node to partition should not have failed")
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, partition.GetLargestFreeNode().NodeID, "node-0", "tie not broken consistently")
+		assert.Equal(t, partition.GetLargestFreeNodeByResource("second").NodeID, "node-0", "tie not broken consistently")
+	}
+
+	// reserved nodes are not considered
+	app := newApplication(appID1, "default", defQueue)
+	ask := newAllocationAsk("alloc-1", appID1, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1}))
+	for _, node := range partition.GetNodes() {
+		err = node.Reserve(app, ask)
+		assert.NilError(t, err, "reserve on node %s should not have failed", node.NodeID)
+	}
+	assert.Equal(t, partition.GetLargestFreeNode(), nilNode, "all nodes reserved should not return a node")
+	assert.Equal(t, partition.GetLargestFreeNodeByResource("first"), nilNode, "all nodes reserved should not return a node")
+}
+
+func TestGetNodeAllocationDensity(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	assert.Equal(t, len(partition.GetNodeAllocationDensity()), 0, "partition without nodes should not have density")
+	assert.Equal(t, partition.GetPartitionAllocationDensity(), float64(0), "partition without nodes should not have density")
+
+	app := newApplication(appID1, "default", defQueue)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "add application to partition should not have failed")
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 100})
+	// many small allocations
+	smallRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5})
+	small := make([]*objects.Allocation, 0)
+	for i := 0; i < 10; i++ {
+		ask := newAllocationAsk(fmt.Sprintf("small-%d", i), appID1, smallRes)
+		small = append(small, objects.NewAllocation(fmt.Sprintf("small-uuid-%d", i), nodeID1, ask))
+	}
+	err = partition.AddNode(newNodeMaxResource(nodeID1, nodeRes), small)
+	assert.NilError(t, err, "add node to partition should not have failed")
+	// one large allocation
+	largeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 50})
+	large := []*objects.Allocation{objects.NewAllocation("large-uuid", nodeID2, newAllocationAsk("large", appID1, largeRes))}
+	err = partition.AddNode(newNodeMaxResource(nodeID2, nodeRes), large)
+	assert.NilError(t, err, "add node to partition should not have failed")
+	// nodes without capacity are ignored
+	err = partition.AddNode(newNodeMaxResource("node-3", resources.NewResource()), nil)
+	assert.NilError(t, err, "add node to partition should not have failed")
+
+	density := partition.GetNodeAllocationDensity()
+	assert.Equal(t, len(density), 2, "unexpected nodes in the density map: %v", density)
+	assert.Equal(t, density[nodeID1], 20.0, "unexpected density for the node with small allocations")
+	assert.Equal(t, density[nodeID2], 2.0, "unexpected density for the node with a large allocation")
+	assert.Assert(t, density[nodeID1] > density[nodeID2], "small allocations should give a higher density")
+	assert.Equal(t, partition.GetPartitionAllocationDensity(), 11.0, "unexpected partition density")
+}
+
+func TestGetNodeUtilizationExtremes(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	nodeID, util := partition.GetMaxNodeUtilization()
+	assert.Equal(t, nodeID, "", "empty partition should not return a node")
+	assert.Equal(t, util, float64(0), "empty partition should not return a utilisation")
+	nodeID, util = partition.GetMinNodeUtilization()
+	assert.Equal(t, nodeID, "", "empty partition should not return a node")
+	assert.Equal(t, util, float64(0), "empty partition should not return a utilisation")
+
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10, "second": 100})
+	nodes := map[string]*resources.Resource{
+		// dominant utilisation 50%
+		nodeID1: resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5, "second": 10}),
+		// dominant utilisation 80%
+		nodeID2: resources.NewResourceFromMap(map[string]resources.Quantity{"first": 2, "second": 80}),
+		// dominant utilisation 70%
+		"node-3": resources.NewResourceFromMap(map[string]resources.Quantity{"first": 7, "second": 40}),
+		// ties: same utilisation as node-1 and node-2
+		"node-0": resources.NewResourceFromMap(map[string]resources.Quantity{"first": 5, "second": 10}),
+		"node-4": resources.NewResourceFromMap(map[string]resources.Quantity{"first": 2, "second": 80}),
+	}
+	err = partition.AddApplication(newApplication(appID1, "default", defQueue))
+	assert.NilError(t, err, "add application to partition should not have failed")
+	for id, used := range nodes {
+		ask := newAllocationAsk("alloc-"+id, appID1, used)
+		alloc := objects.NewAllocation("uuid-"+id, id, ask)
+		err = partition.AddNode(newNodeMaxResource(id, nodeRes), []*objects.Allocation{alloc})
+		assert.NilError(t, err, "add node to partition should not have failed")
+	}
+	// ties return the same node every time
+	for i := 0; i < 10; i++ {
+		nodeID, util = partition.GetMaxNodeUtilization()
+		assert.Equal(t, nodeID, nodeID2, "wrong node with highest utilisation")
+		assert.Equal(t, util, 0.8, "wrong highest utilisation")
+		nodeID, util = partition.GetMinNodeUtilization()
+		assert.Equal(t, nodeID, "node-0", "wrong node with lowest utilisation")
+		assert.Equal(t, util, 0.5, "wrong lowest utilisation")
+	}
+}
+
+func TestTryAllocateStorage(t *testing.T) {
+	partition, err := newConfiguredPartition()
+	assert.NilError(t, err, "partition create failed")
+	for nodeID, storage := range map[string]resources.Quantity{nodeID1: 100, nodeID2: 50} {
+		nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 1000, resources.STORAGE: storage})
+		err = partition.AddNode(newNodeMaxResource(nodeID, nodeRes), nil)
+		assert.NilError(t, err, "add node %s to partition should not have failed", nodeID)
+	}
+	app := newApplication(appID1, "default", "root.leaf")
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "add application to partition should not have failed")
+
+	// only the node with enough storage fits the ask
+	askRes := resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 10, resources.STORAGE: 80})
+	err = app.AddAllocationAsk(newAllocationAsk("alloc-1", appID1, askRes))
+	assert.NilError(t, err, "failed to add ask alloc-1 to app")
+	alloc := partition.tryAllocate()
+	if alloc == nil {
+		t.Fatal("allocation did not return any allocation")
+	}
+	assert.Equal(t, alloc.Result, objects.Allocated, "result is not the expected allocated")
+	assert.Equal(t, alloc.NodeID, nodeID1, "storage ask placed on node without enough storage")
+	assert.Equal(t, partition.GetNode(nodeID1).GetAvailableEphemeralStorage(), int64(20), "unexpected storage left on node-1")
+	assert.Equal(t, partition.GetNode(nodeID2).GetAvailableEphemeralStorage(), int64(50), "unexpected storage left on node-2")
+
+	// no node has enough storage left: the ask is not placed
+	err = app.AddAllocationAsk(newAllocationAsk("alloc-2", appID1, askRes))
+	assert.NilError(t, err, "failed to add ask alloc-2 to app")
+	alloc = partition.tryAllocate()
+	if alloc != nil && alloc.Result == objects.Allocated {
+		t.Fatalf("ask exceeding the available storage was allocated: %s", alloc)
+	}
+	assert.Equal(t, len(partition.GetNode(nodeID1).GetAllAllocations()), 1, "unexpected allocations on node-1")
+	assert.Equal(t, len(partition.GetNode(nodeID2).GetAllAllocations()), 0, "unexpected allocations on node-2")
+}
+
+func TestDrainQueue(t *testing.T) {
+	partition, err := newConfiguredPartition()
+	assert.NilError(t, err, "partition create failed")
+	manager := partitionManager{pc: partition}
+	err = partition.DrainQueue("root")
+	assert.ErrorContains(t, err, "root queue", "root queue should not be drained")
+	err = partition.DrainQueue("root.unknown")
+	assert.ErrorContains(t, err, "not found", "unknown queue should not be drained")
+
+	// managed queue still in the config: drained but not removed
+	err = partition.AddApplication(newApplication(appID1, "default", "root.leaf"))
+	assert.NilError(t, err, "add application to partition should not have failed")
+	err = partition.DrainQueue("root.leaf")
+	assert.NilError(t, err, "drain of managed queue should not have failed")
+	err = partition.DrainQueue("root.leaf")
+	assert.ErrorContains(t, err, "already draining", "second drain should have failed")
+	err = partition.AddApplication(newApplication(appID2, "default", "root.leaf"))
+	assert.ErrorContains(t, err, "draining", "submission to a draining queue should have been rejected")
+	partition.removeApplication(appID1)
+	manager.cleanQueues(partition.root)
+	queue := partition.GetQueue("root.leaf")
+	assert.Assert(t, queue != nil && queue.IsDraining(), "drained managed queue should not have been removed")
+	// removed from the config: the empty queue is removed
+	queue.MarkQueueForRemoval()
+	manager.cleanQueues(partition.root)
+	assert.Assert(t, partition.GetQueue("root.leaf") == nil, "drained queue removed from config should have been removed")
+
+	// dynamic queue: removed after the last application is removed
+	_, err = partition.createQueue("root.parent.dynamic", security.UserGroup{})
+	assert.NilError(t, err, "dynamic queue create should not have failed")
+	err = partition.AddApplication(newApplication(appID1, "default", "root.parent.dynamic"))
+	assert.NilError(t, err, "add application to partition should not have failed")
+	// drain the parent: the children are drained too
+	err = partition.DrainQueue("root.parent")
+	assert.NilError(t, err, "drain of parent queue should not have failed")
+	assert.Assert(t, partition.GetQueue("root.parent.sub-leaf").IsDraining(), "managed child should be draining")
+	err = partition.AddApplication(newApplication(appID2, "default", "root.parent.dynamic"))
+	assert.ErrorContains(t, err, "draining", "submission to a draining queue should have been rejected")
+	manager.cleanQueues(partition.root)
+	assert.Assert(t, partition.GetQueue("root.parent.dynamic") != nil, "dynamic queue with application should not have been removed")
+	partition.removeApplication(appID1)
+	manager.cleanQueues(partition.root)
+	assert.Assert(t, partition.GetQueue("root.parent.dynamic") == nil, "empty drained dynamic queue should have been removed")
+	assert.Assert(t, partition.GetQueue("root.parent") != nil, "drained managed parent should not have been removed")
+}
+
+func TestUpdateQueueMaxResource(t *testing.T) {
+	partition, err := newConfiguredPartition()
+	assert.NilError(t, err, "partition create failed")
+	max := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10})
+	err = partition.UpdateQueueMaxResource("root.unknown", max)
+	assert.ErrorContains(t, err, "not found", "unknown queue should not be updated")
+	// the config owns the max of a managed queue
+	err = partition.UpdateQueueMaxResource("root.leaf", max)
+	assert.ErrorContains(t, err, "configuration", "managed queue max should not be updated")
+	err = partition.UpdateQueueMaxResource("root", max)
+	assert.ErrorContains(t, err, "configuration", "root queue max should not be updated")
+
+	_, err = partition.createQueue("root.parent.dynamic", security.UserGroup{})
+	assert.NilError(t, err, "dynamic queue create should not have failed")
+	err = partition.UpdateQueueMaxResource("root.parent.dynamic", max)
+	assert.NilError(t, err, "unmanaged queue max update should not have failed")
+	assert.Assert(t, resources.Equals(partition.GetQueue("root.parent.dynamic").GetMaxResource(), max), "unmanaged queue max not updated")
+}
+
+func TestUndrainQueue(t *testing.T) {
+	partition, err := newConfiguredPartition()
+	assert.NilError(t, err, "partition create failed")
+	manager := partitionManager{pc: partition}
+	err = partition.UndrainQueue("root.unknown")
+	assert.ErrorContains(t, err, "not found", "unknown queue should not be undrained")
+	err = partition.UndrainQueue("root.leaf")
+	assert.ErrorContains(t, err, "not drained", "running queue should not be undrained")
+
+	// drained on request: accepts applications again after the undrain
+	err = partition.DrainQueue("root.leaf")
+	assert.NilError(t, err, "drain of managed queue should not have failed")
+	err = partition.UndrainQueue("root.leaf")
+	assert.NilError(t, err, "undrain of drained queue should not have failed")
+	assert.Assert(t, partition.GetQueue("root.leaf").IsRunning(), "undrained queue should be running")
+	err = partition.AddApplication(newApplication(appID1, "default", "root.leaf"))
+	assert.NilError(t, err, "submission to an undrained queue should not have failed")
+
+	// the children drained with the parent are undrained with the parent, not on their own
+	err = partition.DrainQueue("root.parent")
+	assert.NilError(t, err, "drain of parent queue should not have failed")
+	err = partition.UndrainQueue("root.parent.sub-leaf")
+	assert.ErrorContains(t, err, "parent", "child of a draining parent should not be undrained")
+	err = partition.UndrainQueue("root.parent")
+	assert.NilError(t, err, "undrain of parent queue should not have failed")
+	assert.Assert(t, partition.GetQueue("root.parent").IsRunning(), "undrained parent should be running")
+	assert.Assert(t, partition.GetQueue("root.parent.sub-leaf").IsRunning(), "child should be undrained with the parent")
+
+	// removed from the config: cannot be undrained
+	partition.GetQueue("root.leaf").MarkQueueForRemoval()
+	err = partition.UndrainQueue("root.leaf")
+	assert.ErrorContains(t, err, "not drained", "queue removed from the config should not be undrained")
+	partition.removeApplication(appID1)
+	manager.cleanQueues(partition.root)
+	assert.Assert(t, partition.GetQueue("root.leaf") == nil, "queue removed from config should have been removed")
+}
+
+func TestSchedulingCycleCount(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	assert.Equal(t, partition.GetSchedulingCycleCount(), int64(0), "new partition should not have run a cycle")
+	assert.Assert(t, partition.GetLastSchedulingCycleTime().IsZero(), "new partition should not have a last cycle time")
+	cc := &ClusterContext{partitions: map[string]*PartitionContext{partition.Name: partition}}
+
+	// no resources in the partition: the partition is skipped
+	cc.schedule()
+	assert.Equal(t, partition.GetSchedulingCycleCount(), int64(0), "partition without resources should not run a cycle")
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10})
+	err = partition.AddNode(newNodeMaxResource(nodeID1, nodeRes), nil)
+	assert.NilError(t, err, "add node to partition should not have failed")
+
+	// read concurrently while the scheduler loop runs
+	const cycles = 500
+	done := make(chan struct{})
+	readers := 4
+	errs := make(chan error, readers)
+	for i := 0; i < readers; i++ {
+		go func() {
+			var last int64
+			for {
+				select {
+				case <-done:
+					errs <- nil
+					return
+				default:
+				}
+				count := partition.GetSchedulingCycleCount()
+				if count < last {
+					errs <- fmt.Errorf("cycle count decreased from %d to %d", last, count)
+					return
+				}
+				last = count
+			}
+		}()
+	}
+	start := time.Now()
+	for i := 0; i < cycles; i++ {
+		cc.schedule()
+	}
+	close(done)
+	for i := 0; i < readers; i++ {
+		assert.NilError(t, <-errs)
+	}
+	assert.Equal(t, partition.GetSchedulingCycleCount(), int64(cycles), "cycle increments missing")
+	last := partition.GetLastSchedulingCycleTime()
+	assert.Assert(t, !last.Before(start) && !last.After(time.Now()), "unexpected last cycle time %v", last)
+}
@@ -43,18 +43,16 @@ func (fr *fixedRule) getName() string {
 	return "fixed"
 }
 
+func (fr *fixedRule) getParam() string {
+	return fr.queue
+}
+
 func (fr *fixedRule) initialise(conf configs.PlacementRule) error {
 	fr.queue = normalise(conf.Value)
-	if fr.queue == "" {
-		return fmt.Errorf("a fixed queue rule must have a queue name set")
-	}
 	fr.create = conf.Create
+	fr.fallback = conf.FallbackOnError
 	fr.filter = newFilter(conf.Filter)
-	// if we have a fully qualified queue name already we should not have a parent
 	fr.qualified = strings.HasPrefix(fr.queue, configs.RootQueue)
-	if fr.qualified && conf.Parent != nil {
-		return fmt.Errorf("cannot have a fixed queue rule with qualified queue getName and a parent rule: %v", conf)
-	}
 	var err = error(nil)
 	if conf.Parent != nil {
 		fr.parent, err = newRule(*conf.Parent)
@@ -62,6 +60,24 @@ func (fr *fixedRule) initialise(conf configs.PlacementRule) error {
 	return err
 }
 
+func (fr *fixedRule) validate() error {
+	if fr.queue == "" {
+		return fmt.Errorf("a fixed queue rule must have a queue name set")
+	}
+	// if we have a fully qualified queue name already we should not have a parent
+	if fr.qualified {
+		if fr.parent != nil {
+			return fmt.Errorf("cannot have a fixed queue rule with qualified queue name %s and a parent rule", fr.queue)
+		}
+		for _, name := range strings.Split(fr.queue, configs.DOT) {
+			if !configs.QueueNameRegExp.MatchString(name) {
+				return fmt.Errorf("fixed queue rule has an invalid queue name %s in qualified queue %s", name, fr.queue)
+			}
+		}
+	}
+	return nil
+}
+
 func (fr *fixedRule) placeApplication(app *objects.Application, queueFn func(string) *objects.Queue) (string, error) {
 	// before anything run the filter
 	if !fr.filter.allowUser(app.GetUser()) {
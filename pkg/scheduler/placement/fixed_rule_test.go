@@ -57,6 +57,27 @@ func TestFixedRule(t *testing.T) {
 	if err == nil || fr != nil {
 		t.Errorf("fixed rule create did not fail with parent rule and qualified child queue name, err 'nil', rule: %v", fr)
 	}
+	// qualified queue name with an invalid queue in the path
+	conf = configs.PlacementRule{
+		Name:  "fixed",
+		Value: "root.invalid!queue",
+	}
+	fr, err = newRule(conf)
+	if err == nil || fr != nil {
+		t.Errorf("fixed rule create did not fail with invalid qualified queue name, err 'nil', rule: %v", fr)
+	}
+	// invalid parent rule
+	conf = configs.PlacementRule{
+		Name:  "fixed",
+		Value: "testchild",
+		Parent: &configs.PlacementRule{
+			Name: "fixed",
+		},
+	}
+	fr, err = newRule(conf)
+	if err == nil || fr != nil {
+		t.Errorf("fixed rule create did not fail with invalid parent rule, err 'nil', rule: %v", fr)
+	}
 }
 
 func TestFixedRulePlace(t *testing.T) {
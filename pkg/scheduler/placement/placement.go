@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -141,12 +142,22 @@ func (m *AppPlacementManager) PlaceApplication(app *objects.Application) error {
 	}
 	var queueName string
 	var err error
+	var placedBy rule
 	for _, checkRule := range m.rules {
 		log.Logger().Debug("Executing rule for placing application",
 			zap.String("ruleName", checkRule.getName()),
 			zap.String("application", app.ApplicationID))
 		queueName, err = checkRule.placeApplication(app, m.queueFn)
 		if err != nil {
+			// try the next rule in the chain if the rule allows it
+			if checkRule.fallbackOnError() {
+				log.Logger().Info("rule execution failed, trying next rule",
+					zap.String("ruleName", checkRule.getName()),
+					zap.String("application", app.ApplicationID),
+					zap.Error(err))
+				queueName = ""
+				continue
+			}
 			log.Logger().Error("rule execution failed",
 				zap.String("ruleName", checkRule.getName()),
 				zap.Error(err))
@@ -198,6 +209,7 @@ func (m *AppPlacementManager) PlaceApplication(app *objects.Application) error {
 				}
 			}
 			// we have a queue that allows submitting and can be created: app placed
+			placedBy = checkRule
 			break
 		}
 	}
@@ -211,5 +223,11 @@ func (m *AppPlacementManager) PlaceApplication(app *objects.Application) error {
 	}
 	// Add the queue into the application, overriding what was submitted
 	app.SetQueueName(queueName)
+	app.SetPlacementAudit(objects.PlacementAudit{
+		RuleName:      placedBy.getName(),
+		RuleParam:     placedBy.getParam(),
+		ResolvedQueue: queueName,
+		Time:          time.Now(),
+	})
 	return nil
 }
@@ -125,6 +125,30 @@ func TestManagerUpdate(t *testing.T) {
 	}
 }
 
+func TestManagerValidateRules(t *testing.T) {
+	// invalid rules should not initialise a new manager
+	invalid := []configs.PlacementRule{
+		{Name: "test"},
+		{Name: "fixed"},
+	}
+	man := NewPlacementManager(invalid, queueFunc)
+	if man.initialised {
+		t.Error("Placement manager marked initialised with invalid rules")
+	}
+	rules := []configs.PlacementRule{
+		{Name: "test"},
+	}
+	err := man.UpdateRules(rules)
+	if err != nil || !man.initialised {
+		t.Errorf("failed to update existing manager, init state: %t, error: %v", man.initialised, err)
+	}
+	// invalid rules should not replace the existing rules
+	err = man.UpdateRules(invalid)
+	if err == nil || !man.initialised || len(man.rules) != 1 {
+		t.Errorf("update with invalid rules should have failed, init state: %t, rules: %d", man.initialised, len(man.rules))
+	}
+}
+
 func TestManagerBuildRule(t *testing.T) {
 	// basic with 1 rule
 	man := NewPlacementManager(nil, queueFunc)
@@ -286,3 +310,113 @@ partitions:
 		t.Errorf("parent queue: app should not have been placed, queue: '%s', error: %v", queueName, err)
 	}
 }
+
+func TestManagerPlaceAppFallbackOnError(t *testing.T) {
+	// Create the structure for the test
+	data := `
+partitions:
+  - name: default
+    queues:
+      - name: root
+        submitacl: "*"
+        queues:
+          - name: leaf
+          - name: fallback
+`
+	err := initQueueStructure([]byte(data))
+	assert.NilError(t, err, "setting up the queue config failed")
+	// each rule fails as the parent rule resolves to a leaf queue
+	failing := map[string]configs.PlacementRule{
+		"user":     {Name: "user", Create: true},
+		"fixed":    {Name: "fixed", Value: "child", Create: true},
+		"provided": {Name: "provided", Create: true},
+		"tag":      {Name: "tag", Value: "namespace", Create: true},
+	}
+	tags := map[string]string{"namespace": "child"}
+	user := security.UserGroup{
+		User:   "testuser",
+		Groups: []string{},
+	}
+	for name, conf := range failing {
+		conf.Parent = &configs.PlacementRule{
+			Name:  "fixed",
+			Value: "root.leaf",
+		}
+		for _, fallback := range []bool{true, false} {
+			conf.FallbackOnError = fallback
+			man := NewPlacementManager(nil, queueFunc)
+			err = man.UpdateRules([]configs.PlacementRule{
+				conf,
+				{Name: "fixed", Value: "root.fallback"},
+			})
+			assert.NilError(t, err, "%s: failed to update rules", name)
+			app := objects.NewApplication("app1", "default", "child", user, tags, nil, "")
+			err = man.PlaceApplication(app)
+			if fallback {
+				assert.NilError(t, err, "%s: fallback chain should have placed the app", name)
+				assert.Equal(t, app.QueueName, "root.fallback", "%s: app placed in wrong queue", name)
+			} else {
+				assert.ErrorContains(t, err, "leaf queue", "%s: rule error should have been returned", name)
+				assert.Equal(t, app.QueueName, "", "%s: app should not have been placed", name)
+			}
+		}
+	}
+}
+
+func TestManagerPlacementAudit(t *testing.T) {
+	// Create the structure for the test
+	data := `
+partitions:
+  - name: default
+    queues:
+      - name: root
+        submitacl: "*"
+        queues:
+          - name: leaf
+`
+	err := initQueueStructure([]byte(data))
+	assert.NilError(t, err, "setting up the queue config failed")
+	user := security.UserGroup{
+		User:   "testuser",
+		Groups: []string{},
+	}
+	tests := []struct {
+		name      string
+		rules     []configs.PlacementRule
+		queue     string
+		tags      map[string]string
+		wantRule  string
+		wantParam string
+		wantQueue string
+	}{
+		{"User", []configs.PlacementRule{{Name: "user", Create: true}}, "", nil, "user", "", "root.testuser"},
+		{"Fixed", []configs.PlacementRule{{Name: "fixed", Value: "root.leaf"}}, "", nil, "fixed", "root.leaf", "root.leaf"},
+		{"Provided", []configs.PlacementRule{{Name: "provided", Create: true}}, "root.provided", nil, "provided", "", "root.provided"},
+		{"Tag", []configs.PlacementRule{{Name: "tag", Value: "namespace", Create: true}}, "", map[string]string{"namespace": "root.ns"}, "tag", "namespace", "root.ns"},
+		{"NextRule", []configs.PlacementRule{{Name: "provided"}, {Name: "fixed", Value: "root.leaf"}}, "", nil, "fixed", "root.leaf", "root.leaf"},
+	}
+	for _, tt := range tests {
+		man := NewPlacementManager(tt.rules, queueFunc)
+		app := objects.NewApplication("app1", "default", tt.queue, user, tt.tags, nil, "")
+		assert.Assert(t, app.GetPlacementAudit() == nil, "%s: app should not have a placement audit before placement", tt.name)
+		err = man.PlaceApplication(app)
+		assert.NilError(t, err, "%s: app should have been placed", tt.name)
+		audit := app.GetPlacementAudit()
+		assert.Assert(t, audit != nil, "%s: placement audit not set", tt.name)
+		assert.Equal(t, audit.RuleName, tt.wantRule, "%s: unexpected rule", tt.name)
+		assert.Equal(t, audit.RuleParam, tt.wantParam, "%s: unexpected rule parameter", tt.name)
+		assert.Equal(t, audit.ResolvedQueue, tt.wantQueue, "%s: unexpected resolved queue", tt.name)
+		assert.Assert(t, !audit.Time.IsZero(), "%s: placement time not set", tt.name)
+
+		// moving the app after placement does not change the audit
+		app.SetQueueName("root.other")
+		assert.Equal(t, app.GetPlacementAudit().ResolvedQueue, tt.wantQueue, "%s: audit changed after move", tt.name)
+	}
+
+	// rejected app has no audit
+	man := NewPlacementManager([]configs.PlacementRule{{Name: "provided"}}, queueFunc)
+	app := objects.NewApplication("app1", "default", "", user, nil, nil, "")
+	err = man.PlaceApplication(app)
+	assert.Assert(t, err != nil, "app without queue should not have been placed")
+	assert.Assert(t, app.GetPlacementAudit() == nil, "rejected app should not have a placement audit")
+}
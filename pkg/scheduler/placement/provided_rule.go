@@ -43,6 +43,7 @@ func (pr *providedRule) getName() string {
 
 func (pr *providedRule) initialise(conf configs.PlacementRule) error {
 	pr.create = conf.Create
+	pr.fallback = conf.FallbackOnError
 	pr.filter = newFilter(conf.Filter)
 	var err = error(nil)
 	if conf.Parent != nil {
@@ -116,6 +116,19 @@ partitions:
 	}
 }
 
+func TestProvidedRuleInvalidParent(t *testing.T) {
+	conf := configs.PlacementRule{
+		Name: "provided",
+		Parent: &configs.PlacementRule{
+			Name: "fixed",
+		},
+	}
+	pr, err := newRule(conf)
+	if err == nil || pr != nil {
+		t.Errorf("provided rule create did not fail with invalid parent rule, err 'nil', rule: %v", pr)
+	}
+}
+
 func TestProvidedRuleParent(t *testing.T) {
 	err := initQueueStructure([]byte(confParentChild))
 	assert.NilError(t, err, "setting up the queue config failed")
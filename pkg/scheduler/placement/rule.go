@@ -35,6 +35,10 @@ type rule interface {
 	// An error may only be returned if the configuration is not correct.
 	initialise(conf configs.PlacementRule) error
 
+	// Check the rule specific invariants after the rule is initialised.
+	// The basicRule provides an implementation for rules without specific invariants.
+	validate() error
+
 	// Execute the rule and return the queue getName the application is placed in.
 	// Returns the fully qualified queue getName if the rule finds a queue or an empty string if the rule did not match.
 	// The error must only be set if there is a failure while executing the rule not if the rule did not match.
@@ -44,9 +48,17 @@ type rule interface {
 	// The basicRule provides a "unnamed rule" implementation.
 	getName() string
 
+	// Return the parameter of the rule as set in the configuration.
+	// The basicRule provides an implementation for rules without a parameter.
+	getParam() string
+
 	// Return the parent rule.
 	// This method is implemented in the basicRule which each rule must be based on.
 	getParent() rule
+
+	// Return true if the next rule in the chain should be tried when this rule fails.
+	// This method is implemented in the basicRule which each rule must be based on.
+	fallbackOnError() bool
 }
 
 // Basic structure that every placement rule uses.
@@ -54,9 +66,10 @@ type rule interface {
 // Linter does not pick up on the usage in the implementation(s).
 //nolint:structcheck
 type basicRule struct {
-	create bool
-	parent rule
-	filter Filter
+	create   bool
+	parent   rule
+	filter   Filter
+	fallback bool
 }
 
 // Get the parent rule used in testing only.
@@ -65,6 +78,11 @@ func (r *basicRule) getParent() rule {
 	return r.parent
 }
 
+// Return the fallback on error flag set from the configuration.
+func (r *basicRule) fallbackOnError() bool {
+	return r.fallback
+}
+
 // Return the name if not overwritten by the rule.
 // Marked as nolint as rules should override this.
 //nolint:unused
@@ -72,6 +90,17 @@ func (r *basicRule) getName() string {
 	return "unnamed rule"
 }
 
+// Rules without a parameter return an empty string.
+func (r *basicRule) getParam() string {
+	return ""
+}
+
+// Rules without specific invariants are always valid.
+// The parent rule is validated when it is created.
+func (r *basicRule) validate() error {
+	return nil
+}
+
 // Create a new rule based on the getName of the rule requested. The rule is initialised with the configuration and can
 // be used directly.
 func newRule(conf configs.PlacementRule) (rule, error) {
@@ -105,6 +134,12 @@ func newRule(conf configs.PlacementRule) (rule, error) {
 		log.Logger().Error("Rule init failed", zap.Error(err))
 		return nil, err
 	}
+	// make sure the rule can be used
+	err = newRule.validate()
+	if err != nil {
+		log.Logger().Error("Rule validation failed", zap.Error(err))
+		return nil, err
+	}
 	log.Logger().Debug("New rule created", zap.Any("ruleConf", conf))
 	return newRule, nil
 }
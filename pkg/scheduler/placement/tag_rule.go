@@ -42,12 +42,14 @@ func (tr *tagRule) getName() string {
 	return "tag"
 }
 
+func (tr *tagRule) getParam() string {
+	return tr.tagName
+}
+
 func (tr *tagRule) initialise(conf configs.PlacementRule) error {
 	tr.tagName = normalise(conf.Value)
-	if tr.tagName == "" {
-		return fmt.Errorf("a tag queue rule must have a tag name set")
-	}
 	tr.create = conf.Create
+	tr.fallback = conf.FallbackOnError
 	tr.filter = newFilter(conf.Filter)
 	var err = error(nil)
 	if conf.Parent != nil {
@@ -56,6 +58,13 @@ func (tr *tagRule) initialise(conf configs.PlacementRule) error {
 	return err
 }
 
+func (tr *tagRule) validate() error {
+	if tr.tagName == "" {
+		return fmt.Errorf("a tag queue rule must have a tag name set")
+	}
+	return nil
+}
+
 func (tr *tagRule) placeApplication(app *objects.Application, queueFn func(string) *objects.Queue) (string, error) {
 	// if the tag is not present we can skipp all other processing
 	tagVal := app.GetTag(tr.tagName)
@@ -57,6 +57,18 @@ func TestTagRule(t *testing.T) {
 	if err != nil || tr == nil {
 		t.Errorf("tag rule create failed with tag as parent rule, err %v", err)
 	}
+	// invalid parent rule
+	conf = configs.PlacementRule{
+		Name:  "tag",
+		Value: "label1",
+		Parent: &configs.PlacementRule{
+			Name: "tag",
+		},
+	}
+	tr, err = newRule(conf)
+	if err == nil || tr != nil {
+		t.Errorf("tag rule create did not fail with invalid parent rule, err 'nil', rule: %v", tr)
+	}
 }
 
 func TestTagRulePlace(t *testing.T) {
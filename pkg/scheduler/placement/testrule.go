@@ -38,6 +38,7 @@ func (tr *testRule) getName() string {
 // Simple init for the test rule: allow everything as per a normal rule.
 func (tr *testRule) initialise(conf configs.PlacementRule) error {
 	tr.create = conf.Create
+	tr.fallback = conf.FallbackOnError
 	tr.filter = newFilter(conf.Filter)
 	var err = error(nil)
 	if conf.Parent != nil {
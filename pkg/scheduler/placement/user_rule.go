@@ -40,6 +40,7 @@ func (ur *userRule) getName() string {
 
 func (ur *userRule) initialise(conf configs.PlacementRule) error {
 	ur.create = conf.Create
+	ur.fallback = conf.FallbackOnError
 	ur.filter = newFilter(conf.Filter)
 	var err = error(nil)
 	if conf.Parent != nil {
@@ -128,6 +128,19 @@ partitions:
 	}
 }
 
+func TestUserRuleInvalidParent(t *testing.T) {
+	conf := configs.PlacementRule{
+		Name: "user",
+		Parent: &configs.PlacementRule{
+			Name: "fixed",
+		},
+	}
+	ur, err := newRule(conf)
+	if err == nil || ur != nil {
+		t.Errorf("user rule create did not fail with invalid parent rule, err 'nil', rule: %v", ur)
+	}
+}
+
 func TestUserRuleParent(t *testing.T) {
 	err := initQueueStructure([]byte(confParentChild))
 	assert.NilError(t, err, "setting up the queue config failed")
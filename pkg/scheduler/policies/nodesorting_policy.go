@@ -20,14 +20,40 @@ package policies
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"go.uber.org/zap"
 
 	"github.com/apache/incubator-yunikorn-core/pkg/log"
 )
 
+// The node sorting policy with its parameters.
+// Parameter keys are namespaced by the policy they apply to: <policy>.<key> (i.e. binpacking.memoryWeight).
+// A key without a policy namespace applies to the configured policy (i.e. memoryWeight).
+// A policy only uses the parameters in its own namespace, parameters for other policies are ignored.
+// The binpacking, fair and bestfit policies accept the keys:
+// - <resource>Weight: the weight of the named resource when comparing nodes (i.e. memoryWeight, vcoreWeight).
+//   The value must be a non negative number. Resources without a weight set default to a weight of 1.
+//   If no weights are set nodes are compared using the dominant share of the available resources.
+// The composite policy requires the key:
+// - policies: the ordered, comma separated, list of policies to chain (i.e. fair,binpacking).
+//   A policy followed by @<attribute> scores the group of nodes that share the value of the node attribute
+//   instead of the node itself (i.e. fair@topology.kubernetes.io/zone).
+//   Each chained policy uses the parameters in its own namespace.
 type NodeSortingPolicy struct {
 	PolicyType SortingPolicy
+	Parameters map[string]string
+
+	resourceWeights map[string]float64          // weights parsed from the parameters
+	groupKey        string                      // node attribute to score node groups on
+	composite       *CompositeNodeSortingPolicy // chained policies for the composite policy
+}
+
+// A node sorting policy that chains an ordered list of policies. Each policy is the tiebreaker for the
+// previous policy in the list.
+type CompositeNodeSortingPolicy struct {
+	Policies []*NodeSortingPolicy
 }
 
 type SortingPolicy int
@@ -35,11 +61,26 @@ type SortingPolicy int
 const (
 	BinPackingPolicy SortingPolicy = iota
 	FairnessPolicy
+	BestFitPolicy
+	CompositePolicy
 	Unknown
 )
 
+const (
+	// suffix for the parameter key that sets a resource weight
+	weightSuffix = "Weight"
+	// default weight for resources that do not have a weight set
+	DefaultResourceWeight = 1.0
+	// parameter key that lists the policies of the composite policy
+	CompositePoliciesKey = "policies"
+	// separator between the policy name and the parameter key
+	parameterSeparator = "."
+	// separator between a policy and the node attribute to group nodes on
+	groupSeparator = "@"
+)
+
 func (nsp SortingPolicy) String() string {
-	return [...]string{"binpacking", "fair", "undefined"}[nsp]
+	return [...]string{"binpacking", "fair", "bestfit", "composite", "undefined"}[nsp]
 }
 
 func FromString(str string) (SortingPolicy, error) {
@@ -49,12 +90,155 @@ func FromString(str string) (SortingPolicy, error) {
 		return FairnessPolicy, nil
 	case BinPackingPolicy.String():
 		return BinPackingPolicy, nil
+	case BestFitPolicy.String():
+		return BestFitPolicy, nil
+	case CompositePolicy.String():
+		return CompositePolicy, nil
 	default:
 		return Unknown, fmt.Errorf("undefined policy: %s", str)
 	}
 }
 
-func NewNodeSortingPolicy(policyType string) *NodeSortingPolicy {
+// Parse the resource weights from the policy parameters.
+// An error is returned for unknown parameter keys or weights that are not a non negative number.
+func ParseResourceWeights(params map[string]string) (map[string]float64, error) {
+	weights := make(map[string]float64)
+	for key, value := range params {
+		resName := strings.TrimSuffix(key, weightSuffix)
+		if resName == key || resName == "" {
+			return nil, fmt.Errorf("unknown node sorting policy parameter: %s", key)
+		}
+		weight, err := strconv.ParseFloat(value, 64)
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("invalid weight for node sorting policy parameter %s: %s", key, value)
+		}
+		weights[resName] = weight
+	}
+	return weights, nil
+}
+
+// Split the namespaced parameters into the parameters for each policy, the namespace is removed from the keys.
+// Keys that do not start with a policy namespace apply to the active policy and are not changed.
+// An error is returned for keys that have a policy namespace but no key.
+func splitParameters(active SortingPolicy, params map[string]string) (map[SortingPolicy]map[string]string, error) {
+	split := make(map[SortingPolicy]map[string]string)
+	for key, value := range params {
+		pType := active
+		if idx := strings.Index(key, parameterSeparator); idx > 0 {
+			if policy, err := FromString(key[:idx]); err == nil && key[:idx] == policy.String() {
+				if idx == len(key)-1 {
+					return nil, fmt.Errorf("node sorting policy parameter must be set as <policy>%s<key>: %s", parameterSeparator, key)
+				}
+				pType = policy
+				key = key[idx+len(parameterSeparator):]
+			}
+		}
+		if split[pType] == nil {
+			split[pType] = make(map[string]string)
+		}
+		split[pType][key] = value
+	}
+	return split, nil
+}
+
+// Return the namespaced parameters for the policy, parameters for other policies are not returned.
+func policyParameters(policyType SortingPolicy, params map[string]string) map[string]string {
+	prefix := policyType.String() + parameterSeparator
+	result := make(map[string]string)
+	for key, value := range params {
+		if strings.HasPrefix(key, prefix) {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// Check the namespaced parameters of all policies, not just the policy type passed in.
+// The composite policy parameters are only checked for the composite policy type as the list of policies is required.
+func CheckParameters(policyType SortingPolicy, params map[string]string) error {
+	split, err := splitParameters(policyType, params)
+	if err != nil {
+		return err
+	}
+	for pType, pParams := range split {
+		if pType == CompositePolicy {
+			continue
+		}
+		if _, err = ParseResourceWeights(pParams); err != nil {
+			return err
+		}
+	}
+	if policyType == CompositePolicy {
+		_, err = ParseCompositePolicies(params)
+	}
+	return err
+}
+
+// Parse the chained policies of the composite policy from the namespaced policy parameters.
+// An error is returned if the list of policies is missing, contains an unknown or composite policy,
+// or if the parameters of a chained policy cannot be parsed.
+func ParseCompositePolicies(params map[string]string) (*CompositeNodeSortingPolicy, error) {
+	split, err := splitParameters(CompositePolicy, params)
+	if err != nil {
+		return nil, err
+	}
+	list := split[CompositePolicy][CompositePoliciesKey]
+	for key := range split[CompositePolicy] {
+		if key != CompositePoliciesKey {
+			return nil, fmt.Errorf("unknown composite node sorting policy parameter: %s", key)
+		}
+	}
+	if strings.TrimSpace(list) == "" {
+		return nil, fmt.Errorf("composite node sorting policy requires the %s%s%s parameter", CompositePolicy, parameterSeparator, CompositePoliciesKey)
+	}
+	composite := &CompositeNodeSortingPolicy{}
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		groupKey := ""
+		if idx := strings.Index(entry, groupSeparator); idx >= 0 {
+			groupKey = entry[idx+len(groupSeparator):]
+			entry = entry[:idx]
+			if groupKey == "" {
+				return nil, fmt.Errorf("composite node sorting policy has an empty group for policy: %s", entry)
+			}
+		}
+		if entry == "" {
+			return nil, fmt.Errorf("composite node sorting policy has an empty policy in: %s", list)
+		}
+		pType, err := FromString(entry)
+		if err != nil {
+			return nil, err
+		}
+		if pType == CompositePolicy {
+			return nil, fmt.Errorf("composite node sorting policy cannot contain a composite policy")
+		}
+		var weights map[string]float64
+		weights, err = ParseResourceWeights(split[pType])
+		if err != nil {
+			return nil, err
+		}
+		composite.Policies = append(composite.Policies, &NodeSortingPolicy{
+			PolicyType:      pType,
+			Parameters:      policyParameters(pType, params),
+			resourceWeights: weights,
+			groupKey:        groupKey,
+		})
+	}
+	return composite, nil
+}
+
+// Compare the scores of two nodes, with one score for each chained policy in the same order as the policies.
+// The first policy that scores the nodes differently decides: the node with the higher score comes first.
+func (cnsp *CompositeNodeSortingPolicy) Less(left, right []float64) bool {
+	for i := range cnsp.Policies {
+		if left[i] != right[i] {
+			return left[i] > right[i]
+		}
+	}
+	return false
+}
+
+func NewNodeSortingPolicy(policyType string, params map[string]string) *NodeSortingPolicy {
 	pType, err := FromString(policyType)
 	if err != nil {
 		log.Logger().Debug("node sorting policy defaulted to 'undefined'",
@@ -62,9 +246,51 @@ func NewNodeSortingPolicy(policyType string) *NodeSortingPolicy {
 	}
 	sp := &NodeSortingPolicy{
 		PolicyType: pType,
+		Parameters: make(map[string]string),
+	}
+	for key, value := range params {
+		sp.Parameters[key] = value
+	}
+	if pType == CompositePolicy {
+		sp.composite, err = ParseCompositePolicies(params)
+		if err != nil {
+			log.Logger().Debug("composite node sorting policy has no policies",
+				zap.Error(err))
+		}
+	} else {
+		var split map[SortingPolicy]map[string]string
+		split, err = splitParameters(pType, params)
+		if err == nil {
+			sp.resourceWeights, err = ParseResourceWeights(split[pType])
+		}
+		if err != nil {
+			log.Logger().Debug("node sorting policy parameters ignored",
+				zap.Error(err))
+		}
 	}
 
 	log.Logger().Debug("new node sorting policy added",
-		zap.String("type", pType.String()))
+		zap.String("type", pType.String()),
+		zap.Any("parameters", params))
 	return sp
 }
+
+// Return the weight for each resource that has a weight set in the parameters.
+// An empty map is returned if no weights are set.
+func (nsp *NodeSortingPolicy) GetResourceWeights() map[string]float64 {
+	weights := make(map[string]float64)
+	for resName, weight := range nsp.resourceWeights {
+		weights[resName] = weight
+	}
+	return weights
+}
+
+// Return the node attribute used to group nodes when scoring, empty if nodes are scored individually.
+func (nsp *NodeSortingPolicy) GetGroupKey() string {
+	return nsp.groupKey
+}
+
+// Return the chained policies of the composite policy, nil for all other policies.
+func (nsp *NodeSortingPolicy) GetCompositePolicy() *CompositeNodeSortingPolicy {
+	return nsp.composite
+}
@@ -19,6 +19,7 @@
 package policies
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -32,6 +33,8 @@ func TestFromString(t *testing.T) {
 		{"EmptyString", "", FairnessPolicy, false},
 		{"FairString", "fair", FairnessPolicy, false},
 		{"BinString", "binpacking", BinPackingPolicy, false},
+		{"BestFitString", "bestfit", BestFitPolicy, false},
+		{"CompositeString", "composite", CompositePolicy, false},
 		{"UnknownString", "unknown", Unknown, true},
 	}
 	for _, tt := range tests {
@@ -55,6 +58,8 @@ func TestToString(t *testing.T) {
 	}{
 		{"FairString", FairnessPolicy, "fair"},
 		{"BinString", BinPackingPolicy, "binpacking"},
+		{"BestFitString", BestFitPolicy, "bestfit"},
+		{"CompositeString", CompositePolicy, "composite"},
 		{"DefaultString", Unknown, "undefined"},
 		{"NoneString", someSP, "binpacking"},
 	}
@@ -74,12 +79,186 @@ func TestNewNodeSortingPolicy(t *testing.T) {
 		{"EmptyString", "", FairnessPolicy},
 		{"FairString", "fair", FairnessPolicy},
 		{"BinString", "binpacking", BinPackingPolicy},
+		{"BestFitString", "bestfit", BestFitPolicy},
 		{"UnknownString", "unknown", Unknown},
 	}
 	for _, tt := range tests {
-		got := NewNodeSortingPolicy(tt.arg)
+		got := NewNodeSortingPolicy(tt.arg, nil)
 		if got == nil || got.PolicyType != tt.want {
 			t.Errorf("%s unexpected policy returned, expected = '%s', got '%v'", tt.name, tt.want, got)
 		}
 	}
 }
+
+func TestNodeSortingPolicyParameters(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  map[string]string
+		want    map[string]float64
+		wantErr bool
+	}{
+		{"NilParams", nil, map[string]float64{}, false},
+		{"MemoryWeight", map[string]string{"memoryWeight": "2"}, map[string]float64{"memory": 2}, false},
+		{"MultiWeight", map[string]string{"memoryWeight": "0.5", "vcoreWeight": "0"}, map[string]float64{"memory": 0.5, "vcore": 0}, false},
+		{"UnknownKey", map[string]string{"memory": "1"}, nil, true},
+		{"EmptyName", map[string]string{"Weight": "1"}, nil, true},
+		{"NotANumber", map[string]string{"memoryWeight": "abc"}, nil, true},
+		{"Negative", map[string]string{"memoryWeight": "-1"}, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseResourceWeights(tt.params)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s unexpected error returned, expected error: %t, got error '%v'", tt.name, tt.wantErr, err)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s unexpected weights returned, expected = '%v', got '%v'", tt.name, tt.want, got)
+		}
+		// the same parameters namespaced for the policy
+		params := make(map[string]string)
+		for key, value := range tt.params {
+			params["binpacking."+key] = value
+		}
+		policy := NewNodeSortingPolicy("binpacking", params)
+		if len(policy.Parameters) != len(tt.params) {
+			t.Errorf("%s parameters not stored, expected = '%v', got '%v'", tt.name, params, policy.Parameters)
+		}
+		if !tt.wantErr && !reflect.DeepEqual(policy.GetResourceWeights(), tt.want) {
+			t.Errorf("%s unexpected policy weights, expected = '%v', got '%v'", tt.name, tt.want, policy.GetResourceWeights())
+		}
+		// failed parsing means no weights are used
+		if tt.wantErr && len(policy.GetResourceWeights()) != 0 {
+			t.Errorf("%s weights should not be set on parse failure, got '%v'", tt.name, policy.GetResourceWeights())
+		}
+	}
+}
+
+func TestNodeSortingPolicyNamespaces(t *testing.T) {
+	params := map[string]string{"binpacking.memoryWeight": "2", "fair.vcoreWeight": "3"}
+	weights := map[string]map[string]float64{
+		"binpacking": {"memory": 2},
+		"fair":       {"vcore": 3},
+		"bestfit":    {},
+	}
+	for policyType, want := range weights {
+		policy := NewNodeSortingPolicy(policyType, params)
+		if !reflect.DeepEqual(policy.GetResourceWeights(), want) {
+			t.Errorf("%s unexpected weights, expected = '%v', got '%v'", policyType, want, policy.GetResourceWeights())
+		}
+		if len(policy.Parameters) != len(params) {
+			t.Errorf("%s all parameters should be stored, got '%v'", policyType, policy.Parameters)
+		}
+	}
+	// parameters that are not namespaced apply to the configured policy
+	want := map[string]float64{"memory": 2, "nvidia.com/gpu": 3}
+	if weights := NewNodeSortingPolicy("binpacking", map[string]string{"memoryWeight": "2", "nvidia.com/gpuWeight": "3"}).GetResourceWeights(); !reflect.DeepEqual(weights, want) {
+		t.Errorf("parameters without a namespace should apply to the policy, expected = '%v', got '%v'", want, weights)
+	}
+
+	tests := []struct {
+		name       string
+		policyType SortingPolicy
+		params     map[string]string
+		wantErr    bool
+	}{
+		{"NilParams", BinPackingPolicy, nil, false},
+		{"OwnNamespace", BinPackingPolicy, map[string]string{"binpacking.memoryWeight": "2"}, false},
+		{"OtherNamespace", BinPackingPolicy, map[string]string{"fair.memoryWeight": "2"}, false},
+		{"OtherNamespaceInvalid", BinPackingPolicy, map[string]string{"fair.memoryWeight": "-1"}, true},
+		{"NoNamespace", BinPackingPolicy, map[string]string{"memoryWeight": "2"}, false},
+		{"NoNamespaceInvalid", BinPackingPolicy, map[string]string{"memoryWeight": "-1"}, true},
+		{"NoNamespaceSeparatorInResource", BinPackingPolicy, map[string]string{"nvidia.com/gpuWeight": "2"}, false},
+		{"EmptyKey", BinPackingPolicy, map[string]string{"binpacking.": "2"}, true},
+		{"CompositeNotUsed", FairnessPolicy, map[string]string{"composite.policies": "unknown"}, false},
+		{"Composite", CompositePolicy, map[string]string{"composite.policies": "fair,binpacking", "fair.memoryWeight": "2"}, false},
+		{"CompositeUnknownKey", CompositePolicy, map[string]string{"composite.policies": "fair", "composite.memoryWeight": "2"}, true},
+		{"CompositeMissingList", CompositePolicy, map[string]string{"fair.memoryWeight": "2"}, true},
+	}
+	for _, tt := range tests {
+		err := CheckParameters(tt.policyType, tt.params)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s unexpected error returned, expected error: %t, got error '%v'", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestParseCompositePolicies(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  map[string]string
+		want    []SortingPolicy
+		groups  []string
+		wantErr bool
+	}{
+		{"NilParams", nil, nil, nil, true},
+		{"EmptyList", map[string]string{"composite.policies": " "}, nil, nil, true},
+		{"NoNamespace", map[string]string{"policies": "binpacking"}, []SortingPolicy{BinPackingPolicy}, []string{""}, false},
+		{"NoNamespaceUnknownKey", map[string]string{"policies": "binpacking", "memoryWeight": "2"}, nil, nil, true},
+		{"Single", map[string]string{"composite.policies": "binpacking"}, []SortingPolicy{BinPackingPolicy}, []string{""}, false},
+		{"ZoneFairBinPacking", map[string]string{"composite.policies": "fair@zone, binpacking"}, []SortingPolicy{FairnessPolicy, BinPackingPolicy}, []string{"zone", ""}, false},
+		{"Weights", map[string]string{"composite.policies": "bestfit,fair", "fair.memoryWeight": "2"}, []SortingPolicy{BestFitPolicy, FairnessPolicy}, []string{"", ""}, false},
+		{"EmptyPolicy", map[string]string{"composite.policies": "fair,,binpacking"}, nil, nil, true},
+		{"EmptyGroup", map[string]string{"composite.policies": "fair@"}, nil, nil, true},
+		{"UnknownPolicy", map[string]string{"composite.policies": "fair,unknown"}, nil, nil, true},
+		{"NestedComposite", map[string]string{"composite.policies": "composite"}, nil, nil, true},
+		{"InvalidWeight", map[string]string{"composite.policies": "fair", "fair.memoryWeight": "-1"}, nil, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseCompositePolicies(tt.params)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s unexpected error returned, expected error: %t, got error '%v'", tt.name, tt.wantErr, err)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if len(got.Policies) != len(tt.want) {
+			t.Errorf("%s unexpected number of policies, expected = %d, got %d", tt.name, len(tt.want), len(got.Policies))
+			continue
+		}
+		for i, policy := range got.Policies {
+			if policy.PolicyType != tt.want[i] || policy.GetGroupKey() != tt.groups[i] {
+				t.Errorf("%s unexpected policy %d, expected = '%s@%s', got '%s@%s'", tt.name, i, tt.want[i], tt.groups[i], policy.PolicyType, policy.GetGroupKey())
+			}
+			if _, ok := policy.Parameters["composite."+CompositePoliciesKey]; ok {
+				t.Errorf("%s policies parameter should not be passed to the chained policy", tt.name)
+			}
+		}
+	}
+
+	policy := NewNodeSortingPolicy("composite", map[string]string{"composite.policies": "fair@zone,binpacking", "binpacking.memoryWeight": "2"})
+	if policy.GetCompositePolicy() == nil || len(policy.GetCompositePolicy().Policies) != 2 {
+		t.Fatalf("composite policy not parsed, got '%v'", policy.GetCompositePolicy())
+	}
+	if len(policy.Parameters) != 2 || len(policy.GetResourceWeights()) != 0 {
+		t.Errorf("composite parameters not stored, got '%v' weights '%v'", policy.Parameters, policy.GetResourceWeights())
+	}
+	// each chained policy only uses the weights in its own namespace
+	chained := policy.GetCompositePolicy().Policies
+	if len(chained[0].GetResourceWeights()) != 0 || !reflect.DeepEqual(chained[1].GetResourceWeights(), map[string]float64{"memory": 2}) {
+		t.Errorf("chained policy weights not namespaced, got fair '%v' binpacking '%v'", chained[0].GetResourceWeights(), chained[1].GetResourceWeights())
+	}
+	if NewNodeSortingPolicy("fair", nil).GetCompositePolicy() != nil {
+		t.Error("non composite policy should not have chained policies")
+	}
+}
+
+func TestCompositeLess(t *testing.T) {
+	composite := &CompositeNodeSortingPolicy{Policies: []*NodeSortingPolicy{{}, {}}}
+	tests := []struct {
+		name        string
+		left, right []float64
+		want        bool
+	}{
+		{"FirstHigher", []float64{1, 0}, []float64{0, 1}, true},
+		{"FirstLower", []float64{0, 1}, []float64{1, 0}, false},
+		{"TieSecondHigher", []float64{1, 2}, []float64{1, 1}, true},
+		{"TieSecondLower", []float64{1, 1}, []float64{1, 2}, false},
+		{"Equal", []float64{1, 1}, []float64{1, 1}, false},
+	}
+	for _, tt := range tests {
+		if got := composite.Less(tt.left, tt.right); got != tt.want {
+			t.Errorf("%s unexpected compare result, expected = %t, got %t", tt.name, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"fmt"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+)
+
+// The effect of preempting an allocation in favour of the most urgent pending ask that could use it.
+// The overall utilisation delta is the change in the utilisation of the partition after the victim is released
+// and the beneficiary is allocated, averaged over the resource types of the partition. It is negative when the
+// victim frees more than the beneficiary uses.
+type PreemptionImpact struct {
+	VictimApp               string
+	VictimQueue             string
+	VictimResource          *resources.Resource
+	BeneficiaryApp          string
+	BeneficiaryQueue        string
+	BeneficiaryGain         *resources.Resource
+	OverallUtilizationDelta float64
+}
+
+// Assess the impact of preempting the allocation with the given uuid.
+// The beneficiary is the most urgent pending ask for which the allocation is a preemption candidate, see
+// GetPreemptionCandidates for the rules. Returns an error if the allocation is not found or there is no pending
+// ask that could preempt the allocation.
+func (pc *PartitionContext) GetPreemptionImpact(victimUUID string) (PreemptionImpact, error) {
+	pc.RLock()
+	defer pc.RUnlock()
+	victim := pc.allocations[victimUUID]
+	if victim == nil {
+		return PreemptionImpact{}, fmt.Errorf("allocation %s not found in partition %s", victimUUID, pc.Name)
+	}
+	var asks []*objects.AllocationAsk
+	for _, app := range pc.applications {
+		for _, ask := range app.GetPendingAsks() {
+			if isPreemptionCandidate(ask, victim) {
+				asks = append(asks, ask)
+			}
+		}
+	}
+	beneficiary := getMostUrgentAsk(asks)
+	if beneficiary == nil {
+		return PreemptionImpact{}, fmt.Errorf("allocation %s cannot be preempted by any pending ask", victimUUID)
+	}
+	return PreemptionImpact{
+		VictimApp:               victim.ApplicationID,
+		VictimQueue:             victim.QueueName,
+		VictimResource:          victim.AllocatedResource.Clone(),
+		BeneficiaryApp:          beneficiary.ApplicationID,
+		BeneficiaryQueue:        beneficiary.QueueName,
+		BeneficiaryGain:         beneficiary.AllocatedResource.Clone(),
+		OverallUtilizationDelta: getUtilizationDelta(beneficiary.AllocatedResource, victim.AllocatedResource, pc.totalPartitionResource),
+	}, nil
+}
+
+// Return the change in utilisation when the released resource is replaced by the allocated resource, averaged over
+// the resource types in the total. Returns 0 if the total has no resources.
+func getUtilizationDelta(allocated, released, total *resources.Resource) float64 {
+	if total == nil {
+		return 0
+	}
+	var delta float64
+	var count int
+	for name, quantity := range total.Resources {
+		if quantity <= 0 {
+			continue
+		}
+		var change resources.Quantity
+		if allocated != nil {
+			change += allocated.Resources[name]
+		}
+		if released != nil {
+			change -= released.Resources[name]
+		}
+		delta += float64(change) / float64(quantity)
+		count++
+	}
+	if count == 0 {
+		return 0
+	}
+	return delta / float64(count)
+}
@@ -0,0 +1,93 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+)
+
+func TestGetPreemptionImpact(t *testing.T) {
+	partition, err := newConfiguredPartition()
+	assert.NilError(t, err, "partition create failed")
+	_, err = partition.GetPreemptionImpact("unknown")
+	assert.ErrorContains(t, err, "not found")
+
+	victimApp1 := newApplication(appID1, "default", "root.parent.sub-leaf")
+	err = partition.AddApplication(victimApp1)
+	assert.NilError(t, err, "add application to partition should not have failed")
+	victimApp2 := newApplication(appID2, "default", "root.leaf")
+	err = partition.AddApplication(victimApp2)
+	assert.NilError(t, err, "add application to partition should not have failed")
+	smallRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 10})
+	largeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 40})
+	newAlloc := func(uuid, appID, queue string, res *resources.Resource, prio int32) *objects.Allocation {
+		alloc := objects.NewAllocation(uuid, nodeID1, newAllocationAskPriority(uuid, appID, res, 1, prio))
+		alloc.QueueName = queue
+		return alloc
+	}
+	allocs := []*objects.Allocation{
+		newAlloc("small-uuid", appID1, "root.parent.sub-leaf", smallRes, 0),
+		newAlloc("large-uuid", appID2, "root.leaf", largeRes, 0),
+		newAlloc("high-uuid", appID1, "root.parent.sub-leaf", largeRes, 30),
+	}
+	err = partition.AddNode(newNodeMaxResource(nodeID1, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 100})), allocs)
+	assert.NilError(t, err, "add node to partition should not have failed")
+	_, err = partition.GetPreemptionImpact("small-uuid")
+	assert.ErrorContains(t, err, "cannot be preempted")
+
+	// two pending asks: the most urgent ask that fits the victim benefits
+	beneficiary := newApplication("app-3", "default", "root.leaf")
+	err = partition.AddApplication(beneficiary)
+	assert.NilError(t, err, "add application to partition should not have failed")
+	err = beneficiary.AddAllocationAsk(newAllocationAskPriority("ask-10", "app-3", smallRes, 1, 10))
+	assert.NilError(t, err, "failed to add ask to app")
+	mediumRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 20})
+	err = beneficiary.AddAllocationAsk(newAllocationAskPriority("ask-20", "app-3", mediumRes, 1, 20))
+	assert.NilError(t, err, "failed to add ask to app")
+
+	// only the smaller ask fits the small victim
+	impact, err := partition.GetPreemptionImpact("small-uuid")
+	assert.NilError(t, err, "impact for small victim should not have failed")
+	assert.Equal(t, impact.VictimApp, appID1, "unexpected victim app")
+	assert.Equal(t, impact.VictimQueue, "root.parent.sub-leaf", "unexpected victim queue")
+	assert.Assert(t, resources.Equals(impact.VictimResource, smallRes), "unexpected victim resource: %s", impact.VictimResource)
+	assert.Equal(t, impact.BeneficiaryApp, "app-3", "unexpected beneficiary app")
+	assert.Equal(t, impact.BeneficiaryQueue, "root.leaf", "unexpected beneficiary queue")
+	assert.Assert(t, resources.Equals(impact.BeneficiaryGain, smallRes), "unexpected beneficiary gain: %s", impact.BeneficiaryGain)
+	assert.Equal(t, impact.OverallUtilizationDelta, float64(0), "replacing the victim with an equal ask should not change utilisation")
+
+	// both asks fit the large victim: the highest priority ask benefits
+	impact, err = partition.GetPreemptionImpact("large-uuid")
+	assert.NilError(t, err, "impact for large victim should not have failed")
+	assert.Equal(t, impact.VictimApp, appID2, "unexpected victim app")
+	assert.Equal(t, impact.VictimQueue, "root.leaf", "unexpected victim queue")
+	assert.Assert(t, resources.Equals(impact.VictimResource, largeRes), "unexpected victim resource: %s", impact.VictimResource)
+	assert.Equal(t, impact.BeneficiaryApp, "app-3", "unexpected beneficiary app")
+	assert.Assert(t, resources.Equals(impact.BeneficiaryGain, mediumRes), "unexpected beneficiary gain: %s", impact.BeneficiaryGain)
+	assert.Equal(t, impact.OverallUtilizationDelta, -0.2, "unexpected utilisation delta")
+
+	// a victim with a higher priority than all asks cannot be preempted
+	_, err = partition.GetPreemptionImpact("high-uuid")
+	assert.ErrorContains(t, err, "cannot be preempted")
+}
@@ -31,11 +31,25 @@ type preemptionContext struct {
 }
 
 type preemptionPartitionContext struct {
+	partition              *PartitionContext
 	partitionTotalResource *resources.Resource
 	root                   *preemptionQueueContext
 	leafQueues             map[string]*preemptionQueueContext
 }
 
+// Return false if preemption was disabled for the partition after the preemption cycle started.
+func (ctx *preemptionPartitionContext) isEnabled() bool {
+	return ctx.partition == nil || ctx.partition.GetIsPreemptable()
+}
+
+// Return the application from the partition, nil if the application is not found or the partition is not set.
+func (ctx *preemptionPartitionContext) getApplication(appID string) *objects.Application {
+	if ctx.partition == nil {
+		return nil
+	}
+	return ctx.partition.getApplication(appID)
+}
+
 type preemptionQueueContext struct {
 	queuePath       string
 	schedulingQueue *objects.Queue
@@ -106,9 +120,13 @@ func (s *Scheduler) resetPreemptionContext() {
 		partitions: make(map[string]*preemptionPartitionContext),
 	}
 
-	// Copy from scheduler
+	// Copy from scheduler, skip partitions that do not allow preemption
 	for partition, partitionContext := range s.clusterContext.GetPartitionMapClone() {
+		if !partitionContext.GetIsPreemptable() {
+			continue
+		}
 		preemptionPartitionCtx := &preemptionPartitionContext{
+			partition:  partitionContext,
 			leafQueues: make(map[string]*preemptionQueueContext),
 		}
 		s.preemptionContext.partitions[partition] = preemptionPartitionCtx
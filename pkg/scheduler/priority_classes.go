@@ -0,0 +1,77 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+)
+
+// Replace the registered priority classes with the classes from the partition config.
+// Applications already added keep their offset.
+// NOTE: this is a lock free call. It should only be called holding the PartitionContext lock or during create.
+func (pc *PartitionContext) setPriorityClasses(classes map[string]int32) {
+	pc.priorityClasses = make(map[string]int32, len(classes))
+	for className, offset := range classes {
+		pc.priorityClasses[className] = offset
+	}
+}
+
+// Register the priority offset for the priority class, replacing the offset if the class was already registered.
+// A config reload replaces all registered classes with the classes from the config.
+// Applications added later that reference the class get the offset applied to the priority of their asks.
+func (pc *PartitionContext) SetPriorityClass(className string, priorityOffset int32) {
+	pc.Lock()
+	defer pc.Unlock()
+	pc.priorityClasses[className] = priorityOffset
+}
+
+// Remove the priority class from the registry, applications already added keep their offset.
+func (pc *PartitionContext) RemovePriorityClass(className string) {
+	pc.Lock()
+	defer pc.Unlock()
+	delete(pc.priorityClasses, className)
+}
+
+// Return the priority offset registered for the priority class and true, or false if the class is not registered.
+func (pc *PartitionContext) GetPriorityClass(className string) (int32, bool) {
+	pc.RLock()
+	defer pc.RUnlock()
+	offset, ok := pc.priorityClasses[className]
+	return offset, ok
+}
+
+// Set the priority class from the application tag on the application.
+// An unknown class is logged and set with a zero offset.
+// Unlocked version must be called holding the partition lock.
+func (pc *PartitionContext) applyPriorityClass(app *objects.Application) {
+	className := app.GetTag(objects.AppTagPriorityClassName)
+	if className == "" {
+		return
+	}
+	offset, ok := pc.priorityClasses[className]
+	if !ok {
+		log.Logger().Warn("application references unknown priority class",
+			zap.String("appID", app.ApplicationID),
+			zap.String("priorityClassName", className))
+	}
+	app.SetPriorityClass(className, offset)
+}
@@ -0,0 +1,138 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/security"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+)
+
+func TestPriorityClassRegistry(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	_, ok := partition.GetPriorityClass("high")
+	assert.Assert(t, !ok, "class should not be registered")
+
+	partition.SetPriorityClass("high", 100)
+	offset, ok := partition.GetPriorityClass("high")
+	assert.Assert(t, ok, "class should be registered")
+	assert.Equal(t, offset, int32(100), "unexpected offset")
+	partition.SetPriorityClass("high", 50)
+	offset, _ = partition.GetPriorityClass("high")
+	assert.Equal(t, offset, int32(50), "offset not replaced")
+
+	partition.RemovePriorityClass("high")
+	_, ok = partition.GetPriorityClass("high")
+	assert.Assert(t, !ok, "class should have been removed")
+}
+
+func TestAddAppPriorityClass(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	partition.SetPriorityClass("high", 100)
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 1})
+
+	tags := map[string]string{objects.AppTagPriorityClassName: "high"}
+	app := objects.NewApplication(appID1, "default", defQueue, security.UserGroup{}, tags, nil, rmID)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app to partition")
+	assert.Equal(t, app.GetPriorityClassName(), "high", "priority class not set from tag")
+	assert.Equal(t, app.GetPriorityOffset(), int32(100), "priority offset not set from registry")
+	ask := newAllocationAskPriority("alloc-1", appID1, res, 1, 5)
+	err = app.AddAllocationAsk(ask)
+	assert.NilError(t, err, "failed to add ask to app")
+	assert.Equal(t, ask.GetEffectivePriority(), int32(105), "class offset not added to ask priority")
+
+	// unknown class is set without an offset
+	tags = map[string]string{objects.AppTagPriorityClassName: "unknown"}
+	app = objects.NewApplication(appID2, "default", defQueue, security.UserGroup{}, tags, nil, rmID)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app to partition")
+	assert.Equal(t, app.GetPriorityClassName(), "unknown", "priority class not set from tag")
+	assert.Equal(t, app.GetPriorityOffset(), int32(0), "unknown class should not have an offset")
+
+	// no tag no class
+	app = newApplication("app-3", "default", defQueue)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app to partition")
+	assert.Equal(t, app.GetPriorityClassName(), "", "priority class should not be set")
+}
+
+func TestPriorityClassesFromConfig(t *testing.T) {
+	conf := configs.PartitionConfig{
+		Name: "test",
+		Queues: []configs.QueueConfig{
+			{
+				Name:      "root",
+				Parent:    true,
+				SubmitACL: "*",
+				Queues:    []configs.QueueConfig{{Name: "default"}},
+			},
+		},
+		PriorityClasses: map[string]int32{"high": 100, "low": -10},
+	}
+	partition, err := newPartitionContext(conf, rmID, nil)
+	assert.NilError(t, err, "partition create failed")
+	offset, ok := partition.GetPriorityClass("high")
+	assert.Assert(t, ok, "class from the config should be registered")
+	assert.Equal(t, offset, int32(100), "unexpected offset")
+	assert.DeepEqual(t, partition.ExportConfig().PriorityClasses, conf.PriorityClasses)
+
+	tags := map[string]string{objects.AppTagPriorityClassName: "low"}
+	app := objects.NewApplication(appID1, "default", defQueue, security.UserGroup{}, tags, nil, rmID)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app to partition")
+	assert.Equal(t, app.GetPriorityOffset(), int32(-10), "priority offset not set from the config")
+
+	// the config replaces the registered classes, apps keep their offset
+	conf.PriorityClasses = map[string]int32{"high": 50}
+	err = partition.updatePartitionDetails(conf)
+	assert.NilError(t, err, "update partition failed unexpected with error")
+	offset, _ = partition.GetPriorityClass("high")
+	assert.Equal(t, offset, int32(50), "offset not updated from the config")
+	_, ok = partition.GetPriorityClass("low")
+	assert.Assert(t, !ok, "class removed from the config should not be registered")
+	assert.Equal(t, app.GetPriorityOffset(), int32(-10), "offset of the existing app should not change")
+}
+
+func TestCloneForSimulationPriorityClass(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	partition.SetPriorityClass("high", 100)
+	tags := map[string]string{objects.AppTagPriorityClassName: "high"}
+	app := objects.NewApplication(appID1, "default", defQueue, security.UserGroup{}, tags, nil, rmID)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app to partition")
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 1})
+	err = app.AddAllocationAsk(newAllocationAskPriority("alloc-1", appID1, res, 1, 5))
+	assert.NilError(t, err, "failed to add ask to app")
+
+	sim := partition.CloneForSimulation()
+	assert.Assert(t, sim != nil, "clone should have been created")
+	clone := sim.getApplication(appID1)
+	assert.Equal(t, clone.GetPriorityClassName(), "high", "priority class not copied")
+	assert.Equal(t, clone.GetPriorityOffset(), int32(100), "priority offset not copied")
+	assert.Equal(t, clone.GetSchedulingAllocationAsk("alloc-1").GetEffectivePriority(), int32(105), "offset not applied to the cloned ask")
+}
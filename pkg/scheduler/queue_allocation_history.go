@@ -0,0 +1,106 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+)
+
+// maximum number of entries kept in the allocation history of a queue, oldest entries are overwritten first
+const maxQueueAllocationHistory = 100
+
+const (
+	AllocationHistoryAllocated = "allocated"
+	AllocationHistoryReleased  = "released"
+)
+
+// An allocation made in or released from a queue.
+type AllocationHistoryEntry struct {
+	AppID         string
+	AllocationKey string
+	NodeID        string
+	Resource      *resources.Resource
+	Timestamp     time.Time
+	Action        string
+}
+
+// Ring buffer of allocation history entries, the oldest entry is overwritten first.
+type allocHistoryBuffer struct {
+	*common.RingBuffer
+}
+
+func newAllocHistoryBuffer(size int) *allocHistoryBuffer {
+	return &allocHistoryBuffer{
+		RingBuffer: common.NewRingBuffer(size),
+	}
+}
+
+// Add the entry to the buffer, evicting the oldest entry if the buffer is full.
+func (b *allocHistoryBuffer) add(entry AllocationHistoryEntry) {
+	b.Add(entry)
+}
+
+// Return a copy of the entries with a timestamp at or after since, oldest entry first.
+func (b *allocHistoryBuffer) since(since time.Time) []AllocationHistoryEntry {
+	result := make([]AllocationHistoryEntry, 0)
+	for i := 0; i < b.Len(); i++ {
+		entry := b.Get(i).(AllocationHistoryEntry)
+		if !entry.Timestamp.Before(since) {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+// Return the allocations made in and released from the queue at or after since, oldest first.
+// Returns nil if nothing was recorded for the queue. The history of a removed queue is dropped once it is no
+// longer one of the last maxRemovedQueueHistories removed queues.
+func (pc *PartitionContext) GetQueueAllocationHistory(queuePath string, since time.Time) []AllocationHistoryEntry {
+	pc.RLock()
+	defer pc.RUnlock()
+	buffer := pc.queueAllocationHistory[strings.ToLower(queuePath)]
+	if buffer == nil {
+		return nil
+	}
+	return buffer.since(since)
+}
+
+// Record the allocation change in the history of the queue of the allocation.
+// Unlocked version must be called holding the partition lock.
+func (pc *PartitionContext) addQueueAllocationHistory(alloc *objects.Allocation, action string) {
+	queuePath := strings.ToLower(alloc.QueueName)
+	buffer := pc.queueAllocationHistory[queuePath]
+	if buffer == nil {
+		buffer = newAllocHistoryBuffer(maxQueueAllocationHistory)
+		pc.queueAllocationHistory[queuePath] = buffer
+	}
+	buffer.add(AllocationHistoryEntry{
+		AppID:         alloc.ApplicationID,
+		AllocationKey: alloc.AllocationKey,
+		NodeID:        alloc.NodeID,
+		Resource:      alloc.AllocatedResource.Clone(),
+		Timestamp:     time.Now(),
+		Action:        action,
+	})
+}
@@ -0,0 +1,147 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+)
+
+// Return the number of entries per action.
+func countHistoryActions(entries []AllocationHistoryEntry) map[string]int {
+	counts := make(map[string]int)
+	for _, entry := range entries {
+		counts[entry.Action]++
+	}
+	return counts
+}
+
+func TestAllocHistoryBuffer(t *testing.T) {
+	start := time.Unix(1000, 0)
+	buffer := newAllocHistoryBuffer(3)
+	assert.Equal(t, len(buffer.since(time.Time{})), 0, "new buffer should not have entries")
+	for i := 1; i <= 5; i++ {
+		buffer.add(AllocationHistoryEntry{AllocationKey: "alloc-" + strconv.Itoa(i), Timestamp: start.Add(time.Duration(i) * time.Second)})
+	}
+	keys := func(entries []AllocationHistoryEntry) []string {
+		result := make([]string, len(entries))
+		for i, entry := range entries {
+			result[i] = entry.AllocationKey
+		}
+		return result
+	}
+	// the oldest entries are evicted, the rest is returned oldest first
+	assert.DeepEqual(t, keys(buffer.since(time.Time{})), []string{"alloc-3", "alloc-4", "alloc-5"})
+	// since is inclusive
+	assert.DeepEqual(t, keys(buffer.since(start.Add(4*time.Second))), []string{"alloc-4", "alloc-5"})
+	assert.Equal(t, len(buffer.since(start.Add(time.Minute))), 0, "no entries expected after the last entry")
+}
+
+func TestGetQueueAllocationHistory(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	assert.Assert(t, partition.GetQueueAllocationHistory(defQueue, time.Time{}) == nil, "queue without allocations should not have history")
+
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 100})
+	err = partition.AddNode(newNodeMaxResource(nodeID1, nodeRes), nil)
+	assert.NilError(t, err, "add node to partition should not have failed")
+	app := newApplication(appID1, "default", defQueue)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	err = app.AddAllocationAsk(newAllocationAskRepeat("alloc-1", appID1, res, 60))
+	assert.NilError(t, err, "failed to add ask alloc-1 to app-1")
+	for i := 0; i < 60; i++ {
+		if alloc := partition.tryAllocate(); alloc == nil {
+			t.Fatalf("allocation %d did not return any allocation", i)
+		}
+	}
+	history := partition.GetQueueAllocationHistory(defQueue, time.Time{})
+	assert.Equal(t, len(history), 60, "unexpected number of history entries")
+	entry := history[0]
+	assert.Equal(t, entry.AppID, appID1, "unexpected application")
+	assert.Equal(t, entry.AllocationKey, "alloc-1", "unexpected allocation key")
+	assert.Equal(t, entry.NodeID, nodeID1, "unexpected node")
+	assert.Equal(t, entry.Action, AllocationHistoryAllocated, "unexpected action")
+	assert.Assert(t, resources.Equals(entry.Resource, res), "unexpected resource")
+
+	released := time.Now()
+	assert.Equal(t, len(partition.removeAllocation(appID1, "")), 60, "unexpected number of released allocations")
+	// the history is capped: the oldest allocated entries are evicted
+	history = partition.GetQueueAllocationHistory(defQueue, time.Time{})
+	assert.Equal(t, len(history), maxQueueAllocationHistory, "history should be capped")
+	assert.DeepEqual(t, countHistoryActions(history), map[string]int{AllocationHistoryAllocated: 40, AllocationHistoryReleased: 60})
+	assert.Equal(t, history[len(history)-1].Action, AllocationHistoryReleased, "newest entry should be last")
+	// only the releases happened after the since time
+	history = partition.GetQueueAllocationHistory("ROOT.DEFAULT", released)
+	assert.DeepEqual(t, countHistoryActions(history), map[string]int{AllocationHistoryReleased: 60})
+	assert.Equal(t, len(partition.GetQueueAllocationHistory(defQueue, time.Now().Add(time.Second))), 0, "no entries expected in the future")
+}
+
+func TestQueueAllocationHistoryReleasePaths(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 100})
+	for _, nodeID := range []string{nodeID1, nodeID2} {
+		err = partition.AddNode(newNodeMaxResource(nodeID, nodeRes), nil)
+		assert.NilError(t, err, "add node to partition should not have failed")
+	}
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	allocate := func(appID string) *objects.Allocation {
+		app := partition.getApplication(appID)
+		if app == nil {
+			app = newApplication(appID, "default", defQueue)
+			err = partition.AddApplication(app)
+			assert.NilError(t, err, "failed to add %s to partition", appID)
+		}
+		err = app.AddAllocationAsk(newAllocationAsk("alloc-"+appID, appID, res))
+		assert.NilError(t, err, "failed to add ask to %s", appID)
+		alloc := partition.tryAllocate()
+		assert.Assert(t, alloc != nil, "allocation for %s failed", appID)
+		return alloc
+	}
+	released := func() map[string]int {
+		counts := make(map[string]int)
+		for _, entry := range partition.GetQueueAllocationHistory(defQueue, time.Time{}) {
+			if entry.Action == AllocationHistoryReleased {
+				counts[entry.AppID]++
+			}
+		}
+		return counts
+	}
+
+	// application removal
+	allocate(appID1)
+	partition.removeApplication(appID1)
+	assert.DeepEqual(t, released(), map[string]int{appID1: 1})
+	// node removal
+	alloc := allocate(appID2)
+	partition.removeNode(alloc.NodeID)
+	assert.DeepEqual(t, released(), map[string]int{appID1: 1, appID2: 1})
+	// clearing the partition
+	allocate(appID2)
+	partition.clearPartition()
+	assert.DeepEqual(t, released(), map[string]int{appID1: 1, appID2: 2})
+}
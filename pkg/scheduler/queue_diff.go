@@ -0,0 +1,149 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+)
+
+// The changes between two queue trees, each list contains the full queue paths sorted.
+// A queue is modified if the max or guaranteed resource, the ACLs or the properties differ.
+type QueueTreeDiff struct {
+	AddedQueues    []string
+	RemovedQueues  []string
+	ModifiedQueues []string
+}
+
+// Compare the queue tree of this partition with the queue tree of the other partition.
+// The other partition is treated as the new state: queues only in the other partition are added.
+// A nil partition is treated as an empty queue tree.
+// Only managed queues that are not draining are compared, dynamic queues are not part of the configuration.
+func (pc *PartitionContext) GetQueueTreeDiff(other *PartitionContext) QueueTreeDiff {
+	return diffQueueTrees(pc.getQueueConfigs(), other.getQueueConfigs())
+}
+
+// Compare the queue trees of the partitions in the cluster with the queue trees from the proposed config.
+// The result is keyed by partition name without the cluster ID. A partition not in the proposed config shows all
+// its queues as removed, a new partition shows all its queues as added.
+// Returns an error if the proposed config does not create valid partitions.
+// NOTE: this call assumes one RM which is registered, the same as the config update from the webservice
+func (cc *ClusterContext) GetQueueTreeDiff(conf *configs.SchedulerConfig) (map[string]QueueTreeDiff, error) {
+	existing := make(map[string]*PartitionContext)
+	rmID := ""
+	for _, part := range cc.GetPartitionMapClone() {
+		existing[common.GetPartitionNameWithoutClusterID(part.Name)] = part
+		rmID = part.RmID
+	}
+	if rmID == "" {
+		return nil, fmt.Errorf("RM has no active partitions, make sure it is registered")
+	}
+	result := make(map[string]QueueTreeDiff)
+	for _, p := range conf.Partitions {
+		name := common.GetPartitionNameWithoutClusterID(p.Name)
+		p.Name = common.GetNormalizedPartitionName(name, rmID)
+		proposed, err := newPartitionContext(p, rmID, nil)
+		if err != nil {
+			return nil, err
+		}
+		result[name] = existing[name].GetQueueTreeDiff(proposed)
+	}
+	for name, part := range existing {
+		if _, ok := result[name]; !ok {
+			result[name] = part.GetQueueTreeDiff(nil)
+		}
+	}
+	return result, nil
+}
+
+// Return the managed queues of the partition keyed by the queue path.
+// The child queues are not included in the returned queue configs.
+func (pc *PartitionContext) getQueueConfigs() map[string]configs.QueueConfig {
+	queues := make(map[string]configs.QueueConfig)
+	if pc == nil {
+		return queues
+	}
+	conf := pc.ExportConfig()
+	for _, queue := range conf.Queues {
+		flattenQueueConfig("", queue, queues)
+	}
+	return queues
+}
+
+func flattenQueueConfig(parentPath string, conf configs.QueueConfig, queues map[string]configs.QueueConfig) {
+	path := conf.Name
+	if parentPath != "" {
+		path = parentPath + configs.DOT + conf.Name
+	}
+	for _, child := range conf.Queues {
+		flattenQueueConfig(path, child, queues)
+	}
+	conf.Queues = nil
+	queues[path] = conf
+}
+
+func diffQueueTrees(current, proposed map[string]configs.QueueConfig) QueueTreeDiff {
+	diff := QueueTreeDiff{
+		AddedQueues:    make([]string, 0),
+		RemovedQueues:  make([]string, 0),
+		ModifiedQueues: make([]string, 0),
+	}
+	for path, conf := range current {
+		newConf, ok := proposed[path]
+		switch {
+		case !ok:
+			diff.RemovedQueues = append(diff.RemovedQueues, path)
+		case isQueueConfigModified(conf, newConf):
+			diff.ModifiedQueues = append(diff.ModifiedQueues, path)
+		}
+	}
+	for path := range proposed {
+		if _, ok := current[path]; !ok {
+			diff.AddedQueues = append(diff.AddedQueues, path)
+		}
+	}
+	sort.Strings(diff.AddedQueues)
+	sort.Strings(diff.RemovedQueues)
+	sort.Strings(diff.ModifiedQueues)
+	return diff
+}
+
+func isQueueConfigModified(current, proposed configs.QueueConfig) bool {
+	return current.AdminACL != proposed.AdminACL ||
+		current.SubmitACL != proposed.SubmitACL ||
+		!equalStringMaps(current.Resources.Max, proposed.Resources.Max) ||
+		!equalStringMaps(current.Resources.Guaranteed, proposed.Resources.Guaranteed) ||
+		!equalStringMaps(current.Properties, proposed.Properties)
+}
+
+// Compare two maps, a nil map is equal to an empty map.
+func equalStringMaps(left, right map[string]string) bool {
+	if len(left) != len(right) {
+		return false
+	}
+	for key, value := range left {
+		if other, ok := right[key]; !ok || other != value {
+			return false
+		}
+	}
+	return true
+}
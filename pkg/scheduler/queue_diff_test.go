@@ -0,0 +1,113 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+)
+
+func newDiffPartitionConfig(name string, children []configs.QueueConfig) configs.PartitionConfig {
+	return configs.PartitionConfig{
+		Name: name,
+		Queues: []configs.QueueConfig{
+			{
+				Name:      "root",
+				Parent:    true,
+				SubmitACL: "*",
+				Queues:    children,
+			},
+		},
+	}
+}
+
+func TestGetQueueTreeDiff(t *testing.T) {
+	current, err := newPartitionContext(newDiffPartitionConfig("test", []configs.QueueConfig{
+		{Name: "max", Resources: configs.Resources{Max: map[string]string{"memory": "100"}}},
+		{Name: "guaranteed", Resources: configs.Resources{Guaranteed: map[string]string{"memory": "10"}}},
+		{Name: "props", Properties: map[string]string{"key": "value"}},
+		{Name: "acl", SubmitACL: "user1"},
+		{Name: "same", Resources: configs.Resources{Max: map[string]string{"memory": "100"}}, Properties: map[string]string{"key": "value"}},
+		{Name: "parent", Parent: true, Queues: []configs.QueueConfig{{Name: "child"}}},
+	}), rmID, nil)
+	assert.NilError(t, err, "current partition create failed")
+	proposed, err := newPartitionContext(newDiffPartitionConfig("test", []configs.QueueConfig{
+		{Name: "max", Resources: configs.Resources{Max: map[string]string{"memory": "200"}}},
+		{Name: "guaranteed", Resources: configs.Resources{Guaranteed: map[string]string{"memory": "10", "vcore": "1"}}},
+		{Name: "props"},
+		{Name: "acl", SubmitACL: "user2"},
+		{Name: "same", Resources: configs.Resources{Max: map[string]string{"memory": "100"}}, Properties: map[string]string{"key": "value"}},
+		{Name: "new", Parent: true, Queues: []configs.QueueConfig{{Name: "child"}}},
+	}), rmID, nil)
+	assert.NilError(t, err, "proposed partition create failed")
+
+	diff := current.GetQueueTreeDiff(proposed)
+	assert.DeepEqual(t, diff.AddedQueues, []string{"root.new", "root.new.child"})
+	assert.DeepEqual(t, diff.RemovedQueues, []string{"root.parent", "root.parent.child"})
+	assert.DeepEqual(t, diff.ModifiedQueues, []string{"root.acl", "root.guaranteed", "root.max", "root.props"})
+
+	// the reverse compare swaps added and removed
+	diff = proposed.GetQueueTreeDiff(current)
+	assert.DeepEqual(t, diff.AddedQueues, []string{"root.parent", "root.parent.child"})
+	assert.DeepEqual(t, diff.RemovedQueues, []string{"root.new", "root.new.child"})
+	assert.DeepEqual(t, diff.ModifiedQueues, []string{"root.acl", "root.guaranteed", "root.max", "root.props"})
+
+	// no changes
+	diff = current.GetQueueTreeDiff(current)
+	assert.Equal(t, len(diff.AddedQueues)+len(diff.RemovedQueues)+len(diff.ModifiedQueues), 0, "same partition should not have changes: %v", diff)
+
+	// compare with nothing: all removed
+	diff = current.GetQueueTreeDiff(nil)
+	assert.Equal(t, len(diff.RemovedQueues), 8, "all queues should have been removed: %v", diff)
+	assert.Equal(t, len(diff.AddedQueues)+len(diff.ModifiedQueues), 0, "only removed queues expected: %v", diff)
+}
+
+func TestClusterGetQueueTreeDiff(t *testing.T) {
+	cc := &ClusterContext{partitions: map[string]*PartitionContext{}}
+	_, err := cc.GetQueueTreeDiff(&configs.SchedulerConfig{})
+	assert.ErrorContains(t, err, "no active partitions")
+
+	partition, err := newConfiguredPartition()
+	assert.NilError(t, err, "partition create failed")
+	cc.partitions[partition.Name] = partition
+	// existing partition changed and a new partition added
+	conf := &configs.SchedulerConfig{
+		Partitions: []configs.PartitionConfig{
+			newDiffPartitionConfig("test", []configs.QueueConfig{
+				{Name: "leaf", Resources: configs.Resources{Max: map[string]string{"memory": "100"}}},
+			}),
+			newDiffPartitionConfig("other", []configs.QueueConfig{{Name: "default"}}),
+		},
+	}
+	diffs, err := cc.GetQueueTreeDiff(conf)
+	assert.NilError(t, err, "diff should not have failed")
+	assert.Equal(t, len(diffs), 2, "unexpected number of partitions compared")
+	assert.DeepEqual(t, diffs["test"].AddedQueues, []string{})
+	assert.DeepEqual(t, diffs["test"].RemovedQueues, []string{"root.parent", "root.parent.sub-leaf"})
+	assert.DeepEqual(t, diffs["test"].ModifiedQueues, []string{"root.leaf"})
+	assert.DeepEqual(t, diffs["other"].AddedQueues, []string{"root", "root.default"})
+
+	// an invalid partition in the proposed config fails
+	conf.Partitions = append(conf.Partitions, configs.PartitionConfig{Name: "invalid"})
+	_, err = cc.GetQueueTreeDiff(conf)
+	assert.ErrorContains(t, err, "root queue")
+}
@@ -0,0 +1,111 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
+)
+
+type QueueEventType string
+
+const (
+	QueuePropertyUpdated QueueEventType = "PropertyUpdated"
+)
+
+// A runtime change of a queue in the partition.
+// The properties are the properties that were changed, not all the properties of the queue.
+type QueueEvent struct {
+	Type       QueueEventType
+	QueuePath  string
+	Properties map[string]string
+	Timestamp  time.Time
+}
+
+// Register the channel to receive all queue events of the partition.
+// Events are sent without blocking: an event is dropped if the channel is full.
+// Registering a channel that is already registered is a no-op.
+func (pc *PartitionContext) ListenForQueueEvents(ch chan<- QueueEvent) {
+	pc.Lock()
+	defer pc.Unlock()
+	for _, listener := range pc.queueListeners {
+		if listener == ch {
+			return
+		}
+	}
+	pc.queueListeners = append(pc.queueListeners, ch)
+}
+
+// Stop sending queue events to the channel. The channel is not closed.
+func (pc *PartitionContext) StopListeningForQueueEvents(ch chan<- QueueEvent) {
+	pc.Lock()
+	defer pc.Unlock()
+	for i, listener := range pc.queueListeners {
+		if listener == ch {
+			pc.queueListeners = append(pc.queueListeners[:i], pc.queueListeners[i+1:]...)
+			return
+		}
+	}
+}
+
+// Update the properties of the queue and notify the queue event listeners of the change.
+func (pc *PartitionContext) UpdateQueueProperties(queuePath string, props map[string]string) error {
+	pc.Lock()
+	defer pc.Unlock()
+	queue := pc.getQueue(queuePath)
+	if queue == nil {
+		return fmt.Errorf("queue %s not found in partition %s", queuePath, pc.Name)
+	}
+	if err := queue.SetProperties(props); err != nil {
+		return err
+	}
+	log.Logger().Info("queue properties updated",
+		zap.String("queueName", queuePath),
+		zap.Any("properties", props))
+	changed := make(map[string]string, len(props))
+	for key, value := range props {
+		changed[key] = value
+	}
+	pc.sendQueueEventInternal(QueueEvent{
+		Type:       QueuePropertyUpdated,
+		QueuePath:  queuePath,
+		Properties: changed,
+		Timestamp:  time.Now(),
+	})
+	return nil
+}
+
+// Send the event to all registered listeners, a listener that is not ready misses the event.
+// Unlocked version must be called holding the partition lock.
+func (pc *PartitionContext) sendQueueEventInternal(event QueueEvent) {
+	for _, listener := range pc.queueListeners {
+		select {
+		case listener <- event:
+		default:
+			log.Logger().Warn("queue event listener is full, event dropped",
+				zap.String("partition", pc.Name),
+				zap.String("eventType", string(event.Type)),
+				zap.String("queueName", event.QueuePath))
+		}
+	}
+}
@@ -0,0 +1,65 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+// Keys of removed objects, like nodes or queues, for which the partition still keeps a history.
+// The number of keys is capped: when a new removal does not fit, the oldest removal drops out and its
+// history should be dropped by the owner. Not locked, the owner must protect access.
+type removedKeys struct {
+	keys  []string // oldest removal first
+	limit int
+}
+
+func newRemovedKeys(limit int) *removedKeys {
+	return &removedKeys{
+		limit: limit,
+	}
+}
+
+// Record the removal of the key. Returns the key that dropped out and true if the list was full.
+func (r *removedKeys) add(key string) (string, bool) {
+	r.remove(key)
+	r.keys = append(r.keys, key)
+	if len(r.keys) <= r.limit {
+		return "", false
+	}
+	evicted := r.keys[0]
+	r.keys = r.keys[1:]
+	return evicted, true
+}
+
+// Forget the removal of the key, used when the object is added again.
+func (r *removedKeys) remove(key string) {
+	for i, removed := range r.keys {
+		if removed == key {
+			r.keys = append(r.keys[:i], r.keys[i+1:]...)
+			return
+		}
+	}
+}
+
+// Return true if the removal of the key is recorded.
+func (r *removedKeys) contains(key string) bool {
+	for _, removed := range r.keys {
+		if removed == key {
+			return true
+		}
+	}
+	return false
+}
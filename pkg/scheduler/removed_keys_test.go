@@ -0,0 +1,47 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestRemovedKeys(t *testing.T) {
+	removed := newRemovedKeys(2)
+	_, evicted := removed.add("key-1")
+	assert.Assert(t, !evicted, "nothing should be evicted below the limit")
+	_, evicted = removed.add("key-2")
+	assert.Assert(t, !evicted, "nothing should be evicted at the limit")
+	assert.Assert(t, removed.contains("key-1") && removed.contains("key-2"), "keys should have been recorded")
+
+	// removing a key again makes it the newest removal
+	_, evicted = removed.add("key-1")
+	assert.Assert(t, !evicted, "a known key should not evict another key")
+	key, evicted := removed.add("key-3")
+	assert.Assert(t, evicted, "oldest key should have been evicted over the limit")
+	assert.Equal(t, key, "key-2", "unexpected key evicted")
+
+	// a key that is added again is forgotten
+	removed.remove("key-1")
+	assert.Assert(t, !removed.contains("key-1"), "key should have been forgotten")
+	_, evicted = removed.add("key-4")
+	assert.Assert(t, !evicted, "forgotten key should have made room")
+}
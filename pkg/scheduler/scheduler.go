@@ -65,9 +65,13 @@ func (s *Scheduler) StartService(handlers handler.EventHandlers, manualSchedule
 }
 
 // Internal start scheduling service
+// The next cycle starts immediately after an allocation, otherwise wait for the scheduling interval.
+// The interval is read each cycle which picks up configuration changes without restarting the loop.
 func (s *Scheduler) internalSchedule() {
 	for {
-		s.clusterContext.schedule()
+		if !s.clusterContext.schedule() {
+			time.Sleep(s.clusterContext.getSchedulingInterval())
+		}
 	}
 }
 
@@ -0,0 +1,210 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"sort"
+	"time"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/plugins"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
+)
+
+type NodeRejectionReason string
+
+const (
+	NodeUnschedulable        NodeRejectionReason = "Unschedulable"
+	NodeReserved             NodeRejectionReason = "Reserved"
+	NodeACLDenied            NodeRejectionReason = "ACLDenied"
+	NodeSelectorMismatch     NodeRejectionReason = "NodeSelectorMismatch"
+	NodeInsufficientCPU      NodeRejectionReason = "InsufficientCPU"
+	NodeInsufficientMemory   NodeRejectionReason = "InsufficientMemory"
+	NodeInsufficientStorage  NodeRejectionReason = "InsufficientStorage"
+	NodeInsufficientResource NodeRejectionReason = "InsufficientResource"
+)
+
+// The result of a scheduling dry run for the most urgent ask of an application.
+// Nodes maps each node in the partition to the reasons the ask cannot be placed on it, no reasons means the ask
+// fits on the node. The allocation key is empty if the application has no pending asks.
+type SchedulingDiagnostics struct {
+	ApplicationID string
+	AllocationKey string
+	Nodes         map[string][]NodeRejectionReason
+}
+
+// Run a dry run of the allocation of the most urgent pending ask of the application on all nodes.
+// The most urgent ask is the ask with the highest priority, the oldest ask if priorities are equal.
+// Nothing is changed in the partition, the application or the nodes.
+func (pc *PartitionContext) GetSchedulingDiagnostics(appID string) SchedulingDiagnostics {
+	diagnostics := SchedulingDiagnostics{
+		ApplicationID: appID,
+	}
+	pc.RLock()
+	defer pc.RUnlock()
+	app := pc.applications[appID]
+	if app == nil {
+		return diagnostics
+	}
+	ask := getMostUrgentAsk(app.GetPendingAsks())
+	if ask == nil {
+		return diagnostics
+	}
+	diagnostics.AllocationKey = ask.AllocationKey
+	diagnostics.Nodes = make(map[string][]NodeRejectionReason, len(pc.nodes))
+	// submit access is checked for the queue, it applies to every node
+	aclDenied := false
+	if queue := pc.getQueue(app.GetQueueName()); queue != nil && !queue.CheckSubmitAccess(app.GetUser()) {
+		aclDenied = true
+	}
+	for nodeID, node := range pc.nodes {
+		reasons := make([]NodeRejectionReason, 0)
+		if aclDenied {
+			reasons = append(reasons, NodeACLDenied)
+		}
+		reasons = append(reasons, getNodeRejectionReasons(app, ask, node)...)
+		diagnostics.Nodes[nodeID] = reasons
+	}
+	return diagnostics
+}
+
+// Limits on the size of the fit matrix.
+const (
+	maxFitMatrixAsks  = 50
+	maxFitMatrixNodes = 20
+)
+
+// Return for pending asks in the partition if they fit on a node, keyed by allocation key and node ID.
+// An ask fits on a node if a dry run of the allocation, as for the scheduling diagnostics, finds no reason to
+// reject the node. The matrix is limited to the 50 most urgent asks and the 20 nodes with the most free capacity.
+// Nothing is changed in the partition, the applications or the nodes.
+func (pc *PartitionContext) GetNodeFitMatrix() map[string]map[string]bool {
+	pc.RLock()
+	defer pc.RUnlock()
+	type pendingAsk struct {
+		app   *objects.Application
+		ask   *objects.AllocationAsk
+		score float64
+	}
+	now := time.Now()
+	var asks []pendingAsk
+	for _, app := range pc.applications {
+		for _, ask := range app.GetPendingAsks() {
+			asks = append(asks, pendingAsk{app: app, ask: ask, score: getAskUrgencyScore(ask, now)})
+		}
+	}
+	sort.Slice(asks, func(i, j int) bool {
+		if asks[i].score != asks[j].score {
+			return asks[i].score > asks[j].score
+		}
+		return asks[i].ask.AllocationKey < asks[j].ask.AllocationKey
+	})
+	if len(asks) > maxFitMatrixAsks {
+		asks = asks[:maxFitMatrixAsks]
+	}
+
+	nodes := make([]*objects.Node, 0, len(pc.nodes))
+	free := make(map[string]float64, len(pc.nodes))
+	for _, node := range pc.nodes {
+		nodes = append(nodes, node)
+		free[node.NodeID] = node.GetAvailableResource().DominantFraction(pc.totalPartitionResource)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		if free[nodes[i].NodeID] != free[nodes[j].NodeID] {
+			return free[nodes[i].NodeID] > free[nodes[j].NodeID]
+		}
+		return nodes[i].NodeID < nodes[j].NodeID
+	})
+	if len(nodes) > maxFitMatrixNodes {
+		nodes = nodes[:maxFitMatrixNodes]
+	}
+
+	matrix := make(map[string]map[string]bool, len(asks))
+	for _, pending := range asks {
+		fits := make(map[string]bool, len(nodes))
+		for _, node := range nodes {
+			fits[node.NodeID] = len(getNodeRejectionReasons(pending.app, pending.ask, node)) == 0
+		}
+		matrix[pending.ask.AllocationKey] = fits
+	}
+	return matrix
+}
+
+// Return the urgency of the ask: the age of the ask in seconds multiplied by its effective priority.
+// Priorities below 1 count as 1 so the age still orders asks without a priority.
+func getAskUrgencyScore(ask *objects.AllocationAsk, now time.Time) float64 {
+	priority := ask.GetEffectivePriority()
+	if priority < 1 {
+		priority = 1
+	}
+	return now.Sub(ask.GetCreateTime()).Seconds() * float64(priority)
+}
+
+// Return the reasons the ask cannot be allocated on the node.
+// The predicates are checked as for a reservation to prevent side effects in the shim.
+func getNodeRejectionReasons(app *objects.Application, ask *objects.AllocationAsk, node *objects.Node) []NodeRejectionReason {
+	var reasons []NodeRejectionReason
+	if !node.IsSchedulable() {
+		reasons = append(reasons, NodeUnschedulable)
+	}
+	if node.IsReserved() && !app.IsReservedOnNode(node.NodeID) {
+		reasons = append(reasons, NodeReserved)
+	}
+	if plugin := plugins.GetPredicatesPlugin(); plugin != nil {
+		if err := plugin.Predicates(&si.PredicatesArgs{
+			AllocationKey: ask.AllocationKey,
+			NodeID:        node.NodeID,
+			Allocate:      false,
+		}); err != nil {
+			reasons = append(reasons, NodeSelectorMismatch)
+		}
+	}
+	unsatisfied := make(map[string]bool)
+	otherResource := false
+	for _, name := range node.GetAllocationFitReport(ask).UnsatisfiedResources {
+		unsatisfied[name] = true
+		otherResource = otherResource || (name != resources.VCORE && name != resources.MEMORY && name != resources.STORAGE)
+	}
+	if unsatisfied[resources.VCORE] {
+		reasons = append(reasons, NodeInsufficientCPU)
+	}
+	if unsatisfied[resources.MEMORY] {
+		reasons = append(reasons, NodeInsufficientMemory)
+	}
+	if unsatisfied[resources.STORAGE] {
+		reasons = append(reasons, NodeInsufficientStorage)
+	}
+	if otherResource {
+		reasons = append(reasons, NodeInsufficientResource)
+	}
+	return reasons
+}
+
+// Return the pending ask with the highest priority, the oldest ask wins if priorities are equal.
+func getMostUrgentAsk(asks []*objects.AllocationAsk) *objects.AllocationAsk {
+	var urgent *objects.AllocationAsk
+	for _, ask := range asks {
+		if urgent == nil || ask.GetEffectivePriority() > urgent.GetEffectivePriority() ||
+			(ask.GetEffectivePriority() == urgent.GetEffectivePriority() && ask.GetCreateTime().Before(urgent.GetCreateTime())) {
+			urgent = ask
+		}
+	}
+	return urgent
+}
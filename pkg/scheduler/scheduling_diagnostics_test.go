@@ -0,0 +1,222 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/security"
+	"github.com/apache/incubator-yunikorn-core/pkg/plugins"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
+)
+
+// predicates plugin that rejects a single node
+type rejectNodePlugin struct {
+	nodeID string
+}
+
+func (p *rejectNodePlugin) Predicates(args *si.PredicatesArgs) error {
+	if args.NodeID == p.nodeID {
+		return fmt.Errorf("node selector does not match node %s", args.NodeID)
+	}
+	return nil
+}
+
+func diagnosticsConfig(submitACL string) configs.PartitionConfig {
+	return configs.PartitionConfig{
+		Name: "test",
+		Queues: []configs.QueueConfig{
+			{
+				Name:   "root",
+				Parent: true,
+				Queues: []configs.QueueConfig{
+					{Name: "default", SubmitACL: submitACL},
+				},
+			},
+		},
+	}
+}
+
+func TestGetSchedulingDiagnostics(t *testing.T) {
+	plugin := &rejectNodePlugin{nodeID: "node-selector"}
+	plugins.RegisterSchedulerPlugin(plugin)
+	// the plugin cannot be removed: make it pass all nodes
+	defer func() { plugin.nodeID = "" }()
+
+	partition, err := newPartitionContext(diagnosticsConfig("testuser"), rmID, nil)
+	assert.NilError(t, err, "partition create failed")
+	fits := resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 100, resources.VCORE: 10})
+	nodeRes := map[string]*resources.Resource{
+		"node-fit":           fits,
+		"node-unschedulable": fits,
+		"node-reserved":      fits,
+		"node-selector":      fits,
+		"node-cpu":           resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 100, resources.VCORE: 1}),
+		"node-memory":        resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 10, resources.VCORE: 10}),
+	}
+	for nodeID, res := range nodeRes {
+		err = partition.AddNode(newNodeMaxResource(nodeID, res), nil)
+		assert.NilError(t, err, "add node to partition should not have failed")
+	}
+	partition.GetNode("node-unschedulable").SetSchedulable(false)
+
+	user := security.UserGroup{User: "testuser"}
+	app := objects.NewApplication(appID1, "default", "root.default", user, nil, nil, rmID)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "add application to partition should not have failed")
+	other := objects.NewApplication(appID2, "default", "root.default", user, nil, nil, rmID)
+	err = partition.AddApplication(other)
+	assert.NilError(t, err, "add application to partition should not have failed")
+	askRes := resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 50, resources.VCORE: 5})
+	otherAsk := newAllocationAsk("other-ask", appID2, askRes)
+	err = other.AddAllocationAsk(otherAsk)
+	assert.NilError(t, err, "failed to add ask to app")
+	err = partition.GetNode("node-reserved").Reserve(other, otherAsk)
+	assert.NilError(t, err, "failed to reserve node")
+
+	// no pending asks: nothing to diagnose
+	diagnostics := partition.GetSchedulingDiagnostics(appID1)
+	assert.Equal(t, diagnostics.AllocationKey, "", "application without asks should not diagnose an ask")
+	assert.Assert(t, diagnostics.Nodes == nil, "application without asks should not have node results")
+
+	err = app.AddAllocationAsk(newAllocationAskPriority("ask-low", appID1, askRes, 1, 1))
+	assert.NilError(t, err, "failed to add ask to app")
+	time.Sleep(time.Millisecond)
+	err = app.AddAllocationAsk(newAllocationAskPriority("ask-high", appID1, askRes, 1, 5))
+	assert.NilError(t, err, "failed to add ask to app")
+
+	diagnostics = partition.GetSchedulingDiagnostics(appID1)
+	assert.Equal(t, diagnostics.ApplicationID, appID1, "unexpected application")
+	assert.Equal(t, diagnostics.AllocationKey, "ask-high", "most urgent ask not diagnosed")
+	expected := map[string][]NodeRejectionReason{
+		"node-fit":           {},
+		"node-unschedulable": {NodeUnschedulable},
+		"node-reserved":      {NodeReserved},
+		"node-selector":      {NodeSelectorMismatch},
+		"node-cpu":           {NodeInsufficientCPU},
+		"node-memory":        {NodeInsufficientMemory},
+	}
+	assert.DeepEqual(t, diagnostics.Nodes, expected)
+
+	// remove submit access: the ACL applies to all nodes
+	err = partition.updatePartitionDetails(diagnosticsConfig("otheruser"))
+	assert.NilError(t, err, "partition update failed")
+	diagnostics = partition.GetSchedulingDiagnostics(appID1)
+	assert.DeepEqual(t, diagnostics.Nodes["node-fit"], []NodeRejectionReason{NodeACLDenied})
+	assert.DeepEqual(t, diagnostics.Nodes["node-cpu"], []NodeRejectionReason{NodeACLDenied, NodeInsufficientCPU})
+
+	// unknown application
+	diagnostics = partition.GetSchedulingDiagnostics("unknown")
+	assert.Assert(t, diagnostics.Nodes == nil, "unknown application should not have node results")
+}
+
+func TestGetSchedulingDiagnosticsStorage(t *testing.T) {
+	partition, err := newPartitionContext(diagnosticsConfig("*"), rmID, nil)
+	assert.NilError(t, err, "partition create failed")
+	nodeRes := map[string]*resources.Resource{
+		"node-fit":     resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 100, resources.STORAGE: 100}),
+		"node-storage": resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 100, resources.STORAGE: 10}),
+	}
+	for nodeID, res := range nodeRes {
+		err = partition.AddNode(newNodeMaxResource(nodeID, res), nil)
+		assert.NilError(t, err, "add node to partition should not have failed")
+	}
+	app := newApplication(appID1, "default", "root.default")
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "add application to partition should not have failed")
+	askRes := resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 50, resources.STORAGE: 50})
+	err = app.AddAllocationAsk(newAllocationAsk("ask-storage", appID1, askRes))
+	assert.NilError(t, err, "failed to add ask to app")
+
+	diagnostics := partition.GetSchedulingDiagnostics(appID1)
+	expected := map[string][]NodeRejectionReason{
+		"node-fit":     {},
+		"node-storage": {NodeInsufficientStorage},
+	}
+	assert.DeepEqual(t, diagnostics.Nodes, expected)
+}
+
+func TestGetNodeFitMatrix(t *testing.T) {
+	partition, err := newPartitionContext(diagnosticsConfig("*"), rmID, nil)
+	assert.NilError(t, err, "partition create failed")
+	assert.Equal(t, len(partition.GetNodeFitMatrix()), 0, "empty partition should have an empty matrix")
+	for nodeID, size := range map[string]resources.Quantity{"node-small": 5, "node-medium": 10, "node-large": 20} {
+		err = partition.AddNode(newNodeMaxResource(nodeID, resources.NewResourceFromMap(map[string]resources.Quantity{"first": size})), nil)
+		assert.NilError(t, err, "failed to add node %s", nodeID)
+	}
+	app := newApplication(appID1, "default", defQueue)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app to partition")
+	for allocKey, size := range map[string]resources.Quantity{"ask-3": 3, "ask-5": 5, "ask-8": 8, "ask-15": 15, "ask-25": 25} {
+		err = app.AddAllocationAsk(newAllocationAsk(allocKey, appID1, resources.NewResourceFromMap(map[string]resources.Quantity{"first": size})))
+		assert.NilError(t, err, "failed to add ask %s", allocKey)
+	}
+	assert.DeepEqual(t, partition.GetNodeFitMatrix(), map[string]map[string]bool{
+		"ask-3":  {"node-small": true, "node-medium": true, "node-large": true},
+		"ask-5":  {"node-small": true, "node-medium": true, "node-large": true},
+		"ask-8":  {"node-small": false, "node-medium": true, "node-large": true},
+		"ask-15": {"node-small": false, "node-medium": false, "node-large": true},
+		"ask-25": {"node-small": false, "node-medium": false, "node-large": false},
+	})
+}
+
+func TestGetNodeFitMatrixLimits(t *testing.T) {
+	partition, err := newPartitionContext(diagnosticsConfig("*"), rmID, nil)
+	assert.NilError(t, err, "partition create failed")
+	for i := 1; i <= maxFitMatrixNodes+5; i++ {
+		res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": resources.Quantity(i)})
+		err = partition.AddNode(newNodeMaxResource(fmt.Sprintf("node-%d", i), res), nil)
+		assert.NilError(t, err, "failed to add node")
+	}
+	app := newApplication(appID1, "default", defQueue)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app to partition")
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	for i := 0; i < maxFitMatrixAsks+10; i++ {
+		err = app.AddAllocationAsk(newAllocationAsk(fmt.Sprintf("ask-%d", i), appID1, res))
+		assert.NilError(t, err, "failed to add ask")
+	}
+	matrix := partition.GetNodeFitMatrix()
+	assert.Equal(t, len(matrix), maxFitMatrixAsks, "asks not limited")
+	for allocKey, fits := range matrix {
+		assert.Equal(t, len(fits), maxFitMatrixNodes, "nodes not limited for %s", allocKey)
+		// the nodes with the most free capacity are used
+		for i := 1; i <= 5; i++ {
+			_, ok := fits[fmt.Sprintf("node-%d", i)]
+			assert.Assert(t, !ok, "small node-%d should not be in the matrix", i)
+		}
+	}
+}
+
+func TestGetAskUrgencyScore(t *testing.T) {
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	ask := newAllocationAskPriority("alloc-1", appID1, res, 1, 5)
+	now := ask.GetCreateTime().Add(10 * time.Second)
+	assert.Equal(t, getAskUrgencyScore(ask, now), 50.0, "unexpected score")
+	ask = newAllocationAskPriority("alloc-2", appID1, res, 1, -5)
+	now = ask.GetCreateTime().Add(10 * time.Second)
+	assert.Equal(t, getAskUrgencyScore(ask, now), 10.0, "low priority should count as 1")
+}
@@ -0,0 +1,112 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+)
+
+// maximum number of scheduling waves kept per partition, oldest waves are overwritten first
+const maxSchedulingWaves = 100
+
+// A burst of allocations committed in back to back scheduling cycles.
+// A scheduling cycle commits at most one allocation per partition: the wave starts with the first cycle that
+// allocates and ends with the first cycle after it that does not allocate.
+type SchedulingWave struct {
+	WaveID           int                 // sequence number of the wave in the partition, starting at 1
+	StartTime        time.Time           // time of the first allocation in the wave
+	Duration         time.Duration       // time between the first and the last allocation in the wave
+	AllocationsCount int                 // number of allocations committed in the wave
+	TotalResource    *resources.Resource // resources of all allocations committed in the wave
+}
+
+// Ring buffer of completed scheduling waves and the wave in progress.
+// Locked, updated from the scheduling cycle and read from the web service.
+type schedulingWaveBuffer struct {
+	waves   *common.RingBuffer // completed waves, the oldest wave is overwritten first
+	current *SchedulingWave    // wave in progress, nil if the last cycle did not allocate
+	lastID  int                // ID of the last wave started
+
+	sync.RWMutex
+}
+
+func newSchedulingWaveBuffer(size int) *schedulingWaveBuffer {
+	return &schedulingWaveBuffer{
+		waves: common.NewRingBuffer(size),
+	}
+}
+
+// Record the result of a scheduling cycle that ended at the time, alloc is nil if the cycle did not allocate.
+func (b *schedulingWaveBuffer) recordCycle(alloc *objects.Allocation, now time.Time) {
+	b.Lock()
+	defer b.Unlock()
+	if alloc == nil {
+		if b.current != nil {
+			b.waves.Add(*b.current)
+			b.current = nil
+		}
+		return
+	}
+	if b.current == nil {
+		b.lastID++
+		b.current = &SchedulingWave{
+			WaveID:        b.lastID,
+			StartTime:     now,
+			TotalResource: resources.NewResource(),
+		}
+	}
+	b.current.AllocationsCount++
+	b.current.TotalResource.AddTo(alloc.AllocatedResource)
+	b.current.Duration = now.Sub(b.current.StartTime)
+}
+
+// Return a copy of the completed waves, oldest wave first, followed by the wave in progress if there is one.
+func (b *schedulingWaveBuffer) getWaves() []SchedulingWave {
+	b.RLock()
+	defer b.RUnlock()
+	result := make([]SchedulingWave, 0, b.waves.Len()+1)
+	for i := 0; i < b.waves.Len(); i++ {
+		wave := b.waves.Get(i).(SchedulingWave)
+		wave.TotalResource = wave.TotalResource.Clone()
+		result = append(result, wave)
+	}
+	if b.current != nil {
+		wave := *b.current
+		wave.TotalResource = wave.TotalResource.Clone()
+		result = append(result, wave)
+	}
+	return result
+}
+
+// Return the scheduling waves of the partition, oldest wave first. The last wave is still in progress if the last
+// scheduling cycle allocated. At most the last 100 completed waves are kept.
+func (pc *PartitionContext) GetSchedulingWaves() []SchedulingWave {
+	return pc.schedulingWaves.getWaves()
+}
+
+// Record the result of a scheduling cycle of the partition, alloc is nil if the cycle did not allocate.
+// Lock free call, the waves are locked separately.
+func (pc *PartitionContext) recordSchedulingCycle(alloc *objects.Allocation) {
+	pc.schedulingWaves.recordCycle(alloc, time.Now())
+}
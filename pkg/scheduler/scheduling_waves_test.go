@@ -0,0 +1,105 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+)
+
+// RM mock handler that drops all events
+type dropEventHandler struct{}
+
+func (d *dropEventHandler) HandleEvent(ev interface{}) {}
+
+func TestSchedulingWaves(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	assert.Equal(t, len(partition.GetSchedulingWaves()), 0, "new partition should not have waves")
+	cc := &ClusterContext{
+		partitions:     map[string]*PartitionContext{partition.Name: partition},
+		rmEventHandler: &dropEventHandler{},
+	}
+	err = partition.AddNode(newNodeMaxResource(nodeID1, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 200, "second": 200})), nil)
+	assert.NilError(t, err, "add node to partition should not have failed")
+	app := newApplication(appID1, "default", defQueue)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "add application to partition should not have failed")
+	addAsks := func(prefix string, count int) {
+		res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1, "second": 2})
+		for i := 0; i < count; i++ {
+			err = app.AddAllocationAsk(newAllocationAsk(fmt.Sprintf("%s-%d", prefix, i), appID1, res))
+			assert.NilError(t, err, "failed to add ask to app")
+		}
+	}
+
+	// cycles without allocations do not start a wave
+	cc.schedule()
+	assert.Equal(t, len(partition.GetSchedulingWaves()), 0, "cycle without allocations should not start a wave")
+
+	// one cycle per allocation, the first cycle that does not allocate ends the wave
+	addAsks("first-wave", 50)
+	start := time.Now()
+	for i := 0; i < 50; i++ {
+		assert.Assert(t, cc.schedule(), "cycle %d should have allocated", i)
+	}
+	end := time.Now()
+	assert.Assert(t, !cc.schedule(), "all asks should have been allocated")
+	cc.schedule()
+	waves := partition.GetSchedulingWaves()
+	assert.Equal(t, len(waves), 1, "expected one completed wave")
+	assert.Equal(t, waves[0].WaveID, 1, "unexpected wave ID")
+	assert.Equal(t, waves[0].AllocationsCount, 50, "unexpected number of allocations in the wave")
+	assert.Assert(t, resources.Equals(waves[0].TotalResource, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 50, "second": 100})), "unexpected wave resource: %s", waves[0].TotalResource)
+	assert.Assert(t, !waves[0].StartTime.Before(start) && waves[0].Duration <= end.Sub(start), "unexpected wave timing: %v %v", waves[0].StartTime, waves[0].Duration)
+
+	// the wave in progress is returned last
+	addAsks("second-wave", 10)
+	for i := 0; i < 10; i++ {
+		assert.Assert(t, cc.schedule(), "cycle %d should have allocated", i)
+	}
+	waves = partition.GetSchedulingWaves()
+	assert.Equal(t, len(waves), 2, "wave in progress not returned")
+	assert.Equal(t, waves[1].WaveID, 2, "unexpected wave ID")
+	assert.Equal(t, waves[1].AllocationsCount, 10, "unexpected number of allocations in the wave in progress")
+	// the returned waves are copies
+	waves[0].TotalResource.AddTo(waves[1].TotalResource)
+	assert.Assert(t, resources.Equals(partition.GetSchedulingWaves()[0].TotalResource, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 50, "second": 100})), "returned wave changed the partition")
+}
+
+func TestSchedulingWaveBufferLimit(t *testing.T) {
+	buffer := newSchedulingWaveBuffer(maxSchedulingWaves)
+	ask := newAllocationAsk("alloc-1", appID1, resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1}))
+	alloc := objects.NewAllocation("uuid-1", nodeID1, ask)
+	now := time.Now()
+	for i := 0; i < maxSchedulingWaves+5; i++ {
+		buffer.recordCycle(alloc, now)
+		buffer.recordCycle(nil, now)
+	}
+	waves := buffer.getWaves()
+	assert.Equal(t, len(waves), maxSchedulingWaves, "buffer should be limited")
+	assert.Equal(t, waves[0].WaveID, 6, "oldest waves should have been evicted")
+	assert.Equal(t, waves[len(waves)-1].WaveID, maxSchedulingWaves+5, "newest wave should be last")
+}
@@ -1228,3 +1228,78 @@ partitions:
 		assert.Equal(t, int(node.GetAllocatedResource().Resources[resources.MEMORY]), 20, "node %s did not get 2 allocated", node.NodeID)
 	}
 }
+
+// Test the scheduling interval with the automatic scheduler and changing the interval via a config reload
+func TestSchedulingIntervalReload(t *testing.T) {
+	configData := `
+partitions:
+  - name: default
+    schedulingintervalms: 1000
+    queues:
+      - name: root
+        submitacl: "*"
+        queues:
+          - name: a
+`
+	ms := &mockScheduler{}
+	defer ms.Stop()
+
+	err := ms.Init(configData, true)
+	assert.NilError(t, err, "RegisterResourceManager failed")
+	part := ms.scheduler.GetClusterContext().GetPartition(partition)
+	assert.Equal(t, part.GetSchedulingInterval(), time.Second, "scheduling interval not set from the config")
+
+	err = ms.addNode("node-1:1234", &si.Resource{
+		Resources: map[string]*si.Quantity{
+			"memory": {Value: 100},
+			"vcore":  {Value: 20},
+		},
+	})
+	assert.NilError(t, err, "NewNode failed")
+	err = ms.addApp(appID1, "root.a", "default")
+	assert.NilError(t, err, "AddApplication failed")
+	ms.mockRM.waitForAcceptedNode(t, "node-1:1234", 1000)
+	ms.mockRM.waitForAcceptedApplication(t, appID1, 1000)
+
+	askRes := &si.Resource{
+		Resources: map[string]*si.Quantity{
+			"memory": {Value: 10},
+			"vcore":  {Value: 1},
+		},
+	}
+	// an idle scheduler waits at most one interval before picking up the ask
+	err = ms.addAppRequest(appID1, "alloc-1", askRes, 1)
+	assert.NilError(t, err, "AllocationAsk failed")
+	ms.mockRM.waitForAllocations(t, 1, 3000)
+
+	configData = `
+partitions:
+  - name: default
+    schedulingintervalms: 10
+    queues:
+      - name: root
+        submitacl: "*"
+        queues:
+          - name: a
+`
+	configs.MockSchedulerConfigByData([]byte(configData))
+	err = ms.proxy.ReloadConfiguration("rm:123")
+	assert.NilError(t, err, "configuration reload failed")
+	err = common.WaitFor(10*time.Millisecond, 5*time.Second, func() bool {
+		return part.GetSchedulingInterval() == 10*time.Millisecond
+	})
+	assert.NilError(t, err, "scheduling interval not updated by the config reload")
+
+	// the loop might still be waiting for the old interval: the new interval is used after the next cycle
+	err = ms.addAppRequest(appID1, "alloc-2", askRes, 1)
+	assert.NilError(t, err, "AllocationAsk failed")
+	ms.mockRM.waitForAllocations(t, 2, 3000)
+
+	// the loop runs with the new interval without a restart
+	start := time.Now()
+	err = ms.addAppRequest(appID1, "alloc-3", askRes, 1)
+	assert.NilError(t, err, "AllocationAsk failed")
+	ms.mockRM.waitForAllocations(t, 3, 1000)
+	latency := time.Since(start)
+	assert.Assert(t, latency < 500*time.Millisecond, "allocation took longer than expected with the short interval: %v", latency)
+}
@@ -0,0 +1,89 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"time"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+)
+
+// Maximum number of application completion times kept, older completions are overwritten.
+const maxAppCompletionTimes = 10000
+
+// Ring buffer of completion times, the oldest entry is overwritten first.
+// Not locked, the owner must protect access.
+type completionTimeBuffer struct {
+	*common.RingBuffer
+}
+
+func newCompletionTimeBuffer(size int) *completionTimeBuffer {
+	return &completionTimeBuffer{
+		RingBuffer: common.NewRingBuffer(size),
+	}
+}
+
+// Record a completion at the time.
+func (b *completionTimeBuffer) add(completed time.Time) {
+	b.Add(completed)
+}
+
+// Return the number of completions after the start time up to and including the end time.
+func (b *completionTimeBuffer) count(start, end time.Time) int {
+	total := 0
+	for i := 0; i < b.Len(); i++ {
+		completed := b.Get(i).(time.Time)
+		if completed.After(start) && !completed.After(end) {
+			total++
+		}
+	}
+	return total
+}
+
+// Return the number of applications completed per second, averaged over the window ending now.
+// The window is truncated to whole seconds. Returns -1 if the window is shorter than a second or longer
+// than objects.MaxAllocationRateWindow.
+func (pc *PartitionContext) GetApplicationThroughput(window time.Duration) float64 {
+	return pc.getApplicationThroughput(time.Now(), window)
+}
+
+func (pc *PartitionContext) getApplicationThroughput(now time.Time, window time.Duration) float64 {
+	seconds := int64(window / time.Second)
+	if seconds < 1 || window > objects.MaxAllocationRateWindow {
+		return -1
+	}
+	pc.RLock()
+	defer pc.RUnlock()
+	count := pc.appCompletionTimes.count(now.Add(-time.Duration(seconds)*time.Second), now)
+	return float64(count) / float64(seconds)
+}
+
+// Return the number of allocations committed in the partition per second, averaged over the window ending now.
+// The window is truncated to whole seconds. Returns -1 if the window is shorter than a second or longer
+// than objects.MaxAllocationRateWindow.
+func (pc *PartitionContext) GetAllocationThroughput(window time.Duration) float64 {
+	seconds := int64(window / time.Second)
+	if seconds < 1 || window > objects.MaxAllocationRateWindow {
+		return -1
+	}
+	pc.RLock()
+	defer pc.RUnlock()
+	return float64(pc.root.GetAllocationCount(window)) / float64(seconds)
+}
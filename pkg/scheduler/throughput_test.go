@@ -0,0 +1,100 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+)
+
+func TestCompletionTimeBuffer(t *testing.T) {
+	now := time.Now()
+	buffer := newCompletionTimeBuffer(0)
+	buffer.add(now)
+	assert.Equal(t, buffer.count(now.Add(-time.Minute), now), 0, "zero size buffer should not keep entries")
+
+	buffer = newCompletionTimeBuffer(3)
+	for i := 5; i > 0; i-- {
+		buffer.add(now.Add(-time.Duration(i) * time.Second))
+	}
+	// only the newest 3 are kept: 3, 2 and 1 second ago
+	assert.Equal(t, buffer.count(now.Add(-time.Minute), now), 3, "oldest entries should have been overwritten")
+	assert.Equal(t, buffer.count(now.Add(-2*time.Second), now), 1, "start of the window should be excluded")
+	assert.Equal(t, buffer.count(now.Add(-3*time.Second), now.Add(-2*time.Second)), 1, "end of the window should be included")
+}
+
+func TestGetApplicationThroughput(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	assert.Equal(t, partition.GetApplicationThroughput(500*time.Millisecond), -1.0, "window below a second should be rejected")
+	assert.Equal(t, partition.GetApplicationThroughput(objects.MaxAllocationRateWindow+time.Second), -1.0, "window above the maximum should be rejected")
+	assert.Equal(t, partition.GetApplicationThroughput(time.Minute), 0.0, "new partition should not have completions")
+
+	// 100 completions spread evenly over the last 50 seconds
+	now := time.Now()
+	for i := 0; i < 100; i++ {
+		partition.appCompletionTimes.add(now.Add(-time.Duration(i) * 500 * time.Millisecond))
+	}
+	assert.Equal(t, partition.getApplicationThroughput(now, time.Minute), 100.0/60, "unexpected throughput for the full window")
+	assert.Equal(t, partition.getApplicationThroughput(now, 10*time.Second), 2.0, "unexpected throughput for a partial window")
+	assert.Equal(t, partition.getApplicationThroughput(now, 10500*time.Millisecond), 2.0, "window should be truncated to whole seconds")
+	assert.Equal(t, partition.getApplicationThroughput(now.Add(time.Hour), time.Minute), 0.0, "old completions should not be counted")
+
+	// removing an application records the completion
+	partition, err = newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	for i := 0; i < 10; i++ {
+		appID := fmt.Sprintf("app-%d", i)
+		err = partition.AddApplication(newApplication(appID, "default", defQueue))
+		assert.NilError(t, err, "add application %s to partition should not have failed", appID)
+		partition.removeApplication(appID)
+	}
+	assert.Equal(t, partition.GetApplicationThroughput(5*time.Second), 2.0, "removed applications not counted")
+}
+
+func TestGetAllocationThroughput(t *testing.T) {
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	assert.Equal(t, partition.GetAllocationThroughput(500*time.Millisecond), -1.0, "window below a second should be rejected")
+	assert.Equal(t, partition.GetAllocationThroughput(objects.MaxAllocationRateWindow+time.Second), -1.0, "window above the maximum should be rejected")
+	assert.Equal(t, partition.GetAllocationThroughput(time.Minute), 0.0, "new partition should not have allocations")
+
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 100})
+	err = partition.AddNode(newNodeMaxResource(nodeID1, nodeRes), nil)
+	assert.NilError(t, err, "add node to partition should not have failed")
+	app := newApplication(appID1, "default", defQueue)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "failed to add app-1 to partition")
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"first": 1})
+	err = app.AddAllocationAsk(newAllocationAskRepeat("alloc-1", appID1, res, 100))
+	assert.NilError(t, err, "failed to add ask alloc-1 to app-1")
+	for i := 0; i < 100; i++ {
+		if alloc := partition.tryAllocate(); alloc == nil {
+			t.Fatalf("allocation %d did not return any allocation", i)
+		}
+	}
+	// all 100 allocations fall in a 2 second window
+	assert.Equal(t, partition.GetAllocationThroughput(2*time.Second), 50.0, "unexpected allocation throughput")
+}
@@ -0,0 +1,148 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"math"
+	"sort"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
+	"github.com/apache/incubator-yunikorn-core/pkg/metrics"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+)
+
+const wildcardUser = "*"
+
+// Set the user quotas from the max resources of the partition limits.
+// The first limit that defines max resources for a user is used, the wildcard user applies to users without a quota.
+// NOTE: this is a lock free call. It should only be called holding the PartitionContext lock or during create.
+func (pc *PartitionContext) setUserQuotas(limits []configs.Limit) {
+	pc.userQuotas = make(map[string]*resources.Resource)
+	for _, limit := range limits {
+		if len(limit.MaxResources) == 0 {
+			continue
+		}
+		quota, err := resources.NewResourceFromConf(limit.MaxResources)
+		if err != nil {
+			log.Logger().Warn("user quota ignored",
+				zap.String("partitionName", pc.Name),
+				zap.Strings("users", limit.Users),
+				zap.Error(err))
+			continue
+		}
+		for _, user := range limit.Users {
+			if _, ok := pc.userQuotas[user]; !ok {
+				pc.userQuotas[user] = quota
+			}
+		}
+	}
+}
+
+// Return the quota for the user, nil if the user has no quota.
+// Unlocked version must be called holding the partition lock.
+func (pc *PartitionContext) getUserQuota(user string) *resources.Resource {
+	if quota, ok := pc.userQuotas[user]; ok {
+		return quota
+	}
+	return pc.userQuotas[wildcardUser]
+}
+
+// Return the applications with an allocated resource that exceeds the quota of the application user.
+// Only the resource types defined in the quota are checked. The applications are sorted by ID.
+func (pc *PartitionContext) GetApplicationsExceedingQuota() []*objects.Application {
+	pc.RLock()
+	defer pc.RUnlock()
+	violating := make([]*objects.Application, 0)
+	for _, app := range pc.applications {
+		user := app.GetUser().User
+		quota := pc.getUserQuota(user)
+		if quota == nil {
+			continue
+		}
+		allocated := app.GetAllocatedResource()
+		if exceedsQuota(quota, allocated) {
+			log.Logger().Warn("application exceeds user quota",
+				zap.String("partitionName", pc.Name),
+				zap.String("applicationID", app.ApplicationID),
+				zap.String("user", user),
+				zap.String("quota", quota.String()),
+				zap.String("allocated", allocated.String()))
+			violating = append(violating, app)
+		}
+	}
+	sort.Slice(violating, func(i, j int) bool {
+		return violating[i].ApplicationID < violating[j].ApplicationID
+	})
+	metrics.GetSchedulerMetrics().SetQuotaViolations(pc.Name, len(violating))
+	return violating
+}
+
+// Return the resources allocated to all applications of the user as a fraction of the user quota per resource type.
+// Only the resource types defined in the quota are returned, a fraction above 1 means the user is over quota.
+// A quota of zero for a type that is allocated is reported as math.MaxFloat64.
+// If the user has no quota -1 is returned for each type in the partition or allocated to the user.
+func (pc *PartitionContext) GetResourceQuotaUtilization(user string) map[string]float64 {
+	pc.RLock()
+	defer pc.RUnlock()
+	allocated := resources.NewResource()
+	for _, app := range pc.applications {
+		if app.GetUser().User == user {
+			allocated.AddTo(app.GetAllocatedResource())
+		}
+	}
+	result := make(map[string]float64)
+	quota := pc.getUserQuota(user)
+	if quota == nil {
+		if pc.totalPartitionResource != nil {
+			for name := range pc.totalPartitionResource.Resources {
+				result[name] = -1
+			}
+		}
+		for name := range allocated.Resources {
+			result[name] = -1
+		}
+		return result
+	}
+	for name, limit := range quota.Resources {
+		used := allocated.Resources[name]
+		switch {
+		case limit > 0:
+			result[name] = float64(used) / float64(limit)
+		case used > 0:
+			result[name] = math.MaxFloat64
+		default:
+			result[name] = 0
+		}
+	}
+	return result
+}
+
+// Return true if any resource type defined in the quota is exceeded by the allocated resource.
+func exceedsQuota(quota, allocated *resources.Resource) bool {
+	for name, limit := range quota.Resources {
+		if allocated.Resources[name] > limit {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"math"
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
+	"github.com/apache/incubator-yunikorn-core/pkg/common/security"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+)
+
+func newQuotaPartition(t *testing.T, limits []configs.Limit) *PartitionContext {
+	conf := configs.PartitionConfig{
+		Name: "test",
+		Queues: []configs.QueueConfig{
+			{
+				Name:      "root",
+				Parent:    true,
+				SubmitACL: "*",
+				Queues: []configs.QueueConfig{
+					{
+						Name: "default",
+					},
+				},
+			},
+		},
+		Limits: limits,
+	}
+	partition, err := newPartitionContext(conf, rmID, nil)
+	assert.NilError(t, err, "partition create failed")
+	return partition
+}
+
+// add an app for the user with the allocated resource set directly on the app
+func addQuotaApp(t *testing.T, partition *PartitionContext, appID, user string, allocated map[string]resources.Quantity) {
+	app := objects.NewApplication(appID, "test", "root.default", security.UserGroup{User: user}, nil, nil, rmID)
+	err := partition.AddApplication(app)
+	assert.NilError(t, err, "app %s add failed", appID)
+	ask := newAllocationAsk("ask-"+appID, appID, resources.NewResourceFromMap(allocated))
+	app.AddAllocation(objects.NewAllocation("uuid-"+appID, nodeID1, ask))
+}
+
+func appIDs(apps []*objects.Application) []string {
+	ids := make([]string, len(apps))
+	for i, app := range apps {
+		ids[i] = app.ApplicationID
+	}
+	return ids
+}
+
+func TestGetApplicationsExceedingQuota(t *testing.T) {
+	partition := newQuotaPartition(t, nil)
+	addQuotaApp(t, partition, "app-none", "alice", map[string]resources.Quantity{"memory": 1000})
+	assert.Equal(t, len(partition.GetApplicationsExceedingQuota()), 0, "no quota defined: no violations expected")
+
+	partition = newQuotaPartition(t, []configs.Limit{
+		{Users: []string{"alice"}, MaxResources: map[string]string{"memory": "10"}},
+		{Users: []string{"bob"}, MaxResources: map[string]string{"vcore": "2"}},
+		{Users: []string{"alice"}, MaxResources: map[string]string{"memory": "1000"}},
+		{Users: []string{"*"}, MaxResources: map[string]string{"memory": "100"}},
+		{Users: []string{"dave"}, MaxApplications: 1},
+	})
+	// first limit for alice is used
+	addQuotaApp(t, partition, "app-alice-over", "alice", map[string]resources.Quantity{"memory": 20})
+	addQuotaApp(t, partition, "app-alice-under", "alice", map[string]resources.Quantity{"memory": 10})
+	// only the types in the quota are checked
+	addQuotaApp(t, partition, "app-bob-over", "bob", map[string]resources.Quantity{"memory": 1000, "vcore": 3})
+	addQuotaApp(t, partition, "app-bob-under", "bob", map[string]resources.Quantity{"memory": 1000, "vcore": 2})
+	// wildcard applies to users without a quota
+	addQuotaApp(t, partition, "app-carol-over", "carol", map[string]resources.Quantity{"memory": 101})
+	addQuotaApp(t, partition, "app-carol-under", "carol", map[string]resources.Quantity{"memory": 50})
+	// a limit without max resources does not override the wildcard
+	addQuotaApp(t, partition, "app-dave-over", "dave", map[string]resources.Quantity{"memory": 200})
+
+	assert.DeepEqual(t, appIDs(partition.GetApplicationsExceedingQuota()), []string{"app-alice-over", "app-bob-over", "app-carol-over", "app-dave-over"})
+
+	// removing the quota through a config update clears the violations
+	conf := configs.PartitionConfig{
+		Name: "test",
+		Queues: []configs.QueueConfig{
+			{
+				Name:      "root",
+				Parent:    true,
+				SubmitACL: "*",
+				Queues: []configs.QueueConfig{
+					{
+						Name: "default",
+					},
+				},
+			},
+		},
+		Limits: []configs.Limit{
+			{Users: []string{"alice"}, MaxResources: map[string]string{"memory": "15"}},
+		},
+	}
+	err := partition.updatePartitionDetails(conf)
+	assert.NilError(t, err, "partition update failed")
+	assert.DeepEqual(t, appIDs(partition.GetApplicationsExceedingQuota()), []string{"app-alice-over"})
+}
+
+func TestGetResourceQuotaUtilization(t *testing.T) {
+	// no quota: -1 for the allocated types and the types of the partition
+	partition := newQuotaPartition(t, nil)
+	assert.DeepEqual(t, partition.GetResourceQuotaUtilization("alice"), map[string]float64{})
+	err := partition.AddNode(newNodeMaxResource(nodeID1, resources.NewResourceFromMap(map[string]resources.Quantity{"memory": 1000, "vcore": 10})), nil)
+	assert.NilError(t, err, "node add failed")
+	addQuotaApp(t, partition, "app-none", "alice", map[string]resources.Quantity{"memory": 100, "gpu": 1})
+	assert.DeepEqual(t, partition.GetResourceQuotaUtilization("alice"), map[string]float64{"memory": -1, "vcore": -1, "gpu": -1})
+
+	partition = newQuotaPartition(t, []configs.Limit{
+		{Users: []string{"alice"}, MaxResources: map[string]string{"memory": "400", "vcore": "8", "gpu": "0"}},
+		{Users: []string{"*"}, MaxResources: map[string]string{"memory": "100"}},
+	})
+	// nothing allocated
+	assert.DeepEqual(t, partition.GetResourceQuotaUtilization("alice"), map[string]float64{"memory": 0, "vcore": 0, "gpu": 0})
+	// allocations of all applications of the user are combined
+	addQuotaApp(t, partition, "app-alice-1", "alice", map[string]resources.Quantity{"memory": 100, "vcore": 2})
+	addQuotaApp(t, partition, "app-alice-2", "alice", map[string]resources.Quantity{"memory": 200, "vcore": 4, "other": 5})
+	addQuotaApp(t, partition, "app-bob", "bob", map[string]resources.Quantity{"memory": 150})
+	assert.DeepEqual(t, partition.GetResourceQuotaUtilization("alice"), map[string]float64{"memory": 300.0 / 400.0, "vcore": 6.0 / 8.0, "gpu": 0})
+	// over quota, a zero quota that is used has no ratio
+	addQuotaApp(t, partition, "app-alice-3", "alice", map[string]resources.Quantity{"memory": 200, "gpu": 1})
+	assert.DeepEqual(t, partition.GetResourceQuotaUtilization("alice"), map[string]float64{"memory": 500.0 / 400.0, "vcore": 6.0 / 8.0, "gpu": math.MaxFloat64})
+	// wildcard quota applies to users without a quota
+	assert.DeepEqual(t, partition.GetResourceQuotaUtilization("bob"), map[string]float64{"memory": 150.0 / 100.0})
+	assert.DeepEqual(t, partition.GetResourceQuotaUtilization("carol"), map[string]float64{"memory": 0})
+}
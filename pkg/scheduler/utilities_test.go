@@ -140,6 +140,15 @@ func newNodeMaxResource(nodeID string, max *resources.Resource) *objects.Node {
 	return newNodeWithResources(nodeID, max, nil)
 }
 
+func newNodeWithAttributes(nodeID string, max *resources.Resource, attributes map[string]string) *objects.Node {
+	proto := &si.NewNodeInfo{
+		NodeID:              nodeID,
+		Attributes:          attributes,
+		SchedulableResource: max.ToProto(),
+	}
+	return objects.NewNode(proto)
+}
+
 // Simple node with just an ID in the node.
 // That is all we need for iteration
 func newNode(nodeID string) *objects.Node {
@@ -0,0 +1,105 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/incubator-yunikorn-core/pkg/log"
+)
+
+// Build information, overridden at link time using:
+// -ldflags "-X github.com/apache/incubator-yunikorn-core/pkg/scheduler.buildVersion=<version> ..."
+var (
+	buildVersion = "0.10.0"
+	gitCommit    = "unknown"
+	buildTime    = "unknown"
+)
+
+// Features supported by the scheduler, registered by the feature implementations.
+var (
+	features     = make(map[string]bool)
+	featuresLock sync.RWMutex
+)
+
+// The version of the scheduler and the features it supports.
+type SchedulerVersion struct {
+	Major             int
+	Minor             int
+	Patch             int
+	GitCommit         string
+	BuildTime         string
+	SupportedFeatures []string
+}
+
+// Register a feature as supported by the scheduler, registering the same feature again is a no-op.
+// Feature implementations should call this from their init function.
+func RegisterFeature(name string) {
+	featuresLock.Lock()
+	defer featuresLock.Unlock()
+	features[name] = true
+}
+
+// Return the version of the running scheduler with the registered features sorted by name.
+func GetSchedulerVersion() SchedulerVersion {
+	version := SchedulerVersion{
+		GitCommit: gitCommit,
+		BuildTime: buildTime,
+	}
+	version.Major, version.Minor, version.Patch = parseVersion(buildVersion)
+	featuresLock.RLock()
+	defer featuresLock.RUnlock()
+	version.SupportedFeatures = make([]string, 0, len(features))
+	for name := range features {
+		version.SupportedFeatures = append(version.SupportedFeatures, name)
+	}
+	sort.Strings(version.SupportedFeatures)
+	return version
+}
+
+// Return the version of the scheduler the partition runs in.
+func (pc *PartitionContext) GetSchedulerVersion() SchedulerVersion {
+	return GetSchedulerVersion()
+}
+
+// Split a semantic version into its numeric parts, a pre-release or build suffix is ignored.
+// Parts that are missing or not numeric are returned as 0.
+func parseVersion(version string) (major, minor, patch int) {
+	if idx := strings.IndexAny(version, "-+"); idx != -1 {
+		version = version[:idx]
+	}
+	parts := make([]int, 3)
+	for i, part := range strings.SplitN(strings.TrimPrefix(version, "v"), ".", 3) {
+		num, err := strconv.Atoi(part)
+		if err != nil {
+			log.Logger().Debug("version part is not numeric",
+				zap.String("version", version),
+				zap.String("part", part),
+				zap.Error(err))
+			continue
+		}
+		parts[i] = num
+	}
+	return parts[0], parts[1], parts[2]
+}
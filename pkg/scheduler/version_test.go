@@ -0,0 +1,87 @@
+/*
+ Licensed to the Apache Software Foundation (ASF) under one
+ or more contributor license agreements.  See the NOTICE file
+ distributed with this work for additional information
+ regarding copyright ownership.  The ASF licenses this file
+ to you under the Apache License, Version 2.0 (the
+ "License"); you may not use this file except in compliance
+ with the License.  You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		major   int
+		minor   int
+		patch   int
+	}{
+		{"0.10.0", 0, 10, 0},
+		{"v1.2.3", 1, 2, 3},
+		{"1.2.3-SNAPSHOT", 1, 2, 3},
+		{"1.2.3+build.5", 1, 2, 3},
+		{"1.2", 1, 2, 0},
+		{"", 0, 0, 0},
+		{"x.y.z", 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			major, minor, patch := parseVersion(tt.version)
+			assert.Equal(t, major, tt.major, "unexpected major version")
+			assert.Equal(t, minor, tt.minor, "unexpected minor version")
+			assert.Equal(t, patch, tt.patch, "unexpected patch version")
+		})
+	}
+}
+
+func TestGetSchedulerVersion(t *testing.T) {
+	version := GetSchedulerVersion()
+	assert.Assert(t, version.Major+version.Minor+version.Patch > 0, "version should be set: %v", version)
+	assert.Assert(t, version.GitCommit != "", "git commit should be set")
+	assert.Assert(t, version.BuildTime != "", "build time should be set")
+	// features registered by the implementations in this package
+	assert.Assert(t, hasFeature(version.SupportedFeatures, "topology-spread"), "topology spread not registered: %v", version.SupportedFeatures)
+	// preemption and gang reservations are not used by the scheduling cycle yet
+	assert.Assert(t, !hasFeature(version.SupportedFeatures, "preemption"), "preemption should not be registered: %v", version.SupportedFeatures)
+	assert.Assert(t, !hasFeature(version.SupportedFeatures, "gang-scheduling"), "gang scheduling should not be registered: %v", version.SupportedFeatures)
+
+	RegisterFeature("test-feature")
+	RegisterFeature("test-feature")
+	defer func() {
+		featuresLock.Lock()
+		delete(features, "test-feature")
+		featuresLock.Unlock()
+	}()
+	partition, err := newBasePartition()
+	assert.NilError(t, err, "partition create failed")
+	version = partition.GetSchedulerVersion()
+	assert.Equal(t, len(version.SupportedFeatures), len(features), "duplicate registration should be listed once")
+	assert.Assert(t, hasFeature(version.SupportedFeatures, "test-feature"), "registered feature not listed: %v", version.SupportedFeatures)
+	for i := 1; i < len(version.SupportedFeatures); i++ {
+		assert.Assert(t, version.SupportedFeatures[i-1] < version.SupportedFeatures[i], "features should be sorted: %v", version.SupportedFeatures)
+	}
+}
+
+func hasFeature(features []string, name string) bool {
+	for _, feature := range features {
+		if feature == name {
+			return true
+		}
+	}
+	return false
+}
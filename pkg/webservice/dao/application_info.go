@@ -23,13 +23,51 @@ type ApplicationsDAOInfo struct {
 }
 
 type ApplicationDAOInfo struct {
-	ApplicationID  string              `json:"applicationID"`
-	UsedResource   string              `json:"usedResource"`
-	Partition      string              `json:"partition"`
-	QueueName      string              `json:"queueName"`
-	SubmissionTime int64               `json:"submissionTime"`
-	Allocations    []AllocationDAOInfo `json:"allocations"`
-	State          string              `json:"applicationState"`
+	ApplicationID     string              `json:"applicationID"`
+	UsedResource      string              `json:"usedResource"`
+	Partition         string              `json:"partition"`
+	QueueName         string              `json:"queueName"`
+	SubmissionTime    int64               `json:"submissionTime"`
+	Age               int64               `json:"age"` // milliseconds
+	Allocations       []AllocationDAOInfo `json:"allocations"`
+	State             string              `json:"applicationState"`
+	MaxAllocationTime int64               `json:"maxAllocationTime"` // milliseconds
+	AvgAllocationTime int64               `json:"avgAllocationTime"` // milliseconds
+	RestartCount      int                 `json:"restartCount"`
+	TaskGroups        []TaskGroupDAOInfo  `json:"taskGroups,omitempty"`
+}
+
+type TaskGroupDAOInfo struct {
+	Name      string `json:"name"`
+	Total     int    `json:"total"`
+	Pending   int    `json:"pending"`
+	Running   int    `json:"running"`
+	Completed int    `json:"completed"`
+}
+
+type ApplicationETADAOInfo struct {
+	ApplicationID   string `json:"applicationID"`
+	Partition       string `json:"partition"`
+	PendingResource string `json:"pendingResource"`
+	ETA             int64  `json:"eta"` // milliseconds
+}
+
+type CompletedApplicationDAOInfo struct {
+	ApplicationID  string `json:"applicationID"`
+	User           string `json:"user"`
+	QueueName      string `json:"queueName"`
+	TotalAllocated string `json:"totalAllocated"`
+	StartTime      int64  `json:"startTime"`
+	EndTime        int64  `json:"endTime"`
+}
+
+type AllocationHistoryDAOInfo struct {
+	ApplicationID string `json:"applicationID"`
+	AllocationKey string `json:"allocationKey"`
+	NodeID        string `json:"nodeID"`
+	Resource      string `json:"resource"`
+	Timestamp     int64  `json:"timestamp"`
+	Action        string `json:"action"`
 }
 
 type AllocationDAOInfo struct {
@@ -42,4 +80,37 @@ type AllocationDAOInfo struct {
 	NodeID           string            `json:"nodeId"`
 	ApplicationID    string            `json:"applicationId"`
 	Partition        string            `json:"partition"`
+	CreationTime     int64             `json:"creationTime"`
+}
+
+type PreemptionCandidatesDAOInfo struct {
+	AllocationKey string              `json:"allocationKey"`
+	Candidates    []AllocationDAOInfo `json:"candidates"`
+}
+
+type SchedulingDiagnosticsDAOInfo struct {
+	ApplicationID string                   `json:"applicationId"`
+	AllocationKey string                   `json:"allocationKey"`
+	Nodes         []NodeDiagnosticsDAOInfo `json:"nodes"`
+}
+
+type NodeDiagnosticsDAOInfo struct {
+	NodeID  string   `json:"nodeId"`
+	Reasons []string `json:"reasons"`
+}
+
+type ApplicationEventDAOInfo struct {
+	Type        string `json:"type"`
+	Time        int64  `json:"time"`
+	Description string `json:"description"`
+	Resource    string `json:"resource,omitempty"`
+}
+
+type PlacementAuditDAOInfo struct {
+	ApplicationID string `json:"applicationID"`
+	Partition     string `json:"partition"`
+	RuleName      string `json:"ruleName"`
+	RuleParam     string `json:"ruleParam,omitempty"`
+	ResolvedQueue string `json:"resolvedQueue"`
+	Time          int64  `json:"time"`
 }
@@ -17,6 +17,15 @@
 */
 package dao
 
+type SchedulerVersionDAOInfo struct {
+	Major             int      `json:"major"`
+	Minor             int      `json:"minor"`
+	Patch             int      `json:"patch"`
+	GitCommit         string   `json:"gitCommit"`
+	BuildTime         string   `json:"buildTime"`
+	SupportedFeatures []string `json:"supportedFeatures"`
+}
+
 type ClusterDAOInfo struct {
 	ClusterName           string `json:"clusterName"`
 	TotalApplications     string `json:"totalApplications"`
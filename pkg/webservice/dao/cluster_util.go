@@ -24,8 +24,10 @@ type ClustersUtilDAOInfo struct {
 }
 
 type ClusterUtilDAOInfo struct {
-	ResourceType string `json:"type"`
-	Total        int64  `json:"total"`
-	Used         int64  `json:"used"`
-	Usage        string `json:"usage"`
+	ResourceType     string  `json:"type"`
+	Total            int64   `json:"total"`
+	Used             int64   `json:"used"`
+	Usage            string  `json:"usage"`
+	Reserved         int64   `json:"reserved"`
+	ReservedFraction float64 `json:"reservedCapacityFraction"`
 }
@@ -22,3 +22,15 @@ type ValidateConfResponse struct {
 	Allowed bool   `json:"allowed"`
 	Reason  string `json:"reason"`
 }
+
+type ConfigChecksumDAOInfo struct {
+	Checksum   string            `json:"checksum"`
+	Partitions map[string]string `json:"partitions"`
+}
+
+type QueueTreeDiffDAOInfo struct {
+	PartitionName  string   `json:"partitionName"`
+	AddedQueues    []string `json:"addedQueues"`
+	RemovedQueues  []string `json:"removedQueues"`
+	ModifiedQueues []string `json:"modifiedQueues"`
+}
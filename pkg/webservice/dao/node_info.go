@@ -24,13 +24,45 @@ type NodesDAOInfo struct {
 }
 
 type NodeDAOInfo struct {
-	NodeID      string               `json:"nodeID"`
-	HostName    string               `json:"hostName"`
-	RackName    string               `json:"rackName"`
-	Capacity    string               `json:"capacity"`
-	Allocated   string               `json:"allocated"`
-	Occupied    string               `json:"occupied"`
-	Available   string               `json:"available"`
-	Allocations []*AllocationDAOInfo `json:"allocations"`
-	Schedulable bool                 `json:"schedulable"`
+	NodeID         string               `json:"nodeID"`
+	HostName       string               `json:"hostName"`
+	RackName       string               `json:"rackName"`
+	Capacity       string               `json:"capacity"`
+	Allocated      string               `json:"allocated"`
+	Occupied       string               `json:"occupied"`
+	Available      string               `json:"available"`
+	Allocations    []*AllocationDAOInfo `json:"allocations"`
+	Schedulable    bool                 `json:"schedulable"`
+	TopologyLabels map[string]string    `json:"topologyLabels,omitempty"`
+}
+
+type NodeEventDAOInfo struct {
+	Type     string `json:"type"`
+	Time     int64  `json:"time"`
+	Resource string `json:"resource"`
+}
+
+type NodeCapacityBucketDAOInfo struct {
+	Count     int    `json:"count"`
+	Capacity  string `json:"capacity"`
+	Allocated string `json:"allocated"`
+}
+
+type NodeCapacityDistributionDAOInfo struct {
+	PartitionName string                                `json:"partitionName"`
+	Buckets       map[string]*NodeCapacityBucketDAOInfo `json:"buckets"`
+}
+
+type NodeUtilizationExtremesDAOInfo struct {
+	PartitionName  string  `json:"partitionName"`
+	MaxNodeID      string  `json:"maxNodeID"`
+	MaxUtilization float64 `json:"maxUtilization"`
+	MinNodeID      string  `json:"minNodeID"`
+	MinUtilization float64 `json:"minUtilization"`
+}
+
+type NodeGroupsDAOInfo struct {
+	PartitionName string              `json:"partitionName"`
+	GroupKey      string              `json:"groupKey"`
+	Groups        map[string][]string `json:"groups"`
 }
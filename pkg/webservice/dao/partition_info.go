@@ -18,18 +18,80 @@
 package dao
 
 type PartitionDAOInfo struct {
-	PartitionName string            `json:"partitionName"`
-	Capacity      PartitionCapacity `json:"capacity"`
-	Nodes         []NodeInfo        `json:"nodes"`
-	Queues        QueueDAOInfo      `json:"queues"`
+	PartitionName        string            `json:"partitionName"`
+	Capacity             PartitionCapacity `json:"capacity"`
+	Nodes                []NodeInfo        `json:"nodes"`
+	Queues               QueueDAOInfo      `json:"queues"`
+	SchedulingIntervalMs int64             `json:"schedulingIntervalMs"`
+	ConfigChecksum       string            `json:"configChecksum"`
 }
 
 type PartitionCapacity struct {
-	Capacity     string `json:"capacity"`
-	UsedCapacity string `json:"usedcapacity"`
+	Capacity         string `json:"capacity"`
+	UsedCapacity     string `json:"usedcapacity"`
+	ReservedCapacity string `json:"reservedCapacity"`
 }
 
 type NodeInfo struct {
 	NodeID     string `json:"nodeId"`
 	Capability string `json:"capability"`
 }
+
+type FairShareViolationDAOInfo struct {
+	ApplicationID        string  `json:"applicationID"`
+	QueueName            string  `json:"queueName"`
+	ExcessResource       string  `json:"excessResource"`
+	StarvedApplicationID string  `json:"starvedApplicationID"`
+	QueueFairShare       float64 `json:"queueFairShare"`
+}
+
+type PartitionPreemptionDAOInfo struct {
+	Enabled bool `json:"enabled"`
+}
+
+type QueueDepthDAOInfo struct {
+	Depth  int `json:"depth"`
+	Queues int `json:"queues"`
+	Leaf   int `json:"leafQueues"`
+	Parent int `json:"parentQueues"`
+}
+
+type ThroughputDAOInfo struct {
+	WindowSeconds int     `json:"windowSeconds"`
+	Applications  float64 `json:"applicationsPerSecond"`
+	Allocations   float64 `json:"allocationsPerSecond"`
+}
+
+type CompletedAllocationStatsDAOInfo struct {
+	PartitionName    string                                       `json:"partitionName"`
+	TotalAllocations int64                                        `json:"totalAllocations"`
+	ResourceSeconds  map[string]float64                           `json:"resourceSeconds"`
+	Queues           map[string]*QueueCompletedAllocationsDAOInfo `json:"queues"`
+}
+
+type QueueCompletedAllocationsDAOInfo struct {
+	Allocations     int64              `json:"allocations"`
+	ResourceSeconds map[string]float64 `json:"resourceSeconds"`
+}
+
+type UserQuotaUtilizationDAOInfo struct {
+	PartitionName string             `json:"partitionName"`
+	User          string             `json:"user"`
+	Utilization   map[string]float64 `json:"utilization"`
+}
+
+type SchedulingWaveDAOInfo struct {
+	WaveID           int    `json:"waveID"`
+	StartTime        int64  `json:"startTime"` // nanoseconds since the epoch
+	Duration         int64  `json:"duration"`  // milliseconds
+	AllocationsCount int    `json:"allocationsCount"`
+	TotalResource    string `json:"totalResource"`
+}
+
+type SchedulerBacklogDAOInfo struct {
+	TotalPendingAsks     int            `json:"totalPendingAsks"`
+	TotalPendingResource string         `json:"totalPendingResource"`
+	OldestAskAge         int64          `json:"oldestAskAge"` // milliseconds
+	AppCount             int            `json:"appCount"`
+	QueueBreakdown       map[string]int `json:"queueBreakdown"`
+}
@@ -18,11 +18,27 @@
 package dao
 
 type QueueDAOInfo struct {
-	QueueName   string            `json:"queuename"`
-	Status      string            `json:"status"`
-	Capacities  QueueCapacity     `json:"capacities"`
-	ChildQueues []QueueDAOInfo    `json:"queues"`
-	Properties  map[string]string `json:"properties"`
+	QueueName                 string            `json:"queuename"`
+	Status                    string            `json:"status"`
+	Capacities                QueueCapacity     `json:"capacities"`
+	ChildQueues               []QueueDAOInfo    `json:"queues"`
+	Properties                map[string]string `json:"properties"`
+	Load                      float64           `json:"load"`
+	MaxConcurrentApplications int               `json:"maxConcurrentApplications"`
+	ConcurrentApplications    int               `json:"concurrentApplications"`
+}
+
+// Filter for the queue hierarchy, criteria that are not set match all queues.
+type QueueFilter struct {
+	MinLoad *float64 `json:"minLoad,omitempty"`
+	MaxLoad *float64 `json:"maxLoad,omitempty"`
+	State   string   `json:"state,omitempty"`
+	HasApps bool     `json:"hasApps,omitempty"`
+}
+
+type QueuesByLabelDAOInfo struct {
+	PartitionName string   `json:"partitionName"`
+	Queues        []string `json:"queues"`
 }
 
 type QueueCapacity struct {
@@ -31,3 +47,14 @@ type QueueCapacity struct {
 	UsedCapacity    string `json:"usedcapacity"`
 	AbsUsedCapacity string `json:"absusedcapacity"`
 }
+
+type QueueResourceDAOInfo struct {
+	AllocatedResource string `json:"allocatedResource"`
+	PendingResource   string `json:"pendingResource"`
+}
+
+type QueueFairShareDAOInfo struct {
+	QueuePath string  `json:"queuePath"`
+	Weight    float64 `json:"weight"`
+	FairShare float64 `json:"fairShare"`
+}
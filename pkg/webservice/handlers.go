@@ -25,18 +25,23 @@ import (
 	"math"
 	"net/http"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 	"gopkg.in/yaml.v2"
 
+	"github.com/apache/incubator-yunikorn-core/pkg/common"
 	"github.com/apache/incubator-yunikorn-core/pkg/common/configs"
 	"github.com/apache/incubator-yunikorn-core/pkg/common/resources"
 	"github.com/apache/incubator-yunikorn-core/pkg/log"
 	"github.com/apache/incubator-yunikorn-core/pkg/plugins"
 	"github.com/apache/incubator-yunikorn-core/pkg/scheduler"
 	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/policies"
 	"github.com/apache/incubator-yunikorn-core/pkg/webservice/dao"
 	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
 )
@@ -62,6 +67,11 @@ func getStackInfo(w http.ResponseWriter, r *http.Request) {
 func getQueueInfo(w http.ResponseWriter, r *http.Request) {
 	writeHeaders(w)
 
+	query := r.URL.Query()
+	if query.Get("label") != "" || query.Get("value") != "" {
+		getQueuesByLabel(w, query.Get("label"), query.Get("value"))
+		return
+	}
 	lists := schedulerContext.GetPartitionMapClone()
 	for _, partition := range lists {
 		partitionInfo := getPartitionJSON(partition)
@@ -72,6 +82,54 @@ func getQueueInfo(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func getQueuesByLabel(w http.ResponseWriter, label, value string) {
+	if label == "" || value == "" {
+		http.Error(w, "both label and value must be set to search queues by label", http.StatusBadRequest)
+		return
+	}
+	var result []*dao.QueuesByLabelDAOInfo
+	for _, partition := range schedulerContext.GetPartitionMapClone() {
+		queues := make([]string, 0)
+		for _, queue := range partition.GetQueueByLabel(label, value) {
+			queues = append(queues, queue.QueuePath)
+		}
+		result = append(result, &dao.QueuesByLabelDAOInfo{
+			PartitionName: common.GetPartitionNameWithoutClusterID(partition.Name),
+			Queues:        queues,
+		})
+	}
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func getQueueInfoFiltered(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	requestBytes, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var filter dao.QueueFilter
+	if err = json.Unmarshal(requestBytes, &filter); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.MinLoad != nil && filter.MaxLoad != nil && *filter.MinLoad > *filter.MaxLoad {
+		http.Error(w, fmt.Sprintf("minimum load %f is larger than maximum load %f", *filter.MinLoad, *filter.MaxLoad), http.StatusBadRequest)
+		return
+	}
+	lists := schedulerContext.GetPartitionMapClone()
+	for _, partition := range lists {
+		partitionInfo := getPartitionJSONWithQueues(partition, partition.GetQueueInfosFiltered(filter))
+
+		if err = json.NewEncoder(w).Encode(partitionInfo); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
+
 func getClusterInfo(w http.ResponseWriter, r *http.Request) {
 	writeHeaders(w)
 
@@ -87,6 +145,23 @@ func getClusterInfo(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func getSchedulerVersion(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	version := scheduler.GetSchedulerVersion()
+	result := &dao.SchedulerVersionDAOInfo{
+		Major:             version.Major,
+		Minor:             version.Minor,
+		Patch:             version.Patch,
+		GitCommit:         version.GitCommit,
+		BuildTime:         version.BuildTime,
+		SupportedFeatures: version.SupportedFeatures,
+	}
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 func getClusterUtilization(w http.ResponseWriter, r *http.Request) {
 	writeHeaders(w)
 	var clusterUtil []*dao.ClustersUtilDAOInfo
@@ -115,13 +190,16 @@ func getApplicationsInfo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	user := r.URL.Query().Get("user")
+	group := r.URL.Query().Get("group")
+
 	var appsDao []*dao.ApplicationDAOInfo
 	lists := schedulerContext.GetPartitionMapClone()
 	for _, partition := range lists {
-		appList := partition.GetApplications()
+		appList := partition.GetApplicationsByUserGroup(user, group)
 		for _, app := range appList {
 			if len(queueName) == 0 || strings.EqualFold(queueName, app.GetQueueName()) {
-				appsDao = append(appsDao, getApplicationJSON(app))
+				appsDao = append(appsDao, getApplicationJSON(partition, app))
 			}
 		}
 	}
@@ -167,6 +245,837 @@ func getNodesInfo(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func getAllocationInfo(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	uuid := mux.Vars(r)["uuid"]
+	for _, partition := range schedulerContext.GetPartitionMapClone() {
+		if alloc := partition.GetAllocationByUUID(uuid); alloc != nil {
+			if err := json.NewEncoder(w).Encode(getAllocationJSON(alloc)); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+	}
+	http.Error(w, fmt.Sprintf("allocation %s not found", uuid), http.StatusNotFound)
+}
+
+func getPreemptionCandidates(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	appID := mux.Vars(r)["appID"]
+	for _, partition := range schedulerContext.GetPartitionMapClone() {
+		for _, app := range partition.GetApplications() {
+			if app.ApplicationID != appID {
+				continue
+			}
+			result := make([]*dao.PreemptionCandidatesDAOInfo, 0)
+			for _, ask := range app.GetPendingAsks() {
+				candidates := make([]dao.AllocationDAOInfo, 0)
+				for _, alloc := range partition.GetPreemptionCandidates(ask) {
+					candidates = append(candidates, *getAllocationJSON(alloc))
+				}
+				result = append(result, &dao.PreemptionCandidatesDAOInfo{
+					AllocationKey: ask.AllocationKey,
+					Candidates:    candidates,
+				})
+			}
+			sort.Slice(result, func(i, j int) bool {
+				return result[i].AllocationKey < result[j].AllocationKey
+			})
+			if err := json.NewEncoder(w).Encode(result); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+	}
+	http.Error(w, fmt.Sprintf("application %s not found", appID), http.StatusNotFound)
+}
+
+func getPartitionResources(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	name := mux.Vars(r)["name"]
+	for _, partition := range schedulerContext.GetPartitionMapClone() {
+		if partition.Name != name && common.GetPartitionNameWithoutClusterID(partition.Name) != name {
+			continue
+		}
+		result := make(map[string]*dao.QueueResourceDAOInfo)
+		for path, res := range partition.GetAllocatedResourceByQueue() {
+			result[path] = &dao.QueueResourceDAOInfo{AllocatedResource: res.DAOString()}
+		}
+		for path, res := range partition.GetPendingResourceByQueue() {
+			if info, ok := result[path]; ok {
+				info.PendingResource = res.DAOString()
+			}
+		}
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	http.Error(w, fmt.Sprintf("partition %s not found", name), http.StatusNotFound)
+}
+
+func getPartitionQueueLoad(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	name := mux.Vars(r)["name"]
+	for _, partition := range schedulerContext.GetPartitionMapClone() {
+		if partition.Name != name && common.GetPartitionNameWithoutClusterID(partition.Name) != name {
+			continue
+		}
+		// the encoder writes the map sorted on the queue path
+		if err := json.NewEncoder(w).Encode(partition.GetHierarchicalQueueLoad()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	http.Error(w, fmt.Sprintf("partition %s not found", name), http.StatusNotFound)
+}
+
+func getPartitionQueueDepths(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	name := mux.Vars(r)["name"]
+	for _, partition := range schedulerContext.GetPartitionMapClone() {
+		if partition.Name != name && common.GetPartitionNameWithoutClusterID(partition.Name) != name {
+			continue
+		}
+		total := partition.GetQueueDepthStats()
+		leaf := partition.GetLeafQueueCountByDepth()
+		parent := partition.GetParentQueueCountByDepth()
+		// depths are contiguous: a queue at depth n has a parent at depth n-1
+		result := make([]dao.QueueDepthDAOInfo, len(total))
+		for depth := range result {
+			result[depth] = dao.QueueDepthDAOInfo{
+				Depth:  depth,
+				Queues: total[depth],
+				Leaf:   leaf[depth],
+				Parent: parent[depth],
+			}
+		}
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	http.Error(w, fmt.Sprintf("partition %s not found", name), http.StatusNotFound)
+}
+
+func getPartitionFitMatrix(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	name := mux.Vars(r)["name"]
+	for _, partition := range schedulerContext.GetPartitionMapClone() {
+		if partition.Name != name && common.GetPartitionNameWithoutClusterID(partition.Name) != name {
+			continue
+		}
+		if err := json.NewEncoder(w).Encode(partition.GetNodeFitMatrix()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	http.Error(w, fmt.Sprintf("partition %s not found", name), http.StatusNotFound)
+}
+
+func getPartitionNodeGroups(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	name := mux.Vars(r)["name"]
+	for _, partition := range schedulerContext.GetPartitionMapClone() {
+		if partition.Name != name && common.GetPartitionNameWithoutClusterID(partition.Name) != name {
+			continue
+		}
+		result := &dao.NodeGroupsDAOInfo{
+			PartitionName: common.GetPartitionNameWithoutClusterID(partition.Name),
+			GroupKey:      partition.GetNodeGroupKey(),
+			Groups:        make(map[string][]string),
+		}
+		for group, nodes := range partition.GetNodeGroups() {
+			nodeIDs := make([]string, 0, len(nodes))
+			for _, node := range nodes {
+				nodeIDs = append(nodeIDs, node.NodeID)
+			}
+			sort.Strings(nodeIDs)
+			result.Groups[group] = nodeIDs
+		}
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	http.Error(w, fmt.Sprintf("partition %s not found", name), http.StatusNotFound)
+}
+
+func getPartitionNodeDistribution(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	name := mux.Vars(r)["name"]
+	for _, partition := range schedulerContext.GetPartitionMapClone() {
+		if partition.Name != name && common.GetPartitionNameWithoutClusterID(partition.Name) != name {
+			continue
+		}
+		result := &dao.NodeCapacityDistributionDAOInfo{
+			PartitionName: common.GetPartitionNameWithoutClusterID(partition.Name),
+			Buckets:       make(map[string]*dao.NodeCapacityBucketDAOInfo),
+		}
+		for size, bucket := range partition.GetNodeCapacityDistribution() {
+			result.Buckets[size] = &dao.NodeCapacityBucketDAOInfo{
+				Count:     bucket.Count,
+				Capacity:  bucket.Capacity.DAOString(),
+				Allocated: bucket.Allocated.DAOString(),
+			}
+		}
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	http.Error(w, fmt.Sprintf("partition %s not found", name), http.StatusNotFound)
+}
+
+func getPartitionNodeExtremes(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	name := mux.Vars(r)["name"]
+	for _, partition := range schedulerContext.GetPartitionMapClone() {
+		if partition.Name != name && common.GetPartitionNameWithoutClusterID(partition.Name) != name {
+			continue
+		}
+		result := &dao.NodeUtilizationExtremesDAOInfo{
+			PartitionName: common.GetPartitionNameWithoutClusterID(partition.Name),
+		}
+		result.MaxNodeID, result.MaxUtilization = partition.GetMaxNodeUtilization()
+		result.MinNodeID, result.MinUtilization = partition.GetMinNodeUtilization()
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	http.Error(w, fmt.Sprintf("partition %s not found", name), http.StatusNotFound)
+}
+
+func getPartitionReservationAges(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	name := mux.Vars(r)["name"]
+	for _, partition := range schedulerContext.GetPartitionMapClone() {
+		if partition.Name != name && common.GetPartitionNameWithoutClusterID(partition.Name) != name {
+			continue
+		}
+		if err := json.NewEncoder(w).Encode(partition.GetReservationAgeHistogram()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	http.Error(w, fmt.Sprintf("partition %s not found", name), http.StatusNotFound)
+}
+
+func getPartitionAllocationRates(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	name := mux.Vars(r)["name"]
+	windowSeconds := 60
+	if value := r.URL.Query().Get("windowSeconds"); value != "" {
+		var err error
+		if windowSeconds, err = strconv.Atoi(value); err != nil {
+			http.Error(w, fmt.Sprintf("invalid window %s", value), http.StatusBadRequest)
+			return
+		}
+	}
+	for _, partition := range schedulerContext.GetPartitionMapClone() {
+		if partition.Name != name && common.GetPartitionNameWithoutClusterID(partition.Name) != name {
+			continue
+		}
+		rates := partition.GetAllocationRateByQueue(time.Duration(windowSeconds) * time.Second)
+		if rates == nil {
+			http.Error(w, fmt.Sprintf("window must be between 1 and %d seconds", int(objects.MaxAllocationRateWindow/time.Second)), http.StatusBadRequest)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(rates); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	http.Error(w, fmt.Sprintf("partition %s not found", name), http.StatusNotFound)
+}
+
+func getPartitionThroughput(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	name := mux.Vars(r)["name"]
+	windowSeconds := 60
+	if value := r.URL.Query().Get("windowSeconds"); value != "" {
+		var err error
+		if windowSeconds, err = strconv.Atoi(value); err != nil {
+			http.Error(w, fmt.Sprintf("invalid window %s", value), http.StatusBadRequest)
+			return
+		}
+	}
+	for _, partition := range schedulerContext.GetPartitionMapClone() {
+		if partition.Name != name && common.GetPartitionNameWithoutClusterID(partition.Name) != name {
+			continue
+		}
+		window := time.Duration(windowSeconds) * time.Second
+		apps := partition.GetApplicationThroughput(window)
+		allocs := partition.GetAllocationThroughput(window)
+		if apps < 0 || allocs < 0 {
+			http.Error(w, fmt.Sprintf("window must be between 1 and %d seconds", int(objects.MaxAllocationRateWindow/time.Second)), http.StatusBadRequest)
+			return
+		}
+		throughput := dao.ThroughputDAOInfo{
+			WindowSeconds: windowSeconds,
+			Applications:  apps,
+			Allocations:   allocs,
+		}
+		if err := json.NewEncoder(w).Encode(throughput); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	http.Error(w, fmt.Sprintf("partition %s not found", name), http.StatusNotFound)
+}
+
+func getPartitionBacklog(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	name := mux.Vars(r)["name"]
+	for _, partition := range schedulerContext.GetPartitionMapClone() {
+		if partition.Name != name && common.GetPartitionNameWithoutClusterID(partition.Name) != name {
+			continue
+		}
+		backlog := partition.GetSchedulerBacklog()
+		backlogInfo := dao.SchedulerBacklogDAOInfo{
+			TotalPendingAsks:     backlog.TotalPendingAsks,
+			TotalPendingResource: backlog.TotalPendingResource.DAOString(),
+			OldestAskAge:         backlog.OldestAskAge.Milliseconds(),
+			AppCount:             backlog.AppCount,
+			QueueBreakdown:       backlog.QueueBreakdown,
+		}
+		if err := json.NewEncoder(w).Encode(backlogInfo); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	http.Error(w, fmt.Sprintf("partition %s not found", name), http.StatusNotFound)
+}
+
+func getPartitionCompletedAllocationStats(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	name := mux.Vars(r)["name"]
+	for _, partition := range schedulerContext.GetPartitionMapClone() {
+		if partition.Name != name && common.GetPartitionNameWithoutClusterID(partition.Name) != name {
+			continue
+		}
+		stats := partition.GetCompletedAllocationStats()
+		result := dao.CompletedAllocationStatsDAOInfo{
+			PartitionName:    common.GetPartitionNameWithoutClusterID(partition.Name),
+			TotalAllocations: stats.TotalAllocations,
+			ResourceSeconds:  stats.ResourceSeconds,
+			Queues:           make(map[string]*dao.QueueCompletedAllocationsDAOInfo, len(stats.QueueBreakdown)),
+		}
+		for queuePath, queueStats := range stats.QueueBreakdown {
+			result.Queues[queuePath] = &dao.QueueCompletedAllocationsDAOInfo{
+				Allocations:     queueStats.Allocations,
+				ResourceSeconds: queueStats.ResourceSeconds,
+			}
+		}
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	http.Error(w, fmt.Sprintf("partition %s not found", name), http.StatusNotFound)
+}
+
+func getPartitionSchedulingWaves(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	name := mux.Vars(r)["name"]
+	for _, partition := range schedulerContext.GetPartitionMapClone() {
+		if partition.Name != name && common.GetPartitionNameWithoutClusterID(partition.Name) != name {
+			continue
+		}
+		waves := partition.GetSchedulingWaves()
+		result := make([]*dao.SchedulingWaveDAOInfo, 0, len(waves))
+		for _, wave := range waves {
+			result = append(result, &dao.SchedulingWaveDAOInfo{
+				WaveID:           wave.WaveID,
+				StartTime:        wave.StartTime.UnixNano(),
+				Duration:         wave.Duration.Milliseconds(),
+				AllocationsCount: wave.AllocationsCount,
+				TotalResource:    wave.TotalResource.DAOString(),
+			})
+		}
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	http.Error(w, fmt.Sprintf("partition %s not found", name), http.StatusNotFound)
+}
+
+func getPartitionFairShareViolations(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	name := mux.Vars(r)["name"]
+	for _, partition := range schedulerContext.GetPartitionMapClone() {
+		if partition.Name != name && common.GetPartitionNameWithoutClusterID(partition.Name) != name {
+			continue
+		}
+		violations := partition.GetFairShareViolations()
+		result := make([]*dao.FairShareViolationDAOInfo, 0, len(violations))
+		for _, violation := range violations {
+			result = append(result, &dao.FairShareViolationDAOInfo{
+				ApplicationID:        violation.ApplicationID,
+				QueueName:            violation.QueueName,
+				ExcessResource:       violation.Excess.DAOString(),
+				StarvedApplicationID: violation.StarvedApplicationID,
+				QueueFairShare:       violation.QueueFairShare,
+			})
+		}
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	http.Error(w, fmt.Sprintf("partition %s not found", name), http.StatusNotFound)
+}
+
+func updatePartitionPreemption(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	name := mux.Vars(r)["name"]
+	requestBytes, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var preemption dao.PartitionPreemptionDAOInfo
+	if err = json.Unmarshal(requestBytes, &preemption); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, partition := range schedulerContext.GetPartitionMapClone() {
+		if partition.Name != name && common.GetPartitionNameWithoutClusterID(partition.Name) != name {
+			continue
+		}
+		partition.SetIsPreemptable(preemption.Enabled)
+		result := &dao.PartitionPreemptionDAOInfo{Enabled: partition.GetIsPreemptable()}
+		if err = json.NewEncoder(w).Encode(result); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	http.Error(w, fmt.Sprintf("partition %s not found", name), http.StatusNotFound)
+}
+
+func updateQueueMaxResource(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	path := mux.Vars(r)["path"]
+	requestBytes, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var resMap map[string]int64
+	if err = json.Unmarshal(requestBytes, &resMap); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var max *resources.Resource
+	if max, err = resources.NewValidResourceFromMap(resMap); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, partition := range schedulerContext.GetPartitionMapClone() {
+		queue := partition.GetQueue(path)
+		if queue == nil {
+			continue
+		}
+		if err = partition.UpdateQueueMaxResource(path, max); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err = json.NewEncoder(w).Encode(queue.GetQueueInfos()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	http.Error(w, fmt.Sprintf("queue %s not found", path), http.StatusNotFound)
+}
+
+func updateQueueAppSortPolicy(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	path := mux.Vars(r)["path"]
+	requestBytes, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var sortMap map[string]string
+	if err = json.Unmarshal(requestBytes, &sortMap); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// an empty policy would silently fall back to the default
+	if sortMap["policy"] == "" {
+		http.Error(w, "application sort policy must be set", http.StatusBadRequest)
+		return
+	}
+	var policy policies.SortPolicy
+	if policy, err = policies.SortPolicyFromString(sortMap["policy"]); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, partition := range schedulerContext.GetPartitionMapClone() {
+		queue := partition.GetQueue(path)
+		if queue == nil {
+			continue
+		}
+		if err = queue.SetApplicationSortingPolicy(policy); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err = json.NewEncoder(w).Encode(queue.GetQueueInfos()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	http.Error(w, fmt.Sprintf("queue %s not found", path), http.StatusNotFound)
+}
+
+func updateQueueProperties(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	path := mux.Vars(r)["path"]
+	requestBytes, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var props map[string]string
+	if err = json.Unmarshal(requestBytes, &props); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, partition := range schedulerContext.GetPartitionMapClone() {
+		queue := partition.GetQueue(path)
+		if queue == nil {
+			continue
+		}
+		if err = partition.UpdateQueueProperties(path, props); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err = json.NewEncoder(w).Encode(queue.GetQueueInfos()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	http.Error(w, fmt.Sprintf("queue %s not found", path), http.StatusNotFound)
+}
+
+func drainQueue(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	path := mux.Vars(r)["path"]
+	for _, partition := range schedulerContext.GetPartitionMapClone() {
+		queue := partition.GetQueue(path)
+		if queue == nil {
+			continue
+		}
+		if err := partition.DrainQueue(path); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(queue.GetQueueInfos()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	http.Error(w, fmt.Sprintf("queue %s not found", path), http.StatusNotFound)
+}
+
+func undrainQueue(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	path := mux.Vars(r)["path"]
+	for _, partition := range schedulerContext.GetPartitionMapClone() {
+		queue := partition.GetQueue(path)
+		if queue == nil {
+			continue
+		}
+		if err := partition.UndrainQueue(path); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(queue.GetQueueInfos()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	http.Error(w, fmt.Sprintf("queue %s not found", path), http.StatusNotFound)
+}
+
+func getQueueCompletedApplications(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	path := mux.Vars(r)["path"]
+	limit := 0
+	if value := r.URL.Query().Get("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil || limit < 0 {
+			http.Error(w, fmt.Sprintf("invalid limit %s", value), http.StatusBadRequest)
+			return
+		}
+	}
+	for _, partition := range schedulerContext.GetPartitionMapClone() {
+		// the history is kept after a queue is removed
+		completed := partition.GetQueueCompletedApplications(path, limit)
+		if len(completed) == 0 && partition.GetQueue(path) == nil {
+			continue
+		}
+		result := make([]*dao.CompletedApplicationDAOInfo, 0, len(completed))
+		for _, app := range completed {
+			result = append(result, &dao.CompletedApplicationDAOInfo{
+				ApplicationID:  app.ApplicationID,
+				User:           app.User,
+				QueueName:      app.QueueName,
+				TotalAllocated: app.TotalAllocated.DAOString(),
+				StartTime:      app.StartTime.Unix(),
+				EndTime:        app.EndTime.Unix(),
+			})
+		}
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	http.Error(w, fmt.Sprintf("queue %s not found", path), http.StatusNotFound)
+}
+
+func getQueueAllocationHistory(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	path := mux.Vars(r)["path"]
+	var since time.Time
+	if value := r.URL.Query().Get("since"); value != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339, value); err != nil {
+			http.Error(w, fmt.Sprintf("invalid since %s", value), http.StatusBadRequest)
+			return
+		}
+	}
+	for _, partition := range schedulerContext.GetPartitionMapClone() {
+		// the history is kept after a queue is removed
+		history := partition.GetQueueAllocationHistory(path, since)
+		if history == nil && partition.GetQueue(path) == nil {
+			continue
+		}
+		result := make([]*dao.AllocationHistoryDAOInfo, 0, len(history))
+		for _, entry := range history {
+			result = append(result, &dao.AllocationHistoryDAOInfo{
+				ApplicationID: entry.AppID,
+				AllocationKey: entry.AllocationKey,
+				NodeID:        entry.NodeID,
+				Resource:      entry.Resource.DAOString(),
+				Timestamp:     entry.Timestamp.UnixNano(),
+				Action:        entry.Action,
+			})
+		}
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	http.Error(w, fmt.Sprintf("queue %s not found", path), http.StatusNotFound)
+}
+
+func getQueueFairShare(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	path := mux.Vars(r)["path"]
+	for _, partition := range schedulerContext.GetPartitionMapClone() {
+		queue := partition.GetQueue(path)
+		if queue == nil {
+			continue
+		}
+		result := &dao.QueueFairShareDAOInfo{
+			QueuePath: queue.GetQueuePath(),
+			Weight:    queue.GetWeight(),
+			FairShare: partition.GetQueueSiblingFairShare(path),
+		}
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	http.Error(w, fmt.Sprintf("queue %s not found", path), http.StatusNotFound)
+}
+
+func getSchedulingDiagnostics(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	appID := mux.Vars(r)["appID"]
+	for _, partition := range schedulerContext.GetPartitionMapClone() {
+		for _, app := range partition.GetApplications() {
+			if app.ApplicationID != appID {
+				continue
+			}
+			diagnostics := partition.GetSchedulingDiagnostics(appID)
+			result := &dao.SchedulingDiagnosticsDAOInfo{
+				ApplicationID: diagnostics.ApplicationID,
+				AllocationKey: diagnostics.AllocationKey,
+				Nodes:         make([]dao.NodeDiagnosticsDAOInfo, 0, len(diagnostics.Nodes)),
+			}
+			for nodeID, reasons := range diagnostics.Nodes {
+				nodeInfo := dao.NodeDiagnosticsDAOInfo{
+					NodeID:  nodeID,
+					Reasons: make([]string, 0, len(reasons)),
+				}
+				for _, reason := range reasons {
+					nodeInfo.Reasons = append(nodeInfo.Reasons, string(reason))
+				}
+				result.Nodes = append(result.Nodes, nodeInfo)
+			}
+			sort.Slice(result.Nodes, func(i, j int) bool {
+				return result.Nodes[i].NodeID < result.Nodes[j].NodeID
+			})
+			if err := json.NewEncoder(w).Encode(result); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+	}
+	http.Error(w, fmt.Sprintf("application %s not found", appID), http.StatusNotFound)
+}
+
+func getNodeEvents(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	nodeID := mux.Vars(r)["nodeID"]
+	var result []*dao.NodeEventDAOInfo
+	for _, partition := range schedulerContext.GetPartitionMapClone() {
+		for _, event := range partition.GetNodeEventHistory(nodeID) {
+			result = append(result, &dao.NodeEventDAOInfo{
+				Type:     string(event.Type),
+				Time:     event.Time.UnixNano(),
+				Resource: event.Resource.DAOString(),
+			})
+		}
+	}
+	if result == nil {
+		http.Error(w, fmt.Sprintf("no events found for node %s", nodeID), http.StatusNotFound)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func getUserQuotaUtilization(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	user := mux.Vars(r)["user"]
+	result := make([]*dao.UserQuotaUtilizationDAOInfo, 0)
+	for _, partition := range schedulerContext.GetPartitionMapClone() {
+		result = append(result, &dao.UserQuotaUtilizationDAOInfo{
+			PartitionName: common.GetPartitionNameWithoutClusterID(partition.Name),
+			User:          user,
+			Utilization:   partition.GetResourceQuotaUtilization(user),
+		})
+	}
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func getApplicationEvents(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	appID := mux.Vars(r)["appID"]
+	var result []*dao.ApplicationEventDAOInfo
+	for _, partition := range schedulerContext.GetPartitionMapClone() {
+		for _, event := range partition.GetApplicationEventLog(appID) {
+			info := &dao.ApplicationEventDAOInfo{
+				Type:        string(event.Type),
+				Time:        event.Time.UnixNano(),
+				Description: event.Description,
+			}
+			if event.Resource != nil {
+				info.Resource = event.Resource.DAOString()
+			}
+			result = append(result, info)
+		}
+	}
+	if result == nil {
+		http.Error(w, fmt.Sprintf("no events found for application %s", appID), http.StatusNotFound)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func getApplicationPlacement(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	appID := mux.Vars(r)["appID"]
+	for _, partition := range schedulerContext.GetPartitionMapClone() {
+		audit := partition.GetApplicationPlacementAudit(appID)
+		if audit.RuleName == "" {
+			continue
+		}
+		result := &dao.PlacementAuditDAOInfo{
+			ApplicationID: appID,
+			Partition:     common.GetPartitionNameWithoutClusterID(partition.Name),
+			RuleName:      audit.RuleName,
+			RuleParam:     audit.RuleParam,
+			ResolvedQueue: audit.ResolvedQueue,
+			Time:          audit.Time.UnixNano(),
+		}
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	http.Error(w, fmt.Sprintf("no placement found for application %s", appID), http.StatusNotFound)
+}
+
+func getApplicationETA(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	appID := mux.Vars(r)["appID"]
+	for _, partition := range schedulerContext.GetPartitionMapClone() {
+		for _, app := range partition.GetApplications() {
+			if app.ApplicationID != appID {
+				continue
+			}
+			eta, err := partition.GetApplicationCompletionPrediction(appID)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			result := &dao.ApplicationETADAOInfo{
+				ApplicationID:   appID,
+				Partition:       common.GetPartitionNameWithoutClusterID(partition.Name),
+				PendingResource: app.GetPendingResource().DAOString(),
+				ETA:             eta.Milliseconds(),
+			}
+			if err = json.NewEncoder(w).Encode(result); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+	}
+	http.Error(w, fmt.Sprintf("application %s not found", appID), http.StatusNotFound)
+}
+
 func getNodesUtilization(w http.ResponseWriter, r *http.Request) {
 	writeHeaders(w)
 
@@ -236,12 +1145,16 @@ func getClusterUtilJSON(partition *scheduler.PartitionContext) []*dao.ClusterUti
 	}
 	if getResource {
 		percent := resources.CalculateAbsUsedCapacity(total, used)
+		reserved := partition.GetReservedCapacity()
+		fractions := partition.GetReservedCapacityFraction()
 		for name, value := range percent.Resources {
 			utilization := &dao.ClusterUtilDAOInfo{
-				ResourceType: name,
-				Total:        int64(total.Resources[name]),
-				Used:         int64(used.Resources[name]),
-				Usage:        fmt.Sprintf("%d", int64(value)) + "%",
+				ResourceType:     name,
+				Total:            int64(total.Resources[name]),
+				Used:             int64(used.Resources[name]),
+				Usage:            fmt.Sprintf("%d", int64(value)) + "%",
+				Reserved:         int64(reserved.Resources[name]),
+				ReservedFraction: fractions[name],
 			}
 			utils = append(utils, utilization)
 		}
@@ -258,76 +1171,93 @@ func getClusterUtilJSON(partition *scheduler.PartitionContext) []*dao.ClusterUti
 }
 
 func getPartitionJSON(partition *scheduler.PartitionContext) *dao.PartitionDAOInfo {
-	partitionInfo := &dao.PartitionDAOInfo{}
+	return getPartitionJSONWithQueues(partition, partition.GetQueueInfos())
+}
 
-	queueDAOInfo := partition.GetQueueInfos()
+func getPartitionJSONWithQueues(partition *scheduler.PartitionContext, queueDAOInfo dao.QueueDAOInfo) *dao.PartitionDAOInfo {
+	partitionInfo := &dao.PartitionDAOInfo{}
 
 	partitionInfo.PartitionName = partition.Name
 	partitionInfo.Capacity = dao.PartitionCapacity{
-		Capacity:     partition.GetTotalPartitionResource().DAOString(),
-		UsedCapacity: "0",
+		Capacity:         partition.GetTotalPartitionResource().DAOString(),
+		UsedCapacity:     "0",
+		ReservedCapacity: partition.GetReservedCapacity().DAOString(),
 	}
 	partitionInfo.Queues = queueDAOInfo
+	partitionInfo.SchedulingIntervalMs = partition.GetSchedulingInterval().Milliseconds()
+	partitionInfo.ConfigChecksum = partition.GetConfigChecksum()
 
 	return partitionInfo
 }
 
-func getApplicationJSON(app *objects.Application) *dao.ApplicationDAOInfo {
+func getApplicationJSON(partition *scheduler.PartitionContext, app *objects.Application) *dao.ApplicationDAOInfo {
 	var allocationInfos []dao.AllocationDAOInfo
 	allocations := app.GetAllAllocations()
 	for _, alloc := range allocations {
-		allocInfo := dao.AllocationDAOInfo{
-			AllocationKey:    alloc.AllocationKey,
-			AllocationTags:   alloc.Tags,
-			UUID:             alloc.UUID,
-			ResourcePerAlloc: alloc.AllocatedResource.DAOString(),
-			Priority:         strconv.Itoa(int(alloc.Priority)),
-			QueueName:        alloc.QueueName,
-			NodeID:           alloc.NodeID,
-			ApplicationID:    alloc.ApplicationID,
-			Partition:        alloc.PartitionName,
-		}
-		allocationInfos = append(allocationInfos, allocInfo)
+		allocationInfos = append(allocationInfos, *getAllocationJSON(alloc))
 	}
+	var taskGroupInfos []dao.TaskGroupDAOInfo
+	for _, group := range app.GetTaskGroupStatus() {
+		taskGroupInfos = append(taskGroupInfos, dao.TaskGroupDAOInfo{
+			Name:      group.Name,
+			Total:     group.Total,
+			Pending:   group.Pending,
+			Running:   group.Running,
+			Completed: group.Completed,
+		})
+	}
+	sort.Slice(taskGroupInfos, func(i, j int) bool {
+		return taskGroupInfos[i].Name < taskGroupInfos[j].Name
+	})
 
 	return &dao.ApplicationDAOInfo{
-		ApplicationID:  app.ApplicationID,
-		UsedResource:   app.GetAllocatedResource().DAOString(),
-		Partition:      app.Partition,
-		QueueName:      app.QueueName,
-		SubmissionTime: app.SubmissionTime.Unix(),
-		Allocations:    allocationInfos,
-		State:          app.CurrentState(),
+		ApplicationID:     app.ApplicationID,
+		UsedResource:      app.GetAllocatedResource().DAOString(),
+		Partition:         app.Partition,
+		QueueName:         app.QueueName,
+		SubmissionTime:    app.GetCreateTime().Unix(),
+		Age:               time.Since(app.GetCreateTime()).Milliseconds(),
+		Allocations:       allocationInfos,
+		State:             app.CurrentState(),
+		MaxAllocationTime: app.GetMaxAllocationTime().Milliseconds(),
+		AvgAllocationTime: app.GetAvgAllocationTime().Milliseconds(),
+		RestartCount:      partition.GetApplicationRestartCount(app.ApplicationID),
+		TaskGroups:        taskGroupInfos,
+	}
+}
+
+func getAllocationJSON(alloc *objects.Allocation) *dao.AllocationDAOInfo {
+	return &dao.AllocationDAOInfo{
+		AllocationKey:    alloc.AllocationKey,
+		AllocationTags:   alloc.Tags,
+		UUID:             alloc.UUID,
+		ResourcePerAlloc: alloc.AllocatedResource.DAOString(),
+		Priority:         strconv.Itoa(int(alloc.Priority)),
+		QueueName:        alloc.QueueName,
+		NodeID:           alloc.NodeID,
+		ApplicationID:    alloc.ApplicationID,
+		Partition:        alloc.PartitionName,
+		CreationTime:     alloc.GetCreateTime().UnixNano(),
 	}
 }
 
 func getNodeJSON(node *objects.Node) *dao.NodeDAOInfo {
 	var allocations []*dao.AllocationDAOInfo
 	for _, alloc := range node.GetAllAllocations() {
-		allocInfo := &dao.AllocationDAOInfo{
-			AllocationKey:    alloc.AllocationKey,
-			AllocationTags:   alloc.Tags,
-			UUID:             alloc.UUID,
-			ResourcePerAlloc: alloc.AllocatedResource.DAOString(),
-			Priority:         strconv.Itoa(int(alloc.Priority)),
-			QueueName:        alloc.QueueName,
-			NodeID:           alloc.NodeID,
-			ApplicationID:    alloc.ApplicationID,
-			Partition:        alloc.PartitionName,
-		}
-		allocations = append(allocations, allocInfo)
+		allocations = append(allocations, getAllocationJSON(alloc))
 	}
 
 	return &dao.NodeDAOInfo{
-		NodeID:      node.NodeID,
-		HostName:    node.Hostname,
-		RackName:    node.Rackname,
-		Capacity:    node.GetCapacity().DAOString(),
-		Occupied:    node.GetOccupiedResource().DAOString(),
-		Allocated:   node.GetAllocatedResource().DAOString(),
-		Available:   node.GetAvailableResource().DAOString(),
-		Allocations: allocations,
-		Schedulable: node.IsSchedulable(),
+		NodeID:         node.NodeID,
+		HostName:       node.GetHostname(),
+		RackName:       node.GetRackname(),
+		Capacity:       node.GetCapacity().DAOString(),
+		Occupied:       node.GetOccupiedResource().DAOString(),
+		Allocated:      node.GetAllocatedResource().DAOString(),
+		Available:      node.GetAvailableResource().DAOString(),
+		Allocations:    allocations,
+		Schedulable:    node.IsSchedulable(),
+		TopologyLabels: node.GetTopologyLabels(),
 	}
 }
 
@@ -457,6 +1387,98 @@ func getClusterConfig(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func getConfigChecksum(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	conf := configs.ConfigContext.Get(schedulerContext.GetPolicyGroup())
+	result := &dao.ConfigChecksumDAOInfo{
+		Partitions: make(map[string]string),
+	}
+	if conf != nil {
+		result.Checksum = fmt.Sprintf("%X", conf.Checksum)
+	}
+	for _, partition := range schedulerContext.GetPartitionMapClone() {
+		result.Partitions[common.GetPartitionNameWithoutClusterID(partition.Name)] = partition.GetConfigChecksum()
+	}
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func getExportedConfig(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	conf := &configs.SchedulerConfig{}
+	for _, partition := range schedulerContext.GetPartitionMapClone() {
+		conf.Partitions = append(conf.Partitions, partition.ExportConfig())
+	}
+	var marshalledConf []byte
+	var err error
+	// check if we have a request for json output
+	if r.Header.Get("Accept") == "application/json" {
+		marshalledConf, err = json.Marshal(conf)
+	} else {
+		w.Header().Set("Content-Type", "application/x-yaml; charset=UTF-8")
+		marshalledConf, err = yaml.Marshal(conf)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err = w.Write(marshalledConf); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func getConfigSchema(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+
+	schema, err := configs.GenerateJSONSchema()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err = w.Write(schema); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func getConfigDiff(w http.ResponseWriter, r *http.Request) {
+	writeHeaders(w)
+	requestBytes, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var newConf *configs.SchedulerConfig
+	newConf, err = configs.LoadSchedulerConfigFromByteArray(requestBytes)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var diffs map[string]scheduler.QueueTreeDiff
+	diffs, err = schedulerContext.GetQueueTreeDiff(newConf)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	result := make([]*dao.QueueTreeDiffDAOInfo, 0, len(diffs))
+	for name, diff := range diffs {
+		result = append(result, &dao.QueueTreeDiffDAOInfo{
+			PartitionName:  name,
+			AddedQueues:    diff.AddedQueues,
+			RemovedQueues:  diff.RemovedQueues,
+			ModifiedQueues: diff.ModifiedQueues,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].PartitionName < result[j].PartitionName
+	})
+	if err = json.NewEncoder(w).Encode(result); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 func updateConfig(w http.ResponseWriter, r *http.Request) {
 	lock.Lock()
 	defer lock.Unlock()
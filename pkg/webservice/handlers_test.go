@@ -26,6 +26,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/gorilla/mux"
 	"gopkg.in/yaml.v2"
 	"gotest.tools/assert"
 
@@ -36,6 +37,7 @@ import (
 	"github.com/apache/incubator-yunikorn-core/pkg/plugins"
 	"github.com/apache/incubator-yunikorn-core/pkg/scheduler"
 	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/objects"
+	"github.com/apache/incubator-yunikorn-core/pkg/scheduler/policies"
 	"github.com/apache/incubator-yunikorn-core/pkg/webservice/dao"
 	"github.com/apache/incubator-yunikorn-scheduler-interface/lib/go/si"
 )
@@ -304,6 +306,55 @@ func TestGetConfigJSON(t *testing.T) {
 	assert.Equal(t, conf.Partitions[0].NodeSortPolicy.Type, "binpacking", "node sort policy not updated (json)")
 }
 
+func TestGetExportedConfig(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(startConf))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load clusterInfo from config")
+	// No err check: new request always returns correctly
+	//nolint: errcheck
+	req, _ := http.NewRequest("GET", "", nil)
+	resp := &MockResponseWriter{}
+	getExportedConfig(resp, req)
+	conf := &configs.SchedulerConfig{}
+	err = yaml.Unmarshal(resp.outputBytes, conf)
+	assert.NilError(t, err, "failed to unmarshal exported config from response body")
+	assert.Equal(t, len(conf.Partitions), 1, "expected one partition to be exported")
+	assert.Equal(t, conf.Partitions[0].Name, "default", "partition name exported incorrectly")
+	assert.Equal(t, conf.Partitions[0].NodeSortPolicy.Type, "fair", "node sort policy exported incorrectly")
+	assert.Equal(t, conf.Partitions[0].Queues[0].Properties["first"], "some value with spaces", "root properties not exported")
+
+	// json output
+	req.Header.Set("Accept", "application/json")
+	getExportedConfig(resp, req)
+	conf = &configs.SchedulerConfig{}
+	err = json.Unmarshal(resp.outputBytes, conf)
+	assert.NilError(t, err, "failed to unmarshal exported config from response body (json)")
+	assert.Equal(t, conf.Partitions[0].Queues[0].Name, "root", "root queue exported incorrectly (json)")
+}
+
+func TestGetConfigChecksum(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load clusterInfo from config")
+	partition := schedulerContext.GetPartition("[" + rmID + "]default")
+	assert.Assert(t, partition != nil, "partition not found")
+
+	// No err check: new request always returns correctly
+	//nolint: errcheck
+	req, _ := http.NewRequest("GET", "/ws/v1/config/checksum", strings.NewReader(""))
+	resp := &MockResponseWriter{}
+	getConfigChecksum(resp, req)
+	var result dao.ConfigChecksumDAOInfo
+	err = json.Unmarshal(resp.outputBytes, &result)
+	assert.NilError(t, err, "failed to unmarshal checksum from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, result.Checksum, fmt.Sprintf("%X", configs.ConfigContext.Get(policyGroup).Checksum), "unexpected config checksum")
+	assert.Assert(t, partition.GetConfigChecksum() != "", "partition checksum not set")
+	assert.DeepEqual(t, result.Partitions, map[string]string{"default": partition.GetConfigChecksum()})
+	assert.Equal(t, getPartitionJSON(partition).ConfigChecksum, partition.GetConfigChecksum(), "checksum not in partition info")
+}
+
 func TestQueryParamInAppsHandler(t *testing.T) {
 	configs.MockSchedulerConfigByData([]byte(configDefault))
 	var err error
@@ -336,6 +387,9 @@ func TestQueryParamInAppsHandler(t *testing.T) {
 	err = json.Unmarshal(resp.outputBytes, &appsDao)
 	assert.NilError(t, err, "failed to unmarshal applications dao response from response body: %s", string(resp.outputBytes))
 	assert.Equal(t, len(appsDao), 1)
+	assert.Equal(t, appsDao[0].SubmissionTime, app.GetCreateTime().Unix(), "unexpected submission time")
+	assert.Assert(t, appsDao[0].Age >= 0, "unexpected age %d", appsDao[0].Age)
+	assert.Equal(t, appsDao[0].RestartCount, 0, "new application should not have restarts")
 
 	// Passing "root.q1" as filter return 0 application as there is no app running in "root.q1" queue
 	req, err = http.NewRequest("GET", "/ws/v1/apps?queue=root.q1", strings.NewReader(""))
@@ -364,6 +418,24 @@ func TestQueryParamInAppsHandler(t *testing.T) {
 	assert.NilError(t, err, "failed to unmarshal applications dao response from response body: %s", string(resp.outputBytes))
 	assert.Equal(t, len(appsDao), 0)
 
+	// Passing a user or group as filter only returns the applications of the owner
+	owned := objects.NewApplication("app-2", partitionName, "root.default", security.UserGroup{User: "alice", Groups: []string{"engineering"}}, nil, nil, rmID)
+	err = part.AddApplication(owned)
+	assert.NilError(t, err, "Failed to add Application to Partition.")
+	for query, expected := range map[string]int{"?user=alice": 1, "?group=engineering": 1, "?user=bob": 0, "?group=sales": 0, "": 2} {
+		req, err = http.NewRequest("GET", "/ws/v1/apps"+query, strings.NewReader(""))
+		assert.NilError(t, err, "App Handler request failed")
+		resp = &MockResponseWriter{}
+		getApplicationsInfo(resp, req)
+		appsDao = nil
+		err = json.Unmarshal(resp.outputBytes, &appsDao)
+		assert.NilError(t, err, "failed to unmarshal applications dao response from response body: %s", string(resp.outputBytes))
+		assert.Equal(t, len(appsDao), expected, "unexpected number of applications for query %s", query)
+		if expected == 1 {
+			assert.Equal(t, appsDao[0].ApplicationID, "app-2", "unexpected application for query %s", query)
+		}
+	}
+
 	// Passing "root.default(spe" as filter throws bad request error as queue name doesn't comply with expected characters
 	req, err = http.NewRequest("GET", "/ws/v1/apps?queue=root.default(spe", strings.NewReader(""))
 	assert.NilError(t, err, "App Handler request failed")
@@ -389,6 +461,22 @@ func (f FakeConfigPlugin) UpdateConfiguration(args *si.UpdateConfigurationReques
 	}
 }
 
+func TestGetConfigSchema(t *testing.T) {
+	// No err check: new request always returns correctly
+	//nolint: errcheck
+	req, _ := http.NewRequest("GET", "/ws/v1/config/schema", nil)
+	resp := &MockResponseWriter{}
+	getConfigSchema(resp, req)
+	assert.Equal(t, resp.statusCode, 0, "unexpected status code")
+	var schema map[string]interface{}
+	err := json.Unmarshal(resp.outputBytes, &schema)
+	assert.NilError(t, err, "failed to unmarshal schema from response body")
+	definitions, ok := schema["definitions"].(map[string]interface{})
+	assert.Assert(t, ok, "definitions missing from schema")
+	_, ok = definitions["QueueConfig"]
+	assert.Assert(t, ok, "queue config definition missing from schema")
+}
+
 func TestSaveConfigMapNoError(t *testing.T) {
 	plugins.RegisterSchedulerPlugin(&FakeConfigPlugin{generateError: false})
 	oldConf, err := updateConfiguration(updatedConf)
@@ -602,3 +690,1388 @@ func TestGetNodesUtilJSON(t *testing.T) {
 	assert.Equal(t, subresNon[0].NumOfNodes, int64(-1))
 	assert.Equal(t, subresNon[0].NodeNames[0], "N/A")
 }
+
+func TestGetNodeEvents(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	partition := schedulerContext.GetPartition("[" + rmID + "]default")
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 1000}).ToProto()
+	err = partition.AddNode(objects.NewNode(&si.NewNodeInfo{NodeID: "node-1", SchedulableResource: nodeRes}), nil)
+	assert.NilError(t, err, "add node to partition should not have failed")
+
+	// No err check: new request always returns correctly
+	//nolint: errcheck
+	req, _ := http.NewRequest("GET", "/ws/v1/node/node-1/events", strings.NewReader(""))
+	req = mux.SetURLVars(req, map[string]string{"nodeID": "node-1"})
+	resp := &MockResponseWriter{}
+	getNodeEvents(resp, req)
+	var events []dao.NodeEventDAOInfo
+	err = json.Unmarshal(resp.outputBytes, &events)
+	assert.NilError(t, err, "failed to unmarshal node events response from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, len(events), 1, "incorrect number of events returned")
+	assert.Equal(t, events[0].Type, string(scheduler.NodeAdded), "unexpected event type")
+	assert.Equal(t, events[0].Resource, "[memory:1000]", "unexpected event resource")
+
+	// unknown node
+	req = mux.SetURLVars(req, map[string]string{"nodeID": "unknown"})
+	resp = &MockResponseWriter{}
+	getNodeEvents(resp, req)
+	assert.Equal(t, resp.statusCode, http.StatusNotFound, "unknown node should return not found")
+}
+
+func TestGetApplicationEvents(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	partitionName := "[" + rmID + "]default"
+	partition := schedulerContext.GetPartition(partitionName)
+	app := newApplication("app-1", partitionName, "root.default", rmID)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "add application to partition should not have failed")
+	err = app.AddAllocationAsk(objects.NewAllocationAsk(&si.AllocationAsk{
+		AllocationKey:  "alloc-1",
+		ApplicationID:  "app-1",
+		ResourceAsk:    resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 100}).ToProto(),
+		MaxAllocations: 1,
+	}))
+	assert.NilError(t, err, "add ask to application should not have failed")
+
+	// No err check: new request always returns correctly
+	//nolint: errcheck
+	req, _ := http.NewRequest("GET", "/ws/v1/apps/app-1/events", strings.NewReader(""))
+	req = mux.SetURLVars(req, map[string]string{"appID": "app-1"})
+	resp := &MockResponseWriter{}
+	getApplicationEvents(resp, req)
+	var events []dao.ApplicationEventDAOInfo
+	err = json.Unmarshal(resp.outputBytes, &events)
+	assert.NilError(t, err, "failed to unmarshal application events response from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, len(events), 3, "incorrect number of events returned")
+	assert.Equal(t, events[0].Type, string(objects.AppAdmitted), "unexpected first event type")
+	assert.Equal(t, events[0].Resource, "", "admitted event should not have a resource")
+	assert.Equal(t, events[2].Type, string(objects.AppAskAdded), "unexpected last event type")
+	assert.Equal(t, events[2].Description, "ask alloc-1 added", "unexpected last event description")
+	assert.Equal(t, events[2].Resource, "[memory:100]", "unexpected event resource")
+
+	// unknown application
+	req = mux.SetURLVars(req, map[string]string{"appID": "unknown"})
+	resp = &MockResponseWriter{}
+	getApplicationEvents(resp, req)
+	assert.Equal(t, resp.statusCode, http.StatusNotFound, "unknown application should return not found")
+}
+
+func TestGetApplicationPlacement(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(`
+partitions:
+  - name: default
+    placementrules:
+      - name: tag
+        value: namespace
+        create: true
+    queues:
+      - name: root
+        submitacl: "*"
+`))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	partitionName := "[" + rmID + "]default"
+	partition := schedulerContext.GetPartition(partitionName)
+	app := objects.NewApplication("app-1", partitionName, "", security.UserGroup{}, map[string]string{"namespace": "root.ns"}, nil, rmID)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "add application to partition should not have failed")
+
+	// No err check: new request always returns correctly
+	//nolint: errcheck
+	req, _ := http.NewRequest("GET", "/ws/v1/apps/app-1/placement", strings.NewReader(""))
+	req = mux.SetURLVars(req, map[string]string{"appID": "app-1"})
+	resp := &MockResponseWriter{}
+	getApplicationPlacement(resp, req)
+	var audit dao.PlacementAuditDAOInfo
+	err = json.Unmarshal(resp.outputBytes, &audit)
+	assert.NilError(t, err, "failed to unmarshal placement response from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, audit.Partition, "default", "unexpected partition")
+	assert.Equal(t, audit.RuleName, "tag", "unexpected rule")
+	assert.Equal(t, audit.RuleParam, "namespace", "unexpected rule parameter")
+	assert.Equal(t, audit.ResolvedQueue, "root.ns", "unexpected resolved queue")
+
+	// unknown application
+	req = mux.SetURLVars(req, map[string]string{"appID": "unknown"})
+	resp = &MockResponseWriter{}
+	getApplicationPlacement(resp, req)
+	assert.Equal(t, resp.statusCode, http.StatusNotFound, "unknown application should return not found")
+}
+
+func TestGetAllocationInfo(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	partitionName := "[" + rmID + "]default"
+	partition := schedulerContext.GetPartition(partitionName)
+	err = partition.AddApplication(newApplication("app-1", partitionName, "root.default", rmID))
+	assert.NilError(t, err, "add application to partition should not have failed")
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 1000}).ToProto()
+	ask := &objects.AllocationAsk{
+		AllocationKey:     "alloc-1",
+		QueueName:         "root.default",
+		ApplicationID:     "app-1",
+		AllocatedResource: resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 100}),
+	}
+	allocs := []*objects.Allocation{objects.NewAllocation("alloc-1-uuid", "node-1", ask)}
+	err = partition.AddNode(objects.NewNode(&si.NewNodeInfo{NodeID: "node-1", SchedulableResource: nodeRes}), allocs)
+	assert.NilError(t, err, "add node to partition should not have failed")
+
+	// No err check: new request always returns correctly
+	//nolint: errcheck
+	req, _ := http.NewRequest("GET", "/ws/v1/allocation/alloc-1-uuid", strings.NewReader(""))
+	req = mux.SetURLVars(req, map[string]string{"uuid": "alloc-1-uuid"})
+	resp := &MockResponseWriter{}
+	getAllocationInfo(resp, req)
+	var allocInfo dao.AllocationDAOInfo
+	err = json.Unmarshal(resp.outputBytes, &allocInfo)
+	assert.NilError(t, err, "failed to unmarshal allocation response from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, allocInfo.UUID, "alloc-1-uuid", "unexpected allocation returned")
+	assert.Equal(t, allocInfo.NodeID, "node-1", "unexpected node returned")
+	assert.Equal(t, allocInfo.ApplicationID, "app-1", "unexpected application returned")
+	assert.Equal(t, allocInfo.ResourcePerAlloc, "[memory:100]", "unexpected resource returned")
+	assert.Assert(t, allocInfo.CreationTime > 0, "creation time not set")
+
+	// unknown allocation
+	req = mux.SetURLVars(req, map[string]string{"uuid": "unknown"})
+	resp = &MockResponseWriter{}
+	getAllocationInfo(resp, req)
+	assert.Equal(t, resp.statusCode, http.StatusNotFound, "unknown allocation should return not found")
+}
+
+func TestGetPreemptionCandidates(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	partitionName := "[" + rmID + "]default"
+	partition := schedulerContext.GetPartition(partitionName)
+	err = partition.AddApplication(newApplication("app-1", partitionName, "root.default", rmID))
+	assert.NilError(t, err, "add application to partition should not have failed")
+	app := newApplication("app-2", partitionName, "root.default", rmID)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "add application to partition should not have failed")
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 1000}).ToProto()
+	ask := &objects.AllocationAsk{
+		AllocationKey:     "alloc-1",
+		QueueName:         "root.default",
+		ApplicationID:     "app-1",
+		AllocatedResource: resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 100}),
+	}
+	allocs := []*objects.Allocation{objects.NewAllocation("alloc-1-uuid", "node-1", ask)}
+	err = partition.AddNode(objects.NewNode(&si.NewNodeInfo{NodeID: "node-1", SchedulableResource: nodeRes}), allocs)
+	assert.NilError(t, err, "add node to partition should not have failed")
+	err = app.AddAllocationAsk(objects.NewAllocationAsk(&si.AllocationAsk{
+		AllocationKey:  "ask-1",
+		ApplicationID:  "app-2",
+		ResourceAsk:    resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 100}).ToProto(),
+		MaxAllocations: 1,
+		Priority:       &si.Priority{Priority: &si.Priority_PriorityValue{PriorityValue: 5}},
+	}))
+	assert.NilError(t, err, "add ask to application should not have failed")
+
+	// No err check: new request always returns correctly
+	//nolint: errcheck
+	req, _ := http.NewRequest("GET", "/ws/v1/apps/app-2/preemption-candidates", strings.NewReader(""))
+	req = mux.SetURLVars(req, map[string]string{"appID": "app-2"})
+	resp := &MockResponseWriter{}
+	getPreemptionCandidates(resp, req)
+	var result []dao.PreemptionCandidatesDAOInfo
+	err = json.Unmarshal(resp.outputBytes, &result)
+	assert.NilError(t, err, "failed to unmarshal candidates response from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, len(result), 1, "expected candidates for one pending ask")
+	assert.Equal(t, result[0].AllocationKey, "ask-1", "unexpected ask returned")
+	assert.Equal(t, len(result[0].Candidates), 1, "expected one candidate")
+	assert.Equal(t, result[0].Candidates[0].UUID, "alloc-1-uuid", "unexpected candidate returned")
+
+	// unknown application
+	req = mux.SetURLVars(req, map[string]string{"appID": "unknown"})
+	resp = &MockResponseWriter{}
+	getPreemptionCandidates(resp, req)
+	assert.Equal(t, resp.statusCode, http.StatusNotFound, "unknown application should return not found")
+}
+
+func TestGetPartitionResources(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	partitionName := "[" + rmID + "]default"
+	partition := schedulerContext.GetPartition(partitionName)
+	err = partition.AddApplication(newApplication("app-1", partitionName, "root.default", rmID))
+	assert.NilError(t, err, "add application to partition should not have failed")
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 1000}).ToProto()
+	ask := &objects.AllocationAsk{
+		AllocationKey:     "alloc-1",
+		QueueName:         "root.default",
+		ApplicationID:     "app-1",
+		AllocatedResource: resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 100}),
+	}
+	allocs := []*objects.Allocation{objects.NewAllocation("alloc-1-uuid", "node-1", ask)}
+	err = partition.AddNode(objects.NewNode(&si.NewNodeInfo{NodeID: "node-1", SchedulableResource: nodeRes}), allocs)
+	assert.NilError(t, err, "add node to partition should not have failed")
+
+	// No err check: new request always returns correctly
+	//nolint: errcheck
+	req, _ := http.NewRequest("GET", "/ws/v1/partition/default/resources", strings.NewReader(""))
+	req = mux.SetURLVars(req, map[string]string{"name": "default"})
+	resp := &MockResponseWriter{}
+	getPartitionResources(resp, req)
+	var result map[string]dao.QueueResourceDAOInfo
+	err = json.Unmarshal(resp.outputBytes, &result)
+	assert.NilError(t, err, "failed to unmarshal resources response from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, len(result), 2, "expected the root and default queue")
+	assert.Equal(t, result["root"].AllocatedResource, "[memory:100]", "unexpected root allocated resource")
+	assert.Equal(t, result["root.default"].AllocatedResource, "[memory:100]", "unexpected leaf allocated resource")
+
+	// unknown partition
+	req = mux.SetURLVars(req, map[string]string{"name": "unknown"})
+	resp = &MockResponseWriter{}
+	getPartitionResources(resp, req)
+	assert.Equal(t, resp.statusCode, http.StatusNotFound, "unknown partition should return not found")
+}
+
+func TestGetSchedulerVersion(t *testing.T) {
+	scheduler.RegisterFeature("test-feature")
+	// No err check: new request always returns correctly
+	//nolint: errcheck
+	req, _ := http.NewRequest("GET", "/ws/v1/version", strings.NewReader(""))
+	resp := &MockResponseWriter{}
+	getSchedulerVersion(resp, req)
+	var result dao.SchedulerVersionDAOInfo
+	err := json.Unmarshal(resp.outputBytes, &result)
+	assert.NilError(t, err, "failed to unmarshal version from response body: %s", string(resp.outputBytes))
+	version := scheduler.GetSchedulerVersion()
+	assert.Equal(t, result.Major, version.Major, "unexpected major version")
+	assert.Equal(t, result.Minor, version.Minor, "unexpected minor version")
+	assert.Equal(t, result.Patch, version.Patch, "unexpected patch version")
+	assert.Assert(t, result.GitCommit != "", "git commit should be set")
+	assert.Assert(t, result.BuildTime != "", "build time should be set")
+	assert.DeepEqual(t, result.SupportedFeatures, version.SupportedFeatures)
+	found := false
+	for _, feature := range result.SupportedFeatures {
+		found = found || feature == "test-feature"
+	}
+	assert.Assert(t, found, "registered feature not returned: %v", result.SupportedFeatures)
+}
+
+func TestGetPartitionQueueLoad(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+
+	// No err check: new request always returns correctly
+	//nolint: errcheck
+	req, _ := http.NewRequest("GET", "/ws/v1/partition/default/queues/load", strings.NewReader(""))
+	req = mux.SetURLVars(req, map[string]string{"name": "default"})
+	resp := &MockResponseWriter{}
+	getPartitionQueueLoad(resp, req)
+	var result map[string]float64
+	err = json.Unmarshal(resp.outputBytes, &result)
+	assert.NilError(t, err, "failed to unmarshal queue load from response body: %s", string(resp.outputBytes))
+	// no nodes: nothing to measure the load against
+	assert.DeepEqual(t, result, map[string]float64{"root": -1, "root.default": -1})
+
+	//nolint: errcheck
+	req, _ = http.NewRequest("GET", "/ws/v1/partition/unknown/queues/load", strings.NewReader(""))
+	req = mux.SetURLVars(req, map[string]string{"name": "unknown"})
+	resp = &MockResponseWriter{}
+	getPartitionQueueLoad(resp, req)
+	assert.Equal(t, resp.statusCode, http.StatusNotFound, "unknown partition should not be found")
+}
+
+func TestGetPartitionQueueDepths(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+
+	// No err check: new request always returns correctly
+	//nolint: errcheck
+	req, _ := http.NewRequest("GET", "/ws/v1/partition/default/queue-depths", strings.NewReader(""))
+	req = mux.SetURLVars(req, map[string]string{"name": "default"})
+	resp := &MockResponseWriter{}
+	getPartitionQueueDepths(resp, req)
+	var result []dao.QueueDepthDAOInfo
+	err = json.Unmarshal(resp.outputBytes, &result)
+	assert.NilError(t, err, "failed to unmarshal queue depths from response body: %s", string(resp.outputBytes))
+	assert.DeepEqual(t, result, []dao.QueueDepthDAOInfo{
+		{Depth: 0, Queues: 1, Leaf: 0, Parent: 1},
+		{Depth: 1, Queues: 1, Leaf: 1, Parent: 0},
+	})
+
+	//nolint: errcheck
+	req, _ = http.NewRequest("GET", "/ws/v1/partition/unknown/queue-depths", strings.NewReader(""))
+	req = mux.SetURLVars(req, map[string]string{"name": "unknown"})
+	resp = &MockResponseWriter{}
+	getPartitionQueueDepths(resp, req)
+	assert.Equal(t, resp.statusCode, http.StatusNotFound, "unknown partition should not be found")
+}
+
+func TestGetPartitionFitMatrix(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+
+	// No err check: new request always returns correctly
+	//nolint: errcheck
+	req, _ := http.NewRequest("GET", "/ws/v1/partition/default/fitmatrix", strings.NewReader(""))
+	req = mux.SetURLVars(req, map[string]string{"name": "default"})
+	resp := &MockResponseWriter{}
+	getPartitionFitMatrix(resp, req)
+	var result map[string]map[string]bool
+	err = json.Unmarshal(resp.outputBytes, &result)
+	assert.NilError(t, err, "failed to unmarshal fit matrix from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, len(result), 0, "partition without asks should have an empty matrix")
+
+	//nolint: errcheck
+	req, _ = http.NewRequest("GET", "/ws/v1/partition/unknown/fitmatrix", strings.NewReader(""))
+	req = mux.SetURLVars(req, map[string]string{"name": "unknown"})
+	resp = &MockResponseWriter{}
+	getPartitionFitMatrix(resp, req)
+	assert.Equal(t, resp.statusCode, http.StatusNotFound, "unknown partition should not be found")
+}
+
+func TestGetPartitionNodeGroups(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	partition := schedulerContext.GetPartition("[" + rmID + "]default")
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 1000}).ToProto()
+	for nodeID, zone := range map[string]string{"node-1": "zone-a", "node-2": "zone-a", "node-3": ""} {
+		attributes := map[string]string{}
+		if zone != "" {
+			attributes[configs.DefaultNodeGroupKey] = zone
+		}
+		err = partition.AddNode(objects.NewNode(&si.NewNodeInfo{NodeID: nodeID, Attributes: attributes, SchedulableResource: nodeRes}), nil)
+		assert.NilError(t, err, "add node to partition should not have failed")
+	}
+
+	// No err check: new request always returns correctly
+	//nolint: errcheck
+	req, _ := http.NewRequest("GET", "/ws/v1/partition/default/nodegroups", strings.NewReader(""))
+	req = mux.SetURLVars(req, map[string]string{"name": "default"})
+	resp := &MockResponseWriter{}
+	getPartitionNodeGroups(resp, req)
+	var result dao.NodeGroupsDAOInfo
+	err = json.Unmarshal(resp.outputBytes, &result)
+	assert.NilError(t, err, "failed to unmarshal node groups response from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, result.PartitionName, "default", "unexpected partition")
+	assert.Equal(t, result.GroupKey, configs.DefaultNodeGroupKey, "unexpected group key")
+	assert.DeepEqual(t, result.Groups, map[string][]string{"zone-a": {"node-1", "node-2"}, "": {"node-3"}})
+
+	//nolint: errcheck
+	req, _ = http.NewRequest("GET", "/ws/v1/partition/unknown/nodegroups", strings.NewReader(""))
+	req = mux.SetURLVars(req, map[string]string{"name": "unknown"})
+	resp = &MockResponseWriter{}
+	getPartitionNodeGroups(resp, req)
+	assert.Equal(t, resp.statusCode, http.StatusNotFound, "unknown partition should not be found")
+}
+
+func TestGetPartitionNodeDistribution(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	partition := schedulerContext.GetPartition("[" + rmID + "]default")
+	for nodeID, size := range map[string]string{"node-1": "small", "node-2": "small", "node-3": ""} {
+		attributes := map[string]string{}
+		if size != "" {
+			attributes[scheduler.NodeSizeLabel] = size
+		}
+		nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 1000}).ToProto()
+		err = partition.AddNode(objects.NewNode(&si.NewNodeInfo{NodeID: nodeID, Attributes: attributes, SchedulableResource: nodeRes}), nil)
+		assert.NilError(t, err, "add node to partition should not have failed")
+	}
+
+	// No err check: new request always returns correctly
+	//nolint: errcheck
+	req, _ := http.NewRequest("GET", "/ws/v1/partition/default/nodes/distribution", strings.NewReader(""))
+	req = mux.SetURLVars(req, map[string]string{"name": "default"})
+	resp := &MockResponseWriter{}
+	getPartitionNodeDistribution(resp, req)
+	var result dao.NodeCapacityDistributionDAOInfo
+	err = json.Unmarshal(resp.outputBytes, &result)
+	assert.NilError(t, err, "failed to unmarshal node distribution response from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, result.PartitionName, "default", "unexpected partition")
+	assert.Equal(t, len(result.Buckets), 2, "unexpected buckets: %v", result.Buckets)
+	assert.DeepEqual(t, result.Buckets["small"], &dao.NodeCapacityBucketDAOInfo{Count: 2, Capacity: "[memory:2000]", Allocated: "[]"})
+	assert.DeepEqual(t, result.Buckets["memory:512-1024"], &dao.NodeCapacityBucketDAOInfo{Count: 1, Capacity: "[memory:1000]", Allocated: "[]"})
+
+	//nolint: errcheck
+	req, _ = http.NewRequest("GET", "/ws/v1/partition/unknown/nodes/distribution", strings.NewReader(""))
+	req = mux.SetURLVars(req, map[string]string{"name": "unknown"})
+	resp = &MockResponseWriter{}
+	getPartitionNodeDistribution(resp, req)
+	assert.Equal(t, resp.statusCode, http.StatusNotFound, "unknown partition should not be found")
+}
+
+func TestGetPartitionNodeExtremes(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	partition := schedulerContext.GetPartition("[" + rmID + "]default")
+	app := newApplication("app-1", partition.Name, "root.default", rmID)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "add application to partition should not have failed")
+	for nodeID, used := range map[string]resources.Quantity{"node-1": 250, "node-2": 750, "node-3": 500} {
+		nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 1000}).ToProto()
+		ask := &objects.AllocationAsk{
+			AllocationKey:     "alloc-" + nodeID,
+			QueueName:         "root.default",
+			ApplicationID:     "app-1",
+			AllocatedResource: resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: used}),
+		}
+		allocs := []*objects.Allocation{objects.NewAllocation("uuid-"+nodeID, nodeID, ask)}
+		err = partition.AddNode(objects.NewNode(&si.NewNodeInfo{NodeID: nodeID, SchedulableResource: nodeRes}), allocs)
+		assert.NilError(t, err, "add node to partition should not have failed")
+	}
+
+	// No err check: new request always returns correctly
+	//nolint: errcheck
+	req, _ := http.NewRequest("GET", "/ws/v1/partition/default/nodes/extremes", strings.NewReader(""))
+	req = mux.SetURLVars(req, map[string]string{"name": "default"})
+	resp := &MockResponseWriter{}
+	getPartitionNodeExtremes(resp, req)
+	var result dao.NodeUtilizationExtremesDAOInfo
+	err = json.Unmarshal(resp.outputBytes, &result)
+	assert.NilError(t, err, "failed to unmarshal node extremes response from response body: %s", string(resp.outputBytes))
+	assert.DeepEqual(t, result, dao.NodeUtilizationExtremesDAOInfo{
+		PartitionName:  "default",
+		MaxNodeID:      "node-2",
+		MaxUtilization: 0.75,
+		MinNodeID:      "node-1",
+		MinUtilization: 0.25,
+	})
+
+	//nolint: errcheck
+	req, _ = http.NewRequest("GET", "/ws/v1/partition/unknown/nodes/extremes", strings.NewReader(""))
+	req = mux.SetURLVars(req, map[string]string{"name": "unknown"})
+	resp = &MockResponseWriter{}
+	getPartitionNodeExtremes(resp, req)
+	assert.Equal(t, resp.statusCode, http.StatusNotFound, "unknown partition should not be found")
+}
+
+func TestGetPartitionAllocationRates(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+
+	tests := []struct {
+		name   string
+		query  string
+		status int
+	}{
+		{"invalid window", "?windowSeconds=abc", http.StatusBadRequest},
+		{"zero window", "?windowSeconds=0", http.StatusBadRequest},
+		{"window too large", "?windowSeconds=3600", http.StatusBadRequest},
+		{"default window", "", 0},
+		{"valid window", "?windowSeconds=10", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// No err check: new request always returns correctly
+			//nolint: errcheck
+			req, _ := http.NewRequest("GET", "/ws/v1/partition/default/allocation-rates"+tt.query, strings.NewReader(""))
+			req = mux.SetURLVars(req, map[string]string{"name": "default"})
+			resp := &MockResponseWriter{}
+			getPartitionAllocationRates(resp, req)
+			assert.Equal(t, resp.statusCode, tt.status, "unexpected status code: %s", string(resp.outputBytes))
+			if tt.status == 0 {
+				var result map[string]float64
+				err = json.Unmarshal(resp.outputBytes, &result)
+				assert.NilError(t, err, "failed to unmarshal allocation rates from response body: %s", string(resp.outputBytes))
+				assert.DeepEqual(t, result, map[string]float64{"root": 0, "root.default": 0})
+			}
+		})
+	}
+
+	//nolint: errcheck
+	req, _ := http.NewRequest("GET", "/ws/v1/partition/unknown/allocation-rates", strings.NewReader(""))
+	req = mux.SetURLVars(req, map[string]string{"name": "unknown"})
+	resp := &MockResponseWriter{}
+	getPartitionAllocationRates(resp, req)
+	assert.Equal(t, resp.statusCode, http.StatusNotFound, "unknown partition should not be found")
+}
+
+func TestGetPartitionThroughput(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		window int
+	}{
+		{"invalid window", "?windowSeconds=abc", http.StatusBadRequest, 0},
+		{"zero window", "?windowSeconds=0", http.StatusBadRequest, 0},
+		{"window too large", "?windowSeconds=3600", http.StatusBadRequest, 0},
+		{"default window", "", 0, 60},
+		{"valid window", "?windowSeconds=10", 0, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// No err check: new request always returns correctly
+			//nolint: errcheck
+			req, _ := http.NewRequest("GET", "/ws/v1/partition/default/throughput"+tt.query, strings.NewReader(""))
+			req = mux.SetURLVars(req, map[string]string{"name": "default"})
+			resp := &MockResponseWriter{}
+			getPartitionThroughput(resp, req)
+			assert.Equal(t, resp.statusCode, tt.status, "unexpected status code: %s", string(resp.outputBytes))
+			if tt.status == 0 {
+				var result dao.ThroughputDAOInfo
+				err = json.Unmarshal(resp.outputBytes, &result)
+				assert.NilError(t, err, "failed to unmarshal throughput from response body: %s", string(resp.outputBytes))
+				assert.DeepEqual(t, result, dao.ThroughputDAOInfo{WindowSeconds: tt.window})
+			}
+		})
+	}
+
+	//nolint: errcheck
+	req, _ := http.NewRequest("GET", "/ws/v1/partition/unknown/throughput", strings.NewReader(""))
+	req = mux.SetURLVars(req, map[string]string{"name": "unknown"})
+	resp := &MockResponseWriter{}
+	getPartitionThroughput(resp, req)
+	assert.Equal(t, resp.statusCode, http.StatusNotFound, "unknown partition should not be found")
+}
+
+func TestGetPartitionBacklog(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	partitionName := "[" + rmID + "]default"
+	app := newApplication("app-1", partitionName, "root.default", rmID)
+	err = schedulerContext.GetPartition(partitionName).AddApplication(app)
+	assert.NilError(t, err, "Failed to add Application to Partition.")
+	res := resources.NewResourceFromMap(map[string]resources.Quantity{"vcore": 1})
+	err = app.AddAllocationAsk(objects.NewAllocationAsk(&si.AllocationAsk{
+		AllocationKey:  "alloc-1",
+		ApplicationID:  "app-1",
+		ResourceAsk:    res.ToProto(),
+		MaxAllocations: 2,
+	}))
+	assert.NilError(t, err, "ask should have been added to app")
+
+	// No err check: new request always returns correctly
+	//nolint: errcheck
+	req, _ := http.NewRequest("GET", "/ws/v1/partition/default/backlog", strings.NewReader(""))
+	req = mux.SetURLVars(req, map[string]string{"name": "default"})
+	resp := &MockResponseWriter{}
+	getPartitionBacklog(resp, req)
+	var backlog dao.SchedulerBacklogDAOInfo
+	err = json.Unmarshal(resp.outputBytes, &backlog)
+	assert.NilError(t, err, "failed to unmarshal backlog from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, backlog.TotalPendingAsks, 1, "unexpected pending asks")
+	assert.Equal(t, backlog.TotalPendingResource, "[vcore:2]", "unexpected pending resource")
+	assert.Equal(t, backlog.AppCount, 1, "unexpected apps in the backlog")
+	assert.DeepEqual(t, backlog.QueueBreakdown, map[string]int{"root.default": 1})
+
+	//nolint: errcheck
+	req, _ = http.NewRequest("GET", "/ws/v1/partition/unknown/backlog", strings.NewReader(""))
+	req = mux.SetURLVars(req, map[string]string{"name": "unknown"})
+	resp = &MockResponseWriter{}
+	getPartitionBacklog(resp, req)
+	assert.Equal(t, resp.statusCode, http.StatusNotFound, "unknown partition should not be found")
+}
+
+func TestGetPartitionCompletedAllocationStats(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	partitionName := "[" + rmID + "]default"
+	partition := schedulerContext.GetPartition(partitionName)
+	app := newApplication("app-1", partitionName, "root.default", rmID)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "Failed to add Application to Partition.")
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 1000}).ToProto()
+	ask := &objects.AllocationAsk{
+		AllocationKey:     "alloc-1",
+		QueueName:         "root.default",
+		ApplicationID:     "app-1",
+		AllocatedResource: resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 100}),
+	}
+	allocs := []*objects.Allocation{objects.NewAllocation("alloc-1-uuid", "node-1", ask)}
+	err = partition.AddNode(objects.NewNode(&si.NewNodeInfo{NodeID: "node-1", SchedulableResource: nodeRes}), allocs)
+	assert.NilError(t, err, "add node to partition should not have failed")
+	assert.Equal(t, len(partition.ForceRemoveApplication("app-1", "test")), 1, "allocation should have been removed")
+
+	// No err check: new request always returns correctly
+	//nolint: errcheck
+	req, _ := http.NewRequest("GET", "/ws/v1/partition/default/completedAllocations/stats", strings.NewReader(""))
+	req = mux.SetURLVars(req, map[string]string{"name": "default"})
+	resp := &MockResponseWriter{}
+	getPartitionCompletedAllocationStats(resp, req)
+	var stats dao.CompletedAllocationStatsDAOInfo
+	err = json.Unmarshal(resp.outputBytes, &stats)
+	assert.NilError(t, err, "failed to unmarshal completed allocation stats from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, stats.PartitionName, "default", "unexpected partition")
+	assert.Equal(t, stats.TotalAllocations, int64(1), "unexpected completed allocations")
+	assert.Equal(t, len(stats.Queues), 1, "unexpected queues in the breakdown")
+	assert.Equal(t, stats.Queues["root.default"].Allocations, int64(1), "unexpected completed allocations for the queue")
+	_, ok := stats.ResourceSeconds[resources.MEMORY]
+	assert.Assert(t, ok, "resource time not reported for memory")
+
+	//nolint: errcheck
+	req, _ = http.NewRequest("GET", "/ws/v1/partition/unknown/completedAllocations/stats", strings.NewReader(""))
+	req = mux.SetURLVars(req, map[string]string{"name": "unknown"})
+	resp = &MockResponseWriter{}
+	getPartitionCompletedAllocationStats(resp, req)
+	assert.Equal(t, resp.statusCode, http.StatusNotFound, "unknown partition should not be found")
+}
+
+func TestGetUserQuotaUtilization(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(`
+partitions:
+  - name: default
+    queues:
+      - name: root
+        submitacl: "*"
+        queues:
+          - name: default
+    limits:
+      - limit:
+        users:
+        - alice
+        maxresources: {memory: 1000, vcore: 10}
+`))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	partitionName := "[" + rmID + "]default"
+	partition := schedulerContext.GetPartition(partitionName)
+	app := objects.NewApplication("app-1", partitionName, "root.default", security.UserGroup{User: "alice"}, nil, nil, rmID)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "Failed to add Application to Partition.")
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 2000, resources.VCORE: 20}).ToProto()
+	ask := &objects.AllocationAsk{
+		AllocationKey:     "alloc-1",
+		QueueName:         "root.default",
+		ApplicationID:     "app-1",
+		AllocatedResource: resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 250, resources.VCORE: 5}),
+	}
+	allocs := []*objects.Allocation{objects.NewAllocation("alloc-1-uuid", "node-1", ask)}
+	err = partition.AddNode(objects.NewNode(&si.NewNodeInfo{NodeID: "node-1", SchedulableResource: nodeRes}), allocs)
+	assert.NilError(t, err, "add node to partition should not have failed")
+
+	tests := map[string]map[string]float64{
+		"alice": {resources.MEMORY: 0.25, resources.VCORE: 0.5},
+		"bob":   {resources.MEMORY: -1, resources.VCORE: -1},
+	}
+	for user, expected := range tests {
+		// No err check: new request always returns correctly
+		//nolint: errcheck
+		req, _ := http.NewRequest("GET", "/ws/v1/users/"+user+"/quota", strings.NewReader(""))
+		req = mux.SetURLVars(req, map[string]string{"user": user})
+		resp := &MockResponseWriter{}
+		getUserQuotaUtilization(resp, req)
+		var result []*dao.UserQuotaUtilizationDAOInfo
+		err = json.Unmarshal(resp.outputBytes, &result)
+		assert.NilError(t, err, "failed to unmarshal quota utilisation from response body: %s", string(resp.outputBytes))
+		assert.Equal(t, len(result), 1, "unexpected number of partitions")
+		assert.DeepEqual(t, result[0], &dao.UserQuotaUtilizationDAOInfo{PartitionName: "default", User: user, Utilization: expected})
+	}
+}
+
+func TestGetPartitionReservationAges(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+
+	// No err check: new request always returns correctly
+	//nolint: errcheck
+	req, _ := http.NewRequest("GET", "/ws/v1/partition/default/reservations/age", strings.NewReader(""))
+	req = mux.SetURLVars(req, map[string]string{"name": "default"})
+	resp := &MockResponseWriter{}
+	getPartitionReservationAges(resp, req)
+	var result map[string]int
+	err = json.Unmarshal(resp.outputBytes, &result)
+	assert.NilError(t, err, "failed to unmarshal reservation ages from response body: %s", string(resp.outputBytes))
+	assert.DeepEqual(t, result, map[string]int{"<1s": 0, "1s-10s": 0, "10s-60s": 0, "1m-10m": 0, ">10m": 0})
+
+	//nolint: errcheck
+	req, _ = http.NewRequest("GET", "/ws/v1/partition/unknown/reservations/age", strings.NewReader(""))
+	req = mux.SetURLVars(req, map[string]string{"name": "unknown"})
+	resp = &MockResponseWriter{}
+	getPartitionReservationAges(resp, req)
+	assert.Equal(t, resp.statusCode, http.StatusNotFound, "unknown partition should not be found")
+}
+
+func TestGetPartitionFairShareViolations(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+
+	// No err check: new request always returns correctly
+	//nolint: errcheck
+	req, _ := http.NewRequest("GET", "/ws/v1/partition/default/fairshare/violations", strings.NewReader(""))
+	req = mux.SetURLVars(req, map[string]string{"name": "default"})
+	resp := &MockResponseWriter{}
+	getPartitionFairShareViolations(resp, req)
+	var result []*dao.FairShareViolationDAOInfo
+	err = json.Unmarshal(resp.outputBytes, &result)
+	assert.NilError(t, err, "failed to unmarshal violations from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, len(result), 0, "partition without apps should not have violations")
+
+	//nolint: errcheck
+	req, _ = http.NewRequest("GET", "/ws/v1/partition/unknown/fairshare/violations", strings.NewReader(""))
+	req = mux.SetURLVars(req, map[string]string{"name": "unknown"})
+	resp = &MockResponseWriter{}
+	getPartitionFairShareViolations(resp, req)
+	assert.Equal(t, resp.statusCode, http.StatusNotFound, "unknown partition should not be found")
+}
+
+func TestGetQueueFairShare(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+
+	// No err check: new request always returns correctly
+	//nolint: errcheck
+	req, _ := http.NewRequest("GET", "/ws/v1/queue/root.default/fairshare", strings.NewReader(""))
+	req = mux.SetURLVars(req, map[string]string{"path": "root.default"})
+	resp := &MockResponseWriter{}
+	getQueueFairShare(resp, req)
+	var result dao.QueueFairShareDAOInfo
+	err = json.Unmarshal(resp.outputBytes, &result)
+	assert.NilError(t, err, "failed to unmarshal fair share from response body: %s", string(resp.outputBytes))
+	// no nodes: nothing to divide over the queues
+	assert.DeepEqual(t, result, dao.QueueFairShareDAOInfo{QueuePath: "root.default", Weight: 1, FairShare: -1})
+
+	//nolint: errcheck
+	req, _ = http.NewRequest("GET", "/ws/v1/queue/root.unknown/fairshare", strings.NewReader(""))
+	req = mux.SetURLVars(req, map[string]string{"path": "root.unknown"})
+	resp = &MockResponseWriter{}
+	getQueueFairShare(resp, req)
+	assert.Equal(t, resp.statusCode, http.StatusNotFound, "unknown queue should not be found")
+}
+
+func TestGetQueuesByLabel(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(startConf))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		queues []string
+	}{
+		{"missing value", "?label=first", http.StatusBadRequest, nil},
+		{"missing label", "?value=somethingElse", http.StatusBadRequest, nil},
+		{"matching value", "?label=second&value=somethingElse", 0, []string{"root"}},
+		{"other value", "?label=second&value=other", 0, []string{}},
+		{"wildcard", "?label=first&value=*", 0, []string{"root"}},
+		{"unknown label", "?label=unknown&value=*", 0, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// No err check: new request always returns correctly
+			//nolint: errcheck
+			req, _ := http.NewRequest("GET", "/ws/v1/queues"+tt.query, strings.NewReader(""))
+			resp := &MockResponseWriter{}
+			getQueueInfo(resp, req)
+			assert.Equal(t, resp.statusCode, tt.status, "unexpected status code: %s", string(resp.outputBytes))
+			if tt.status == 0 {
+				var result []*dao.QueuesByLabelDAOInfo
+				err = json.Unmarshal(resp.outputBytes, &result)
+				assert.NilError(t, err, "failed to unmarshal queues from response body: %s", string(resp.outputBytes))
+				assert.Equal(t, len(result), 1, "unexpected number of partitions")
+				assert.Equal(t, result[0].PartitionName, "default", "unexpected partition")
+				assert.DeepEqual(t, result[0].Queues, tt.queues)
+			}
+		})
+	}
+}
+
+func TestGetQueueInfoFiltered(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	partitionName := "[" + rmID + "]default"
+	err = schedulerContext.GetPartition(partitionName).AddApplication(newApplication("app-1", partitionName, "root.default", rmID))
+	assert.NilError(t, err, "Failed to add Application to Partition.")
+
+	tests := []struct {
+		name     string
+		body     string
+		status   int
+		children []string
+	}{
+		{"invalid json", "{", http.StatusBadRequest, nil},
+		{"invalid load range", `{"minLoad": 0.5, "maxLoad": 0.1}`, http.StatusBadRequest, nil},
+		{"no filter", "{}", 0, []string{"default"}},
+		{"has apps", `{"hasApps": true}`, 0, []string{"default"}},
+		{"state", `{"state": "Draining"}`, 0, nil},
+		{"no load", `{"maxLoad": 0}`, 0, []string{"default"}},
+		{"loaded", `{"minLoad": 0}`, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// No err check: new request always returns correctly
+			//nolint: errcheck
+			req, _ := http.NewRequest("POST", "/ws/v1/queues", strings.NewReader(tt.body))
+			resp := &MockResponseWriter{}
+			getQueueInfoFiltered(resp, req)
+			assert.Equal(t, resp.statusCode, tt.status, "unexpected status code: %s", string(resp.outputBytes))
+			if tt.status == 0 {
+				var result dao.PartitionDAOInfo
+				err = json.Unmarshal(resp.outputBytes, &result)
+				assert.NilError(t, err, "failed to unmarshal partition from response body: %s", string(resp.outputBytes))
+				assert.Equal(t, result.PartitionName, partitionName, "unexpected partition")
+				assert.Equal(t, result.Queues.QueueName, "root", "root should always be returned")
+				var children []string
+				for _, child := range result.Queues.ChildQueues {
+					children = append(children, child.QueueName)
+				}
+				assert.DeepEqual(t, children, tt.children)
+			}
+		})
+	}
+}
+
+func TestUpdatePartitionPreemption(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	partition := schedulerContext.GetPartition("[" + rmID + "]default")
+	assert.Assert(t, !partition.GetIsPreemptable(), "preemption should be disabled from config")
+
+	tests := []struct {
+		name      string
+		partition string
+		body      string
+		status    int
+		enabled   bool
+	}{
+		{"invalid json", "default", "{", http.StatusBadRequest, false},
+		{"unknown partition", "unknown", `{"enabled": true}`, http.StatusNotFound, false},
+		{"enable", "default", `{"enabled": true}`, 0, true},
+		{"disable", "default", `{"enabled": false}`, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// No err check: new request always returns correctly
+			//nolint: errcheck
+			req, _ := http.NewRequest("PUT", "/ws/v1/partition/"+tt.partition+"/preemption", strings.NewReader(tt.body))
+			req = mux.SetURLVars(req, map[string]string{"name": tt.partition})
+			resp := &MockResponseWriter{}
+			updatePartitionPreemption(resp, req)
+			assert.Equal(t, resp.statusCode, tt.status, "unexpected status code: %s", string(resp.outputBytes))
+			assert.Equal(t, partition.GetIsPreemptable(), tt.enabled, "unexpected preemption state")
+			if tt.status == 0 {
+				var result dao.PartitionPreemptionDAOInfo
+				err = json.Unmarshal(resp.outputBytes, &result)
+				assert.NilError(t, err, "failed to unmarshal preemption response: %s", string(resp.outputBytes))
+				assert.Equal(t, result.Enabled, tt.enabled, "unexpected preemption state in response")
+			}
+		})
+	}
+}
+
+func TestUpdateQueueMaxResource(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(`
+partitions:
+  - name: default
+    placementrules:
+      - name: provided
+        create: true
+    queues:
+      - name: root
+        submitacl: "*"
+        queues:
+          - name: default
+`))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	partitionName := "[" + rmID + "]default"
+	partition := schedulerContext.GetPartition(partitionName)
+	err = partition.AddApplication(newApplication("app-1", partitionName, "root.dynamic", rmID))
+	assert.NilError(t, err, "add application to partition should not have failed")
+	nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 1000}).ToProto()
+	ask := &objects.AllocationAsk{
+		AllocationKey:     "alloc-1",
+		QueueName:         "root.dynamic",
+		ApplicationID:     "app-1",
+		AllocatedResource: resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 100}),
+	}
+	allocs := []*objects.Allocation{objects.NewAllocation("alloc-1-uuid", "node-1", ask)}
+	err = partition.AddNode(objects.NewNode(&si.NewNodeInfo{NodeID: "node-1", SchedulableResource: nodeRes}), allocs)
+	assert.NilError(t, err, "add node to partition should not have failed")
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+	}{
+		{"invalid json", "root.dynamic", "{", http.StatusBadRequest},
+		{"negative value", "root.dynamic", `{"memory": -1}`, http.StatusBadRequest},
+		{"below allocation", "root.dynamic", `{"memory": 50}`, http.StatusBadRequest},
+		{"above parent", "root.dynamic", `{"memory": 5000}`, http.StatusBadRequest},
+		{"unknown queue", "root.unknown", `{"memory": 500}`, http.StatusNotFound},
+		{"managed queue", "root.default", `{"memory": 500}`, http.StatusBadRequest},
+		{"valid", "root.dynamic", `{"memory": 500}`, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// No err check: new request always returns correctly
+			//nolint: errcheck
+			req, _ := http.NewRequest("PUT", "/ws/v1/queue/"+tt.path+"/max", strings.NewReader(tt.body))
+			req = mux.SetURLVars(req, map[string]string{"path": tt.path})
+			resp := &MockResponseWriter{}
+			updateQueueMaxResource(resp, req)
+			assert.Equal(t, resp.statusCode, tt.status, "unexpected status code: %s", string(resp.outputBytes))
+		})
+	}
+	expected := resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 500})
+	assert.Assert(t, resources.Equals(partition.GetQueue("root.dynamic").GetMaxResource(), expected), "queue max not updated")
+	assert.Assert(t, !resources.Equals(partition.GetQueue("root.default").GetMaxResource(), expected), "managed queue max should not have been updated")
+}
+
+func TestUpdateQueueAppSortPolicy(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	partition := schedulerContext.GetPartition("[" + rmID + "]default")
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+	}{
+		{"invalid json", "root.default", "{", http.StatusBadRequest},
+		{"no policy", "root.default", `{}`, http.StatusBadRequest},
+		{"unknown policy", "root.default", `{"policy": "unknown"}`, http.StatusBadRequest},
+		{"parent queue", "root", `{"policy": "fair"}`, http.StatusBadRequest},
+		{"unknown queue", "root.unknown", `{"policy": "fair"}`, http.StatusNotFound},
+		{"valid", "root.default", `{"policy": "fair"}`, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// No err check: new request always returns correctly
+			//nolint: errcheck
+			req, _ := http.NewRequest("PUT", "/ws/v1/queue/"+tt.path+"/appsort", strings.NewReader(tt.body))
+			req = mux.SetURLVars(req, map[string]string{"path": tt.path})
+			resp := &MockResponseWriter{}
+			updateQueueAppSortPolicy(resp, req)
+			assert.Equal(t, resp.statusCode, tt.status, "unexpected status code: %s", string(resp.outputBytes))
+		})
+	}
+	assert.Equal(t, partition.GetQueue("root.default").GetApplicationSortingPolicy(), policies.FairSortPolicy, "queue policy not updated")
+}
+
+func TestUpdateQueueProperties(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	partition := schedulerContext.GetPartition("[" + rmID + "]default")
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+	}{
+		{"invalid json", "root.default", "{", http.StatusBadRequest},
+		{"unknown property", "root.default", `{"unknown": "value"}`, http.StatusBadRequest},
+		{"unknown policy", "root.default", `{"application.sort.policy": "unknown"}`, http.StatusBadRequest},
+		{"parent queue", "root", `{"application.sort.policy": "fair"}`, http.StatusBadRequest},
+		{"unknown queue", "root.unknown", `{"application.sort.policy": "fair"}`, http.StatusNotFound},
+		{"valid", "root.default", `{"application.sort.policy": "fair"}`, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// No err check: new request always returns correctly
+			//nolint: errcheck
+			req, _ := http.NewRequest("PATCH", "/ws/v1/queue/"+tt.path+"/properties", strings.NewReader(tt.body))
+			req = mux.SetURLVars(req, map[string]string{"path": tt.path})
+			resp := &MockResponseWriter{}
+			updateQueueProperties(resp, req)
+			assert.Equal(t, resp.statusCode, tt.status, "unexpected status code: %s", string(resp.outputBytes))
+		})
+	}
+	assert.Equal(t, partition.GetQueue("root.default").GetApplicationSortingPolicy(), policies.FairSortPolicy, "queue policy not updated")
+}
+
+func TestDrainQueue(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	partitionName := "[" + rmID + "]default"
+	partition := schedulerContext.GetPartition(partitionName)
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+	}{
+		{"unknown queue", "root.unknown", http.StatusNotFound},
+		{"root queue", "root", http.StatusBadRequest},
+		{"valid", "root.default", 0},
+		{"already draining", "root.default", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// No err check: new request always returns correctly
+			//nolint: errcheck
+			req, _ := http.NewRequest("POST", "/ws/v1/queue/"+tt.path+"/drain", nil)
+			req = mux.SetURLVars(req, map[string]string{"path": tt.path})
+			resp := &MockResponseWriter{}
+			drainQueue(resp, req)
+			assert.Equal(t, resp.statusCode, tt.status, "unexpected status code: %s", string(resp.outputBytes))
+		})
+	}
+	assert.Assert(t, partition.GetQueue("root.default").IsDraining(), "queue should be draining")
+	err = partition.AddApplication(newApplication("app-1", partitionName, "root.default", rmID))
+	assert.ErrorContains(t, err, "draining", "submission to a draining queue should have been rejected")
+}
+
+func TestGetQueueAllocationHistory(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+
+	tests := []struct {
+		name   string
+		path   string
+		since  string
+		status int
+	}{
+		{"unknown queue", "root.unknown", "", http.StatusNotFound},
+		{"invalid since", "root.default", "yesterday", http.StatusBadRequest},
+		{"no since", "root.default", "", 0},
+		{"valid since", "root.default", "2021-01-02T15:04:05Z", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := "/ws/v1/queue/" + tt.path + "/allocationHistory"
+			if tt.since != "" {
+				url += "?since=" + tt.since
+			}
+			// No err check: new request always returns correctly
+			//nolint: errcheck
+			req, _ := http.NewRequest("GET", url, nil)
+			req = mux.SetURLVars(req, map[string]string{"path": tt.path})
+			resp := &MockResponseWriter{}
+			getQueueAllocationHistory(resp, req)
+			assert.Equal(t, resp.statusCode, tt.status, "unexpected status code: %s", string(resp.outputBytes))
+			if tt.status != 0 {
+				return
+			}
+			var history []*dao.AllocationHistoryDAOInfo
+			err = json.Unmarshal(resp.outputBytes, &history)
+			assert.NilError(t, err, "failed to unmarshal response")
+			assert.Equal(t, len(history), 0, "queue without allocations should not have history")
+		})
+	}
+}
+
+func TestGetQueueCompletedApplications(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	partitionName := "[" + rmID + "]default"
+	partition := schedulerContext.GetPartition(partitionName)
+	for _, appID := range []string{"app-1", "app-2", "app-3"} {
+		err = partition.AddApplication(newApplication(appID, partitionName, "root.default", rmID))
+		assert.NilError(t, err, "add application to partition should not have failed")
+		partition.ForceRemoveApplication(appID, "test")
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		limit  string
+		status int
+		apps   []string
+	}{
+		{"unknown queue", "root.unknown", "", http.StatusNotFound, nil},
+		{"invalid limit", "root.default", "x", http.StatusBadRequest, nil},
+		{"negative limit", "root.default", "-1", http.StatusBadRequest, nil},
+		{"no completed apps", "root", "", 0, []string{}},
+		{"all", "root.default", "", 0, []string{"app-3", "app-2", "app-1"}},
+		{"limited", "root.default", "2", 0, []string{"app-3", "app-2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := "/ws/v1/queue/" + tt.path + "/completedApps"
+			if tt.limit != "" {
+				url += "?limit=" + tt.limit
+			}
+			// No err check: new request always returns correctly
+			//nolint: errcheck
+			req, _ := http.NewRequest("GET", url, nil)
+			req = mux.SetURLVars(req, map[string]string{"path": tt.path})
+			resp := &MockResponseWriter{}
+			getQueueCompletedApplications(resp, req)
+			assert.Equal(t, resp.statusCode, tt.status, "unexpected status code: %s", string(resp.outputBytes))
+			if tt.status != 0 {
+				return
+			}
+			var apps []*dao.CompletedApplicationDAOInfo
+			err = json.Unmarshal(resp.outputBytes, &apps)
+			assert.NilError(t, err, "failed to unmarshal response")
+			ids := make([]string, 0, len(apps))
+			for _, app := range apps {
+				ids = append(ids, app.ApplicationID)
+			}
+			assert.DeepEqual(t, ids, tt.apps)
+		})
+	}
+}
+
+func TestUndrainQueue(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	partitionName := "[" + rmID + "]default"
+	partition := schedulerContext.GetPartition(partitionName)
+
+	tests := []struct {
+		name   string
+		path   string
+		drain  bool
+		status int
+	}{
+		{"unknown queue", "root.unknown", false, http.StatusNotFound},
+		{"not drained", "root.default", false, http.StatusBadRequest},
+		{"valid", "root.default", true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.drain {
+				err = partition.DrainQueue(tt.path)
+				assert.NilError(t, err, "drain of queue should not have failed")
+			}
+			// No err check: new request always returns correctly
+			//nolint: errcheck
+			req, _ := http.NewRequest("POST", "/ws/v1/queue/"+tt.path+"/undrain", nil)
+			req = mux.SetURLVars(req, map[string]string{"path": tt.path})
+			resp := &MockResponseWriter{}
+			undrainQueue(resp, req)
+			assert.Equal(t, resp.statusCode, tt.status, "unexpected status code: %s", string(resp.outputBytes))
+		})
+	}
+	assert.Assert(t, partition.GetQueue("root.default").IsRunning(), "queue should be running")
+	err = partition.AddApplication(newApplication("app-1", partitionName, "root.default", rmID))
+	assert.NilError(t, err, "submission to an undrained queue should not have failed")
+}
+
+func TestGetSchedulingDiagnostics(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	partitionName := "[" + rmID + "]default"
+	partition := schedulerContext.GetPartition(partitionName)
+	app := newApplication("app-1", partitionName, "root.default", rmID)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "add application to partition should not have failed")
+	for nodeID, mem := range map[string]int64{"node-1": 1000, "node-2": 10} {
+		nodeRes := resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: resources.Quantity(mem)}).ToProto()
+		err = partition.AddNode(objects.NewNode(&si.NewNodeInfo{NodeID: nodeID, SchedulableResource: nodeRes}), nil)
+		assert.NilError(t, err, "add node to partition should not have failed")
+	}
+	err = app.AddAllocationAsk(objects.NewAllocationAsk(&si.AllocationAsk{
+		AllocationKey:  "ask-1",
+		ApplicationID:  "app-1",
+		ResourceAsk:    resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 100}).ToProto(),
+		MaxAllocations: 1,
+	}))
+	assert.NilError(t, err, "add ask to application should not have failed")
+
+	// No err check: new request always returns correctly
+	//nolint: errcheck
+	req, _ := http.NewRequest("GET", "/ws/v1/apps/app-1/diagnostics", strings.NewReader(""))
+	req = mux.SetURLVars(req, map[string]string{"appID": "app-1"})
+	resp := &MockResponseWriter{}
+	getSchedulingDiagnostics(resp, req)
+	var result dao.SchedulingDiagnosticsDAOInfo
+	err = json.Unmarshal(resp.outputBytes, &result)
+	assert.NilError(t, err, "failed to unmarshal diagnostics response from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, result.AllocationKey, "ask-1", "unexpected ask diagnosed")
+	assert.Equal(t, len(result.Nodes), 2, "expected diagnostics for both nodes")
+	assert.Equal(t, result.Nodes[0].NodeID, "node-1", "nodes not sorted")
+	assert.Equal(t, len(result.Nodes[0].Reasons), 0, "ask should fit on node-1")
+	assert.DeepEqual(t, result.Nodes[1].Reasons, []string{string(scheduler.NodeInsufficientMemory)})
+
+	// unknown application
+	req = mux.SetURLVars(req, map[string]string{"appID": "unknown"})
+	resp = &MockResponseWriter{}
+	getSchedulingDiagnostics(resp, req)
+	assert.Equal(t, resp.statusCode, http.StatusNotFound, "unknown application should return not found")
+}
+
+func TestGetPartitionSchedulingWaves(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+
+	// No err check: new request always returns correctly
+	//nolint: errcheck
+	req, _ := http.NewRequest("GET", "/ws/v1/partition/default/schedulingWaves", strings.NewReader(""))
+	req = mux.SetURLVars(req, map[string]string{"name": "default"})
+	resp := &MockResponseWriter{}
+	getPartitionSchedulingWaves(resp, req)
+	var waves []*dao.SchedulingWaveDAOInfo
+	err = json.Unmarshal(resp.outputBytes, &waves)
+	assert.NilError(t, err, "failed to unmarshal scheduling waves from response body: %s", string(resp.outputBytes))
+	assert.Assert(t, waves != nil, "empty list expected for a partition that has not scheduled")
+	assert.Equal(t, len(waves), 0, "unexpected scheduling waves")
+
+	//nolint: errcheck
+	req, _ = http.NewRequest("GET", "/ws/v1/partition/unknown/schedulingWaves", strings.NewReader(""))
+	req = mux.SetURLVars(req, map[string]string{"name": "unknown"})
+	resp = &MockResponseWriter{}
+	getPartitionSchedulingWaves(resp, req)
+	assert.Equal(t, resp.statusCode, http.StatusNotFound, "unknown partition should not be found")
+}
+
+func TestGetApplicationTaskGroups(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	partitionName := "[" + rmID + "]default"
+	partition := schedulerContext.GetPartition(partitionName)
+	app := newApplication("app-1", partitionName, "root.default", rmID)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "Failed to add Application to Partition.")
+	ask := objects.NewAllocationAsk(&si.AllocationAsk{
+		AllocationKey:  "gang-ask",
+		ApplicationID:  "app-1",
+		ResourceAsk:    resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 100}).ToProto(),
+		MaxAllocations: 3,
+		Tags:           map[string]string{objects.AskTagGangID: "workers"},
+	})
+	err = app.AddAllocationAsk(ask)
+	assert.NilError(t, err, "ask should have been added to app")
+
+	// No err check: new request always returns correctly
+	//nolint: errcheck
+	req, _ := http.NewRequest("GET", "/ws/v1/apps", strings.NewReader(""))
+	resp := &MockResponseWriter{}
+	getApplicationsInfo(resp, req)
+	var appsDao []*dao.ApplicationDAOInfo
+	err = json.Unmarshal(resp.outputBytes, &appsDao)
+	assert.NilError(t, err, "failed to unmarshal applications dao response from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, len(appsDao), 1, "unexpected number of applications")
+	assert.DeepEqual(t, appsDao[0].TaskGroups, []dao.TaskGroupDAOInfo{{Name: "workers", Total: 3, Pending: 3}})
+}
+
+func TestGetConfigDiff(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+
+	proposed := `
+partitions:
+  - name: default
+    queues:
+      - name: root
+        submitacl: "*"
+        queues:
+          - name: default
+            submitacl: "user1"
+          - name: new
+`
+	// No err check: new request always returns correctly
+	//nolint: errcheck
+	req, _ := http.NewRequest("POST", "/ws/v1/config/diff", strings.NewReader(proposed))
+	resp := &MockResponseWriter{}
+	getConfigDiff(resp, req)
+	var diffs []*dao.QueueTreeDiffDAOInfo
+	err = json.Unmarshal(resp.outputBytes, &diffs)
+	assert.NilError(t, err, "failed to unmarshal config diff from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, len(diffs), 1, "unexpected number of partitions")
+	assert.Equal(t, diffs[0].PartitionName, "default", "unexpected partition")
+	assert.DeepEqual(t, diffs[0].AddedQueues, []string{"root.new"})
+	assert.DeepEqual(t, diffs[0].RemovedQueues, []string{})
+	assert.DeepEqual(t, diffs[0].ModifiedQueues, []string{"root.default"})
+
+	// invalid config is rejected
+	//nolint: errcheck
+	req, _ = http.NewRequest("POST", "/ws/v1/config/diff", strings.NewReader(invalidConf))
+	resp = &MockResponseWriter{}
+	getConfigDiff(resp, req)
+	assert.Equal(t, resp.statusCode, http.StatusBadRequest, "invalid config should be rejected")
+}
+
+func TestGetApplicationETA(t *testing.T) {
+	configs.MockSchedulerConfigByData([]byte(configDefault))
+	var err error
+	schedulerContext, err = scheduler.NewClusterContext(rmID, policyGroup)
+	assert.NilError(t, err, "Error when load schedulerContext from config")
+	partitionName := "[" + rmID + "]default"
+	partition := schedulerContext.GetPartition(partitionName)
+	app := newApplication("app-1", partitionName, "root.default", rmID)
+	err = partition.AddApplication(app)
+	assert.NilError(t, err, "Failed to add Application to Partition.")
+
+	// nothing pending
+	// No err check: new request always returns correctly
+	//nolint: errcheck
+	req, _ := http.NewRequest("GET", "/ws/v1/apps/app-1/eta", strings.NewReader(""))
+	req = mux.SetURLVars(req, map[string]string{"appID": "app-1"})
+	resp := &MockResponseWriter{}
+	getApplicationETA(resp, req)
+	var eta dao.ApplicationETADAOInfo
+	err = json.Unmarshal(resp.outputBytes, &eta)
+	assert.NilError(t, err, "failed to unmarshal application eta from response body: %s", string(resp.outputBytes))
+	assert.Equal(t, eta.ApplicationID, "app-1", "unexpected application")
+	assert.Equal(t, eta.Partition, "default", "unexpected partition")
+	assert.Equal(t, eta.ETA, int64(0), "app without pending asks should not wait")
+
+	// pending without history cannot be predicted
+	ask := objects.NewAllocationAsk(&si.AllocationAsk{
+		AllocationKey:  "alloc-1",
+		ApplicationID:  "app-1",
+		ResourceAsk:    resources.NewResourceFromMap(map[string]resources.Quantity{resources.MEMORY: 100}).ToProto(),
+		MaxAllocations: 1,
+	})
+	err = app.AddAllocationAsk(ask)
+	assert.NilError(t, err, "ask should have been added to app")
+	resp = &MockResponseWriter{}
+	getApplicationETA(resp, req)
+	assert.Equal(t, resp.statusCode, http.StatusNotFound, "app without history should not have an eta")
+
+	//nolint: errcheck
+	req, _ = http.NewRequest("GET", "/ws/v1/apps/unknown/eta", strings.NewReader(""))
+	req = mux.SetURLVars(req, map[string]string{"appID": "unknown"})
+	resp = &MockResponseWriter{}
+	getApplicationETA(resp, req)
+	assert.Equal(t, resp.statusCode, http.StatusNotFound, "unknown application should not be found")
+}
@@ -42,6 +42,12 @@ var webRoutes = routes{
 		"/ws/v1/queues",
 		getQueueInfo,
 	},
+	route{
+		"Scheduler",
+		"POST",
+		"/ws/v1/queues",
+		getQueueInfoFiltered,
+	},
 	route{
 		"Cluster",
 		"GET",
@@ -54,12 +60,48 @@ var webRoutes = routes{
 		"/ws/v1/clusters/utilization",
 		getClusterUtilization,
 	},
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/version",
+		getSchedulerVersion,
+	},
 	route{
 		"Scheduler",
 		"GET",
 		"/ws/v1/apps",
 		getApplicationsInfo,
 	},
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/apps/{appID}/preemption-candidates",
+		getPreemptionCandidates,
+	},
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/apps/{appID}/diagnostics",
+		getSchedulingDiagnostics,
+	},
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/apps/{appID}/events",
+		getApplicationEvents,
+	},
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/apps/{appID}/placement",
+		getApplicationPlacement,
+	},
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/apps/{appID}/eta",
+		getApplicationETA,
+	},
 	route{
 		"Scheduler",
 		"GET",
@@ -72,6 +114,108 @@ var webRoutes = routes{
 		"/ws/v1/nodes/utilization",
 		getNodesUtilization,
 	},
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/partition/{name}/resources",
+		getPartitionResources,
+	},
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/partition/{name}/queues/load",
+		getPartitionQueueLoad,
+	},
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/partition/{name}/queue-depths",
+		getPartitionQueueDepths,
+	},
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/partition/{name}/fitmatrix",
+		getPartitionFitMatrix,
+	},
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/partition/{name}/nodegroups",
+		getPartitionNodeGroups,
+	},
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/partition/{name}/nodes/distribution",
+		getPartitionNodeDistribution,
+	},
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/partition/{name}/nodes/extremes",
+		getPartitionNodeExtremes,
+	},
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/partition/{name}/reservations/age",
+		getPartitionReservationAges,
+	},
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/partition/{name}/allocation-rates",
+		getPartitionAllocationRates,
+	},
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/partition/{name}/throughput",
+		getPartitionThroughput,
+	},
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/partition/{name}/backlog",
+		getPartitionBacklog,
+	},
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/partition/{name}/completedAllocations/stats",
+		getPartitionCompletedAllocationStats,
+	},
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/partition/{name}/schedulingWaves",
+		getPartitionSchedulingWaves,
+	},
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/partition/{name}/fairshare/violations",
+		getPartitionFairShareViolations,
+	},
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/node/{nodeID}/events",
+		getNodeEvents,
+	},
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/users/{user}/quota",
+		getUserQuotaUtilization,
+	},
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/allocation/{uuid}",
+		getAllocationInfo,
+	},
 
 	// endpoint to retrieve goroutines info
 	route{
@@ -97,6 +241,38 @@ var webRoutes = routes{
 		getClusterConfig,
 	},
 
+	// endpoint to retrieve the conf rebuilt from the live scheduler state
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/config/export",
+		getExportedConfig,
+	},
+
+	// endpoint to retrieve the JSON schema of the conf
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/config/schema",
+		getConfigSchema,
+	},
+
+	// endpoint to retrieve the checksum of the active conf
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/config/checksum",
+		getConfigChecksum,
+	},
+
+	// endpoint to compare the queues of a proposed conf with the current queues
+	route{
+		"Scheduler",
+		"POST",
+		"/ws/v1/config/diff",
+		getConfigDiff,
+	},
+
 	// endpoint to update the current conf
 	route{
 		"Scheduler",
@@ -105,6 +281,76 @@ var webRoutes = routes{
 		updateConfig,
 	},
 
+	// endpoint to enable or disable preemption for a partition
+	route{
+		"Scheduler",
+		"PUT",
+		"/ws/v1/partition/{name}/preemption",
+		updatePartitionPreemption,
+	},
+
+	// endpoint to update the max resource of an unmanaged queue, the config owns the max of managed queues
+	route{
+		"Scheduler",
+		"PUT",
+		"/ws/v1/queue/{path}/max",
+		updateQueueMaxResource,
+	},
+
+	// endpoint to update the application sort policy of a queue
+	route{
+		"Scheduler",
+		"PUT",
+		"/ws/v1/queue/{path}/appsort",
+		updateQueueAppSortPolicy,
+	},
+
+	// endpoint to update the properties of a queue
+	route{
+		"Scheduler",
+		"PATCH",
+		"/ws/v1/queue/{path}/properties",
+		updateQueueProperties,
+	},
+
+	// endpoint to drain a queue
+	route{
+		"Scheduler",
+		"POST",
+		"/ws/v1/queue/{path}/drain",
+		drainQueue,
+	},
+
+	// endpoint to undrain a queue that was drained on request
+	route{
+		"Scheduler",
+		"POST",
+		"/ws/v1/queue/{path}/undrain",
+		undrainQueue,
+	},
+
+	// endpoint to retrieve the completed applications of a queue
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/queue/{path}/completedApps",
+		getQueueCompletedApplications,
+	},
+
+	// endpoint to retrieve the allocation history of a queue
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/queue/{path}/allocationHistory",
+		getQueueAllocationHistory,
+	},
+	route{
+		"Scheduler",
+		"GET",
+		"/ws/v1/queue/{path}/fairshare",
+		getQueueFairShare,
+	},
+
 	// endpoint to validate conf
 	route{
 		"Scheduler",